@@ -0,0 +1,52 @@
+package switchblade
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+)
+
+const (
+	AppFeatureSSH       = "ssh"
+	AppFeatureRevisions = "revisions"
+)
+
+type appFeatureReader interface {
+	Read(ctx context.Context, name string) (map[string]bool, error)
+}
+
+type deploymentFeatures struct {
+	name   string
+	reader appFeatureReader
+}
+
+func (d Deployment) AppFeatures() (map[string]bool, error) {
+	if d.features == nil {
+		return nil, fmt.Errorf("failed to read app features for %s: app features are not supported by this platform", d.Name)
+	}
+
+	features, err := d.features.reader.Read(context.Background(), d.features.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read app features for %s: %w", d.Name, err)
+	}
+
+	return features, nil
+}
+
+func withAppFeatureReader(reader appFeatureReader) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.features = reader
+		return config
+	}
+}
+
+type cloudFoundryAppFeatureReader struct {
+	reader    cloudfoundry.AppFeatureReader
+	workspace string
+}
+
+func (r cloudFoundryAppFeatureReader) Read(ctx context.Context, name string) (map[string]bool, error) {
+	return r.reader.Read(ctx, filepath.Join(r.workspace, name), name)
+}
@@ -0,0 +1,187 @@
+package switchblade
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+)
+
+func WithFailureArtifacts(dir string) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.artifactsDir = dir
+		return config
+	}
+}
+
+type artifactTracker struct {
+	dir      string
+	failures map[string]fmt.Stringer
+	m        sync.Mutex
+}
+
+func (t *artifactTracker) enabled() bool {
+	return t != nil && t.dir != ""
+}
+
+func (t *artifactTracker) record(name string, logs fmt.Stringer, err error) {
+	if !t.enabled() {
+		return
+	}
+
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if err == nil {
+		delete(t.failures, name)
+		return
+	}
+
+	t.failures[name] = logs
+}
+
+func (t *artifactTracker) archive(name string, collect func(dir string) error) error {
+	if !t.enabled() {
+		return nil
+	}
+
+	t.m.Lock()
+	logs, ok := t.failures[name]
+	delete(t.failures, name)
+	t.m.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	dir := filepath.Join(t.dir, name)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "deploy.log"), []byte(logs.String()), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write deploy logs: %w", err)
+	}
+
+	return collect(dir)
+}
+
+type phaseTiming struct {
+	Phase    string  `json:"phase"`
+	Seconds  float64 `json:"seconds"`
+	Error    string  `json:"error,omitempty"`
+	Finished string  `json:"finished"`
+}
+
+type phaseTimings struct {
+	timings []phaseTiming
+	m       sync.Mutex
+}
+
+func (t *phaseTimings) hook() PhaseHook {
+	return PhaseHook{
+		Stop: func(phase string, labels map[string]string, duration time.Duration, err error) {
+			t.m.Lock()
+			defer t.m.Unlock()
+
+			timing := phaseTiming{
+				Phase:    phase,
+				Seconds:  duration.Seconds(),
+				Finished: time.Now().UTC().Format(time.RFC3339Nano),
+			}
+			if err != nil {
+				timing.Error = err.Error()
+			}
+
+			t.timings = append(t.timings, timing)
+		},
+	}
+}
+
+func (t *artifactTracker) write(name, platform string, logs fmt.Stringer, env map[string]string, timings *phaseTimings, deployErr error, collect func(dir string) error) error {
+	dir, err := filepath.Abs(filepath.Join(t.dir, name))
+	if err != nil {
+		return fmt.Errorf("%w\n\nfailed to write artifact bundle: %s", deployErr, err)
+	}
+
+	err = t.populate(dir, name, platform, logs, env, timings, deployErr, collect)
+	if err != nil {
+		return fmt.Errorf("%w\n\nfailed to write artifact bundle: %s", deployErr, err)
+	}
+
+	var attachments []string
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			attachments = append(attachments, fmt.Sprintf("[[ATTACHMENT|%s]]", path))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w\n\nfailed to list artifact bundle: %s", deployErr, err)
+	}
+
+	return fmt.Errorf("%w\n\nArtifacts:\n%s", deployErr, strings.Join(attachments, "\n"))
+}
+
+func (t *artifactTracker) populate(dir, name, platform string, logs fmt.Stringer, env map[string]string, timings *phaseTimings, deployErr error, collect func(dir string) error) error {
+	err := os.RemoveAll(dir)
+	if err != nil {
+		return fmt.Errorf("failed to remove stale artifact bundle: %w", err)
+	}
+
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create artifact bundle directory: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "deploy.log"), []byte(logs.String()), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write deploy logs: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "error.txt"), []byte(deployErr.Error()), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write deploy error: %w", err)
+	}
+
+	content, err := json.MarshalIndent(map[string]interface{}{
+		"name":     name,
+		"platform": platform,
+		"env":      docker.RedactEnvironment(env),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal deployment: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "deployment.json"), content, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write deployment: %w", err)
+	}
+
+	timings.m.Lock()
+	content, err = json.MarshalIndent(timings.timings, "", "  ")
+	timings.m.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal timings: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "timings.json"), content, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write timings: %w", err)
+	}
+
+	return collect(dir)
+}
@@ -0,0 +1,223 @@
+package switchblade
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+func WithAsyncTeardown() PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.asyncTeardown = true
+		return config
+	}
+}
+
+type cleanupTracker struct {
+	pending sync.WaitGroup
+	errs    []error
+	m       sync.Mutex
+}
+
+func (t *cleanupTracker) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for cleanup: %w", ctx.Err())
+	case <-done:
+	}
+
+	t.m.Lock()
+	errs := t.errs
+	t.errs = nil
+	t.m.Unlock()
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Errorf("%d deletions failed:\n%s", len(errs), strings.Join(messages, "\n"))
+}
+
+type asyncDeleteProcess struct {
+	delete  DeleteProcess
+	cleanup *cleanupTracker
+}
+
+func (p asyncDeleteProcess) Execute(name string) error {
+	p.cleanup.pending.Add(1)
+	go func() {
+		defer p.cleanup.pending.Done()
+
+		err := p.delete.Execute(name)
+		if err != nil {
+			p.cleanup.m.Lock()
+			p.cleanup.errs = append(p.cleanup.errs, fmt.Errorf("failed to delete %s: %w", name, err))
+			p.cleanup.m.Unlock()
+		}
+	}()
+
+	return nil
+}
+
+func (p asyncDeleteProcess) ExecuteWithReport(name string) (TeardownReport, error) {
+	return p.delete.ExecuteWithReport(name)
+}
+
+func (p asyncDeleteProcess) DryRun(name string) ([]Resource, error) {
+	return p.delete.DryRun(name)
+}
+
+func (p asyncDeleteProcess) ByPrefix(ctx context.Context, prefix string) error {
+	return p.delete.ByPrefix(ctx, prefix)
+}
+
+func withAsyncTeardown(platform Platform, config platformConfig) Platform {
+	if !config.asyncTeardown {
+		return platform
+	}
+
+	cleanup := &cleanupTracker{}
+	platform.Delete = asyncDeleteProcess{delete: platform.Delete, cleanup: cleanup}
+	platform.cleanup = cleanup
+
+	return platform
+}
+
+type deploymentTracker struct {
+	names map[string]struct{}
+	m     sync.Mutex
+}
+
+func (t *deploymentTracker) add(name string) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.names[name] = struct{}{}
+}
+
+func (t *deploymentTracker) remove(name string) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	delete(t.names, name)
+}
+
+func (t *deploymentTracker) list() []string {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	var names []string
+	for name := range t.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (p Platform) Cleanup(ctx context.Context) error {
+	var messages []string
+
+	if p.deployments != nil {
+		for _, name := range p.deployments.list() {
+			err := p.Delete.Execute(name)
+			if err != nil {
+				messages = append(messages, fmt.Sprintf("failed to delete %s: %s", name, err))
+			}
+		}
+	}
+
+	err := p.WaitForCleanup(ctx)
+	if err != nil {
+		messages = append(messages, err.Error())
+	}
+
+	err = p.Close()
+	if err != nil {
+		messages = append(messages, err.Error())
+	}
+
+	if len(messages) > 0 {
+		return fmt.Errorf("failed to clean up:\n%s", strings.Join(messages, "\n"))
+	}
+
+	return nil
+}
+
+func deleteAll(ctx context.Context, names []string, remove func(ctx context.Context, name string) error) error {
+	var messages []string
+	for _, name := range names {
+		err := remove(ctx, name)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("failed to delete %s: %s", name, err))
+		}
+	}
+
+	if len(messages) > 0 {
+		return fmt.Errorf("failed to delete deployments:\n%s", strings.Join(messages, "\n"))
+	}
+
+	return nil
+}
+
+func CleanupOnInterrupt(platform Platform) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+
+			err := platform.Cleanup(context.Background())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+
+			// Apps that were still being deployed are not tracked yet, so
+			// everything labelled with the run ID is removed as well.
+			if platform.interrupt != nil {
+				err = platform.interrupt.Execute(context.Background())
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
+			}
+
+			code := 1
+			if s, ok := sig.(syscall.Signal); ok {
+				code = 128 + int(s)
+			}
+
+			os.Exit(code)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
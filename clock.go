@@ -0,0 +1,16 @@
+package switchblade
+
+import "time"
+
+//go:generate faux --interface Clock --output fakes/clock.go
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+func WithClock(clock Clock) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.clock = clock
+		return config
+	}
+}
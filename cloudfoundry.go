@@ -1,11 +1,14 @@
 package switchblade
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"path/filepath"
+	"time"
 
 	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/docker"
 )
 
 //go:generate faux --package github.com/cloudfoundry/switchblade/internal/cloudfoundry --interface InitializePhase --name CloudFoundryInitializePhase --output fakes/cloudfoundry_initialize_phase.go
@@ -13,16 +16,21 @@ import (
 //go:generate faux --package github.com/cloudfoundry/switchblade/internal/cloudfoundry --interface StagePhase --name CloudFoundryStagePhase --output fakes/cloudfoundry_stage_phase.go
 //go:generate faux --package github.com/cloudfoundry/switchblade/internal/cloudfoundry --interface TeardownPhase --name CloudFoundryTeardownPhase --output fakes/cloudfoundry_teardown_phase.go
 
-func NewCloudFoundry(initialize cloudfoundry.InitializePhase, setup cloudfoundry.SetupPhase, stage cloudfoundry.StagePhase, teardown cloudfoundry.TeardownPhase, workspace string) Platform {
-	return Platform{
-		initialize: cloudFoundryInitializeProcess{initialize: initialize},
-		Deploy:     cloudFoundryDeployProcess{setup: setup, stage: stage, workspace: workspace},
-		Delete:     cloudFoundryDeleteProcess{teardown: teardown, workspace: workspace},
-	}
+func NewCloudFoundry(initialize cloudfoundry.InitializePhase, setup cloudfoundry.SetupPhase, stage cloudfoundry.StagePhase, teardown cloudfoundry.TeardownPhase, workspace string, options ...PlatformOption) Platform {
+	config := newPlatformConfig(options)
+
+	return withAsyncTeardown(Platform{
+		initialize:  cloudFoundryInitializeProcess{initialize: initialize, instrumentation: config.instrumentation},
+		deployments: config.deployments,
+		runID:       config.runID,
+		Deploy:      cloudFoundryDeployProcess{setup: setup, stage: stage, workspace: workspace, instrumentation: config.instrumentation, logs: config.logs, deployments: config.deployments, artifacts: config.artifacts, teardown: teardown, scaler: config.scaler, metadata: config.metadata, features: config.features, stagedBuildpacks: config.stagedBuildpacks, clock: config.clock, traffic: config.traffic, runtimeLogs: config.runtimeLogs},
+		Delete:      cloudFoundryDeleteProcess{teardown: teardown, workspace: workspace, instrumentation: config.instrumentation, deployments: config.deployments, artifacts: config.artifacts, traffic: config.traffic, runtimeLogs: config.runtimeLogs},
+	}, config)
 }
 
 type cloudFoundryInitializeProcess struct {
-	initialize cloudfoundry.InitializePhase
+	initialize      cloudfoundry.InitializePhase
+	instrumentation instrumentation
 }
 
 func (p cloudFoundryInitializeProcess) Execute(buildpacks ...Buildpack) error {
@@ -34,13 +42,54 @@ func (p cloudFoundryInitializeProcess) Execute(buildpacks ...Buildpack) error {
 		})
 	}
 
-	return p.initialize.Run(bps)
+	return p.instrumentation.run(context.Background(), "initialize", map[string]string{"platform": CloudFoundry}, func(context.Context) error {
+		return p.initialize.Run(bps)
+	})
+}
+
+type cloudFoundryBuildpackLister struct {
+	registry docker.BuildpacksRegistry
+}
+
+func (l cloudFoundryBuildpackLister) List() ([]cloudfoundry.Buildpack, error) {
+	list, err := l.registry.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var buildpacks []cloudfoundry.Buildpack
+	for _, buildpack := range list {
+		buildpacks = append(buildpacks, cloudfoundry.Buildpack{
+			Name: buildpack.Name,
+			URI:  buildpack.URI,
+		})
+	}
+
+	return buildpacks, nil
 }
 
 type cloudFoundryDeployProcess struct {
-	setup     cloudfoundry.SetupPhase
-	stage     cloudfoundry.StagePhase
-	workspace string
+	setup            cloudfoundry.SetupPhase
+	stage            cloudfoundry.StagePhase
+	workspace        string
+	instrumentation  instrumentation
+	logs             logBuffers
+	deployments      *deploymentTracker
+	artifacts        *artifactTracker
+	teardown         cloudfoundry.TeardownPhase
+	env              map[string]string
+	scaler           instanceScaler
+	metadata         metadataReader
+	features         appFeatureReader
+	stagedBuildpacks stagedBuildpackReader
+	clock            Clock
+	traffic          *trafficProxies
+	captureTraffic   bool
+	runtimeLogs      runtimeLogStreams
+	runtimeWriter    io.Writer
+	profileScripts   map[string]string
+	appFeatures      map[string]bool
+	manifest         cloudfoundry.Manifest
 }
 
 func (p cloudFoundryDeployProcess) WithBuildpacks(buildpacks ...string) DeployProcess {
@@ -54,6 +103,7 @@ func (p cloudFoundryDeployProcess) WithStack(stack string) DeployProcess {
 }
 
 func (p cloudFoundryDeployProcess) WithEnv(env map[string]string) DeployProcess {
+	p.env = env
 	p.setup = p.setup.WithEnv(env)
 	return p
 }
@@ -73,32 +123,353 @@ func (p cloudFoundryDeployProcess) WithServices(services map[string]Service) Dep
 	return p
 }
 
+func (p cloudFoundryDeployProcess) WithCredentials(credentials map[string]interface{}) DeployProcess {
+	p.setup = p.setup.WithCredentials(credentials)
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithStagingContainerReuse() DeployProcess {
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithStackSetup(setup func(exec Execer) error) DeployProcess {
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithTrafficCapture() DeployProcess {
+	p.captureTraffic = true
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithDevMode(paths ...string) DeployProcess {
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithUser(uid, gid int) DeployProcess {
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithReadOnlyRootFS() DeployProcess {
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithSecurityProfile(profile SecurityProfile) DeployProcess {
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithHealthCheckPolling(polling HealthCheckPolling) DeployProcess {
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithHealthCheck(healthCheckType, endpoint string, timeout time.Duration) DeployProcess {
+	p.setup = p.setup.WithHealthCheck(cloudfoundry.HealthCheck{
+		Type:     healthCheckType,
+		Endpoint: endpoint,
+		Timeout:  timeout,
+	})
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithSidecars(sidecars ...Sidecar) DeployProcess {
+	var s []cloudfoundry.Sidecar
+	for _, sidecar := range sidecars {
+		s = append(s, cloudfoundry.Sidecar(sidecar))
+	}
+
+	p.setup = p.setup.WithSidecars(s)
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithProfileScript(name, contents string) DeployProcess {
+	scripts := map[string]string{name: contents}
+	for key, value := range p.profileScripts {
+		if _, ok := scripts[key]; !ok {
+			scripts[key] = value
+		}
+	}
+
+	p.profileScripts = scripts
+	p.setup = p.setup.WithProfileScripts(scripts)
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithProcessCommand(processType string, command func(original string) string) DeployProcess {
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithProcessType(processType string) DeployProcess {
+	p.stage = p.stage.WithProcessType(processType)
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithMetadata(metadata Metadata) DeployProcess {
+	p.setup = p.setup.WithMetadata(cloudfoundry.Metadata(metadata))
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithAppFeature(name string, enabled bool) DeployProcess {
+	features := map[string]bool{name: enabled}
+	for key, value := range p.appFeatures {
+		if _, ok := features[key]; !ok {
+			features[key] = value
+		}
+	}
+
+	p.appFeatures = features
+	p.setup = p.setup.WithAppFeatures(features)
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithManifest(path string) DeployProcess {
+	p.manifest.Path = path
+	p.setup = p.setup.WithManifest(p.manifest)
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithManifestVars(vars map[string]string, varsFiles ...string) DeployProcess {
+	merged := map[string]string{}
+	for key, value := range p.manifest.Vars {
+		merged[key] = value
+	}
+	for key, value := range vars {
+		merged[key] = value
+	}
+
+	p.manifest.Vars = merged
+	p.manifest.VarsFiles = append(p.manifest.VarsFiles[:len(p.manifest.VarsFiles):len(p.manifest.VarsFiles)], varsFiles...)
+	p.setup = p.setup.WithManifest(p.manifest)
+	return p
+}
+
+func (p cloudFoundryDeployProcess) WithRuntimeLogWriter(w io.Writer) DeployProcess {
+	p.runtimeWriter = w
+	return p
+}
+
 func (p cloudFoundryDeployProcess) Execute(name, source string) (Deployment, fmt.Stringer, error) {
-	logs := bytes.NewBuffer(nil)
+	return p.execute(context.Background(), name, source)
+}
+
+func (p cloudFoundryDeployProcess) ExecuteFromReader(ctx context.Context, logs io.Writer, name string, bits io.Reader) (Deployment, error) {
+	p.setup = p.setup.WithSource(bits)
+	p.logs = p.logs.withWriter(logs)
+
+	deployment, _, err := p.execute(ctx, name, "")
+	return deployment, err
+}
+
+func (p cloudFoundryDeployProcess) execute(ctx context.Context, name, source string) (deployment Deployment, output fmt.Stringer, err error) {
+	logs, err := p.logs.create(name)
+	if err != nil {
+		return Deployment{}, nil, err
+	}
+	defer func() {
+		closeErr := logs.close()
+		if err == nil && closeErr != nil {
+			deployment, err = Deployment{}, closeErr
+		}
+	}()
+
 	home := filepath.Join(p.workspace, name)
 
-	internalURL, err := p.setup.Run(logs, home, name, source)
+	if p.artifacts.enabled() {
+		timings := &phaseTimings{}
+		p.instrumentation = p.instrumentation.withHook(timings.hook())
+		defer func() {
+			if err != nil {
+				err = p.artifacts.write(name, CloudFoundry, logs, p.env, timings, err, func(dir string) error {
+					return p.teardown.Archive(home, name, dir)
+				})
+			}
+		}()
+	}
+
+	labels := map[string]string{"platform": CloudFoundry, "app": name}
+	p.deployments.add(name)
+	defer func() { p.artifacts.record(name, logs, err) }()
+
+	var internalURL string
+	err = p.instrumentation.run(ctx, "setup", labels, func(ctx context.Context) (err error) {
+		internalURL, err = p.setup.Run(ctx, logs, home, name, source)
+		return err
+	})
 	if err != nil {
 		return Deployment{}, logs, err
 	}
 
-	externalURL, err := p.stage.Run(logs, home, name)
+	var externalURL string
+	recorder := newStagingLogRecorder(p.clock)
+	stopRecording := logs.record(recorder)
+	err = p.instrumentation.run(ctx, "stage", labels, func(ctx context.Context) (err error) {
+		externalURL, err = p.stage.Run(ctx, logs, home, name)
+		return err
+	})
+	stopRecording()
 	if err != nil {
 		return Deployment{}, logs, err
 	}
 
-	return Deployment{
+	deployment = Deployment{
 		Name:        name,
 		ExternalURL: externalURL,
 		InternalURL: internalURL,
-	}, logs, nil
+		Staging:     recorder.log(),
+	}
+
+	if p.stagedBuildpacks != nil {
+		deployment.Staging.Buildpacks, err = p.stagedBuildpacks.Read(ctx, name)
+		if err != nil {
+			return Deployment{}, logs, fmt.Errorf("failed to read staged buildpacks: %w", err)
+		}
+	}
+
+	if p.scaler != nil {
+		deployment.scaler = &deploymentScaler{
+			name:   name,
+			scaler: p.scaler,
+		}
+	}
+
+	if p.metadata != nil {
+		deployment.metadata = &deploymentMetadata{
+			name:   name,
+			reader: p.metadata,
+		}
+	}
+
+	if p.features != nil {
+		deployment.features = &deploymentFeatures{
+			name:   name,
+			reader: p.features,
+		}
+	}
+
+	if p.captureTraffic {
+		proxy, err := p.traffic.start(name, deployment.ExternalURL)
+		if err != nil {
+			return Deployment{}, logs, err
+		}
+
+		deployment.ExternalURL = proxy.url
+		deployment.traffic = proxy
+	}
+
+	p.runtimeLogs.start(name, name, p.runtimeWriter)
+
+	return deployment, logs, nil
 }
 
 type cloudFoundryDeleteProcess struct {
-	teardown  cloudfoundry.TeardownPhase
-	workspace string
+	teardown        cloudfoundry.TeardownPhase
+	workspace       string
+	instrumentation instrumentation
+	deployments     *deploymentTracker
+	artifacts       *artifactTracker
+	traffic         *trafficProxies
+	runtimeLogs     runtimeLogStreams
 }
 
 func (p cloudFoundryDeleteProcess) Execute(name string) error {
-	return p.teardown.Run(filepath.Join(p.workspace, name), name)
+	_, err := p.ExecuteWithReport(name)
+	return err
+}
+
+func (p cloudFoundryDeleteProcess) ExecuteWithReport(name string) (TeardownReport, error) {
+	return p.executeWithReport(context.Background(), name)
+}
+
+func (p cloudFoundryDeleteProcess) executeWithReport(ctx context.Context, name string) (TeardownReport, error) {
+	home := filepath.Join(p.workspace, name)
+
+	archiveErr := p.artifacts.archive(name, func(dir string) error {
+		return p.teardown.Archive(home, name, dir)
+	})
+
+	p.runtimeLogs.stop(name)
+
+	var report cloudfoundry.TeardownReport
+	err := p.instrumentation.run(ctx, "teardown", map[string]string{"platform": CloudFoundry, "app": name}, func(ctx context.Context) (err error) {
+		report, err = p.teardown.Run(ctx, home, name)
+		return err
+	})
+	if err != nil {
+		return convertCloudFoundryReport(report), err
+	}
+
+	p.deployments.remove(name)
+
+	err = p.traffic.stop(name)
+	if err != nil {
+		return convertCloudFoundryReport(report), err
+	}
+
+	if archiveErr != nil {
+		return convertCloudFoundryReport(report), fmt.Errorf("failed to archive artifacts: %w", archiveErr)
+	}
+
+	return convertCloudFoundryReport(report), nil
+}
+
+func (p cloudFoundryDeleteProcess) DryRun(name string) ([]Resource, error) {
+	resources, err := p.teardown.List(filepath.Join(p.workspace, name), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teardown resources: %w", err)
+	}
+
+	return convertCloudFoundryResources(resources), nil
+}
+
+func (p cloudFoundryDeleteProcess) ByPrefix(ctx context.Context, prefix string) error {
+	names, err := p.teardown.Names(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	return deleteAll(ctx, names, func(ctx context.Context, name string) error {
+		_, err := p.executeWithReport(ctx, name)
+		return err
+	})
+}
+
+type cloudFoundryGCProcess struct {
+	collector cloudfoundry.GarbageCollector
+}
+
+func (p cloudFoundryGCProcess) Execute(ctx context.Context, olderThan time.Duration) error {
+	err := p.collector.Run(ctx, olderThan)
+	if err != nil {
+		return fmt.Errorf("failed to collect garbage: %w", err)
+	}
+
+	return nil
+}
+
+func (p cloudFoundryGCProcess) DryRun(ctx context.Context, olderThan time.Duration) ([]Resource, error) {
+	resources, err := p.collector.List(ctx, olderThan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list garbage: %w", err)
+	}
+
+	return convertCloudFoundryResources(resources), nil
+}
+
+func convertCloudFoundryResources(resources []cloudfoundry.Resource) []Resource {
+	var converted []Resource
+	for _, resource := range resources {
+		converted = append(converted, Resource(resource))
+	}
+
+	return converted
+}
+
+func convertCloudFoundryReport(report cloudfoundry.TeardownReport) TeardownReport {
+	converted := TeardownReport{
+		Removed: convertCloudFoundryResources(report.Removed),
+		Missing: convertCloudFoundryResources(report.Missing),
+	}
+	for _, failure := range report.Failed {
+		converted.Failed = append(converted.Failed, TeardownFailure{Resource: Resource(failure.Resource), Error: failure.Error})
+	}
+
+	return converted
 }
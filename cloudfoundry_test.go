@@ -1,12 +1,16 @@
 package switchblade_test
 
 import (
+	"bytes"
+	gocontext "context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudfoundry/switchblade"
 	"github.com/cloudfoundry/switchblade/fakes"
@@ -101,12 +105,12 @@ func testCloudFoundry(t *testing.T, context spec.G, it spec.S) {
 			home, err = os.MkdirTemp("", "home")
 			Expect(err).NotTo(HaveOccurred())
 
-			setup.RunCall.Stub = func(logs io.Writer, home, name, source string) (string, error) {
+			setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, home, name, source string) (string, error) {
 				fmt.Fprintln(logs, "Setting up...")
 				return "some-internal-url", nil
 			}
 
-			stage.RunCall.Stub = func(logs io.Writer, home, name string) (string, error) {
+			stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, home, name string) (string, error) {
 				fmt.Fprintln(logs, "Staging...")
 				return "some-external-url", nil
 			}
@@ -129,16 +133,41 @@ func testCloudFoundry(t *testing.T, context spec.G, it spec.S) {
 				"Staging...",
 			))
 
+			Expect(setup.RunCall.Receives.Ctx).NotTo(BeNil())
 			Expect(setup.RunCall.Receives.Logs).To(Equal(logs))
 			Expect(setup.RunCall.Receives.Home).To(Equal(filepath.Join(workspace, "some-app")))
 			Expect(setup.RunCall.Receives.Name).To(Equal("some-app"))
 			Expect(setup.RunCall.Receives.Name).To(Equal("some-app"))
 
+			Expect(stage.RunCall.Receives.Ctx).NotTo(BeNil())
 			Expect(stage.RunCall.Receives.Logs).To(Equal(logs))
 			Expect(stage.RunCall.Receives.Home).To(Equal(filepath.Join(workspace, "some-app")))
 			Expect(stage.RunCall.Receives.Name).To(Equal("some-app"))
 		})
 
+		context("ExecuteFromReader", func() {
+			it.Before(func() {
+				setup.WithSourceCall.Returns.SetupPhase = setup
+			})
+
+			it("pushes the app from the given source stream", func() {
+				logs := bytes.NewBuffer(nil)
+				bits := strings.NewReader("some-tar-stream")
+
+				deployment, err := platform.Deploy.ExecuteFromReader(gocontext.Background(), logs, "some-app", bits)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployment.ExternalURL).To(Equal("some-external-url"))
+
+				Expect(setup.WithSourceCall.Receives.Source).To(Equal(bits))
+				Expect(setup.RunCall.Receives.Source).To(Equal(""))
+
+				Expect(logs).To(ContainLines(
+					"Setting up...",
+					"Staging...",
+				))
+			})
+		})
+
 		context("WithBuildpacks", func() {
 			it("uses those buildpacks", func() {
 				platform.Deploy.WithBuildpacks("some-buildpack", "other-buildpack")
@@ -182,10 +211,175 @@ func testCloudFoundry(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("WithCredentials", func() {
+			it("binds a credential service to the app", func() {
+				platform.Deploy.WithCredentials(map[string]interface{}{
+					"some-credential": "some-value",
+				})
+				Expect(setup.WithCredentialsCall.Receives.Credentials).To(Equal(map[string]interface{}{
+					"some-credential": "some-value",
+				}))
+			})
+		})
+
+		context("WithSidecars", func() {
+			it("declares those sidecars on the app", func() {
+				platform.Deploy.WithSidecars(switchblade.Sidecar{
+					Name:         "some-sidecar",
+					Command:      "some-command",
+					Memory:       "64M",
+					ProcessTypes: []string{"web"},
+				})
+				Expect(setup.WithSidecarsCall.Receives.Sidecars).To(Equal([]cloudfoundry.Sidecar{
+					{
+						Name:         "some-sidecar",
+						Command:      "some-command",
+						Memory:       "64M",
+						ProcessTypes: []string{"web"},
+					},
+				}))
+			})
+		})
+
+		context("WithHealthCheck", func() {
+			it("pushes the app with that health check", func() {
+				platform.Deploy.WithHealthCheck(switchblade.HealthCheckHTTP, "/health", 2*time.Minute)
+				Expect(setup.WithHealthCheckCall.Receives.HealthCheck).To(Equal(cloudfoundry.HealthCheck{
+					Type:     "http",
+					Endpoint: "/health",
+					Timeout:  2 * time.Minute,
+				}))
+			})
+		})
+
+		context("WithProcessType", func() {
+			it("starts that process type", func() {
+				platform.Deploy.WithProcessType("worker")
+				Expect(stage.WithProcessTypeCall.Receives.ProcessType).To(Equal("worker"))
+			})
+		})
+
+		context("WithProfileScript", func() {
+			it("adds the scripts to the app's profile.d directory", func() {
+				setup.WithProfileScriptsCall.Returns.SetupPhase = setup
+
+				platform.Deploy.
+					WithProfileScript("some-script.sh", "export SOME_VARIABLE=some-value").
+					WithProfileScript("other-script.sh", "export OTHER_VARIABLE=other-value")
+				Expect(setup.WithProfileScriptsCall.Receives.Scripts).To(Equal(map[string]string{
+					"some-script.sh":  "export SOME_VARIABLE=some-value",
+					"other-script.sh": "export OTHER_VARIABLE=other-value",
+				}))
+			})
+		})
+
+		context("WithMetadata", func() {
+			it("sets those labels and annotations on the app", func() {
+				platform.Deploy.WithMetadata(switchblade.Metadata{
+					Labels:      map[string]string{"team": "some-team"},
+					Annotations: map[string]string{"contact": "some-team@example.com"},
+				})
+				Expect(setup.WithMetadataCall.Receives.Metadata).To(Equal(cloudfoundry.Metadata{
+					Labels:      map[string]string{"team": "some-team"},
+					Annotations: map[string]string{"contact": "some-team@example.com"},
+				}))
+			})
+		})
+
+		context("WithAppFeature", func() {
+			it("updates those features on the app", func() {
+				setup.WithAppFeaturesCall.Returns.SetupPhase = setup
+
+				platform.Deploy.
+					WithAppFeature(switchblade.AppFeatureSSH, false).
+					WithAppFeature(switchblade.AppFeatureRevisions, true)
+				Expect(setup.WithAppFeaturesCall.Receives.Features).To(Equal(map[string]bool{
+					"ssh":       false,
+					"revisions": true,
+				}))
+			})
+		})
+
+		context("WithManifest and WithManifestVars", func() {
+			it("pushes the app with that manifest and its variables", func() {
+				setup.WithManifestCall.Returns.SetupPhase = setup
+
+				platform.Deploy.
+					WithManifest("/some/path/to/manifest.yml").
+					WithManifestVars(map[string]string{"memory": "128M", "instances": "2"}, "/some/path/to/vars.yml").
+					WithManifestVars(map[string]string{"memory": "256M"}, "/other/path/to/vars.yml")
+				Expect(setup.WithManifestCall.Receives.Manifest).To(Equal(cloudfoundry.Manifest{
+					Path: "/some/path/to/manifest.yml",
+					Vars: map[string]string{
+						"memory":    "256M",
+						"instances": "2",
+					},
+					VarsFiles: []string{"/some/path/to/vars.yml", "/other/path/to/vars.yml"},
+				}))
+			})
+		})
+
+		context("when an app feature reader is not configured", func() {
+			it("returns an error when reading app features", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = deployment.AppFeatures()
+				Expect(err).To(MatchError("failed to read app features for some-app: app features are not supported by this platform"))
+			})
+		})
+
+		context("when a metadata reader is not configured", func() {
+			it("returns an error when reading metadata", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = deployment.Metadata()
+				Expect(err).To(MatchError("failed to read metadata for some-app: metadata is not supported by this platform"))
+			})
+		})
+
+		context("when a scaler is not configured", func() {
+			it("returns an error when scaling", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = deployment.Scale(2)
+				Expect(err).To(MatchError("failed to scale some-app: scaling is not supported by this platform"))
+			})
+
+			it("returns an error when killing or restarting the container", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = deployment.Kill("SIGKILL")
+				Expect(err).To(MatchError("failed to kill some-app: killing containers is not supported by this platform"))
+
+				err = deployment.RestartContainer()
+				Expect(err).To(MatchError("failed to restart some-app: restarting containers is not supported by this platform"))
+			})
+
+			it("returns an error when rerunning the app", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = deployment.Rerun()
+				Expect(err).To(MatchError("failed to rerun some-app: rerunning is not supported by this platform"))
+			})
+
+			it("returns an error when snapshotting the environment", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = deployment.EnvironmentSnapshot()
+				Expect(err).To(MatchError("failed to snapshot environment for some-app: environment snapshots are not supported by this platform"))
+			})
+		})
+
 		context("failure cases", func() {
 			context("when the setup phase errors", func() {
 				it.Before(func() {
-					setup.RunCall.Stub = func(logs io.Writer, home, name, source string) (string, error) {
+					setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, home, name, source string) (string, error) {
 						fmt.Fprintln(logs, "Setting up... errored")
 						return "", errors.New("failed to setup")
 					}
@@ -200,7 +394,7 @@ func testCloudFoundry(t *testing.T, context spec.G, it spec.S) {
 
 			context("when the stage phase errors", func() {
 				it.Before(func() {
-					stage.RunCall.Stub = func(logs io.Writer, home, name string) (string, error) {
+					stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, home, name string) (string, error) {
 						fmt.Fprintln(logs, "Staging... errored")
 						return "some-url", errors.New("failed to stage")
 					}
@@ -227,6 +421,33 @@ func testCloudFoundry(t *testing.T, context spec.G, it spec.S) {
 			Expect(teardown.RunCall.Receives.Name).To(Equal("some-app"))
 		})
 
+		context("WithPhaseHooks", func() {
+			var phases []string
+
+			it.Before(func() {
+				phases = nil
+
+				platform = switchblade.NewCloudFoundry(initialize, setup, stage, teardown, workspace, switchblade.WithPhaseHooks(switchblade.PhaseHook{
+					Start: func(phase string, labels map[string]string) {
+						phases = append(phases, fmt.Sprintf("start %s %s %s", phase, labels["platform"], labels["app"]))
+					},
+					Stop: func(phase string, labels map[string]string, duration time.Duration, err error) {
+						phases = append(phases, fmt.Sprintf("stop %s %s %s", phase, labels["platform"], labels["app"]))
+					},
+				}))
+			})
+
+			it("calls the hooks around the teardown phase", func() {
+				err := platform.Delete.Execute("some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(phases).To(Equal([]string{
+					"start teardown cf some-app",
+					"stop teardown cf some-app",
+				}))
+			})
+		})
+
 		context("failure cases", func() {
 			context("when the teardown phase errors", func() {
 				it.Before(func() {
@@ -240,4 +461,184 @@ func testCloudFoundry(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 	})
+
+	context("WithFailureArtifacts", func() {
+		var artifacts string
+
+		it.Before(func() {
+			var err error
+			artifacts, err = os.MkdirTemp("", "artifacts")
+			Expect(err).NotTo(HaveOccurred())
+
+			setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, home, name, source string) (string, error) {
+				fmt.Fprintln(logs, "Setting up...")
+				return "", errors.New("setup phase errored")
+			}
+
+			platform = switchblade.NewCloudFoundry(initialize, setup, stage, teardown, workspace, switchblade.WithFailureArtifacts(artifacts))
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(artifacts)).To(Succeed())
+		})
+
+		it("archives the artifacts of failed deployments before deleting them", func() {
+			_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+			Expect(err).To(HaveOccurred())
+
+			Expect(platform.Delete.Execute("some-app")).To(Succeed())
+
+			Expect(teardown.ArchiveCall.Receives.Home).To(Equal(filepath.Join(workspace, "some-app")))
+			Expect(teardown.ArchiveCall.Receives.Name).To(Equal("some-app"))
+			Expect(teardown.ArchiveCall.Receives.Dir).To(Equal(filepath.Join(artifacts, "some-app")))
+			Expect(teardown.RunCall.CallCount).To(Equal(1))
+
+			content, err := os.ReadFile(filepath.Join(artifacts, "some-app", "deploy.log"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("Setting up...\n"))
+		})
+	})
+
+	context("WithFailureArtifacts when a deployment fails", func() {
+		var artifacts string
+
+		it.Before(func() {
+			var err error
+			artifacts, err = os.MkdirTemp("", "artifacts")
+			Expect(err).NotTo(HaveOccurred())
+
+			setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, home, name, source string) (string, error) {
+				fmt.Fprintln(logs, "Setting up...")
+				return "", errors.New("setup phase errored")
+			}
+
+			platform = switchblade.NewCloudFoundry(initialize, setup, stage, teardown, workspace, switchblade.WithFailureArtifacts(artifacts))
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(artifacts)).To(Succeed())
+		})
+
+		it("writes a bundle for a failed deployment as soon as it fails", func() {
+			_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+			Expect(err).To(MatchError(ContainSubstring("setup phase errored")))
+			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("[[ATTACHMENT|%s]]", filepath.Join(artifacts, "some-app", "deploy.log")))))
+
+			Expect(teardown.ArchiveCall.Receives.Home).To(Equal(filepath.Join(workspace, "some-app")))
+			Expect(teardown.ArchiveCall.Receives.Name).To(Equal("some-app"))
+			Expect(teardown.ArchiveCall.Receives.Dir).To(Equal(filepath.Join(artifacts, "some-app")))
+			Expect(teardown.RunCall.CallCount).To(Equal(0))
+
+			for _, file := range []string{"deploy.log", "error.txt", "deployment.json", "timings.json"} {
+				Expect(filepath.Join(artifacts, "some-app", file)).To(BeARegularFile())
+			}
+		})
+	})
+
+	context("ByPrefix", func() {
+		var deleted []string
+
+		it.Before(func() {
+			deleted = nil
+			teardown.NamesCall.Returns.StringSlice = []string{"nodejs_other-app", "nodejs_some-app"}
+			teardown.RunCall.Stub = func(ctx gocontext.Context, home, name string) (cloudfoundry.TeardownReport, error) {
+				deleted = append(deleted, name)
+				return cloudfoundry.TeardownReport{}, nil
+			}
+		})
+
+		it("deletes every deployment whose name matches the prefix", func() {
+			err := platform.Delete.ByPrefix(gocontext.Background(), "nodejs_")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(teardown.NamesCall.Receives.Prefix).To(Equal("nodejs_"))
+			Expect(deleted).To(Equal([]string{"nodejs_other-app", "nodejs_some-app"}))
+			Expect(teardown.RunCall.Receives.Home).To(Equal(filepath.Join(workspace, "nodejs_some-app")))
+		})
+
+		it("passes the context to the deletion of each deployment", func() {
+			ctx, cancel := gocontext.WithCancel(gocontext.Background())
+			defer cancel()
+
+			teardown.RunCall.Stub = func(ctx gocontext.Context, home, name string) (cloudfoundry.TeardownReport, error) {
+				deleted = append(deleted, name)
+				if ctx.Err() != nil {
+					return cloudfoundry.TeardownReport{}, ctx.Err()
+				}
+
+				cancel()
+				return cloudfoundry.TeardownReport{}, nil
+			}
+
+			err := platform.Delete.ByPrefix(ctx, "nodejs_")
+			Expect(err).To(MatchError(ContainSubstring("failed to delete nodejs_some-app:")))
+			Expect(err).To(MatchError(ContainSubstring("context canceled")))
+			Expect(deleted).To(Equal([]string{"nodejs_other-app", "nodejs_some-app"}))
+		})
+
+		context("failure cases", func() {
+			context("when the deployments cannot be listed", func() {
+				it.Before(func() {
+					teardown.NamesCall.Returns.Error = errors.New("could not list")
+				})
+
+				it("returns an error", func() {
+					err := platform.Delete.ByPrefix(gocontext.Background(), "nodejs_")
+					Expect(err).To(MatchError("failed to list deployments: could not list"))
+				})
+			})
+
+			context("when a deployment cannot be deleted", func() {
+				it.Before(func() {
+					teardown.RunCall.Stub = func(ctx gocontext.Context, home, name string) (cloudfoundry.TeardownReport, error) {
+						deleted = append(deleted, name)
+						if name == "nodejs_other-app" {
+							return cloudfoundry.TeardownReport{}, errors.New("teardown phase errored")
+						}
+
+						return cloudfoundry.TeardownReport{}, nil
+					}
+				})
+
+				it("deletes the rest and returns an error", func() {
+					err := platform.Delete.ByPrefix(gocontext.Background(), "nodejs_")
+					Expect(err).To(MatchError("failed to delete deployments:\nfailed to delete nodejs_other-app: teardown phase errored"))
+					Expect(deleted).To(Equal([]string{"nodejs_other-app", "nodejs_some-app"}))
+				})
+			})
+		})
+	})
+
+	context("DryRun", func() {
+		it.Before(func() {
+			teardown.ListCall.Returns.ResourceSlice = []cloudfoundry.Resource{
+				{Kind: "org", Name: "some-app", ID: "some-org-guid", Age: time.Hour},
+			}
+		})
+
+		it("lists the resources the teardown would delete without deleting them", func() {
+			resources, err := platform.Delete.DryRun("some-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resources).To(Equal([]switchblade.Resource{
+				{Kind: "org", Name: "some-app", ID: "some-org-guid", Age: time.Hour},
+			}))
+
+			Expect(teardown.ListCall.Receives.Home).To(Equal(filepath.Join(workspace, "some-app")))
+			Expect(teardown.ListCall.Receives.Name).To(Equal("some-app"))
+			Expect(teardown.RunCall.CallCount).To(Equal(0))
+		})
+
+		context("failure cases", func() {
+			context("when the teardown phase cannot list resources", func() {
+				it.Before(func() {
+					teardown.ListCall.Returns.Error = errors.New("could not list")
+				})
+
+				it("returns an error", func() {
+					_, err := platform.Delete.DryRun("some-app")
+					Expect(err).To(MatchError("failed to list teardown resources: could not list"))
+				})
+			})
+		})
+	})
 }
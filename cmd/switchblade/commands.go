@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/client"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+func stage(stdout io.Writer, opts options, name, path string) error {
+	phases, err := newPhases(opts)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	containerID, _, err := phases.setup.Run(ctx, stdout, name, path)
+	if err != nil {
+		return fmt.Errorf("failed to run setup phase: %w", err)
+	}
+
+	command, err := phases.stage.Run(ctx, stdout, containerID, name)
+	if err != nil {
+		return fmt.Errorf("failed to run stage phase: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "Start command: %s\n", command)
+
+	return nil
+}
+
+func start(stdout io.Writer, opts options, name string) error {
+	phases, err := newPhases(opts)
+	if err != nil {
+		return err
+	}
+
+	externalURL, internalURL, err := phases.start.Run(context.Background(), stdout, name, opts.command)
+	if err != nil {
+		return fmt.Errorf("failed to run start phase: %w", err)
+	}
+
+	printURLs(stdout, externalURL, internalURL)
+
+	return nil
+}
+
+func deploy(stdout io.Writer, opts options, name, path string) error {
+	platform, err := newPlatform(opts)
+	if err != nil {
+		return err
+	}
+
+	deployment, logs, err := platform.Deploy.
+		WithBuildpacks(opts.buildpacks...).
+		WithEnv(opts.env).
+		Execute(name, path)
+	if logs != nil {
+		fmt.Fprint(stdout, logs)
+	}
+	if err != nil {
+		return err
+	}
+
+	printURLs(stdout, deployment.ExternalURL, deployment.InternalURL)
+
+	return nil
+}
+
+func remove(opts options, name string) error {
+	platform, err := newPlatform(opts)
+	if err != nil {
+		return err
+	}
+
+	return platform.Delete.Execute(name)
+}
+
+func cleanup(stdout io.Writer, opts options) error {
+	var platformType string
+	switch opts.platform {
+	case "docker":
+		platformType = switchblade.Docker
+	case "cf":
+		platformType = switchblade.CloudFoundry
+	default:
+		return fmt.Errorf("unknown platform: %q", opts.platform)
+	}
+
+	platform, err := switchblade.NewPlatform(platformType, opts.token, opts.stack, switchblade.WithWorkspace(opts.workspace))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	if opts.dryRun {
+		resources, err := platform.GCDryRun(ctx, opts.olderThan)
+		if err != nil {
+			return err
+		}
+
+		printResources(stdout, resources)
+
+		return nil
+	}
+
+	return platform.GC(ctx, opts.olderThan)
+}
+
+func printResources(stdout io.Writer, resources []switchblade.Resource) {
+	writer := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "KIND\tNAME\tID\tAGE")
+	for _, resource := range resources {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", resource.Kind, resource.Name, resource.ID, resource.Age.Round(time.Second))
+	}
+	writer.Flush()
+}
+
+func printURLs(stdout io.Writer, externalURL, internalURL string) {
+	fmt.Fprintf(stdout, "External URL: %s\n", externalURL)
+	fmt.Fprintf(stdout, "Internal URL: %s\n", internalURL)
+}
+
+func newPlatform(opts options) (switchblade.Platform, error) {
+	platform, err := switchblade.NewPlatform(switchblade.Docker, opts.token, opts.stack, switchblade.WithWorkspace(opts.workspace))
+	if err != nil {
+		return switchblade.Platform{}, err
+	}
+
+	err = platform.Initialize(buildpacks(opts.overrides)...)
+	if err != nil {
+		return switchblade.Platform{}, err
+	}
+
+	return platform, nil
+}
+
+func buildpacks(overrides pairs) []switchblade.Buildpack {
+	var names []string
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buildpacks []switchblade.Buildpack
+	for _, name := range names {
+		buildpacks = append(buildpacks, switchblade.Buildpack{Name: name, URI: overrides[name]})
+	}
+
+	return buildpacks
+}
+
+type phases struct {
+	setup docker.SetupPhase
+	stage docker.StagePhase
+	start docker.StartPhase
+}
+
+func newPhases(opts options) (phases, error) {
+	var signingKey crypto.Signer
+	if opts.signingKey != "" {
+		key, err := readSigningKey(opts.signingKey)
+		if err != nil {
+			return phases{}, err
+		}
+
+		signingKey = key
+	}
+
+	var verificationKey crypto.PublicKey
+	if opts.verificationKey != "" {
+		key, err := readVerificationKey(opts.verificationKey)
+		if err != nil {
+			return phases{}, err
+		}
+
+		verificationKey = key
+	}
+
+	apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return phases{}, err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return phases{}, err
+	}
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return phases{}, err
+	}
+
+	workspace := opts.workspace
+	if workspace == "" {
+		workspace = filepath.Join(home, ".switchblade")
+	}
+
+	archiver := docker.NewTGZArchiver()
+	lifecycleManager := docker.NewOnceLifecycleBuilder(docker.NewLifecycleManager(pexec.NewExecutable("go"), archiver, filepath.Join(cache, "switchblade", "lifecycle")), filepath.Join(workspace, "locks"))
+	buildpacksRegistry := docker.NewBuildpacksRegistry("https://api.github.com", opts.token)
+	buildpacksManager := docker.NewBuildpacksManager(archiver, docker.NewBuildpacksCache(filepath.Join(workspace, "buildpacks-cache")), buildpacksRegistry)
+	networkManager := docker.NewNetworkManager(apiClient)
+
+	var overrides []docker.Buildpack
+	for _, buildpack := range buildpacks(opts.overrides) {
+		overrides = append(overrides, docker.Buildpack{Name: buildpack.Name, URI: buildpack.URI})
+	}
+	docker.NewInitialize(buildpacksRegistry).Run(overrides)
+
+	setup := docker.NewSetup(apiClient, lifecycleManager, buildpacksManager, archiver, networkManager, workspace, opts.stack).
+		WithStackPuller(docker.NewStackPuller(apiClient, filepath.Join(workspace, "locks")))
+
+	stage := docker.NewStage(apiClient, archiver, workspace)
+	if signingKey != nil {
+		stage = stage.WithDropletSigning(signingKey)
+	}
+
+	start := docker.NewStart(apiClient, networkManager, workspace, opts.stack)
+	if verificationKey != nil {
+		start = start.WithDropletVerification(verificationKey)
+	}
+
+	return phases{
+		setup: setup.WithBuildpacks(opts.buildpacks...).WithEnv(opts.env),
+		stage: stage,
+		start: start.WithEnv(opts.env),
+	}, nil
+}
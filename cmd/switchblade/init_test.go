@@ -0,0 +1,17 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega/format"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestSwitchblade(t *testing.T) {
+	format.MaxLength = 0
+
+	suite := spec.New("cmd/switchblade", spec.Report(report.Terminal{}), spec.Parallel())
+	suite("Run", testRun)
+	suite.Run(t)
+}
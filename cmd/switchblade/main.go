@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: switchblade <command> [options] [<name>] [<path>]
+
+Commands:
+  stage    Stage an application source into a droplet
+  start    Start a container from a previously staged droplet
+  deploy   Stage and start an application
+  delete   Delete an application and its resources
+  cleanup  Remove switchblade resources left behind by earlier runs
+`
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	stack      string
+	token      string
+	command    string
+	buildpacks list
+	overrides  pairs
+	env        pairs
+	platform   string
+	olderThan  time.Duration
+	dryRun     bool
+	workspace  string
+
+	signingKey      string
+	verificationKey string
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errors.New("missing command")
+	}
+
+	var opts options
+	set := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	set.SetOutput(stderr)
+	set.StringVar(&opts.stack, "stack", "cflinuxfs3", "stack to stage and run the application on")
+	set.StringVar(&opts.token, "token", os.Getenv("GITHUB_TOKEN"), "GitHub API token used to look up buildpacks")
+	set.StringVar(&opts.workspace, "workspace", "", "directory to keep droplets, logs, and caches in (defaults to ~/.switchblade)")
+
+	if args[0] != "cleanup" {
+		set.Var(&opts.buildpacks, "buildpack", "buildpack name to stage with (may be repeated)")
+		set.Var(&opts.overrides, "buildpack-uri", "buildpack override as name=uri (may be repeated)")
+		set.Var(&opts.env, "env", "environment variable as KEY=VALUE (may be repeated)")
+	}
+
+	var operands int
+	switch args[0] {
+	case "stage":
+		set.StringVar(&opts.signingKey, "signing-key", "", "PEM private key used to sign the droplet")
+		operands = 2
+	case "deploy":
+		operands = 2
+	case "start":
+		set.StringVar(&opts.command, "command", "", "start command printed by the stage command")
+		set.StringVar(&opts.verificationKey, "verification-key", "", "PEM public key the droplet signature must match")
+		operands = 1
+	case "delete":
+		operands = 1
+	case "cleanup":
+		set.StringVar(&opts.platform, "platform", "docker", "platform to clean up, either docker or cf")
+		set.DurationVar(&opts.olderThan, "older-than", 24*time.Hour, "only remove resources older than this")
+		set.BoolVar(&opts.dryRun, "dry-run", false, "list the resources that would be removed without removing them")
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command: %q", args[0])
+	}
+
+	err := set.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+
+	if set.NArg() != operands {
+		return fmt.Errorf("%s expects %d argument(s), received %d", args[0], operands, set.NArg())
+	}
+
+	switch args[0] {
+	case "stage":
+		return stage(stdout, opts, set.Arg(0), set.Arg(1))
+	case "start":
+		if opts.command == "" {
+			return errors.New("start requires a -command")
+		}
+
+		return start(stdout, opts, set.Arg(0))
+	case "deploy":
+		return deploy(stdout, opts, set.Arg(0), set.Arg(1))
+	case "cleanup":
+		return cleanup(stdout, opts)
+	default:
+		return remove(opts, set.Arg(0))
+	}
+}
+
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+type pairs map[string]string
+
+func (p *pairs) String() string {
+	var values []string
+	for key, value := range *p {
+		values = append(values, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(values)
+
+	return strings.Join(values, ",")
+}
+
+func (p *pairs) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected KEY=VALUE, received %q", value)
+	}
+
+	if *p == nil {
+		*p = pairs{}
+	}
+	(*p)[key] = val
+
+	return nil
+}
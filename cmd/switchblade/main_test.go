@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRun(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		stdout *bytes.Buffer
+		stderr *bytes.Buffer
+	)
+
+	it.Before(func() {
+		stdout = bytes.NewBuffer(nil)
+		stderr = bytes.NewBuffer(nil)
+	})
+
+	context("help", func() {
+		it("prints the usage", func() {
+			err := run([]string{"help"}, stdout, stderr)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(ContainSubstring("Usage: switchblade <command>"))
+			Expect(stdout.String()).To(ContainSubstring("deploy   Stage and start an application"))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when no command is given", func() {
+			it("prints the usage and returns an error", func() {
+				err := run(nil, stdout, stderr)
+				Expect(err).To(MatchError("missing command"))
+
+				Expect(stderr.String()).To(ContainSubstring("Usage: switchblade <command>"))
+			})
+		})
+
+		context("when the command is unknown", func() {
+			it("returns an error", func() {
+				err := run([]string{"push"}, stdout, stderr)
+				Expect(err).To(MatchError(`unknown command: "push"`))
+			})
+		})
+
+		context("when the wrong number of arguments is given", func() {
+			it("returns an error", func() {
+				err := run([]string{"deploy", "some-app"}, stdout, stderr)
+				Expect(err).To(MatchError("deploy expects 2 argument(s), received 1"))
+			})
+		})
+
+		context("when start is given no command", func() {
+			it("returns an error", func() {
+				err := run([]string{"start", "some-app"}, stdout, stderr)
+				Expect(err).To(MatchError("start requires a -command"))
+			})
+		})
+
+		context("when an environment variable is malformed", func() {
+			it("returns an error", func() {
+				err := run([]string{"deploy", "-env", "SOME_KEY", "some-app", "some-path"}, stdout, stderr)
+				Expect(err).To(MatchError(`invalid value "SOME_KEY" for flag -env: expected KEY=VALUE, received "SOME_KEY"`))
+			})
+		})
+
+		context("when cleanup is given an unknown platform", func() {
+			it("returns an error", func() {
+				err := run([]string{"cleanup", "-platform", "k8s"}, stdout, stderr)
+				Expect(err).To(MatchError(`unknown platform: "k8s"`))
+			})
+		})
+
+		context("when cleanup is given a malformed age", func() {
+			it("returns an error", func() {
+				err := run([]string{"cleanup", "-older-than", "yesterday"}, stdout, stderr)
+				Expect(err).To(MatchError(ContainSubstring(`invalid value "yesterday" for flag -older-than`)))
+			})
+		})
+
+		context("when cleanup is given an application name", func() {
+			it("returns an error", func() {
+				err := run([]string{"cleanup", "some-app"}, stdout, stderr)
+				Expect(err).To(MatchError("cleanup expects 0 argument(s), received 1"))
+			})
+		})
+
+		context("when the signing key does not exist", func() {
+			it("returns an error", func() {
+				err := run([]string{"stage", "-signing-key", filepath.Join(t.TempDir(), "missing.pem"), "some-app", "some-path"}, stdout, stderr)
+				Expect(err).To(MatchError(ContainSubstring("failed to read signing key: open")))
+			})
+		})
+
+		context("when the signing key is not a private key", func() {
+			it("returns an error", func() {
+				path := filepath.Join(t.TempDir(), "key.pem")
+				Expect(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")}), 0600)).To(Succeed())
+
+				err := run([]string{"stage", "-signing-key", path, "some-app", "some-path"}, stdout, stderr)
+				Expect(err).To(MatchError(fmt.Sprintf("failed to read signing key: %s does not contain a PKCS #8, EC, or PKCS #1 private key", path)))
+			})
+		})
+
+		context("when the verification key is not PEM encoded", func() {
+			it("returns an error", func() {
+				path := filepath.Join(t.TempDir(), "key.pub")
+				Expect(os.WriteFile(path, []byte("not-a-key"), 0600)).To(Succeed())
+
+				err := run([]string{"start", "-command", "some-command", "-verification-key", path, "some-app"}, stdout, stderr)
+				Expect(err).To(MatchError("failed to read verification key: no PEM data found"))
+			})
+		})
+
+		context("when the workspace cannot be created", func() {
+			it("returns an error", func() {
+				path := filepath.Join(t.TempDir(), "some-file")
+				Expect(os.WriteFile(path, nil, 0600)).To(Succeed())
+
+				err := run([]string{"cleanup", "-platform", "cf", "-workspace", filepath.Join(path, "workspace")}, stdout, stderr)
+				Expect(err).To(MatchError(ContainSubstring("failed to create workspace:")))
+				Expect(err).To(MatchError(ContainSubstring("not a directory")))
+			})
+		})
+
+		context("when a flag is unknown", func() {
+			it("returns an error", func() {
+				err := run([]string{"delete", "-force", "some-app"}, stdout, stderr)
+				Expect(err).To(MatchError("flag provided but not defined: -force"))
+			})
+		})
+	})
+}
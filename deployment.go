@@ -1,7 +1,114 @@
 package switchblade
 
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+)
+
 type Deployment struct {
 	Name        string
 	ExternalURL string
 	InternalURL string
+	StackDigest string
+	Scan        ScanResult
+	Staging     StagingLog
+
+	container   *deploymentContainer
+	scaler      *deploymentScaler
+	metadata    *deploymentMetadata
+	features    *deploymentFeatures
+	controller  *deploymentController
+	environment *deploymentEnvironment
+	traffic     *trafficProxy
+	sbom        string
+	manifest    string
+}
+
+type deploymentContainer struct {
+	logs     io.Writer
+	name     string
+	droplets dropletPusher
+}
+
+type deploymentController struct {
+	name       string
+	controller containerController
+}
+
+func (d Deployment) PushDroplet(ref string) error {
+	if d.container == nil {
+		return fmt.Errorf("failed to push droplet for %s: pushing droplets is not supported by this platform", d.Name)
+	}
+
+	return d.container.droplets.Push(context.Background(), d.container.logs, d.container.name, ref)
+}
+
+func (d Deployment) Snapshot(ref string) error {
+	if d.container == nil {
+		return fmt.Errorf("failed to snapshot %s: snapshots are not supported by this platform", d.Name)
+	}
+
+	return d.container.droplets.Snapshot(context.Background(), d.container.name, ref)
+}
+
+func (d Deployment) Kill(signal string) error {
+	if d.controller == nil {
+		return fmt.Errorf("failed to kill %s: killing containers is not supported by this platform", d.Name)
+	}
+
+	return d.controller.controller.Kill(context.Background(), d.controller.name, signal)
+}
+
+func (d Deployment) RestartContainer() error {
+	if d.controller == nil {
+		return fmt.Errorf("failed to restart %s: restarting containers is not supported by this platform", d.Name)
+	}
+
+	return d.controller.controller.Restart(context.Background(), d.controller.name)
+}
+
+func (d Deployment) SBOM() (SBOM, error) {
+	if d.sbom == "" {
+		return SBOM{}, fmt.Errorf("failed to read sbom for %s: sboms are not enabled for this platform", d.Name)
+	}
+
+	components, err := docker.ReadSBOM(d.sbom)
+	if err != nil {
+		return SBOM{}, fmt.Errorf("failed to read sbom for %s: %w", d.Name, err)
+	}
+
+	sbom := SBOM{Name: d.Name}
+	for _, component := range components {
+		sbom.Components = append(sbom.Components, SBOMComponent(component))
+	}
+
+	return sbom, nil
+}
+
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+func WithDeployment(t TestingT, platform Platform, name, source string, body func(Deployment)) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		err := platform.Delete.Execute(name)
+		if err != nil {
+			t.Errorf("failed to delete %s: %s", name, err)
+		}
+	})
+
+	deployment, _, err := platform.Deploy.Execute(name, source)
+	if err != nil {
+		t.Fatalf("failed to deploy %s: %s", name, err)
+	}
+
+	body(deployment)
 }
@@ -0,0 +1,146 @@
+package switchblade_test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/cloudfoundry/switchblade/fakes"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+type fakeTestingT struct {
+	cleanups []func()
+	errors   []string
+	fatals   []string
+}
+
+func (t *fakeTestingT) Helper() {}
+
+func (t *fakeTestingT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *fakeTestingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeTestingT) Fatalf(format string, args ...interface{}) {
+	t.fatals = append(t.fatals, fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+func (t *fakeTestingT) run(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { _ = recover() }()
+		f()
+	}()
+	<-done
+
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func testWithDeployment(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		platform switchblade.Platform
+		teardown *fakes.DockerTeardownPhase
+		start    *fakes.DockerStartPhase
+		fakeT    *fakeTestingT
+	)
+
+	it.Before(func() {
+		start = &fakes.DockerStartPhase{}
+		start.RunCall.Returns.ExternalURL = "some-external-url"
+		teardown = &fakes.DockerTeardownPhase{}
+
+		platform = switchblade.NewDocker(&fakes.DockerInitializePhase{}, &fakes.DockerSetupPhase{}, &fakes.DockerStagePhase{}, start, teardown)
+		fakeT = &fakeTestingT{}
+	})
+
+	it("deploys the app, runs the body, and deletes the app", func() {
+		var deployment switchblade.Deployment
+		fakeT.run(func() {
+			switchblade.WithDeployment(fakeT, platform, "some-app", "/some/path/to/my/app", func(d switchblade.Deployment) {
+				deployment = d
+			})
+		})
+
+		Expect(deployment.Name).To(Equal("some-app"))
+		Expect(deployment.ExternalURL).To(Equal("some-external-url"))
+		Expect(teardown.RunCall.CallCount).To(Equal(1))
+		Expect(teardown.RunCall.Receives.Name).To(Equal("some-app"))
+		Expect(fakeT.errors).To(BeEmpty())
+		Expect(fakeT.fatals).To(BeEmpty())
+	})
+
+	context("when the body panics", func() {
+		it("still deletes the app", func() {
+			fakeT.run(func() {
+				switchblade.WithDeployment(fakeT, platform, "some-app", "/some/path/to/my/app", func(d switchblade.Deployment) {
+					panic("something went wrong")
+				})
+			})
+
+			Expect(teardown.RunCall.CallCount).To(Equal(1))
+		})
+	})
+
+	context("when the body fails the test", func() {
+		it("still deletes the app", func() {
+			fakeT.run(func() {
+				switchblade.WithDeployment(fakeT, platform, "some-app", "/some/path/to/my/app", func(d switchblade.Deployment) {
+					fakeT.Fatalf("assertion failed")
+				})
+			})
+
+			Expect(fakeT.fatals).To(Equal([]string{"assertion failed"}))
+			Expect(teardown.RunCall.CallCount).To(Equal(1))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the deployment fails", func() {
+			it.Before(func() {
+				start.RunCall.Returns.Err = errors.New("start phase errored")
+			})
+
+			it("fails the test without running the body and deletes the app", func() {
+				var called bool
+				fakeT.run(func() {
+					switchblade.WithDeployment(fakeT, platform, "some-app", "/some/path/to/my/app", func(d switchblade.Deployment) {
+						called = true
+					})
+				})
+
+				Expect(called).To(BeFalse())
+				Expect(fakeT.fatals).To(HaveLen(1))
+				Expect(fakeT.fatals[0]).To(ContainSubstring("failed to deploy some-app: failed to run start phase: start phase errored"))
+				Expect(teardown.RunCall.CallCount).To(Equal(1))
+			})
+		})
+
+		context("when the deletion fails", func() {
+			it.Before(func() {
+				teardown.RunCall.Returns.Error = errors.New("teardown phase errored")
+			})
+
+			it("reports the error", func() {
+				fakeT.run(func() {
+					switchblade.WithDeployment(fakeT, platform, "some-app", "/some/path/to/my/app", func(d switchblade.Deployment) {})
+				})
+
+				Expect(fakeT.errors).To(Equal([]string{"failed to delete some-app: failed to run teardown phase: teardown phase errored"}))
+			})
+		})
+	})
+}
@@ -0,0 +1,54 @@
+package switchblade
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+func (d Deployment) Rerun() error {
+	if d.controller == nil {
+		return fmt.Errorf("failed to rerun %s: rerunning is not supported by this platform", d.Name)
+	}
+
+	return d.controller.controller.Restart(context.Background(), d.controller.name)
+}
+
+func devMounts(source string, paths []string) (map[string]string, error) {
+	if source == "" {
+		return nil, fmt.Errorf("failed to enable dev mode: a source directory is required")
+	}
+
+	source, err := filepath.Abs(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable dev mode: %w", err)
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable dev mode: %w", err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("failed to enable dev mode: %s is not a directory", source)
+	}
+
+	if len(paths) == 0 {
+		return map[string]string{"/home/vcap/app": source}, nil
+	}
+
+	mounts := map[string]string{}
+	for _, p := range paths {
+		clean := filepath.Clean(p)
+		if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("failed to enable dev mode: %s is not a subdirectory of the source", p)
+		}
+
+		mounts[path.Join("/home/vcap/app", filepath.ToSlash(clean))] = filepath.Join(source, clean)
+	}
+
+	return mounts, nil
+}
@@ -1,9 +1,13 @@
 package switchblade
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry/switchblade/internal/docker"
 )
@@ -14,16 +18,34 @@ import (
 //go:generate faux --package github.com/cloudfoundry/switchblade/internal/docker --interface StartPhase --name DockerStartPhase --output fakes/docker_start_phase.go
 //go:generate faux --package github.com/cloudfoundry/switchblade/internal/docker --interface TeardownPhase --name DockerTeardownPhase --output fakes/docker_teardown_phase.go
 
-func NewDocker(initialize docker.InitializePhase, setup docker.SetupPhase, stage docker.StagePhase, start docker.StartPhase, teardown docker.TeardownPhase) Platform {
-	return Platform{
-		initialize: dockerInitializeProcess{initialize: initialize},
-		Deploy:     dockerDeployProcess{setup: setup, stage: stage, start: start},
-		Delete:     dockerDeleteProcess{teardown: teardown},
+type StartError = docker.StartError
+
+const stagingRetryDelay = 2 * time.Second
+
+func NewDocker(initialize docker.InitializePhase, setup docker.SetupPhase, stage docker.StagePhase, start docker.StartPhase, teardown docker.TeardownPhase, options ...PlatformOption) Platform {
+	config := newPlatformConfig(options)
+
+	if config.metrics != nil && config.workspace != "" {
+		config.metrics.watch(config.workspace)
 	}
+
+	var staging chan struct{}
+	if config.stagingLimit > 0 {
+		staging = make(chan struct{}, config.stagingLimit)
+	}
+
+	return withAsyncTeardown(Platform{
+		initialize:  dockerInitializeProcess{initialize: initialize, instrumentation: config.instrumentation},
+		deployments: config.deployments,
+		runID:       config.runID,
+		Deploy:      dockerDeployProcess{setup: setup, stage: stage, start: start, staging: staging, instrumentation: config.instrumentation, logs: config.logs, deployments: config.deployments, artifacts: config.artifacts, runID: config.runID, droplets: config.droplets, scaler: config.scaler, controller: config.controller, clock: config.clock, stagingRetries: config.stagingRetries, traffic: config.traffic, runtimeLogs: config.runtimeLogs, snapshotter: config.snapshotter, workspace: config.workspace, zstdDroplets: config.zstdDroplets, sbom: config.sbom, scanner: config.scanner, scanImages: config.scanImages, teardown: teardown},
+		Delete:      dockerDeleteProcess{teardown: teardown, instrumentation: config.instrumentation, deployments: config.deployments, artifacts: config.artifacts, runID: config.runID, traffic: config.traffic, runtimeLogs: config.runtimeLogs},
+	}, config)
 }
 
 type dockerInitializeProcess struct {
-	initialize docker.InitializePhase
+	initialize      docker.InitializePhase
+	instrumentation instrumentation
 }
 
 func (p dockerInitializeProcess) Execute(buildpacks ...Buildpack) error {
@@ -35,15 +57,45 @@ func (p dockerInitializeProcess) Execute(buildpacks ...Buildpack) error {
 		})
 	}
 
-	p.initialize.Run(bps)
-
-	return nil
+	return p.instrumentation.run(context.Background(), "initialize", map[string]string{"platform": Docker}, func(ctx context.Context) error {
+		p.initialize.Run(bps)
+		return nil
+	})
 }
 
 type dockerDeployProcess struct {
-	setup docker.SetupPhase
-	stage docker.StagePhase
-	start docker.StartPhase
+	setup           docker.SetupPhase
+	stage           docker.StagePhase
+	start           docker.StartPhase
+	staging         chan struct{}
+	instrumentation instrumentation
+	logs            logBuffers
+	deployments     *deploymentTracker
+	artifacts       *artifactTracker
+	runID           string
+	droplets        dropletPusher
+	scaler          instanceScaler
+	controller      containerController
+	clock           Clock
+	stagingRetries  int
+	snapshotter     environmentSnapshotter
+	workspace       string
+	zstdDroplets    bool
+	sbom            bool
+	scanner         DropletScanner
+	scanImages      bool
+	teardown        docker.TeardownPhase
+	env             map[string]string
+	traffic         *trafficProxies
+	captureTraffic  bool
+	runtimeLogs     runtimeLogStreams
+	runtimeWriter   io.Writer
+	devMode         bool
+	devPaths        []string
+	profileScripts  map[string]string
+	commands        map[string]func(string) string
+	sidecars        []docker.Sidecar
+	processType     string
 }
 
 func (p dockerDeployProcess) WithBuildpacks(buildpacks ...string) DeployProcess {
@@ -58,6 +110,7 @@ func (p dockerDeployProcess) WithStack(stack string) DeployProcess {
 }
 
 func (p dockerDeployProcess) WithEnv(env map[string]string) DeployProcess {
+	p.env = env
 	p.setup = p.setup.WithEnv(env)
 	p.start = p.start.WithEnv(env)
 	return p
@@ -79,43 +132,574 @@ func (p dockerDeployProcess) WithServices(services map[string]Service) DeployPro
 	return p
 }
 
+func (p dockerDeployProcess) WithCredentials(credentials map[string]interface{}) DeployProcess {
+	p.start = p.start.WithCredentials(credentials)
+	return p
+}
+
+func (p dockerDeployProcess) WithStagingContainerReuse() DeployProcess {
+	p.setup = p.setup.WithStagingContainerReuse()
+	return p
+}
+
+func (p dockerDeployProcess) WithStackSetup(setup func(exec Execer) error) DeployProcess {
+	p.setup = p.setup.WithStackSetup(func(exec docker.Execer) error { return setup(exec) })
+	p.start = p.start.WithCustomizedStack()
+	return p
+}
+
+func (p dockerDeployProcess) WithTrafficCapture() DeployProcess {
+	p.captureTraffic = true
+	return p
+}
+
+func (p dockerDeployProcess) WithDevMode(paths ...string) DeployProcess {
+	p.devMode = true
+	p.devPaths = paths
+	return p
+}
+
+func (p dockerDeployProcess) WithUser(uid, gid int) DeployProcess {
+	p.start = p.start.WithUser(uid, gid)
+	return p
+}
+
+func (p dockerDeployProcess) WithReadOnlyRootFS() DeployProcess {
+	p.start = p.start.WithReadOnlyRootFS()
+	return p
+}
+
+func (p dockerDeployProcess) WithSecurityProfile(profile SecurityProfile) DeployProcess {
+	security := docker.SecurityProfile(profile)
+	p.setup = p.setup.WithSecurityProfile(security)
+	p.start = p.start.WithSecurityProfile(security)
+	return p
+}
+
+func (p dockerDeployProcess) WithHealthCheckPolling(polling HealthCheckPolling) DeployProcess {
+	p.start = p.start.WithHealthCheckPolling(docker.HealthCheckPolling{
+		InitialDelay:   polling.InitialDelay,
+		Interval:       polling.Interval,
+		RequestTimeout: polling.RequestTimeout,
+		Timeout:        polling.Timeout,
+	})
+	return p
+}
+
+func (p dockerDeployProcess) WithHealthCheck(healthCheckType, endpoint string, timeout time.Duration) DeployProcess {
+	p.start = p.start.WithHealthCheck(docker.HealthCheck{
+		Type:     healthCheckType,
+		Endpoint: endpoint,
+		Timeout:  timeout,
+	})
+	return p
+}
+
+func (p dockerDeployProcess) WithSidecars(sidecars ...Sidecar) DeployProcess {
+	p.sidecars = nil
+	for _, sidecar := range sidecars {
+		p.sidecars = append(p.sidecars, docker.Sidecar{
+			Name:         sidecar.Name,
+			Command:      sidecar.Command,
+			ProcessTypes: sidecar.ProcessTypes,
+		})
+	}
+
+	return p
+}
+
+func (p dockerDeployProcess) WithProfileScript(name, contents string) DeployProcess {
+	scripts := map[string]string{name: contents}
+	for key, value := range p.profileScripts {
+		if _, ok := scripts[key]; !ok {
+			scripts[key] = value
+		}
+	}
+
+	p.profileScripts = scripts
+	p.start = p.start.WithProfileScripts(scripts)
+	return p
+}
+
+func (p dockerDeployProcess) WithProcessCommand(processType string, command func(original string) string) DeployProcess {
+	commands := map[string]func(string) string{processType: command}
+	for key, value := range p.commands {
+		if _, ok := commands[key]; !ok {
+			commands[key] = value
+		}
+	}
+
+	p.commands = commands
+	p.start = p.start.WithProcessCommands(commands)
+	return p
+}
+
+func (p dockerDeployProcess) WithProcessType(processType string) DeployProcess {
+	p.processType = processType
+	p.start = p.start.WithProcessType(processType)
+	return p
+}
+
+func (p dockerDeployProcess) WithMetadata(metadata Metadata) DeployProcess {
+	return p
+}
+
+func (p dockerDeployProcess) WithAppFeature(name string, enabled bool) DeployProcess {
+	return p
+}
+
+func (p dockerDeployProcess) WithManifest(path string) DeployProcess {
+	return p
+}
+
+func (p dockerDeployProcess) WithManifestVars(vars map[string]string, varsFiles ...string) DeployProcess {
+	return p
+}
+
+func (p dockerDeployProcess) WithRuntimeLogWriter(w io.Writer) DeployProcess {
+	p.runtimeWriter = w
+	return p
+}
+
 func (p dockerDeployProcess) Execute(name, path string) (Deployment, fmt.Stringer, error) {
-	ctx := context.Background()
-	logs := bytes.NewBuffer(nil)
+	return p.execute(context.Background(), name, path)
+}
+
+func (p dockerDeployProcess) ExecuteFromReader(ctx context.Context, logs io.Writer, name string, bits io.Reader) (Deployment, error) {
+	p.setup = p.setup.WithSource(bits)
+	p.logs = p.logs.withWriter(logs)
+
+	deployment, _, err := p.execute(ctx, name, "")
+	return deployment, err
+}
 
-	containerID, err := p.setup.Run(ctx, logs, name, path)
+func (p dockerDeployProcess) execute(ctx context.Context, name, path string) (deployment Deployment, output fmt.Stringer, err error) {
+	logs, err := p.logs.create(name)
 	if err != nil {
-		return Deployment{}, logs, fmt.Errorf("failed to run setup phase: %w\n\nOutput:\n%s", err, logs)
+		return Deployment{}, nil, err
+	}
+	defer func() {
+		closeErr := logs.close()
+		if err == nil && closeErr != nil {
+			deployment, err = Deployment{}, closeErr
+		}
+	}()
+
+	if p.artifacts.enabled() {
+		timings := &phaseTimings{}
+		p.instrumentation = p.instrumentation.withHook(timings.hook())
+		defer func() {
+			if err != nil {
+				err = p.artifacts.write(name, Docker, logs, p.env, timings, err, func(dir string) error {
+					err := copyManifest(manifestPath(p.workspace, namespaced(p.runID, name)), dir)
+					if err != nil {
+						return err
+					}
+
+					return p.teardown.Archive(ctx, namespaced(p.runID, name), dir)
+				})
+			}
+		}()
+	}
+
+	if p.devMode {
+		mounts, err := devMounts(path, p.devPaths)
+		if err != nil {
+			return Deployment{}, logs, err
+		}
+
+		p.start = p.start.WithDevMounts(mounts)
 	}
 
-	command, err := p.stage.Run(ctx, logs, containerID, name)
+	labels := map[string]string{"platform": Docker, "app": name}
+	p.deployments.add(name)
+	defer func() { p.artifacts.record(name, logs, err) }()
+
+	stackDigest, result, staging, err := p.build(ctx, logs, labels, namespaced(p.runID, name), path)
 	if err != nil {
-		return Deployment{}, logs, fmt.Errorf("failed to run stage phase: %w\n\nOutput:\n%s", err, logs)
+		return Deployment{}, logs, err
+	}
+
+	// Sidecars declared by the buildpacks run alongside those requested by
+	// the test, as they do on Cloud Foundry.
+	var sidecars []docker.Sidecar
+	sidecars = append(sidecars, result.Sidecars...)
+	sidecars = append(sidecars, p.sidecars...)
+	if len(sidecars) > 0 {
+		p.start = p.start.WithSidecars(sidecars)
 	}
 
-	externalURL, internalURL, err := p.start.Run(ctx, logs, name, command)
+	command := result.Command
+	if p.processType != "" {
+		var ok bool
+		command, ok = result.Processes[p.processType]
+		if !ok {
+			return Deployment{}, logs, fmt.Errorf("failed to run start phase: app has no %q process type\n\nOutput:\n%s", p.processType, logs)
+		}
+	}
+
+	var externalURL, internalURL string
+	err = p.instrumentation.run(ctx, "start", labels, func(ctx context.Context) (err error) {
+		externalURL, internalURL, err = p.start.Run(ctx, logs, namespaced(p.runID, name), command)
+		return err
+	})
 	if err != nil {
 		return Deployment{}, logs, fmt.Errorf("failed to run start phase: %w\n\nOutput:\n%s", err, logs)
 	}
 
-	return Deployment{
+	deployment = Deployment{
 		Name:        name,
 		ExternalURL: externalURL,
 		InternalURL: internalURL,
-	}, logs, nil
+		StackDigest: stackDigest,
+		Staging:     staging,
+		manifest:    manifestPath(p.workspace, namespaced(p.runID, name)),
+	}
+
+	if p.sbom {
+		deployment.sbom = filepath.Join(p.workspace, "sboms", fmt.Sprintf("%s.json", namespaced(p.runID, name)))
+	}
+
+	if p.droplets != nil {
+		deployment.container = &deploymentContainer{
+			logs:     logs,
+			name:     namespaced(p.runID, name),
+			droplets: p.droplets,
+		}
+	}
+
+	if p.controller != nil {
+		deployment.controller = &deploymentController{
+			name:       namespaced(p.runID, name),
+			controller: p.controller,
+		}
+	}
+
+	if p.snapshotter != nil {
+		deployment.environment = &deploymentEnvironment{
+			name:        namespaced(p.runID, name),
+			snapshotter: p.snapshotter,
+		}
+	}
+
+	if p.scaler != nil {
+		deployment.scaler = &deploymentScaler{
+			name:   namespaced(p.runID, name),
+			scaler: p.scaler,
+		}
+	}
+
+	if p.scanner != nil {
+		deployment.Scan, err = p.scan(ctx, labels, name)
+		if err != nil {
+			return Deployment{}, logs, fmt.Errorf("failed to scan droplet: %w\n\nOutput:\n%s", err, logs)
+		}
+	}
+
+	if p.captureTraffic {
+		proxy, err := p.traffic.start(name, deployment.ExternalURL)
+		if err != nil {
+			return Deployment{}, logs, err
+		}
+
+		deployment.ExternalURL = proxy.url
+		deployment.traffic = proxy
+	}
+
+	p.runtimeLogs.start(name, namespaced(p.runID, name), p.runtimeWriter)
+
+	return deployment, logs, nil
+}
+
+func (p dockerDeployProcess) scan(ctx context.Context, labels map[string]string, name string) (ScanResult, error) {
+	extension := ".tar.gz"
+	if p.zstdDroplets {
+		extension = ".tar.zst"
+	}
+
+	target := ScanTarget{
+		Name:    name,
+		Droplet: filepath.Join(p.workspace, "droplets", namespaced(p.runID, name)+extension),
+	}
+
+	if p.scanImages && p.droplets != nil {
+		target.Image = fmt.Sprintf("switchblade-scan/%s:latest", namespaced(p.runID, name))
+		err := p.droplets.Snapshot(ctx, namespaced(p.runID, name), target.Image)
+		if err != nil {
+			return ScanResult{}, err
+		}
+	}
+
+	var result ScanResult
+	err := p.instrumentation.run(ctx, "scan", labels, func(ctx context.Context) (err error) {
+		result, err = p.scanner.Scan(target)
+		return err
+	})
+	if err != nil {
+		return ScanResult{}, err
+	}
+
+	return result, nil
+}
+
+func (p dockerDeployProcess) build(ctx context.Context, logs *logBuffer, labels map[string]string, name, path string) (string, docker.StageResult, StagingLog, error) {
+	if p.staging != nil {
+		err := p.instrumentation.run(ctx, "queue", labels, func(ctx context.Context) error {
+			select {
+			case p.staging <- struct{}{}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			return "", docker.StageResult{}, StagingLog{}, err
+		}
+		defer func() { <-p.staging }()
+	}
+
+	for attempt := 1; ; attempt++ {
+		stackDigest, result, staging, err := p.buildOnce(ctx, logs, labels, name, path)
+		if err == nil || attempt > p.stagingRetries || ctx.Err() != nil || !docker.IsTransient(err) {
+			return stackDigest, result, staging, err
+		}
+
+		fmt.Fprintf(logs, "Staging failed with a transient error, retrying (%d of %d): %s\n", attempt, p.stagingRetries, errors.Unwrap(err))
+
+		select {
+		case <-p.after(time.Duration(attempt) * stagingRetryDelay):
+		case <-ctx.Done():
+			return "", docker.StageResult{}, StagingLog{}, ctx.Err()
+		}
+	}
+}
+
+func (p dockerDeployProcess) after(d time.Duration) <-chan time.Time {
+	if p.clock == nil {
+		return time.After(d)
+	}
+
+	return p.clock.After(d)
+}
+
+func (p dockerDeployProcess) buildOnce(ctx context.Context, logs *logBuffer, labels map[string]string, name, path string) (string, docker.StageResult, StagingLog, error) {
+	var containerID, stackDigest string
+	err := p.instrumentation.run(ctx, "setup", labels, func(ctx context.Context) (err error) {
+		containerID, stackDigest, err = p.setup.Run(ctx, logs, name, path)
+		return err
+	})
+	if err != nil {
+		return "", docker.StageResult{}, StagingLog{}, fmt.Errorf("failed to run setup phase: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	var result docker.StageResult
+	recorder := newStagingLogRecorder(p.clock)
+	stopRecording := logs.record(recorder)
+	err = p.instrumentation.run(ctx, "stage", labels, func(ctx context.Context) (err error) {
+		result, err = p.stage.Run(ctx, logs, containerID, name)
+		return err
+	})
+	stopRecording()
+	if err != nil {
+		return "", docker.StageResult{}, StagingLog{}, fmt.Errorf("failed to run stage phase: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	staging := recorder.log()
+	staging.Buildpacks = convertDockerStagedBuildpacks(result)
+
+	return stackDigest, result, staging, nil
+}
+
+func convertDockerStagedBuildpacks(result docker.StageResult) []StagingBuildpack {
+	var buildpacks []StagingBuildpack
+	for _, buildpack := range result.Buildpacks {
+		name := buildpack.Name
+		if name == "" {
+			name = buildpack.Key
+		}
+
+		buildpacks = append(buildpacks, StagingBuildpack{Name: name, Version: buildpack.Version})
+	}
+
+	// Only the final buildpack runs detection, so its output is the only one
+	// the lifecycle reports.
+	if len(buildpacks) > 0 {
+		buildpacks[len(buildpacks)-1].DetectOutput = result.DetectOutput
+	}
+
+	return buildpacks
 }
 
 type dockerDeleteProcess struct {
-	teardown docker.TeardownPhase
+	teardown        docker.TeardownPhase
+	instrumentation instrumentation
+	deployments     *deploymentTracker
+	artifacts       *artifactTracker
+	runID           string
+	traffic         *trafficProxies
+	runtimeLogs     runtimeLogStreams
 }
 
 func (p dockerDeleteProcess) Execute(name string) error {
-	ctx := context.Background()
+	_, err := p.ExecuteWithReport(name)
+	return err
+}
+
+func (p dockerDeleteProcess) ExecuteWithReport(name string) (TeardownReport, error) {
+	return p.executeWithReport(context.Background(), name)
+}
+
+func (p dockerDeleteProcess) executeWithReport(ctx context.Context, name string) (TeardownReport, error) {
+	archiveErr := p.artifacts.archive(name, func(dir string) error {
+		return p.teardown.Archive(ctx, namespaced(p.runID, name), dir)
+	})
+
+	p.runtimeLogs.stop(name)
+
+	var report docker.TeardownReport
+	err := p.instrumentation.run(ctx, "teardown", map[string]string{"platform": Docker, "app": name}, func(ctx context.Context) (err error) {
+		report, err = p.teardown.Run(ctx, namespaced(p.runID, name))
+		return err
+	})
+	if err != nil {
+		return convertDockerReport(report), fmt.Errorf("failed to run teardown phase: %w", err)
+	}
+
+	p.deployments.remove(name)
+
+	err = p.traffic.stop(name)
+	if err != nil {
+		return convertDockerReport(report), err
+	}
+
+	if archiveErr != nil {
+		return convertDockerReport(report), fmt.Errorf("failed to archive artifacts: %w", archiveErr)
+	}
+
+	return convertDockerReport(report), nil
+}
+
+func (p dockerDeleteProcess) DryRun(name string) ([]Resource, error) {
+	resources, err := p.teardown.List(context.Background(), namespaced(p.runID, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teardown resources: %w", err)
+	}
 
-	err := p.teardown.Run(ctx, name)
+	return convertDockerResources(resources), nil
+}
+
+func (p dockerDeleteProcess) ByPrefix(ctx context.Context, prefix string) error {
+	names, err := p.teardown.Names(ctx, namespaced(p.runID, prefix))
+	if err != nil {
+		return fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	for i, name := range names {
+		names[i] = strings.TrimPrefix(name, namespaced(p.runID, ""))
+	}
+
+	return deleteAll(ctx, names, func(ctx context.Context, name string) error {
+		_, err := p.executeWithReport(ctx, name)
+		return err
+	})
+}
+
+type dockerCloseProcess struct {
+	pool   *docker.StagingPool
+	reaper io.Closer
+}
+
+func (p dockerCloseProcess) Execute() error {
+	if p.pool != nil {
+		err := p.pool.Drain(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to drain staging pool: %w", err)
+		}
+	}
+
+	if p.reaper != nil {
+		err := p.reaper.Close()
+		if err != nil {
+			return fmt.Errorf("failed to close reaper session: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type dockerGCProcess struct {
+	collector docker.GarbageCollector
+}
+
+func (p dockerGCProcess) Execute(ctx context.Context, olderThan time.Duration) error {
+	err := p.collector.Run(ctx, olderThan)
+	if err != nil {
+		return fmt.Errorf("failed to collect garbage: %w", err)
+	}
+
+	return nil
+}
+
+func (p dockerGCProcess) DryRun(ctx context.Context, olderThan time.Duration) ([]Resource, error) {
+	resources, err := p.collector.List(ctx, olderThan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list garbage: %w", err)
+	}
+
+	return convertDockerResources(resources), nil
+}
+
+type dockerInterruptProcess struct {
+	collector docker.GarbageCollector
+}
+
+func (p dockerInterruptProcess) Execute(ctx context.Context) error {
+	err := p.collector.RemoveRun(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to remove run resources: %w", err)
+	}
+
+	return nil
+}
+
+type dockerRecoverProcess struct {
+	recovery docker.Recovery
+}
+
+func (p dockerRecoverProcess) Execute(ctx context.Context) error {
+	err := p.recovery.Run(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to run teardown phase: %w", err)
+		return fmt.Errorf("failed to recover: %w", err)
 	}
 
 	return nil
 }
+
+func namespaced(runID, name string) string {
+	if runID == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s-%s", runID, name)
+}
+
+func convertDockerResources(resources []docker.Resource) []Resource {
+	var converted []Resource
+	for _, resource := range resources {
+		converted = append(converted, Resource(resource))
+	}
+
+	return converted
+}
+
+func convertDockerReport(report docker.TeardownReport) TeardownReport {
+	converted := TeardownReport{
+		Removed: convertDockerResources(report.Removed),
+		Missing: convertDockerResources(report.Missing),
+	}
+	for _, failure := range report.Failed {
+		converted.Failed = append(converted.Failed, TeardownFailure{Resource: Resource(failure.Resource), Error: failure.Error})
+	}
+
+	return converted
+}
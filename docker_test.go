@@ -1,15 +1,25 @@
 package switchblade_test
 
 import (
+	"bytes"
 	gocontext "context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/pprof"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/cloudfoundry/switchblade"
 	"github.com/cloudfoundry/switchblade/fakes"
 	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/errdefs"
 	"github.com/sclevine/spec"
 
 	. "github.com/cloudfoundry/switchblade/matchers"
@@ -18,7 +28,8 @@ import (
 
 func testDocker(t *testing.T, context spec.G, it spec.S) {
 	var (
-		Expect = NewWithT(t).Expect
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
 
 		platform switchblade.Platform
 
@@ -67,14 +78,14 @@ func testDocker(t *testing.T, context spec.G, it spec.S) {
 
 	context("Deploy", func() {
 		it.Before(func() {
-			setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, error) {
+			setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
 				fmt.Fprintln(logs, "Setting up...")
-				return "some-container-id", nil
+				return "some-container-id", "sha256:some-digest", nil
 			}
 
-			stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, containerID, name string) (string, error) {
+			stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, containerID, name string) (docker.StageResult, error) {
 				fmt.Fprintln(logs, "Staging...")
-				return "some-command", nil
+				return docker.StageResult{Command: "some-command"}, nil
 			}
 
 			start.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, command string) (string, string, error) {
@@ -83,6 +94,381 @@ func testDocker(t *testing.T, context spec.G, it spec.S) {
 			}
 		})
 
+		context("WithSecurityProfile", func() {
+			it.Before(func() {
+				setup.WithSecurityProfileCall.Returns.SetupPhase = setup
+				start.WithSecurityProfileCall.Returns.StartPhase = start
+			})
+
+			it("applies the profile to the staging and running containers", func() {
+				profile := switchblade.SecurityProfile{
+					Seccomp:  "/some/seccomp.json",
+					AppArmor: "some-apparmor-profile",
+					CapAdd:   []string{"NET_ADMIN"},
+					CapDrop:  []string{"ALL"},
+				}
+
+				_, _, err := platform.Deploy.WithSecurityProfile(profile).Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(setup.WithSecurityProfileCall.Receives.Profile).To(Equal(docker.SecurityProfile(profile)))
+				Expect(start.WithSecurityProfileCall.Receives.Profile).To(Equal(docker.SecurityProfile(profile)))
+			})
+		})
+
+		context("WithReadOnlyRootFS", func() {
+			it.Before(func() {
+				start.WithReadOnlyRootFSCall.Returns.StartPhase = start
+			})
+
+			it("runs the app with a read-only root filesystem", func() {
+				_, _, err := platform.Deploy.WithReadOnlyRootFS().Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(start.WithReadOnlyRootFSCall.CallCount).To(Equal(1))
+				Expect(start.RunCall.CallCount).To(Equal(1))
+			})
+		})
+
+		context("WithProcessCommand", func() {
+			it.Before(func() {
+				start.WithProcessCommandsCall.Returns.StartPhase = start
+			})
+
+			it("overrides the command of that process type when the app starts", func() {
+				_, _, err := platform.Deploy.
+					WithProcessCommand("web", func(command string) string { return "strace -f " + command }).
+					WithProcessCommand("worker", func(string) string { return "some-worker-command" }).
+					Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				commands := start.WithProcessCommandsCall.Receives.Commands
+				Expect(commands).To(HaveLen(2))
+				Expect(commands["web"]("some-command")).To(Equal("strace -f some-command"))
+				Expect(commands["worker"]("some-command")).To(Equal("some-worker-command"))
+				Expect(start.RunCall.CallCount).To(Equal(1))
+			})
+		})
+
+		context("WithSidecars", func() {
+			it.Before(func() {
+				start.WithSidecarsCall.Returns.StartPhase = start
+				stage.RunCall.Stub = nil
+				stage.RunCall.Returns.Result = docker.StageResult{
+					Command: "some-command",
+					Sidecars: []docker.Sidecar{
+						{Name: "buildpack-sidecar", Command: "./buildpack-sidecar", ProcessTypes: []string{"web"}},
+					},
+				}
+			})
+
+			it("runs the requested sidecars alongside those declared by the buildpacks", func() {
+				_, _, err := platform.Deploy.
+					WithSidecars(switchblade.Sidecar{
+						Name:         "config-server",
+						Command:      "./config-server",
+						Memory:       "64M",
+						ProcessTypes: []string{"web"},
+					}).
+					Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(start.WithSidecarsCall.Receives.Sidecars).To(Equal([]docker.Sidecar{
+					{Name: "buildpack-sidecar", Command: "./buildpack-sidecar", ProcessTypes: []string{"web"}},
+					{Name: "config-server", Command: "./config-server", ProcessTypes: []string{"web"}},
+				}))
+				Expect(start.RunCall.Receives.Command).To(Equal("some-command"))
+			})
+		})
+
+		context("WithProcessType", func() {
+			it.Before(func() {
+				start.WithProcessTypeCall.Returns.StartPhase = start
+				stage.RunCall.Stub = nil
+				stage.RunCall.Returns.Result = docker.StageResult{
+					Command: "some-command",
+					Processes: map[string]string{
+						"web":    "some-command",
+						"worker": "some-worker-command",
+					},
+				}
+			})
+
+			it("starts the command of that process type", func() {
+				_, _, err := platform.Deploy.WithProcessType("worker").Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(start.WithProcessTypeCall.Receives.ProcessType).To(Equal("worker"))
+				Expect(start.RunCall.Receives.Command).To(Equal("some-worker-command"))
+			})
+
+			context("failure cases", func() {
+				context("when the app has no such process type", func() {
+					it("returns an error", func() {
+						_, _, err := platform.Deploy.WithProcessType("task").Execute("some-app", "/some/path/to/my/app")
+						Expect(err).To(MatchError(ContainSubstring(`failed to run start phase: app has no "task" process type`)))
+
+						Expect(start.RunCall.CallCount).To(Equal(0))
+					})
+				})
+			})
+		})
+
+		context("WithProfileScript", func() {
+			it.Before(func() {
+				start.WithProfileScriptsCall.Returns.StartPhase = start
+			})
+
+			it("places the scripts in the app's profile.d directory", func() {
+				_, _, err := platform.Deploy.
+					WithProfileScript("some-script.sh", "export SOME_VARIABLE=some-value").
+					WithProfileScript("other-script.sh", "export OTHER_VARIABLE=other-value").
+					WithProfileScript("some-script.sh", "export SOME_VARIABLE=updated-value").
+					Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(start.WithProfileScriptsCall.CallCount).To(Equal(3))
+				Expect(start.WithProfileScriptsCall.Receives.Scripts).To(Equal(map[string]string{
+					"some-script.sh":  "export SOME_VARIABLE=updated-value",
+					"other-script.sh": "export OTHER_VARIABLE=other-value",
+				}))
+				Expect(start.RunCall.CallCount).To(Equal(1))
+			})
+		})
+
+		context("WithUser", func() {
+			it.Before(func() {
+				start.WithUserCall.Returns.StartPhase = start
+			})
+
+			it("runs the app as the given user", func() {
+				_, _, err := platform.Deploy.WithUser(1001, 1002).Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(start.WithUserCall.Receives.Uid).To(Equal(1001))
+				Expect(start.WithUserCall.Receives.Gid).To(Equal(1002))
+				Expect(start.RunCall.CallCount).To(Equal(1))
+			})
+		})
+
+		context("WithDevMode", func() {
+			var source string
+
+			it.Before(func() {
+				var err error
+				source, err = os.MkdirTemp("", "source")
+				Expect(err).NotTo(HaveOccurred())
+
+				start.WithDevMountsCall.Returns.StartPhase = start
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(source)).To(Succeed())
+			})
+
+			it("mounts the source into the running container", func() {
+				_, _, err := platform.Deploy.WithDevMode().Execute("some-app", source)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(start.WithDevMountsCall.Receives.Mounts).To(Equal(map[string]string{
+					"/home/vcap/app": source,
+				}))
+				Expect(start.RunCall.CallCount).To(Equal(1))
+			})
+
+			context("when subdirectories are given", func() {
+				it("mounts only those subdirectories", func() {
+					_, _, err := platform.Deploy.WithDevMode("lib", "views/partials").Execute("some-app", source)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(start.WithDevMountsCall.Receives.Mounts).To(Equal(map[string]string{
+						"/home/vcap/app/lib":            filepath.Join(source, "lib"),
+						"/home/vcap/app/views/partials": filepath.Join(source, "views", "partials"),
+					}))
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the source is not a directory", func() {
+					it("returns an error", func() {
+						path := filepath.Join(source, "app.zip")
+						Expect(os.WriteFile(path, []byte("some-content"), 0600)).To(Succeed())
+
+						_, _, err := platform.Deploy.WithDevMode().Execute("some-app", path)
+						Expect(err).To(MatchError(fmt.Sprintf("failed to enable dev mode: %s is not a directory", path)))
+						Expect(setup.RunCall.CallCount).To(Equal(0))
+					})
+				})
+
+				context("when a subdirectory is outside of the source", func() {
+					it("returns an error", func() {
+						_, _, err := platform.Deploy.WithDevMode("../other").Execute("some-app", source)
+						Expect(err).To(MatchError("failed to enable dev mode: ../other is not a subdirectory of the source"))
+					})
+				})
+
+				context("when deploying from a stream", func() {
+					it("returns an error", func() {
+						_, err := platform.Deploy.WithDevMode().ExecuteFromReader(gocontext.Background(), nil, "some-app", strings.NewReader(""))
+						Expect(err).To(MatchError("failed to enable dev mode: a source directory is required"))
+					})
+				})
+			})
+		})
+
+		context("when staging prints buildpack output", func() {
+			it.Before(func() {
+				stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, containerID, name string) (docker.StageResult, error) {
+					fmt.Fprintln(logs, "-----> Go Buildpack version 1.10.0")
+					fmt.Fprintln(logs, "-----> Installing go 1.20.1")
+					fmt.Fprintln(logs, "       Download [https://example.com/go.tgz]")
+					fmt.Fprintln(logs, "-----> Running: go build")
+					fmt.Fprintln(logs, "Exit status 0")
+					return docker.StageResult{
+						Command: "some-command",
+						Buildpacks: []docker.StagedBuildpack{
+							{Key: "some-buildpack"},
+							{Key: "go_buildpack", Name: "go", Version: "1.10.0"},
+						},
+						DetectOutput: "go",
+					}, nil
+				}
+
+				clock := &fakes.Clock{}
+				now := time.Unix(0, 0)
+				clock.NowCall.Stub = func() time.Time {
+					now = now.Add(time.Second)
+					return now
+				}
+
+				platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithClock(clock))
+			})
+
+			it("parses the staging logs into timed sections", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(deployment.Staging).To(Equal(switchblade.StagingLog{
+					Duration: 4 * time.Second,
+					Sections: []switchblade.StagingSection{
+						{
+							Buildpack: "Go",
+							Version:   "1.10.0",
+							Duration:  4 * time.Second,
+							Phases: []switchblade.StagingPhase{
+								{
+									Name:     "Installing go 1.20.1",
+									Lines:    []string{"Download [https://example.com/go.tgz]"},
+									Duration: 2 * time.Second,
+								},
+								{
+									Name:     "Running: go build",
+									Lines:    []string{"Exit status 0"},
+									Duration: time.Second,
+								},
+							},
+						},
+					},
+					Buildpacks: []switchblade.StagingBuildpack{
+						{Name: "some-buildpack"},
+						{Name: "go", Version: "1.10.0", DetectOutput: "go"},
+					},
+				}))
+
+				buildpack, ok := deployment.Staging.Detected()
+				Expect(ok).To(BeTrue())
+				Expect(buildpack).To(Equal(switchblade.StagingBuildpack{Name: "go", Version: "1.10.0", DetectOutput: "go"}))
+			})
+		})
+
+		context("when a droplet pusher is not configured", func() {
+			it("returns an error when pushing the droplet", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = deployment.PushDroplet("registry.example.com/some-app:latest")
+				Expect(err).To(MatchError("failed to push droplet for some-app: pushing droplets is not supported by this platform"))
+			})
+
+			it("returns an error when taking a snapshot", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = deployment.Snapshot("some-app-snapshot:latest")
+				Expect(err).To(MatchError("failed to snapshot some-app: snapshots are not supported by this platform"))
+			})
+
+			it("returns an error when reading the sbom", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = deployment.SBOM()
+				Expect(err).To(MatchError("failed to read sbom for some-app: sboms are not enabled for this platform"))
+			})
+
+			it("returns an error when reading the manifest", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = deployment.Manifest()
+				Expect(err).To(MatchError("failed to read manifest for some-app: manifests are not recorded by this platform"))
+			})
+
+			it("returns an error when killing the container", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = deployment.Kill("SIGKILL")
+				Expect(err).To(MatchError("failed to kill some-app: killing containers is not supported by this platform"))
+			})
+
+			it("returns an error when restarting the container", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = deployment.RestartContainer()
+				Expect(err).To(MatchError("failed to restart some-app: restarting containers is not supported by this platform"))
+			})
+
+			it("returns an error when rerunning the app", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = deployment.Rerun()
+				Expect(err).To(MatchError("failed to rerun some-app: rerunning is not supported by this platform"))
+			})
+
+			it("returns an error when snapshotting the environment", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = deployment.EnvironmentSnapshot()
+				Expect(err).To(MatchError("failed to snapshot environment for some-app: environment snapshots are not supported by this platform"))
+			})
+
+			it("returns an error when scaling", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = deployment.Scale(2)
+				Expect(err).To(MatchError("failed to scale some-app: scaling is not supported by this platform"))
+			})
+
+			it("stops the probe when scaling fails", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				var stopped bool
+				err = deployment.ScaleDuring(func(ctx gocontext.Context) error {
+					<-ctx.Done()
+					stopped = true
+					return ctx.Err()
+				}, 0, 2, 1)
+				Expect(err).To(MatchError("failed to scale some-app: scaling is not supported by this platform"))
+				Expect(stopped).To(BeTrue())
+			})
+		})
+
 		it("builds and runs the app", func() {
 			deployment, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
 			Expect(err).NotTo(HaveOccurred())
@@ -96,6 +482,7 @@ func testDocker(t *testing.T, context spec.G, it spec.S) {
 				Name:        "some-app",
 				ExternalURL: "some-external-url",
 				InternalURL: "some-internal-url",
+				StackDigest: "sha256:some-digest",
 			}))
 
 			Expect(setup.RunCall.Receives.Ctx).To(Equal(gocontext.Background()))
@@ -163,87 +550,681 @@ func testDocker(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
-		context("failure cases", func() {
-			context("when the setup phase errors", func() {
-				it.Before(func() {
-					setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, error) {
-						fmt.Fprintln(logs, "Setting up...")
-						return "", errors.New("setup phase errored")
-					}
+		context("WithCredentials", func() {
+			it("serves those credentials to the app during start", func() {
+				platform.Deploy.WithCredentials(map[string]interface{}{
+					"some-credential": "some-value",
 				})
+				Expect(start.WithCredentialsCall.Receives.Credentials).To(Equal(map[string]interface{}{
+					"some-credential": "some-value",
+				}))
+			})
+		})
 
-				it("returns an error and the build logs", func() {
-					_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
-					Expect(err).To(MatchError(ContainSubstring("failed to run setup phase: setup phase errored")))
-					Expect(err).To(MatchError(ContainSubstring("Setting up...")))
-					Expect(logs).To(ContainLines(
-						"Setting up...",
-					))
-					Expect(logs).NotTo(ContainLines(
-						"Staging...",
-						"Starting...",
-					))
+		context("ExecuteFromReader", func() {
+			it.Before(func() {
+				setup.WithSourceCall.Returns.SetupPhase = setup
+			})
+
+			it("builds and runs the app from the given source stream", func() {
+				logs := bytes.NewBuffer(nil)
+				bits := strings.NewReader("some-tar-stream")
+
+				deployment, err := platform.Deploy.ExecuteFromReader(gocontext.Background(), logs, "some-app", bits)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployment.ExternalURL).To(Equal("some-external-url"))
+
+				Expect(setup.WithSourceCall.Receives.Source).To(Equal(bits))
+				Expect(setup.RunCall.Receives.Path).To(Equal(""))
+
+				Expect(logs).To(ContainLines(
+					"Setting up...",
+					"Staging...",
+					"Starting...",
+				))
+			})
+		})
+
+		context("WithStagingContainerReuse", func() {
+			it("reuses the prepared staging container", func() {
+				platform.Deploy.WithStagingContainerReuse()
+				Expect(setup.WithStagingContainerReuseCall.CallCount).To(Equal(1))
+			})
+		})
+
+		context("WithStackSetup", func() {
+			it("customizes the stack for staging and running", func() {
+				var outputs []string
+				platform.Deploy.WithStackSetup(func(exec switchblade.Execer) error {
+					output, err := exec.Exec("update-ca-certificates")
+					outputs = append(outputs, output)
+					return err
 				})
+				Expect(start.WithCustomizedStackCall.CallCount).To(Equal(1))
+
+				execer := &fakes.Execer{}
+				execer.ExecCall.Returns.Output = "some-output"
+				Expect(setup.WithStackSetupCall.Receives.Setup(execer)).To(Succeed())
+				Expect(execer.ExecCall.Receives.Command).To(Equal([]string{"update-ca-certificates"}))
+				Expect(outputs).To(Equal([]string{"some-output"}))
 			})
+		})
 
-			context("when the stage phase errors", func() {
+		context("WithHealthCheckPolling", func() {
+			it("polls the app before reporting it as started", func() {
+				platform.Deploy.WithHealthCheckPolling(switchblade.HealthCheckPolling{
+					InitialDelay:   time.Second,
+					Interval:       2 * time.Second,
+					RequestTimeout: 3 * time.Second,
+					Timeout:        time.Minute,
+				})
+				Expect(start.WithHealthCheckPollingCall.Receives.Polling).To(Equal(docker.HealthCheckPolling{
+					InitialDelay:   time.Second,
+					Interval:       2 * time.Second,
+					RequestTimeout: 3 * time.Second,
+					Timeout:        time.Minute,
+				}))
+			})
+		})
+
+		context("WithHealthCheck", func() {
+			it("checks the app's health before reporting it as started", func() {
+				platform.Deploy.WithHealthCheck(switchblade.HealthCheckPort, "", 2*time.Minute)
+				Expect(start.WithHealthCheckCall.Receives.HealthCheck).To(Equal(docker.HealthCheck{
+					Type:    "port",
+					Timeout: 2 * time.Minute,
+				}))
+			})
+		})
+
+		context("WithPhaseHooks", func() {
+			type hookInvocation struct {
+				Event  string
+				Phase  string
+				Labels map[string]string
+				Err    error
+			}
+
+			var invocations []hookInvocation
+
+			it.Before(func() {
+				invocations = nil
+
+				platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithPhaseHooks(switchblade.PhaseHook{
+					Start: func(phase string, labels map[string]string) {
+						invocations = append(invocations, hookInvocation{Event: "start", Phase: phase, Labels: labels})
+					},
+					Stop: func(phase string, labels map[string]string, duration time.Duration, err error) {
+						invocations = append(invocations, hookInvocation{Event: "stop", Phase: phase, Labels: labels, Err: err})
+					},
+				}))
+			})
+
+			it("calls the hooks around each phase", func() {
+				_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				labels := map[string]string{"platform": "docker", "app": "some-app"}
+				Expect(invocations).To(Equal([]hookInvocation{
+					{Event: "start", Phase: "setup", Labels: labels},
+					{Event: "stop", Phase: "setup", Labels: labels},
+					{Event: "start", Phase: "stage", Labels: labels},
+					{Event: "stop", Phase: "stage", Labels: labels},
+					{Event: "start", Phase: "start", Labels: labels},
+					{Event: "stop", Phase: "start", Labels: labels},
+				}))
+			})
+
+			context("when a phase errors", func() {
 				it.Before(func() {
-					stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, containerID, name string) (string, error) {
-						fmt.Fprintln(logs, "Staging...")
-						return "", errors.New("stage phase errored")
-					}
+					stage.RunCall.Stub = nil
+					stage.RunCall.Returns.Err = errors.New("stage phase errored")
 				})
 
-				it("returns an error and the build logs", func() {
-					_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
-					Expect(err).To(MatchError(ContainSubstring("failed to run stage phase: stage phase errored")))
-					Expect(err).To(MatchError(ContainSubstring("Staging...")))
-					Expect(logs).To(ContainLines(
-						"Setting up...",
-						"Staging...",
-					))
-					Expect(logs).NotTo(ContainLines(
-						"Starting...",
-					))
+				it("passes the error to the stop hook", func() {
+					_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+					Expect(err).To(HaveOccurred())
+
+					Expect(invocations).To(HaveLen(4))
+					Expect(invocations[3].Phase).To(Equal("stage"))
+					Expect(invocations[3].Err).To(MatchError("stage phase errored"))
 				})
 			})
+		})
 
-			context("when the start phase errors", func() {
+		context("WithLogLimit", func() {
+			var path string
+
+			it.Before(func() {
+				platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithLogLimit(16))
+
+				setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
+					fmt.Fprintln(logs, "Setting up a very chatty application...")
+					return "some-container-id", "", nil
+				}
+
+				stage.RunCall.Stub = nil
+				stage.RunCall.Returns.Err = errors.New("stage phase errored")
+			})
+
+			it.After(func() {
+				if path != "" {
+					Expect(os.Remove(path)).To(Succeed())
+				}
+			})
+
+			it("keeps the tail of the output in memory and spills the rest to disk", func() {
+				_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).To(MatchError(ContainSubstring("failed to run stage phase: stage phase errored")))
+
+				matches := regexp.MustCompile(`full output written to (\S+)\]`).FindStringSubmatch(logs.String())
+				Expect(matches).To(HaveLen(2))
+				path = matches[1]
+
+				Expect(logs.String()).To(HaveSuffix("]\n application...\n"))
+				Expect(err).To(MatchError(ContainSubstring(path)))
+
+				content, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("Setting up a very chatty application...\n"))
+			})
+
+			context("when the output keeps coming after the limit", func() {
 				it.Before(func() {
-					start.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, command string) (string, string, error) {
-						fmt.Fprintln(logs, "Starting...")
-						return "", "", errors.New("start phase errored")
+					setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
+						for i := 0; i < 100; i++ {
+							fmt.Fprintf(logs, "line %03d\n", i)
+						}
+
+						return "some-container-id", "", nil
 					}
 				})
 
-				it("returns an error and the build logs", func() {
+				it("writes every line to disk and keeps only the tail", func() {
 					_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
-					Expect(err).To(MatchError(ContainSubstring("failed to run start phase: start phase errored")))
-					Expect(err).To(MatchError(ContainSubstring("Starting...")))
-					Expect(logs).To(ContainLines(
-						"Setting up...",
-						"Staging...",
-						"Starting...",
-					))
+					Expect(err).To(HaveOccurred())
+
+					matches := regexp.MustCompile(`full output written to (\S+)\]`).FindStringSubmatch(logs.String())
+					Expect(matches).To(HaveLen(2))
+					path = matches[1]
+
+					Expect(logs.String()).To(HaveSuffix("]\nne 098\nline 099\n"))
+
+					content, err := os.ReadFile(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(strings.Count(string(content), "\n")).To(Equal(100))
+					Expect(string(content)).To(HavePrefix("line 000\n"))
+					Expect(string(content)).To(HaveSuffix("line 099\n"))
 				})
 			})
 		})
-	})
 
-	context("Delete", func() {
-		it("deletes the app", func() {
-			err := platform.Delete.Execute("some-app")
-			Expect(err).NotTo(HaveOccurred())
+		context("WithLogSinks", func() {
+			var dir string
 
-			Expect(teardown.RunCall.Receives.Ctx).To(Equal(gocontext.Background()))
-			Expect(teardown.RunCall.Receives.Name).To(Equal("some-app"))
+			it.Before(func() {
+				var err error
+				dir, err = os.MkdirTemp("", "log-sinks")
+				Expect(err).NotTo(HaveOccurred())
+
+				platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithLogSinks(switchblade.FileLogSink(dir)))
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			})
+
+			it("writes the deployment logs to each sink", func() {
+				_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(dir, "some-app.log"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal(logs.String()))
+				Expect(string(content)).To(ContainSubstring("Starting..."))
+			})
+
+			context("failure cases", func() {
+				context("when a sink cannot be opened", func() {
+					it.Before(func() {
+						Expect(os.WriteFile(filepath.Join(dir, "file"), nil, 0600)).To(Succeed())
+						platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithLogSinks(switchblade.FileLogSink(filepath.Join(dir, "file"))))
+					})
+
+					it("returns an error", func() {
+						_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+						Expect(err).To(MatchError(ContainSubstring("failed to open log sink: failed to create log sink directory:")))
+						Expect(setup.RunCall.CallCount).To(Equal(0))
+					})
+				})
+			})
 		})
 
-		context("failure cases", func() {
-			context("when the teardown phase errors", func() {
+		context("WithStagingLimit", func() {
+			var (
+				m       sync.Mutex
+				queued  []string
+				active  int
+				highest int
+			)
+
+			it.Before(func() {
+				queued = nil
+				active = 0
+				highest = 0
+
+				platform = switchblade.NewDocker(initialize, setup, stage, start, teardown,
+					switchblade.WithStagingLimit(1),
+					switchblade.WithPhaseHooks(switchblade.PhaseHook{
+						Stop: func(phase string, labels map[string]string, duration time.Duration, err error) {
+							if phase == "queue" {
+								m.Lock()
+								queued = append(queued, labels["app"])
+								m.Unlock()
+							}
+						},
+					}),
+				)
+
+				setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
+					m.Lock()
+					defer m.Unlock()
+
+					active++
+					if active > highest {
+						highest = active
+					}
+
+					return "some-container-id", "", nil
+				}
+
+				stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, containerID, name string) (docker.StageResult, error) {
+					time.Sleep(10 * time.Millisecond)
+
+					m.Lock()
+					defer m.Unlock()
+
+					active--
+
+					return docker.StageResult{Command: "some-command"}, nil
+				}
+			})
+
+			it("bounds the number of concurrent stagings and reports the time spent queued", func() {
+				var wg sync.WaitGroup
+				for _, name := range []string{"some-app", "other-app", "another-app"} {
+					wg.Add(1)
+					go func(name string) {
+						defer wg.Done()
+
+						_, _, err := platform.Deploy.Execute(name, "/some/path/to/my/app")
+						Expect(err).NotTo(HaveOccurred())
+					}(name)
+				}
+				wg.Wait()
+
+				Expect(highest).To(Equal(1))
+				Expect(queued).To(ConsistOf("some-app", "other-app", "another-app"))
+			})
+
+			context("when the deadline passes while queued", func() {
+				var release chan struct{}
+
 				it.Before(func() {
-					teardown.RunCall.Stub = nil
-					teardown.RunCall.Returns.Error = errors.New("teardown phase errored")
+					release = make(chan struct{})
+					stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, containerID, name string) (docker.StageResult, error) {
+						<-release
+						return docker.StageResult{Command: "some-command"}, nil
+					}
+				})
+
+				it("returns the context error without staging", func() {
+					done := make(chan error, 1)
+					go func() {
+						_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+						done <- err
+					}()
+					Eventually(func() int {
+						m.Lock()
+						defer m.Unlock()
+						return active
+					}).Should(Equal(1))
+
+					ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 10*time.Millisecond)
+					defer cancel()
+
+					_, err := platform.Deploy.ExecuteFromReader(ctx, nil, "other-app", strings.NewReader(""))
+					Expect(err).To(MatchError(gocontext.DeadlineExceeded))
+
+					m.Lock()
+					Expect(queued).To(ConsistOf("some-app", "other-app"))
+					m.Unlock()
+
+					close(release)
+					Expect(<-done).NotTo(HaveOccurred())
+					Expect(setup.RunCall.CallCount).To(Equal(1))
+				})
+			})
+
+			context("when a staging fails", func() {
+				it.Before(func() {
+					stage.RunCall.Stub = nil
+					stage.RunCall.Returns.Err = errors.New("stage phase errored")
+				})
+
+				it("releases its slot for the next deployment", func() {
+					_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("stage phase errored")))
+
+					_, _, err = platform.Deploy.Execute("other-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("stage phase errored")))
+				})
+			})
+		})
+
+		context("WithStagingRetries", func() {
+			var (
+				clock  *fakes.Clock
+				errs   []error
+				delays []time.Duration
+			)
+
+			it.Before(func() {
+				delays = nil
+				clock = &fakes.Clock{}
+				clock.AfterCall.Stub = func(d time.Duration) <-chan time.Time {
+					delays = append(delays, d)
+
+					c := make(chan time.Time, 1)
+					c <- time.Time{}
+					return c
+				}
+
+				errs = []error{
+					fmt.Errorf("failed to copy image pull logs: %w", io.ErrUnexpectedEOF),
+					fmt.Errorf("failed to create network: %w", errdefs.Conflict(errors.New("network already exists"))),
+				}
+				setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
+					if len(errs) > 0 {
+						err := errs[0]
+						errs = errs[1:]
+						return "", "", err
+					}
+
+					return "some-container-id", "sha256:some-digest", nil
+				}
+
+				platform = switchblade.NewDocker(initialize, setup, stage, start, teardown,
+					switchblade.WithStagingRetries(2),
+					switchblade.WithClock(clock),
+				)
+			})
+
+			it("retries transient failures with an increasing delay", func() {
+				_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(setup.RunCall.CallCount).To(Equal(3))
+				Expect(stage.RunCall.CallCount).To(Equal(1))
+				Expect(delays).To(Equal([]time.Duration{2 * time.Second, 4 * time.Second}))
+				Expect(logs).To(ContainLines(
+					"Staging failed with a transient error, retrying (1 of 2): failed to copy image pull logs: unexpected EOF",
+					"Staging failed with a transient error, retrying (2 of 2): failed to create network: network already exists",
+				))
+			})
+
+			context("when the transient failures outlast the retries", func() {
+				it.Before(func() {
+					errs = append(errs, errdefs.System(errors.New("internal server error")))
+				})
+
+				it("returns the last error", func() {
+					_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("failed to run setup phase: internal server error")))
+
+					Expect(setup.RunCall.CallCount).To(Equal(3))
+				})
+			})
+
+			context("when the buildpack fails", func() {
+				it.Before(func() {
+					errs = nil
+					stage.RunCall.Stub = nil
+					stage.RunCall.Returns.Err = errors.New("App staging failed: container exited with non-zero status code (222)")
+				})
+
+				it("does not retry", func() {
+					_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("failed to run stage phase: App staging failed")))
+
+					Expect(setup.RunCall.CallCount).To(Equal(1))
+					Expect(stage.RunCall.CallCount).To(Equal(1))
+					Expect(delays).To(BeEmpty())
+				})
+			})
+
+			context("when retries are not enabled", func() {
+				it.Before(func() {
+					platform = switchblade.NewDocker(initialize, setup, stage, start, teardown)
+				})
+
+				it("returns the first error", func() {
+					_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("failed to run setup phase: failed to copy image pull logs: unexpected EOF")))
+
+					Expect(setup.RunCall.CallCount).To(Equal(1))
+				})
+			})
+		})
+
+		context("WithDropletScanner", func() {
+			var scanner *fakes.DropletScanner
+
+			it.Before(func() {
+				scanner = &fakes.DropletScanner{}
+				scanner.ScanCall.Returns.ScanResult = switchblade.ScanResult{
+					Scanner: "some-scanner",
+					Findings: []switchblade.ScanFinding{
+						{ID: "CVE-2023-0001", Severity: "High", Package: "openssl", Version: "1.1.1"},
+					},
+				}
+
+				platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithDropletScanner(scanner, false), switchblade.WithZstdDroplets())
+			})
+
+			it("scans the droplet and attaches the result to the deployment", func() {
+				deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(scanner.ScanCall.Receives.Target.Name).To(Equal("some-app"))
+				Expect(scanner.ScanCall.Receives.Target.Droplet).To(HaveSuffix(filepath.Join("droplets", "some-app.tar.zst")))
+				Expect(scanner.ScanCall.Receives.Target.Image).To(BeEmpty())
+
+				Expect(deployment.Scan).To(Equal(switchblade.ScanResult{
+					Scanner: "some-scanner",
+					Findings: []switchblade.ScanFinding{
+						{ID: "CVE-2023-0001", Severity: "High", Package: "openssl", Version: "1.1.1"},
+					},
+				}))
+			})
+
+			context("failure cases", func() {
+				context("when the scanner errors", func() {
+					it.Before(func() {
+						scanner.ScanCall.Returns.Error = errors.New("scanner errored")
+					})
+
+					it("returns an error and the build logs", func() {
+						_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+						Expect(err).To(MatchError(ContainSubstring("failed to scan droplet: scanner errored")))
+						Expect(logs).To(ContainLines(
+							"Setting up...",
+							"Staging...",
+							"Starting...",
+						))
+					})
+				})
+			})
+		})
+
+		context("WithProfilerLabels", func() {
+			it.Before(func() {
+				platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithProfilerLabels())
+			})
+
+			it("labels each phase for the profiler", func() {
+				_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				phase, ok := pprof.Label(setup.RunCall.Receives.Ctx, "switchblade.phase")
+				Expect(ok).To(BeTrue())
+				Expect(phase).To(Equal("setup"))
+
+				app, ok := pprof.Label(setup.RunCall.Receives.Ctx, "switchblade.app")
+				Expect(ok).To(BeTrue())
+				Expect(app).To(Equal("some-app"))
+
+				phase, ok = pprof.Label(start.RunCall.Receives.Ctx, "switchblade.phase")
+				Expect(ok).To(BeTrue())
+				Expect(phase).To(Equal("start"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the setup phase errors", func() {
+				it.Before(func() {
+					setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
+						fmt.Fprintln(logs, "Setting up...")
+						return "", "", errors.New("setup phase errored")
+					}
+				})
+
+				it("returns an error and the build logs", func() {
+					_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("failed to run setup phase: setup phase errored")))
+					Expect(err).To(MatchError(ContainSubstring("Setting up...")))
+					Expect(logs).To(ContainLines(
+						"Setting up...",
+					))
+					Expect(logs).NotTo(ContainLines(
+						"Staging...",
+						"Starting...",
+					))
+				})
+			})
+
+			context("when the stage phase errors", func() {
+				it.Before(func() {
+					stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, containerID, name string) (docker.StageResult, error) {
+						fmt.Fprintln(logs, "Staging...")
+						return docker.StageResult{}, errors.New("stage phase errored")
+					}
+				})
+
+				it("returns an error and the build logs", func() {
+					_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("failed to run stage phase: stage phase errored")))
+					Expect(err).To(MatchError(ContainSubstring("Staging...")))
+					Expect(logs).To(ContainLines(
+						"Setting up...",
+						"Staging...",
+					))
+					Expect(logs).NotTo(ContainLines(
+						"Starting...",
+					))
+				})
+			})
+
+			context("when the start phase errors", func() {
+				it.Before(func() {
+					start.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, command string) (string, string, error) {
+						fmt.Fprintln(logs, "Starting...")
+						return "", "", errors.New("start phase errored")
+					}
+				})
+
+				it("returns an error and the build logs", func() {
+					_, logs, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("failed to run start phase: start phase errored")))
+					Expect(err).To(MatchError(ContainSubstring("Starting...")))
+					Expect(logs).To(ContainLines(
+						"Setting up...",
+						"Staging...",
+						"Starting...",
+					))
+				})
+			})
+
+			context("when the app container exits during startup", func() {
+				it.Before(func() {
+					start.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, command string) (string, string, error) {
+						return "", "", docker.StartError{ExitCode: 1, Logs: "some-app crashed"}
+					}
+				})
+
+				it("returns a start error", func() {
+					_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+
+					var startErr switchblade.StartError
+					Expect(errors.As(err, &startErr)).To(BeTrue())
+					Expect(startErr.ExitCode).To(Equal(int64(1)))
+					Expect(startErr.Logs).To(Equal("some-app crashed"))
+				})
+			})
+		})
+	})
+
+	context("Delete", func() {
+		it("deletes the app", func() {
+			err := platform.Delete.Execute("some-app")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(teardown.RunCall.Receives.Ctx).To(Equal(gocontext.Background()))
+			Expect(teardown.RunCall.Receives.Name).To(Equal("some-app"))
+		})
+
+		context("ExecuteWithReport", func() {
+			it.Before(func() {
+				teardown.RunCall.Returns.TeardownReport = docker.TeardownReport{
+					Removed: []docker.Resource{{Kind: "container", Name: "some-app", ID: "some-app"}},
+					Missing: []docker.Resource{{Kind: "file", Name: "some-source"}},
+				}
+			})
+
+			it("returns a summary of the teardown", func() {
+				report, err := platform.Delete.ExecuteWithReport("some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report).To(Equal(switchblade.TeardownReport{
+					Removed: []switchblade.Resource{{Kind: "container", Name: "some-app", ID: "some-app"}},
+					Missing: []switchblade.Resource{{Kind: "file", Name: "some-source"}},
+				}))
+			})
+
+			context("when the teardown phase errors", func() {
+				it.Before(func() {
+					teardown.RunCall.Returns.TeardownReport = docker.TeardownReport{
+						Failed: []docker.TeardownFailure{{Resource: docker.Resource{Kind: "volume", Name: "some-volume"}, Error: "volume is in use"}},
+					}
+					teardown.RunCall.Returns.Error = errors.New("volume is in use")
+				})
+
+				it("returns the failures alongside the error", func() {
+					report, err := platform.Delete.ExecuteWithReport("some-app")
+					Expect(err).To(MatchError("failed to run teardown phase: volume is in use"))
+
+					Expect(report.Failed).To(Equal([]switchblade.TeardownFailure{
+						{Resource: switchblade.Resource{Kind: "volume", Name: "some-volume"}, Error: "volume is in use"},
+					}))
+				})
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the teardown phase errors", func() {
+				it.Before(func() {
+					teardown.RunCall.Stub = nil
+					teardown.RunCall.Returns.Error = errors.New("teardown phase errored")
 				})
 
 				it("returns an error", func() {
@@ -253,4 +1234,410 @@ func testDocker(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 	})
+
+	context("WithFailureArtifacts", func() {
+		var artifacts string
+
+		it.Before(func() {
+			var err error
+			artifacts, err = os.MkdirTemp("", "artifacts")
+			Expect(err).NotTo(HaveOccurred())
+
+			setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
+				fmt.Fprintln(logs, "Setting up...")
+				if name == "failing-app" {
+					return "", "", errors.New("setup phase errored")
+				}
+
+				return "some-container-id", "", nil
+			}
+
+			platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithFailureArtifacts(artifacts))
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(artifacts)).To(Succeed())
+		})
+
+		it("archives the artifacts of failed deployments before deleting them", func() {
+			_, _, err := platform.Deploy.Execute("failing-app", "/some/path/to/my/app")
+			Expect(err).To(HaveOccurred())
+
+			Expect(teardown.ArchiveCall.CallCount).To(Equal(1))
+
+			Expect(platform.Delete.Execute("failing-app")).To(Succeed())
+
+			Expect(teardown.ArchiveCall.CallCount).To(Equal(2))
+			Expect(teardown.ArchiveCall.Receives.Name).To(Equal("failing-app"))
+			Expect(teardown.ArchiveCall.Receives.Dir).To(Equal(filepath.Join(artifacts, "failing-app")))
+			Expect(teardown.RunCall.CallCount).To(Equal(1))
+
+			content, err := os.ReadFile(filepath.Join(artifacts, "failing-app", "deploy.log"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("Setting up...\n"))
+		})
+
+		it("does not archive the artifacts of successful deployments", func() {
+			_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(platform.Delete.Execute("some-app")).To(Succeed())
+
+			Expect(teardown.ArchiveCall.CallCount).To(Equal(0))
+			Expect(filepath.Join(artifacts, "some-app")).NotTo(BeADirectory())
+		})
+
+		context("when the artifacts cannot be archived", func() {
+			it.Before(func() {
+				teardown.ArchiveCall.Returns.Error = errors.New("could not archive")
+			})
+
+			it("still deletes the app and returns an error", func() {
+				_, _, err := platform.Deploy.Execute("failing-app", "/some/path/to/my/app")
+				Expect(err).To(HaveOccurred())
+
+				err = platform.Delete.Execute("failing-app")
+				Expect(err).To(MatchError("failed to archive artifacts: could not archive"))
+				Expect(teardown.RunCall.CallCount).To(Equal(1))
+			})
+		})
+	})
+
+	context("WithFailureArtifacts when a deployment fails", func() {
+		var artifacts string
+
+		it.Before(func() {
+			var err error
+			artifacts, err = os.MkdirTemp("", "artifacts")
+			Expect(err).NotTo(HaveOccurred())
+
+			setup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
+				fmt.Fprintln(logs, "Setting up...")
+				return "some-container-id", "", nil
+			}
+
+			stage.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, containerID, name string) (docker.StageResult, error) {
+				fmt.Fprintln(logs, "Staging...")
+				if name == "failing-app" {
+					return docker.StageResult{}, errors.New("stage phase errored")
+				}
+
+				return docker.StageResult{Command: "some-command"}, nil
+			}
+
+			teardown.ArchiveCall.Stub = func(ctx gocontext.Context, name, dir string) error {
+				return os.WriteFile(filepath.Join(dir, "inspect.json"), []byte("{}"), 0600)
+			}
+
+			setup.WithEnvCall.Returns.SetupPhase = setup
+			start.WithEnvCall.Returns.StartPhase = start
+
+			platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithFailureArtifacts(artifacts))
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(artifacts)).To(Succeed())
+		})
+
+		it("writes a bundle for a failed deployment as soon as it fails", func() {
+			_, _, err := platform.Deploy.WithEnv(map[string]string{"SOME_VARIABLE": "some-value", "GITHUB_TOKEN": "some-token"}).Execute("failing-app", "/some/path/to/my/app")
+			Expect(err).To(MatchError(ContainSubstring("failed to run stage phase: stage phase errored")))
+
+			dir := filepath.Join(artifacts, "failing-app")
+			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("Artifacts:\n[[ATTACHMENT|%s]]", filepath.Join(dir, "deploy.log")))))
+			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("[[ATTACHMENT|%s]]", filepath.Join(dir, "inspect.json")))))
+
+			Expect(teardown.ArchiveCall.Receives.Name).To(Equal("failing-app"))
+			Expect(teardown.ArchiveCall.Receives.Dir).To(Equal(dir))
+			Expect(teardown.RunCall.CallCount).To(Equal(0))
+
+			content, err := os.ReadFile(filepath.Join(dir, "deploy.log"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("Setting up...\nStaging...\n"))
+
+			content, err = os.ReadFile(filepath.Join(dir, "error.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(HavePrefix("failed to run stage phase: stage phase errored"))
+
+			content, err = os.ReadFile(filepath.Join(dir, "deployment.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(MatchJSON(`{
+				"name": "failing-app",
+				"platform": "docker",
+				"env": { "SOME_VARIABLE": "some-value", "GITHUB_TOKEN": "[REDACTED]" }
+			}`))
+
+			content, err = os.ReadFile(filepath.Join(dir, "timings.json"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var timings []struct {
+				Phase string `json:"phase"`
+				Error string `json:"error"`
+			}
+			Expect(json.Unmarshal(content, &timings)).To(Succeed())
+			Expect(timings).To(HaveLen(2))
+			Expect(timings[0].Phase).To(Equal("setup"))
+			Expect(timings[0].Error).To(BeEmpty())
+			Expect(timings[1].Phase).To(Equal("stage"))
+			Expect(timings[1].Error).To(Equal("stage phase errored"))
+		})
+
+		it("does not write a bundle for a successful deployment", func() {
+			_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(teardown.ArchiveCall.CallCount).To(Equal(0))
+			Expect(filepath.Join(artifacts, "some-app")).NotTo(BeADirectory())
+		})
+
+		context("failure cases", func() {
+			context("when the bundle cannot be collected", func() {
+				it.Before(func() {
+					teardown.ArchiveCall.Stub = nil
+					teardown.ArchiveCall.Returns.Error = errors.New("could not archive")
+				})
+
+				it("returns the deployment error along with the bundle error", func() {
+					_, _, err := platform.Deploy.Execute("failing-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("failed to run stage phase: stage phase errored")))
+					Expect(err).To(MatchError(ContainSubstring("failed to write artifact bundle: could not archive")))
+				})
+			})
+		})
+	})
+
+	context("DryRun", func() {
+		it.Before(func() {
+			teardown.ListCall.Returns.ResourceSlice = []docker.Resource{
+				{Kind: "container", Name: "some-app", ID: "some-container-id", Age: time.Hour},
+			}
+		})
+
+		it("lists the resources the teardown would remove without removing them", func() {
+			resources, err := platform.Delete.DryRun("some-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resources).To(Equal([]switchblade.Resource{
+				{Kind: "container", Name: "some-app", ID: "some-container-id", Age: time.Hour},
+			}))
+
+			Expect(teardown.ListCall.Receives.Name).To(Equal("some-app"))
+			Expect(teardown.RunCall.CallCount).To(Equal(0))
+		})
+
+		context("failure cases", func() {
+			context("when the teardown phase cannot list resources", func() {
+				it.Before(func() {
+					teardown.ListCall.Returns.Error = errors.New("could not list")
+				})
+
+				it("returns an error", func() {
+					_, err := platform.Delete.DryRun("some-app")
+					Expect(err).To(MatchError("failed to list teardown resources: could not list"))
+				})
+			})
+		})
+	})
+
+	context("ByPrefix", func() {
+		var deleted []string
+
+		it.Before(func() {
+			deleted = nil
+			teardown.NamesCall.Returns.StringSlice = []string{"nodejs_other-app", "nodejs_some-app"}
+			teardown.RunCall.Stub = func(ctx gocontext.Context, name string) (docker.TeardownReport, error) {
+				deleted = append(deleted, name)
+				return docker.TeardownReport{}, nil
+			}
+		})
+
+		it("deletes every deployment whose name matches the prefix", func() {
+			err := platform.Delete.ByPrefix(gocontext.Background(), "nodejs_")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(teardown.NamesCall.Receives.Prefix).To(Equal("nodejs_"))
+			Expect(deleted).To(Equal([]string{"nodejs_other-app", "nodejs_some-app"}))
+		})
+
+		it("passes the context to the deletion of each deployment", func() {
+			ctx, cancel := gocontext.WithCancel(gocontext.Background())
+			defer cancel()
+
+			teardown.RunCall.Stub = func(ctx gocontext.Context, name string) (docker.TeardownReport, error) {
+				deleted = append(deleted, name)
+				if ctx.Err() != nil {
+					return docker.TeardownReport{}, ctx.Err()
+				}
+
+				cancel()
+				return docker.TeardownReport{}, nil
+			}
+
+			err := platform.Delete.ByPrefix(ctx, "nodejs_")
+			Expect(err).To(MatchError(ContainSubstring("failed to delete nodejs_some-app:")))
+			Expect(err).To(MatchError(ContainSubstring("context canceled")))
+			Expect(deleted).To(Equal([]string{"nodejs_other-app", "nodejs_some-app"}))
+		})
+
+		context("WithRunID", func() {
+			it.Before(func() {
+				teardown.NamesCall.Returns.StringSlice = []string{"some-run-nodejs_some-app"}
+				platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithRunID("some-run"))
+			})
+
+			it("only deletes the deployments from that run", func() {
+				err := platform.Delete.ByPrefix(gocontext.Background(), "nodejs_")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(teardown.NamesCall.Receives.Prefix).To(Equal("some-run-nodejs_"))
+				Expect(deleted).To(Equal([]string{"some-run-nodejs_some-app"}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the deployments cannot be listed", func() {
+				it.Before(func() {
+					teardown.NamesCall.Returns.Error = errors.New("could not list")
+				})
+
+				it("returns an error", func() {
+					err := platform.Delete.ByPrefix(gocontext.Background(), "nodejs_")
+					Expect(err).To(MatchError("failed to list deployments: could not list"))
+				})
+			})
+		})
+	})
+
+	context("WithRunID", func() {
+		it.Before(func() {
+			platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithRunID("some-run"))
+		})
+
+		it("exposes the run ID", func() {
+			Expect(platform.RunID()).To(Equal("some-run"))
+		})
+
+		it("prefixes the app name with the run ID", func() {
+			deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployment.Name).To(Equal("some-app"))
+
+			Expect(setup.RunCall.Receives.Name).To(Equal("some-run-some-app"))
+			Expect(stage.RunCall.Receives.Name).To(Equal("some-run-some-app"))
+			Expect(start.RunCall.Receives.Name).To(Equal("some-run-some-app"))
+
+			_, err = platform.Delete.DryRun("some-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(teardown.ListCall.Receives.Name).To(Equal("some-run-some-app"))
+
+			err = platform.Delete.Execute("some-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(teardown.RunCall.Receives.Name).To(Equal("some-run-some-app"))
+		})
+	})
+
+	context("WithAsyncTeardown", func() {
+		var release chan struct{}
+
+		it.Before(func() {
+			release = make(chan struct{})
+			teardown.RunCall.Stub = func(ctx gocontext.Context, name string) (docker.TeardownReport, error) {
+				<-release
+				if name == "failing-app" {
+					return docker.TeardownReport{}, errors.New("teardown phase errored")
+				}
+
+				return docker.TeardownReport{}, nil
+			}
+
+			platform = switchblade.NewDocker(initialize, setup, stage, start, teardown, switchblade.WithAsyncTeardown())
+		})
+
+		it("returns before the teardown completes and waits for it on request", func() {
+			Expect(platform.Delete.Execute("some-app")).To(Succeed())
+			Expect(platform.Delete.Execute("other-app")).To(Succeed())
+
+			ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			err := platform.WaitForCleanup(ctx)
+			Expect(err).To(MatchError("failed to wait for cleanup: context deadline exceeded"))
+
+			close(release)
+
+			Expect(platform.WaitForCleanup(gocontext.Background())).To(Succeed())
+			Expect(teardown.RunCall.CallCount).To(Equal(2))
+		})
+
+		context("when a teardown fails", func() {
+			it("reports the failure when waiting", func() {
+				Expect(platform.Delete.Execute("failing-app")).To(Succeed())
+
+				close(release)
+
+				err := platform.WaitForCleanup(gocontext.Background())
+				Expect(err).To(MatchError("failed to delete failing-app: failed to run teardown phase: teardown phase errored"))
+
+				Expect(platform.WaitForCleanup(gocontext.Background())).To(Succeed())
+			})
+		})
+	})
+
+	context("Cleanup", func() {
+		it("deletes the apps that were deployed but not deleted", func() {
+			_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = platform.Deploy.Execute("other-app", "/some/path/to/my/app")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(platform.Delete.Execute("other-app")).To(Succeed())
+			Expect(teardown.RunCall.CallCount).To(Equal(1))
+
+			Expect(platform.Cleanup(gocontext.Background())).To(Succeed())
+			Expect(teardown.RunCall.CallCount).To(Equal(2))
+			Expect(teardown.RunCall.Receives.Name).To(Equal("some-app"))
+
+			Expect(platform.Cleanup(gocontext.Background())).To(Succeed())
+			Expect(teardown.RunCall.CallCount).To(Equal(2))
+		})
+
+		context("failure cases", func() {
+			context("when a teardown fails", func() {
+				it.Before(func() {
+					teardown.RunCall.Stub = nil
+					teardown.RunCall.Returns.Error = errors.New("teardown phase errored")
+				})
+
+				it("returns an error and keeps tracking the app", func() {
+					_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+					Expect(err).NotTo(HaveOccurred())
+
+					err = platform.Cleanup(gocontext.Background())
+					Expect(err).To(MatchError("failed to clean up:\nfailed to delete some-app: failed to run teardown phase: teardown phase errored"))
+
+					teardown.RunCall.Returns.Error = nil
+
+					Expect(platform.Cleanup(gocontext.Background())).To(Succeed())
+					Expect(teardown.RunCall.CallCount).To(Equal(2))
+				})
+			})
+		})
+	})
+
+	context("CleanupOnInterrupt", func() {
+		it("returns a function that stops handling signals", func() {
+			stop := switchblade.CleanupOnInterrupt(platform)
+			stop()
+			stop()
+		})
+	})
+
+	context("Close", func() {
+		it("succeeds when there is nothing to release", func() {
+			Expect(platform.Close()).To(Succeed())
+			Expect(platform.WaitForCleanup(gocontext.Background())).To(Succeed())
+			Expect(platform.GC(gocontext.Background(), time.Hour)).To(Succeed())
+			Expect(platform.Recover(gocontext.Background())).To(Succeed())
+		})
+	})
 }
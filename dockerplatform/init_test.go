@@ -0,0 +1,19 @@
+package dockerplatform_test
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega/format"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestDockerPlatform(t *testing.T) {
+	format.MaxLength = 0
+
+	suite := spec.New("switchblade/dockerplatform", spec.Report(report.Terminal{}), spec.Parallel())
+	suite("Networks", testNetworks)
+	suite("Stager", testStager)
+	suite("Starter", testStarter)
+	suite.Run(t)
+}
@@ -0,0 +1,29 @@
+package dockerplatform
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type LifecycleBuilder struct {
+	builder docker.LifecycleBuilder
+}
+
+func NewLifecycleBuilder(cache string) LifecycleBuilder {
+	return LifecycleBuilder{
+		builder: docker.NewLifecycleManager(pexec.NewExecutable("go"), docker.NewTGZArchiver(), cache),
+	}
+}
+
+func NewPrebuiltLifecycleBuilder(uri, version string) LifecycleBuilder {
+	return LifecycleBuilder{
+		builder: docker.NewPrebuiltLifecycleManager(docker.NewTGZArchiver(), uri, version),
+	}
+}
+
+func (b LifecycleBuilder) Build(ctx context.Context, workspace string) (string, error) {
+	return b.builder.Build(ctx, docker.BuildpackAppLifecycleRepoURL, filepath.Join(workspace, "lifecycle"))
+}
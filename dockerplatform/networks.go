@@ -0,0 +1,33 @@
+package dockerplatform
+
+import (
+	"context"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/client"
+)
+
+const (
+	BridgeNetworkName   = docker.BridgeNetworkName
+	InternalNetworkName = docker.InternalNetworkName
+)
+
+type Networks struct {
+	manager docker.NetworkManager
+}
+
+func NewNetworks(client client.CommonAPIClient) Networks {
+	return Networks{manager: docker.NewNetworkManager(client)}
+}
+
+func (n Networks) Create(ctx context.Context, name string, internal bool) error {
+	return n.manager.Create(ctx, name, "bridge", internal)
+}
+
+func (n Networks) Connect(ctx context.Context, containerID, name string) error {
+	return n.manager.Connect(ctx, containerID, name)
+}
+
+func (n Networks) Delete(ctx context.Context, name string) error {
+	return n.manager.Delete(ctx, name)
+}
@@ -0,0 +1,88 @@
+package dockerplatform_test
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/dockerplatform"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testNetworks(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		networks dockerplatform.Networks
+		server   *httptest.Server
+		requests []string
+		existing []types.NetworkResource
+		m        sync.Mutex
+	)
+
+	it.Before(func() {
+		requests = nil
+		existing = nil
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			m.Lock()
+			defer m.Unlock()
+
+			path := req.URL.Path[strings.Index(req.URL.Path[1:], "/")+1:]
+			requests = append(requests, fmt.Sprintf("%s %s", req.Method, path))
+
+			switch {
+			case req.Method == http.MethodGet && path == "/networks":
+				_ = json.NewEncoder(w).Encode(existing)
+			case req.Method == http.MethodPost && path == "/networks/create":
+				var request types.NetworkCreateRequest
+				_ = json.NewDecoder(req.Body).Decode(&request)
+				existing = append(existing, types.NetworkResource{ID: "some-network-id", Name: request.Name, Internal: request.Internal})
+				_ = json.NewEncoder(w).Encode(types.NetworkCreateResponse{ID: "some-network-id"})
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+
+		apiClient, err := client.NewClientWithOpts(client.WithHost(strings.Replace(server.URL, "http://", "tcp://", 1)), client.WithVersion("1.41"))
+		Expect(err).NotTo(HaveOccurred())
+
+		networks = dockerplatform.NewNetworks(apiClient)
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	it("creates, connects, and deletes networks", func() {
+		ctx := gocontext.Background()
+
+		Expect(networks.Create(ctx, "some-network", true)).To(Succeed())
+		Expect(existing).To(Equal([]types.NetworkResource{
+			{ID: "some-network-id", Name: "some-network", Internal: true},
+		}))
+
+		Expect(networks.Create(ctx, "some-network", true)).To(Succeed())
+		Expect(networks.Connect(ctx, "some-container-id", "some-network")).To(Succeed())
+		Expect(networks.Delete(ctx, "some-network")).To(Succeed())
+
+		Expect(requests).To(Equal([]string{
+			"GET /networks",
+			"POST /networks/create",
+			"GET /networks",
+			"GET /networks",
+			"POST /networks/some-network-id/connect",
+			"GET /networks",
+			"DELETE /networks/some-network-id",
+		}))
+	})
+}
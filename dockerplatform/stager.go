@@ -0,0 +1,104 @@
+package dockerplatform
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/client"
+)
+
+type Buildpack struct {
+	Name string
+	URI  string
+}
+
+type StageResult struct {
+	Command     string
+	Droplet     string
+	Signature   string
+	StackDigest string
+}
+
+type Stager struct {
+	setup      docker.SetupPhase
+	stage      docker.Stage
+	initialize docker.Initialize
+	workspace  string
+	signed     bool
+}
+
+func NewStager(client client.CommonAPIClient, lifecycle LifecycleBuilder, networks Networks, workspace, stack, token string) Stager {
+	archiver := docker.NewTGZArchiver()
+	registry := docker.NewBuildpacksRegistry("https://api.github.com", token)
+	buildpacks := docker.NewBuildpacksManager(archiver, docker.NewBuildpacksCache(filepath.Join(workspace, "buildpacks-cache")), registry)
+
+	return Stager{
+		setup:      docker.NewSetup(client, lifecycle.builder, buildpacks, archiver, networks.manager, workspace, stack),
+		stage:      docker.NewStage(client, archiver, workspace),
+		initialize: docker.NewInitialize(registry),
+		workspace:  workspace,
+	}
+}
+
+func (s Stager) Initialize(buildpacks ...Buildpack) {
+	var bps []docker.Buildpack
+	for _, buildpack := range buildpacks {
+		bps = append(bps, docker.Buildpack(buildpack))
+	}
+
+	s.initialize.Run(bps)
+}
+
+func (s Stager) WithBuildpacks(buildpacks ...string) Stager {
+	s.setup = s.setup.WithBuildpacks(buildpacks...)
+	return s
+}
+
+func (s Stager) WithStack(stack string) Stager {
+	s.setup = s.setup.WithStack(stack)
+	return s
+}
+
+func (s Stager) WithEnv(env map[string]string) Stager {
+	s.setup = s.setup.WithEnv(env)
+	return s
+}
+
+func (s Stager) WithoutInternetAccess() Stager {
+	s.setup = s.setup.WithoutInternetAccess()
+	return s
+}
+
+func (s Stager) WithDropletSigning(key crypto.Signer) Stager {
+	s.stage = s.stage.WithDropletSigning(key)
+	s.signed = true
+	return s
+}
+
+func (s Stager) Stage(ctx context.Context, logs io.Writer, name, path string) (StageResult, error) {
+	containerID, stackDigest, err := s.setup.Run(ctx, logs, name, path)
+	if err != nil {
+		return StageResult{}, fmt.Errorf("failed to set up staging container: %w", err)
+	}
+
+	staged, err := s.stage.Run(ctx, logs, containerID, name)
+	if err != nil {
+		return StageResult{}, fmt.Errorf("failed to stage: %w", err)
+	}
+
+	result := StageResult{
+		Command:     staged.Command,
+		Droplet:     filepath.Join(s.workspace, "droplets", fmt.Sprintf("%s.tar.gz", name)),
+		StackDigest: stackDigest,
+	}
+
+	if s.signed {
+		result.Signature = result.Droplet + docker.SignatureExtension
+	}
+
+	return result, nil
+}
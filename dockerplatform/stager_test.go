@@ -0,0 +1,242 @@
+package dockerplatform_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	gocontext "context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/dockerplatform"
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testStager(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		stager    dockerplatform.Stager
+		server    *httptest.Server
+		workspace string
+		source    string
+		requests  []string
+		failures  map[string]int
+		exitCode  int
+		m         sync.Mutex
+	)
+
+	archive := func(files map[string]string) []byte {
+		buffer := bytes.NewBuffer(nil)
+		tw := tar.NewWriter(buffer)
+		for name, content := range files {
+			Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content))})).To(Succeed())
+			_, err := tw.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(tw.Close()).To(Succeed())
+
+		return buffer.Bytes()
+	}
+
+	it.Before(func() {
+		var err error
+		workspace, err = os.MkdirTemp("", "workspace")
+		Expect(err).NotTo(HaveOccurred())
+
+		source, err = os.MkdirTemp("", "source")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(source, "some-file"), []byte("some-content"), 0600)).To(Succeed())
+
+		buildpack := bytes.NewBuffer(nil)
+		zw := zip.NewWriter(buildpack)
+		file, err := zw.Create("bin/compile")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.Write([]byte("#!/bin/bash"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(zw.Close()).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(workspace, "some-buildpack.zip"), buildpack.Bytes(), 0600)).To(Succeed())
+
+		lifecycle := bytes.NewBuffer(nil)
+		gw := gzip.NewWriter(lifecycle)
+		_, err = gw.Write(archive(map[string]string{"builder": "builder-binary", "launcher": "launcher-binary"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gw.Close()).To(Succeed())
+
+		requests = nil
+		failures = map[string]int{}
+		exitCode = 0
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			m.Lock()
+			defer m.Unlock()
+
+			if req.URL.Path == "/lifecycle.tgz" {
+				_, _ = w.Write(lifecycle.Bytes())
+				return
+			}
+
+			path := req.URL.Path[strings.Index(req.URL.Path[1:], "/")+1:]
+			request := fmt.Sprintf("%s %s", req.Method, path)
+			if req.URL.Query().Has("path") {
+				request = fmt.Sprintf("%s?path=%s", request, req.URL.Query().Get("path"))
+			}
+			requests = append(requests, request)
+
+			if status, ok := failures[request]; ok {
+				w.WriteHeader(status)
+				_ = json.NewEncoder(w).Encode(map[string]string{"message": "some-error"})
+				return
+			}
+
+			stat := base64.StdEncoding.EncodeToString([]byte("{}"))
+
+			switch {
+			case req.Method == http.MethodGet && strings.HasPrefix(path, "/images/"):
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(map[string]string{"message": "no such image"})
+			case req.Method == http.MethodGet && path == "/containers/some-app/json":
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(map[string]string{"message": "no such container"})
+			case req.Method == http.MethodGet && path == "/networks":
+				_ = json.NewEncoder(w).Encode([]types.NetworkResource{
+					{ID: "bridge-network-id", Name: "bridge"},
+					{ID: "internal-network-id", Name: "switchblade-internal", Internal: true},
+				})
+			case req.Method == http.MethodPost && path == "/containers/create":
+				_ = json.NewEncoder(w).Encode(container.CreateResponse{ID: "some-container-id"})
+			case req.Method == http.MethodPost && path == "/containers/some-container-id/wait":
+				_ = json.NewEncoder(w).Encode(container.WaitResponse{StatusCode: int64(exitCode)})
+			case req.Method == http.MethodGet && path == "/containers/some-container-id/archive":
+				w.Header().Set("X-Docker-Container-Path-Stat", stat)
+
+				switch req.URL.Query().Get("path") {
+				case "/tmp/droplet":
+					_, _ = w.Write(archive(map[string]string{"droplet": "droplet-content"}))
+				case "/tmp/result.json":
+					_, _ = w.Write(archive(map[string]string{"result.json": `{"processes": [{"type": "web", "command": "some-command"}]}`}))
+				default:
+					_, _ = w.Write(archive(nil))
+				}
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+
+		apiClient, err := client.NewClientWithOpts(client.WithHost(strings.Replace(server.URL, "http://", "tcp://", 1)), client.WithVersion("1.41"))
+		Expect(err).NotTo(HaveOccurred())
+
+		lifecycleBuilder := dockerplatform.NewPrebuiltLifecycleBuilder(fmt.Sprintf("%s/lifecycle.tgz", server.URL), "1.0.0")
+		stager = dockerplatform.NewStager(apiClient, lifecycleBuilder, dockerplatform.NewNetworks(apiClient), workspace, "cflinuxfs4", "")
+
+		var buildpacks []dockerplatform.Buildpack
+		for _, name := range docker.DefaultBuildpacks {
+			buildpacks = append(buildpacks, dockerplatform.Buildpack{
+				Name: strings.ReplaceAll(fmt.Sprintf("%s-buildpack", name), "-", "_"),
+				URI:  filepath.Join(workspace, "some-buildpack.zip"),
+			})
+		}
+		stager.Initialize(buildpacks...)
+	})
+
+	it.After(func() {
+		server.Close()
+		Expect(os.RemoveAll(workspace)).To(Succeed())
+		Expect(os.RemoveAll(source)).To(Succeed())
+	})
+
+	context("Stage", func() {
+		it("stages the app and returns the droplet", func() {
+			result, err := stager.
+				WithBuildpacks("go_buildpack").
+				Stage(gocontext.Background(), bytes.NewBuffer(nil), "some-app", source)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(dockerplatform.StageResult{
+				Command: "some-command",
+				Droplet: filepath.Join(workspace, "droplets", "some-app.tar.gz"),
+			}))
+
+			content, err := os.ReadFile(result.Droplet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("droplet-content"))
+
+			Expect(requests).To(ContainElements(
+				"POST /containers/create",
+				"PUT /containers/some-container-id/archive?path=/",
+				"POST /containers/some-container-id/start",
+				"GET /containers/some-container-id/archive?path=/tmp/droplet",
+				"GET /containers/some-container-id/archive?path=/tmp/result.json",
+				"DELETE /containers/some-container-id",
+			))
+		})
+
+		context("WithDropletSigning", func() {
+			var key ed25519.PrivateKey
+
+			it.Before(func() {
+				var err error
+				_, key, err = ed25519.GenerateKey(rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("returns a signature that verifies the droplet", func() {
+				result, err := stager.
+					WithBuildpacks("go_buildpack").
+					WithDropletSigning(key).
+					Stage(gocontext.Background(), bytes.NewBuffer(nil), "some-app", source)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Signature).To(Equal(filepath.Join(workspace, "droplets", "some-app.tar.gz.sig")))
+				Expect(result.Signature).To(BeARegularFile())
+				Expect(docker.VerifyDroplet(result.Droplet, key.Public())).To(Succeed())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the staging container cannot be set up", func() {
+				it.Before(func() {
+					failures["POST /containers/create"] = http.StatusInternalServerError
+				})
+
+				it("returns an error", func() {
+					_, err := stager.
+						WithBuildpacks("go_buildpack").
+						Stage(gocontext.Background(), bytes.NewBuffer(nil), "some-app", source)
+					Expect(err).To(MatchError(ContainSubstring("failed to set up staging container: failed to create staging container:")))
+				})
+			})
+
+			context("when staging fails", func() {
+				it.Before(func() {
+					exitCode = 1
+				})
+
+				it("returns an error", func() {
+					_, err := stager.
+						WithBuildpacks("go_buildpack").
+						Stage(gocontext.Background(), bytes.NewBuffer(nil), "some-app", source)
+					Expect(err).To(MatchError("failed to stage: App staging failed: container exited with non-zero status code (1)"))
+
+					Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,112 @@
+package dockerplatform
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/client"
+)
+
+type Starter struct {
+	start     docker.StartPhase
+	lifecycle LifecycleBuilder
+	networks  Networks
+	workspace string
+	verifier  crypto.PublicKey
+}
+
+func NewStarter(client client.CommonAPIClient, lifecycle LifecycleBuilder, networks Networks, workspace, stack string) Starter {
+	return Starter{
+		start:     docker.NewStart(client, networks.manager, workspace, stack),
+		lifecycle: lifecycle,
+		networks:  networks,
+		workspace: workspace,
+	}
+}
+
+func (s Starter) WithStack(stack string) Starter {
+	s.start = s.start.WithStack(stack)
+	return s
+}
+
+func (s Starter) WithEnv(env map[string]string) Starter {
+	s.start = s.start.WithEnv(env)
+	return s
+}
+
+func (s Starter) WithReadOnlyRootFS() Starter {
+	s.start = s.start.WithReadOnlyRootFS()
+	return s
+}
+
+func (s Starter) WithDropletVerification(key crypto.PublicKey) Starter {
+	s.verifier = key
+	return s
+}
+
+func (s Starter) Start(ctx context.Context, logs io.Writer, name, droplet, command string) (externalURL, internalURL string, err error) {
+	if s.verifier != nil {
+		err = docker.VerifyDroplet(droplet, s.verifier)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	err = copyFile(droplet, filepath.Join(s.workspace, "droplets", fmt.Sprintf("%s.tar.gz", name)))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to import droplet: %w", err)
+	}
+
+	err = os.RemoveAll(filepath.Join(s.workspace, "droplets", fmt.Sprintf("%s.tar.zst", name)))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to remove stale droplet: %w", err)
+	}
+
+	lifecycle, err := s.lifecycle.Build(ctx, s.workspace)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build lifecycle: %w", err)
+	}
+
+	err = copyFile(lifecycle, filepath.Join(s.workspace, "lifecycle", "lifecycle.tar.gz"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to import lifecycle: %w", err)
+	}
+
+	err = s.networks.Create(ctx, InternalNetworkName, true)
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.start.Run(ctx, logs, name, command)
+}
+
+func copyFile(source, destination string) error {
+	if source == destination {
+		return nil
+	}
+
+	input, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	err = os.MkdirAll(filepath.Dir(destination), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	output, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	_, err = io.Copy(output, input)
+	return err
+}
@@ -0,0 +1,107 @@
+package dockerplatform_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/dockerplatform"
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/client"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testStarter(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		starter   dockerplatform.Starter
+		workspace string
+	)
+
+	it.Before(func() {
+		var err error
+		workspace, err = os.MkdirTemp("", "workspace")
+		Expect(err).NotTo(HaveOccurred())
+
+		apiClient, err := client.NewClientWithOpts(client.WithHost("tcp://127.0.0.1:0"), client.WithVersion("1.41"))
+		Expect(err).NotTo(HaveOccurred())
+
+		lifecycle := dockerplatform.NewPrebuiltLifecycleBuilder("http://127.0.0.1:0/lifecycle.tgz", "1.0.0")
+		starter = dockerplatform.NewStarter(apiClient, lifecycle, dockerplatform.NewNetworks(apiClient), workspace, "cflinuxfs4")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workspace)).To(Succeed())
+	})
+
+	context("Start", func() {
+		context("WithDropletVerification", func() {
+			var key ed25519.PrivateKey
+
+			it.Before(func() {
+				var err error
+				_, key, err = ed25519.GenerateKey(rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.WriteFile(filepath.Join(workspace, "droplet.tar.gz"), []byte("droplet-content"), 0600)).To(Succeed())
+				Expect(docker.SignDroplet(filepath.Join(workspace, "droplet.tar.gz"), key)).To(Succeed())
+			})
+
+			it("imports a droplet with a valid signature", func() {
+				_, _, err := starter.
+					WithDropletVerification(key.Public()).
+					Start(gocontext.Background(), bytes.NewBuffer(nil), "some-app", filepath.Join(workspace, "droplet.tar.gz"), "some-command")
+				Expect(err).To(MatchError(ContainSubstring("failed to build lifecycle:")))
+
+				Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).To(BeARegularFile())
+			})
+
+			context("when the droplet has been modified after signing", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workspace, "droplet.tar.gz"), []byte("tampered-content"), 0600)).To(Succeed())
+				})
+
+				it("refuses to import the droplet", func() {
+					_, _, err := starter.
+						WithDropletVerification(key.Public()).
+						Start(gocontext.Background(), bytes.NewBuffer(nil), "some-app", filepath.Join(workspace, "droplet.tar.gz"), "some-command")
+					Expect(err).To(MatchError("failed to verify droplet: signature does not match droplet.tar.gz"))
+
+					Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+				})
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the droplet does not exist", func() {
+				it("returns an error", func() {
+					_, _, err := starter.Start(gocontext.Background(), bytes.NewBuffer(nil), "some-app", filepath.Join(workspace, "missing.tar.gz"), "some-command")
+					Expect(err).To(MatchError(ContainSubstring("failed to import droplet: open")))
+					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				})
+			})
+
+			context("when the lifecycle cannot be built", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workspace, "droplet.tar.gz"), []byte("droplet-content"), 0600)).To(Succeed())
+				})
+
+				it("returns an error after importing the droplet", func() {
+					_, _, err := starter.Start(gocontext.Background(), bytes.NewBuffer(nil), "some-app", filepath.Join(workspace, "droplet.tar.gz"), "some-command")
+					Expect(err).To(MatchError(ContainSubstring("failed to build lifecycle:")))
+
+					content, err := os.ReadFile(filepath.Join(workspace, "droplets", "some-app.tar.gz"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("droplet-content"))
+				})
+			})
+		})
+	})
+}
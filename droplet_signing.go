@@ -0,0 +1,10 @@
+package switchblade
+
+import "crypto"
+
+func WithDropletSigning(key crypto.Signer) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.dropletKey = key
+		return config
+	}
+}
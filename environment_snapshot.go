@@ -0,0 +1,121 @@
+package switchblade
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type EnvironmentSnapshot struct {
+	Env   map[string]string
+	Files map[string]string
+}
+
+type EnvironmentChange struct {
+	Name   string
+	Before string
+	After  string
+}
+
+type EnvironmentDiff struct {
+	Env   []EnvironmentChange
+	Files []EnvironmentChange
+}
+
+type environmentSnapshotter interface {
+	Snapshot(ctx context.Context, name string) (env, files map[string]string, err error)
+}
+
+type deploymentEnvironment struct {
+	name        string
+	snapshotter environmentSnapshotter
+}
+
+func (d Deployment) EnvironmentSnapshot() (EnvironmentSnapshot, error) {
+	if d.environment == nil {
+		return EnvironmentSnapshot{}, fmt.Errorf("failed to snapshot environment for %s: environment snapshots are not supported by this platform", d.Name)
+	}
+
+	env, files, err := d.environment.snapshotter.Snapshot(context.Background(), d.environment.name)
+	if err != nil {
+		return EnvironmentSnapshot{}, fmt.Errorf("failed to snapshot environment for %s: %w", d.Name, err)
+	}
+
+	return EnvironmentSnapshot{Env: env, Files: files}, nil
+}
+
+func DiffDeployments(before, after Deployment) (EnvironmentDiff, error) {
+	beforeSnapshot, err := before.EnvironmentSnapshot()
+	if err != nil {
+		return EnvironmentDiff{}, err
+	}
+
+	afterSnapshot, err := after.EnvironmentSnapshot()
+	if err != nil {
+		return EnvironmentDiff{}, err
+	}
+
+	return beforeSnapshot.Diff(afterSnapshot), nil
+}
+
+func (s EnvironmentSnapshot) Diff(other EnvironmentSnapshot) EnvironmentDiff {
+	return EnvironmentDiff{
+		Env:   diffEntries(s.Env, other.Env),
+		Files: diffEntries(s.Files, other.Files),
+	}
+}
+
+func (d EnvironmentDiff) Empty() bool {
+	return len(d.Env) == 0 && len(d.Files) == 0
+}
+
+func (d EnvironmentDiff) String() string {
+	var lines []string
+	for _, section := range []struct {
+		title   string
+		changes []EnvironmentChange
+	}{
+		{title: "env", changes: d.Env},
+		{title: "files", changes: d.Files},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%s:", section.title))
+		for _, change := range section.changes {
+			switch {
+			case change.Before == "":
+				lines = append(lines, fmt.Sprintf("  + %s=%s", change.Name, change.After))
+			case change.After == "":
+				lines = append(lines, fmt.Sprintf("  - %s=%s", change.Name, change.Before))
+			default:
+				lines = append(lines, fmt.Sprintf("  ~ %s: %s -> %s", change.Name, change.Before, change.After))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func diffEntries(before, after map[string]string) []EnvironmentChange {
+	var changes []EnvironmentChange
+	for name, value := range before {
+		if other, ok := after[name]; !ok || other != value {
+			changes = append(changes, EnvironmentChange{Name: name, Before: value, After: after[name]})
+		}
+	}
+
+	for name, value := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, EnvironmentChange{Name: name, After: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
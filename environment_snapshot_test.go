@@ -0,0 +1,89 @@
+package switchblade_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testEnvironmentSnapshot(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		before switchblade.EnvironmentSnapshot
+		after  switchblade.EnvironmentSnapshot
+	)
+
+	it.Before(func() {
+		before = switchblade.EnvironmentSnapshot{
+			Env: map[string]string{
+				"PATH":         "/usr/bin",
+				"RUBY_VERSION": "3.1.2",
+				"REMOVED_KEY":  "some-value",
+			},
+			Files: map[string]string{
+				"/home/vcap/app/Gemfile.lock":    "some-checksum",
+				"/home/vcap/deps/0/bin/ruby":     "ruby-checksum",
+				"/home/vcap/deps/0/bin/old-tool": "old-checksum",
+			},
+		}
+
+		after = switchblade.EnvironmentSnapshot{
+			Env: map[string]string{
+				"PATH":         "/usr/bin",
+				"RUBY_VERSION": "3.2.0",
+				"ADDED_KEY":    "other-value",
+			},
+			Files: map[string]string{
+				"/home/vcap/app/Gemfile.lock": "some-checksum",
+				"/home/vcap/deps/0/bin/ruby":  "other-ruby-checksum",
+			},
+		}
+	})
+
+	context("Diff", func() {
+		it("returns the sorted changes between the snapshots", func() {
+			diff := before.Diff(after)
+			Expect(diff.Empty()).To(BeFalse())
+			Expect(diff.Env).To(Equal([]switchblade.EnvironmentChange{
+				{Name: "ADDED_KEY", After: "other-value"},
+				{Name: "REMOVED_KEY", Before: "some-value"},
+				{Name: "RUBY_VERSION", Before: "3.1.2", After: "3.2.0"},
+			}))
+			Expect(diff.Files).To(Equal([]switchblade.EnvironmentChange{
+				{Name: "/home/vcap/deps/0/bin/old-tool", Before: "old-checksum"},
+				{Name: "/home/vcap/deps/0/bin/ruby", Before: "ruby-checksum", After: "other-ruby-checksum"},
+			}))
+
+			Expect(diff.String()).To(Equal(`env:
+  + ADDED_KEY=other-value
+  - REMOVED_KEY=some-value
+  ~ RUBY_VERSION: 3.1.2 -> 3.2.0
+files:
+  - /home/vcap/deps/0/bin/old-tool=old-checksum
+  ~ /home/vcap/deps/0/bin/ruby: ruby-checksum -> other-ruby-checksum`))
+		})
+
+		context("when the snapshots are the same", func() {
+			it("returns an empty diff", func() {
+				diff := before.Diff(before)
+				Expect(diff.Empty()).To(BeTrue())
+				Expect(diff.String()).To(BeEmpty())
+			})
+		})
+	})
+
+	context("DiffDeployments", func() {
+		context("failure cases", func() {
+			context("when the platform does not support environment snapshots", func() {
+				it("returns an error", func() {
+					_, err := switchblade.DiffDeployments(switchblade.Deployment{Name: "some-app"}, switchblade.Deployment{Name: "other-app"})
+					Expect(err).To(MatchError("failed to snapshot environment for some-app: environment snapshots are not supported by this platform"))
+				})
+			})
+		})
+	})
+}
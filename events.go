@@ -0,0 +1,74 @@
+package switchblade
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+type Event struct {
+	Time      time.Time `json:"time"`
+	Source    string    `json:"source"`
+	Operation string    `json:"operation"`
+	Resource  string    `json:"resource"`
+	Status    string    `json:"status"`
+	Duration  float64   `json:"duration"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func WithEventStream(w io.Writer) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		events := &eventStream{w: w}
+		config.events = events
+		config.instrumentation.hooks = append(config.instrumentation.hooks, PhaseHook{
+			Start: func(phase string, labels map[string]string) {
+				events.emit(Event{Source: "phase", Operation: phase, Resource: labels["app"], Status: "started"})
+			},
+			Stop: func(phase string, labels map[string]string, duration time.Duration, err error) {
+				events.record("phase")(phase, labels["app"], duration, err)
+			},
+		})
+		return config
+	}
+}
+
+type eventStream struct {
+	w io.Writer
+	m sync.Mutex
+}
+
+func (s *eventStream) record(source string) func(operation, resource string, duration time.Duration, err error) {
+	return func(operation, resource string, duration time.Duration, err error) {
+		event := Event{
+			Source:    source,
+			Operation: operation,
+			Resource:  resource,
+			Status:    "succeeded",
+			Duration:  duration.Seconds(),
+		}
+
+		if err != nil {
+			event.Status = "failed"
+			event.Error = err.Error()
+		}
+
+		s.emit(event)
+	}
+}
+
+func (s *eventStream) emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	content, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	_, _ = s.w.Write(append(content, '\n'))
+}
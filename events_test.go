@@ -0,0 +1,63 @@
+package switchblade_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/cloudfoundry/switchblade/fakes"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testEvents(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buffer   *bytes.Buffer
+		stage    *fakes.DockerStagePhase
+		platform switchblade.Platform
+	)
+
+	it.Before(func() {
+		buffer = bytes.NewBuffer(nil)
+		stage = &fakes.DockerStagePhase{}
+
+		platform = switchblade.NewDocker(&fakes.DockerInitializePhase{}, &fakes.DockerSetupPhase{}, stage, &fakes.DockerStartPhase{}, &fakes.DockerTeardownPhase{}, switchblade.WithEventStream(buffer))
+	})
+
+	it("writes a JSON line for each phase as it starts and stops", func() {
+		stage.RunCall.Returns.Err = errors.New("stage phase errored")
+
+		_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+		Expect(err).To(HaveOccurred())
+
+		var events []switchblade.Event
+		scanner := bufio.NewScanner(buffer)
+		for scanner.Scan() {
+			var event switchblade.Event
+			Expect(json.Unmarshal(scanner.Bytes(), &event)).To(Succeed())
+			Expect(event.Time).NotTo(BeZero())
+
+			events = append(events, event)
+		}
+		Expect(scanner.Err()).NotTo(HaveOccurred())
+
+		Expect(events).To(HaveLen(4))
+
+		for i, expected := range []switchblade.Event{
+			{Source: "phase", Operation: "setup", Resource: "some-app", Status: "started"},
+			{Source: "phase", Operation: "setup", Resource: "some-app", Status: "succeeded"},
+			{Source: "phase", Operation: "stage", Resource: "some-app", Status: "started"},
+			{Source: "phase", Operation: "stage", Resource: "some-app", Status: "failed", Error: "stage phase errored"},
+		} {
+			expected.Time = events[i].Time
+			expected.Duration = events[i].Duration
+			Expect(events[i]).To(Equal(expected))
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package fakes
+
+import (
+	"sync"
+	"time"
+)
+
+type Clock struct {
+	AfterCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			D time.Duration
+		}
+		Returns struct {
+			TimeChannel <-chan time.Time
+		}
+		Stub func(time.Duration) <-chan time.Time
+	}
+	NowCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			Time time.Time
+		}
+		Stub func() time.Time
+	}
+}
+
+func (f *Clock) After(param1 time.Duration) <-chan time.Time {
+	f.AfterCall.mutex.Lock()
+	defer f.AfterCall.mutex.Unlock()
+	f.AfterCall.CallCount++
+	f.AfterCall.Receives.D = param1
+	if f.AfterCall.Stub != nil {
+		return f.AfterCall.Stub(param1)
+	}
+	return f.AfterCall.Returns.TimeChannel
+}
+func (f *Clock) Now() time.Time {
+	f.NowCall.mutex.Lock()
+	defer f.NowCall.mutex.Unlock()
+	f.NowCall.CallCount++
+	if f.NowCall.Stub != nil {
+		return f.NowCall.Stub()
+	}
+	return f.NowCall.Returns.Time
+}
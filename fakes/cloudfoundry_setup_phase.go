@@ -1,6 +1,7 @@
 package fakes
 
 import (
+	"context"
 	"io"
 	"sync"
 
@@ -12,6 +13,7 @@ type CloudFoundrySetupPhase struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
+			Ctx    context.Context
 			Logs   io.Writer
 			Home   string
 			Name   string
@@ -21,7 +23,18 @@ type CloudFoundrySetupPhase struct {
 			Url string
 			Err error
 		}
-		Stub func(io.Writer, string, string, string) (string, error)
+		Stub func(context.Context, io.Writer, string, string, string) (string, error)
+	}
+	WithAppFeaturesCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Features map[string]bool
+		}
+		Returns struct {
+			SetupPhase cloudfoundry.SetupPhase
+		}
+		Stub func(map[string]bool) cloudfoundry.SetupPhase
 	}
 	WithBuildpacksCall struct {
 		mutex     sync.Mutex
@@ -34,6 +47,19 @@ type CloudFoundrySetupPhase struct {
 		}
 		Stub func(...string) cloudfoundry.SetupPhase
 	}
+	WithCredentialsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Credentials map[string]interface {
+			}
+		}
+		Returns struct {
+			SetupPhase cloudfoundry.SetupPhase
+		}
+		Stub func(map[string]interface {
+		}) cloudfoundry.SetupPhase
+	}
 	WithEnvCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -45,6 +71,50 @@ type CloudFoundrySetupPhase struct {
 		}
 		Stub func(map[string]string) cloudfoundry.SetupPhase
 	}
+	WithHealthCheckCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			HealthCheck cloudfoundry.HealthCheck
+		}
+		Returns struct {
+			SetupPhase cloudfoundry.SetupPhase
+		}
+		Stub func(cloudfoundry.HealthCheck) cloudfoundry.SetupPhase
+	}
+	WithManifestCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Manifest cloudfoundry.Manifest
+		}
+		Returns struct {
+			SetupPhase cloudfoundry.SetupPhase
+		}
+		Stub func(cloudfoundry.Manifest) cloudfoundry.SetupPhase
+	}
+	WithMetadataCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Metadata cloudfoundry.Metadata
+		}
+		Returns struct {
+			SetupPhase cloudfoundry.SetupPhase
+		}
+		Stub func(cloudfoundry.Metadata) cloudfoundry.SetupPhase
+	}
+	WithProfileScriptsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Scripts map[string]string
+		}
+		Returns struct {
+			SetupPhase cloudfoundry.SetupPhase
+		}
+		Stub func(map[string]string) cloudfoundry.SetupPhase
+	}
 	WithServicesCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -58,6 +128,28 @@ type CloudFoundrySetupPhase struct {
 		Stub func(map[string]map[string]interface {
 		}) cloudfoundry.SetupPhase
 	}
+	WithSidecarsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Sidecars []cloudfoundry.Sidecar
+		}
+		Returns struct {
+			SetupPhase cloudfoundry.SetupPhase
+		}
+		Stub func([]cloudfoundry.Sidecar) cloudfoundry.SetupPhase
+	}
+	WithSourceCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Source io.Reader
+		}
+		Returns struct {
+			SetupPhase cloudfoundry.SetupPhase
+		}
+		Stub func(io.Reader) cloudfoundry.SetupPhase
+	}
 	WithStackCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -79,19 +171,30 @@ type CloudFoundrySetupPhase struct {
 	}
 }
 
-func (f *CloudFoundrySetupPhase) Run(param1 io.Writer, param2 string, param3 string, param4 string) (string, error) {
+func (f *CloudFoundrySetupPhase) Run(param1 context.Context, param2 io.Writer, param3 string, param4 string, param5 string) (string, error) {
 	f.RunCall.mutex.Lock()
 	defer f.RunCall.mutex.Unlock()
 	f.RunCall.CallCount++
-	f.RunCall.Receives.Logs = param1
-	f.RunCall.Receives.Home = param2
-	f.RunCall.Receives.Name = param3
-	f.RunCall.Receives.Source = param4
+	f.RunCall.Receives.Ctx = param1
+	f.RunCall.Receives.Logs = param2
+	f.RunCall.Receives.Home = param3
+	f.RunCall.Receives.Name = param4
+	f.RunCall.Receives.Source = param5
 	if f.RunCall.Stub != nil {
-		return f.RunCall.Stub(param1, param2, param3, param4)
+		return f.RunCall.Stub(param1, param2, param3, param4, param5)
 	}
 	return f.RunCall.Returns.Url, f.RunCall.Returns.Err
 }
+func (f *CloudFoundrySetupPhase) WithAppFeatures(param1 map[string]bool) cloudfoundry.SetupPhase {
+	f.WithAppFeaturesCall.mutex.Lock()
+	defer f.WithAppFeaturesCall.mutex.Unlock()
+	f.WithAppFeaturesCall.CallCount++
+	f.WithAppFeaturesCall.Receives.Features = param1
+	if f.WithAppFeaturesCall.Stub != nil {
+		return f.WithAppFeaturesCall.Stub(param1)
+	}
+	return f.WithAppFeaturesCall.Returns.SetupPhase
+}
 func (f *CloudFoundrySetupPhase) WithBuildpacks(param1 ...string) cloudfoundry.SetupPhase {
 	f.WithBuildpacksCall.mutex.Lock()
 	defer f.WithBuildpacksCall.mutex.Unlock()
@@ -102,6 +205,17 @@ func (f *CloudFoundrySetupPhase) WithBuildpacks(param1 ...string) cloudfoundry.S
 	}
 	return f.WithBuildpacksCall.Returns.SetupPhase
 }
+func (f *CloudFoundrySetupPhase) WithCredentials(param1 map[string]interface {
+}) cloudfoundry.SetupPhase {
+	f.WithCredentialsCall.mutex.Lock()
+	defer f.WithCredentialsCall.mutex.Unlock()
+	f.WithCredentialsCall.CallCount++
+	f.WithCredentialsCall.Receives.Credentials = param1
+	if f.WithCredentialsCall.Stub != nil {
+		return f.WithCredentialsCall.Stub(param1)
+	}
+	return f.WithCredentialsCall.Returns.SetupPhase
+}
 func (f *CloudFoundrySetupPhase) WithEnv(param1 map[string]string) cloudfoundry.SetupPhase {
 	f.WithEnvCall.mutex.Lock()
 	defer f.WithEnvCall.mutex.Unlock()
@@ -112,6 +226,46 @@ func (f *CloudFoundrySetupPhase) WithEnv(param1 map[string]string) cloudfoundry.
 	}
 	return f.WithEnvCall.Returns.SetupPhase
 }
+func (f *CloudFoundrySetupPhase) WithHealthCheck(param1 cloudfoundry.HealthCheck) cloudfoundry.SetupPhase {
+	f.WithHealthCheckCall.mutex.Lock()
+	defer f.WithHealthCheckCall.mutex.Unlock()
+	f.WithHealthCheckCall.CallCount++
+	f.WithHealthCheckCall.Receives.HealthCheck = param1
+	if f.WithHealthCheckCall.Stub != nil {
+		return f.WithHealthCheckCall.Stub(param1)
+	}
+	return f.WithHealthCheckCall.Returns.SetupPhase
+}
+func (f *CloudFoundrySetupPhase) WithManifest(param1 cloudfoundry.Manifest) cloudfoundry.SetupPhase {
+	f.WithManifestCall.mutex.Lock()
+	defer f.WithManifestCall.mutex.Unlock()
+	f.WithManifestCall.CallCount++
+	f.WithManifestCall.Receives.Manifest = param1
+	if f.WithManifestCall.Stub != nil {
+		return f.WithManifestCall.Stub(param1)
+	}
+	return f.WithManifestCall.Returns.SetupPhase
+}
+func (f *CloudFoundrySetupPhase) WithMetadata(param1 cloudfoundry.Metadata) cloudfoundry.SetupPhase {
+	f.WithMetadataCall.mutex.Lock()
+	defer f.WithMetadataCall.mutex.Unlock()
+	f.WithMetadataCall.CallCount++
+	f.WithMetadataCall.Receives.Metadata = param1
+	if f.WithMetadataCall.Stub != nil {
+		return f.WithMetadataCall.Stub(param1)
+	}
+	return f.WithMetadataCall.Returns.SetupPhase
+}
+func (f *CloudFoundrySetupPhase) WithProfileScripts(param1 map[string]string) cloudfoundry.SetupPhase {
+	f.WithProfileScriptsCall.mutex.Lock()
+	defer f.WithProfileScriptsCall.mutex.Unlock()
+	f.WithProfileScriptsCall.CallCount++
+	f.WithProfileScriptsCall.Receives.Scripts = param1
+	if f.WithProfileScriptsCall.Stub != nil {
+		return f.WithProfileScriptsCall.Stub(param1)
+	}
+	return f.WithProfileScriptsCall.Returns.SetupPhase
+}
 func (f *CloudFoundrySetupPhase) WithServices(param1 map[string]map[string]interface {
 }) cloudfoundry.SetupPhase {
 	f.WithServicesCall.mutex.Lock()
@@ -123,6 +277,26 @@ func (f *CloudFoundrySetupPhase) WithServices(param1 map[string]map[string]inter
 	}
 	return f.WithServicesCall.Returns.SetupPhase
 }
+func (f *CloudFoundrySetupPhase) WithSidecars(param1 []cloudfoundry.Sidecar) cloudfoundry.SetupPhase {
+	f.WithSidecarsCall.mutex.Lock()
+	defer f.WithSidecarsCall.mutex.Unlock()
+	f.WithSidecarsCall.CallCount++
+	f.WithSidecarsCall.Receives.Sidecars = param1
+	if f.WithSidecarsCall.Stub != nil {
+		return f.WithSidecarsCall.Stub(param1)
+	}
+	return f.WithSidecarsCall.Returns.SetupPhase
+}
+func (f *CloudFoundrySetupPhase) WithSource(param1 io.Reader) cloudfoundry.SetupPhase {
+	f.WithSourceCall.mutex.Lock()
+	defer f.WithSourceCall.mutex.Unlock()
+	f.WithSourceCall.CallCount++
+	f.WithSourceCall.Receives.Source = param1
+	if f.WithSourceCall.Stub != nil {
+		return f.WithSourceCall.Stub(param1)
+	}
+	return f.WithSourceCall.Returns.SetupPhase
+}
 func (f *CloudFoundrySetupPhase) WithStack(param1 string) cloudfoundry.SetupPhase {
 	f.WithStackCall.mutex.Lock()
 	defer f.WithStackCall.mutex.Unlock()
@@ -1,8 +1,11 @@
 package fakes
 
 import (
+	"context"
 	"io"
 	"sync"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
 )
 
 type CloudFoundryStagePhase struct {
@@ -10,6 +13,7 @@ type CloudFoundryStagePhase struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
+			Ctx  context.Context
 			Logs io.Writer
 			Home string
 			Name string
@@ -18,19 +22,41 @@ type CloudFoundryStagePhase struct {
 			Url string
 			Err error
 		}
-		Stub func(io.Writer, string, string) (string, error)
+		Stub func(context.Context, io.Writer, string, string) (string, error)
+	}
+	WithProcessTypeCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			ProcessType string
+		}
+		Returns struct {
+			StagePhase cloudfoundry.StagePhase
+		}
+		Stub func(string) cloudfoundry.StagePhase
 	}
 }
 
-func (f *CloudFoundryStagePhase) Run(param1 io.Writer, param2 string, param3 string) (string, error) {
+func (f *CloudFoundryStagePhase) Run(param1 context.Context, param2 io.Writer, param3 string, param4 string) (string, error) {
 	f.RunCall.mutex.Lock()
 	defer f.RunCall.mutex.Unlock()
 	f.RunCall.CallCount++
-	f.RunCall.Receives.Logs = param1
-	f.RunCall.Receives.Home = param2
-	f.RunCall.Receives.Name = param3
+	f.RunCall.Receives.Ctx = param1
+	f.RunCall.Receives.Logs = param2
+	f.RunCall.Receives.Home = param3
+	f.RunCall.Receives.Name = param4
 	if f.RunCall.Stub != nil {
-		return f.RunCall.Stub(param1, param2, param3)
+		return f.RunCall.Stub(param1, param2, param3, param4)
 	}
 	return f.RunCall.Returns.Url, f.RunCall.Returns.Err
 }
+func (f *CloudFoundryStagePhase) WithProcessType(param1 string) cloudfoundry.StagePhase {
+	f.WithProcessTypeCall.mutex.Lock()
+	defer f.WithProcessTypeCall.mutex.Unlock()
+	f.WithProcessTypeCall.CallCount++
+	f.WithProcessTypeCall.Receives.ProcessType = param1
+	if f.WithProcessTypeCall.Stub != nil {
+		return f.WithProcessTypeCall.Stub(param1)
+	}
+	return f.WithProcessTypeCall.Returns.StagePhase
+}
@@ -1,30 +1,111 @@
 package fakes
 
-import "sync"
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+)
 
 type CloudFoundryTeardownPhase struct {
-	RunCall struct {
+	ArchiveCall struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
 			Home string
 			Name string
+			Dir  string
 		}
 		Returns struct {
 			Error error
 		}
-		Stub func(string, string) error
+		Stub func(string, string, string) error
+	}
+	ListCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Home string
+			Name string
+		}
+		Returns struct {
+			ResourceSlice []cloudfoundry.Resource
+			Error         error
+		}
+		Stub func(string, string) ([]cloudfoundry.Resource, error)
+	}
+	NamesCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx    context.Context
+			Prefix string
+		}
+		Returns struct {
+			StringSlice []string
+			Error       error
+		}
+		Stub func(context.Context, string) ([]string, error)
+	}
+	RunCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx  context.Context
+			Home string
+			Name string
+		}
+		Returns struct {
+			TeardownReport cloudfoundry.TeardownReport
+			Error          error
+		}
+		Stub func(context.Context, string, string) (cloudfoundry.TeardownReport, error)
 	}
 }
 
-func (f *CloudFoundryTeardownPhase) Run(param1 string, param2 string) error {
+func (f *CloudFoundryTeardownPhase) Archive(param1 string, param2 string, param3 string) error {
+	f.ArchiveCall.mutex.Lock()
+	defer f.ArchiveCall.mutex.Unlock()
+	f.ArchiveCall.CallCount++
+	f.ArchiveCall.Receives.Home = param1
+	f.ArchiveCall.Receives.Name = param2
+	f.ArchiveCall.Receives.Dir = param3
+	if f.ArchiveCall.Stub != nil {
+		return f.ArchiveCall.Stub(param1, param2, param3)
+	}
+	return f.ArchiveCall.Returns.Error
+}
+func (f *CloudFoundryTeardownPhase) List(param1 string, param2 string) ([]cloudfoundry.Resource, error) {
+	f.ListCall.mutex.Lock()
+	defer f.ListCall.mutex.Unlock()
+	f.ListCall.CallCount++
+	f.ListCall.Receives.Home = param1
+	f.ListCall.Receives.Name = param2
+	if f.ListCall.Stub != nil {
+		return f.ListCall.Stub(param1, param2)
+	}
+	return f.ListCall.Returns.ResourceSlice, f.ListCall.Returns.Error
+}
+func (f *CloudFoundryTeardownPhase) Names(param1 context.Context, param2 string) ([]string, error) {
+	f.NamesCall.mutex.Lock()
+	defer f.NamesCall.mutex.Unlock()
+	f.NamesCall.CallCount++
+	f.NamesCall.Receives.Ctx = param1
+	f.NamesCall.Receives.Prefix = param2
+	if f.NamesCall.Stub != nil {
+		return f.NamesCall.Stub(param1, param2)
+	}
+	return f.NamesCall.Returns.StringSlice, f.NamesCall.Returns.Error
+}
+func (f *CloudFoundryTeardownPhase) Run(param1 context.Context, param2 string, param3 string) (cloudfoundry.TeardownReport, error) {
 	f.RunCall.mutex.Lock()
 	defer f.RunCall.mutex.Unlock()
 	f.RunCall.CallCount++
-	f.RunCall.Receives.Home = param1
-	f.RunCall.Receives.Name = param2
+	f.RunCall.Receives.Ctx = param1
+	f.RunCall.Receives.Home = param2
+	f.RunCall.Receives.Name = param3
 	if f.RunCall.Stub != nil {
-		return f.RunCall.Stub(param1, param2)
+		return f.RunCall.Stub(param1, param2, param3)
 	}
-	return f.RunCall.Returns.Error
+	return f.RunCall.Returns.TeardownReport, f.RunCall.Returns.Error
 }
@@ -20,9 +20,10 @@ type DockerSetupPhase struct {
 		}
 		Returns struct {
 			ContainerID string
+			StackDigest string
 			Err         error
 		}
-		Stub func(context.Context, io.Writer, string, string) (string, error)
+		Stub func(context.Context, io.Writer, string, string) (string, string, error)
 	}
 	WithBuildpacksCall struct {
 		mutex     sync.Mutex
@@ -46,6 +47,17 @@ type DockerSetupPhase struct {
 		}
 		Stub func(map[string]string) docker.SetupPhase
 	}
+	WithSecurityProfileCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Profile docker.SecurityProfile
+		}
+		Returns struct {
+			SetupPhase docker.SetupPhase
+		}
+		Stub func(docker.SecurityProfile) docker.SetupPhase
+	}
 	WithServicesCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -59,6 +71,17 @@ type DockerSetupPhase struct {
 		Stub func(map[string]map[string]interface {
 		}) docker.SetupPhase
 	}
+	WithSourceCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Source io.Reader
+		}
+		Returns struct {
+			SetupPhase docker.SetupPhase
+		}
+		Stub func(io.Reader) docker.SetupPhase
+	}
 	WithStackCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -70,6 +93,25 @@ type DockerSetupPhase struct {
 		}
 		Stub func(string) docker.SetupPhase
 	}
+	WithStackSetupCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Setup func(docker.Execer) error
+		}
+		Returns struct {
+			SetupPhase docker.SetupPhase
+		}
+		Stub func(func(docker.Execer) error) docker.SetupPhase
+	}
+	WithStagingContainerReuseCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			SetupPhase docker.SetupPhase
+		}
+		Stub func() docker.SetupPhase
+	}
 	WithoutInternetAccessCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -80,7 +122,7 @@ type DockerSetupPhase struct {
 	}
 }
 
-func (f *DockerSetupPhase) Run(param1 context.Context, param2 io.Writer, param3 string, param4 string) (string, error) {
+func (f *DockerSetupPhase) Run(param1 context.Context, param2 io.Writer, param3 string, param4 string) (string, string, error) {
 	f.RunCall.mutex.Lock()
 	defer f.RunCall.mutex.Unlock()
 	f.RunCall.CallCount++
@@ -91,7 +133,7 @@ func (f *DockerSetupPhase) Run(param1 context.Context, param2 io.Writer, param3
 	if f.RunCall.Stub != nil {
 		return f.RunCall.Stub(param1, param2, param3, param4)
 	}
-	return f.RunCall.Returns.ContainerID, f.RunCall.Returns.Err
+	return f.RunCall.Returns.ContainerID, f.RunCall.Returns.StackDigest, f.RunCall.Returns.Err
 }
 func (f *DockerSetupPhase) WithBuildpacks(param1 ...string) docker.SetupPhase {
 	f.WithBuildpacksCall.mutex.Lock()
@@ -113,6 +155,16 @@ func (f *DockerSetupPhase) WithEnv(param1 map[string]string) docker.SetupPhase {
 	}
 	return f.WithEnvCall.Returns.SetupPhase
 }
+func (f *DockerSetupPhase) WithSecurityProfile(param1 docker.SecurityProfile) docker.SetupPhase {
+	f.WithSecurityProfileCall.mutex.Lock()
+	defer f.WithSecurityProfileCall.mutex.Unlock()
+	f.WithSecurityProfileCall.CallCount++
+	f.WithSecurityProfileCall.Receives.Profile = param1
+	if f.WithSecurityProfileCall.Stub != nil {
+		return f.WithSecurityProfileCall.Stub(param1)
+	}
+	return f.WithSecurityProfileCall.Returns.SetupPhase
+}
 func (f *DockerSetupPhase) WithServices(param1 map[string]map[string]interface {
 }) docker.SetupPhase {
 	f.WithServicesCall.mutex.Lock()
@@ -124,6 +176,16 @@ func (f *DockerSetupPhase) WithServices(param1 map[string]map[string]interface {
 	}
 	return f.WithServicesCall.Returns.SetupPhase
 }
+func (f *DockerSetupPhase) WithSource(param1 io.Reader) docker.SetupPhase {
+	f.WithSourceCall.mutex.Lock()
+	defer f.WithSourceCall.mutex.Unlock()
+	f.WithSourceCall.CallCount++
+	f.WithSourceCall.Receives.Source = param1
+	if f.WithSourceCall.Stub != nil {
+		return f.WithSourceCall.Stub(param1)
+	}
+	return f.WithSourceCall.Returns.SetupPhase
+}
 func (f *DockerSetupPhase) WithStack(param1 string) docker.SetupPhase {
 	f.WithStackCall.mutex.Lock()
 	defer f.WithStackCall.mutex.Unlock()
@@ -134,6 +196,25 @@ func (f *DockerSetupPhase) WithStack(param1 string) docker.SetupPhase {
 	}
 	return f.WithStackCall.Returns.SetupPhase
 }
+func (f *DockerSetupPhase) WithStackSetup(param1 func(docker.Execer) error) docker.SetupPhase {
+	f.WithStackSetupCall.mutex.Lock()
+	defer f.WithStackSetupCall.mutex.Unlock()
+	f.WithStackSetupCall.CallCount++
+	f.WithStackSetupCall.Receives.Setup = param1
+	if f.WithStackSetupCall.Stub != nil {
+		return f.WithStackSetupCall.Stub(param1)
+	}
+	return f.WithStackSetupCall.Returns.SetupPhase
+}
+func (f *DockerSetupPhase) WithStagingContainerReuse() docker.SetupPhase {
+	f.WithStagingContainerReuseCall.mutex.Lock()
+	defer f.WithStagingContainerReuseCall.mutex.Unlock()
+	f.WithStagingContainerReuseCall.CallCount++
+	if f.WithStagingContainerReuseCall.Stub != nil {
+		return f.WithStagingContainerReuseCall.Stub()
+	}
+	return f.WithStagingContainerReuseCall.Returns.SetupPhase
+}
 func (f *DockerSetupPhase) WithoutInternetAccess() docker.SetupPhase {
 	f.WithoutInternetAccessCall.mutex.Lock()
 	defer f.WithoutInternetAccessCall.mutex.Unlock()
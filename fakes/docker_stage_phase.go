@@ -4,6 +4,8 @@ import (
 	"context"
 	"io"
 	"sync"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
 )
 
 type DockerStagePhase struct {
@@ -17,14 +19,14 @@ type DockerStagePhase struct {
 			Name        string
 		}
 		Returns struct {
-			Command string
-			Err     error
+			Result docker.StageResult
+			Err    error
 		}
-		Stub func(context.Context, io.Writer, string, string) (string, error)
+		Stub func(context.Context, io.Writer, string, string) (docker.StageResult, error)
 	}
 }
 
-func (f *DockerStagePhase) Run(param1 context.Context, param2 io.Writer, param3 string, param4 string) (string, error) {
+func (f *DockerStagePhase) Run(param1 context.Context, param2 io.Writer, param3 string, param4 string) (docker.StageResult, error) {
 	f.RunCall.mutex.Lock()
 	defer f.RunCall.mutex.Unlock()
 	f.RunCall.CallCount++
@@ -35,5 +37,5 @@ func (f *DockerStagePhase) Run(param1 context.Context, param2 io.Writer, param3
 	if f.RunCall.Stub != nil {
 		return f.RunCall.Stub(param1, param2, param3, param4)
 	}
-	return f.RunCall.Returns.Command, f.RunCall.Returns.Err
+	return f.RunCall.Returns.Result, f.RunCall.Returns.Err
 }
@@ -25,6 +25,38 @@ type DockerStartPhase struct {
 		}
 		Stub func(context.Context, io.Writer, string, string) (string, string, error)
 	}
+	WithCredentialsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Credentials map[string]interface {
+			}
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func(map[string]interface {
+		}) docker.StartPhase
+	}
+	WithCustomizedStackCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func() docker.StartPhase
+	}
+	WithDevMountsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Mounts map[string]string
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func(map[string]string) docker.StartPhase
+	}
 	WithEnvCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -36,6 +68,80 @@ type DockerStartPhase struct {
 		}
 		Stub func(map[string]string) docker.StartPhase
 	}
+	WithHealthCheckCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			HealthCheck docker.HealthCheck
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func(docker.HealthCheck) docker.StartPhase
+	}
+	WithHealthCheckPollingCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Polling docker.HealthCheckPolling
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func(docker.HealthCheckPolling) docker.StartPhase
+	}
+	WithProcessCommandsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Commands map[string]func(command string) string
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func(map[string]func(command string) string) docker.StartPhase
+	}
+	WithProcessTypeCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			ProcessType string
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func(string) docker.StartPhase
+	}
+	WithProfileScriptsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Scripts map[string]string
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func(map[string]string) docker.StartPhase
+	}
+	WithReadOnlyRootFSCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func() docker.StartPhase
+	}
+	WithSecurityProfileCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Profile docker.SecurityProfile
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func(docker.SecurityProfile) docker.StartPhase
+	}
 	WithServicesCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -49,6 +155,17 @@ type DockerStartPhase struct {
 		Stub func(map[string]map[string]interface {
 		}) docker.StartPhase
 	}
+	WithSidecarsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Sidecars []docker.Sidecar
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func([]docker.Sidecar) docker.StartPhase
+	}
 	WithStackCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -60,6 +177,18 @@ type DockerStartPhase struct {
 		}
 		Stub func(string) docker.StartPhase
 	}
+	WithUserCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Uid int
+			Gid int
+		}
+		Returns struct {
+			StartPhase docker.StartPhase
+		}
+		Stub func(int, int) docker.StartPhase
+	}
 }
 
 func (f *DockerStartPhase) Run(param1 context.Context, param2 io.Writer, param3 string, param4 string) (string, string, error) {
@@ -75,6 +204,36 @@ func (f *DockerStartPhase) Run(param1 context.Context, param2 io.Writer, param3
 	}
 	return f.RunCall.Returns.ExternalURL, f.RunCall.Returns.InternalURL, f.RunCall.Returns.Err
 }
+func (f *DockerStartPhase) WithCredentials(param1 map[string]interface {
+}) docker.StartPhase {
+	f.WithCredentialsCall.mutex.Lock()
+	defer f.WithCredentialsCall.mutex.Unlock()
+	f.WithCredentialsCall.CallCount++
+	f.WithCredentialsCall.Receives.Credentials = param1
+	if f.WithCredentialsCall.Stub != nil {
+		return f.WithCredentialsCall.Stub(param1)
+	}
+	return f.WithCredentialsCall.Returns.StartPhase
+}
+func (f *DockerStartPhase) WithCustomizedStack() docker.StartPhase {
+	f.WithCustomizedStackCall.mutex.Lock()
+	defer f.WithCustomizedStackCall.mutex.Unlock()
+	f.WithCustomizedStackCall.CallCount++
+	if f.WithCustomizedStackCall.Stub != nil {
+		return f.WithCustomizedStackCall.Stub()
+	}
+	return f.WithCustomizedStackCall.Returns.StartPhase
+}
+func (f *DockerStartPhase) WithDevMounts(param1 map[string]string) docker.StartPhase {
+	f.WithDevMountsCall.mutex.Lock()
+	defer f.WithDevMountsCall.mutex.Unlock()
+	f.WithDevMountsCall.CallCount++
+	f.WithDevMountsCall.Receives.Mounts = param1
+	if f.WithDevMountsCall.Stub != nil {
+		return f.WithDevMountsCall.Stub(param1)
+	}
+	return f.WithDevMountsCall.Returns.StartPhase
+}
 func (f *DockerStartPhase) WithEnv(param1 map[string]string) docker.StartPhase {
 	f.WithEnvCall.mutex.Lock()
 	defer f.WithEnvCall.mutex.Unlock()
@@ -85,6 +244,75 @@ func (f *DockerStartPhase) WithEnv(param1 map[string]string) docker.StartPhase {
 	}
 	return f.WithEnvCall.Returns.StartPhase
 }
+func (f *DockerStartPhase) WithHealthCheck(param1 docker.HealthCheck) docker.StartPhase {
+	f.WithHealthCheckCall.mutex.Lock()
+	defer f.WithHealthCheckCall.mutex.Unlock()
+	f.WithHealthCheckCall.CallCount++
+	f.WithHealthCheckCall.Receives.HealthCheck = param1
+	if f.WithHealthCheckCall.Stub != nil {
+		return f.WithHealthCheckCall.Stub(param1)
+	}
+	return f.WithHealthCheckCall.Returns.StartPhase
+}
+func (f *DockerStartPhase) WithHealthCheckPolling(param1 docker.HealthCheckPolling) docker.StartPhase {
+	f.WithHealthCheckPollingCall.mutex.Lock()
+	defer f.WithHealthCheckPollingCall.mutex.Unlock()
+	f.WithHealthCheckPollingCall.CallCount++
+	f.WithHealthCheckPollingCall.Receives.Polling = param1
+	if f.WithHealthCheckPollingCall.Stub != nil {
+		return f.WithHealthCheckPollingCall.Stub(param1)
+	}
+	return f.WithHealthCheckPollingCall.Returns.StartPhase
+}
+func (f *DockerStartPhase) WithProcessCommands(param1 map[string]func(command string) string) docker.StartPhase {
+	f.WithProcessCommandsCall.mutex.Lock()
+	defer f.WithProcessCommandsCall.mutex.Unlock()
+	f.WithProcessCommandsCall.CallCount++
+	f.WithProcessCommandsCall.Receives.Commands = param1
+	if f.WithProcessCommandsCall.Stub != nil {
+		return f.WithProcessCommandsCall.Stub(param1)
+	}
+	return f.WithProcessCommandsCall.Returns.StartPhase
+}
+func (f *DockerStartPhase) WithProcessType(param1 string) docker.StartPhase {
+	f.WithProcessTypeCall.mutex.Lock()
+	defer f.WithProcessTypeCall.mutex.Unlock()
+	f.WithProcessTypeCall.CallCount++
+	f.WithProcessTypeCall.Receives.ProcessType = param1
+	if f.WithProcessTypeCall.Stub != nil {
+		return f.WithProcessTypeCall.Stub(param1)
+	}
+	return f.WithProcessTypeCall.Returns.StartPhase
+}
+func (f *DockerStartPhase) WithProfileScripts(param1 map[string]string) docker.StartPhase {
+	f.WithProfileScriptsCall.mutex.Lock()
+	defer f.WithProfileScriptsCall.mutex.Unlock()
+	f.WithProfileScriptsCall.CallCount++
+	f.WithProfileScriptsCall.Receives.Scripts = param1
+	if f.WithProfileScriptsCall.Stub != nil {
+		return f.WithProfileScriptsCall.Stub(param1)
+	}
+	return f.WithProfileScriptsCall.Returns.StartPhase
+}
+func (f *DockerStartPhase) WithReadOnlyRootFS() docker.StartPhase {
+	f.WithReadOnlyRootFSCall.mutex.Lock()
+	defer f.WithReadOnlyRootFSCall.mutex.Unlock()
+	f.WithReadOnlyRootFSCall.CallCount++
+	if f.WithReadOnlyRootFSCall.Stub != nil {
+		return f.WithReadOnlyRootFSCall.Stub()
+	}
+	return f.WithReadOnlyRootFSCall.Returns.StartPhase
+}
+func (f *DockerStartPhase) WithSecurityProfile(param1 docker.SecurityProfile) docker.StartPhase {
+	f.WithSecurityProfileCall.mutex.Lock()
+	defer f.WithSecurityProfileCall.mutex.Unlock()
+	f.WithSecurityProfileCall.CallCount++
+	f.WithSecurityProfileCall.Receives.Profile = param1
+	if f.WithSecurityProfileCall.Stub != nil {
+		return f.WithSecurityProfileCall.Stub(param1)
+	}
+	return f.WithSecurityProfileCall.Returns.StartPhase
+}
 func (f *DockerStartPhase) WithServices(param1 map[string]map[string]interface {
 }) docker.StartPhase {
 	f.WithServicesCall.mutex.Lock()
@@ -96,6 +324,16 @@ func (f *DockerStartPhase) WithServices(param1 map[string]map[string]interface {
 	}
 	return f.WithServicesCall.Returns.StartPhase
 }
+func (f *DockerStartPhase) WithSidecars(param1 []docker.Sidecar) docker.StartPhase {
+	f.WithSidecarsCall.mutex.Lock()
+	defer f.WithSidecarsCall.mutex.Unlock()
+	f.WithSidecarsCall.CallCount++
+	f.WithSidecarsCall.Receives.Sidecars = param1
+	if f.WithSidecarsCall.Stub != nil {
+		return f.WithSidecarsCall.Stub(param1)
+	}
+	return f.WithSidecarsCall.Returns.StartPhase
+}
 func (f *DockerStartPhase) WithStack(param1 string) docker.StartPhase {
 	f.WithStackCall.mutex.Lock()
 	defer f.WithStackCall.mutex.Unlock()
@@ -106,3 +344,14 @@ func (f *DockerStartPhase) WithStack(param1 string) docker.StartPhase {
 	}
 	return f.WithStackCall.Returns.StartPhase
 }
+func (f *DockerStartPhase) WithUser(param1 int, param2 int) docker.StartPhase {
+	f.WithUserCall.mutex.Lock()
+	defer f.WithUserCall.mutex.Unlock()
+	f.WithUserCall.CallCount++
+	f.WithUserCall.Receives.Uid = param1
+	f.WithUserCall.Receives.Gid = param2
+	if f.WithUserCall.Stub != nil {
+		return f.WithUserCall.Stub(param1, param2)
+	}
+	return f.WithUserCall.Returns.StartPhase
+}
@@ -3,24 +3,100 @@ package fakes
 import (
 	"context"
 	"sync"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
 )
 
 type DockerTeardownPhase struct {
-	RunCall struct {
+	ArchiveCall struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
 			Ctx  context.Context
 			Name string
+			Dir  string
 		}
 		Returns struct {
 			Error error
 		}
-		Stub func(context.Context, string) error
+		Stub func(context.Context, string, string) error
+	}
+	ListCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx  context.Context
+			Name string
+		}
+		Returns struct {
+			ResourceSlice []docker.Resource
+			Error         error
+		}
+		Stub func(context.Context, string) ([]docker.Resource, error)
+	}
+	NamesCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx    context.Context
+			Prefix string
+		}
+		Returns struct {
+			StringSlice []string
+			Error       error
+		}
+		Stub func(context.Context, string) ([]string, error)
+	}
+	RunCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx  context.Context
+			Name string
+		}
+		Returns struct {
+			TeardownReport docker.TeardownReport
+			Error          error
+		}
+		Stub func(context.Context, string) (docker.TeardownReport, error)
 	}
 }
 
-func (f *DockerTeardownPhase) Run(param1 context.Context, param2 string) error {
+func (f *DockerTeardownPhase) Archive(param1 context.Context, param2 string, param3 string) error {
+	f.ArchiveCall.mutex.Lock()
+	defer f.ArchiveCall.mutex.Unlock()
+	f.ArchiveCall.CallCount++
+	f.ArchiveCall.Receives.Ctx = param1
+	f.ArchiveCall.Receives.Name = param2
+	f.ArchiveCall.Receives.Dir = param3
+	if f.ArchiveCall.Stub != nil {
+		return f.ArchiveCall.Stub(param1, param2, param3)
+	}
+	return f.ArchiveCall.Returns.Error
+}
+func (f *DockerTeardownPhase) List(param1 context.Context, param2 string) ([]docker.Resource, error) {
+	f.ListCall.mutex.Lock()
+	defer f.ListCall.mutex.Unlock()
+	f.ListCall.CallCount++
+	f.ListCall.Receives.Ctx = param1
+	f.ListCall.Receives.Name = param2
+	if f.ListCall.Stub != nil {
+		return f.ListCall.Stub(param1, param2)
+	}
+	return f.ListCall.Returns.ResourceSlice, f.ListCall.Returns.Error
+}
+func (f *DockerTeardownPhase) Names(param1 context.Context, param2 string) ([]string, error) {
+	f.NamesCall.mutex.Lock()
+	defer f.NamesCall.mutex.Unlock()
+	f.NamesCall.CallCount++
+	f.NamesCall.Receives.Ctx = param1
+	f.NamesCall.Receives.Prefix = param2
+	if f.NamesCall.Stub != nil {
+		return f.NamesCall.Stub(param1, param2)
+	}
+	return f.NamesCall.Returns.StringSlice, f.NamesCall.Returns.Error
+}
+func (f *DockerTeardownPhase) Run(param1 context.Context, param2 string) (docker.TeardownReport, error) {
 	f.RunCall.mutex.Lock()
 	defer f.RunCall.mutex.Unlock()
 	f.RunCall.CallCount++
@@ -29,5 +105,5 @@ func (f *DockerTeardownPhase) Run(param1 context.Context, param2 string) error {
 	if f.RunCall.Stub != nil {
 		return f.RunCall.Stub(param1, param2)
 	}
-	return f.RunCall.Returns.Error
+	return f.RunCall.Returns.TeardownReport, f.RunCall.Returns.Error
 }
@@ -0,0 +1,33 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/switchblade"
+)
+
+type DropletScanner struct {
+	ScanCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Target switchblade.ScanTarget
+		}
+		Returns struct {
+			ScanResult switchblade.ScanResult
+			Error      error
+		}
+		Stub func(switchblade.ScanTarget) (switchblade.ScanResult, error)
+	}
+}
+
+func (f *DropletScanner) Scan(param1 switchblade.ScanTarget) (switchblade.ScanResult, error) {
+	f.ScanCall.mutex.Lock()
+	defer f.ScanCall.mutex.Unlock()
+	f.ScanCall.CallCount++
+	f.ScanCall.Receives.Target = param1
+	if f.ScanCall.Stub != nil {
+		return f.ScanCall.Stub(param1)
+	}
+	return f.ScanCall.Returns.ScanResult, f.ScanCall.Returns.Error
+}
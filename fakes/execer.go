@@ -0,0 +1,29 @@
+package fakes
+
+import "sync"
+
+type Execer struct {
+	ExecCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Command []string
+		}
+		Returns struct {
+			Output string
+			Err    error
+		}
+		Stub func(...string) (string, error)
+	}
+}
+
+func (f *Execer) Exec(param1 ...string) (string, error) {
+	f.ExecCall.mutex.Lock()
+	defer f.ExecCall.mutex.Unlock()
+	f.ExecCall.CallCount++
+	f.ExecCall.Receives.Command = param1
+	if f.ExecCall.Stub != nil {
+		return f.ExecCall.Stub(param1...)
+	}
+	return f.ExecCall.Returns.Output, f.ExecCall.Returns.Err
+}
@@ -14,7 +14,19 @@ func TestSwitchblade(t *testing.T) {
 	suite := spec.New("switchblade", spec.Report(report.Terminal{}), spec.Parallel())
 	suite("CloudFoundry", testCloudFoundry)
 	suite("Docker", testDocker)
+	suite("DeploymentURLs", testDeploymentURLs)
+	suite("EnvironmentSnapshot", testEnvironmentSnapshot)
+	suite("Events", testEvents)
+	suite("LogSink", testLogSink)
+	suite("Metrics", testMetrics)
+	suite("NewPlatform", testNewPlatform)
+	suite("PackageBuildpack", testPackageBuildpack, spec.Sequential())
 	suite("RandomName", testRandomName)
+	suite("SBOM", testSBOM)
+	suite("StackMatrix", testStackMatrix)
+	suite("StagingLog", testStagingLog)
+	suite("Traffic", testTraffic)
 	suite("Source", testSource)
+	suite("WithDeployment", testWithDeployment)
 	suite.Run(t)
 }
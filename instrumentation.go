@@ -0,0 +1,81 @@
+package switchblade
+
+import (
+	"context"
+	"runtime/pprof"
+	"sort"
+	"time"
+)
+
+type PhaseHook struct {
+	Start func(phase string, labels map[string]string)
+	Stop  func(phase string, labels map[string]string, duration time.Duration, err error)
+}
+
+func WithPhaseHooks(hooks ...PhaseHook) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.instrumentation.hooks = append(config.instrumentation.hooks, hooks...)
+		return config
+	}
+}
+
+func WithProfilerLabels() PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.instrumentation.profilerLabels = true
+		return config
+	}
+}
+
+type instrumentation struct {
+	hooks          []PhaseHook
+	profilerLabels bool
+}
+
+func (i instrumentation) withHook(hook PhaseHook) instrumentation {
+	i.hooks = append(append([]PhaseHook(nil), i.hooks...), hook)
+	return i
+}
+
+func (i instrumentation) run(ctx context.Context, phase string, labels map[string]string, f func(ctx context.Context) error) error {
+	for _, hook := range i.hooks {
+		if hook.Start != nil {
+			hook.Start(phase, labels)
+		}
+	}
+
+	start := time.Now()
+
+	var err error
+	if i.profilerLabels {
+		pprof.Do(ctx, pprof.Labels(profilerLabels(phase, labels)...), func(ctx context.Context) {
+			err = f(ctx)
+		})
+	} else {
+		err = f(ctx)
+	}
+
+	duration := time.Since(start)
+
+	for _, hook := range i.hooks {
+		if hook.Stop != nil {
+			hook.Stop(phase, labels, duration, err)
+		}
+	}
+
+	return err
+}
+
+func profilerLabels(phase string, labels map[string]string) []string {
+	var keys []string
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := []string{"switchblade.phase", phase}
+	for _, key := range keys {
+		pairs = append(pairs, "switchblade."+key, labels[key])
+	}
+
+	return pairs
+}
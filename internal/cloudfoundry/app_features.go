@@ -0,0 +1,110 @@
+package cloudfoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type AppFeatureReader struct {
+	cli Executable
+}
+
+func NewAppFeatureReader(cli Executable) AppFeatureReader {
+	return AppFeatureReader{
+		cli: cli,
+	}
+}
+
+func (r AppFeatureReader) Read(ctx context.Context, home, name string) (map[string]bool, error) {
+	r.cli = withContext(ctx, r.cli)
+
+	env := append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home))
+
+	guid, err := appGUID(r.cli, env, name)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	err = r.cli.Execute(pexec.Execution{
+		Args:   []string{"curl", path.Join("/v3", "apps", guid, "features")},
+		Stdout: buffer,
+		Stderr: buffer,
+		Env:    env,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch app features: %w\n\nOutput:\n%s", err, buffer)
+	}
+
+	var features struct {
+		Resources []struct {
+			Name    string `json:"name"`
+			Enabled bool   `json:"enabled"`
+		} `json:"resources"`
+	}
+	err = json.NewDecoder(buffer).Decode(&features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse app features: %w\n\nOutput:\n%s", err, buffer)
+	}
+
+	enabled := make(map[string]bool)
+	for _, feature := range features.Resources {
+		enabled[feature.Name] = feature.Enabled
+	}
+
+	return enabled, nil
+}
+
+func updateAppFeatures(cli Executable, log io.Writer, env []string, name string, features map[string]bool) error {
+	guid, err := appGUID(cli, env, name)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for feature := range features {
+		names = append(names, feature)
+	}
+	sort.Strings(names)
+
+	for _, feature := range names {
+		err = cli.Execute(pexec.Execution{
+			Args: []string{
+				"curl", "-X", "PATCH", path.Join("/v3", "apps", guid, "features", feature),
+				"-d", fmt.Sprintf(`{"enabled":%t}`, features[feature]),
+			},
+			Stdout: log,
+			Stderr: log,
+			Env:    env,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update app feature %s: %w\n\nOutput:\n%s", feature, err, log)
+		}
+	}
+
+	return nil
+}
+
+func appGUID(cli Executable, env []string, name string) (string, error) {
+	buffer := bytes.NewBuffer(nil)
+	err := cli.Execute(pexec.Execution{
+		Args:   []string{"app", name, "--guid"},
+		Stdout: buffer,
+		Stderr: buffer,
+		Env:    env,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch guid: %w\n\nOutput:\n%s", err, buffer)
+	}
+
+	return strings.TrimSpace(buffer.String()), nil
+}
@@ -0,0 +1,125 @@
+package cloudfoundry_test
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+func testAppFeatureReader(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Read", func() {
+		var (
+			reader cloudfoundry.AppFeatureReader
+
+			executable *fakes.Executable
+			executions []pexec.Execution
+		)
+
+		it.Before(func() {
+			executions = nil
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				executions = append(executions, execution)
+
+				switch strings.Join(execution.Args, " ") {
+				case "app some-app --guid":
+					fmt.Fprintln(execution.Stdout, "some-app-guid")
+				case "curl /v3/apps/some-app-guid/features":
+					fmt.Fprintln(execution.Stdout, `{
+						"resources": [
+							{"name": "ssh", "description": "Enable SSHing into the app.", "enabled": true},
+							{"name": "revisions", "description": "Enable versioning of an application", "enabled": false}
+						]
+					}`)
+				}
+
+				return nil
+			}
+
+			reader = cloudfoundry.NewAppFeatureReader(executable)
+		})
+
+		it("returns whether each feature is enabled on the app", func() {
+			features, err := reader.Read(gocontext.Background(), "/some/home", "some-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(features).To(Equal(map[string]bool{
+				"ssh":       true,
+				"revisions": false,
+			}))
+
+			Expect(executions).To(HaveLen(2))
+			Expect(executions[1]).To(MatchFields(IgnoreExtras, Fields{
+				"Args": Equal([]string{"curl", "/v3/apps/some-app-guid/features"}),
+				"Env":  ContainElement("CF_HOME=/some/home"),
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the guid cannot be fetched", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						fmt.Fprintln(execution.Stdout, "App 'some-app' not found.")
+						return errors.New("exit status 1")
+					}
+				})
+
+				it("returns an error and the output", func() {
+					_, err := reader.Read(gocontext.Background(), "/some/home", "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to fetch guid: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("App 'some-app' not found.")))
+				})
+			})
+
+			context("when the features cannot be fetched", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						if execution.Args[0] == "curl" {
+							fmt.Fprintln(execution.Stdout, "Unauthorized")
+							return errors.New("exit status 1")
+						}
+
+						fmt.Fprintln(execution.Stdout, "some-app-guid")
+						return nil
+					}
+				})
+
+				it("returns an error and the output", func() {
+					_, err := reader.Read(gocontext.Background(), "/some/home", "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to fetch app features: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("Unauthorized")))
+				})
+			})
+
+			context("when the features cannot be parsed", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						if execution.Args[0] == "curl" {
+							fmt.Fprintln(execution.Stdout, "%%%")
+							return nil
+						}
+
+						fmt.Fprintln(execution.Stdout, "some-app-guid")
+						return nil
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := reader.Read(gocontext.Background(), "/some/home", "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to parse app features:")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,217 @@
+package cloudfoundry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type Interaction struct {
+	Args   []string `json:"args"`
+	Stdout string   `json:"stdout,omitempty"`
+	Stderr string   `json:"stderr,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+type RecordingExecutable struct {
+	cli  Executable
+	path string
+	m    *sync.Mutex
+}
+
+func NewRecordingExecutable(cli Executable, path string) RecordingExecutable {
+	return RecordingExecutable{
+		cli:  cli,
+		path: path,
+		m:    &sync.Mutex{},
+	}
+}
+
+func (e RecordingExecutable) Execute(execution pexec.Execution) error {
+	return e.ExecuteContext(context.Background(), execution)
+}
+
+func (e RecordingExecutable) ExecuteContext(ctx context.Context, execution pexec.Execution) error {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+
+	interaction := Interaction{Args: execution.Args}
+
+	recorded := execution
+	recorded.Stdout = tee(execution.Stdout, stdout)
+	recorded.Stderr = tee(execution.Stderr, stderr)
+
+	err := executeContext(ctx, e.cli, recorded)
+	if err != nil {
+		interaction.Error = err.Error()
+	}
+
+	interaction.Stdout = stdout.String()
+	interaction.Stderr = stderr.String()
+
+	recordErr := e.record(interaction)
+	if recordErr != nil {
+		return recordErr
+	}
+
+	return err
+}
+
+func (e RecordingExecutable) record(interaction Interaction) error {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	content, err := json.Marshal(interaction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette interaction: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(e.path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	file, err := os.OpenFile(e.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(content, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return file.Close()
+}
+
+type ReplayingExecutable struct {
+	interactions []Interaction
+	used         []bool
+	m            *sync.Mutex
+}
+
+func NewReplayingExecutable(path string) (ReplayingExecutable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ReplayingExecutable{}, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer file.Close()
+
+	var interactions []Interaction
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		err = json.Unmarshal(scanner.Bytes(), &interaction)
+		if err != nil {
+			return ReplayingExecutable{}, fmt.Errorf("failed to parse cassette: %w", err)
+		}
+
+		interactions = append(interactions, interaction)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return ReplayingExecutable{}, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	return ReplayingExecutable{
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+		m:            &sync.Mutex{},
+	}, nil
+}
+
+func (e ReplayingExecutable) Execute(execution pexec.Execution) error {
+	interaction, ok := e.next(execution.Args)
+	if !ok {
+		return fmt.Errorf("failed to replay cassette: no unused interaction for `cf %s`", strings.Join(execution.Args, " "))
+	}
+
+	if execution.Stdout != nil {
+		_, err := io.WriteString(execution.Stdout, interaction.Stdout)
+		if err != nil {
+			return err
+		}
+	}
+
+	if execution.Stderr != nil {
+		_, err := io.WriteString(execution.Stderr, interaction.Stderr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if interaction.Error != "" {
+		return errors.New(interaction.Error)
+	}
+
+	return nil
+}
+
+func (e ReplayingExecutable) Remaining() []Interaction {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	var remaining []Interaction
+	for i, interaction := range e.interactions {
+		if !e.used[i] {
+			remaining = append(remaining, interaction)
+		}
+	}
+
+	return remaining
+}
+
+func (e ReplayingExecutable) next(args []string) (Interaction, bool) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	for i, interaction := range e.interactions {
+		if e.used[i] || !equalArgs(interaction.Args, args) {
+			continue
+		}
+
+		e.used[i] = true
+		return interaction, true
+	}
+
+	return Interaction{}, false
+}
+
+func equalArgs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func tee(w io.Writer, buffer *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buffer
+	}
+
+	return io.MultiWriter(w, buffer)
+}
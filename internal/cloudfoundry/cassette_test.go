@@ -0,0 +1,174 @@
+package cloudfoundry_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCassette(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir  string
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "cassette")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(dir, "cassettes", "some-cassette.jsonl")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	context("RecordingExecutable", func() {
+		var (
+			executable *fakes.Executable
+			recorder   cloudfoundry.RecordingExecutable
+		)
+
+		it.Before(func() {
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				switch execution.Args[0] {
+				case "curl":
+					fmt.Fprint(execution.Stdout, `{"resources":[]}`)
+				case "push":
+					fmt.Fprint(execution.Stdout, "Pushing app...")
+					fmt.Fprint(execution.Stderr, "something bad happened")
+					return errors.New("exit status 1")
+				}
+
+				return nil
+			}
+
+			recorder = cloudfoundry.NewRecordingExecutable(executable, path)
+		})
+
+		it("passes executions through and records them to the cassette", func() {
+			stdout := bytes.NewBuffer(nil)
+			err := recorder.Execute(pexec.Execution{
+				Args:   []string{"curl", "/v3/apps"},
+				Stdout: stdout,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal(`{"resources":[]}`))
+
+			stderr := bytes.NewBuffer(nil)
+			err = recorder.Execute(pexec.Execution{
+				Args:   []string{"push", "some-app"},
+				Stdout: stdout,
+				Stderr: stderr,
+			})
+			Expect(err).To(MatchError("exit status 1"))
+			Expect(stderr.String()).To(Equal("something bad happened"))
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(`{"args":["curl","/v3/apps"],"stdout":"{\"resources\":[]}"}
+{"args":["push","some-app"],"stdout":"Pushing app...","stderr":"something bad happened","error":"exit status 1"}
+`))
+		})
+
+		context("failure cases", func() {
+			context("when the cassette cannot be written", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(path, os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := recorder.Execute(pexec.Execution{Args: []string{"curl", "/v3/apps"}})
+					Expect(err).To(MatchError(ContainSubstring("failed to open cassette:")))
+				})
+			})
+		})
+	})
+
+	context("ReplayingExecutable", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Dir(path), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(`{"args":["curl","/v3/apps"],"stdout":"first"}
+{"args":["push","some-app"],"stdout":"Pushing app...","stderr":"something bad happened","error":"exit status 1"}
+
+{"args":["curl","/v3/apps"],"stdout":"second"}
+`), 0600)).To(Succeed())
+		})
+
+		it("replays the recorded output for matching executions", func() {
+			replayer, err := cloudfoundry.NewReplayingExecutable(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			stdout := bytes.NewBuffer(nil)
+			stderr := bytes.NewBuffer(nil)
+			err = replayer.Execute(pexec.Execution{
+				Args:   []string{"push", "some-app"},
+				Stdout: stdout,
+				Stderr: stderr,
+			})
+			Expect(err).To(MatchError("exit status 1"))
+			Expect(stdout.String()).To(Equal("Pushing app..."))
+			Expect(stderr.String()).To(Equal("something bad happened"))
+
+			stdout.Reset()
+			Expect(replayer.Execute(pexec.Execution{Args: []string{"curl", "/v3/apps"}, Stdout: stdout})).To(Succeed())
+			Expect(stdout.String()).To(Equal("first"))
+
+			Expect(replayer.Remaining()).To(Equal([]cloudfoundry.Interaction{
+				{Args: []string{"curl", "/v3/apps"}, Stdout: "second"},
+			}))
+
+			stdout.Reset()
+			Expect(replayer.Execute(pexec.Execution{Args: []string{"curl", "/v3/apps"}, Stdout: stdout})).To(Succeed())
+			Expect(stdout.String()).To(Equal("second"))
+
+			Expect(replayer.Remaining()).To(BeEmpty())
+		})
+
+		context("failure cases", func() {
+			context("when the cassette does not exist", func() {
+				it("returns an error", func() {
+					_, err := cloudfoundry.NewReplayingExecutable(filepath.Join(dir, "missing.jsonl"))
+					Expect(err).To(MatchError(ContainSubstring("failed to open cassette:")))
+				})
+			})
+
+			context("when the cassette is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("%%%\n"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := cloudfoundry.NewReplayingExecutable(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse cassette:")))
+				})
+			})
+
+			context("when no recorded interaction matches", func() {
+				it("returns an error", func() {
+					replayer, err := cloudfoundry.NewReplayingExecutable(path)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(replayer.Execute(pexec.Execution{Args: []string{"push", "some-app"}})).To(HaveOccurred())
+
+					err = replayer.Execute(pexec.Execution{Args: []string{"push", "some-app"}})
+					Expect(err).To(MatchError("failed to replay cassette: no unused interaction for `cf push some-app`"))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,43 @@
+package cloudfoundry
+
+import (
+	"context"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type EventRecorder func(operation, resource string, duration time.Duration, err error)
+
+type EventingExecutable struct {
+	cli    Executable
+	record EventRecorder
+}
+
+func NewEventingExecutable(cli Executable, record EventRecorder) EventingExecutable {
+	return EventingExecutable{
+		cli:    cli,
+		record: record,
+	}
+}
+
+func (e EventingExecutable) Execute(execution pexec.Execution) error {
+	return e.ExecuteContext(context.Background(), execution)
+}
+
+func (e EventingExecutable) ExecuteContext(ctx context.Context, execution pexec.Execution) error {
+	start := time.Now()
+	err := executeContext(ctx, e.cli, execution)
+
+	var operation, resource string
+	if len(execution.Args) > 0 {
+		operation = execution.Args[0]
+	}
+	if len(execution.Args) > 1 {
+		resource = execution.Args[1]
+	}
+
+	e.record(operation, resource, time.Since(start), err)
+
+	return err
+}
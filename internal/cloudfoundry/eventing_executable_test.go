@@ -0,0 +1,79 @@
+package cloudfoundry_test
+
+import (
+	gocontext "context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testEventingExecutable(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		executable *fakes.Executable
+		events     [][]string
+		errs       []error
+		eventing   cloudfoundry.EventingExecutable
+	)
+
+	it.Before(func() {
+		executable = &fakes.Executable{}
+		events = nil
+		errs = nil
+
+		eventing = cloudfoundry.NewEventingExecutable(executable, func(operation, resource string, duration time.Duration, err error) {
+			events = append(events, []string{operation, resource})
+			errs = append(errs, err)
+		})
+	})
+
+	it("records each invocation", func() {
+		err := eventing.Execute(pexec.Execution{Args: []string{"push", "some-app", "-p", "/some/path"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = eventing.Execute(pexec.Execution{Args: []string{"target"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(executable.ExecuteCall.CallCount).To(Equal(2))
+		Expect(events).To(Equal([][]string{
+			{"push", "some-app"},
+			{"target", ""},
+		}))
+		Expect(errs).To(Equal([]error{nil, nil}))
+	})
+
+	context("when the invocation fails", func() {
+		it.Before(func() {
+			executable.ExecuteCall.Returns.Error = errors.New("exit status 1")
+		})
+
+		it("records the error and returns it", func() {
+			err := eventing.Execute(pexec.Execution{Args: []string{"start", "some-app"}})
+			Expect(err).To(MatchError("exit status 1"))
+
+			Expect(events).To(Equal([][]string{{"start", "some-app"}}))
+			Expect(errs).To(Equal([]error{errors.New("exit status 1")}))
+		})
+	})
+	context("when the context is done", func() {
+		it("records the error without invoking the executable", func() {
+			ctx, cancel := gocontext.WithCancel(gocontext.Background())
+			cancel()
+
+			err := eventing.ExecuteContext(ctx, pexec.Execution{Args: []string{"start", "some-app"}})
+			Expect(err).To(MatchError(gocontext.Canceled))
+
+			Expect(executable.ExecuteCall.CallCount).To(Equal(0))
+			Expect(events).To(Equal([][]string{{"start", "some-app"}}))
+			Expect(errs).To(Equal([]error{gocontext.Canceled}))
+		})
+	})
+}
@@ -1,8 +1,79 @@
 package cloudfoundry
 
-import "github.com/paketo-buildpacks/packit/v2/pexec"
+import (
+	"context"
+	"os/exec"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
 
 //go:generate faux --interface Executable --output fakes/executable.go
 type Executable interface {
 	Execute(pexec.Execution) error
 }
+
+// ContextExecutable is implemented by executables that can stop a running
+// command once its context is done.
+type ContextExecutable interface {
+	ExecuteContext(ctx context.Context, execution pexec.Execution) error
+}
+
+type Command struct {
+	name string
+}
+
+func NewCommand(name string) Command {
+	return Command{
+		name: name,
+	}
+}
+
+func (c Command) Execute(execution pexec.Execution) error {
+	return c.ExecuteContext(context.Background(), execution)
+}
+
+func (c Command) ExecuteContext(ctx context.Context, execution pexec.Execution) error {
+	cmd := exec.CommandContext(ctx, c.name, execution.Args...)
+	cmd.Dir = execution.Dir
+	if len(execution.Env) > 0 {
+		cmd.Env = execution.Env
+	}
+	cmd.Stdout = execution.Stdout
+	cmd.Stderr = execution.Stderr
+
+	err := cmd.Run()
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+type contextExecutable struct {
+	ctx context.Context
+	cli Executable
+}
+
+func withContext(ctx context.Context, cli Executable) Executable {
+	return contextExecutable{
+		ctx: ctx,
+		cli: cli,
+	}
+}
+
+func (e contextExecutable) Execute(execution pexec.Execution) error {
+	return executeContext(e.ctx, e.cli, execution)
+}
+
+func executeContext(ctx context.Context, cli Executable, execution pexec.Execution) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	if c, ok := cli.(ContextExecutable); ok {
+		return c.ExecuteContext(ctx, execution)
+	}
+
+	return cli.Execute(execution)
+}
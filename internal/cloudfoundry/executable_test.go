@@ -0,0 +1,54 @@
+package cloudfoundry_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCommand(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Execute", func() {
+		it("runs the command", func() {
+			buffer := bytes.NewBuffer(nil)
+
+			err := cloudfoundry.NewCommand("echo").Execute(pexec.Execution{
+				Args:   []string{"some-output"},
+				Stdout: buffer,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.String()).To(Equal("some-output\n"))
+		})
+	})
+
+	context("ExecuteContext", func() {
+		it("stops the command when the context is done", func() {
+			ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			err := cloudfoundry.NewCommand("sleep").ExecuteContext(ctx, pexec.Execution{
+				Args: []string{"10"},
+			})
+			Expect(err).To(MatchError(gocontext.DeadlineExceeded))
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		})
+
+		context("failure cases", func() {
+			context("when the command fails", func() {
+				it("returns an error", func() {
+					err := cloudfoundry.NewCommand("false").ExecuteContext(gocontext.Background(), pexec.Execution{})
+					Expect(err).To(MatchError("exit status 1"))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,29 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+)
+
+type BuildpackLister struct {
+	ListCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			BuildpackSlice []cloudfoundry.Buildpack
+			Error          error
+		}
+		Stub func() ([]cloudfoundry.Buildpack, error)
+	}
+}
+
+func (f *BuildpackLister) List() ([]cloudfoundry.Buildpack, error) {
+	f.ListCall.mutex.Lock()
+	defer f.ListCall.mutex.Unlock()
+	f.ListCall.CallCount++
+	if f.ListCall.Stub != nil {
+		return f.ListCall.Stub()
+	}
+	return f.ListCall.Returns.BuildpackSlice, f.ListCall.Returns.Error
+}
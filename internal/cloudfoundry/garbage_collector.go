@@ -0,0 +1,171 @@
+package cloudfoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+const ResourcePrefix = "switchblade-"
+
+type GarbageCollector struct {
+	cli       Executable
+	workspace string
+}
+
+func NewGarbageCollector(cli Executable, workspace string) GarbageCollector {
+	return GarbageCollector{
+		cli:       cli,
+		workspace: workspace,
+	}
+}
+
+func (g GarbageCollector) Run(ctx context.Context, olderThan time.Duration) error {
+	g.cli = withContext(ctx, g.cli)
+
+	logs := bytes.NewBuffer(nil)
+
+	resources, err := g.list(logs, olderThan)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		switch resource.Kind {
+		case "space":
+			err = g.cli.Execute(pexec.Execution{
+				Args:   []string{"curl", "-X", "DELETE", fmt.Sprintf("/v3/spaces/%s", resource.ID)},
+				Stdout: logs,
+				Stderr: logs,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to delete space: %w\n\nOutput:\n%s", err, logs)
+			}
+
+		case "org", "security-group":
+			command := fmt.Sprintf("delete-%s", resource.Kind)
+			err = g.cli.Execute(pexec.Execution{
+				Args:   []string{command, resource.Name, "-f"},
+				Stdout: logs,
+				Stderr: logs,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to %s: %w\n\nOutput:\n%s", command, err, logs)
+			}
+
+		case "file":
+			err = os.RemoveAll(resource.Name)
+			if err != nil {
+				return fmt.Errorf("failed to delete workspace entry: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g GarbageCollector) List(ctx context.Context, olderThan time.Duration) ([]Resource, error) {
+	g.cli = withContext(ctx, g.cli)
+
+	return g.list(bytes.NewBuffer(nil), olderThan)
+}
+
+func (g GarbageCollector) list(logs io.Writer, olderThan time.Duration) ([]Resource, error) {
+	now := time.Now()
+	cutoff := now.Add(-olderThan)
+
+	var resources []Resource
+	for _, resource := range []struct {
+		path string
+		kind string
+	}{
+		{path: "/v3/spaces", kind: "space"},
+		{path: "/v3/organizations", kind: "org"},
+		{path: "/v3/security_groups", kind: "security-group"},
+	} {
+		stale, err := g.staleResources(logs, resource.path, resource.kind, now, cutoff)
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, stale...)
+	}
+
+	entries, err := os.ReadDir(g.workspace)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read workspace: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ResourcePrefix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat workspace entry: %w", err)
+		}
+
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		resources = append(resources, Resource{Kind: "file", Name: filepath.Join(g.workspace, entry.Name()), Age: now.Sub(info.ModTime())})
+	}
+
+	return resources, nil
+}
+
+func (g GarbageCollector) staleResources(logs io.Writer, path, kind string, now, cutoff time.Time) ([]Resource, error) {
+	resources, err := listResources(g.cli, logs, nil, fmt.Sprintf("%s?per_page=5000", path))
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []Resource
+	for _, resource := range resources {
+		if strings.HasPrefix(resource.Name, ResourcePrefix) && resource.CreatedAt.Before(cutoff) {
+			stale = append(stale, Resource{Kind: kind, Name: resource.Name, ID: resource.GUID, Age: now.Sub(resource.CreatedAt)})
+		}
+	}
+
+	return stale, nil
+}
+
+type apiResource struct {
+	GUID      string    `json:"guid"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func listResources(cli Executable, logs io.Writer, env []string, path string) ([]apiResource, error) {
+	buffer := bytes.NewBuffer(nil)
+	err := cli.Execute(pexec.Execution{
+		Args:   []string{"curl", path},
+		Stdout: io.MultiWriter(buffer, logs),
+		Stderr: logs,
+		Env:    env,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to curl %s: %w\n\nOutput:\n%s", strings.Split(path, "?")[0], err, logs)
+	}
+
+	var resources struct {
+		Resources []apiResource `json:"resources"`
+	}
+	err = json.NewDecoder(buffer).Decode(&resources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s json: %w", strings.Split(path, "?")[0], err)
+	}
+
+	return resources.Resources, nil
+}
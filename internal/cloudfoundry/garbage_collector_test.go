@@ -0,0 +1,200 @@
+package cloudfoundry_test
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+func testGarbageCollector(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Run", func() {
+		var (
+			collector cloudfoundry.GarbageCollector
+
+			executable *fakes.Executable
+			workspace  string
+
+			executions []pexec.Execution
+		)
+
+		it.Before(func() {
+			old := time.Now().Add(-2 * time.Hour)
+
+			executions = nil
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				executions = append(executions, execution)
+
+				if execution.Args[0] == "curl" {
+					return json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{
+						"resources": []map[string]interface{}{
+							{"guid": "old-guid", "name": "switchblade-old", "created_at": old.Format(time.RFC3339)},
+							{"name": "switchblade-new", "created_at": time.Now().Format(time.RFC3339)},
+							{"name": "some-other-resource", "created_at": old.Format(time.RFC3339)},
+						},
+					})
+				}
+
+				return nil
+			}
+
+			var err error
+			workspace, err = os.MkdirTemp("", "workspace")
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, name := range []string{"switchblade-old", "switchblade-new", "some-other-dir"} {
+				Expect(os.MkdirAll(filepath.Join(workspace, name), os.ModePerm)).To(Succeed())
+			}
+			Expect(os.Chtimes(filepath.Join(workspace, "switchblade-old"), old, old)).To(Succeed())
+			Expect(os.Chtimes(filepath.Join(workspace, "some-other-dir"), old, old)).To(Succeed())
+
+			collector = cloudfoundry.NewGarbageCollector(executable, workspace)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workspace)).To(Succeed())
+		})
+
+		it("deletes switchblade spaces, orgs, security groups, and config older than the given age", func() {
+			err := collector.Run(gocontext.Background(), time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			var commands []string
+			for _, execution := range executions {
+				commands = append(commands, strings.Join(execution.Args, " "))
+			}
+
+			Expect(commands).To(Equal([]string{
+				"curl /v3/spaces?per_page=5000",
+				"curl /v3/organizations?per_page=5000",
+				"curl /v3/security_groups?per_page=5000",
+				"curl -X DELETE /v3/spaces/old-guid",
+				"delete-org switchblade-old -f",
+				"delete-security-group switchblade-old -f",
+			}))
+
+			Expect(filepath.Join(workspace, "switchblade-old")).NotTo(BeADirectory())
+			Expect(filepath.Join(workspace, "switchblade-new")).To(BeADirectory())
+			Expect(filepath.Join(workspace, "some-other-dir")).To(BeADirectory())
+		})
+
+		context("List", func() {
+			it("returns the resources that would be deleted without deleting them", func() {
+				resources, err := collector.List(gocontext.Background(), time.Hour)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(resources).To(HaveLen(4))
+				Expect(resources[0]).To(MatchFields(IgnoreExtras, Fields{
+					"Kind": Equal("space"),
+					"Name": Equal("switchblade-old"),
+					"ID":   Equal("old-guid"),
+				}))
+				Expect(resources[1]).To(MatchFields(IgnoreExtras, Fields{
+					"Kind": Equal("org"),
+					"Name": Equal("switchblade-old"),
+					"ID":   Equal("old-guid"),
+					"Age":  BeNumerically("~", 2*time.Hour, time.Minute),
+				}))
+				Expect(resources[2]).To(MatchFields(IgnoreExtras, Fields{
+					"Kind": Equal("security-group"),
+					"Name": Equal("switchblade-old"),
+					"ID":   Equal("old-guid"),
+				}))
+				Expect(resources[3]).To(MatchFields(IgnoreExtras, Fields{
+					"Kind": Equal("file"),
+					"Name": Equal(filepath.Join(workspace, "switchblade-old")),
+					"Age":  BeNumerically("~", 2*time.Hour, time.Minute),
+				}))
+
+				for _, execution := range executions {
+					Expect(execution.Args[0]).To(Equal("curl"))
+				}
+				Expect(filepath.Join(workspace, "switchblade-old")).To(BeADirectory())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the resources cannot be listed", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						_, err := execution.Stdout.Write([]byte("some-output"))
+						Expect(err).NotTo(HaveOccurred())
+
+						return errors.New("could not curl")
+					}
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError(ContainSubstring("failed to curl /v3/spaces: could not curl")))
+					Expect(err).To(MatchError(ContainSubstring("some-output")))
+				})
+			})
+
+			context("when the resource json cannot be decoded", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						_, err := execution.Stdout.Write([]byte("%%%"))
+						return err
+					}
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError(ContainSubstring("failed to decode /v3/spaces json:")))
+				})
+			})
+
+			context("when a space cannot be deleted", func() {
+				it.Before(func() {
+					stub := executable.ExecuteCall.Stub
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						if strings.Join(execution.Args, " ") == "curl -X DELETE /v3/spaces/old-guid" {
+							return errors.New("could not delete space")
+						}
+
+						return stub(execution)
+					}
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError(ContainSubstring("failed to delete space: could not delete space")))
+				})
+			})
+
+			context("when a resource cannot be deleted", func() {
+				it.Before(func() {
+					stub := executable.ExecuteCall.Stub
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						if execution.Args[0] == "delete-org" {
+							return errors.New("could not delete org")
+						}
+
+						return stub(execution)
+					}
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError(ContainSubstring("failed to delete-org: could not delete org")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,46 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+// HomeExecutable runs every command against the given $CF_HOME unless the
+// execution already names one, so that commands which would otherwise read the
+// user's configuration stay on the platform's target.
+type HomeExecutable struct {
+	cli  Executable
+	home string
+}
+
+func NewHomeExecutable(cli Executable, home string) HomeExecutable {
+	return HomeExecutable{
+		cli:  cli,
+		home: home,
+	}
+}
+
+func (e HomeExecutable) Execute(execution pexec.Execution) error {
+	return e.ExecuteContext(context.Background(), execution)
+}
+
+func (e HomeExecutable) ExecuteContext(ctx context.Context, execution pexec.Execution) error {
+	env := execution.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	for _, variable := range env {
+		if strings.HasPrefix(variable, "CF_HOME=") {
+			return executeContext(ctx, e.cli, execution)
+		}
+	}
+
+	execution.Env = append(env[:len(env):len(env)], fmt.Sprintf("CF_HOME=%s", e.home))
+
+	return executeContext(ctx, e.cli, execution)
+}
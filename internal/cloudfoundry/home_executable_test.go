@@ -0,0 +1,65 @@
+package cloudfoundry_test
+
+import (
+	gocontext "context"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testHomeExecutable(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		executable *fakes.Executable
+		home       cloudfoundry.HomeExecutable
+	)
+
+	it.Before(func() {
+		executable = &fakes.Executable{}
+		home = cloudfoundry.NewHomeExecutable(executable, "/some/cf-home")
+	})
+
+	it("runs the command against the platform home", func() {
+		err := home.Execute(pexec.Execution{Args: []string{"buildpacks"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(executable.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"buildpacks"}))
+		Expect(executable.ExecuteCall.Receives.Execution.Env).To(ContainElement("CF_HOME=/some/cf-home"))
+	})
+
+	context("when the execution has an environment", func() {
+		it("adds the platform home to it", func() {
+			err := home.Execute(pexec.Execution{Args: []string{"delete", "some-app"}, Env: []string{"SOME_KEY=some-value"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(executable.ExecuteCall.Receives.Execution.Env).To(Equal([]string{"SOME_KEY=some-value", "CF_HOME=/some/cf-home"}))
+		})
+	})
+
+	context("when the execution names a home", func() {
+		it("leaves it alone", func() {
+			err := home.Execute(pexec.Execution{Args: []string{"push", "some-app"}, Env: []string{"CF_HOME=/some/app-home"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(executable.ExecuteCall.Receives.Execution.Env).To(Equal([]string{"CF_HOME=/some/app-home"}))
+		})
+	})
+
+	context("when the context is done", func() {
+		it("does not invoke the executable", func() {
+			ctx, cancel := gocontext.WithCancel(gocontext.Background())
+			cancel()
+
+			err := home.ExecuteContext(ctx, pexec.Execution{Args: []string{"buildpacks"}})
+			Expect(err).To(MatchError(gocontext.Canceled))
+
+			Expect(executable.ExecuteCall.CallCount).To(Equal(0))
+		})
+	})
+}
@@ -12,9 +12,21 @@ func TestCloudFoundry(t *testing.T) {
 	format.MaxLength = 0
 
 	suite := spec.New("switchblade/internal/cloudfoundry", spec.Report(report.Terminal{}), spec.Parallel())
+	suite("AppFeatureReader", testAppFeatureReader)
+	suite("Cassette", testCassette)
+	suite("Command", testCommand)
+	suite("EventingExecutable", testEventingExecutable)
+	suite("GarbageCollector", testGarbageCollector)
+	suite("HomeExecutable", testHomeExecutable)
 	suite("Initialize", testInitialize)
+	suite("LogStreamer", testLogStreamer)
+	suite("Login", testLogin)
+	suite("MetadataReader", testMetadataReader)
+	suite("Preflight", testPreflight)
+	suite("Scaler", testScaler)
 	suite("Setup", testSetup)
 	suite("Stage", testStage)
+	suite("StagedBuildpackReader", testStagedBuildpackReader)
 	suite("Teardown", testTeardown)
 	suite.Run(t)
 }
@@ -2,11 +2,19 @@ package cloudfoundry
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/cloudfoundry/switchblade/internal/filelock"
 	"github.com/paketo-buildpacks/packit/v2/pexec"
 )
 
@@ -19,60 +27,317 @@ type InitializePhase interface {
 	Run([]Buildpack) error
 }
 
+//go:generate faux --interface BuildpackLister --output fakes/buildpack_lister.go
+type BuildpackLister interface {
+	List() ([]Buildpack, error)
+}
+
 type Initialize struct {
-	cli Executable
+	cli     Executable
+	missing BuildpackLister
+	aws     Executable
+	gcloud  Executable
+	locks   string
+}
+
+// uploadRecord describes the last upload of a buildpack made from this
+// machine, so that a parallel process can tell that it need not be repeated.
+type uploadRecord struct {
+	Source    string `json:"source"`
+	GUID      string `json:"guid"`
+	UpdatedAt string `json:"updated_at"`
 }
 
 func NewInitialize(cli Executable) Initialize {
-	return Initialize{cli: cli}
+	return Initialize{
+		cli:    cli,
+		aws:    pexec.NewExecutable("aws"),
+		gcloud: pexec.NewExecutable("gcloud"),
+	}
+}
+
+func (i Initialize) WithObjectStorage(aws, gcloud Executable) Initialize {
+	i.aws = aws
+	i.gcloud = gcloud
+	return i
+}
+
+func (i Initialize) WithMissingBuildpacks(lister BuildpackLister) Initialize {
+	i.missing = lister
+	return i
+}
+
+// WithLocks serializes buildpack uploads between processes that share the
+// locks directory, such as parallel test processes targeting one foundation.
+// A buildpack that another process uploaded from the same source is left in
+// place rather than deleted and created again.
+func (i Initialize) WithLocks(dir string) Initialize {
+	i.locks = dir
+	return i
 }
 
 func (i Initialize) Run(buildpacks []Buildpack) error {
 	logs := bytes.NewBuffer(nil)
 
 	for _, buildpack := range buildpacks {
-		position := "1000"
+		err := i.withLock(fmt.Sprintf("buildpack-%s", buildpack.Name), func() error {
+			return i.replace(logs, buildpack)
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-		buffer := bytes.NewBuffer(nil)
-		err := i.cli.Execute(pexec.Execution{
-			Args:   []string{"curl", fmt.Sprintf("/v3/buildpacks?names=%s", buildpack.Name)},
-			Stdout: io.MultiWriter(buffer, logs),
-			Stderr: logs,
+	if i.missing != nil {
+		return i.withLock("missing-buildpacks", func() error {
+			return i.createMissing(logs, buildpacks)
 		})
-		if err == nil {
-			var payload struct {
-				Resources []struct {
-					Position int `json:"position"`
-				} `json:"resources"`
-			}
-			err = json.NewDecoder(buffer).Decode(&payload)
-			if err != nil {
-				return fmt.Errorf("failed to parse buildpacks: %w", err)
-			}
+	}
 
-			if len(payload.Resources) > 0 {
-				position = strconv.Itoa(payload.Resources[0].Position)
-			}
+	return nil
+}
+
+func (i Initialize) withLock(name string, fn func() error) error {
+	if i.locks == "" {
+		return fn()
+	}
+
+	unlock, err := filelock.Lock(context.Background(), filepath.Join(i.locks, fmt.Sprintf("%s.lock", name)))
+	if err != nil {
+		return fmt.Errorf("failed to lock %s: %w", name, err)
+	}
+	defer unlock()
+
+	return fn()
+}
 
-			err = i.cli.Execute(pexec.Execution{
-				Args:   []string{"delete-buildpack", "-f", buildpack.Name},
-				Stdout: logs,
-				Stderr: logs,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to delete buildpack: %s\n\nOutput:\n%s", err, logs)
+func (i Initialize) replace(logs *bytes.Buffer, buildpack Buildpack) error {
+	position := "1000"
+
+	resource, found, err := i.find(logs, buildpack.Name)
+	if err == nil {
+		if found {
+			position = strconv.Itoa(resource.Position)
+
+			if i.locks != "" {
+				uploaded, err := i.uploaded(buildpack, resource)
+				if err != nil {
+					return err
+				}
+
+				if uploaded {
+					return nil
+				}
 			}
 		}
 
 		err = i.cli.Execute(pexec.Execution{
-			Args:   []string{"create-buildpack", buildpack.Name, buildpack.URI, position},
+			Args:   []string{"delete-buildpack", "-f", buildpack.Name},
+			Stdout: logs,
+			Stderr: logs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete buildpack: %s\n\nOutput:\n%s", err, logs)
+		}
+	} else if errors.Is(err, errMalformedBuildpacks) {
+		return err
+	}
+
+	err = i.create(logs, buildpack, position)
+	if err != nil {
+		return err
+	}
+
+	if i.locks != "" {
+		return i.record(logs, buildpack)
+	}
+
+	return nil
+}
+
+var errMalformedBuildpacks = errors.New("failed to parse buildpacks")
+
+type buildpackResource struct {
+	GUID      string `json:"guid"`
+	Position  int    `json:"position"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func (i Initialize) find(logs *bytes.Buffer, name string) (buildpackResource, bool, error) {
+	buffer := bytes.NewBuffer(nil)
+	err := i.cli.Execute(pexec.Execution{
+		Args:   []string{"curl", fmt.Sprintf("/v3/buildpacks?names=%s", name)},
+		Stdout: io.MultiWriter(buffer, logs),
+		Stderr: logs,
+	})
+	if err != nil {
+		return buildpackResource{}, false, err
+	}
+
+	var payload struct {
+		Resources []buildpackResource `json:"resources"`
+	}
+	err = json.NewDecoder(buffer).Decode(&payload)
+	if err != nil {
+		return buildpackResource{}, false, fmt.Errorf("%w: %s", errMalformedBuildpacks, err)
+	}
+
+	if len(payload.Resources) == 0 {
+		return buildpackResource{}, false, nil
+	}
+
+	return payload.Resources[0], true, nil
+}
+
+func (i Initialize) uploaded(buildpack Buildpack, resource buildpackResource) (bool, error) {
+	content, err := os.ReadFile(filepath.Join(i.locks, fmt.Sprintf("buildpack-%s.json", buildpack.Name)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to read buildpack upload record: %w", err)
+	}
+
+	// A record that cannot be parsed is treated as missing, so the buildpack
+	// is simply uploaded again.
+	var record uploadRecord
+	err = json.Unmarshal(content, &record)
+	if err != nil {
+		return false, nil
+	}
+
+	source, err := buildpackSource(buildpack.URI)
+	if err != nil {
+		return false, err
+	}
+
+	return record == uploadRecord{Source: source, GUID: resource.GUID, UpdatedAt: resource.UpdatedAt}, nil
+}
+
+func (i Initialize) record(logs *bytes.Buffer, buildpack Buildpack) error {
+	resource, found, err := i.find(logs, buildpack.Name)
+	if err != nil {
+		return fmt.Errorf("failed to fetch buildpack: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	if !found {
+		return nil
+	}
+
+	source, err := buildpackSource(buildpack.URI)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(uploadRecord{Source: source, GUID: resource.GUID, UpdatedAt: resource.UpdatedAt})
+	if err != nil {
+		return fmt.Errorf("failed to marshal buildpack upload record: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(i.locks, fmt.Sprintf("buildpack-%s.json", buildpack.Name)), content, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write buildpack upload record: %w", err)
+	}
+
+	return nil
+}
+
+// buildpackSource identifies the content of a buildpack. Local files are
+// identified by their digest and remote buildpacks by their URI.
+func buildpackSource(uri string) (string, error) {
+	file, err := os.Open(uri)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return uri, nil
+		}
+
+		return "", fmt.Errorf("failed to open buildpack: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", fmt.Errorf("failed to digest buildpack: %w", err)
+	}
+
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
+
+func (i Initialize) createMissing(logs *bytes.Buffer, overrides []Buildpack) error {
+	candidates, err := i.missing.List()
+	if err != nil {
+		return fmt.Errorf("failed to list buildpacks: %w", err)
+	}
+
+	installed, err := listResources(i.cli, logs, nil, "/v3/buildpacks?per_page=5000")
+	if err != nil {
+		return err
+	}
+
+	names := map[string]struct{}{}
+	for _, resource := range installed {
+		names[normalizeBuildpackName(resource.Name)] = struct{}{}
+	}
+	for _, buildpack := range overrides {
+		names[normalizeBuildpackName(buildpack.Name)] = struct{}{}
+	}
+
+	for _, buildpack := range candidates {
+		if _, ok := names[normalizeBuildpackName(buildpack.Name)]; ok {
+			continue
+		}
+
+		err = i.create(logs, buildpack, "1000")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i Initialize) create(logs *bytes.Buffer, buildpack Buildpack, position string) error {
+	uri := buildpack.URI
+
+	var args []string
+	var cli Executable
+	switch {
+	case strings.HasPrefix(uri, "s3://"):
+		cli = i.aws
+		args = []string{"s3", "cp", "--only-show-errors"}
+	case strings.HasPrefix(uri, "gs://"):
+		cli = i.gcloud
+		args = []string{"storage", "cp"}
+	}
+
+	if cli != nil {
+		dir, err := os.MkdirTemp("", "buildpack")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		uri = filepath.Join(dir, path.Base(buildpack.URI))
+		err = cli.Execute(pexec.Execution{
+			Args:   append(args, buildpack.URI, uri),
 			Stdout: logs,
 			Stderr: logs,
 		})
 		if err != nil {
-			return fmt.Errorf("failed to create buildpack: %w\n\nOutput:\n%s", err, logs)
+			return fmt.Errorf("failed to download buildpack: %w\n\nOutput:\n%s", err, logs)
 		}
 	}
 
+	err := i.cli.Execute(pexec.Execution{
+		Args:   []string{"create-buildpack", buildpack.Name, uri, position},
+		Stdout: logs,
+		Stderr: logs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create buildpack: %w\n\nOutput:\n%s", err, logs)
+	}
+
 	return nil
 }
@@ -3,7 +3,10 @@ package cloudfoundry_test
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
@@ -130,6 +133,313 @@ func testInitialize(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("WithObjectStorage", func() {
+			var (
+				aws    *fakes.Executable
+				gcloud *fakes.Executable
+				paths  []string
+			)
+
+			it.Before(func() {
+				copyObject := func(execution pexec.Execution) error {
+					paths = append(paths, execution.Args[len(execution.Args)-1])
+					return os.WriteFile(execution.Args[len(execution.Args)-1], []byte("object-content"), 0600)
+				}
+
+				aws = &fakes.Executable{}
+				aws.ExecuteCall.Stub = copyObject
+
+				gcloud = &fakes.Executable{}
+				gcloud.ExecuteCall.Stub = copyObject
+
+				executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+					executions = append(executions, execution)
+
+					if execution.Args[0] == "curl" {
+						return errors.New("no such buildpack")
+					}
+
+					Expect(execution.Args[2]).To(BeAnExistingFile())
+
+					return nil
+				}
+
+				initialize = initialize.WithObjectStorage(aws, gcloud)
+			})
+
+			it("downloads the buildpacks before creating them", func() {
+				err := initialize.Run([]cloudfoundry.Buildpack{
+					{
+						Name: "some-buildpack-name",
+						URI:  "s3://some-bucket/some-buildpack.zip",
+					},
+					{
+						Name: "other-buildpack-name",
+						URI:  "gs://other-bucket/other-buildpack.zip",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(paths).To(HaveLen(2))
+				Expect(aws.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"s3", "cp", "--only-show-errors", "s3://some-bucket/some-buildpack.zip", paths[0]}))
+				Expect(gcloud.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"storage", "cp", "gs://other-bucket/other-buildpack.zip", paths[1]}))
+
+				Expect(filepath.Base(paths[0])).To(Equal("some-buildpack.zip"))
+				Expect(filepath.Base(paths[1])).To(Equal("other-buildpack.zip"))
+
+				Expect(executions).To(HaveLen(4))
+				Expect(executions[1]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"create-buildpack", "some-buildpack-name", paths[0], "1000"}),
+				}))
+				Expect(executions[3]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"create-buildpack", "other-buildpack-name", paths[1], "1000"}),
+				}))
+
+				Expect(paths[0]).NotTo(BeAnExistingFile())
+				Expect(paths[1]).NotTo(BeAnExistingFile())
+			})
+
+			context("failure cases", func() {
+				context("when the buildpack cannot be downloaded", func() {
+					it.Before(func() {
+						aws.ExecuteCall.Stub = func(execution pexec.Execution) error {
+							fmt.Fprintln(execution.Stderr, "access denied")
+							return errors.New("exit status 1")
+						}
+					})
+
+					it("returns an error", func() {
+						err := initialize.Run([]cloudfoundry.Buildpack{
+							{
+								Name: "some-buildpack-name",
+								URI:  "s3://some-bucket/some-buildpack.zip",
+							},
+						})
+						Expect(err).To(MatchError("failed to download buildpack: exit status 1\n\nOutput:\naccess denied\n"))
+					})
+				})
+			})
+		})
+
+		context("WithMissingBuildpacks", func() {
+			var lister *fakes.BuildpackLister
+
+			it.Before(func() {
+				lister = &fakes.BuildpackLister{}
+				lister.ListCall.Returns.BuildpackSlice = []cloudfoundry.Buildpack{
+					{Name: "some-buildpack-name", URI: "some-listed-uri"},
+					{Name: "installed_buildpack", URI: "installed-uri"},
+					{Name: "missing_buildpack", URI: "missing-uri"},
+				}
+
+				executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+					executions = append(executions, execution)
+
+					args := strings.Join(execution.Args, " ")
+					switch {
+					case strings.Contains(args, "curl /v3/buildpacks?names=some-buildpack-name"):
+						return errors.New("no such buildpack")
+
+					case strings.Contains(args, "curl /v3/buildpacks?per_page=5000"):
+						fmt.Fprint(execution.Stdout, `{"resources":[{"name": "installed-buildpack"}]}`)
+					}
+
+					return nil
+				}
+
+				initialize = initialize.WithMissingBuildpacks(lister)
+			})
+
+			it("creates the listed buildpacks that are not installed or overridden", func() {
+				err := initialize.Run([]cloudfoundry.Buildpack{
+					{
+						Name: "some-buildpack-name",
+						URI:  "some-buildpack-uri",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(lister.ListCall.CallCount).To(Equal(1))
+
+				Expect(executions).To(HaveLen(4))
+				Expect(executions[0]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"curl", "/v3/buildpacks?names=some-buildpack-name"}),
+				}))
+				Expect(executions[1]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"create-buildpack", "some-buildpack-name", "some-buildpack-uri", "1000"}),
+				}))
+				Expect(executions[2]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"curl", "/v3/buildpacks?per_page=5000"}),
+				}))
+				Expect(executions[3]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"create-buildpack", "missing_buildpack", "missing-uri", "1000"}),
+				}))
+			})
+
+			context("failure cases", func() {
+				context("when the buildpacks cannot be listed", func() {
+					it.Before(func() {
+						lister.ListCall.Returns.Error = errors.New("failed to list")
+					})
+
+					it("returns an error", func() {
+						err := initialize.Run(nil)
+						Expect(err).To(MatchError("failed to list buildpacks: failed to list"))
+					})
+				})
+
+				context("when the installed buildpacks cannot be listed", func() {
+					it.Before(func() {
+						executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+							fmt.Fprintln(execution.Stderr, "something bad happened")
+							return errors.New("curl failed")
+						}
+					})
+
+					it("returns an error", func() {
+						err := initialize.Run(nil)
+						Expect(err).To(MatchError("failed to curl /v3/buildpacks: curl failed\n\nOutput:\nsomething bad happened\n"))
+					})
+				})
+
+				context("when a missing buildpack cannot be created", func() {
+					it.Before(func() {
+						executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+							args := strings.Join(execution.Args, " ")
+							switch {
+							case strings.Contains(args, "curl /v3/buildpacks?per_page=5000"):
+								fmt.Fprint(execution.Stdout, `{"resources":[]}`)
+
+							case strings.Contains(args, "create-buildpack missing_buildpack"):
+								fmt.Fprintln(execution.Stderr, "something bad happened")
+								return errors.New("create-buildpack failed")
+							}
+
+							return nil
+						}
+					})
+
+					it("returns an error", func() {
+						err := initialize.Run(nil)
+						Expect(err).To(MatchError(ContainSubstring("failed to create buildpack: create-buildpack failed\n\nOutput:\n")))
+					})
+				})
+			})
+		})
+
+		context("WithLocks", func() {
+			var (
+				locks     string
+				buildpack string
+				mutex     sync.Mutex
+				installed map[string]int
+				creates   int
+			)
+
+			it.Before(func() {
+				var err error
+				locks, err = os.MkdirTemp("", "locks")
+				Expect(err).NotTo(HaveOccurred())
+
+				buildpack = filepath.Join(locks, "some-buildpack.zip")
+				Expect(os.WriteFile(buildpack, []byte("some-buildpack-content"), 0600)).To(Succeed())
+
+				// The fake foundation gives every created buildpack a new guid.
+				installed = map[string]int{}
+				executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					executions = append(executions, execution)
+
+					name := execution.Args[len(execution.Args)-1]
+					switch execution.Args[0] {
+					case "curl":
+						name = strings.TrimPrefix(name, "/v3/buildpacks?names=")
+						if generation, ok := installed[name]; ok {
+							fmt.Fprintf(execution.Stdout, `{"resources":[{"guid": "guid-%[1]d", "position": 1, "updated_at": "2023-01-0%[1]dT00:00:00Z"}]}`, generation)
+						} else {
+							fmt.Fprint(execution.Stdout, `{"resources":[]}`)
+						}
+					case "delete-buildpack":
+						delete(installed, name)
+					case "create-buildpack":
+						creates++
+						installed[execution.Args[1]] = creates
+					}
+
+					return nil
+				}
+
+				initialize = initialize.WithLocks(locks)
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(locks)).To(Succeed())
+			})
+
+			it("uploads a buildpack only once for processes sharing the locks", func() {
+				var wg sync.WaitGroup
+				errs := make(chan error, 4)
+				for i := 0; i < 4; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						errs <- initialize.Run([]cloudfoundry.Buildpack{{Name: "some-buildpack-name", URI: buildpack}})
+					}()
+				}
+				wg.Wait()
+				close(errs)
+
+				for err := range errs {
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				Expect(creates).To(Equal(1))
+				Expect(filepath.Join(locks, "buildpack-some-buildpack-name.json")).To(BeARegularFile())
+			})
+
+			context("when the buildpack source changes", func() {
+				it("uploads it again", func() {
+					err := initialize.Run([]cloudfoundry.Buildpack{{Name: "some-buildpack-name", URI: buildpack}})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(os.WriteFile(buildpack, []byte("other-buildpack-content"), 0600)).To(Succeed())
+
+					err = initialize.Run([]cloudfoundry.Buildpack{{Name: "some-buildpack-name", URI: buildpack}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(creates).To(Equal(2))
+				})
+			})
+
+			context("when the buildpack was replaced on the foundation", func() {
+				it("uploads it again", func() {
+					err := initialize.Run([]cloudfoundry.Buildpack{{Name: "some-buildpack-name", URI: buildpack}})
+					Expect(err).NotTo(HaveOccurred())
+
+					installed["some-buildpack-name"] = 7
+
+					err = initialize.Run([]cloudfoundry.Buildpack{{Name: "some-buildpack-name", URI: buildpack}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(creates).To(Equal(2))
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the lock cannot be taken", func() {
+					it.Before(func() {
+						Expect(os.WriteFile(filepath.Join(locks, "some-file"), nil, 0600)).To(Succeed())
+						initialize = initialize.WithLocks(filepath.Join(locks, "some-file"))
+					})
+
+					it("returns an error", func() {
+						err := initialize.Run([]cloudfoundry.Buildpack{{Name: "some-buildpack-name", URI: buildpack}})
+						Expect(err).To(MatchError(ContainSubstring("failed to lock buildpack-some-buildpack-name:")))
+					})
+				})
+			})
+		})
+
 		context("failure cases", func() {
 			context("when the buildpack JSON cannot be parsed", func() {
 				it.Before(func() {
@@ -0,0 +1,34 @@
+package cloudfoundry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type LogStreamer struct {
+	cli Executable
+}
+
+func NewLogStreamer(cli Executable) LogStreamer {
+	return LogStreamer{
+		cli: cli,
+	}
+}
+
+func (s LogStreamer) Stream(ctx context.Context, home, name string, w io.Writer) error {
+	err := withContext(ctx, s.cli).Execute(pexec.Execution{
+		Args:   []string{"logs", name},
+		Stdout: w,
+		Stderr: w,
+		Env:    append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home)),
+	})
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to stream logs: %w", err)
+	}
+
+	return nil
+}
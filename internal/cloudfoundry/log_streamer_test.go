@@ -0,0 +1,74 @@
+package cloudfoundry_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testLogStreamer(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		streamer   cloudfoundry.LogStreamer
+		executable *fakes.Executable
+	)
+
+	it.Before(func() {
+		executable = &fakes.Executable{}
+		executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+			fmt.Fprintln(execution.Stdout, "Retrieving logs for app some-app...")
+			fmt.Fprintln(execution.Stdout, "[APP/PROC/WEB/0] OUT Listening on port 8080")
+			return nil
+		}
+
+		streamer = cloudfoundry.NewLogStreamer(executable)
+	})
+
+	context("Stream", func() {
+		it("tails the app logs", func() {
+			logs := bytes.NewBuffer(nil)
+
+			err := streamer.Stream(gocontext.Background(), "/some/home", "some-app", logs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logs.String()).To(ContainSubstring("[APP/PROC/WEB/0] OUT Listening on port 8080"))
+
+			Expect(executable.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"logs", "some-app"}))
+			Expect(executable.ExecuteCall.Receives.Execution.Env).To(ContainElement("CF_HOME=/some/home"))
+		})
+
+		context("when the context is done", func() {
+			it("stops without an error", func() {
+				ctx, cancel := gocontext.WithCancel(gocontext.Background())
+				cancel()
+
+				err := streamer.Stream(ctx, "/some/home", "some-app", bytes.NewBuffer(nil))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(executable.ExecuteCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the logs cannot be streamed", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = nil
+					executable.ExecuteCall.Returns.Error = errors.New("exit status 1")
+				})
+
+				it("returns an error", func() {
+					err := streamer.Stream(gocontext.Background(), "/some/home", "some-app", bytes.NewBuffer(nil))
+					Expect(err).To(MatchError("failed to stream logs: exit status 1"))
+				})
+			})
+		})
+	})
+}
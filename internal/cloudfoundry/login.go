@@ -0,0 +1,89 @@
+package cloudfoundry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type Target struct {
+	API               string
+	Username          string
+	Password          string
+	Org               string
+	Space             string
+	SkipSSLValidation bool
+}
+
+type Login struct {
+	cli Executable
+}
+
+func NewLogin(cli Executable) Login {
+	return Login{
+		cli: cli,
+	}
+}
+
+func (l Login) Run(home string, target Target) error {
+	err := os.MkdirAll(home, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to make $CF_HOME: %w", err)
+	}
+
+	env := append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home))
+	logs := bytes.NewBuffer(nil)
+
+	args := []string{"api", target.API}
+	if target.SkipSSLValidation {
+		args = append(args, "--skip-ssl-validation")
+	}
+
+	err = l.cli.Execute(pexec.Execution{
+		Args:   args,
+		Stdout: logs,
+		Stderr: logs,
+		Env:    env,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set api: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	// The credentials are passed through the environment so that they do not
+	// appear in process listings or recorded cassettes.
+	err = l.cli.Execute(pexec.Execution{
+		Args:   []string{"auth"},
+		Stdout: logs,
+		Stderr: logs,
+		Env:    append(env, fmt.Sprintf("CF_USERNAME=%s", target.Username), fmt.Sprintf("CF_PASSWORD=%s", target.Password)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to authenticate: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	if target.Org == "" && target.Space == "" {
+		return nil
+	}
+
+	args = []string{"target"}
+	if target.Org != "" {
+		args = append(args, "-o", target.Org)
+	}
+	if target.Space != "" {
+		args = append(args, "-s", target.Space)
+	}
+
+	err = l.cli.Execute(pexec.Execution{
+		Args:   args,
+		Stdout: logs,
+		Stderr: logs,
+		Env:    env,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to target org and space: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	return nil
+}
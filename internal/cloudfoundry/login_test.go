@@ -0,0 +1,155 @@
+package cloudfoundry_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+func testLogin(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Run", func() {
+		var (
+			login cloudfoundry.Login
+
+			home       string
+			target     cloudfoundry.Target
+			executable *fakes.Executable
+			executions []pexec.Execution
+		)
+
+		it.Before(func() {
+			var err error
+			home, err = os.MkdirTemp("", "home")
+			Expect(err).NotTo(HaveOccurred())
+			home = filepath.Join(home, "cf-home")
+
+			target = cloudfoundry.Target{
+				API:      "https://api.example.com",
+				Username: "some-username",
+				Password: "some-password",
+				Org:      "some-org",
+				Space:    "some-space",
+			}
+
+			executions = nil
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				executions = append(executions, execution)
+				return nil
+			}
+
+			login = cloudfoundry.NewLogin(executable)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(filepath.Dir(home))).To(Succeed())
+		})
+
+		it("targets and authenticates against the api in the given home", func() {
+			err := login.Run(home, target)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(home).To(BeADirectory())
+
+			Expect(executions).To(HaveLen(3))
+			Expect(executions[0]).To(MatchFields(IgnoreExtras, Fields{
+				"Args": Equal([]string{"api", "https://api.example.com"}),
+				"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", home)),
+			}))
+			Expect(executions[1]).To(MatchFields(IgnoreExtras, Fields{
+				"Args": Equal([]string{"auth"}),
+				"Env": SatisfyAll(
+					ContainElement(fmt.Sprintf("CF_HOME=%s", home)),
+					ContainElement("CF_USERNAME=some-username"),
+					ContainElement("CF_PASSWORD=some-password"),
+				),
+			}))
+			Expect(executions[2]).To(MatchFields(IgnoreExtras, Fields{
+				"Args": Equal([]string{"target", "-o", "some-org", "-s", "some-space"}),
+				"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", home)),
+			}))
+		})
+
+		context("when ssl validation is skipped", func() {
+			it.Before(func() {
+				target.SkipSSLValidation = true
+			})
+
+			it("passes the flag to the api command", func() {
+				err := login.Run(home, target)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions[0].Args).To(Equal([]string{"api", "https://api.example.com", "--skip-ssl-validation"}))
+			})
+		})
+
+		context("when no org or space is given", func() {
+			it.Before(func() {
+				target.Org = ""
+				target.Space = ""
+			})
+
+			it("does not target", func() {
+				err := login.Run(home, target)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions).To(HaveLen(2))
+				Expect(executions[1].Args).To(Equal([]string{"auth"}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the home cannot be created", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(filepath.Dir(home), "some-file"), nil, 0600)).To(Succeed())
+					home = filepath.Join(filepath.Dir(home), "some-file", "cf-home")
+				})
+
+				it("returns an error", func() {
+					err := login.Run(home, target)
+					Expect(err).To(MatchError(ContainSubstring("failed to make $CF_HOME:")))
+				})
+			})
+
+			for _, failure := range []struct{ command, message string }{
+				{"api", "failed to set api: exit status 1"},
+				{"auth", "failed to authenticate: exit status 1"},
+				{"target", "failed to target org and space: exit status 1"},
+			} {
+				failure := failure
+
+				context(fmt.Sprintf("when %s fails", failure.command), func() {
+					it.Before(func() {
+						executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+							if strings.HasPrefix(strings.Join(execution.Args, " "), failure.command) {
+								fmt.Fprintln(execution.Stdout, "Not logged in.")
+								return errors.New("exit status 1")
+							}
+
+							return nil
+						}
+					})
+
+					it("returns an error and the output", func() {
+						err := login.Run(home, target)
+						Expect(err).To(MatchError(ContainSubstring(failure.message)))
+						Expect(err).To(MatchError(ContainSubstring("Not logged in.")))
+					})
+				})
+			}
+		})
+	})
+}
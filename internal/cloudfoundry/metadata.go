@@ -0,0 +1,59 @@
+package cloudfoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type Metadata struct {
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels"`
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations"`
+}
+
+type MetadataReader struct {
+	cli Executable
+}
+
+func NewMetadataReader(cli Executable) MetadataReader {
+	return MetadataReader{
+		cli: cli,
+	}
+}
+
+func (r MetadataReader) Read(ctx context.Context, home, name string) (Metadata, error) {
+	r.cli = withContext(ctx, r.cli)
+
+	env := append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home))
+
+	guid, err := appGUID(r.cli, env, name)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	err = r.cli.Execute(pexec.Execution{
+		Args:   []string{"curl", path.Join("/v3", "apps", guid)},
+		Stdout: buffer,
+		Stderr: buffer,
+		Env:    env,
+	})
+	if err != nil {
+		return Metadata{}, fmt.Errorf("failed to fetch app: %w\n\nOutput:\n%s", err, buffer)
+	}
+
+	var app struct {
+		Metadata Metadata `json:"metadata"`
+	}
+	err = json.NewDecoder(buffer).Decode(&app)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("failed to parse app metadata: %w\n\nOutput:\n%s", err, buffer)
+	}
+
+	return app.Metadata, nil
+}
@@ -0,0 +1,126 @@
+package cloudfoundry_test
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+func testMetadataReader(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Read", func() {
+		var (
+			reader cloudfoundry.MetadataReader
+
+			executable *fakes.Executable
+			executions []pexec.Execution
+		)
+
+		it.Before(func() {
+			executions = nil
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				executions = append(executions, execution)
+
+				switch strings.Join(execution.Args, " ") {
+				case "app some-app --guid":
+					fmt.Fprintln(execution.Stdout, "some-app-guid")
+				case "curl /v3/apps/some-app-guid":
+					fmt.Fprintln(execution.Stdout, `{
+						"guid": "some-app-guid",
+						"metadata": {
+							"labels": {"team": "some-team"},
+							"annotations": {"contact": "some-team@example.com"}
+						}
+					}`)
+				}
+
+				return nil
+			}
+
+			reader = cloudfoundry.NewMetadataReader(executable)
+		})
+
+		it("returns the labels and annotations on the app", func() {
+			metadata, err := reader.Read(gocontext.Background(), "/some/home", "some-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata).To(Equal(cloudfoundry.Metadata{
+				Labels:      map[string]string{"team": "some-team"},
+				Annotations: map[string]string{"contact": "some-team@example.com"},
+			}))
+
+			Expect(executions).To(HaveLen(2))
+			Expect(executions[1]).To(MatchFields(IgnoreExtras, Fields{
+				"Args": Equal([]string{"curl", "/v3/apps/some-app-guid"}),
+				"Env":  ContainElement("CF_HOME=/some/home"),
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the guid cannot be fetched", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						fmt.Fprintln(execution.Stdout, "App 'some-app' not found.")
+						return errors.New("exit status 1")
+					}
+				})
+
+				it("returns an error and the output", func() {
+					_, err := reader.Read(gocontext.Background(), "/some/home", "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to fetch guid: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("App 'some-app' not found.")))
+				})
+			})
+
+			context("when the app cannot be fetched", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						if execution.Args[0] == "curl" {
+							fmt.Fprintln(execution.Stdout, "Unauthorized")
+							return errors.New("exit status 1")
+						}
+
+						fmt.Fprintln(execution.Stdout, "some-app-guid")
+						return nil
+					}
+				})
+
+				it("returns an error and the output", func() {
+					_, err := reader.Read(gocontext.Background(), "/some/home", "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to fetch app: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("Unauthorized")))
+				})
+			})
+
+			context("when the app cannot be parsed", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						if execution.Args[0] == "curl" {
+							fmt.Fprintln(execution.Stdout, "%%%")
+							return nil
+						}
+
+						fmt.Fprintln(execution.Stdout, "some-app-guid")
+						return nil
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := reader.Read(gocontext.Background(), "/some/home", "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to parse app metadata:")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,146 @@
+package cloudfoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+const MinimumQuotaMemory = 1024
+
+type Problem struct {
+	Check   string
+	Message string
+	Remedy  string
+}
+
+type Preflight struct {
+	cli Executable
+}
+
+func NewPreflight(cli Executable) Preflight {
+	return Preflight{
+		cli: cli,
+	}
+}
+
+func (p Preflight) Run(ctx context.Context) ([]Problem, error) {
+	p.cli = withContext(ctx, p.cli)
+
+	buffer := bytes.NewBuffer(nil)
+	err := p.cli.Execute(pexec.Execution{
+		Args:   []string{"oauth-token"},
+		Stdout: buffer,
+		Stderr: buffer,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Nothing else can be checked without a session.
+		return []Problem{{
+			Check:   "auth",
+			Message: fmt.Sprintf("could not get a token for the targeted Cloud Foundry: %s\n\n%s", err, strings.TrimSpace(buffer.String())),
+			Remedy:  "Run `cf api` and `cf login`, or pass credentials with WithCloudFoundryTarget.",
+		}}, nil
+	}
+
+	var problems []Problem
+	if !hasScope(buffer.String(), "cloud_controller.admin") {
+		problems = append(problems, Problem{
+			Check:   "auth",
+			Message: "the logged in user is not a Cloud Foundry admin, but deployments create orgs, security groups, and buildpacks",
+			Remedy:  "Log in as a user with the cloud_controller.admin scope.",
+		})
+	}
+
+	buffer = bytes.NewBuffer(nil)
+	err = p.cli.Execute(pexec.Execution{
+		Args:   []string{"curl", "/v3/organization_quotas?names=default"},
+		Stdout: buffer,
+		Stderr: buffer,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return append(problems, Problem{
+			Check:   "target",
+			Message: fmt.Sprintf("could not reach the Cloud Foundry API: %s\n\n%s", err, strings.TrimSpace(buffer.String())),
+			Remedy:  "Check that the API targeted by `cf api` is up and reachable from this machine.",
+		}), nil
+	}
+
+	var quotas struct {
+		Resources []struct {
+			Apps struct {
+				TotalMemoryInMB *int `json:"total_memory_in_mb"`
+			} `json:"apps"`
+		} `json:"resources"`
+	}
+	err = json.Unmarshal(buffer.Bytes(), &quotas)
+	if err != nil {
+		return append(problems, Problem{
+			Check:   "target",
+			Message: fmt.Sprintf("could not parse the organization quotas returned by the Cloud Foundry API: %s", err),
+			Remedy:  "Check that the API targeted by `cf api` is a Cloud Foundry v3 API.",
+		}), nil
+	}
+
+	switch {
+	case len(quotas.Resources) == 0:
+		problems = append(problems, Problem{
+			Check:   "quota",
+			Message: "the default organization quota does not exist, but every deployment creates an org that uses it",
+			Remedy:  "Create it with `cf create-org-quota default`.",
+		})
+	case quotas.Resources[0].Apps.TotalMemoryInMB != nil && *quotas.Resources[0].Apps.TotalMemoryInMB < MinimumQuotaMemory:
+		problems = append(problems, Problem{
+			Check:   "quota",
+			Message: fmt.Sprintf("the default organization quota allows %d MB of memory, but at least %d MB is needed to stage and run an app", *quotas.Resources[0].Apps.TotalMemoryInMB, MinimumQuotaMemory),
+			Remedy:  fmt.Sprintf("Raise it with `cf update-org-quota default -m %dM`.", MinimumQuotaMemory),
+		})
+	}
+
+	return problems, nil
+}
+
+func hasScope(token, scope string) bool {
+	fields := strings.Fields(token)
+	if len(fields) == 0 {
+		return false
+	}
+
+	parts := strings.Split(fields[len(fields)-1], ".")
+	if len(parts) != 3 {
+		return false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+
+	var claims struct {
+		Scope []string `json:"scope"`
+	}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return false
+	}
+
+	for _, s := range claims.Scope {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,186 @@
+package cloudfoundry_test
+
+import (
+	gocontext "context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+func testPreflight(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	token := func(scopes ...string) string {
+		payload := fmt.Sprintf(`{"scope":["%s"]}`, strings.Join(scopes, `","`))
+		return fmt.Sprintf("bearer some-header.%s.some-signature", base64.RawURLEncoding.EncodeToString([]byte(payload)))
+	}
+
+	context("Run", func() {
+		var (
+			preflight cloudfoundry.Preflight
+
+			executable *fakes.Executable
+			executions []pexec.Execution
+			scopes     []string
+			quotas     string
+		)
+
+		it.Before(func() {
+			scopes = []string{"openid", "cloud_controller.admin"}
+			quotas = `{"resources": [{"name": "default", "apps": {"total_memory_in_mb": null}}]}`
+
+			executions = nil
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				executions = append(executions, execution)
+
+				switch strings.Join(execution.Args, " ") {
+				case "oauth-token":
+					fmt.Fprintln(execution.Stdout, token(scopes...))
+				case "curl /v3/organization_quotas?names=default":
+					fmt.Fprintln(execution.Stdout, quotas)
+				}
+
+				return nil
+			}
+
+			preflight = cloudfoundry.NewPreflight(executable)
+		})
+
+		it("finds no problems with a healthy foundation", func() {
+			problems, err := preflight.Run(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+
+			Expect(executions).To(HaveLen(2))
+		})
+
+		context("when the user is not an admin", func() {
+			it.Before(func() {
+				scopes = []string{"openid", "cloud_controller.read"}
+			})
+
+			it("reports a problem", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Check":   Equal("auth"),
+					"Message": ContainSubstring("is not a Cloud Foundry admin"),
+				})))
+			})
+		})
+
+		context("when the default quota does not exist", func() {
+			it.Before(func() {
+				quotas = `{"resources": []}`
+			})
+
+			it("reports a problem", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Check":  Equal("quota"),
+					"Remedy": Equal("Create it with `cf create-org-quota default`."),
+				})))
+			})
+		})
+
+		context("when the default quota has too little memory", func() {
+			it.Before(func() {
+				quotas = `{"resources": [{"name": "default", "apps": {"total_memory_in_mb": 512}}]}`
+			})
+
+			it("reports a problem", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Check":   Equal("quota"),
+					"Message": Equal("the default organization quota allows 512 MB of memory, but at least 1024 MB is needed to stage and run an app"),
+				})))
+			})
+		})
+
+		context("when the user is not logged in", func() {
+			it.Before(func() {
+				executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+					executions = append(executions, execution)
+					fmt.Fprintln(execution.Stdout, "Not logged in. Use 'cf login' or 'cf login --sso' to log in.")
+					return errors.New("exit status 1")
+				}
+			})
+
+			it("reports a problem without checking anything else", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Check":   Equal("auth"),
+					"Message": ContainSubstring("Not logged in."),
+				})))
+
+				Expect(executions).To(HaveLen(1))
+			})
+		})
+
+		context("when the api cannot be reached", func() {
+			it.Before(func() {
+				executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+					if execution.Args[0] == "curl" {
+						fmt.Fprintln(execution.Stdout, "dial tcp: lookup api.example.com: no such host")
+						return errors.New("exit status 1")
+					}
+
+					fmt.Fprintln(execution.Stdout, token(scopes...))
+					return nil
+				}
+			})
+
+			it("reports a problem", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Check":   Equal("target"),
+					"Message": ContainSubstring("no such host"),
+				})))
+			})
+		})
+
+		context("when the quotas cannot be parsed", func() {
+			it.Before(func() {
+				quotas = "%%%"
+			})
+
+			it("reports a problem", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Check":   Equal("target"),
+					"Message": ContainSubstring("could not parse the organization quotas"),
+				})))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the context is done", func() {
+				it("returns the context error", func() {
+					ctx, cancel := gocontext.WithCancel(gocontext.Background())
+					cancel()
+
+					_, err := preflight.Run(ctx)
+					Expect(err).To(MatchError(gocontext.Canceled))
+
+					Expect(executions).To(BeEmpty())
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,35 @@
+package cloudfoundry
+
+import "time"
+
+type Resource struct {
+	Kind string
+	Name string
+	ID   string
+	Age  time.Duration
+}
+
+type TeardownFailure struct {
+	Resource Resource
+	Error    string
+}
+
+type TeardownReport struct {
+	Removed []Resource
+	Missing []Resource
+	Failed  []TeardownFailure
+}
+
+func (r *TeardownReport) record(resource Resource, removed bool, err error) error {
+	switch {
+	case err != nil:
+		r.Failed = append(r.Failed, TeardownFailure{Resource: resource, Error: err.Error()})
+		return err
+	case removed:
+		r.Removed = append(r.Removed, resource)
+	default:
+		r.Missing = append(r.Missing, resource)
+	}
+
+	return nil
+}
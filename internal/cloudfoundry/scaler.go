@@ -0,0 +1,35 @@
+package cloudfoundry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type Scaler struct {
+	cli Executable
+}
+
+func NewScaler(cli Executable) Scaler {
+	return Scaler{
+		cli: cli,
+	}
+}
+
+func (s Scaler) Scale(home, name string, instances int) error {
+	buffer := bytes.NewBuffer(nil)
+	err := s.cli.Execute(pexec.Execution{
+		Args:   []string{"scale", name, "-i", strconv.Itoa(instances)},
+		Stdout: buffer,
+		Stderr: buffer,
+		Env:    append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scale: %w\n\nOutput:\n%s", err, buffer)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package cloudfoundry_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+func testScaler(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Scale", func() {
+		var (
+			scaler cloudfoundry.Scaler
+
+			executable *fakes.Executable
+		)
+
+		it.Before(func() {
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				fmt.Fprintln(execution.Stdout, "Scaling app...")
+				return nil
+			}
+
+			scaler = cloudfoundry.NewScaler(executable)
+		})
+
+		it("scales the app to the given number of instances", func() {
+			err := scaler.Scale("/some/home", "some-app", 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(executable.ExecuteCall.CallCount).To(Equal(1))
+			Expect(executable.ExecuteCall.Receives.Execution).To(MatchFields(IgnoreExtras, Fields{
+				"Args": Equal([]string{"scale", "some-app", "-i", "3"}),
+				"Env":  ContainElement("CF_HOME=/some/home"),
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the app cannot be scaled", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						fmt.Fprintln(execution.Stdout, "Instance count exceeds quota")
+						return errors.New("exit status 1")
+					}
+				})
+
+				it("returns an error and the output", func() {
+					err := scaler.Scale("/some/home", "some-app", 3)
+					Expect(err).To(MatchError(ContainSubstring("failed to scale: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("Instance count exceeds quota")))
+				})
+			})
+		})
+	})
+}
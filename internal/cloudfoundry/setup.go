@@ -2,28 +2,66 @@ package cloudfoundry
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/paketo-buildpacks/packit/v2/fs"
 	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"gopkg.in/yaml.v3"
 )
 
 type SetupPhase interface {
-	Run(logs io.Writer, home, name, source string) (url string, err error)
+	Run(ctx context.Context, logs io.Writer, home, name, source string) (url string, err error)
 
 	WithBuildpacks(buildpacks ...string) SetupPhase
 	WithStack(stack string) SetupPhase
 	WithEnv(env map[string]string) SetupPhase
 	WithoutInternetAccess() SetupPhase
 	WithServices(services map[string]map[string]interface{}) SetupPhase
+	WithCredentials(credentials map[string]interface{}) SetupPhase
+	WithSidecars(sidecars []Sidecar) SetupPhase
+	WithProfileScripts(scripts map[string]string) SetupPhase
+	WithMetadata(metadata Metadata) SetupPhase
+	WithAppFeatures(features map[string]bool) SetupPhase
+	WithManifest(manifest Manifest) SetupPhase
+	WithSource(source io.Reader) SetupPhase
+	WithHealthCheck(healthCheck HealthCheck) SetupPhase
+}
+
+type Sidecar struct {
+	Name         string   `yaml:"name"`
+	Command      string   `yaml:"command"`
+	Memory       string   `yaml:"memory,omitempty"`
+	ProcessTypes []string `yaml:"process_types"`
+}
+
+// Manifest is an app manifest supplied by the test author, along with the
+// values used to interpolate its ((variables)) at push time.
+type Manifest struct {
+	Path      string
+	Vars      map[string]string
+	VarsFiles []string
+}
+
+// HealthCheck is passed to `cf push` as the app's health check type,
+// endpoint, and start timeout.
+type HealthCheck struct {
+	Type     string
+	Endpoint string
+	Timeout  time.Duration
 }
 
 type Setup struct {
@@ -35,6 +73,14 @@ type Setup struct {
 	stack          string
 	env            map[string]string
 	services       map[string]map[string]interface{}
+	credentials    map[string]interface{}
+	sidecars       []Sidecar
+	profileScripts map[string]string
+	metadata       Metadata
+	features       map[string]bool
+	manifest       Manifest
+	source         io.Reader
+	healthCheck    *HealthCheck
 	lookupHost     func(string) ([]string, error)
 }
 
@@ -73,12 +119,54 @@ func (s Setup) WithServices(services map[string]map[string]interface{}) SetupPha
 	return s
 }
 
+func (s Setup) WithCredentials(credentials map[string]interface{}) SetupPhase {
+	s.credentials = credentials
+	return s
+}
+
+func (s Setup) WithSidecars(sidecars []Sidecar) SetupPhase {
+	s.sidecars = sidecars
+	return s
+}
+
+func (s Setup) WithProfileScripts(scripts map[string]string) SetupPhase {
+	s.profileScripts = scripts
+	return s
+}
+
+func (s Setup) WithMetadata(metadata Metadata) SetupPhase {
+	s.metadata = metadata
+	return s
+}
+
+func (s Setup) WithAppFeatures(features map[string]bool) SetupPhase {
+	s.features = features
+	return s
+}
+
+func (s Setup) WithManifest(manifest Manifest) SetupPhase {
+	s.manifest = manifest
+	return s
+}
+
+func (s Setup) WithSource(source io.Reader) SetupPhase {
+	s.source = source
+	return s
+}
+
+func (s Setup) WithHealthCheck(healthCheck HealthCheck) SetupPhase {
+	s.healthCheck = &healthCheck
+	return s
+}
+
 func (s Setup) WithCustomHostLookup(lookupHost func(string) ([]string, error)) Setup {
 	s.lookupHost = lookupHost
 	return s
 }
 
-func (s Setup) Run(log io.Writer, home, name, source string) (string, error) {
+func (s Setup) Run(ctx context.Context, log io.Writer, home, name, source string) (string, error) {
+	s.cli = withContext(ctx, s.cli)
+
 	err := os.MkdirAll(home, os.ModePerm)
 	if err != nil {
 		return "", fmt.Errorf("failed to make temporary $CF_HOME: %w", err)
@@ -317,11 +405,90 @@ func (s Setup) Run(log io.Writer, home, name, source string) (string, error) {
 		}
 	}
 
+	buildpacks, err := s.resolveBuildpacks(log, env)
+	if err != nil {
+		return "", err
+	}
+
+	if s.source != nil {
+		source, err = decompressSource(home, s.source)
+	} else {
+		source, err = extractSource(home, source)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(s.profileScripts) > 0 {
+		source, err = injectProfileScripts(home, source, s.profileScripts)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	args := []string{"push", name, "-p", source, "--no-start", "-s", s.stack}
-	for _, buildpack := range s.buildpacks {
+	for _, buildpack := range buildpacks {
 		args = append(args, "-b", buildpack)
 	}
 
+	if s.healthCheck != nil {
+		args = append(args, "-u", s.healthCheck.Type)
+		if s.healthCheck.Type == "http" && s.healthCheck.Endpoint != "" {
+			args = append(args, "--endpoint", s.healthCheck.Endpoint)
+		}
+
+		if s.healthCheck.Timeout > 0 {
+			args = append(args, "-t", strconv.Itoa(int(math.Ceil(s.healthCheck.Timeout.Seconds()))))
+		}
+	}
+
+	var metadata *Metadata
+	if len(s.metadata.Labels) > 0 || len(s.metadata.Annotations) > 0 {
+		metadata = &s.metadata
+	}
+
+	if s.manifest.Path != "" {
+		if len(s.sidecars) > 0 || metadata != nil {
+			return "", fmt.Errorf("failed to push: sidecars and metadata cannot be combined with the manifest at %s", s.manifest.Path)
+		}
+
+		args = append(args, "-f", s.manifest.Path)
+	} else if len(s.sidecars) > 0 || metadata != nil {
+		type application struct {
+			Name     string    `yaml:"name"`
+			Sidecars []Sidecar `yaml:"sidecars,omitempty"`
+			Metadata *Metadata `yaml:"metadata,omitempty"`
+		}
+
+		content, err := yaml.Marshal(map[string][]application{
+			"applications": {{Name: name, Sidecars: s.sidecars, Metadata: metadata}},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal manifest: %w", err)
+		}
+
+		err = os.WriteFile(filepath.Join(home, "manifest.yml"), content, 0600)
+		if err != nil {
+			return "", fmt.Errorf("failed to write manifest: %w", err)
+		}
+
+		args = append(args, "-f", filepath.Join(home, "manifest.yml"))
+	}
+
+	var keys []string
+	for key := range s.manifest.Vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		args = append(args, "--var", fmt.Sprintf("%s=%s", key, s.manifest.Vars[key]))
+	}
+
+	for _, file := range s.manifest.VarsFiles {
+		args = append(args, "--vars-file", file)
+	}
+
 	err = s.cli.Execute(pexec.Execution{
 		Args:   args,
 		Stdout: log,
@@ -464,6 +631,41 @@ func (s Setup) Run(log io.Writer, home, name, source string) (string, error) {
 		}
 	}
 
+	if len(s.credentials) > 0 {
+		content, err := json.Marshal(s.credentials)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal credentials json: %w", err)
+		}
+
+		service := fmt.Sprintf("%s-credentials", name)
+		err = s.cli.Execute(pexec.Execution{
+			Args:   []string{"create-service", "credhub", "default", service, "-c", string(content)},
+			Stdout: log,
+			Stderr: log,
+			Env:    env,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create-service: %w\n\nOutput:\n%s", err, log)
+		}
+
+		err = s.cli.Execute(pexec.Execution{
+			Args:   []string{"bind-service", name, service},
+			Stdout: log,
+			Stderr: log,
+			Env:    env,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to bind-service: %w\n\nOutput:\n%s", err, log)
+		}
+	}
+
+	if len(s.features) > 0 {
+		err = updateAppFeatures(s.cli, log, env, name, s.features)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	return fmt.Sprintf("http://tcp.%s:%d", domain, port), nil
 }
 
@@ -515,3 +717,156 @@ var (
 		},
 	}
 )
+
+func (s Setup) resolveBuildpacks(log io.Writer, env []string) ([]string, error) {
+	var buildpacks []string
+	var installed []apiResource
+	for _, buildpack := range s.buildpacks {
+		if strings.ContainsAny(buildpack, "/@") {
+			buildpacks = append(buildpacks, buildpackReference(buildpack))
+			continue
+		}
+
+		if installed == nil {
+			var err error
+			installed, err = listResources(s.cli, log, env, "/v3/buildpacks?per_page=5000")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var match string
+		var available []string
+		for _, resource := range installed {
+			if normalizeBuildpackName(resource.Name) == normalizeBuildpackName(buildpack) {
+				match = resource.Name
+			}
+			available = append(available, resource.Name)
+		}
+
+		if match == "" {
+			return nil, fmt.Errorf("failed to find buildpack %q, available buildpacks: %s", buildpack, strings.Join(available, ", "))
+		}
+
+		buildpacks = append(buildpacks, match)
+	}
+
+	return buildpacks, nil
+}
+
+func normalizeBuildpackName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+}
+
+func buildpackReference(buildpack string) string {
+	name, version, ok := strings.Cut(buildpack, "@")
+	if !ok || strings.Contains(name, "/") {
+		return buildpack
+	}
+
+	if !strings.HasPrefix(version, "v") {
+		version = fmt.Sprintf("v%s", version)
+	}
+
+	return fmt.Sprintf("https://github.com/cloudfoundry/%s#%s", strings.ReplaceAll(name, "_", "-"), version)
+}
+
+func extractSource(home, source string) (string, error) {
+	info, err := os.Stat(source)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.IsDir()) {
+		return source, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat source: %w", err)
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to open source: %w", err)
+	}
+	defer file.Close()
+
+	magic := make([]byte, 262)
+	n, err := io.ReadFull(file, magic)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read source: %w", err)
+	}
+	magic = magic[:n]
+
+	if !bytes.HasPrefix(magic, []byte{0x1f, 0x8b}) && (len(magic) < 262 || string(magic[257:262]) != "ustar") {
+		return source, nil
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", fmt.Errorf("failed to read source: %w", err)
+	}
+
+	return decompressSource(home, file)
+}
+
+func decompressSource(home string, source io.Reader) (string, error) {
+	destination := filepath.Join(home, "source")
+	err := os.RemoveAll(destination)
+	if err != nil {
+		return "", fmt.Errorf("failed to clear extracted source: %w", err)
+	}
+
+	err = vacation.NewArchive(source).Decompress(destination)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract source: %w", err)
+	}
+
+	return destination, nil
+}
+
+func injectProfileScripts(home, source string, scripts map[string]string) (string, error) {
+	destination := filepath.Join(home, "source")
+	if source != destination {
+		err := os.RemoveAll(destination)
+		if err != nil {
+			return "", fmt.Errorf("failed to clear extracted source: %w", err)
+		}
+
+		info, err := os.Stat(source)
+		if err != nil {
+			return "", fmt.Errorf("failed to stat source: %w", err)
+		}
+
+		if info.IsDir() {
+			err = fs.Copy(source, destination)
+			if err != nil {
+				return "", fmt.Errorf("failed to copy source: %w", err)
+			}
+		} else {
+			file, err := os.Open(source)
+			if err != nil {
+				return "", fmt.Errorf("failed to open source: %w", err)
+			}
+			defer file.Close()
+
+			err = vacation.NewArchive(file).Decompress(destination)
+			if err != nil {
+				return "", fmt.Errorf("failed to extract source: %w", err)
+			}
+		}
+	}
+
+	err := os.MkdirAll(filepath.Join(destination, ".profile.d"), os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to create profile.d directory: %w", err)
+	}
+
+	for name, contents := range scripts {
+		if name == "" || name != filepath.Base(name) {
+			return "", fmt.Errorf("failed to add profile script %q: name must not contain a path", name)
+		}
+
+		err = os.WriteFile(filepath.Join(destination, ".profile.d", name), []byte(contents), 0755)
+		if err != nil {
+			return "", fmt.Errorf("failed to write profile script: %w", err)
+		}
+	}
+
+	return destination, nil
+}
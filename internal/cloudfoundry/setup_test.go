@@ -1,13 +1,18 @@
 package cloudfoundry_test
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	gocontext "context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
 	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
@@ -63,6 +68,12 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 						{ "protocol": "http", "port": null },
 						{ "protocol": "tcp", "port": 5555 }
 					] }`)
+				case strings.HasPrefix(command, "curl /v3/buildpacks"):
+					fmt.Fprintln(execution.Stdout, `{ "resources": [
+						{ "name": "some-buildpack" },
+						{ "name": "other-buildpack" },
+						{ "name": "nodejs_buildpack" }
+					] }`)
 				case strings.HasPrefix(command, "curl /v2/security_groups"):
 					fmt.Fprintln(execution.Stdout, `{ "resources": [
 						{ "entity": { "name": "some-default-network" } },
@@ -83,6 +94,8 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 					fmt.Fprintln(execution.Stdout, "Updating security group...")
 				case strings.HasPrefix(command, "push"):
 					fmt.Fprintln(execution.Stdout, "Pushing app...")
+				case command == "app some-app --guid":
+					fmt.Fprintln(execution.Stdout, "some-app-guid")
 				case strings.HasPrefix(command, "set-env"):
 					fmt.Fprintln(execution.Stdout, "Setting environment variable...")
 				case strings.HasPrefix(command, "create-user-provided-service"):
@@ -136,7 +149,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 		it("sets up the app", func() {
 			logs := bytes.NewBuffer(nil)
 
-			url, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+			url, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(url).To(Equal("http://tcp.example.com:5555"))
 
@@ -268,11 +281,15 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 			it("pushes the app with those buildpacks", func() {
 				_, err := setup.
 					WithBuildpacks("some-buildpack", "other-buildpack").
-					Run(bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(executions).To(HaveLen(16))
+				Expect(executions).To(HaveLen(17))
 				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"curl", "/v3/buildpacks?per_page=5000"}),
+					"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
+				}))
+				Expect(executions[12]).To(MatchFields(IgnoreExtras, Fields{
 					"Args": Equal([]string{
 						"push", "some-app",
 						"-p", "/some/path/to/my/app",
@@ -284,13 +301,54 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 					"Env": ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
 				}))
 			})
+
+			context("when a buildpack name differs from the installed system buildpack", func() {
+				it("pushes the app with the installed name", func() {
+					_, err := setup.
+						WithBuildpacks("nodejs-buildpack").
+						Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(executions[12].Args).To(ContainElements("-b", "nodejs_buildpack"))
+				})
+			})
+
+			context("when a buildpack is not installed", func() {
+				it("returns an error", func() {
+					_, err := setup.
+						WithBuildpacks("go_buildpack").
+						Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(`failed to find buildpack "go_buildpack", available buildpacks: some-buildpack, other-buildpack, nodejs_buildpack`))
+				})
+			})
+		})
+
+		context("when a buildpack is pinned to a version", func() {
+			it("pushes the app with the tagged buildpack repository", func() {
+				_, err := setup.
+					WithBuildpacks("nodejs_buildpack@v4.500.0", "go_buildpack@1.10.0", "https://example.com/some-buildpack.zip").
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{
+						"push", "some-app",
+						"-p", "/some/path/to/my/app",
+						"--no-start",
+						"-s", "default-stack",
+						"-b", "https://github.com/cloudfoundry/nodejs-buildpack#v4.500.0",
+						"-b", "https://github.com/cloudfoundry/go-buildpack#v1.10.0",
+						"-b", "https://example.com/some-buildpack.zip",
+					}),
+				}))
+			})
 		})
 
 		context("when the app has a specific stack", func() {
 			it("pushes the app with that stack", func() {
 				_, err := setup.
 					WithStack("some-stack").
-					Run(bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(executions).To(HaveLen(16))
@@ -301,6 +359,321 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the app has a health check", func() {
+			it("pushes the app with that health check", func() {
+				_, err := setup.
+					WithHealthCheck(cloudfoundry.HealthCheck{Type: "http", Endpoint: "/health", Timeout: 90500 * time.Millisecond}).
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions).To(HaveLen(16))
+				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{
+						"push", "some-app",
+						"-p", "/some/path/to/my/app",
+						"--no-start",
+						"-s", "default-stack",
+						"-u", "http",
+						"--endpoint", "/health",
+						"-t", "91",
+					}),
+				}))
+			})
+		})
+
+		context("when the app has sidecars", func() {
+			it("pushes the app with a manifest declaring those sidecars", func() {
+				_, err := setup.
+					WithSidecars([]cloudfoundry.Sidecar{
+						{
+							Name:         "some-sidecar",
+							Command:      "some-command",
+							Memory:       "64M",
+							ProcessTypes: []string{"web", "worker"},
+						},
+						{
+							Name:         "other-sidecar",
+							Command:      "other-command",
+							ProcessTypes: []string{"web"},
+						},
+					}).
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions).To(HaveLen(16))
+				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{
+						"push", "some-app",
+						"-p", "/some/path/to/my/app",
+						"--no-start",
+						"-s", "default-stack",
+						"-f", filepath.Join(workspace, "some-home", "manifest.yml"),
+					}),
+				}))
+
+				content, err := os.ReadFile(filepath.Join(workspace, "some-home", "manifest.yml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(MatchYAML(`---
+applications:
+- name: some-app
+  sidecars:
+  - name: some-sidecar
+    command: some-command
+    memory: 64M
+    process_types: [web, worker]
+  - name: other-sidecar
+    command: other-command
+    process_types: [web]
+`))
+			})
+		})
+
+		context("when the app has metadata", func() {
+			it("pushes the app with a manifest declaring those labels and annotations", func() {
+				_, err := setup.
+					WithMetadata(cloudfoundry.Metadata{
+						Labels:      map[string]string{"team": "some-team"},
+						Annotations: map[string]string{"contact": "some-team@example.com"},
+					}).
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{
+						"push", "some-app",
+						"-p", "/some/path/to/my/app",
+						"--no-start",
+						"-s", "default-stack",
+						"-f", filepath.Join(workspace, "some-home", "manifest.yml"),
+					}),
+				}))
+
+				content, err := os.ReadFile(filepath.Join(workspace, "some-home", "manifest.yml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(MatchYAML(`---
+applications:
+- name: some-app
+  metadata:
+    labels:
+      team: some-team
+    annotations:
+      contact: some-team@example.com
+`))
+			})
+		})
+
+		context("when the app has a manifest", func() {
+			it("pushes the app with that manifest and its variables", func() {
+				_, err := setup.
+					WithManifest(cloudfoundry.Manifest{
+						Path: "/some/path/to/manifest.yml",
+						Vars: map[string]string{
+							"memory":    "256M",
+							"instances": "2",
+						},
+						VarsFiles: []string{"/some/path/to/vars.yml", "/other/path/to/vars.yml"},
+					}).
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{
+						"push", "some-app",
+						"-p", "/some/path/to/my/app",
+						"--no-start",
+						"-s", "default-stack",
+						"-f", "/some/path/to/manifest.yml",
+						"--var", "instances=2",
+						"--var", "memory=256M",
+						"--vars-file", "/some/path/to/vars.yml",
+						"--vars-file", "/other/path/to/vars.yml",
+					}),
+				}))
+
+				Expect(filepath.Join(workspace, "some-home", "manifest.yml")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		context("when the source is a tarball", func() {
+			var source string
+
+			it.Before(func() {
+				source = filepath.Join(workspace, "source.tgz")
+				file, err := os.Create(source)
+				Expect(err).NotTo(HaveOccurred())
+				defer file.Close()
+
+				gw := gzip.NewWriter(file)
+				defer gw.Close()
+
+				tw := tar.NewWriter(gw)
+				defer tw.Close()
+
+				Expect(tw.WriteHeader(&tar.Header{Name: "some-file", Mode: 0644, Size: 12, Typeflag: tar.TypeReg})).To(Succeed())
+				_, err = tw.Write([]byte("some-content"))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("extracts the tarball and pushes the extracted directory", func() {
+				_, err := setup.Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", source)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"push", "some-app", "-p", filepath.Join(workspace, "some-home", "source"), "--no-start", "-s", "default-stack"}),
+				}))
+
+				content, err := os.ReadFile(filepath.Join(workspace, "some-home", "source", "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-content"))
+			})
+		})
+
+		context("when the source is streamed", func() {
+			it("extracts the stream and pushes the extracted directory", func() {
+				stream := bytes.NewBuffer(nil)
+				tw := tar.NewWriter(stream)
+				Expect(tw.WriteHeader(&tar.Header{Name: "some-file", Mode: 0644, Size: 12, Typeflag: tar.TypeReg})).To(Succeed())
+				_, err := tw.Write([]byte("some-content"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tw.Close()).To(Succeed())
+
+				_, err = setup.
+					WithSource(stream).
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"push", "some-app", "-p", filepath.Join(workspace, "some-home", "source"), "--no-start", "-s", "default-stack"}),
+				}))
+
+				content, err := os.ReadFile(filepath.Join(workspace, "some-home", "source", "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-content"))
+			})
+		})
+
+		context("when the source is a zip file", func() {
+			var source string
+
+			it.Before(func() {
+				source = filepath.Join(workspace, "source.jar")
+				file, err := os.Create(source)
+				Expect(err).NotTo(HaveOccurred())
+				defer file.Close()
+
+				zw := zip.NewWriter(file)
+				defer zw.Close()
+
+				w, err := zw.Create("some-file")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = w.Write([]byte("some-content"))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("pushes the zip file as is", func() {
+				_, err := setup.Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", source)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"push", "some-app", "-p", source, "--no-start", "-s", "default-stack"}),
+				}))
+			})
+		})
+
+		context("when the app has profile scripts", func() {
+			var source string
+
+			it.Before(func() {
+				source = filepath.Join(workspace, "some-app")
+				Expect(os.MkdirAll(source, os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(source, "some-file"), []byte("some-content"), 0600)).To(Succeed())
+			})
+
+			it("pushes a copy of the source with the scripts in its profile.d directory", func() {
+				_, err := setup.
+					WithProfileScripts(map[string]string{
+						"some-script.sh": "export SOME_VARIABLE=some-value",
+					}).
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", source)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"push", "some-app", "-p", filepath.Join(workspace, "some-home", "source"), "--no-start", "-s", "default-stack"}),
+				}))
+
+				content, err := os.ReadFile(filepath.Join(workspace, "some-home", "source", "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-content"))
+
+				info, err := os.Stat(filepath.Join(workspace, "some-home", "source", ".profile.d", "some-script.sh"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+
+				content, err = os.ReadFile(filepath.Join(workspace, "some-home", "source", ".profile.d", "some-script.sh"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("export SOME_VARIABLE=some-value"))
+
+				Expect(filepath.Join(source, ".profile.d")).NotTo(BeAnExistingFile())
+			})
+
+			context("when the source is a zip file", func() {
+				it.Before(func() {
+					source = filepath.Join(workspace, "source.jar")
+					file, err := os.Create(source)
+					Expect(err).NotTo(HaveOccurred())
+					defer file.Close()
+
+					zw := zip.NewWriter(file)
+					defer zw.Close()
+
+					w, err := zw.Create("some-file")
+					Expect(err).NotTo(HaveOccurred())
+					_, err = w.Write([]byte("some-content"))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("extracts the zip file and adds the scripts", func() {
+					_, err := setup.
+						WithProfileScripts(map[string]string{
+							"some-script.sh": "export SOME_VARIABLE=some-value",
+						}).
+						Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", source)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(executions[11]).To(MatchFields(IgnoreExtras, Fields{
+						"Args": Equal([]string{"push", "some-app", "-p", filepath.Join(workspace, "some-home", "source"), "--no-start", "-s", "default-stack"}),
+					}))
+
+					content, err := os.ReadFile(filepath.Join(workspace, "some-home", "source", "some-file"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("some-content"))
+
+					Expect(filepath.Join(workspace, "some-home", "source", ".profile.d", "some-script.sh")).To(BeAnExistingFile())
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the source does not exist", func() {
+					it("returns an error", func() {
+						_, err := setup.
+							WithProfileScripts(map[string]string{"some-script.sh": "true"}).
+							Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+						Expect(err).To(MatchError(ContainSubstring("failed to stat source:")))
+						Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+					})
+				})
+
+				context("when a script name contains a path", func() {
+					it("returns an error", func() {
+						_, err := setup.
+							WithProfileScripts(map[string]string{"../some-script.sh": "true"}).
+							Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", source)
+						Expect(err).To(MatchError(`failed to add profile script "../some-script.sh": name must not contain a path`))
+					})
+				})
+			})
+		})
+
 		context("when the app has environment variables", func() {
 			it("pushes the app with those environment variables", func() {
 				logs := bytes.NewBuffer(nil)
@@ -310,7 +683,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 						"SOME_VARIABLE":  "some-value",
 						"OTHER_VARIABLE": "other-value",
 					}).
-					Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(executions).To(HaveLen(18))
@@ -334,7 +707,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 			it("uses a private network security group", func() {
 				_, err := setup.
 					WithoutInternetAccess().
-					Run(bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 				Expect(err).NotTo(HaveOccurred())
 
 				content, err := os.ReadFile(filepath.Join(workspace, "some-home", "security-group.json"))
@@ -380,7 +753,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 							"other-key": "other-value",
 						},
 					}).
-					Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(executions).To(HaveLen(20))
@@ -410,6 +783,104 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the app has credentials", func() {
+			it("creates and binds a credhub service instance", func() {
+				logs := bytes.NewBuffer(nil)
+
+				_, err := setup.
+					WithCredentials(map[string]interface{}{
+						"some-credential": "some-value",
+					}).
+					Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions).To(HaveLen(18))
+				Expect(executions[16]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"create-service", "credhub", "default", "some-app-credentials", "-c", `{"some-credential":"some-value"}`}),
+					"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
+				}))
+				Expect(executions[17]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"bind-service", "some-app", "some-app-credentials"}),
+					"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
+				}))
+			})
+
+			context("failure cases", func() {
+				context("when the credhub service cannot be created", func() {
+					it.Before(func() {
+						stub := executable.ExecuteCall.Stub
+						executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+							if strings.HasPrefix(strings.Join(execution.Args, " "), "create-service") {
+								fmt.Fprintln(execution.Stdout, "could not create service")
+								return errors.New("exit status 1")
+							}
+
+							return stub(execution)
+						}
+					})
+
+					it("returns an error and the build logs", func() {
+						_, err := setup.
+							WithCredentials(map[string]interface{}{
+								"some-credential": "some-value",
+							}).
+							Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+						Expect(err).To(MatchError(ContainSubstring("failed to create-service: exit status 1")))
+						Expect(err).To(MatchError(ContainSubstring("could not create service")))
+					})
+				})
+			})
+		})
+
+		context("when the app has features", func() {
+			it("updates those features on the app", func() {
+				_, err := setup.
+					WithAppFeatures(map[string]bool{
+						"ssh":       false,
+						"revisions": true,
+					}).
+					Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions).To(HaveLen(19))
+				Expect(executions[16]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"app", "some-app", "--guid"}),
+				}))
+				Expect(executions[17]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"curl", "-X", "PATCH", "/v3/apps/some-app-guid/features/revisions", "-d", `{"enabled":true}`}),
+					"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
+				}))
+				Expect(executions[18]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"curl", "-X", "PATCH", "/v3/apps/some-app-guid/features/ssh", "-d", `{"enabled":false}`}),
+					"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
+				}))
+			})
+
+			context("failure cases", func() {
+				context("when a feature cannot be updated", func() {
+					it.Before(func() {
+						stub := executable.ExecuteCall.Stub
+						executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+							if strings.HasPrefix(strings.Join(execution.Args, " "), "curl -X PATCH") {
+								fmt.Fprintln(execution.Stdout, "could not update feature")
+								return errors.New("exit status 1")
+							}
+
+							return stub(execution)
+						}
+					})
+
+					it("returns an error and the build logs", func() {
+						_, err := setup.
+							WithAppFeatures(map[string]bool{"ssh": true}).
+							Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+						Expect(err).To(MatchError(ContainSubstring("failed to update app feature ssh: exit status 1")))
+						Expect(err).To(MatchError(ContainSubstring("could not update feature")))
+					})
+				})
+			})
+		})
+
 		context("when the tcp domain already exists", func() {
 			it.Before(func() {
 				executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
@@ -451,7 +922,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 			it("skips creating that domain again", func() {
 				logs := bytes.NewBuffer(nil)
 
-				_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+				_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(executions).To(HaveLen(14))
@@ -513,7 +984,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 			it("strips the prefix from the domain", func() {
 				logs := bytes.NewBuffer(nil)
 
-				_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+				_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(executions).To(HaveLen(16))
@@ -543,7 +1014,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := setup.Run(bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to make temporary $CF_HOME:")))
 					Expect(err).To(MatchError(ContainSubstring("permission denied")))
 				})
@@ -559,7 +1030,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := setup.Run(bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to copy $CF_HOME:")))
 					Expect(err).To(MatchError(ContainSubstring("permission denied")))
 				})
@@ -581,7 +1052,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to curl /v3/domains: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring(`{"error": "could not list domains"}`)))
 
@@ -604,7 +1075,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to parse domains")))
 					Expect(err).To(MatchError(ContainSubstring("invalid character '%'")))
 				})
@@ -633,7 +1104,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to curl /routing/v1/router_groups: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring(`{"error": "could not list router groups"}`)))
 
@@ -663,7 +1134,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to parse router groups")))
 					Expect(err).To(MatchError(ContainSubstring("invalid character '%'")))
 				})
@@ -698,7 +1169,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to create-shared-domain: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Shared domain failed to create")))
 
@@ -735,7 +1206,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to create-org: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Org failed to create")))
 
@@ -772,7 +1243,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to create-space: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Space failed to create")))
 
@@ -809,7 +1280,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to target: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Target failed")))
 
@@ -823,7 +1294,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := setup.Run(bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("invalid character '%'")))
 				})
 			})
@@ -834,7 +1305,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := setup.Run(bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring(`invalid URL escape "%%%"`)))
 				})
 			})
@@ -845,7 +1316,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := setup.Run(bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("no such host")))
 				})
 			})
@@ -857,11 +1328,48 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := setup.Run(bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("permission denied")))
 				})
 			})
 
+			context("when the source tarball cannot be extracted", func() {
+				var source string
+
+				it.Before(func() {
+					source = filepath.Join(workspace, "source.tgz")
+					Expect(os.WriteFile(source, []byte{0x1f, 0x8b, 0x00}, 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := setup.Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", source)
+					Expect(err).To(MatchError(ContainSubstring("failed to extract source")))
+				})
+			})
+
+			context("when the manifest cannot be written", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(workspace, "some-home", "manifest.yml"), os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := setup.
+						WithSidecars([]cloudfoundry.Sidecar{{Name: "some-sidecar", Command: "some-command"}}).
+						Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError(ContainSubstring("failed to write manifest")))
+				})
+			})
+
+			context("when a manifest is combined with sidecars", func() {
+				it("returns an error", func() {
+					_, err := setup.
+						WithManifest(cloudfoundry.Manifest{Path: "/some/path/to/manifest.yml"}).
+						WithSidecars([]cloudfoundry.Sidecar{{Name: "some-sidecar", Command: "some-command"}}).
+						Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					Expect(err).To(MatchError("failed to push: sidecars and metadata cannot be combined with the manifest at /some/path/to/manifest.yml"))
+				})
+			})
+
 			context("when the security-group cannot be created", func() {
 				it.Before(func() {
 					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
@@ -891,7 +1399,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to create-security-group: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Security group failed to create")))
 
@@ -928,7 +1436,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to bind-security-group: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Security group failed to bind")))
 
@@ -965,7 +1473,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to curl /v2/security_groups: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring(`{"error": "could not list security groups"}`)))
 
@@ -1001,7 +1509,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to parse security groups")))
 					Expect(err).To(MatchError(ContainSubstring("invalid character '%'")))
 				})
@@ -1041,7 +1549,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to update-security-group: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Security group failed to update")))
 
@@ -1083,7 +1591,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to push: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("App failed to create")))
 
@@ -1125,7 +1633,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to update-quota: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Quota failed to update")))
 
@@ -1167,7 +1675,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to map-route: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Route failed to map")))
 
@@ -1209,7 +1717,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to curl /v3/spaces: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring(`{"error": "could not list spaces"}`)))
 
@@ -1250,7 +1758,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to parse spaces")))
 					Expect(err).To(MatchError(ContainSubstring("invalid character '%'")))
 				})
@@ -1297,7 +1805,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to curl /v3/routes: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring(`{"error": "could not list routes"}`)))
 
@@ -1345,7 +1853,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := setup.Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+					_, err := setup.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to parse routes")))
 					Expect(err).To(MatchError(ContainSubstring("invalid character '%'")))
 				})
@@ -1399,7 +1907,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 
 					_, err := setup.
 						WithEnv(map[string]string{"SOME_VARIABLE": "some-value"}).
-						Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+						Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to set-env: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("App failed to set environment")))
 
@@ -1417,7 +1925,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 								"some-key": func() {},
 							},
 						}).
-						Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+						Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to marshal services json")))
 					Expect(err).To(MatchError(ContainSubstring("unsupported type: func()")))
 				})
@@ -1475,7 +1983,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 								"some-key": "some-value",
 							},
 						}).
-						Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+						Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to create-user-provided-service: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("could not create user-provided service")))
 				})
@@ -1533,7 +2041,7 @@ func testSetup(t *testing.T, context spec.G, it spec.S) {
 								"some-key": "some-value",
 							},
 						}).
-						Run(logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
+						Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app", "/some/path/to/my/app")
 					Expect(err).To(MatchError(ContainSubstring("failed to bind-service: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("could not bind service")))
 				})
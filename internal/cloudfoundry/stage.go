@@ -2,6 +2,7 @@ package cloudfoundry
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,11 +14,13 @@ import (
 )
 
 type StagePhase interface {
-	Run(logs io.Writer, home, name string) (url string, err error)
+	Run(ctx context.Context, logs io.Writer, home, name string) (url string, err error)
+	WithProcessType(processType string) StagePhase
 }
 
 type Stage struct {
-	cli Executable
+	cli         Executable
+	processType string
 }
 
 func NewStage(cli Executable) Stage {
@@ -26,7 +29,14 @@ func NewStage(cli Executable) Stage {
 	}
 }
 
-func (s Stage) Run(logs io.Writer, home, name string) (string, error) {
+func (s Stage) WithProcessType(processType string) StagePhase {
+	s.processType = processType
+	return s
+}
+
+func (s Stage) Run(ctx context.Context, logs io.Writer, home, name string) (string, error) {
+	s.cli = withContext(ctx, s.cli)
+
 	env := append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home))
 
 	err := s.cli.Execute(pexec.Execution{
@@ -39,6 +49,20 @@ func (s Stage) Run(logs io.Writer, home, name string) (string, error) {
 		return "", fmt.Errorf("failed to start: %w\n\nOutput:\n%s", err, logs)
 	}
 
+	// Only the web process is given an instance when the app is pushed, so
+	// any other process type is scaled up once the app has started.
+	if s.processType != "" && s.processType != "web" {
+		err = s.cli.Execute(pexec.Execution{
+			Args:   []string{"scale", name, "--process", s.processType, "-i", "1"},
+			Stdout: logs,
+			Stderr: logs,
+			Env:    env,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to scale %s process: %w\n\nOutput:\n%s", s.processType, err, logs)
+		}
+	}
+
 	buffer := bytes.NewBuffer(nil)
 	err = s.cli.Execute(pexec.Execution{
 		Args:   []string{"app", name, "--guid"},
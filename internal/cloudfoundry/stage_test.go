@@ -2,6 +2,7 @@ package cloudfoundry_test
 
 import (
 	"bytes"
+	gocontext "context"
 	"errors"
 	"fmt"
 	"os"
@@ -80,7 +81,7 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 		it("stages the app", func() {
 			logs := bytes.NewBuffer(nil)
 
-			url, err := stage.Run(logs, filepath.Join(workspace, "some-home"), "some-app")
+			url, err := stage.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(url).To(Equal("http://some-app.example.com/some/path"))
 
@@ -105,7 +106,61 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 			Expect(logs).To(ContainLines("Starting app..."))
 		})
 
+		context("WithProcessType", func() {
+			it("scales that process type up once the app has started", func() {
+				_, err := stage.WithProcessType("worker").Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions).To(HaveLen(5))
+				Expect(executions[0].Args).To(Equal([]string{"start", "some-app"}))
+				Expect(executions[1]).To(MatchFields(IgnoreExtras, Fields{
+					"Args": Equal([]string{"scale", "some-app", "--process", "worker", "-i", "1"}),
+					"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
+				}))
+			})
+
+			context("when the process type is web", func() {
+				it("does not scale it", func() {
+					_, err := stage.WithProcessType("web").Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(executions).To(HaveLen(4))
+				})
+			})
+
+			context("when the process cannot be scaled", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						if strings.HasPrefix(strings.Join(execution.Args, " "), "scale") {
+							fmt.Fprintln(execution.Stdout, "Process worker not found")
+							return errors.New("exit status 1")
+						}
+						return nil
+					}
+				})
+
+				it("returns an error and the build logs", func() {
+					_, err := stage.WithProcessType("worker").Run(gocontext.Background(), bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to scale worker process: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("Process worker not found")))
+				})
+			})
+		})
+
 		context("failure cases", func() {
+			context("when the context is done", func() {
+				it("returns an error without invoking the cli", func() {
+					ctx, cancel := gocontext.WithCancel(gocontext.Background())
+					cancel()
+
+					_, err := stage.Run(ctx, bytes.NewBuffer(nil), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to start:")))
+					Expect(err).To(MatchError(gocontext.Canceled))
+
+					Expect(executable.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
 			context("when the app cannot be started", func() {
 				it.Before(func() {
 					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
@@ -120,7 +175,7 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := stage.Run(logs, filepath.Join(workspace, "some-home"), "some-app")
+					_, err := stage.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to start: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("App failed to start")))
 
@@ -143,7 +198,7 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := stage.Run(logs, filepath.Join(workspace, "some-home"), "some-app")
+					_, err := stage.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to fetch guid: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Could not fetch guid")))
 
@@ -166,7 +221,7 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := stage.Run(logs, filepath.Join(workspace, "some-home"), "some-app")
+					_, err := stage.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to fetch routes: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Could not fetch routes")))
 
@@ -189,7 +244,7 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := stage.Run(logs, filepath.Join(workspace, "some-home"), "some-app")
+					_, err := stage.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to parse routes: invalid character '%'")))
 
 					Expect(logs).To(ContainSubstring("Some log output"))
@@ -228,7 +283,7 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := stage.Run(logs, filepath.Join(workspace, "some-home"), "some-app")
+					_, err := stage.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to fetch domain: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Could not fetch domain")))
 
@@ -267,7 +322,7 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error and the build logs", func() {
 					logs := bytes.NewBuffer(nil)
 
-					_, err := stage.Run(logs, filepath.Join(workspace, "some-home"), "some-app")
+					_, err := stage.Run(gocontext.Background(), logs, filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to parse domain: invalid character '%'")))
 
 					Expect(logs).To(ContainSubstring("Some log output"))
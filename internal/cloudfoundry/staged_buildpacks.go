@@ -0,0 +1,79 @@
+package cloudfoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type StagedBuildpack struct {
+	Name         string
+	Version      string
+	DetectOutput string
+}
+
+type StagedBuildpackReader struct {
+	cli Executable
+}
+
+func NewStagedBuildpackReader(cli Executable) StagedBuildpackReader {
+	return StagedBuildpackReader{
+		cli: cli,
+	}
+}
+
+func (r StagedBuildpackReader) Read(ctx context.Context, home, name string) ([]StagedBuildpack, error) {
+	r.cli = withContext(ctx, r.cli)
+
+	env := append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home))
+
+	guid, err := appGUID(r.cli, env, name)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	err = r.cli.Execute(pexec.Execution{
+		Args:   []string{"curl", path.Join("/v3", "apps", guid, "droplets", "current")},
+		Stdout: buffer,
+		Stderr: buffer,
+		Env:    env,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch droplet: %w\n\nOutput:\n%s", err, buffer)
+	}
+
+	var droplet struct {
+		Buildpacks []struct {
+			Name          string `json:"name"`
+			BuildpackName string `json:"buildpack_name"`
+			Version       string `json:"version"`
+			DetectOutput  string `json:"detect_output"`
+		} `json:"buildpacks"`
+	}
+	err = json.NewDecoder(buffer).Decode(&droplet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse droplet buildpacks: %w\n\nOutput:\n%s", err, buffer)
+	}
+
+	var buildpacks []StagedBuildpack
+	for _, buildpack := range droplet.Buildpacks {
+		name := buildpack.BuildpackName
+		if name == "" {
+			name = buildpack.Name
+		}
+
+		buildpacks = append(buildpacks, StagedBuildpack{
+			Name:         name,
+			Version:      buildpack.Version,
+			DetectOutput: buildpack.DetectOutput,
+		})
+	}
+
+	return buildpacks, nil
+}
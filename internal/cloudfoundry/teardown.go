@@ -2,51 +2,78 @@ package cloudfoundry
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/paketo-buildpacks/packit/v2/pexec"
 )
 
 type TeardownPhase interface {
-	Run(home, name string) error
+	Run(ctx context.Context, home, name string) (TeardownReport, error)
+	List(home, name string) ([]Resource, error)
+	Archive(home, name, dir string) error
+	Names(ctx context.Context, prefix string) ([]string, error)
 }
 
 type Teardown struct {
-	cli Executable
+	cli          Executable
+	pollInterval time.Duration
+	pollTimeout  time.Duration
+	graceful     bool
+	clock        Clock
 }
 
 func NewTeardown(cli Executable) Teardown {
 	return Teardown{
-		cli: cli,
+		cli:          cli,
+		pollInterval: time.Second,
+		pollTimeout:  5 * time.Minute,
+		clock:        realClock{},
 	}
 }
 
-func (t Teardown) Run(home, name string) error {
+func (t Teardown) WithOperationPolling(interval, timeout time.Duration) Teardown {
+	t.pollInterval = interval
+	t.pollTimeout = timeout
+	return t
+}
+
+func (t Teardown) WithClock(clock Clock) Teardown {
+	t.clock = clock
+	return t
+}
+
+func (t Teardown) WithGracefulStop() Teardown {
+	t.graceful = true
+	return t
+}
+
+func (t Teardown) Run(ctx context.Context, home, name string) (TeardownReport, error) {
+	t.cli = withContext(ctx, t.cli)
+
+	var report TeardownReport
 	logs := bytes.NewBuffer(nil)
-	env := append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home))
+	env := os.Environ()
 
-	err := t.cli.Execute(pexec.Execution{
-		Args:   []string{"delete-org", name, "-f"},
-		Stdout: logs,
-		Stderr: logs,
-		Env:    env,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to delete-org: %w\n\nOutput:\n%s", err, logs)
+	_, err := os.Stat(home)
+	if err == nil {
+		env = append(env, fmt.Sprintf("CF_HOME=%s", home))
 	}
+	targeted := err == nil
 
-	err = t.cli.Execute(pexec.Execution{
-		Args:   []string{"delete-security-group", name, "-f"},
-		Stdout: logs,
-		Stderr: logs,
-		Env:    env,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to delete-security-group: %w\n\nOutput:\n%s", err, logs)
+	if t.graceful && targeted {
+		_, err = t.delete(logs, env, "stop", name)
+		if err != nil {
+			return report, fmt.Errorf("failed to stop: %w\n\nOutput:\n%s", err, logs)
+		}
 	}
 
 	buffer := bytes.NewBuffer(nil)
@@ -57,37 +84,346 @@ func (t Teardown) Run(home, name string) error {
 		Env:    env,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to curl /v3/service_instances: %w\n\nOutput:\n%s", err, logs)
+		return report, fmt.Errorf("failed to curl /v3/service_instances: %w\n\nOutput:\n%s", err, logs)
 	}
 
 	var serviceInstances struct {
 		Resources []struct {
+			GUID string `json:"guid"`
 			Name string `json:"name"`
 		} `json:"resources"`
 	}
 	err = json.NewDecoder(buffer).Decode(&serviceInstances)
 	if err != nil {
-		return fmt.Errorf("failed to decode service instance json: %w", err)
+		return report, fmt.Errorf("failed to decode service instance json: %w", err)
 	}
 
+	var services []Resource
 	for _, service := range serviceInstances.Resources {
 		if strings.HasPrefix(service.Name, fmt.Sprintf("%s-", name)) {
-			err = t.cli.Execute(pexec.Execution{
-				Args:   []string{"delete-service", service.Name, "-f"},
-				Stdout: logs,
-				Stderr: logs,
-				Env:    env,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to delete-service: %w\n\nOutput:\n%s", err, logs)
-			}
+			services = append(services, Resource{Kind: "service", Name: service.Name, ID: service.GUID})
+		}
+	}
+
+	for _, service := range services {
+		err = t.deleteBindings(&report, logs, env, service.ID)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if t.graceful && targeted {
+		err = t.unmapRoutes(&report, logs, env, name)
+		if err != nil {
+			return report, err
 		}
 	}
 
+	for _, service := range services {
+		removed, err := t.delete(logs, env, "delete-service", service.Name, "-f")
+		if err != nil {
+			err = fmt.Errorf("failed to delete-service: %w\n\nOutput:\n%s", err, logs)
+		}
+
+		err = report.record(service, removed, err)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	removed, err := t.delete(logs, env, "delete-org", name, "-f")
+	if err != nil {
+		err = fmt.Errorf("failed to delete-org: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	err = report.record(Resource{Kind: "org", Name: name}, removed, err)
+	if err != nil {
+		return report, err
+	}
+
+	removed, err = t.delete(logs, env, "delete-security-group", name, "-f")
+	if err != nil {
+		err = fmt.Errorf("failed to delete-security-group: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	err = report.record(Resource{Kind: "security-group", Name: name}, removed, err)
+	if err != nil {
+		return report, err
+	}
+
+	_, err = os.Stat(home)
+	removed = err == nil
+
 	err = os.RemoveAll(home)
+	err = report.record(Resource{Kind: "file", Name: home}, removed, err)
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+func (t Teardown) List(home, name string) ([]Resource, error) {
+	logs := bytes.NewBuffer(nil)
+	env := append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home))
+	now := time.Now()
+
+	var resources []Resource
+	for _, resource := range []struct {
+		path string
+		kind string
+	}{
+		{path: fmt.Sprintf("/v3/organizations?names=%s", name), kind: "org"},
+		{path: fmt.Sprintf("/v3/security_groups?names=%s", name), kind: "security-group"},
+		{path: "/v3/service_instances", kind: "service"},
+	} {
+		found, err := listResources(t.cli, logs, env, resource.path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range found {
+			match := r.Name == name
+			if resource.kind == "service" {
+				match = strings.HasPrefix(r.Name, fmt.Sprintf("%s-", name))
+			}
+
+			if match {
+				resources = append(resources, Resource{Kind: resource.kind, Name: r.Name, ID: r.GUID, Age: now.Sub(r.CreatedAt)})
+			}
+		}
+	}
+
+	info, err := os.Stat(home)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to stat home: %w", err)
+	}
+
+	if info != nil {
+		resources = append(resources, Resource{Kind: "file", Name: home, Age: now.Sub(info.ModTime())})
+	}
+
+	return resources, nil
+}
+
+func (t Teardown) Names(ctx context.Context, prefix string) ([]string, error) {
+	t.cli = withContext(ctx, t.cli)
+
+	orgs, err := listResources(t.cli, bytes.NewBuffer(nil), nil, "/v3/organizations")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, org := range orgs {
+		if strings.HasPrefix(org.Name, prefix) {
+			names = append(names, org.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (t Teardown) Archive(home, name, dir string) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	logs := bytes.NewBuffer(nil)
+	buffer := bytes.NewBuffer(nil)
+	err = t.cli.Execute(pexec.Execution{
+		Args:   []string{"logs", name, "--recent"},
+		Stdout: io.MultiWriter(buffer, logs),
+		Stderr: logs,
+		Env:    append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch recent logs: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "output.log"), buffer.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write recent logs: %w", err)
+	}
+
+	logs = bytes.NewBuffer(nil)
+	buffer = bytes.NewBuffer(nil)
+	err = t.cli.Execute(pexec.Execution{
+		Args:   []string{"events", name},
+		Stdout: io.MultiWriter(buffer, logs),
+		Stderr: logs,
+		Env:    append(os.Environ(), fmt.Sprintf("CF_HOME=%s", home)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch app events: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "events.log"), buffer.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write app events: %w", err)
+	}
+
+	return nil
+}
+
+func (t Teardown) deleteBindings(report *TeardownReport, logs io.Writer, env []string, serviceInstanceGUID string) error {
+	bindings, err := listResources(t.cli, logs, env, fmt.Sprintf("/v3/service_credential_bindings?service_instance_guids=%s", serviceInstanceGUID))
 	if err != nil {
 		return err
 	}
 
+	for _, binding := range bindings {
+		path := fmt.Sprintf("/v3/service_credential_bindings/%s", binding.GUID)
+
+		removed, err := t.delete(logs, env, "curl", "-X", "DELETE", path)
+		if err != nil {
+			err = fmt.Errorf("failed to delete service credential binding: %w\n\nOutput:\n%s", err, logs)
+		} else if removed {
+			err = t.waitForDeletion(logs, env, path)
+		}
+
+		err = report.record(Resource{Kind: "service-credential-binding", Name: binding.Name, ID: binding.GUID}, removed, err)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+func (t Teardown) unmapRoutes(report *TeardownReport, logs io.Writer, env []string, name string) error {
+	buffer := bytes.NewBuffer(nil)
+	err := t.cli.Execute(pexec.Execution{
+		Args:   []string{"app", name, "--guid"},
+		Stdout: io.MultiWriter(buffer, logs),
+		Stderr: io.MultiWriter(buffer, logs),
+		Env:    env,
+	})
+	if err != nil {
+		if isNotFound(buffer.String()) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to fetch guid: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	guid := strings.TrimSpace(buffer.String())
+	path := fmt.Sprintf("/v3/apps/%s/routes", guid)
+
+	buffer = bytes.NewBuffer(nil)
+	err = t.cli.Execute(pexec.Execution{
+		Args:   []string{"curl", path},
+		Stdout: io.MultiWriter(buffer, logs),
+		Stderr: logs,
+		Env:    env,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to curl %s: %w\n\nOutput:\n%s", path, err, logs)
+	}
+
+	var routes struct {
+		Resources []struct {
+			GUID         string `json:"guid"`
+			URL          string `json:"url"`
+			Destinations []struct {
+				GUID string `json:"guid"`
+				App  struct {
+					GUID string `json:"guid"`
+				} `json:"app"`
+			} `json:"destinations"`
+		} `json:"resources"`
+	}
+	err = json.NewDecoder(buffer).Decode(&routes)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s json: %w", path, err)
+	}
+
+	for _, route := range routes.Resources {
+		for _, destination := range route.Destinations {
+			if destination.App.GUID != guid {
+				continue
+			}
+
+			removed, err := t.delete(logs, env, "curl", "-X", "DELETE", fmt.Sprintf("/v3/routes/%s/destinations/%s", route.GUID, destination.GUID))
+			if err != nil {
+				err = fmt.Errorf("failed to unmap route: %w\n\nOutput:\n%s", err, logs)
+			}
+
+			err = report.record(Resource{Kind: "route-mapping", Name: route.URL, ID: destination.GUID}, removed, err)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t Teardown) waitForDeletion(logs io.Writer, env []string, path string) error {
+	deadline := t.clock.Now().Add(t.pollTimeout)
+
+	for {
+		buffer := bytes.NewBuffer(nil)
+		err := t.cli.Execute(pexec.Execution{
+			Args:   []string{"curl", path},
+			Stdout: io.MultiWriter(buffer, logs),
+			Stderr: logs,
+			Env:    env,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to curl %s: %w\n\nOutput:\n%s", path, err, logs)
+		}
+
+		if isNotFound(buffer.String()) {
+			return nil
+		}
+
+		var resource struct {
+			LastOperation struct {
+				State       string `json:"state"`
+				Description string `json:"description"`
+			} `json:"last_operation"`
+		}
+		err = json.Unmarshal(buffer.Bytes(), &resource)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s json: %w", path, err)
+		}
+
+		if resource.LastOperation.State == "failed" {
+			return fmt.Errorf("failed to delete %s: %s", path, resource.LastOperation.Description)
+		}
+
+		if t.clock.Now().After(deadline) {
+			return fmt.Errorf("failed to delete %s within %s", path, t.pollTimeout)
+		}
+
+		<-t.clock.After(t.pollInterval)
+	}
+}
+
+func (t Teardown) delete(logs io.Writer, env []string, args ...string) (bool, error) {
+	output := bytes.NewBuffer(nil)
+	err := t.cli.Execute(pexec.Execution{
+		Args:   args,
+		Stdout: io.MultiWriter(logs, output),
+		Stderr: io.MultiWriter(logs, output),
+		Env:    env,
+	})
+	if isNotFound(output.String()) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func isNotFound(output string) bool {
+	for _, message := range []string{"not found", "does not exist", "CF-ResourceNotFound"} {
+		if strings.Contains(output, message) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,7 @@
 package cloudfoundry_test
 
 import (
+	gocontext "context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
 	"github.com/cloudfoundry/switchblade/internal/cloudfoundry/fakes"
@@ -29,30 +31,54 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			workspace  string
 
 			executions []pexec.Execution
+			respond    func(execution pexec.Execution) error
+			polls      int
 		)
 
 		it.Before(func() {
-			executable = &fakes.Executable{}
-			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
-				executions = append(executions, execution)
-
-				if strings.HasPrefix(strings.Join(execution.Args, " "), "curl /v3/service_instances") {
-					err := json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{
+			executions = nil
+			polls = 0
+			respond = func(execution pexec.Execution) error {
+				command := strings.Join(execution.Args, " ")
+				switch {
+				case strings.HasPrefix(command, "curl /v3/service_instances"):
+					return json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{
+						"resources": []map[string]interface{}{
+							{"guid": "other-app-some-service-guid", "name": "other-app-some-service"},
+							{"guid": "some-app-some-service-guid", "name": "some-app-some-service"},
+							{"guid": "other-app-other-service-guid", "name": "other-app-other-service"},
+							{"guid": "some-app-other-service-guid", "name": "some-app-other-service"},
+						},
+					})
+				case command == "curl /v3/service_credential_bindings?service_instance_guids=some-app-some-service-guid":
+					return json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{
 						"resources": []map[string]interface{}{
-							{"name": "other-app-some-service"},
-							{"name": "some-app-some-service"},
-							{"name": "other-app-other-service"},
-							{"name": "some-app-other-service"},
+							{"guid": "some-key-guid", "name": "some-key"},
+							{"guid": "some-binding-guid"},
 						},
 					})
-					if err != nil {
-						return err
+				case strings.HasPrefix(command, "curl /v3/service_credential_bindings?"):
+					return json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{"resources": []interface{}{}})
+				case command == "curl /v3/service_credential_bindings/some-key-guid":
+					polls++
+					if polls == 1 {
+						fmt.Fprintln(execution.Stdout, `{"guid": "some-key-guid", "last_operation": {"state": "in progress"}}`)
+						return nil
 					}
+					fmt.Fprintln(execution.Stdout, `{"errors": [{"title": "CF-ResourceNotFound"}]}`)
+				case strings.HasPrefix(command, "curl /v3/service_credential_bindings/"):
+					fmt.Fprintln(execution.Stdout, `{"errors": [{"title": "CF-ResourceNotFound"}]}`)
 				}
 
 				return nil
 			}
 
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				executions = append(executions, execution)
+				return respond(execution)
+			}
+
 			var err error
 			workspace, err = os.MkdirTemp("", "workspace")
 			Expect(err).NotTo(HaveOccurred())
@@ -60,52 +86,275 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			err = os.MkdirAll(filepath.Join(workspace, "some-home"), os.ModePerm)
 			Expect(err).NotTo(HaveOccurred())
 
-			teardown = cloudfoundry.NewTeardown(executable)
+			teardown = cloudfoundry.NewTeardown(executable).WithOperationPolling(time.Millisecond, time.Second)
 		})
 
-		it("deletes the org, security-group, service-instances, and config", func() {
-			err := teardown.Run(filepath.Join(workspace, "some-home"), "some-app")
+		it.After(func() {
+			Expect(os.RemoveAll(workspace)).To(Succeed())
+		})
+
+		it("deletes the service keys and bindings, service-instances, org, security-group, and config", func() {
+			_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(executions).To(HaveLen(5))
-			Expect(executions[0]).To(MatchFields(IgnoreExtras, Fields{
-				"Args": Equal([]string{"delete-org", "some-app", "-f"}),
-				"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
-			}))
-			Expect(executions[1]).To(MatchFields(IgnoreExtras, Fields{
-				"Args": Equal([]string{"delete-security-group", "some-app", "-f"}),
-				"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
-			}))
-			Expect(executions[2]).To(MatchFields(IgnoreExtras, Fields{
-				"Args": Equal([]string{"curl", "/v3/service_instances"}),
-				"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
-			}))
-			Expect(executions[3]).To(MatchFields(IgnoreExtras, Fields{
-				"Args": Equal([]string{"delete-service", "some-app-some-service", "-f"}),
-				"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
-			}))
-			Expect(executions[4]).To(MatchFields(IgnoreExtras, Fields{
-				"Args": Equal([]string{"delete-service", "some-app-other-service", "-f"}),
-				"Env":  ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))),
+			var commands []string
+			for _, execution := range executions {
+				commands = append(commands, strings.Join(execution.Args, " "))
+				Expect(execution.Env).To(ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))))
+			}
+
+			Expect(commands).To(Equal([]string{
+				"curl /v3/service_instances",
+				"curl /v3/service_credential_bindings?service_instance_guids=some-app-some-service-guid",
+				"curl -X DELETE /v3/service_credential_bindings/some-key-guid",
+				"curl /v3/service_credential_bindings/some-key-guid",
+				"curl /v3/service_credential_bindings/some-key-guid",
+				"curl -X DELETE /v3/service_credential_bindings/some-binding-guid",
+				"curl /v3/service_credential_bindings/some-binding-guid",
+				"curl /v3/service_credential_bindings?service_instance_guids=some-app-other-service-guid",
+				"delete-service some-app-some-service -f",
+				"delete-service some-app-other-service -f",
+				"delete-org some-app -f",
+				"delete-security-group some-app -f",
 			}))
 
 			Expect(filepath.Join(workspace, "some-home")).NotTo(BeADirectory())
 		})
 
-		context("failure cases", func() {
-			context("when the delete-org fails", func() {
+		context("WithGracefulStop", func() {
+			it.Before(func() {
+				defaults := respond
+				respond = func(execution pexec.Execution) error {
+					switch strings.Join(execution.Args, " ") {
+					case "app some-app --guid":
+						fmt.Fprintln(execution.Stdout, "some-app-guid")
+						return nil
+					case "curl /v3/apps/some-app-guid/routes":
+						return json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{
+							"resources": []map[string]interface{}{
+								{
+									"guid": "some-route-guid",
+									"url":  "some-app.example.com",
+									"destinations": []map[string]interface{}{
+										{"guid": "some-destination-guid", "app": map[string]interface{}{"guid": "some-app-guid"}},
+										{"guid": "other-destination-guid", "app": map[string]interface{}{"guid": "other-app-guid"}},
+									},
+								},
+							},
+						})
+					}
+
+					return defaults(execution)
+				}
+
+				teardown = teardown.WithGracefulStop()
+			})
+
+			it("stops the app, unbinds its services, and unmaps its routes before deleting", func() {
+				report, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				var commands []string
+				for _, execution := range executions {
+					commands = append(commands, strings.Join(execution.Args, " "))
+				}
+
+				Expect(commands).To(Equal([]string{
+					"stop some-app",
+					"curl /v3/service_instances",
+					"curl /v3/service_credential_bindings?service_instance_guids=some-app-some-service-guid",
+					"curl -X DELETE /v3/service_credential_bindings/some-key-guid",
+					"curl /v3/service_credential_bindings/some-key-guid",
+					"curl /v3/service_credential_bindings/some-key-guid",
+					"curl -X DELETE /v3/service_credential_bindings/some-binding-guid",
+					"curl /v3/service_credential_bindings/some-binding-guid",
+					"curl /v3/service_credential_bindings?service_instance_guids=some-app-other-service-guid",
+					"app some-app --guid",
+					"curl /v3/apps/some-app-guid/routes",
+					"curl -X DELETE /v3/routes/some-route-guid/destinations/some-destination-guid",
+					"delete-service some-app-some-service -f",
+					"delete-service some-app-other-service -f",
+					"delete-org some-app -f",
+					"delete-security-group some-app -f",
+				}))
+
+				Expect(report.Removed).To(ContainElement(cloudfoundry.Resource{Kind: "route-mapping", Name: "some-app.example.com", ID: "some-destination-guid"}))
+			})
+
+			context("when the app has already been deleted", func() {
 				it.Before(func() {
-					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
-						if strings.HasPrefix(strings.Join(execution.Args, " "), "delete-org") {
-							fmt.Fprintf(execution.Stdout, "Could not delete org")
+					defaults := respond
+					respond = func(execution pexec.Execution) error {
+						switch execution.Args[0] {
+						case "stop", "app":
+							fmt.Fprintln(execution.Stdout, "App 'some-app' not found.")
 							return errors.New("exit status 1")
 						}
-						return nil
+
+						return defaults(execution)
+					}
+				})
+
+				it("skips to deleting the remaining resources", func() {
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(executions[len(executions)-2].Args).To(Equal([]string{"delete-org", "some-app", "-f"}))
+				})
+			})
+
+			context("when the home directory does not exist", func() {
+				it.Before(func() {
+					Expect(os.RemoveAll(filepath.Join(workspace, "some-home"))).To(Succeed())
+				})
+
+				it("does not stop or unmap anything outside of a targeted space", func() {
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					for _, execution := range executions {
+						Expect(execution.Args[0]).NotTo(BeElementOf("stop", "app"))
+					}
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the app cannot be stopped", func() {
+					it.Before(func() {
+						defaults := respond
+						respond = func(execution pexec.Execution) error {
+							if execution.Args[0] == "stop" {
+								fmt.Fprintln(execution.Stdout, "stop failed")
+								return errors.New("exit status 1")
+							}
+
+							return defaults(execution)
+						}
+					})
+
+					it("returns an error", func() {
+						_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+						Expect(err).To(MatchError(ContainSubstring("failed to stop: exit status 1")))
+					})
+				})
+
+				context("when a route cannot be unmapped", func() {
+					it.Before(func() {
+						defaults := respond
+						respond = func(execution pexec.Execution) error {
+							if strings.HasPrefix(strings.Join(execution.Args, " "), "curl -X DELETE /v3/routes/") {
+								fmt.Fprintln(execution.Stdout, "unmap failed")
+								return errors.New("exit status 1")
+							}
+
+							return defaults(execution)
+						}
+					})
+
+					it("returns an error", func() {
+						_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+						Expect(err).To(MatchError(ContainSubstring("failed to unmap route: exit status 1")))
+					})
+				})
+			})
+		})
+
+		it("reports what was removed", func() {
+			report, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report).To(Equal(cloudfoundry.TeardownReport{
+				Removed: []cloudfoundry.Resource{
+					{Kind: "service-credential-binding", Name: "some-key", ID: "some-key-guid"},
+					{Kind: "service-credential-binding", ID: "some-binding-guid"},
+					{Kind: "service", Name: "some-app-some-service", ID: "some-app-some-service-guid"},
+					{Kind: "service", Name: "some-app-other-service", ID: "some-app-other-service-guid"},
+					{Kind: "org", Name: "some-app"},
+					{Kind: "security-group", Name: "some-app"},
+					{Kind: "file", Name: filepath.Join(workspace, "some-home")},
+				},
+			}))
+		})
+
+		context("when the home directory does not exist", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(filepath.Join(workspace, "some-home"))).To(Succeed())
+			})
+
+			it("uses the default $CF_HOME", func() {
+				_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions[0].Args).To(Equal([]string{"curl", "/v3/service_instances"}))
+				Expect(executions[0].Env).NotTo(ContainElement(fmt.Sprintf("CF_HOME=%s", filepath.Join(workspace, "some-home"))))
+			})
+		})
+
+		context("when the resources have already been deleted", func() {
+			it.Before(func() {
+				respond = func(execution pexec.Execution) error {
+					command := strings.Join(execution.Args, " ")
+					switch {
+					case strings.HasPrefix(command, "delete-org"):
+						fmt.Fprintln(execution.Stdout, "Org 'some-app' does not exist.")
+						return errors.New("exit status 1")
+					case strings.HasPrefix(command, "delete-security-group"):
+						fmt.Fprintln(execution.Stderr, "Security group 'some-app' not found.")
+						return errors.New("exit status 1")
+					case strings.HasPrefix(command, "delete-service"):
+						fmt.Fprintln(execution.Stderr, "CF-ResourceNotFound")
+						return errors.New("exit status 1")
+					case strings.HasPrefix(command, "curl /v3/service_instances"):
+						return json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{
+							"resources": []map[string]interface{}{
+								{"guid": "some-app-some-service-guid", "name": "some-app-some-service"},
+							},
+						})
+					case strings.HasPrefix(command, "curl /v3/service_credential_bindings?"):
+						return json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{"resources": []interface{}{}})
 					}
+
+					return nil
+				}
+
+				Expect(os.RemoveAll(filepath.Join(workspace, "some-home"))).To(Succeed())
+			})
+
+			it("treats them as deleted and continues", func() {
+				report, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executions).To(HaveLen(5))
+				Expect(report.Removed).To(BeEmpty())
+				Expect(report.Missing).To(Equal([]cloudfoundry.Resource{
+					{Kind: "service", Name: "some-app-some-service", ID: "some-app-some-service-guid"},
+					{Kind: "org", Name: "some-app"},
+					{Kind: "security-group", Name: "some-app"},
+					{Kind: "file", Name: filepath.Join(workspace, "some-home")},
+				}))
+			})
+		})
+
+		context("failure cases", func() {
+			var fail = func(prefix, output string) {
+				defaults := respond
+				respond = func(execution pexec.Execution) error {
+					if strings.HasPrefix(strings.Join(execution.Args, " "), prefix) {
+						fmt.Fprint(execution.Stdout, output)
+						return errors.New("exit status 1")
+					}
+
+					return defaults(execution)
+				}
+			}
+
+			context("when the delete-org fails", func() {
+				it.Before(func() {
+					fail("delete-org", "Could not delete org")
 				})
 
 				it("returns an error", func() {
-					err := teardown.Run(filepath.Join(workspace, "some-home"), "some-app")
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to delete-org: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Could not delete org")))
 				})
@@ -113,17 +362,11 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 
 			context("when the delete-security-group fails", func() {
 				it.Before(func() {
-					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
-						if strings.HasPrefix(strings.Join(execution.Args, " "), "delete-security-group") {
-							fmt.Fprintf(execution.Stdout, "Could not delete security group")
-							return errors.New("exit status 1")
-						}
-						return nil
-					}
+					fail("delete-security-group", "Could not delete security group")
 				})
 
 				it("returns an error", func() {
-					err := teardown.Run(filepath.Join(workspace, "some-home"), "some-app")
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to delete-security-group: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Could not delete security group")))
 				})
@@ -131,17 +374,11 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 
 			context("when the curl /v3/service_instances fails", func() {
 				it.Before(func() {
-					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
-						if strings.HasPrefix(strings.Join(execution.Args, " "), "curl /v3/service_instances") {
-							fmt.Fprintf(execution.Stdout, "Could not curl service instances")
-							return errors.New("exit status 1")
-						}
-						return nil
-					}
+					fail("curl /v3/service_instances", "Could not curl service instances")
 				})
 
 				it("returns an error", func() {
-					err := teardown.Run(filepath.Join(workspace, "some-home"), "some-app")
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to curl /v3/service_instances: exit status 1")))
 					Expect(err).To(MatchError(ContainSubstring("Could not curl service instances")))
 				})
@@ -149,7 +386,7 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 
 			context("when the curl /v3/service_instances response is malformed", func() {
 				it.Before(func() {
-					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+					respond = func(execution pexec.Execution) error {
 						if strings.HasPrefix(strings.Join(execution.Args, " "), "curl /v3/service_instances") {
 							fmt.Fprintln(execution.Stdout, "%%%")
 						}
@@ -158,32 +395,338 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					err := teardown.Run(filepath.Join(workspace, "some-home"), "some-app")
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to decode service instance json:")))
 					Expect(err).To(MatchError(ContainSubstring("invalid character '%'")))
 				})
 			})
 
+			context("when the service credential bindings cannot be listed", func() {
+				it.Before(func() {
+					fail("curl /v3/service_credential_bindings?", "Could not curl bindings")
+				})
+
+				it("returns an error", func() {
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to curl /v3/service_credential_bindings: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("Could not curl bindings")))
+				})
+			})
+
+			context("when a service credential binding cannot be deleted", func() {
+				it.Before(func() {
+					fail("curl -X DELETE", "Could not delete binding")
+				})
+
+				it("returns an error", func() {
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to delete service credential binding: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("Could not delete binding")))
+				})
+			})
+
+			context("when the binding deletion fails asynchronously", func() {
+				it.Before(func() {
+					defaults := respond
+					respond = func(execution pexec.Execution) error {
+						if strings.Join(execution.Args, " ") == "curl /v3/service_credential_bindings/some-key-guid" {
+							fmt.Fprintln(execution.Stdout, `{"last_operation": {"state": "failed", "description": "broker refused"}}`)
+							return nil
+						}
+
+						return defaults(execution)
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).To(MatchError("failed to delete /v3/service_credential_bindings/some-key-guid: broker refused"))
+				})
+			})
+
+			context("when the binding is not deleted in time", func() {
+				it.Before(func() {
+					defaults := respond
+					respond = func(execution pexec.Execution) error {
+						if strings.Join(execution.Args, " ") == "curl /v3/service_credential_bindings/some-key-guid" {
+							fmt.Fprintln(execution.Stdout, `{"last_operation": {"state": "in progress"}}`)
+							return nil
+						}
+
+						return defaults(execution)
+					}
+
+					teardown = teardown.WithOperationPolling(time.Millisecond, 10*time.Millisecond)
+				})
+
+				it("returns an error", func() {
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).To(MatchError("failed to delete /v3/service_credential_bindings/some-key-guid within 10ms"))
+				})
+
+				context("when a clock is provided", func() {
+					var clock *fakes.Clock
+
+					it.Before(func() {
+						now := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+						clock = &fakes.Clock{}
+						clock.NowCall.Stub = func() time.Time {
+							now = now.Add(time.Minute)
+							return now
+						}
+						clock.AfterCall.Stub = func(time.Duration) <-chan time.Time {
+							c := make(chan time.Time)
+							close(c)
+							return c
+						}
+
+						teardown = teardown.WithOperationPolling(time.Minute, 5*time.Minute).WithClock(clock)
+					})
+
+					it("polls using that clock", func() {
+						_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+						Expect(err).To(MatchError("failed to delete /v3/service_credential_bindings/some-key-guid within 5m0s"))
+
+						Expect(clock.AfterCall.CallCount).To(Equal(5))
+						Expect(clock.AfterCall.Receives.D).To(Equal(time.Minute))
+					})
+				})
+			})
+
 			context("when the delete-service fails", func() {
+				it.Before(func() {
+					fail("delete-service", "Could not delete service")
+				})
+
+				it("returns an error", func() {
+					_, err := teardown.Run(gocontext.Background(), filepath.Join(workspace, "some-home"), "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to delete-service: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("Could not delete service")))
+				})
+			})
+		})
+	})
+
+	context("List", func() {
+		var (
+			teardown cloudfoundry.Teardown
+
+			executable *fakes.Executable
+			home       string
+
+			executions []pexec.Execution
+		)
+
+		it.Before(func() {
+			old := time.Now().Add(-time.Hour)
+
+			executions = nil
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				executions = append(executions, execution)
+
+				var resources []map[string]interface{}
+				switch strings.Join(execution.Args, " ") {
+				case "curl /v3/organizations?names=some-app":
+					resources = []map[string]interface{}{{"guid": "some-org-guid", "name": "some-app", "created_at": old.Format(time.RFC3339)}}
+				case "curl /v3/security_groups?names=some-app":
+					resources = []map[string]interface{}{{"guid": "some-security-group-guid", "name": "some-app", "created_at": old.Format(time.RFC3339)}}
+				case "curl /v3/service_instances":
+					resources = []map[string]interface{}{
+						{"guid": "other-service-guid", "name": "other-app-some-service", "created_at": old.Format(time.RFC3339)},
+						{"guid": "some-service-guid", "name": "some-app-some-service", "created_at": old.Format(time.RFC3339)},
+					}
+				}
+
+				return json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{"resources": resources})
+			}
+
+			var err error
+			home, err = os.MkdirTemp("", "home")
+			Expect(err).NotTo(HaveOccurred())
+
+			teardown = cloudfoundry.NewTeardown(executable)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(home)).To(Succeed())
+		})
+
+		it("returns the resources that would be deleted without deleting them", func() {
+			resources, err := teardown.List(home, "some-app")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resources).To(HaveLen(4))
+			Expect(resources[0]).To(MatchFields(IgnoreExtras, Fields{
+				"Kind": Equal("org"),
+				"Name": Equal("some-app"),
+				"ID":   Equal("some-org-guid"),
+				"Age":  BeNumerically("~", time.Hour, time.Minute),
+			}))
+			Expect(resources[1]).To(MatchFields(IgnoreExtras, Fields{
+				"Kind": Equal("security-group"),
+				"Name": Equal("some-app"),
+				"ID":   Equal("some-security-group-guid"),
+			}))
+			Expect(resources[2]).To(MatchFields(IgnoreExtras, Fields{
+				"Kind": Equal("service"),
+				"Name": Equal("some-app-some-service"),
+				"ID":   Equal("some-service-guid"),
+			}))
+			Expect(resources[3]).To(MatchFields(IgnoreExtras, Fields{
+				"Kind": Equal("file"),
+				"Name": Equal(home),
+			}))
+
+			Expect(executions).To(HaveLen(3))
+			for _, execution := range executions {
+				Expect(execution.Args[0]).To(Equal("curl"))
+				Expect(execution.Env).To(ContainElement(fmt.Sprintf("CF_HOME=%s", home)))
+			}
+			Expect(home).To(BeADirectory())
+		})
+
+		context("failure cases", func() {
+			context("when the resources cannot be listed", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						fmt.Fprint(execution.Stdout, "some-output")
+						return errors.New("could not curl")
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := teardown.List(home, "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to curl /v3/organizations: could not curl")))
+					Expect(err).To(MatchError(ContainSubstring("some-output")))
+				})
+			})
+		})
+	})
+
+	context("Names", func() {
+		var (
+			teardown cloudfoundry.Teardown
+
+			executable *fakes.Executable
+		)
+
+		it.Before(func() {
+			executable = &fakes.Executable{}
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				return json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{
+					"resources": []map[string]interface{}{
+						{"name": "nodejs_some-app"},
+						{"name": "ruby_some-app"},
+						{"name": "nodejs_other-app"},
+					},
+				})
+			}
+
+			teardown = cloudfoundry.NewTeardown(executable)
+		})
+
+		it("returns the deployments whose names match the prefix", func() {
+			names, err := teardown.Names(gocontext.Background(), "nodejs_")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"nodejs_other-app", "nodejs_some-app"}))
+
+			Expect(executable.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"curl", "/v3/organizations"}))
+		})
+
+		context("failure cases", func() {
+			context("when the orgs cannot be listed", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						fmt.Fprint(execution.Stdout, "some-output")
+						return errors.New("could not curl")
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := teardown.Names(gocontext.Background(), "nodejs_")
+					Expect(err).To(MatchError(ContainSubstring("failed to curl /v3/organizations: could not curl")))
+				})
+			})
+		})
+	})
+
+	context("Archive", func() {
+		var (
+			teardown cloudfoundry.Teardown
+
+			executable *fakes.Executable
+			executions []pexec.Execution
+			artifacts  string
+		)
+
+		it.Before(func() {
+			executable = &fakes.Executable{}
+			executions = nil
+			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				executions = append(executions, execution)
+				switch execution.Args[0] {
+				case "logs":
+					fmt.Fprintln(execution.Stdout, "some-recent-logs")
+				case "events":
+					fmt.Fprintln(execution.Stdout, "some-app-events")
+				}
+				return nil
+			}
+
+			var err error
+			artifacts, err = os.MkdirTemp("", "artifacts")
+			Expect(err).NotTo(HaveOccurred())
+
+			teardown = cloudfoundry.NewTeardown(executable)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(artifacts)).To(Succeed())
+		})
+
+		it("exports the recent app logs and events", func() {
+			err := teardown.Archive("some-home", "some-app", filepath.Join(artifacts, "some-app"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(executions).To(HaveLen(2))
+			Expect(executions[0].Args).To(Equal([]string{"logs", "some-app", "--recent"}))
+			Expect(executions[0].Env).To(ContainElement("CF_HOME=some-home"))
+			Expect(executions[1].Args).To(Equal([]string{"events", "some-app"}))
+			Expect(executions[1].Env).To(ContainElement("CF_HOME=some-home"))
+
+			content, err := os.ReadFile(filepath.Join(artifacts, "some-app", "output.log"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("some-recent-logs\n"))
+
+			content, err = os.ReadFile(filepath.Join(artifacts, "some-app", "events.log"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("some-app-events\n"))
+		})
+
+		context("failure cases", func() {
+			context("when the logs cannot be fetched", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						fmt.Fprint(execution.Stderr, "some-output")
+						return errors.New("exit status 1")
+					}
+				})
+
+				it("returns an error", func() {
+					err := teardown.Archive("some-home", "some-app", artifacts)
+					Expect(err).To(MatchError(ContainSubstring("failed to fetch recent logs: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("some-output")))
+				})
+			})
+
+			context("when the events cannot be fetched", func() {
 				it.Before(func() {
 					executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
-						command := strings.Join(execution.Args, " ")
-						switch {
-						case strings.HasPrefix(command, "delete-service"):
-							fmt.Fprintf(execution.Stdout, "Could not delete service")
+						if execution.Args[0] == "events" {
+							fmt.Fprint(execution.Stderr, "some-output")
 							return errors.New("exit status 1")
-						case strings.HasPrefix(command, "curl /v3/service_instances"):
-							err := json.NewEncoder(execution.Stdout).Encode(map[string]interface{}{
-								"resources": []map[string]interface{}{
-									{"name": "other-app-some-service"},
-									{"name": "some-app-some-service"},
-									{"name": "other-app-other-service"},
-									{"name": "some-app-other-service"},
-								},
-							})
-							if err != nil {
-								return err
-							}
 						}
 
 						return nil
@@ -191,9 +734,9 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					err := teardown.Run(filepath.Join(workspace, "some-home"), "some-app")
-					Expect(err).To(MatchError(ContainSubstring("failed to delete-service: exit status 1")))
-					Expect(err).To(MatchError(ContainSubstring("Could not delete service")))
+					err := teardown.Archive("some-home", "some-app", artifacts)
+					Expect(err).To(MatchError(ContainSubstring("failed to fetch app events: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("some-output")))
 				})
 			})
 		})
@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+//go:generate faux --interface ArchitectureClient --output fakes/architecture_client.go
+type ArchitectureClient interface {
+	Info(ctx context.Context) (types.Info, error)
+	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error)
+	ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error
+	ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+}
+
+type ArchitectureValidator struct {
+	client ArchitectureClient
+}
+
+func NewArchitectureValidator(client ArchitectureClient) ArchitectureValidator {
+	return ArchitectureValidator{client: client}
+}
+
+func (v ArchitectureValidator) Validate(ctx context.Context, image string) error {
+	inspect, _, err := v.client.ImageInspectWithRaw(ctx, image)
+	if errdefs.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect stack image: %w", err)
+	}
+
+	info, err := v.client.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to inspect docker daemon: %w", err)
+	}
+
+	stack, daemon := normalizeArchitecture(inspect.Architecture), normalizeArchitecture(info.Architecture)
+	if stack == "" || daemon == "" || stack == daemon {
+		return nil
+	}
+
+	emulated, err := v.emulated(ctx, image)
+	if err != nil {
+		return err
+	}
+
+	if !emulated {
+		return fmt.Errorf("failed to validate stack architecture: stack is %s but daemon is %s without emulation, install binfmt support (for example with tonistiigi/binfmt) or use a %s stack image", stack, daemon, daemon)
+	}
+
+	return nil
+}
+
+func (v ArchitectureValidator) emulated(ctx context.Context, image string) (bool, error) {
+	resp, err := v.client.ContainerCreate(ctx, &container.Config{Image: image, Entrypoint: []string{"true"}}, nil, nil, nil, "")
+	if err != nil {
+		return false, fmt.Errorf("failed to create architecture probe container: %w", err)
+	}
+	defer v.client.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+
+	err = v.client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
+	if err != nil {
+		if strings.Contains(err.Error(), "exec format error") {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to start architecture probe container: %w", err)
+	}
+
+	onExit, onErr := v.client.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-onErr:
+		if err != nil {
+			return false, fmt.Errorf("failed to wait on architecture probe container: %w", err)
+		}
+	case status := <-onExit:
+		return status.StatusCode == 0, nil
+	}
+
+	return false, nil
+}
+
+func normalizeArchitecture(architecture string) string {
+	switch architecture {
+	case "x86_64", "amd64":
+		return "amd64"
+	case "aarch64", "arm64":
+		return "arm64"
+	case "armv7l", "arm":
+		return "arm"
+	default:
+		return architecture
+	}
+}
@@ -0,0 +1,157 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testArchitectureValidator(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		validator docker.ArchitectureValidator
+		client    *fakes.ArchitectureClient
+	)
+
+	it.Before(func() {
+		client = &fakes.ArchitectureClient{}
+		client.ImageInspectWithRawCall.Returns.ImageInspect = types.ImageInspect{Architecture: "amd64"}
+		client.InfoCall.Returns.Info = types.Info{Architecture: "x86_64"}
+		client.ContainerCreateCall.Returns.CreateResponse = container.CreateResponse{ID: "some-probe-id"}
+
+		validator = docker.NewArchitectureValidator(client)
+	})
+
+	context("Validate", func() {
+		it("accepts a stack matching the daemon architecture", func() {
+			err := validator.Validate(gocontext.Background(), "some-stack-image")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ImageInspectWithRawCall.Receives.ImageID).To(Equal("some-stack-image"))
+			Expect(client.ContainerCreateCall.CallCount).To(Equal(0))
+		})
+
+		context("when the architectures differ", func() {
+			var exits chan container.WaitResponse
+
+			it.Before(func() {
+				client.InfoCall.Returns.Info = types.Info{Architecture: "aarch64"}
+
+				exits = make(chan container.WaitResponse, 1)
+				client.ContainerWaitCall.Returns.WaitResponseChannel = exits
+			})
+
+			context("when the daemon can emulate the stack architecture", func() {
+				it.Before(func() {
+					exits <- container.WaitResponse{StatusCode: 0}
+				})
+
+				it("accepts the stack", func() {
+					err := validator.Validate(gocontext.Background(), "some-stack-image")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(client.ContainerCreateCall.Receives.Config).To(Equal(&container.Config{
+						Image:      "some-stack-image",
+						Entrypoint: []string{"true"},
+					}))
+					Expect(client.ContainerStartCall.Receives.ContainerID).To(Equal("some-probe-id"))
+					Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-probe-id"))
+					Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{Force: true}))
+				})
+			})
+
+			context("when the probe cannot execute", func() {
+				it.Before(func() {
+					exits <- container.WaitResponse{StatusCode: 1}
+				})
+
+				it("returns an error", func() {
+					err := validator.Validate(gocontext.Background(), "some-stack-image")
+					Expect(err).To(MatchError("failed to validate stack architecture: stack is amd64 but daemon is arm64 without emulation, install binfmt support (for example with tonistiigi/binfmt) or use a arm64 stack image"))
+					Expect(client.ContainerRemoveCall.CallCount).To(Equal(1))
+				})
+			})
+
+			context("when the probe fails to start with an exec format error", func() {
+				it.Before(func() {
+					client.ContainerStartCall.Returns.Error = errors.New("exec /usr/bin/true: exec format error")
+				})
+
+				it("returns an error", func() {
+					err := validator.Validate(gocontext.Background(), "some-stack-image")
+					Expect(err).To(MatchError(ContainSubstring("stack is amd64 but daemon is arm64 without emulation")))
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the probe container cannot be created", func() {
+					it.Before(func() {
+						client.ContainerCreateCall.Returns.Error = errors.New("failed to create")
+					})
+
+					it("returns an error", func() {
+						err := validator.Validate(gocontext.Background(), "some-stack-image")
+						Expect(err).To(MatchError("failed to create architecture probe container: failed to create"))
+					})
+				})
+
+				context("when the probe container cannot be started", func() {
+					it.Before(func() {
+						client.ContainerStartCall.Returns.Error = errors.New("failed to start")
+					})
+
+					it("returns an error", func() {
+						err := validator.Validate(gocontext.Background(), "some-stack-image")
+						Expect(err).To(MatchError("failed to start architecture probe container: failed to start"))
+					})
+				})
+			})
+		})
+
+		context("when the stack image has not been pulled", func() {
+			it.Before(func() {
+				client.ImageInspectWithRawCall.Returns.Error = errdefs.NotFound(errors.New("no such image"))
+			})
+
+			it("skips the validation", func() {
+				err := validator.Validate(gocontext.Background(), "some-stack-image")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.InfoCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the stack image cannot be inspected", func() {
+				it.Before(func() {
+					client.ImageInspectWithRawCall.Returns.Error = errors.New("failed to inspect")
+				})
+
+				it("returns an error", func() {
+					err := validator.Validate(gocontext.Background(), "some-stack-image")
+					Expect(err).To(MatchError("failed to inspect stack image: failed to inspect"))
+				})
+			})
+
+			context("when the daemon cannot be inspected", func() {
+				it.Before(func() {
+					client.InfoCall.Returns.Error = errors.New("daemon unavailable")
+				})
+
+				it("returns an error", func() {
+					err := validator.Validate(gocontext.Background(), "some-stack-image")
+					Expect(err).To(MatchError("failed to inspect docker daemon: daemon unavailable"))
+				})
+			})
+		})
+	})
+}
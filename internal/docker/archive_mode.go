@@ -0,0 +1,9 @@
+//go:build !windows
+
+package docker
+
+import "io/fs"
+
+func archiveMode(info fs.FileInfo, mode int64) int64 {
+	return mode
+}
@@ -0,0 +1,11 @@
+package docker
+
+import "io/fs"
+
+func archiveMode(info fs.FileInfo, mode int64) int64 {
+	if info.Mode().IsRegular() || info.IsDir() {
+		return mode&^0777 | 0755
+	}
+
+	return mode
+}
@@ -1,6 +1,8 @@
 package docker
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"io"
@@ -9,21 +11,33 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
 )
 
 type BuildpacksCache struct {
 	workspace string
 	index     *sync.Map
+	aws       Executable
+	gcloud    Executable
 }
 
 func NewBuildpacksCache(workspace string) BuildpacksCache {
 	return BuildpacksCache{
 		workspace: workspace,
 		index:     &sync.Map{},
+		aws:       pexec.NewExecutable("aws"),
+		gcloud:    pexec.NewExecutable("gcloud"),
 	}
 }
 
-func (c BuildpacksCache) Fetch(uri string) (io.ReadCloser, error) {
+func (c BuildpacksCache) WithObjectStorage(aws, gcloud Executable) BuildpacksCache {
+	c.aws = aws
+	c.gcloud = gcloud
+	return c
+}
+
+func (c BuildpacksCache) Fetch(ctx context.Context, uri string) (io.ReadCloser, error) {
 	err := os.MkdirAll(c.workspace, os.ModePerm)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create workspace: %w", err)
@@ -61,7 +75,19 @@ func (c BuildpacksCache) Fetch(uri string) (io.ReadCloser, error) {
 		return file, nil
 	}
 
-	resp, err := http.Get(uri)
+	switch u.Scheme {
+	case "s3":
+		return c.copyObject(c.aws, []string{"s3", "cp", "--only-show-errors", uri, path}, path)
+	case "gs":
+		return c.copyObject(c.gcloud, []string{"storage", "cp", uri, path}, path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download buildpack: %w", err)
 	}
@@ -84,3 +110,23 @@ func (c BuildpacksCache) Fetch(uri string) (io.ReadCloser, error) {
 
 	return file, nil
 }
+
+func (c BuildpacksCache) copyObject(cli Executable, args []string, path string) (io.ReadCloser, error) {
+	logs := bytes.NewBuffer(nil)
+	err := cli.Execute(pexec.Execution{
+		Args:   args,
+		Stdout: logs,
+		Stderr: logs,
+	})
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to download buildpack: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open buildpack: %w", err)
+	}
+
+	return file, nil
+}
@@ -1,7 +1,9 @@
 package docker_test
 
 import (
+	gocontext "context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +13,8 @@ import (
 	"testing"
 
 	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
 	"github.com/sclevine/spec"
 
 	. "github.com/onsi/gomega"
@@ -46,7 +50,7 @@ func testBuildpacksCache(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		it("downloads the buildpack into the cache", func() {
-			buildpack, err := cache.Fetch(server.URL)
+			buildpack, err := cache.Fetch(gocontext.Background(), server.URL)
 			Expect(err).NotTo(HaveOccurred())
 
 			content, err := io.ReadAll(buildpack)
@@ -72,7 +76,7 @@ func testBuildpacksCache(t *testing.T, context spec.G, it spec.S) {
 			})
 
 			it("reuses the cached buildpack", func() {
-				buildpack, err := cache.Fetch(server.URL)
+				buildpack, err := cache.Fetch(gocontext.Background(), server.URL)
 				Expect(err).NotTo(HaveOccurred())
 
 				content, err := io.ReadAll(buildpack)
@@ -89,7 +93,7 @@ func testBuildpacksCache(t *testing.T, context spec.G, it spec.S) {
 					})
 
 					it("returns an error", func() {
-						_, err := cache.Fetch(server.URL)
+						_, err := cache.Fetch(gocontext.Background(), server.URL)
 						Expect(err).To(MatchError(ContainSubstring("failed to open buildpack:")))
 						Expect(err).To(MatchError(ContainSubstring("permission denied")))
 					})
@@ -97,6 +101,79 @@ func testBuildpacksCache(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the url is an object storage url", func() {
+			var (
+				aws    *fakes.Executable
+				gcloud *fakes.Executable
+			)
+
+			it.Before(func() {
+				copyObject := func(execution pexec.Execution) error {
+					return os.WriteFile(execution.Args[len(execution.Args)-1], []byte("object-content"), 0600)
+				}
+
+				aws = &fakes.Executable{}
+				aws.ExecuteCall.Stub = copyObject
+
+				gcloud = &fakes.Executable{}
+				gcloud.ExecuteCall.Stub = copyObject
+
+				cache = cache.WithObjectStorage(aws, gcloud)
+			})
+
+			it("downloads s3 objects with the aws cli", func() {
+				buildpack, err := cache.Fetch(gocontext.Background(), "s3://some-bucket/some-buildpack.zip")
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := io.ReadAll(buildpack)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("object-content"))
+
+				Expect(buildpack.Close()).To(Succeed())
+
+				path := filepath.Join(workspace, "some-cache", fmt.Sprintf("%x", sha256.Sum256([]byte("s3://some-bucket/some-buildpack.zip"))))
+				Expect(aws.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"s3", "cp", "--only-show-errors", "s3://some-bucket/some-buildpack.zip", path}))
+				Expect(gcloud.ExecuteCall.CallCount).To(Equal(0))
+
+				buildpack, err = cache.Fetch(gocontext.Background(), "s3://some-bucket/some-buildpack.zip")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buildpack.Close()).To(Succeed())
+
+				Expect(aws.ExecuteCall.CallCount).To(Equal(1))
+			})
+
+			it("downloads gs objects with the gcloud cli", func() {
+				buildpack, err := cache.Fetch(gocontext.Background(), "gs://some-bucket/some-buildpack.zip")
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := io.ReadAll(buildpack)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("object-content"))
+
+				Expect(buildpack.Close()).To(Succeed())
+
+				path := filepath.Join(workspace, "some-cache", fmt.Sprintf("%x", sha256.Sum256([]byte("gs://some-bucket/some-buildpack.zip"))))
+				Expect(gcloud.ExecuteCall.Receives.Execution.Args).To(Equal([]string{"storage", "cp", "gs://some-bucket/some-buildpack.zip", path}))
+				Expect(aws.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			context("failure cases", func() {
+				context("when the object cannot be copied", func() {
+					it.Before(func() {
+						aws.ExecuteCall.Stub = func(execution pexec.Execution) error {
+							fmt.Fprintln(execution.Stderr, "access denied")
+							return errors.New("exit status 1")
+						}
+					})
+
+					it("returns an error", func() {
+						_, err := cache.Fetch(gocontext.Background(), "s3://some-bucket/some-buildpack.zip")
+						Expect(err).To(MatchError("failed to download buildpack: exit status 1\n\nOutput:\naccess denied\n"))
+					})
+				})
+			})
+		})
+
 		context("when the url is a filepath", func() {
 			it.Before(func() {
 				err := os.WriteFile(filepath.Join(workspace, "some-buildpack"), []byte("file-content"), 0600)
@@ -104,7 +181,7 @@ func testBuildpacksCache(t *testing.T, context spec.G, it spec.S) {
 			})
 
 			it("returns the filepath", func() {
-				buildpack, err := cache.Fetch(filepath.Join(workspace, "some-buildpack"))
+				buildpack, err := cache.Fetch(gocontext.Background(), filepath.Join(workspace, "some-buildpack"))
 				Expect(err).NotTo(HaveOccurred())
 
 				content, err := io.ReadAll(buildpack)
@@ -121,7 +198,7 @@ func testBuildpacksCache(t *testing.T, context spec.G, it spec.S) {
 					})
 
 					it("returns an error", func() {
-						_, err := cache.Fetch(filepath.Join(workspace, "some-buildpack"))
+						_, err := cache.Fetch(gocontext.Background(), filepath.Join(workspace, "some-buildpack"))
 						Expect(err).To(MatchError(ContainSubstring("failed to open buildpack:")))
 						Expect(err).To(MatchError(ContainSubstring("permission denied")))
 					})
@@ -136,7 +213,7 @@ func testBuildpacksCache(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := cache.Fetch(server.URL)
+					_, err := cache.Fetch(gocontext.Background(), server.URL)
 					Expect(err).To(MatchError(ContainSubstring("failed to create workspace:")))
 					Expect(err).To(MatchError(ContainSubstring("permission denied")))
 				})
@@ -144,7 +221,7 @@ func testBuildpacksCache(t *testing.T, context spec.G, it spec.S) {
 
 			context("when the uri is malformed", func() {
 				it("returns an error", func() {
-					_, err := cache.Fetch("%%%")
+					_, err := cache.Fetch(gocontext.Background(), "%%%")
 					Expect(err).To(MatchError(ContainSubstring("failed to parse uri:")))
 					Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
 				})
@@ -152,19 +229,30 @@ func testBuildpacksCache(t *testing.T, context spec.G, it spec.S) {
 
 			context("when the request fails", func() {
 				it("returns an error", func() {
-					_, err := cache.Fetch("http://localhost:0")
+					_, err := cache.Fetch(gocontext.Background(), "http://localhost:0")
 					Expect(err).To(MatchError(ContainSubstring("failed to download buildpack:")))
 					Expect(err).To(MatchError(ContainSubstring("dial tcp")))
 				})
 			})
 
+			context("when the context is done", func() {
+				it("returns an error", func() {
+					ctx, cancel := gocontext.WithCancel(gocontext.Background())
+					cancel()
+
+					_, err := cache.Fetch(ctx, server.URL)
+					Expect(err).To(MatchError(ContainSubstring("failed to download buildpack:")))
+					Expect(err).To(MatchError(gocontext.Canceled))
+				})
+			})
+
 			context("when the buildpack file cannot be created", func() {
 				it.Before(func() {
 					Expect(os.MkdirAll(filepath.Join(workspace, "some-cache"), 0000)).To(Succeed())
 				})
 
 				it("returns an error", func() {
-					_, err := cache.Fetch(server.URL)
+					_, err := cache.Fetch(gocontext.Background(), server.URL)
 					Expect(err).To(MatchError(ContainSubstring("failed to create buildpack file:")))
 					Expect(err).To(MatchError(ContainSubstring("permission denied")))
 				})
@@ -1,7 +1,10 @@
 package docker
 
 import (
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -14,12 +17,13 @@ import (
 
 //go:generate faux --interface BPCache --output fakes/bp_cache.go
 type BPCache interface {
-	Fetch(url string) (io.ReadCloser, error)
+	Fetch(ctx context.Context, url string) (io.ReadCloser, error)
 }
 
 //go:generate faux --interface BPRegistry --output fakes/bp_registry.go
 type BPRegistry interface {
 	List() ([]Buildpack, error)
+	Resolve(name string) (Buildpack, error)
 	Override(...Buildpack)
 }
 
@@ -44,7 +48,7 @@ func NewBuildpacksManager(archiver Archiver, cache BPCache, registry BPRegistry)
 	}
 }
 
-func (m BuildpacksManager) Build(workspace, name string) (string, error) {
+func (m BuildpacksManager) Build(ctx context.Context, workspace, name string) (string, error) {
 	err := os.RemoveAll(filepath.Join(workspace, name))
 	if err != nil {
 		return "", fmt.Errorf("failed to remove existing buildpack directory: %w", err)
@@ -55,6 +59,20 @@ func (m BuildpacksManager) Build(workspace, name string) (string, error) {
 		return "", fmt.Errorf("failed to list buildpacks: %w", err)
 	}
 
+	for _, name := range m.filter {
+		if !strings.Contains(name, "@") || containsBuildpack(buildpacks, name) {
+			continue
+		}
+
+		buildpack, err := m.registry.Resolve(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve buildpack: %w", err)
+		}
+
+		buildpacks = append(buildpacks, buildpack)
+	}
+
+	var lock []BuildpackManifest
 	for _, buildpack := range buildpacks {
 		contains := len(m.filter) == 0
 		for _, name := range m.filter {
@@ -68,7 +86,7 @@ func (m BuildpacksManager) Build(workspace, name string) (string, error) {
 			continue
 		}
 
-		bp, err := m.cache.Fetch(buildpack.URI)
+		bp, err := m.cache.Fetch(ctx, buildpack.URI)
 		if err != nil {
 			return "", fmt.Errorf("failed to fetch buildpack: %w", err)
 		}
@@ -85,24 +103,49 @@ func (m BuildpacksManager) Build(workspace, name string) (string, error) {
 
 		destination := filepath.Join(workspace, name, fmt.Sprintf("%x", md5.Sum([]byte(buildpack.Name))))
 
+		var digest string
 		if isDir {
 			err = fs.Copy(buildpack.URI, destination)
 			if err != nil {
 				return "", fmt.Errorf("failed to copy buildpack: %w", err)
 			}
+
+			digest, err = DirectoryDigest(buildpack.URI)
+			if err != nil {
+				return "", fmt.Errorf("failed to digest buildpack: %w", err)
+			}
 		} else {
-			err = vacation.NewZipArchive(bp).Decompress(destination)
+			hash := sha256.New()
+			err = vacation.NewZipArchive(io.TeeReader(bp, hash)).Decompress(destination)
 			if err != nil {
 				return "", fmt.Errorf("failed to decompress buildpack: %w", err)
 			}
+
+			digest = formatDigest(hash)
 		}
 
+		lock = append(lock, BuildpackManifest{
+			Name:   buildpack.Name,
+			URI:    buildpack.URI,
+			SHA256: digest,
+		})
+
 		err = bp.Close()
 		if err != nil {
 			return "", fmt.Errorf("failed to close buildpack: %w", err)
 		}
 	}
 
+	content, err := json.Marshal(lock)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal buildpacks lock: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(workspace, fmt.Sprintf("%s.json", name)), content, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write buildpacks lock: %w", err)
+	}
+
 	output := filepath.Join(workspace, fmt.Sprintf("%s.tar.gz", name))
 	err = m.archiver.WithPrefix("/tmp/buildpacks").Compress(filepath.Join(workspace, name), output)
 	if err != nil {
@@ -130,6 +173,16 @@ func (m BuildpacksManager) Order() (string, bool, error) {
 	return strings.Join(names, ","), len(m.filter) > 0, nil
 }
 
+func containsBuildpack(buildpacks []Buildpack, name string) bool {
+	for _, buildpack := range buildpacks {
+		if buildpack.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (m BuildpacksManager) WithBuildpacks(buildpacks ...string) BuildpacksBuilder {
 	m.filter = buildpacks
 	return m
@@ -3,6 +3,9 @@ package docker_test
 import (
 	"archive/zip"
 	"bytes"
+	gocontext "context"
+	"crypto/md5"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -46,7 +49,7 @@ func testBuildpacksManager(t *testing.T, context spec.G, it spec.S) {
 		archiver.WithPrefixCall.Returns.Archiver = archiver
 
 		cache = &fakes.BPCache{}
-		cache.FetchCall.Stub = func(url string) (io.ReadCloser, error) {
+		cache.FetchCall.Stub = func(ctx gocontext.Context, url string) (io.ReadCloser, error) {
 			cacheFetchInvocations = append(cacheFetchInvocations, cacheFetchInvocation{URL: url})
 
 			if url == filepath.Join(workspace, "some-buildpack") {
@@ -105,7 +108,7 @@ func testBuildpacksManager(t *testing.T, context spec.G, it spec.S) {
 
 	context("Build", func() {
 		it("bundles the buildpacks into a tarball", func() {
-			buildpacks, err := manager.Build(workspace, "some-app")
+			buildpacks, err := manager.Build(gocontext.Background(), workspace, "some-app")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(buildpacks).To(Equal(filepath.Join(workspace, "some-app.tar.gz")))
 
@@ -153,9 +156,34 @@ func testBuildpacksManager(t *testing.T, context spec.G, it spec.S) {
 			Expect(string(content)).To(Equal("some-content"))
 		})
 
+		it("records the source and digest of each buildpack in a lock file", func() {
+			_, err := manager.Build(gocontext.Background(), workspace, "some-app")
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := os.ReadFile(filepath.Join(workspace, "some-app.json"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var lock []docker.BuildpackManifest
+			Expect(json.Unmarshal(content, &lock)).To(Succeed())
+			Expect(lock).To(HaveLen(4))
+
+			Expect(lock[0].Name).To(Equal("ruby-buildpack"))
+			Expect(lock[0].URI).To(Equal("some-ruby-uri"))
+			Expect(lock[0].SHA256).To(MatchRegexp(`^sha256:[0-9a-f]{64}$`))
+			Expect(lock[1].SHA256).NotTo(Equal(lock[0].SHA256))
+
+			digest, err := docker.DirectoryDigest(filepath.Join(workspace, "some-buildpack"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock[2]).To(Equal(docker.BuildpackManifest{
+				Name:   "directory-buildpack",
+				URI:    filepath.Join(workspace, "some-buildpack"),
+				SHA256: digest,
+			}))
+		})
+
 		context("WithBuildpacks", func() {
 			it("only builds the named buildpacks", func() {
-				_, err := manager.WithBuildpacks("ruby-buildpack", "nodejs-buildpack").Build(workspace, "some-app")
+				_, err := manager.WithBuildpacks("ruby-buildpack", "nodejs-buildpack").Build(gocontext.Background(), workspace, "some-app")
 				Expect(err).NotTo(HaveOccurred())
 
 				directories, err := filepath.Glob(filepath.Join(workspace, "some-app", "*"))
@@ -173,6 +201,41 @@ func testBuildpacksManager(t *testing.T, context spec.G, it spec.S) {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(string(content)).To(Equal("some-nodejs-content"))
 			})
+
+			context("when a buildpack is pinned to a version", func() {
+				it.Before(func() {
+					registry.ResolveCall.Returns.Buildpack = docker.Buildpack{
+						Name: "nodejs-buildpack@v4.500.0",
+						URI:  "some-pinned-uri",
+					}
+				})
+
+				it("resolves and builds the pinned release", func() {
+					_, err := manager.WithBuildpacks("ruby-buildpack", "nodejs-buildpack@v4.500.0").Build(gocontext.Background(), workspace, "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(registry.ResolveCall.Receives.Name).To(Equal("nodejs-buildpack@v4.500.0"))
+
+					directories, err := filepath.Glob(filepath.Join(workspace, "some-app", "*"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(directories).To(HaveLen(2))
+
+					content, err := os.ReadFile(filepath.Join(workspace, "some-app", fmt.Sprintf("%x", md5.Sum([]byte("nodejs-buildpack@v4.500.0"))), "some-pinned-file"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("some-pinned-content"))
+				})
+
+				context("when the pinned release cannot be resolved", func() {
+					it.Before(func() {
+						registry.ResolveCall.Returns.Error = errors.New("could not resolve")
+					})
+
+					it("returns an error", func() {
+						_, err := manager.WithBuildpacks("nodejs-buildpack@v4.500.0").Build(gocontext.Background(), workspace, "some-app")
+						Expect(err).To(MatchError("failed to resolve buildpack: could not resolve"))
+					})
+				})
+			})
 		})
 
 		context("failure cases", func() {
@@ -182,7 +245,7 @@ func testBuildpacksManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(workspace, "some-app")
+					_, err := manager.Build(gocontext.Background(), workspace, "some-app")
 					Expect(err).To(MatchError("failed to list buildpacks: could not list buildpacks"))
 				})
 			})
@@ -194,7 +257,7 @@ func testBuildpacksManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(workspace, "some-app")
+					_, err := manager.Build(gocontext.Background(), workspace, "some-app")
 					Expect(err).To(MatchError("failed to fetch buildpack: could not fetch buildpack"))
 				})
 			})
@@ -205,7 +268,7 @@ func testBuildpacksManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(workspace, "some-app")
+					_, err := manager.Build(gocontext.Background(), workspace, "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to copy buildpack:")))
 					Expect(err).To(MatchError(ContainSubstring("permission denied")))
 				})
@@ -213,13 +276,13 @@ func testBuildpacksManager(t *testing.T, context spec.G, it spec.S) {
 
 			context("when the buildpack cannot be decompressed", func() {
 				it.Before(func() {
-					cache.FetchCall.Stub = func(url string) (io.ReadCloser, error) {
+					cache.FetchCall.Stub = func(ctx gocontext.Context, url string) (io.ReadCloser, error) {
 						return io.NopCloser(bytes.NewBuffer([]byte("this is not a zip file"))), nil
 					}
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(workspace, "some-app")
+					_, err := manager.Build(gocontext.Background(), workspace, "some-app")
 					Expect(err).To(MatchError(ContainSubstring("failed to decompress buildpack:")))
 					Expect(err).To(MatchError(ContainSubstring("not a valid zip file")))
 				})
@@ -231,7 +294,7 @@ func testBuildpacksManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(workspace, "some-app")
+					_, err := manager.Build(gocontext.Background(), workspace, "some-app")
 					Expect(err).To(MatchError("failed to archive buildpacks: could not compress buildpacks"))
 				})
 			})
@@ -24,16 +24,18 @@ var DefaultBuildpacks = []string{
 }
 
 type BuildpacksRegistry struct {
-	api   string
-	token string
-	index *sync.Map
+	api    string
+	token  string
+	index  *sync.Map
+	pinned *sync.Map
 }
 
 func NewBuildpacksRegistry(api, token string) BuildpacksRegistry {
 	return BuildpacksRegistry{
-		api:   api,
-		token: token,
-		index: &sync.Map{},
+		api:    api,
+		token:  token,
+		index:  &sync.Map{},
+		pinned: &sync.Map{},
 	}
 }
 
@@ -47,41 +49,12 @@ func (r BuildpacksRegistry) List() ([]Buildpack, error) {
 		if ok {
 			buildpack.URI = value.(string)
 		} else {
-			req, err := http.NewRequest("GET", fmt.Sprintf("%s/repos/cloudfoundry/%s/releases/latest", r.api, name), nil)
+			uri, err := r.release(fmt.Sprintf("%s/repos/cloudfoundry/%s/releases/latest", r.api, name))
 			if err != nil {
-				return nil, fmt.Errorf("failed to create request: %w", err)
-			}
-
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.token))
-
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
-				return nil, fmt.Errorf("failed to complete request: %w", err)
-			}
-
-			if resp.StatusCode != http.StatusOK {
-				dump, _ := httputil.DumpResponse(resp, true)
-				return nil, fmt.Errorf("received unexpected response status: %s", dump)
-			}
-
-			var release struct {
-				Assets []struct {
-					Name               string `json:"name"`
-					BrowserDownloadURL string `json:"browser_download_url"`
-				} `json:"assets"`
-			}
-			err = json.NewDecoder(resp.Body).Decode(&release)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse response json: %w", err)
-			}
-
-			for _, asset := range release.Assets {
-				if strings.HasSuffix(asset.Name, ".zip") {
-					buildpack.URI = asset.BrowserDownloadURL
-					break
-				}
+				return nil, err
 			}
 
+			buildpack.URI = uri
 			r.index.Store(buildpack.Name, buildpack.URI)
 		}
 
@@ -107,6 +80,70 @@ func (r BuildpacksRegistry) List() ([]Buildpack, error) {
 	return list, nil
 }
 
+func (r BuildpacksRegistry) Resolve(name string) (Buildpack, error) {
+	value, ok := r.pinned.Load(name)
+	if ok {
+		return Buildpack{Name: name, URI: value.(string)}, nil
+	}
+
+	repo, version, ok := strings.Cut(name, "@")
+	if !ok || repo == "" || version == "" {
+		return Buildpack{}, fmt.Errorf("invalid buildpack version reference %q, expected <name>@<version>", name)
+	}
+
+	if !strings.HasPrefix(version, "v") {
+		version = fmt.Sprintf("v%s", version)
+	}
+
+	uri, err := r.release(fmt.Sprintf("%s/repos/cloudfoundry/%s/releases/tags/%s", r.api, strings.ReplaceAll(repo, "_", "-"), version))
+	if err != nil {
+		return Buildpack{}, err
+	}
+
+	r.pinned.Store(name, uri)
+
+	return Buildpack{Name: name, URI: uri}, nil
+}
+
+func (r BuildpacksRegistry) release(url string) (string, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.token))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to complete request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		dump, _ := httputil.DumpResponse(resp, true)
+		return "", fmt.Errorf("received unexpected response status: %s", dump)
+	}
+
+	var release struct {
+		Assets []struct {
+			Name               string `json:"name"`
+			BrowserDownloadURL string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&release)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse response json: %w", err)
+	}
+
+	for _, asset := range release.Assets {
+		if strings.HasSuffix(asset.Name, ".zip") {
+			return asset.BrowserDownloadURL, nil
+		}
+	}
+
+	return "", nil
+}
+
 func (r BuildpacksRegistry) Override(buildpacks ...Buildpack) {
 	for _, buildpack := range buildpacks {
 		r.index.Store(buildpack.Name, buildpack.URI)
@@ -178,6 +178,71 @@ func testBuildpacksRegistry(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("Resolve", func() {
+		var requests []string
+
+		it.Before(func() {
+			requests = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				requests = append(requests, req.URL.Path)
+
+				if req.URL.Path != "/repos/cloudfoundry/nodejs-buildpack/releases/tags/v4.500.0" {
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, "release not found")
+					return
+				}
+
+				fmt.Fprint(w, `{"assets": [{"name": "nodejs-buildpack-v4.500.0.zip", "browser_download_url": "some-pinned-nodejs-uri"}]}`)
+			}))
+
+			registry = docker.NewBuildpacksRegistry(server.URL, "some-token")
+		})
+
+		it("resolves a pinned version to its release asset", func() {
+			buildpack, err := registry.Resolve("nodejs_buildpack@v4.500.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buildpack).To(Equal(docker.Buildpack{
+				Name: "nodejs_buildpack@v4.500.0",
+				URI:  "some-pinned-nodejs-uri",
+			}))
+		})
+
+		it("caches the resolved release", func() {
+			_, err := registry.Resolve("nodejs_buildpack@v4.500.0")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = registry.Resolve("nodejs_buildpack@v4.500.0")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requests).To(HaveLen(1))
+		})
+
+		context("when the version has no v prefix", func() {
+			it("adds it", func() {
+				buildpack, err := registry.Resolve("nodejs_buildpack@4.500.0")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buildpack.URI).To(Equal("some-pinned-nodejs-uri"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the reference has no version", func() {
+				it("returns an error", func() {
+					_, err := registry.Resolve("nodejs_buildpack@")
+					Expect(err).To(MatchError(`invalid buildpack version reference "nodejs_buildpack@", expected <name>@<version>`))
+				})
+			})
+
+			context("when the release does not exist", func() {
+				it("returns an error", func() {
+					_, err := registry.Resolve("nodejs_buildpack@v0.0.0")
+					Expect(err).To(MatchError(ContainSubstring("received unexpected response status:")))
+					Expect(err).To(MatchError(ContainSubstring("release not found")))
+				})
+			})
+		})
+	})
+
 	context("Override", func() {
 		it("overrides the given buildpack", func() {
 			registry.Override(docker.Buildpack{Name: "python_buildpack", URI: "override-python-uri"})
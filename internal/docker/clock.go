@@ -0,0 +1,19 @@
+package docker
+
+import "time"
+
+//go:generate faux --interface Clock --output fakes/clock.go
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
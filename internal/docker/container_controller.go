@@ -0,0 +1,40 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+//go:generate faux --interface ContainerControllerClient --output fakes/container_controller_client.go
+type ContainerControllerClient interface {
+	ContainerKill(ctx context.Context, containerID, signal string) error
+	ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) error
+}
+
+type ContainerController struct {
+	client ContainerControllerClient
+}
+
+func NewContainerController(client ContainerControllerClient) ContainerController {
+	return ContainerController{client: client}
+}
+
+func (c ContainerController) Kill(ctx context.Context, name, signal string) error {
+	err := c.client.ContainerKill(ctx, name, signal)
+	if err != nil {
+		return fmt.Errorf("failed to kill container: %w", err)
+	}
+
+	return nil
+}
+
+func (c ContainerController) Restart(ctx context.Context, name string) error {
+	err := c.client.ContainerRestart(ctx, name, container.StopOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to restart container: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types/container"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testContainerController(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		controller docker.ContainerController
+		client     *fakes.ContainerControllerClient
+	)
+
+	it.Before(func() {
+		client = &fakes.ContainerControllerClient{}
+		controller = docker.NewContainerController(client)
+	})
+
+	context("Kill", func() {
+		it("sends the signal to the application container", func() {
+			err := controller.Kill(gocontext.Background(), "some-app", "SIGTERM")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ContainerKillCall.Receives.ContainerID).To(Equal("some-app"))
+			Expect(client.ContainerKillCall.Receives.Signal).To(Equal("SIGTERM"))
+		})
+
+		context("failure cases", func() {
+			context("when the container cannot be killed", func() {
+				it.Before(func() {
+					client.ContainerKillCall.Returns.Error = errors.New("no such container")
+				})
+
+				it("returns an error", func() {
+					err := controller.Kill(gocontext.Background(), "some-app", "SIGTERM")
+					Expect(err).To(MatchError("failed to kill container: no such container"))
+				})
+			})
+		})
+	})
+
+	context("Restart", func() {
+		it("restarts the application container", func() {
+			err := controller.Restart(gocontext.Background(), "some-app")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ContainerRestartCall.Receives.ContainerID).To(Equal("some-app"))
+			Expect(client.ContainerRestartCall.Receives.Options).To(Equal(container.StopOptions{}))
+		})
+
+		context("failure cases", func() {
+			context("when the container cannot be restarted", func() {
+				it.Before(func() {
+					client.ContainerRestartCall.Returns.Error = errors.New("no such container")
+				})
+
+				it("returns an error", func() {
+					err := controller.Restart(gocontext.Background(), "some-app")
+					Expect(err).To(MatchError("failed to restart container: no such container"))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,171 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+const CredentialServicePort = 8844
+
+//go:embed credentialservice/main.go
+var credentialServiceSource []byte
+
+//go:generate faux --interface CredentialServiceClient --output fakes/credential_service_client.go
+type CredentialServiceClient interface {
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error)
+	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error
+	ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+}
+
+//go:generate faux --interface CredentialServiceRunner --output fakes/credential_service_runner.go
+type CredentialServiceRunner interface {
+	Run(ctx context.Context, logs io.Writer, name, stack string, credentials map[string]interface{}) (url string, err error)
+}
+
+type CredentialService struct {
+	client   CredentialServiceClient
+	golang   Executable
+	archiver Archiver
+	cache    string
+	runID    string
+	m        *sync.Mutex
+}
+
+func NewCredentialService(client CredentialServiceClient, golang Executable, archiver Archiver, cache string) CredentialService {
+	return CredentialService{
+		client:   client,
+		golang:   golang,
+		archiver: archiver,
+		cache:    cache,
+		m:        &sync.Mutex{},
+	}
+}
+
+func (s CredentialService) WithRunID(runID string) CredentialService {
+	s.runID = runID
+	return s
+}
+
+func (s CredentialService) Run(ctx context.Context, logs io.Writer, name, stack string, credentials map[string]interface{}) (string, error) {
+	tarball, err := s.build()
+	if err != nil {
+		return "", err
+	}
+
+	content, err := json.Marshal(credentials)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal credentials json: %w", err)
+	}
+
+	containerName := fmt.Sprintf("%s-credentials", name)
+	err = s.client.ContainerRemove(ctx, containerName, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+	if err != nil && !errdefs.IsNotFound(err) {
+		return "", fmt.Errorf("failed to remove stale credential service container: %w", err)
+	}
+
+	containerConfig := container.Config{
+		Image: stackImage(stack),
+		Cmd:   []string{"/tmp/credential-service/credential-service"},
+		User:  "vcap",
+		Env: []string{
+			fmt.Sprintf("CREDENTIALS=%s", content),
+			fmt.Sprintf("PORT=%d", CredentialServicePort),
+		},
+		Labels: runLabels(s.runID, map[string]string{AppLabel: name}),
+	}
+
+	hostConfig := container.HostConfig{
+		NetworkMode: container.NetworkMode(internalNetworkName(s.runID)),
+	}
+
+	resp, err := s.client.ContainerCreate(ctx, &containerConfig, &hostConfig, nil, nil, containerName)
+	if err != nil {
+		return "", fmt.Errorf("failed to create credential service container: %w", err)
+	}
+
+	file, err := os.Open(tarball)
+	if err != nil {
+		return "", fmt.Errorf("failed to open credential service: %w", err)
+	}
+	defer file.Close()
+
+	err = s.client.CopyToContainer(ctx, resp.ID, "/", file, types.CopyToContainerOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to copy credential service into container: %w", err)
+	}
+
+	err = s.client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to start credential service container: %w", err)
+	}
+
+	fmt.Fprintf(logs, "Started credential service %s\n", containerName)
+
+	return fmt.Sprintf("http://%s:%d", containerName, CredentialServicePort), nil
+}
+
+func (s CredentialService) build() (string, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	dir := filepath.Join(s.cache, fmt.Sprintf("credential-service-%x-%s-%s", sha256.Sum256(credentialServiceSource), lifecycleGOOS, lifecycleGOARCH))
+	output := filepath.Join(dir, "credential-service.tar.gz")
+
+	_, err := os.Stat(output)
+	if err == nil {
+		return output, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to stat credential service cache: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Join(dir, "src"), os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to create credential service cache: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "src", "main.go"), credentialServiceSource, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write credential service source: %w", err)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	err = s.golang.Execute(pexec.Execution{
+		Args: []string{"build", "-o", filepath.Join(dir, "bin", "credential-service"), "main.go"},
+		Env: append(os.Environ(),
+			fmt.Sprintf("GOOS=%s", lifecycleGOOS),
+			fmt.Sprintf("GOARCH=%s", lifecycleGOARCH),
+			"CGO_ENABLED=0",
+		),
+		Dir:    filepath.Join(dir, "src"),
+		Stdout: buffer,
+		Stderr: buffer,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build credential service: %w\n\n%s", err, buffer)
+	}
+
+	err = s.archiver.WithPrefix("/tmp/credential-service").Compress(filepath.Join(dir, "bin"), output)
+	if err != nil {
+		return "", fmt.Errorf("failed to archive credential service: %w", err)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,222 @@
+package docker_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCredentialService(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Run", func() {
+		var (
+			service docker.CredentialService
+
+			client     *fakes.CredentialServiceClient
+			golang     *fakes.Executable
+			archiver   *fakes.Archiver
+			cache      string
+			executions []pexec.Execution
+			copied     string
+		)
+
+		it.Before(func() {
+			var err error
+			cache, err = os.MkdirTemp("", "cache")
+			Expect(err).NotTo(HaveOccurred())
+
+			client = &fakes.CredentialServiceClient{}
+			client.ContainerRemoveCall.Returns.Error = errdefs.NotFound(errors.New("no such container"))
+			client.ContainerCreateCall.Returns.CreateResponse = container.CreateResponse{ID: "some-container-id"}
+			client.CopyToContainerCall.Stub = func(ctx gocontext.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+				b, err := io.ReadAll(content)
+				copied = string(b)
+				return err
+			}
+
+			executions = nil
+			golang = &fakes.Executable{}
+			golang.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				executions = append(executions, execution)
+				return nil
+			}
+
+			archiver = &fakes.Archiver{}
+			archiver.WithPrefixCall.Returns.Archiver = archiver
+			archiver.CompressCall.Stub = func(input, output string) error {
+				return os.WriteFile(output, []byte("credential-service-content"), 0600)
+			}
+
+			service = docker.NewCredentialService(client, golang, archiver, cache)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(cache)).To(Succeed())
+		})
+
+		it("builds the credential service and runs it on the internal network", func() {
+			logs := bytes.NewBuffer(nil)
+
+			url, err := service.Run(gocontext.Background(), logs, "some-app", "some-stack", map[string]interface{}{
+				"some-credential": "some-value",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url).To(Equal("http://some-app-credentials:8844"))
+
+			Expect(executions).To(HaveLen(1))
+			Expect(executions[0].Args[:3]).To(Equal([]string{"build", "-o", executions[0].Args[2]}))
+			Expect(executions[0].Args[2]).To(HavePrefix(cache))
+			Expect(executions[0].Env).To(ContainElements("GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0"))
+
+			source, err := os.ReadFile(filepath.Join(executions[0].Dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(source)).To(ContainSubstring("/api/v1/data"))
+
+			Expect(archiver.WithPrefixCall.Receives.Prefix).To(Equal("/tmp/credential-service"))
+
+			Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-app-credentials"))
+			Expect(client.ContainerCreateCall.Receives.ContainerName).To(Equal("some-app-credentials"))
+			Expect(client.ContainerCreateCall.Receives.Config).To(Equal(&container.Config{
+				Image: "cloudfoundry/some-stack:latest",
+				Cmd:   []string{"/tmp/credential-service/credential-service"},
+				User:  "vcap",
+				Env: []string{
+					`CREDENTIALS={"some-credential":"some-value"}`,
+					"PORT=8844",
+				},
+				Labels: map[string]string{"switchblade.app": "some-app"},
+			}))
+			Expect(client.ContainerCreateCall.Receives.HostConfig).To(Equal(&container.HostConfig{
+				NetworkMode: container.NetworkMode("switchblade-internal"),
+			}))
+
+			Expect(client.CopyToContainerCall.Receives.ContainerID).To(Equal("some-container-id"))
+			Expect(client.CopyToContainerCall.Receives.DstPath).To(Equal("/"))
+			Expect(copied).To(Equal("credential-service-content"))
+
+			Expect(client.ContainerStartCall.Receives.ContainerID).To(Equal("some-container-id"))
+			Expect(logs.String()).To(Equal("Started credential service some-app-credentials\n"))
+		})
+
+		it("reuses a previously built credential service", func() {
+			_, err := service.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-app", "some-stack", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.Run(gocontext.Background(), bytes.NewBuffer(nil), "other-app", "some-stack", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(executions).To(HaveLen(1))
+			Expect(archiver.CompressCall.CallCount).To(Equal(1))
+		})
+
+		context("WithRunID", func() {
+			it("labels the container with the run and uses the run's network", func() {
+				_, err := service.WithRunID("some-run").Run(gocontext.Background(), bytes.NewBuffer(nil), "some-app", "some-stack", nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ContainerCreateCall.Receives.Config.Labels).To(Equal(map[string]string{
+					"switchblade.app": "some-app",
+					"switchblade.run": "some-run",
+				}))
+				Expect(client.ContainerCreateCall.Receives.HostConfig.NetworkMode).To(Equal(container.NetworkMode("some-run-switchblade-internal")))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the credential service cannot be built", func() {
+				it.Before(func() {
+					golang.ExecuteCall.Stub = func(execution pexec.Execution) error {
+						io.WriteString(execution.Stderr, "build failed")
+						return errors.New("exit status 1")
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := service.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-app", "some-stack", nil)
+					Expect(err).To(MatchError("failed to build credential service: exit status 1\n\nbuild failed"))
+				})
+			})
+
+			context("when the credential service cannot be archived", func() {
+				it.Before(func() {
+					archiver.CompressCall.Stub = nil
+					archiver.CompressCall.Returns.Error = errors.New("could not compress")
+				})
+
+				it("returns an error", func() {
+					_, err := service.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-app", "some-stack", nil)
+					Expect(err).To(MatchError("failed to archive credential service: could not compress"))
+				})
+			})
+
+			context("when the credentials cannot be marshalled to json", func() {
+				it("returns an error", func() {
+					_, err := service.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-app", "some-stack", map[string]interface{}{
+						"some-credential": func() {},
+					})
+					Expect(err).To(MatchError(ContainSubstring("failed to marshal credentials json")))
+				})
+			})
+
+			context("when the stale container cannot be removed", func() {
+				it.Before(func() {
+					client.ContainerRemoveCall.Returns.Error = errors.New("could not remove")
+				})
+
+				it("returns an error", func() {
+					_, err := service.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-app", "some-stack", nil)
+					Expect(err).To(MatchError("failed to remove stale credential service container: could not remove"))
+				})
+			})
+
+			context("when the container cannot be created", func() {
+				it.Before(func() {
+					client.ContainerCreateCall.Returns.Error = errors.New("could not create")
+				})
+
+				it("returns an error", func() {
+					_, err := service.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-app", "some-stack", nil)
+					Expect(err).To(MatchError("failed to create credential service container: could not create"))
+				})
+			})
+
+			context("when the credential service cannot be copied into the container", func() {
+				it.Before(func() {
+					client.CopyToContainerCall.Stub = nil
+					client.CopyToContainerCall.Returns.Error = errors.New("could not copy")
+				})
+
+				it("returns an error", func() {
+					_, err := service.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-app", "some-stack", nil)
+					Expect(err).To(MatchError("failed to copy credential service into container: could not copy"))
+				})
+			})
+
+			context("when the container cannot be started", func() {
+				it.Before(func() {
+					client.ContainerStartCall.Returns.Error = errors.New("could not start")
+				})
+
+				it("returns an error", func() {
+					_, err := service.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-app", "some-stack", nil)
+					Expect(err).To(MatchError("failed to start credential service container: could not start"))
+				})
+			})
+		})
+	})
+}
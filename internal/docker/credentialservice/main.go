@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type credential struct {
+	ID               string      `json:"id"`
+	Name             string      `json:"name"`
+	Type             string      `json:"type"`
+	Value            interface{} `json:"value"`
+	VersionCreatedAt string      `json:"version_created_at"`
+}
+
+func main() {
+	var values map[string]interface{}
+	err := json.Unmarshal([]byte(os.Getenv("CREDENTIALS")), &values)
+	if err != nil {
+		log.Fatalf("failed to parse CREDENTIALS: %s", err)
+	}
+
+	createdAt := time.Now().UTC().Format(time.RFC3339)
+	credentials := map[string]credential{}
+	for name, value := range values {
+		name = normalize(name)
+
+		kind := "json"
+		if _, ok := value.(string); ok {
+			kind = "value"
+		}
+
+		credentials[name] = credential{
+			ID:               fmt.Sprintf("%x", name),
+			Name:             name,
+			Type:             kind,
+			Value:            value,
+			VersionCreatedAt: createdAt,
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info", func(w http.ResponseWriter, req *http.Request) {
+		respond(w, http.StatusOK, map[string]interface{}{
+			"app": map[string]string{"name": "CredHub"},
+		})
+	})
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, req *http.Request) {
+		respond(w, http.StatusOK, map[string]string{"status": "UP"})
+	})
+
+	mux.HandleFunc("/api/v1/data", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			respond(w, http.StatusMethodNotAllowed, map[string]string{"error": "The credential service fixture is read-only."})
+			return
+		}
+
+		credential, ok := credentials[normalize(req.URL.Query().Get("name"))]
+		if !ok {
+			respond(w, http.StatusNotFound, map[string]string{"error": "The request could not be completed because the credential does not exist or you do not have sufficient authorization."})
+			return
+		}
+
+		respond(w, http.StatusOK, map[string]interface{}{"data": []interface{}{credential}})
+	})
+
+	mux.HandleFunc("/api/v1/interpolate", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			respond(w, http.StatusMethodNotAllowed, map[string]string{"error": "The interpolate endpoint only accepts POST requests."})
+			return
+		}
+
+		var services map[string][]map[string]interface{}
+		err := json.NewDecoder(req.Body).Decode(&services)
+		if err != nil {
+			respond(w, http.StatusBadRequest, map[string]string{"error": "The request body could not be parsed as VCAP_SERVICES."})
+			return
+		}
+
+		for _, instances := range services {
+			for _, instance := range instances {
+				refs, ok := instance["credentials"].(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				ref, ok := refs["credhub-ref"].(string)
+				if !ok {
+					continue
+				}
+
+				credential, ok := credentials[normalize(ref)]
+				if !ok {
+					respond(w, http.StatusNotFound, map[string]string{"error": "The request could not be completed because the credential does not exist or you do not have sufficient authorization."})
+					return
+				}
+
+				instance["credentials"] = credential.Value
+			}
+		}
+
+		respond(w, http.StatusOK, services)
+	})
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8844"
+	}
+
+	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", port), mux))
+}
+
+func normalize(name string) string {
+	return fmt.Sprintf("/%s", strings.TrimPrefix(name, "/"))
+}
+
+func respond(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+const DebugBodyLimit = 1024
+
+type DebugTransport struct {
+	base http.RoundTripper
+	w    io.Writer
+	m    *sync.Mutex
+}
+
+func NewDebugTransport(base http.RoundTripper, w io.Writer) DebugTransport {
+	return DebugTransport{
+		base: base,
+		w:    w,
+		m:    &sync.Mutex{},
+	}
+}
+
+func (t DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := apiVersionPrefix.ReplaceAllString(req.URL.Path, "/")
+	if req.URL.RawQuery != "" {
+		path = fmt.Sprintf("%s?%s", path, req.URL.RawQuery)
+	}
+
+	var request *capturedBody
+	if req.Body != nil && req.Body != http.NoBody {
+		request = &capturedBody{ReadCloser: req.Body, contentType: req.Header.Get("Content-Type")}
+		req = req.Clone(req.Context())
+		req.Body = request
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	duration := time.Since(start)
+
+	if err != nil {
+		t.write(req.Method, path, request, fmt.Sprintf("error: %s", err), duration, nil)
+		return resp, err
+	}
+
+	if resp.Body == nil {
+		t.write(req.Method, path, request, resp.Status, duration, nil)
+		return resp, nil
+	}
+
+	response := &capturedBody{ReadCloser: resp.Body, contentType: resp.Header.Get("Content-Type")}
+	response.onClose = func() {
+		t.write(req.Method, path, request, resp.Status, duration, response)
+	}
+	resp.Body = response
+
+	return resp, nil
+}
+
+func (t DebugTransport) write(method, path string, request *capturedBody, status string, duration time.Duration, response *capturedBody) {
+	entry := bytes.NewBuffer(nil)
+	fmt.Fprintf(entry, "--> %s %s\n", method, path)
+	request.summarize(entry)
+	fmt.Fprintf(entry, "<-- %s (%s)\n", status, duration.Round(time.Millisecond))
+	response.summarize(entry)
+
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	_, _ = t.w.Write(entry.Bytes())
+}
+
+type capturedBody struct {
+	io.ReadCloser
+
+	contentType string
+	prefix      bytes.Buffer
+	size        int
+	once        sync.Once
+	onClose     func()
+}
+
+func (b *capturedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if remaining := DebugBodyLimit - b.prefix.Len(); remaining > 0 {
+		if n < remaining {
+			remaining = n
+		}
+
+		b.prefix.Write(p[:remaining])
+	}
+	b.size += n
+
+	return n, err
+}
+
+func (b *capturedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.onClose != nil {
+		b.once.Do(b.onClose)
+	}
+
+	return err
+}
+
+func (b *capturedBody) summarize(w io.Writer) {
+	if b == nil || b.size == 0 {
+		return
+	}
+
+	if !strings.Contains(b.contentType, "json") && !strings.HasPrefix(b.contentType, "text/") {
+		fmt.Fprintf(w, "    [%d bytes of %s]\n", b.size, contentType(b.contentType))
+		return
+	}
+
+	body := strings.TrimSpace(b.prefix.String())
+	if b.size > DebugBodyLimit {
+		body = fmt.Sprintf("%s... [truncated, %d bytes total]", body, b.size)
+	}
+
+	fmt.Fprintf(w, "    %s\n", body)
+}
+
+func contentType(value string) string {
+	if value == "" {
+		return "unknown content"
+	}
+
+	return value
+}
+
+func WithDebugTransport(w io.Writer) client.Opt {
+	return func(c *client.Client) error {
+		httpClient := c.HTTPClient()
+		if transport, ok := httpClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+			err := client.WithScheme("https")(c)
+			if err != nil {
+				return err
+			}
+		}
+
+		httpClient.Transport = NewDebugTransport(httpClient.Transport, w)
+
+		return client.WithHTTPClient(httpClient)(c)
+	}
+}
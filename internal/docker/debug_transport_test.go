@@ -0,0 +1,155 @@
+package docker_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/client"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDebugTransport(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		log *bytes.Buffer
+	)
+
+	it.Before(func() {
+		log = bytes.NewBuffer(nil)
+	})
+
+	context("RoundTrip", func() {
+		it("logs the request and response once the response body is closed", func() {
+			var received string
+			transport := docker.NewDebugTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				received = string(body)
+
+				return &http.Response{
+					StatusCode: http.StatusCreated,
+					Status:     "201 Created",
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"Id":"some-container-id"}`)),
+				}, nil
+			}), log)
+
+			req, err := http.NewRequest("POST", "http://docker/v1.41/containers/create?name=some-app", strings.NewReader(`{"Image":"some-image"}`))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := transport.RoundTrip(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(received).To(Equal(`{"Image":"some-image"}`))
+			Expect(log.String()).To(BeEmpty())
+
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(`{"Id":"some-container-id"}`))
+			Expect(resp.Body.Close()).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+			Expect(lines).To(HaveLen(4))
+			Expect(lines[0]).To(Equal("--> POST /containers/create?name=some-app"))
+			Expect(lines[1]).To(Equal(`    {"Image":"some-image"}`))
+			Expect(lines[2]).To(MatchRegexp(`^<-- 201 Created \(\S+\)$`))
+			Expect(lines[3]).To(Equal(`    {"Id":"some-container-id"}`))
+		})
+
+		it("truncates long bodies", func() {
+			content := strings.Repeat("a", docker.DebugBodyLimit+10)
+			transport := docker.NewDebugTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Status:     "200 OK",
+					Header:     http.Header{"Content-Type": []string{"text/plain"}},
+					Body:       io.NopCloser(strings.NewReader(content)),
+				}, nil
+			}), log)
+
+			req, err := http.NewRequest("GET", "http://docker/containers/some-app/logs", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := transport.RoundTrip(req)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = io.Copy(io.Discard, resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+
+			Expect(log.String()).To(ContainSubstring(strings.Repeat("a", docker.DebugBodyLimit) + "... [truncated, 1034 bytes total]\n"))
+		})
+
+		it("summarizes binary bodies", func() {
+			transport := docker.NewDebugTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				_, err := io.Copy(io.Discard, req.Body)
+				if err != nil {
+					return nil, err
+				}
+
+				return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: http.NoBody}, nil
+			}), log)
+
+			req, err := http.NewRequest("PUT", "http://docker/containers/some-app/archive?path=%2F", bytes.NewReader([]byte("some-tarball")))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Content-Type", "application/x-tar")
+
+			resp, err := transport.RoundTrip(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+
+			Expect(log.String()).To(ContainSubstring("--> PUT /containers/some-app/archive?path=%2F\n    [12 bytes of application/x-tar]\n<-- 200 OK"))
+		})
+
+		it("logs requests that fail", func() {
+			transport := docker.NewDebugTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			}), log)
+
+			req, err := http.NewRequest("GET", "http://docker/_ping", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = transport.RoundTrip(req)
+			Expect(err).To(MatchError("connection refused"))
+
+			Expect(log.String()).To(MatchRegexp(`^--> GET /_ping\n<-- error: connection refused \(\S+\)\n$`))
+		})
+	})
+
+	context("WithDebugTransport", func() {
+		var server *httptest.Server
+
+		it.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("API-Version", "1.41")
+				w.WriteHeader(http.StatusOK)
+			}))
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("logs the requests made by the client", func() {
+			cli, err := client.NewClientWithOpts(client.WithHost(strings.Replace(server.URL, "http://", "tcp://", 1)), docker.WithDebugTransport(log))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = cli.Ping(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(log.String()).To(ContainSubstring("/_ping\n<-- 200 OK"))
+		})
+	})
+}
@@ -0,0 +1,37 @@
+package docker
+
+import (
+	"io"
+	"path"
+	"strings"
+)
+
+func excludeMounted(droplet io.Reader, root string, targets []string) io.Reader {
+	var excluded []string
+	for _, target := range targets {
+		prefix := path.Clean(root) + "/"
+		if strings.HasPrefix(path.Clean(target), prefix) {
+			excluded = append(excluded, strings.TrimPrefix(path.Clean(target), prefix))
+		}
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(filterTarball(droplet, w, func(name string) (string, bool) {
+			return name, !isMounted(name, excluded)
+		}))
+	}()
+
+	return r
+}
+
+func isMounted(name string, excluded []string) bool {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	for _, prefix := range excluded {
+		if name == prefix || strings.HasPrefix(name, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
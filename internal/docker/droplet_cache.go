@@ -0,0 +1,226 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DropletCache keeps staged droplets between test runs, keyed on everything
+// recorded in a deployment's manifest that can change the staging output.
+type DropletCache struct {
+	dir string
+}
+
+type cachedDroplet struct {
+	Droplet string      `json:"droplet"`
+	Result  StageResult `json:"result"`
+}
+
+func NewDropletCache(dir string) DropletCache {
+	return DropletCache{dir: dir}
+}
+
+// Key identifies the droplet that staging the manifest's source would
+// produce. The app name is left out so that identical fixtures deployed under
+// different names share a droplet. The recorded env has its secrets redacted,
+// so the key also covers the env digest to catch changes to those values.
+func (c DropletCache) Key(manifest Manifest, extension string) (string, error) {
+	stack := manifest.Stack.Digest
+	if stack == "" {
+		stack = manifest.Stack.Image
+	}
+
+	content, err := json.Marshal(struct {
+		Source         string              `json:"source"`
+		Stack          string              `json:"stack"`
+		Lifecycle      string              `json:"lifecycle"`
+		Buildpacks     []BuildpackManifest `json:"buildpacks"`
+		BuildpackOrder []string            `json:"buildpack_order"`
+		SkipDetect     bool                `json:"skip_detect"`
+		Env            map[string]string   `json:"env"`
+		EnvDigest      string              `json:"env_digest"`
+		Services       []string            `json:"services"`
+		Options        []string            `json:"options"`
+		Format         string              `json:"format"`
+	}{
+		Source:         manifest.Source,
+		Stack:          stack,
+		Lifecycle:      manifest.Lifecycle.SHA256,
+		Buildpacks:     manifest.Buildpacks,
+		BuildpackOrder: manifest.BuildpackOrder,
+		SkipDetect:     manifest.SkipDetect,
+		Env:            manifest.Env,
+		EnvDigest:      manifest.EnvDigest,
+		Services:       manifest.Services,
+		Options:        manifest.Options,
+		Format:         extension,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal droplet cache key: %w", err)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
+
+// Load links the droplet cached under key into dir as the droplet for name.
+// It returns the staging result and droplet digest recorded with it, and
+// false when nothing is cached under key.
+func (c DropletCache) Load(ctx context.Context, key, dir, name, extension string) (StageResult, []byte, bool, error) {
+	content, err := os.ReadFile(filepath.Join(c.dir, fmt.Sprintf("%s.json", key)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return StageResult{}, nil, false, nil
+		}
+
+		return StageResult{}, nil, false, fmt.Errorf("failed to read cached droplet: %w", err)
+	}
+
+	var entry cachedDroplet
+	err = json.Unmarshal(content, &entry)
+	if err != nil {
+		return StageResult{}, nil, false, fmt.Errorf("failed to parse cached droplet: %w", err)
+	}
+
+	_, err = os.Stat(filepath.Join(c.dir, entry.Droplet))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return StageResult{}, nil, false, nil
+		}
+
+		return StageResult{}, nil, false, fmt.Errorf("failed to stat cached droplet: %w", err)
+	}
+
+	digest, err := hex.DecodeString(strings.TrimSuffix(filepath.Base(entry.Droplet), extension))
+	if err != nil {
+		return StageResult{}, nil, false, fmt.Errorf("failed to parse cached droplet digest: %w", err)
+	}
+
+	unlock, err := lockDroplets(ctx, dir)
+	if err != nil {
+		return StageResult{}, nil, false, err
+	}
+	defer unlock()
+
+	err = linkFile(filepath.Join(c.dir, entry.Droplet), filepath.Join(dir, entry.Droplet))
+	if err != nil {
+		return StageResult{}, nil, false, fmt.Errorf("failed to copy cached droplet: %w", err)
+	}
+
+	err = removeStaleDroplets(dir, name)
+	if err != nil {
+		return StageResult{}, nil, false, err
+	}
+
+	err = linkDroplet(dir, entry.Droplet, filepath.Join(dir, name+extension))
+	if err != nil {
+		return StageResult{}, nil, false, fmt.Errorf("failed to link droplet: %w", err)
+	}
+
+	return entry.Result, digest, true, nil
+}
+
+// Store caches the droplet that dir holds for name under key, along with the
+// result of the staging that produced it.
+func (c DropletCache) Store(ctx context.Context, key, dir, name, extension string, result StageResult) error {
+	unlock, err := lockDroplets(ctx, dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	blob, err := dropletBlob(dir, name+extension)
+	if err != nil {
+		return fmt.Errorf("failed to resolve droplet: %w", err)
+	}
+
+	if blob == "" {
+		return fmt.Errorf("failed to resolve droplet: %s is not linked to a blob", name+extension)
+	}
+
+	err = linkFile(filepath.Join(dir, blob), filepath.Join(c.dir, blob))
+	if err != nil {
+		return fmt.Errorf("failed to cache droplet: %w", err)
+	}
+
+	content, err := json.Marshal(cachedDroplet{Droplet: blob, Result: result})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached droplet: %w", err)
+	}
+
+	file, err := os.CreateTemp(c.dir, fmt.Sprintf("%s-*.json", key))
+	if err != nil {
+		return fmt.Errorf("failed to create cached droplet: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	_, err = file.Write(content)
+	if err != nil {
+		return fmt.Errorf("failed to write cached droplet: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close cached droplet: %w", err)
+	}
+
+	err = os.Rename(file.Name(), filepath.Join(c.dir, fmt.Sprintf("%s.json", key)))
+	if err != nil {
+		return fmt.Errorf("failed to store cached droplet: %w", err)
+	}
+
+	return nil
+}
+
+// linkFile hard links source to destination, falling back to a copy when the
+// two are on different filesystems. Droplet blobs are named by their digest,
+// so an existing destination already has the same content.
+func linkFile(source, destination string) error {
+	_, err := os.Stat(destination)
+	if err == nil {
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(destination), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	err = os.Link(source, destination)
+	if err == nil || errors.Is(err, os.ErrExist) {
+		return nil
+	}
+
+	input, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	output, err := os.CreateTemp(filepath.Dir(destination), filepath.Base(destination)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(output.Name())
+	defer output.Close()
+
+	_, err = io.Copy(output, input)
+	if err != nil {
+		return err
+	}
+
+	err = output.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(output.Name(), destination)
+}
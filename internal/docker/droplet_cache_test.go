@@ -0,0 +1,191 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDropletCache(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cache     docker.DropletCache
+		cacheDir  string
+		workspace string
+		manifest  docker.Manifest
+	)
+
+	it.Before(func() {
+		var err error
+		cacheDir, err = os.MkdirTemp("", "droplet-cache")
+		Expect(err).NotTo(HaveOccurred())
+
+		workspace, err = os.MkdirTemp("", "workspace")
+		Expect(err).NotTo(HaveOccurred())
+
+		manifest = docker.Manifest{
+			Name:   "some-app",
+			Source: "sha256:some-source-digest",
+			Stack: docker.StackManifest{
+				Name:   "cflinuxfs4",
+				Image:  "cloudfoundry/cflinuxfs4:latest",
+				Digest: "sha256:some-stack-digest",
+			},
+			Buildpacks: []docker.BuildpackManifest{
+				{Name: "go_buildpack", URI: "https://example.com/go_buildpack.zip", SHA256: "some-buildpack-digest"},
+			},
+			BuildpackOrder: []string{"go_buildpack"},
+			Env:            map[string]string{"SOME_VARIABLE": "some-value"},
+		}
+
+		cache = docker.NewDropletCache(cacheDir)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(cacheDir)).To(Succeed())
+		Expect(os.RemoveAll(workspace)).To(Succeed())
+	})
+
+	context("Key", func() {
+		it("is shared by apps with the same source and buildpacks", func() {
+			key, err := cache.Key(manifest, ".tar.gz")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key).To(HaveLen(64))
+
+			other := manifest
+			other.Name = "other-app"
+			otherKey, err := cache.Key(other, ".tar.gz")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(otherKey).To(Equal(key))
+		})
+
+		it("changes when only a redacted env value changes", func() {
+			manifest.Env = map[string]string{"GITHUB_TOKEN": docker.RedactedValue}
+			manifest.EnvDigest = "sha256:some-env-digest"
+
+			key, err := cache.Key(manifest, ".tar.gz")
+			Expect(err).NotTo(HaveOccurred())
+
+			other := manifest
+			other.EnvDigest = "sha256:other-env-digest"
+			otherKey, err := cache.Key(other, ".tar.gz")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(otherKey).NotTo(Equal(key))
+
+			_, _, ok, err := cache.Load(gocontext.Background(), otherKey, filepath.Join(workspace, "droplets"), "some-app", ".tar.gz")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		it("changes with anything that affects staging", func() {
+			key, err := cache.Key(manifest, ".tar.gz")
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, change := range []func(m docker.Manifest) docker.Manifest{
+				func(m docker.Manifest) docker.Manifest { m.Source = "sha256:other-source-digest"; return m },
+				func(m docker.Manifest) docker.Manifest { m.Stack.Digest = "sha256:other-stack-digest"; return m },
+				func(m docker.Manifest) docker.Manifest {
+					m.Buildpacks = []docker.BuildpackManifest{{Name: "go_buildpack", SHA256: "other-buildpack-digest"}}
+					return m
+				},
+				func(m docker.Manifest) docker.Manifest { m.BuildpackOrder = nil; return m },
+				func(m docker.Manifest) docker.Manifest {
+					m.Env = map[string]string{"SOME_VARIABLE": "other-value"}
+					return m
+				},
+			} {
+				changed, err := cache.Key(change(manifest), ".tar.gz")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(changed).NotTo(Equal(key))
+			}
+
+			zstd, err := cache.Key(manifest, ".tar.zst")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zstd).NotTo(Equal(key))
+		})
+	})
+
+	context("Store and Load", func() {
+		var digest string
+
+		it.Before(func() {
+			digest = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+			Expect(os.MkdirAll(filepath.Join(workspace, "droplets", "sha256"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workspace, "droplets", "sha256", digest+".tar.gz"), []byte("droplet-content"), 0600)).To(Succeed())
+			Expect(os.Symlink(filepath.Join("sha256", digest+".tar.gz"), filepath.Join(workspace, "droplets", "some-app.tar.gz"))).To(Succeed())
+		})
+
+		it("links the cached droplet in for another app", func() {
+			result := docker.StageResult{
+				Command:   "some-command",
+				Processes: map[string]string{"web": "some-command"},
+			}
+
+			err := cache.Store(gocontext.Background(), "some-key", filepath.Join(workspace, "droplets"), "some-app", ".tar.gz", result)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.RemoveAll(filepath.Join(workspace, "droplets"))).To(Succeed())
+
+			loaded, loadedDigest, ok, err := cache.Load(gocontext.Background(), "some-key", filepath.Join(workspace, "droplets"), "other-app", ".tar.gz")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(loaded).To(Equal(result))
+			Expect(loadedDigest).To(HaveLen(32))
+
+			content, err := os.ReadFile(filepath.Join(workspace, "droplets", "other-app.tar.gz"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("droplet-content"))
+		})
+
+		context("when nothing is cached under the key", func() {
+			it("reports a miss", func() {
+				_, _, ok, err := cache.Load(gocontext.Background(), "other-key", filepath.Join(workspace, "droplets"), "other-app", ".tar.gz")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+		})
+
+		context("when the cached droplet has been removed", func() {
+			it.Before(func() {
+				err := cache.Store(gocontext.Background(), "some-key", filepath.Join(workspace, "droplets"), "some-app", ".tar.gz", docker.StageResult{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.RemoveAll(filepath.Join(cacheDir, "sha256"))).To(Succeed())
+			})
+
+			it("reports a miss", func() {
+				_, _, ok, err := cache.Load(gocontext.Background(), "some-key", filepath.Join(workspace, "droplets"), "other-app", ".tar.gz")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the cache entry is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(cacheDir, "some-key.json"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, _, _, err := cache.Load(gocontext.Background(), "some-key", filepath.Join(workspace, "droplets"), "other-app", ".tar.gz")
+					Expect(err).To(MatchError(ContainSubstring("failed to parse cached droplet:")))
+				})
+			})
+
+			context("when the app has no droplet", func() {
+				it("returns an error", func() {
+					err := cache.Store(gocontext.Background(), "some-key", filepath.Join(workspace, "droplets"), "other-app", ".tar.gz", docker.StageResult{})
+					Expect(err).To(MatchError(ContainSubstring("failed to resolve droplet:")))
+				})
+			})
+		})
+	})
+}
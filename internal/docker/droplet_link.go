@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// lockDroplets serializes linking droplets to blobs in dir with removing
+// them, so that a blob is never removed just as another droplet links to it.
+func lockDroplets(ctx context.Context, dir string) (func() error, error) {
+	unlock, err := lockFile(ctx, fmt.Sprintf("%s.lock", dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock droplets: %w", err)
+	}
+
+	return unlock, nil
+}
+
+// linkDroplet points the droplet at path to blob, a path relative to dir.
+// Windows hosts without Developer Mode or admin rights cannot create
+// symlinks, so it falls back to a hard link, or a copy, of the blob.
+func linkDroplet(dir, blob, path string) error {
+	err := os.Symlink(blob, path)
+	if err == nil {
+		return nil
+	}
+
+	return linkFile(filepath.Join(dir, blob), path)
+}
+
+// dropletBlob returns the blob, relative to dir, that the droplet named
+// filename was linked to, or "" when filename is not a linked droplet.
+func dropletBlob(dir, filename string) (string, error) {
+	path := filepath.Join(dir, filename)
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return os.Readlink(path)
+	}
+
+	var extension string
+	for _, ext := range []string{".tar.gz", ".tar.zst"} {
+		if strings.HasSuffix(filename, ext) {
+			extension = ext
+		}
+	}
+
+	if !info.Mode().IsRegular() || extension == "" {
+		return "", nil
+	}
+
+	blobs, err := os.ReadDir(filepath.Join(dir, "sha256"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	for _, entry := range blobs {
+		blobInfo, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+
+		if os.SameFile(info, blobInfo) {
+			return filepath.Join("sha256", entry.Name()), nil
+		}
+	}
+
+	// The droplet is a copy of its blob, which is named by its digest.
+	digest, err := dropletDigest(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to digest droplet: %w", err)
+	}
+
+	blob := filepath.Join("sha256", hex.EncodeToString(digest)+extension)
+	_, err = os.Stat(filepath.Join(dir, blob))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return blob, nil
+}
@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+)
+
+//go:generate faux --interface DropletPusherClient --output fakes/droplet_pusher_client.go
+type DropletPusherClient interface {
+	ContainerCommit(ctx context.Context, container string, options types.ContainerCommitOptions) (types.IDResponse, error)
+	ImagePush(ctx context.Context, image string, options types.ImagePushOptions) (io.ReadCloser, error)
+}
+
+type DropletPusher struct {
+	client DropletPusherClient
+	auth   types.AuthConfig
+}
+
+func NewDropletPusher(client DropletPusherClient) DropletPusher {
+	return DropletPusher{client: client}
+}
+
+func (p DropletPusher) WithAuth(username, password string) DropletPusher {
+	p.auth = types.AuthConfig{Username: username, Password: password}
+	return p
+}
+
+func (p DropletPusher) Snapshot(ctx context.Context, name, ref string) error {
+	return p.commit(ctx, name, ref, fmt.Sprintf("snapshot of %s", name))
+}
+
+func (p DropletPusher) Push(ctx context.Context, logs io.Writer, name, ref string) error {
+	err := p.commit(ctx, name, ref, fmt.Sprintf("droplet for %s", name))
+	if err != nil {
+		return err
+	}
+
+	auth, err := json.Marshal(p.auth)
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry auth: %w", err)
+	}
+
+	pushLogs, err := p.client.ImagePush(ctx, ref, types.ImagePushOptions{
+		RegistryAuth: base64.URLEncoding.EncodeToString(auth),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to push image: %w", err)
+	}
+	defer pushLogs.Close()
+
+	decoder := json.NewDecoder(pushLogs)
+	for {
+		var message struct {
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		}
+		err = decoder.Decode(&message)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read image push logs: %w", err)
+		}
+
+		if message.Error != "" {
+			return fmt.Errorf("failed to push image: %s", message.Error)
+		}
+
+		if message.Status != "" {
+			fmt.Fprintln(logs, message.Status)
+		}
+	}
+}
+
+func (p DropletPusher) commit(ctx context.Context, name, ref, comment string) error {
+	_, err := p.client.ContainerCommit(ctx, name, types.ContainerCommitOptions{
+		Reference: ref,
+		Comment:   comment,
+		Pause:     true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit container: %w", err)
+	}
+
+	return nil
+}
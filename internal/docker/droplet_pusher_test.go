@@ -0,0 +1,154 @@
+package docker_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDropletPusher(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Snapshot", func() {
+		var (
+			pusher docker.DropletPusher
+			client *fakes.DropletPusherClient
+		)
+
+		it.Before(func() {
+			client = &fakes.DropletPusherClient{}
+			pusher = docker.NewDropletPusher(client)
+		})
+
+		it("commits the application container to a local image", func() {
+			err := pusher.Snapshot(gocontext.Background(), "some-app", "some-app-snapshot:latest")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ContainerCommitCall.Receives.Container).To(Equal("some-app"))
+			Expect(client.ContainerCommitCall.Receives.Options).To(Equal(types.ContainerCommitOptions{
+				Reference: "some-app-snapshot:latest",
+				Comment:   "snapshot of some-app",
+				Pause:     true,
+			}))
+
+			Expect(client.ImagePushCall.CallCount).To(Equal(0))
+		})
+
+		context("failure cases", func() {
+			context("when the container cannot be committed", func() {
+				it.Before(func() {
+					client.ContainerCommitCall.Returns.Error = errors.New("failed to commit")
+				})
+
+				it("returns an error", func() {
+					err := pusher.Snapshot(gocontext.Background(), "some-app", "some-app-snapshot:latest")
+					Expect(err).To(MatchError("failed to commit container: failed to commit"))
+				})
+			})
+		})
+	})
+
+	context("Push", func() {
+		var (
+			pusher docker.DropletPusher
+			client *fakes.DropletPusherClient
+			logs   *bytes.Buffer
+		)
+
+		it.Before(func() {
+			client = &fakes.DropletPusherClient{}
+			client.ImagePushCall.Returns.ReadCloser = io.NopCloser(strings.NewReader(`{"status":"Preparing"}
+{"status":"Pushed"}
+`))
+
+			logs = bytes.NewBuffer(nil)
+			pusher = docker.NewDropletPusher(client)
+		})
+
+		it("commits the application container and pushes it to the registry", func() {
+			err := pusher.WithAuth("some-username", "some-password").Push(gocontext.Background(), logs, "some-app", "registry.example.com/some-app:latest")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ContainerCommitCall.Receives.Container).To(Equal("some-app"))
+			Expect(client.ContainerCommitCall.Receives.Options).To(Equal(types.ContainerCommitOptions{
+				Reference: "registry.example.com/some-app:latest",
+				Comment:   "droplet for some-app",
+				Pause:     true,
+			}))
+
+			Expect(client.ImagePushCall.Receives.Image).To(Equal("registry.example.com/some-app:latest"))
+
+			content, err := base64.URLEncoding.DecodeString(client.ImagePushCall.Receives.Options.RegistryAuth)
+			Expect(err).NotTo(HaveOccurred())
+
+			var auth types.AuthConfig
+			Expect(json.Unmarshal(content, &auth)).To(Succeed())
+			Expect(auth).To(Equal(types.AuthConfig{
+				Username: "some-username",
+				Password: "some-password",
+			}))
+
+			Expect(logs.String()).To(Equal("Preparing\nPushed\n"))
+		})
+
+		context("failure cases", func() {
+			context("when the container cannot be committed", func() {
+				it.Before(func() {
+					client.ContainerCommitCall.Returns.Error = errors.New("failed to commit")
+				})
+
+				it("returns an error", func() {
+					err := pusher.Push(gocontext.Background(), logs, "some-app", "registry.example.com/some-app:latest")
+					Expect(err).To(MatchError("failed to commit container: failed to commit"))
+				})
+			})
+
+			context("when the push cannot be started", func() {
+				it.Before(func() {
+					client.ImagePushCall.Returns.Error = errors.New("failed to push")
+				})
+
+				it("returns an error", func() {
+					err := pusher.Push(gocontext.Background(), logs, "some-app", "registry.example.com/some-app:latest")
+					Expect(err).To(MatchError("failed to push image: failed to push"))
+				})
+			})
+
+			context("when the push logs report an error", func() {
+				it.Before(func() {
+					client.ImagePushCall.Returns.ReadCloser = io.NopCloser(strings.NewReader(`{"status":"Preparing"}
+{"error":"unauthorized: authentication required"}
+`))
+				})
+
+				it("returns an error", func() {
+					err := pusher.Push(gocontext.Background(), logs, "some-app", "registry.example.com/some-app:latest")
+					Expect(err).To(MatchError("failed to push image: unauthorized: authentication required"))
+				})
+			})
+
+			context("when the push logs cannot be parsed", func() {
+				it.Before(func() {
+					client.ImagePushCall.Returns.ReadCloser = io.NopCloser(strings.NewReader(`%%%`))
+				})
+
+				it("returns an error", func() {
+					err := pusher.Push(gocontext.Background(), logs, "some-app", "registry.example.com/some-app:latest")
+					Expect(err).To(MatchError(ContainSubstring("failed to read image push logs:")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const SignatureExtension = ".sig"
+
+func SignDroplet(path string, key crypto.Signer) error {
+	digest, err := dropletDigest(path)
+	if err != nil {
+		return fmt.Errorf("failed to sign droplet: %w", err)
+	}
+
+	return writeSignature(path, digest, key)
+}
+
+func VerifyDroplet(path string, key crypto.PublicKey) error {
+	content, err := os.ReadFile(path + SignatureExtension)
+	if err != nil {
+		return fmt.Errorf("failed to read droplet signature: %w", err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return fmt.Errorf("failed to decode droplet signature: %w", err)
+	}
+
+	digest, err := dropletDigest(path)
+	if err != nil {
+		return fmt.Errorf("failed to verify droplet: %w", err)
+	}
+
+	var valid bool
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest, signature)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, digest, signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil
+	default:
+		return fmt.Errorf("failed to verify droplet: unsupported public key type %T", key)
+	}
+
+	if !valid {
+		return fmt.Errorf("failed to verify droplet: signature does not match %s", filepath.Base(path))
+	}
+
+	return nil
+}
+
+func verifyStoredDroplet(dir, name string, key crypto.PublicKey) error {
+	path := filepath.Join(dir, fmt.Sprintf("%s.tar.zst", name))
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		path = filepath.Join(dir, fmt.Sprintf("%s.tar.gz", name))
+	}
+
+	return VerifyDroplet(path, key)
+}
+
+func writeSignature(path string, digest []byte, key crypto.Signer) error {
+	var opts crypto.SignerOpts = crypto.SHA256
+	if _, ok := key.Public().(ed25519.PublicKey); ok {
+		opts = crypto.Hash(0)
+	}
+
+	signature, err := key.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return fmt.Errorf("failed to sign droplet: %w", err)
+	}
+
+	err = os.WriteFile(path+SignatureExtension, []byte(base64.StdEncoding.EncodeToString(signature)), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write droplet signature: %w", err)
+	}
+
+	return nil
+}
+
+func dropletDigest(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
+}
@@ -0,0 +1,140 @@
+package docker_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDropletSignature(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		droplet string
+	)
+
+	it.Before(func() {
+		dir := t.TempDir()
+		droplet = filepath.Join(dir, "some-app.tar.gz")
+		Expect(os.WriteFile(droplet, []byte("droplet-content"), 0600)).To(Succeed())
+	})
+
+	context("SignDroplet and VerifyDroplet", func() {
+		it("signs and verifies droplets with ECDSA, Ed25519, and RSA keys", func() {
+			ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+
+			rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, key := range []crypto.Signer{ecdsaKey, ed25519Key, rsaKey} {
+				Expect(docker.SignDroplet(droplet, key)).To(Succeed())
+				Expect(docker.VerifyDroplet(droplet, key.Public())).To(Succeed())
+			}
+		})
+
+		it("writes a base64 signature over the droplet digest", func() {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(docker.SignDroplet(droplet, key)).To(Succeed())
+
+			content, err := os.ReadFile(droplet + ".sig")
+			Expect(err).NotTo(HaveOccurred())
+
+			signature, err := base64.StdEncoding.DecodeString(string(content))
+			Expect(err).NotTo(HaveOccurred())
+
+			digest := sha256.Sum256([]byte("droplet-content"))
+			Expect(ecdsa.VerifyASN1(&key.PublicKey, digest[:], signature)).To(BeTrue())
+		})
+
+		context("failure cases", func() {
+			var key ed25519.PrivateKey
+
+			it.Before(func() {
+				var err error
+				_, key, err = ed25519.GenerateKey(rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			context("when the droplet does not exist", func() {
+				it("returns an error", func() {
+					err := docker.SignDroplet(filepath.Join(filepath.Dir(droplet), "missing.tar.gz"), key)
+					Expect(err).To(MatchError(ContainSubstring("failed to sign droplet: open")))
+				})
+			})
+
+			context("when the signature does not exist", func() {
+				it("returns an error", func() {
+					err := docker.VerifyDroplet(droplet, key.Public())
+					Expect(err).To(MatchError(ContainSubstring("failed to read droplet signature: open")))
+				})
+			})
+
+			context("when the signature is not base64", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(droplet+".sig", []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := docker.VerifyDroplet(droplet, key.Public())
+					Expect(err).To(MatchError(ContainSubstring("failed to decode droplet signature:")))
+				})
+			})
+
+			context("when the droplet was modified after signing", func() {
+				it.Before(func() {
+					Expect(docker.SignDroplet(droplet, key)).To(Succeed())
+					Expect(os.WriteFile(droplet, []byte("tampered-content"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := docker.VerifyDroplet(droplet, key.Public())
+					Expect(err).To(MatchError("failed to verify droplet: signature does not match some-app.tar.gz"))
+				})
+			})
+
+			context("when the droplet was signed with another key", func() {
+				it.Before(func() {
+					Expect(docker.SignDroplet(droplet, key)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					other, _, err := ed25519.GenerateKey(rand.Reader)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = docker.VerifyDroplet(droplet, other)
+					Expect(err).To(MatchError("failed to verify droplet: signature does not match some-app.tar.gz"))
+				})
+			})
+
+			context("when the public key type is not supported", func() {
+				it.Before(func() {
+					Expect(docker.SignDroplet(droplet, key)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := docker.VerifyDroplet(droplet, "not-a-key")
+					Expect(err).To(MatchError("failed to verify droplet: unsupported public key type string"))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+var SnapshotPaths = []string{"/home/vcap/app", "/home/vcap/deps"}
+
+type EnvironmentSnapshotter struct {
+	client ExecClient
+}
+
+func NewEnvironmentSnapshotter(client ExecClient) EnvironmentSnapshotter {
+	return EnvironmentSnapshotter{client: client}
+}
+
+func (s EnvironmentSnapshotter) Snapshot(ctx context.Context, name string) (map[string]string, map[string]string, error) {
+	execer := containerExecer{ctx: ctx, client: s.client, containerID: name, workingDir: "/home/vcap"}
+
+	output, err := s.run(execer, "/tmp/lifecycle/launcher", "app", "env -0", "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read environment: %w", err)
+	}
+
+	env := map[string]string{}
+	for _, variable := range strings.Split(output, "\x00") {
+		key, value, ok := strings.Cut(variable, "=")
+		if !ok {
+			continue
+		}
+
+		env[key] = value
+	}
+
+	output, err = s.run(execer, "sh", "-c", fmt.Sprintf("find %s -type f -print0 2>/dev/null | xargs -0 -r sha256sum", strings.Join(SnapshotPaths, " ")))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read files: %w", err)
+	}
+
+	files := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		checksum, path, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			continue
+		}
+
+		files[path] = checksum
+	}
+
+	return env, files, nil
+}
+
+func (s EnvironmentSnapshotter) run(execer containerExecer, command ...string) (string, error) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	exitCode, err := execer.run(command, stdout, stderr)
+	if err != nil {
+		return "", err
+	}
+
+	if exitCode != 0 {
+		return "", fmt.Errorf("exit status %d\n\nOutput:\n%s", exitCode, stderr)
+	}
+
+	return stdout.String(), nil
+}
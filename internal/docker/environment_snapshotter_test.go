@@ -0,0 +1,138 @@
+package docker_test
+
+import (
+	"bufio"
+	"bytes"
+	gocontext "context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testEnvironmentSnapshotter(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		snapshotter docker.EnvironmentSnapshotter
+		client      *fakes.ExecClient
+
+		execConfigs []types.ExecConfig
+		outputs     map[string]string
+		exitCodes   map[string]int
+	)
+
+	it.Before(func() {
+		execConfigs = nil
+		outputs = map[string]string{
+			"env": "PATH=/usr/bin\x00VCAP_SERVICES={\"some\":\"service\"}\x00MULTI=line\none\x00",
+			"files": "some-checksum  /home/vcap/app/Gemfile.lock\n" +
+				"ruby-checksum  /home/vcap/deps/0/bin/ruby\n",
+		}
+		exitCodes = map[string]int{}
+
+		client = &fakes.ExecClient{}
+		client.ContainerExecCreateCall.Stub = func(ctx gocontext.Context, containerID string, config types.ExecConfig) (types.IDResponse, error) {
+			execConfigs = append(execConfigs, config)
+			if config.Cmd[0] == "sh" {
+				return types.IDResponse{ID: "files"}, nil
+			}
+
+			return types.IDResponse{ID: "env"}, nil
+		}
+		client.ContainerExecAttachCall.Stub = func(ctx gocontext.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error) {
+			output := bytes.NewBuffer(nil)
+			_, err := stdcopy.NewStdWriter(output, stdcopy.Stdout).Write([]byte(outputs[execID]))
+			if err != nil {
+				return types.HijackedResponse{}, err
+			}
+
+			_, err = stdcopy.NewStdWriter(output, stdcopy.Stderr).Write([]byte("some-warning\n"))
+			if err != nil {
+				return types.HijackedResponse{}, err
+			}
+
+			conn, _ := net.Pipe()
+			return types.HijackedResponse{Conn: conn, Reader: bufio.NewReader(output)}, nil
+		}
+		client.ContainerExecInspectCall.Stub = func(ctx gocontext.Context, execID string) (types.ContainerExecInspect, error) {
+			return types.ContainerExecInspect{ExitCode: exitCodes[execID]}, nil
+		}
+
+		snapshotter = docker.NewEnvironmentSnapshotter(client)
+	})
+
+	it("captures the runtime environment and files in the application container", func() {
+		env, files, err := snapshotter.Snapshot(gocontext.Background(), "some-app")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(env).To(Equal(map[string]string{
+			"PATH":          "/usr/bin",
+			"VCAP_SERVICES": `{"some":"service"}`,
+			"MULTI":         "line\none",
+		}))
+		Expect(files).To(Equal(map[string]string{
+			"/home/vcap/app/Gemfile.lock": "some-checksum",
+			"/home/vcap/deps/0/bin/ruby":  "ruby-checksum",
+		}))
+
+		Expect(client.ContainerExecCreateCall.Receives.Container).To(Equal("some-app"))
+		Expect(execConfigs).To(Equal([]types.ExecConfig{
+			{
+				WorkingDir:   "/home/vcap",
+				Cmd:          []string{"/tmp/lifecycle/launcher", "app", "env -0", ""},
+				AttachStdout: true,
+				AttachStderr: true,
+			},
+			{
+				WorkingDir:   "/home/vcap",
+				Cmd:          []string{"sh", "-c", "find /home/vcap/app /home/vcap/deps -type f -print0 2>/dev/null | xargs -0 -r sha256sum"},
+				AttachStdout: true,
+				AttachStderr: true,
+			},
+		}))
+	})
+
+	context("failure cases", func() {
+		context("when the exec cannot be created", func() {
+			it.Before(func() {
+				client.ContainerExecCreateCall.Stub = nil
+				client.ContainerExecCreateCall.Returns.Error = errors.New("could not create exec")
+			})
+
+			it("returns an error", func() {
+				_, _, err := snapshotter.Snapshot(gocontext.Background(), "some-app")
+				Expect(err).To(MatchError("failed to read environment: failed to create exec: could not create exec"))
+			})
+		})
+
+		context("when the environment command fails", func() {
+			it.Before(func() {
+				exitCodes["env"] = 1
+			})
+
+			it("returns an error", func() {
+				_, _, err := snapshotter.Snapshot(gocontext.Background(), "some-app")
+				Expect(err).To(MatchError("failed to read environment: exit status 1\n\nOutput:\nsome-warning\n"))
+			})
+		})
+
+		context("when the files command fails", func() {
+			it.Before(func() {
+				exitCodes["files"] = 127
+			})
+
+			it("returns an error", func() {
+				_, _, err := snapshotter.Snapshot(gocontext.Background(), "some-app")
+				Expect(err).To(MatchError("failed to read files: exit status 127\n\nOutput:\nsome-warning\n"))
+			})
+		})
+	})
+}
@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+type EventRecorder func(operation, resource string, duration time.Duration, err error)
+
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9.]+/`)
+
+type EventTransport struct {
+	base   http.RoundTripper
+	record EventRecorder
+}
+
+func NewEventTransport(base http.RoundTripper, record EventRecorder) EventTransport {
+	return EventTransport{
+		base:   base,
+		record: record,
+	}
+}
+
+func (t EventTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	recordErr := err
+	if err == nil && resp.StatusCode >= 400 {
+		recordErr = fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	t.record(req.Method, apiVersionPrefix.ReplaceAllString(req.URL.Path, "/"), time.Since(start), recordErr)
+
+	return resp, err
+}
+
+func WithEventTransport(record EventRecorder) client.Opt {
+	return func(c *client.Client) error {
+		httpClient := c.HTTPClient()
+		if transport, ok := httpClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+			err := client.WithScheme("https")(c)
+			if err != nil {
+				return err
+			}
+		}
+
+		httpClient.Transport = NewEventTransport(httpClient.Transport, record)
+
+		return client.WithHTTPClient(httpClient)(c)
+	}
+}
@@ -0,0 +1,123 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/client"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type recordedEvent struct {
+	Operation string
+	Resource  string
+	Err       error
+}
+
+func testEventTransport(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		events []recordedEvent
+		record docker.EventRecorder
+	)
+
+	it.Before(func() {
+		events = nil
+		record = func(operation, resource string, duration time.Duration, err error) {
+			events = append(events, recordedEvent{Operation: operation, Resource: resource, Err: err})
+		}
+	})
+
+	context("RoundTrip", func() {
+		it("records each request", func() {
+			transport := docker.NewEventTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusCreated, Status: "201 Created"}, nil
+			}), record)
+
+			req, err := http.NewRequest("POST", "http://docker/v1.41/containers/create?name=some-app", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := transport.RoundTrip(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+
+			Expect(events).To(Equal([]recordedEvent{
+				{Operation: "POST", Resource: "/containers/create"},
+			}))
+		})
+
+		it("records requests that receive an error status", func() {
+			transport := docker.NewEventTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}, nil
+			}), record)
+
+			req, err := http.NewRequest("GET", "http://docker/containers/some-app/json", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := transport.RoundTrip(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Resource).To(Equal("/containers/some-app/json"))
+			Expect(events[0].Err).To(MatchError("unexpected response status: 404 Not Found"))
+		})
+
+		it("records requests that fail", func() {
+			transport := docker.NewEventTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			}), record)
+
+			req, err := http.NewRequest("GET", "http://docker/_ping", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = transport.RoundTrip(req)
+			Expect(err).To(MatchError("connection refused"))
+
+			Expect(events).To(Equal([]recordedEvent{
+				{Operation: "GET", Resource: "/_ping", Err: errors.New("connection refused")},
+			}))
+		})
+	})
+
+	context("WithEventTransport", func() {
+		var server *httptest.Server
+
+		it.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("API-Version", "1.41")
+				w.WriteHeader(http.StatusOK)
+			}))
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("records the requests made by the client", func() {
+			cli, err := client.NewClientWithOpts(client.WithHost(strings.Replace(server.URL, "http://", "tcp://", 1)), docker.WithEventTransport(record))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = cli.Ping(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Resource).To(Equal("/_ping"))
+		})
+	})
+}
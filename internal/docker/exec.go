@@ -0,0 +1,76 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+type Execer interface {
+	Exec(command ...string) (output string, err error)
+}
+
+//go:generate faux --interface ExecClient --output fakes/exec_client.go
+type ExecClient interface {
+	ContainerExecCreate(ctx context.Context, container string, config types.ExecConfig) (types.IDResponse, error)
+	ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error)
+	ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error)
+}
+
+type containerExecer struct {
+	ctx         context.Context
+	client      ExecClient
+	containerID string
+	user        string
+	workingDir  string
+}
+
+func (e containerExecer) Exec(command ...string) (string, error) {
+	buffer := bytes.NewBuffer(nil)
+	exitCode, err := e.run(command, buffer, buffer)
+	if err != nil {
+		return buffer.String(), err
+	}
+
+	if exitCode != 0 {
+		return buffer.String(), fmt.Errorf("failed to execute %q: exit status %d\n\nOutput:\n%s", strings.Join(command, " "), exitCode, buffer)
+	}
+
+	return buffer.String(), nil
+}
+
+func (e containerExecer) run(command []string, stdout, stderr io.Writer) (int, error) {
+	resp, err := e.client.ContainerExecCreate(e.ctx, e.containerID, types.ExecConfig{
+		User:         e.user,
+		WorkingDir:   e.workingDir,
+		Cmd:          command,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attach, err := e.client.ContainerExecAttach(e.ctx, resp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer attach.Close()
+
+	_, err = stdcopy.StdCopy(stdout, stderr, attach.Reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read exec output: %w", err)
+	}
+
+	inspect, err := e.client.ContainerExecInspect(e.ctx, resp.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect exec: %w", err)
+	}
+
+	return inspect.ExitCode, nil
+}
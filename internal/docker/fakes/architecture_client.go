@@ -0,0 +1,170 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type ArchitectureClient struct {
+	ContainerCreateCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx              context.Context
+			Config           *container.Config
+			HostConfig       *container.HostConfig
+			NetworkingConfig *network.NetworkingConfig
+			Platform         *v1.Platform
+			ContainerName    string
+		}
+		Returns struct {
+			CreateResponse container.CreateResponse
+			Error          error
+		}
+		Stub func(context.Context, *container.Config, *container.HostConfig, *network.NetworkingConfig, *v1.Platform, string) (container.CreateResponse, error)
+	}
+	ContainerRemoveCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     types.ContainerRemoveOptions
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, types.ContainerRemoveOptions) error
+	}
+	ContainerStartCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     types.ContainerStartOptions
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, types.ContainerStartOptions) error
+	}
+	ContainerWaitCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Condition   container.WaitCondition
+		}
+		Returns struct {
+			WaitResponseChannel <-chan container.WaitResponse
+			ErrorChannel        <-chan error
+		}
+		Stub func(context.Context, string, container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+	}
+	ImageInspectWithRawCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			ImageID string
+		}
+		Returns struct {
+			ImageInspect types.ImageInspect
+			ByteSlice    []byte
+			Error        error
+		}
+		Stub func(context.Context, string) (types.ImageInspect, []byte, error)
+	}
+	InfoCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx context.Context
+		}
+		Returns struct {
+			Info  types.Info
+			Error error
+		}
+		Stub func(context.Context) (types.Info, error)
+	}
+}
+
+func (f *ArchitectureClient) ContainerCreate(param1 context.Context, param2 *container.Config, param3 *container.HostConfig, param4 *network.NetworkingConfig, param5 *v1.Platform, param6 string) (container.CreateResponse, error) {
+	f.ContainerCreateCall.mutex.Lock()
+	defer f.ContainerCreateCall.mutex.Unlock()
+	f.ContainerCreateCall.CallCount++
+	f.ContainerCreateCall.Receives.Ctx = param1
+	f.ContainerCreateCall.Receives.Config = param2
+	f.ContainerCreateCall.Receives.HostConfig = param3
+	f.ContainerCreateCall.Receives.NetworkingConfig = param4
+	f.ContainerCreateCall.Receives.Platform = param5
+	f.ContainerCreateCall.Receives.ContainerName = param6
+	if f.ContainerCreateCall.Stub != nil {
+		return f.ContainerCreateCall.Stub(param1, param2, param3, param4, param5, param6)
+	}
+	return f.ContainerCreateCall.Returns.CreateResponse, f.ContainerCreateCall.Returns.Error
+}
+func (f *ArchitectureClient) ContainerRemove(param1 context.Context, param2 string, param3 types.ContainerRemoveOptions) error {
+	f.ContainerRemoveCall.mutex.Lock()
+	defer f.ContainerRemoveCall.mutex.Unlock()
+	f.ContainerRemoveCall.CallCount++
+	f.ContainerRemoveCall.Receives.Ctx = param1
+	f.ContainerRemoveCall.Receives.ContainerID = param2
+	f.ContainerRemoveCall.Receives.Options = param3
+	if f.ContainerRemoveCall.Stub != nil {
+		return f.ContainerRemoveCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerRemoveCall.Returns.Error
+}
+func (f *ArchitectureClient) ContainerStart(param1 context.Context, param2 string, param3 types.ContainerStartOptions) error {
+	f.ContainerStartCall.mutex.Lock()
+	defer f.ContainerStartCall.mutex.Unlock()
+	f.ContainerStartCall.CallCount++
+	f.ContainerStartCall.Receives.Ctx = param1
+	f.ContainerStartCall.Receives.ContainerID = param2
+	f.ContainerStartCall.Receives.Options = param3
+	if f.ContainerStartCall.Stub != nil {
+		return f.ContainerStartCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerStartCall.Returns.Error
+}
+func (f *ArchitectureClient) ContainerWait(param1 context.Context, param2 string, param3 container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	f.ContainerWaitCall.mutex.Lock()
+	defer f.ContainerWaitCall.mutex.Unlock()
+	f.ContainerWaitCall.CallCount++
+	f.ContainerWaitCall.Receives.Ctx = param1
+	f.ContainerWaitCall.Receives.ContainerID = param2
+	f.ContainerWaitCall.Receives.Condition = param3
+	if f.ContainerWaitCall.Stub != nil {
+		return f.ContainerWaitCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerWaitCall.Returns.WaitResponseChannel, f.ContainerWaitCall.Returns.ErrorChannel
+}
+func (f *ArchitectureClient) ImageInspectWithRaw(param1 context.Context, param2 string) (types.ImageInspect, []byte, error) {
+	f.ImageInspectWithRawCall.mutex.Lock()
+	defer f.ImageInspectWithRawCall.mutex.Unlock()
+	f.ImageInspectWithRawCall.CallCount++
+	f.ImageInspectWithRawCall.Receives.Ctx = param1
+	f.ImageInspectWithRawCall.Receives.ImageID = param2
+	if f.ImageInspectWithRawCall.Stub != nil {
+		return f.ImageInspectWithRawCall.Stub(param1, param2)
+	}
+	return f.ImageInspectWithRawCall.Returns.ImageInspect, f.ImageInspectWithRawCall.Returns.ByteSlice, f.ImageInspectWithRawCall.Returns.Error
+}
+func (f *ArchitectureClient) Info(param1 context.Context) (types.Info, error) {
+	f.InfoCall.mutex.Lock()
+	defer f.InfoCall.mutex.Unlock()
+	f.InfoCall.CallCount++
+	f.InfoCall.Receives.Ctx = param1
+	if f.InfoCall.Stub != nil {
+		return f.InfoCall.Stub(param1)
+	}
+	return f.InfoCall.Returns.Info, f.InfoCall.Returns.Error
+}
@@ -1,6 +1,7 @@
 package fakes
 
 import (
+	"io"
 	"sync"
 
 	"github.com/cloudfoundry/switchblade/internal/docker"
@@ -19,6 +20,42 @@ type Archiver struct {
 		}
 		Stub func(string, string) error
 	}
+	DigestCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Input string
+		}
+		Returns struct {
+			String string
+			Error  error
+		}
+		Stub func(string) (string, error)
+	}
+	StreamCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Input  string
+			Output io.Writer
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(string, io.Writer) error
+	}
+	StreamTarCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Input  io.Reader
+			Output io.Writer
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(io.Reader, io.Writer) error
+	}
 	WithPrefixCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -43,6 +80,38 @@ func (f *Archiver) Compress(param1 string, param2 string) error {
 	}
 	return f.CompressCall.Returns.Error
 }
+func (f *Archiver) Digest(param1 string) (string, error) {
+	f.DigestCall.mutex.Lock()
+	defer f.DigestCall.mutex.Unlock()
+	f.DigestCall.CallCount++
+	f.DigestCall.Receives.Input = param1
+	if f.DigestCall.Stub != nil {
+		return f.DigestCall.Stub(param1)
+	}
+	return f.DigestCall.Returns.String, f.DigestCall.Returns.Error
+}
+func (f *Archiver) Stream(param1 string, param2 io.Writer) error {
+	f.StreamCall.mutex.Lock()
+	defer f.StreamCall.mutex.Unlock()
+	f.StreamCall.CallCount++
+	f.StreamCall.Receives.Input = param1
+	f.StreamCall.Receives.Output = param2
+	if f.StreamCall.Stub != nil {
+		return f.StreamCall.Stub(param1, param2)
+	}
+	return f.StreamCall.Returns.Error
+}
+func (f *Archiver) StreamTar(param1 io.Reader, param2 io.Writer) error {
+	f.StreamTarCall.mutex.Lock()
+	defer f.StreamTarCall.mutex.Unlock()
+	f.StreamTarCall.CallCount++
+	f.StreamTarCall.Receives.Input = param1
+	f.StreamTarCall.Receives.Output = param2
+	if f.StreamTarCall.Stub != nil {
+		return f.StreamTarCall.Stub(param1, param2)
+	}
+	return f.StreamTarCall.Returns.Error
+}
 func (f *Archiver) WithPrefix(param1 string) docker.Archiver {
 	f.WithPrefixCall.mutex.Lock()
 	defer f.WithPrefixCall.mutex.Unlock()
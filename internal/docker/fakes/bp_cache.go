@@ -1,6 +1,7 @@
 package fakes
 
 import (
+	"context"
 	"io"
 	"sync"
 )
@@ -10,23 +11,25 @@ type BPCache struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
+			Ctx context.Context
 			Url string
 		}
 		Returns struct {
 			ReadCloser io.ReadCloser
 			Error      error
 		}
-		Stub func(string) (io.ReadCloser, error)
+		Stub func(context.Context, string) (io.ReadCloser, error)
 	}
 }
 
-func (f *BPCache) Fetch(param1 string) (io.ReadCloser, error) {
+func (f *BPCache) Fetch(param1 context.Context, param2 string) (io.ReadCloser, error) {
 	f.FetchCall.mutex.Lock()
 	defer f.FetchCall.mutex.Unlock()
 	f.FetchCall.CallCount++
-	f.FetchCall.Receives.Url = param1
+	f.FetchCall.Receives.Ctx = param1
+	f.FetchCall.Receives.Url = param2
 	if f.FetchCall.Stub != nil {
-		return f.FetchCall.Stub(param1)
+		return f.FetchCall.Stub(param1, param2)
 	}
 	return f.FetchCall.Returns.ReadCloser, f.FetchCall.Returns.Error
 }
@@ -24,6 +24,18 @@ type BPRegistry struct {
 		}
 		Stub func(...docker.Buildpack)
 	}
+	ResolveCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Name string
+		}
+		Returns struct {
+			Buildpack docker.Buildpack
+			Error     error
+		}
+		Stub func(string) (docker.Buildpack, error)
+	}
 }
 
 func (f *BPRegistry) List() ([]docker.Buildpack, error) {
@@ -44,3 +56,13 @@ func (f *BPRegistry) Override(param1 ...docker.Buildpack) {
 		f.OverrideCall.Stub(param1...)
 	}
 }
+func (f *BPRegistry) Resolve(param1 string) (docker.Buildpack, error) {
+	f.ResolveCall.mutex.Lock()
+	defer f.ResolveCall.mutex.Unlock()
+	f.ResolveCall.CallCount++
+	f.ResolveCall.Receives.Name = param1
+	if f.ResolveCall.Stub != nil {
+		return f.ResolveCall.Stub(param1)
+	}
+	return f.ResolveCall.Returns.Buildpack, f.ResolveCall.Returns.Error
+}
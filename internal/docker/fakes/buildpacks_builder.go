@@ -1,6 +1,7 @@
 package fakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/cloudfoundry/switchblade/internal/docker"
@@ -11,6 +12,7 @@ type BuildpacksBuilder struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
+			Ctx       context.Context
 			Workspace string
 			Name      string
 		}
@@ -18,7 +20,7 @@ type BuildpacksBuilder struct {
 			Path string
 			Err  error
 		}
-		Stub func(string, string) (string, error)
+		Stub func(context.Context, string, string) (string, error)
 	}
 	OrderCall struct {
 		mutex     sync.Mutex
@@ -43,14 +45,15 @@ type BuildpacksBuilder struct {
 	}
 }
 
-func (f *BuildpacksBuilder) Build(param1 string, param2 string) (string, error) {
+func (f *BuildpacksBuilder) Build(param1 context.Context, param2 string, param3 string) (string, error) {
 	f.BuildCall.mutex.Lock()
 	defer f.BuildCall.mutex.Unlock()
 	f.BuildCall.CallCount++
-	f.BuildCall.Receives.Workspace = param1
-	f.BuildCall.Receives.Name = param2
+	f.BuildCall.Receives.Ctx = param1
+	f.BuildCall.Receives.Workspace = param2
+	f.BuildCall.Receives.Name = param3
 	if f.BuildCall.Stub != nil {
-		return f.BuildCall.Stub(param1, param2)
+		return f.BuildCall.Stub(param1, param2, param3)
 	}
 	return f.BuildCall.Returns.Path, f.BuildCall.Returns.Err
 }
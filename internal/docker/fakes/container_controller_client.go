@@ -0,0 +1,62 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+type ContainerControllerClient struct {
+	ContainerKillCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Signal      string
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, string) error
+	}
+	ContainerRestartCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     container.StopOptions
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, container.StopOptions) error
+	}
+}
+
+func (f *ContainerControllerClient) ContainerKill(param1 context.Context, param2 string, param3 string) error {
+	f.ContainerKillCall.mutex.Lock()
+	defer f.ContainerKillCall.mutex.Unlock()
+	f.ContainerKillCall.CallCount++
+	f.ContainerKillCall.Receives.Ctx = param1
+	f.ContainerKillCall.Receives.ContainerID = param2
+	f.ContainerKillCall.Receives.Signal = param3
+	if f.ContainerKillCall.Stub != nil {
+		return f.ContainerKillCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerKillCall.Returns.Error
+}
+func (f *ContainerControllerClient) ContainerRestart(param1 context.Context, param2 string, param3 container.StopOptions) error {
+	f.ContainerRestartCall.mutex.Lock()
+	defer f.ContainerRestartCall.mutex.Unlock()
+	f.ContainerRestartCall.CallCount++
+	f.ContainerRestartCall.Receives.Ctx = param1
+	f.ContainerRestartCall.Receives.ContainerID = param2
+	f.ContainerRestartCall.Receives.Options = param3
+	if f.ContainerRestartCall.Stub != nil {
+		return f.ContainerRestartCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerRestartCall.Returns.Error
+}
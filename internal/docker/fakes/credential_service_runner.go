@@ -0,0 +1,44 @@
+package fakes
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+type CredentialServiceRunner struct {
+	RunCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			Logs        io.Writer
+			Name        string
+			Stack       string
+			Credentials map[string]interface {
+			}
+		}
+		Returns struct {
+			Url string
+			Err error
+		}
+		Stub func(context.Context, io.Writer, string, string, map[string]interface {
+		}) (string, error)
+	}
+}
+
+func (f *CredentialServiceRunner) Run(param1 context.Context, param2 io.Writer, param3 string, param4 string, param5 map[string]interface {
+}) (string, error) {
+	f.RunCall.mutex.Lock()
+	defer f.RunCall.mutex.Unlock()
+	f.RunCall.CallCount++
+	f.RunCall.Receives.Ctx = param1
+	f.RunCall.Receives.Logs = param2
+	f.RunCall.Receives.Name = param3
+	f.RunCall.Receives.Stack = param4
+	f.RunCall.Receives.Credentials = param5
+	if f.RunCall.Stub != nil {
+		return f.RunCall.Stub(param1, param2, param3, param4, param5)
+	}
+	return f.RunCall.Returns.Url, f.RunCall.Returns.Err
+}
@@ -0,0 +1,51 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+type DaemonSocketClient struct {
+	DaemonHostCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			String string
+		}
+		Stub func() string
+	}
+	InfoCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx context.Context
+		}
+		Returns struct {
+			Info  types.Info
+			Error error
+		}
+		Stub func(context.Context) (types.Info, error)
+	}
+}
+
+func (f *DaemonSocketClient) DaemonHost() string {
+	f.DaemonHostCall.mutex.Lock()
+	defer f.DaemonHostCall.mutex.Unlock()
+	f.DaemonHostCall.CallCount++
+	if f.DaemonHostCall.Stub != nil {
+		return f.DaemonHostCall.Stub()
+	}
+	return f.DaemonHostCall.Returns.String
+}
+func (f *DaemonSocketClient) Info(param1 context.Context) (types.Info, error) {
+	f.InfoCall.mutex.Lock()
+	defer f.InfoCall.mutex.Unlock()
+	f.InfoCall.CallCount++
+	f.InfoCall.Receives.Ctx = param1
+	if f.InfoCall.Stub != nil {
+		return f.InfoCall.Stub(param1)
+	}
+	return f.InfoCall.Returns.Info, f.InfoCall.Returns.Error
+}
@@ -0,0 +1,65 @@
+package fakes
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+type DropletPusherClient struct {
+	ContainerCommitCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx       context.Context
+			Container string
+			Options   types.ContainerCommitOptions
+		}
+		Returns struct {
+			IDResponse types.IDResponse
+			Error      error
+		}
+		Stub func(context.Context, string, types.ContainerCommitOptions) (types.IDResponse, error)
+	}
+	ImagePushCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			Image   string
+			Options types.ImagePushOptions
+		}
+		Returns struct {
+			ReadCloser io.ReadCloser
+			Error      error
+		}
+		Stub func(context.Context, string, types.ImagePushOptions) (io.ReadCloser, error)
+	}
+}
+
+func (f *DropletPusherClient) ContainerCommit(param1 context.Context, param2 string, param3 types.ContainerCommitOptions) (types.IDResponse, error) {
+	f.ContainerCommitCall.mutex.Lock()
+	defer f.ContainerCommitCall.mutex.Unlock()
+	f.ContainerCommitCall.CallCount++
+	f.ContainerCommitCall.Receives.Ctx = param1
+	f.ContainerCommitCall.Receives.Container = param2
+	f.ContainerCommitCall.Receives.Options = param3
+	if f.ContainerCommitCall.Stub != nil {
+		return f.ContainerCommitCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerCommitCall.Returns.IDResponse, f.ContainerCommitCall.Returns.Error
+}
+func (f *DropletPusherClient) ImagePush(param1 context.Context, param2 string, param3 types.ImagePushOptions) (io.ReadCloser, error) {
+	f.ImagePushCall.mutex.Lock()
+	defer f.ImagePushCall.mutex.Unlock()
+	f.ImagePushCall.CallCount++
+	f.ImagePushCall.Receives.Ctx = param1
+	f.ImagePushCall.Receives.Image = param2
+	f.ImagePushCall.Receives.Options = param3
+	if f.ImagePushCall.Stub != nil {
+		return f.ImagePushCall.Stub(param1, param2, param3)
+	}
+	return f.ImagePushCall.Returns.ReadCloser, f.ImagePushCall.Returns.Error
+}
@@ -0,0 +1,88 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+type ExecClient struct {
+	ContainerExecAttachCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx    context.Context
+			ExecID string
+			Config types.ExecStartCheck
+		}
+		Returns struct {
+			HijackedResponse types.HijackedResponse
+			Error            error
+		}
+		Stub func(context.Context, string, types.ExecStartCheck) (types.HijackedResponse, error)
+	}
+	ContainerExecCreateCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx       context.Context
+			Container string
+			Config    types.ExecConfig
+		}
+		Returns struct {
+			IDResponse types.IDResponse
+			Error      error
+		}
+		Stub func(context.Context, string, types.ExecConfig) (types.IDResponse, error)
+	}
+	ContainerExecInspectCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx    context.Context
+			ExecID string
+		}
+		Returns struct {
+			ContainerExecInspect types.ContainerExecInspect
+			Error                error
+		}
+		Stub func(context.Context, string) (types.ContainerExecInspect, error)
+	}
+}
+
+func (f *ExecClient) ContainerExecAttach(param1 context.Context, param2 string, param3 types.ExecStartCheck) (types.HijackedResponse, error) {
+	f.ContainerExecAttachCall.mutex.Lock()
+	defer f.ContainerExecAttachCall.mutex.Unlock()
+	f.ContainerExecAttachCall.CallCount++
+	f.ContainerExecAttachCall.Receives.Ctx = param1
+	f.ContainerExecAttachCall.Receives.ExecID = param2
+	f.ContainerExecAttachCall.Receives.Config = param3
+	if f.ContainerExecAttachCall.Stub != nil {
+		return f.ContainerExecAttachCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerExecAttachCall.Returns.HijackedResponse, f.ContainerExecAttachCall.Returns.Error
+}
+func (f *ExecClient) ContainerExecCreate(param1 context.Context, param2 string, param3 types.ExecConfig) (types.IDResponse, error) {
+	f.ContainerExecCreateCall.mutex.Lock()
+	defer f.ContainerExecCreateCall.mutex.Unlock()
+	f.ContainerExecCreateCall.CallCount++
+	f.ContainerExecCreateCall.Receives.Ctx = param1
+	f.ContainerExecCreateCall.Receives.Container = param2
+	f.ContainerExecCreateCall.Receives.Config = param3
+	if f.ContainerExecCreateCall.Stub != nil {
+		return f.ContainerExecCreateCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerExecCreateCall.Returns.IDResponse, f.ContainerExecCreateCall.Returns.Error
+}
+func (f *ExecClient) ContainerExecInspect(param1 context.Context, param2 string) (types.ContainerExecInspect, error) {
+	f.ContainerExecInspectCall.mutex.Lock()
+	defer f.ContainerExecInspectCall.mutex.Unlock()
+	f.ContainerExecInspectCall.CallCount++
+	f.ContainerExecInspectCall.Receives.Ctx = param1
+	f.ContainerExecInspectCall.Receives.ExecID = param2
+	if f.ContainerExecInspectCall.Stub != nil {
+		return f.ContainerExecInspectCall.Stub(param1, param2)
+	}
+	return f.ContainerExecInspectCall.Returns.ContainerExecInspect, f.ContainerExecInspectCall.Returns.Error
+}
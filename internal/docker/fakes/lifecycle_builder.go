@@ -1,12 +1,16 @@
 package fakes
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 type LifecycleBuilder struct {
 	BuildCall struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
+			Ctx       context.Context
 			SourceURI string
 			Workspace string
 		}
@@ -14,18 +18,19 @@ type LifecycleBuilder struct {
 			Path string
 			Err  error
 		}
-		Stub func(string, string) (string, error)
+		Stub func(context.Context, string, string) (string, error)
 	}
 }
 
-func (f *LifecycleBuilder) Build(param1 string, param2 string) (string, error) {
+func (f *LifecycleBuilder) Build(param1 context.Context, param2 string, param3 string) (string, error) {
 	f.BuildCall.mutex.Lock()
 	defer f.BuildCall.mutex.Unlock()
 	f.BuildCall.CallCount++
-	f.BuildCall.Receives.SourceURI = param1
-	f.BuildCall.Receives.Workspace = param2
+	f.BuildCall.Receives.Ctx = param1
+	f.BuildCall.Receives.SourceURI = param2
+	f.BuildCall.Receives.Workspace = param3
 	if f.BuildCall.Stub != nil {
-		return f.BuildCall.Stub(param1, param2)
+		return f.BuildCall.Stub(param1, param2, param3)
 	}
 	return f.BuildCall.Returns.Path, f.BuildCall.Returns.Err
 }
@@ -0,0 +1,39 @@
+package fakes
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+type LogStreamerClient struct {
+	ContainerLogsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx       context.Context
+			Container string
+			Options   types.ContainerLogsOptions
+		}
+		Returns struct {
+			ReadCloser io.ReadCloser
+			Error      error
+		}
+		Stub func(context.Context, string, types.ContainerLogsOptions) (io.ReadCloser, error)
+	}
+}
+
+func (f *LogStreamerClient) ContainerLogs(param1 context.Context, param2 string, param3 types.ContainerLogsOptions) (io.ReadCloser, error) {
+	f.ContainerLogsCall.mutex.Lock()
+	defer f.ContainerLogsCall.mutex.Unlock()
+	f.ContainerLogsCall.CallCount++
+	f.ContainerLogsCall.Receives.Ctx = param1
+	f.ContainerLogsCall.Receives.Container = param2
+	f.ContainerLogsCall.Receives.Options = param3
+	if f.ContainerLogsCall.Stub != nil {
+		return f.ContainerLogsCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerLogsCall.Returns.ReadCloser, f.ContainerLogsCall.Returns.Error
+}
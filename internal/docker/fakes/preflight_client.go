@@ -0,0 +1,108 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
+)
+
+type PreflightClient struct {
+	DistributionInspectCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx                 context.Context
+			Image               string
+			EncodedRegistryAuth string
+		}
+		Returns struct {
+			DistributionInspect registry.DistributionInspect
+			Error               error
+		}
+		Stub func(context.Context, string, string) (registry.DistributionInspect, error)
+	}
+	ImageInspectWithRawCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			ImageID string
+		}
+		Returns struct {
+			ImageInspect types.ImageInspect
+			ByteSlice    []byte
+			Error        error
+		}
+		Stub func(context.Context, string) (types.ImageInspect, []byte, error)
+	}
+	PingCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx context.Context
+		}
+		Returns struct {
+			Ping  types.Ping
+			Error error
+		}
+		Stub func(context.Context) (types.Ping, error)
+	}
+	ServerVersionCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx context.Context
+		}
+		Returns struct {
+			Version types.Version
+			Error   error
+		}
+		Stub func(context.Context) (types.Version, error)
+	}
+}
+
+func (f *PreflightClient) DistributionInspect(param1 context.Context, param2 string, param3 string) (registry.DistributionInspect, error) {
+	f.DistributionInspectCall.mutex.Lock()
+	defer f.DistributionInspectCall.mutex.Unlock()
+	f.DistributionInspectCall.CallCount++
+	f.DistributionInspectCall.Receives.Ctx = param1
+	f.DistributionInspectCall.Receives.Image = param2
+	f.DistributionInspectCall.Receives.EncodedRegistryAuth = param3
+	if f.DistributionInspectCall.Stub != nil {
+		return f.DistributionInspectCall.Stub(param1, param2, param3)
+	}
+	return f.DistributionInspectCall.Returns.DistributionInspect, f.DistributionInspectCall.Returns.Error
+}
+func (f *PreflightClient) ImageInspectWithRaw(param1 context.Context, param2 string) (types.ImageInspect, []byte, error) {
+	f.ImageInspectWithRawCall.mutex.Lock()
+	defer f.ImageInspectWithRawCall.mutex.Unlock()
+	f.ImageInspectWithRawCall.CallCount++
+	f.ImageInspectWithRawCall.Receives.Ctx = param1
+	f.ImageInspectWithRawCall.Receives.ImageID = param2
+	if f.ImageInspectWithRawCall.Stub != nil {
+		return f.ImageInspectWithRawCall.Stub(param1, param2)
+	}
+	return f.ImageInspectWithRawCall.Returns.ImageInspect, f.ImageInspectWithRawCall.Returns.ByteSlice, f.ImageInspectWithRawCall.Returns.Error
+}
+func (f *PreflightClient) Ping(param1 context.Context) (types.Ping, error) {
+	f.PingCall.mutex.Lock()
+	defer f.PingCall.mutex.Unlock()
+	f.PingCall.CallCount++
+	f.PingCall.Receives.Ctx = param1
+	if f.PingCall.Stub != nil {
+		return f.PingCall.Stub(param1)
+	}
+	return f.PingCall.Returns.Ping, f.PingCall.Returns.Error
+}
+func (f *PreflightClient) ServerVersion(param1 context.Context) (types.Version, error) {
+	f.ServerVersionCall.mutex.Lock()
+	defer f.ServerVersionCall.mutex.Unlock()
+	f.ServerVersionCall.CallCount++
+	f.ServerVersionCall.Receives.Ctx = param1
+	if f.ServerVersionCall.Stub != nil {
+		return f.ServerVersionCall.Stub(param1)
+	}
+	return f.ServerVersionCall.Returns.Version, f.ServerVersionCall.Returns.Error
+}
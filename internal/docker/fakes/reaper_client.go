@@ -0,0 +1,123 @@
+package fakes
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type ReaperClient struct {
+	ContainerCreateCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx              context.Context
+			Config           *container.Config
+			HostConfig       *container.HostConfig
+			NetworkingConfig *network.NetworkingConfig
+			Platform         *v1.Platform
+			ContainerName    string
+		}
+		Returns struct {
+			CreateResponse container.CreateResponse
+			Error          error
+		}
+		Stub func(context.Context, *container.Config, *container.HostConfig, *network.NetworkingConfig, *v1.Platform, string) (container.CreateResponse, error)
+	}
+	ContainerInspectCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+		}
+		Returns struct {
+			ContainerJSON types.ContainerJSON
+			Error         error
+		}
+		Stub func(context.Context, string) (types.ContainerJSON, error)
+	}
+	ContainerStartCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     types.ContainerStartOptions
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, types.ContainerStartOptions) error
+	}
+	ImagePullCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			Ref     string
+			Options types.ImagePullOptions
+		}
+		Returns struct {
+			ReadCloser io.ReadCloser
+			Error      error
+		}
+		Stub func(context.Context, string, types.ImagePullOptions) (io.ReadCloser, error)
+	}
+}
+
+func (f *ReaperClient) ContainerCreate(param1 context.Context, param2 *container.Config, param3 *container.HostConfig, param4 *network.NetworkingConfig, param5 *v1.Platform, param6 string) (container.CreateResponse, error) {
+	f.ContainerCreateCall.mutex.Lock()
+	defer f.ContainerCreateCall.mutex.Unlock()
+	f.ContainerCreateCall.CallCount++
+	f.ContainerCreateCall.Receives.Ctx = param1
+	f.ContainerCreateCall.Receives.Config = param2
+	f.ContainerCreateCall.Receives.HostConfig = param3
+	f.ContainerCreateCall.Receives.NetworkingConfig = param4
+	f.ContainerCreateCall.Receives.Platform = param5
+	f.ContainerCreateCall.Receives.ContainerName = param6
+	if f.ContainerCreateCall.Stub != nil {
+		return f.ContainerCreateCall.Stub(param1, param2, param3, param4, param5, param6)
+	}
+	return f.ContainerCreateCall.Returns.CreateResponse, f.ContainerCreateCall.Returns.Error
+}
+func (f *ReaperClient) ContainerInspect(param1 context.Context, param2 string) (types.ContainerJSON, error) {
+	f.ContainerInspectCall.mutex.Lock()
+	defer f.ContainerInspectCall.mutex.Unlock()
+	f.ContainerInspectCall.CallCount++
+	f.ContainerInspectCall.Receives.Ctx = param1
+	f.ContainerInspectCall.Receives.ContainerID = param2
+	if f.ContainerInspectCall.Stub != nil {
+		return f.ContainerInspectCall.Stub(param1, param2)
+	}
+	return f.ContainerInspectCall.Returns.ContainerJSON, f.ContainerInspectCall.Returns.Error
+}
+func (f *ReaperClient) ContainerStart(param1 context.Context, param2 string, param3 types.ContainerStartOptions) error {
+	f.ContainerStartCall.mutex.Lock()
+	defer f.ContainerStartCall.mutex.Unlock()
+	f.ContainerStartCall.CallCount++
+	f.ContainerStartCall.Receives.Ctx = param1
+	f.ContainerStartCall.Receives.ContainerID = param2
+	f.ContainerStartCall.Receives.Options = param3
+	if f.ContainerStartCall.Stub != nil {
+		return f.ContainerStartCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerStartCall.Returns.Error
+}
+func (f *ReaperClient) ImagePull(param1 context.Context, param2 string, param3 types.ImagePullOptions) (io.ReadCloser, error) {
+	f.ImagePullCall.mutex.Lock()
+	defer f.ImagePullCall.mutex.Unlock()
+	f.ImagePullCall.CallCount++
+	f.ImagePullCall.Receives.Ctx = param1
+	f.ImagePullCall.Receives.Ref = param2
+	f.ImagePullCall.Receives.Options = param3
+	if f.ImagePullCall.Stub != nil {
+		return f.ImagePullCall.Stub(param1, param2, param3)
+	}
+	return f.ImagePullCall.Returns.ReadCloser, f.ImagePullCall.Returns.Error
+}
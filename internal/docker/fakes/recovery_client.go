@@ -0,0 +1,86 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+type RecoveryClient struct {
+	ContainerRemoveCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     types.ContainerRemoveOptions
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, types.ContainerRemoveOptions) error
+	}
+	ImageRemoveCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			ImageID string
+			Options types.ImageRemoveOptions
+		}
+		Returns struct {
+			ImageDeleteResponseItemSlice []types.ImageDeleteResponseItem
+			Error                        error
+		}
+		Stub func(context.Context, string, types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+	}
+	NetworkRemoveCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx       context.Context
+			NetworkID string
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string) error
+	}
+}
+
+func (f *RecoveryClient) ContainerRemove(param1 context.Context, param2 string, param3 types.ContainerRemoveOptions) error {
+	f.ContainerRemoveCall.mutex.Lock()
+	defer f.ContainerRemoveCall.mutex.Unlock()
+	f.ContainerRemoveCall.CallCount++
+	f.ContainerRemoveCall.Receives.Ctx = param1
+	f.ContainerRemoveCall.Receives.ContainerID = param2
+	f.ContainerRemoveCall.Receives.Options = param3
+	if f.ContainerRemoveCall.Stub != nil {
+		return f.ContainerRemoveCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerRemoveCall.Returns.Error
+}
+func (f *RecoveryClient) ImageRemove(param1 context.Context, param2 string, param3 types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	f.ImageRemoveCall.mutex.Lock()
+	defer f.ImageRemoveCall.mutex.Unlock()
+	f.ImageRemoveCall.CallCount++
+	f.ImageRemoveCall.Receives.Ctx = param1
+	f.ImageRemoveCall.Receives.ImageID = param2
+	f.ImageRemoveCall.Receives.Options = param3
+	if f.ImageRemoveCall.Stub != nil {
+		return f.ImageRemoveCall.Stub(param1, param2, param3)
+	}
+	return f.ImageRemoveCall.Returns.ImageDeleteResponseItemSlice, f.ImageRemoveCall.Returns.Error
+}
+func (f *RecoveryClient) NetworkRemove(param1 context.Context, param2 string) error {
+	f.NetworkRemoveCall.mutex.Lock()
+	defer f.NetworkRemoveCall.mutex.Unlock()
+	f.NetworkRemoveCall.CallCount++
+	f.NetworkRemoveCall.Receives.Ctx = param1
+	f.NetworkRemoveCall.Receives.NetworkID = param2
+	if f.NetworkRemoveCall.Stub != nil {
+		return f.NetworkRemoveCall.Stub(param1, param2)
+	}
+	return f.NetworkRemoveCall.Returns.Error
+}
@@ -0,0 +1,34 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+type RuntimeClient struct {
+	InfoCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx context.Context
+		}
+		Returns struct {
+			Info  types.Info
+			Error error
+		}
+		Stub func(context.Context) (types.Info, error)
+	}
+}
+
+func (f *RuntimeClient) Info(param1 context.Context) (types.Info, error) {
+	f.InfoCall.mutex.Lock()
+	defer f.InfoCall.mutex.Unlock()
+	f.InfoCall.CallCount++
+	f.InfoCall.Receives.Ctx = param1
+	if f.InfoCall.Stub != nil {
+		return f.InfoCall.Stub(param1)
+	}
+	return f.InfoCall.Returns.Info, f.InfoCall.Returns.Error
+}
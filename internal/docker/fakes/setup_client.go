@@ -12,6 +12,20 @@ import (
 )
 
 type SetupClient struct {
+	ContainerCommitCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     types.ContainerCommitOptions
+		}
+		Returns struct {
+			IDResponse types.IDResponse
+			Error      error
+		}
+		Stub func(context.Context, string, types.ContainerCommitOptions) (types.IDResponse, error)
+	}
 	ContainerCreateCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -29,6 +43,47 @@ type SetupClient struct {
 		}
 		Stub func(context.Context, *container.Config, *container.HostConfig, *network.NetworkingConfig, *v1.Platform, string) (container.CreateResponse, error)
 	}
+	ContainerExecAttachCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx    context.Context
+			ExecID string
+			Config types.ExecStartCheck
+		}
+		Returns struct {
+			HijackedResponse types.HijackedResponse
+			Error            error
+		}
+		Stub func(context.Context, string, types.ExecStartCheck) (types.HijackedResponse, error)
+	}
+	ContainerExecCreateCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx       context.Context
+			Container string
+			Config    types.ExecConfig
+		}
+		Returns struct {
+			IDResponse types.IDResponse
+			Error      error
+		}
+		Stub func(context.Context, string, types.ExecConfig) (types.IDResponse, error)
+	}
+	ContainerExecInspectCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx    context.Context
+			ExecID string
+		}
+		Returns struct {
+			ContainerExecInspect types.ContainerExecInspect
+			Error                error
+		}
+		Stub func(context.Context, string) (types.ContainerExecInspect, error)
+	}
 	ContainerInspectCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -55,6 +110,46 @@ type SetupClient struct {
 		}
 		Stub func(context.Context, string, types.ContainerRemoveOptions) error
 	}
+	ContainerRenameCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx              context.Context
+			ContainerID      string
+			NewContainerName string
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, string) error
+	}
+	ContainerStartCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     types.ContainerStartOptions
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, types.ContainerStartOptions) error
+	}
+	ContainerWaitCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Condition   container.WaitCondition
+		}
+		Returns struct {
+			WaitResponseChannel <-chan container.WaitResponse
+			ErrorChannel        <-chan error
+		}
+		Stub func(context.Context, string, container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+	}
 	CopyToContainerCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -70,6 +165,20 @@ type SetupClient struct {
 		}
 		Stub func(context.Context, string, string, io.Reader, types.CopyToContainerOptions) error
 	}
+	ImageInspectWithRawCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			ImageID string
+		}
+		Returns struct {
+			ImageInspect types.ImageInspect
+			ByteSlice    []byte
+			Error        error
+		}
+		Stub func(context.Context, string) (types.ImageInspect, []byte, error)
+	}
 	ImagePullCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -84,8 +193,32 @@ type SetupClient struct {
 		}
 		Stub func(context.Context, string, types.ImagePullOptions) (io.ReadCloser, error)
 	}
+	InfoCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx context.Context
+		}
+		Returns struct {
+			Info  types.Info
+			Error error
+		}
+		Stub func(context.Context) (types.Info, error)
+	}
 }
 
+func (f *SetupClient) ContainerCommit(param1 context.Context, param2 string, param3 types.ContainerCommitOptions) (types.IDResponse, error) {
+	f.ContainerCommitCall.mutex.Lock()
+	defer f.ContainerCommitCall.mutex.Unlock()
+	f.ContainerCommitCall.CallCount++
+	f.ContainerCommitCall.Receives.Ctx = param1
+	f.ContainerCommitCall.Receives.ContainerID = param2
+	f.ContainerCommitCall.Receives.Options = param3
+	if f.ContainerCommitCall.Stub != nil {
+		return f.ContainerCommitCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerCommitCall.Returns.IDResponse, f.ContainerCommitCall.Returns.Error
+}
 func (f *SetupClient) ContainerCreate(param1 context.Context, param2 *container.Config, param3 *container.HostConfig, param4 *network.NetworkingConfig, param5 *v1.Platform, param6 string) (container.CreateResponse, error) {
 	f.ContainerCreateCall.mutex.Lock()
 	defer f.ContainerCreateCall.mutex.Unlock()
@@ -101,6 +234,41 @@ func (f *SetupClient) ContainerCreate(param1 context.Context, param2 *container.
 	}
 	return f.ContainerCreateCall.Returns.CreateResponse, f.ContainerCreateCall.Returns.Error
 }
+func (f *SetupClient) ContainerExecAttach(param1 context.Context, param2 string, param3 types.ExecStartCheck) (types.HijackedResponse, error) {
+	f.ContainerExecAttachCall.mutex.Lock()
+	defer f.ContainerExecAttachCall.mutex.Unlock()
+	f.ContainerExecAttachCall.CallCount++
+	f.ContainerExecAttachCall.Receives.Ctx = param1
+	f.ContainerExecAttachCall.Receives.ExecID = param2
+	f.ContainerExecAttachCall.Receives.Config = param3
+	if f.ContainerExecAttachCall.Stub != nil {
+		return f.ContainerExecAttachCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerExecAttachCall.Returns.HijackedResponse, f.ContainerExecAttachCall.Returns.Error
+}
+func (f *SetupClient) ContainerExecCreate(param1 context.Context, param2 string, param3 types.ExecConfig) (types.IDResponse, error) {
+	f.ContainerExecCreateCall.mutex.Lock()
+	defer f.ContainerExecCreateCall.mutex.Unlock()
+	f.ContainerExecCreateCall.CallCount++
+	f.ContainerExecCreateCall.Receives.Ctx = param1
+	f.ContainerExecCreateCall.Receives.Container = param2
+	f.ContainerExecCreateCall.Receives.Config = param3
+	if f.ContainerExecCreateCall.Stub != nil {
+		return f.ContainerExecCreateCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerExecCreateCall.Returns.IDResponse, f.ContainerExecCreateCall.Returns.Error
+}
+func (f *SetupClient) ContainerExecInspect(param1 context.Context, param2 string) (types.ContainerExecInspect, error) {
+	f.ContainerExecInspectCall.mutex.Lock()
+	defer f.ContainerExecInspectCall.mutex.Unlock()
+	f.ContainerExecInspectCall.CallCount++
+	f.ContainerExecInspectCall.Receives.Ctx = param1
+	f.ContainerExecInspectCall.Receives.ExecID = param2
+	if f.ContainerExecInspectCall.Stub != nil {
+		return f.ContainerExecInspectCall.Stub(param1, param2)
+	}
+	return f.ContainerExecInspectCall.Returns.ContainerExecInspect, f.ContainerExecInspectCall.Returns.Error
+}
 func (f *SetupClient) ContainerInspect(param1 context.Context, param2 string) (types.ContainerJSON, error) {
 	f.ContainerInspectCall.mutex.Lock()
 	defer f.ContainerInspectCall.mutex.Unlock()
@@ -124,6 +292,42 @@ func (f *SetupClient) ContainerRemove(param1 context.Context, param2 string, par
 	}
 	return f.ContainerRemoveCall.Returns.Error
 }
+func (f *SetupClient) ContainerRename(param1 context.Context, param2 string, param3 string) error {
+	f.ContainerRenameCall.mutex.Lock()
+	defer f.ContainerRenameCall.mutex.Unlock()
+	f.ContainerRenameCall.CallCount++
+	f.ContainerRenameCall.Receives.Ctx = param1
+	f.ContainerRenameCall.Receives.ContainerID = param2
+	f.ContainerRenameCall.Receives.NewContainerName = param3
+	if f.ContainerRenameCall.Stub != nil {
+		return f.ContainerRenameCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerRenameCall.Returns.Error
+}
+func (f *SetupClient) ContainerStart(param1 context.Context, param2 string, param3 types.ContainerStartOptions) error {
+	f.ContainerStartCall.mutex.Lock()
+	defer f.ContainerStartCall.mutex.Unlock()
+	f.ContainerStartCall.CallCount++
+	f.ContainerStartCall.Receives.Ctx = param1
+	f.ContainerStartCall.Receives.ContainerID = param2
+	f.ContainerStartCall.Receives.Options = param3
+	if f.ContainerStartCall.Stub != nil {
+		return f.ContainerStartCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerStartCall.Returns.Error
+}
+func (f *SetupClient) ContainerWait(param1 context.Context, param2 string, param3 container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	f.ContainerWaitCall.mutex.Lock()
+	defer f.ContainerWaitCall.mutex.Unlock()
+	f.ContainerWaitCall.CallCount++
+	f.ContainerWaitCall.Receives.Ctx = param1
+	f.ContainerWaitCall.Receives.ContainerID = param2
+	f.ContainerWaitCall.Receives.Condition = param3
+	if f.ContainerWaitCall.Stub != nil {
+		return f.ContainerWaitCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerWaitCall.Returns.WaitResponseChannel, f.ContainerWaitCall.Returns.ErrorChannel
+}
 func (f *SetupClient) CopyToContainer(param1 context.Context, param2 string, param3 string, param4 io.Reader, param5 types.CopyToContainerOptions) error {
 	f.CopyToContainerCall.mutex.Lock()
 	defer f.CopyToContainerCall.mutex.Unlock()
@@ -138,6 +342,17 @@ func (f *SetupClient) CopyToContainer(param1 context.Context, param2 string, par
 	}
 	return f.CopyToContainerCall.Returns.Error
 }
+func (f *SetupClient) ImageInspectWithRaw(param1 context.Context, param2 string) (types.ImageInspect, []byte, error) {
+	f.ImageInspectWithRawCall.mutex.Lock()
+	defer f.ImageInspectWithRawCall.mutex.Unlock()
+	f.ImageInspectWithRawCall.CallCount++
+	f.ImageInspectWithRawCall.Receives.Ctx = param1
+	f.ImageInspectWithRawCall.Receives.ImageID = param2
+	if f.ImageInspectWithRawCall.Stub != nil {
+		return f.ImageInspectWithRawCall.Stub(param1, param2)
+	}
+	return f.ImageInspectWithRawCall.Returns.ImageInspect, f.ImageInspectWithRawCall.Returns.ByteSlice, f.ImageInspectWithRawCall.Returns.Error
+}
 func (f *SetupClient) ImagePull(param1 context.Context, param2 string, param3 types.ImagePullOptions) (io.ReadCloser, error) {
 	f.ImagePullCall.mutex.Lock()
 	defer f.ImagePullCall.mutex.Unlock()
@@ -150,3 +365,13 @@ func (f *SetupClient) ImagePull(param1 context.Context, param2 string, param3 ty
 	}
 	return f.ImagePullCall.Returns.ReadCloser, f.ImagePullCall.Returns.Error
 }
+func (f *SetupClient) Info(param1 context.Context) (types.Info, error) {
+	f.InfoCall.mutex.Lock()
+	defer f.InfoCall.mutex.Unlock()
+	f.InfoCall.CallCount++
+	f.InfoCall.Receives.Ctx = param1
+	if f.InfoCall.Stub != nil {
+		return f.InfoCall.Stub(param1)
+	}
+	return f.InfoCall.Returns.Info, f.InfoCall.Returns.Error
+}
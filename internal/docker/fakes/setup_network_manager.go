@@ -6,6 +6,15 @@ import (
 )
 
 type SetupNetworkManager struct {
+	AcquireCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Name  string
+			Owner string
+		}
+		Stub func(string, string)
+	}
 	ConnectCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -35,6 +44,16 @@ type SetupNetworkManager struct {
 	}
 }
 
+func (f *SetupNetworkManager) Acquire(param1 string, param2 string) {
+	f.AcquireCall.mutex.Lock()
+	defer f.AcquireCall.mutex.Unlock()
+	f.AcquireCall.CallCount++
+	f.AcquireCall.Receives.Name = param1
+	f.AcquireCall.Receives.Owner = param2
+	if f.AcquireCall.Stub != nil {
+		f.AcquireCall.Stub(param1, param2)
+	}
+}
 func (f *SetupNetworkManager) Connect(param1 context.Context, param2 string, param3 string) error {
 	f.ConnectCall.mutex.Lock()
 	defer f.ConnectCall.mutex.Unlock()
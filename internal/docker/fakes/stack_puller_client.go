@@ -0,0 +1,64 @@
+package fakes
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+type StackPullerClient struct {
+	ImageInspectWithRawCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			ImageID string
+		}
+		Returns struct {
+			ImageInspect types.ImageInspect
+			ByteSlice    []byte
+			Error        error
+		}
+		Stub func(context.Context, string) (types.ImageInspect, []byte, error)
+	}
+	ImagePullCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			Ref     string
+			Options types.ImagePullOptions
+		}
+		Returns struct {
+			ReadCloser io.ReadCloser
+			Error      error
+		}
+		Stub func(context.Context, string, types.ImagePullOptions) (io.ReadCloser, error)
+	}
+}
+
+func (f *StackPullerClient) ImageInspectWithRaw(param1 context.Context, param2 string) (types.ImageInspect, []byte, error) {
+	f.ImageInspectWithRawCall.mutex.Lock()
+	defer f.ImageInspectWithRawCall.mutex.Unlock()
+	f.ImageInspectWithRawCall.CallCount++
+	f.ImageInspectWithRawCall.Receives.Ctx = param1
+	f.ImageInspectWithRawCall.Receives.ImageID = param2
+	if f.ImageInspectWithRawCall.Stub != nil {
+		return f.ImageInspectWithRawCall.Stub(param1, param2)
+	}
+	return f.ImageInspectWithRawCall.Returns.ImageInspect, f.ImageInspectWithRawCall.Returns.ByteSlice, f.ImageInspectWithRawCall.Returns.Error
+}
+func (f *StackPullerClient) ImagePull(param1 context.Context, param2 string, param3 types.ImagePullOptions) (io.ReadCloser, error) {
+	f.ImagePullCall.mutex.Lock()
+	defer f.ImagePullCall.mutex.Unlock()
+	f.ImagePullCall.CallCount++
+	f.ImagePullCall.Receives.Ctx = param1
+	f.ImagePullCall.Receives.Ref = param2
+	f.ImagePullCall.Receives.Options = param3
+	if f.ImagePullCall.Stub != nil {
+		return f.ImagePullCall.Stub(param1, param2, param3)
+	}
+	return f.ImagePullCall.Returns.ReadCloser, f.ImagePullCall.Returns.Error
+}
@@ -0,0 +1,35 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+)
+
+type StagingContainerPool struct {
+	TakeCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx   context.Context
+			Stack string
+		}
+		Returns struct {
+			ContainerID string
+			Ok          bool
+			Err         error
+		}
+		Stub func(context.Context, string) (string, bool, error)
+	}
+}
+
+func (f *StagingContainerPool) Take(param1 context.Context, param2 string) (string, bool, error) {
+	f.TakeCall.mutex.Lock()
+	defer f.TakeCall.mutex.Unlock()
+	f.TakeCall.CallCount++
+	f.TakeCall.Receives.Ctx = param1
+	f.TakeCall.Receives.Stack = param2
+	if f.TakeCall.Stub != nil {
+		return f.TakeCall.Stub(param1, param2)
+	}
+	return f.TakeCall.Returns.ContainerID, f.TakeCall.Returns.Ok, f.TakeCall.Returns.Err
+}
@@ -42,6 +42,46 @@ type StartClient struct {
 		}
 		Stub func(context.Context, string) (types.ContainerJSON, error)
 	}
+	ContainerListCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			Options types.ContainerListOptions
+		}
+		Returns struct {
+			ContainerSlice []types.Container
+			Error          error
+		}
+		Stub func(context.Context, types.ContainerListOptions) ([]types.Container, error)
+	}
+	ContainerLogsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx       context.Context
+			Container string
+			Options   types.ContainerLogsOptions
+		}
+		Returns struct {
+			ReadCloser io.ReadCloser
+			Error      error
+		}
+		Stub func(context.Context, string, types.ContainerLogsOptions) (io.ReadCloser, error)
+	}
+	ContainerRemoveCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     types.ContainerRemoveOptions
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, types.ContainerRemoveOptions) error
+	}
 	ContainerStartCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -55,6 +95,20 @@ type StartClient struct {
 		}
 		Stub func(context.Context, string, types.ContainerStartOptions) error
 	}
+	ContainerWaitCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Condition   container.WaitCondition
+		}
+		Returns struct {
+			WaitResponseChannel <-chan container.WaitResponse
+			ErrorChannel        <-chan error
+		}
+		Stub func(context.Context, string, container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+	}
 	CopyToContainerCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -98,6 +152,41 @@ func (f *StartClient) ContainerInspect(param1 context.Context, param2 string) (t
 	}
 	return f.ContainerInspectCall.Returns.ContainerJSON, f.ContainerInspectCall.Returns.Error
 }
+func (f *StartClient) ContainerList(param1 context.Context, param2 types.ContainerListOptions) ([]types.Container, error) {
+	f.ContainerListCall.mutex.Lock()
+	defer f.ContainerListCall.mutex.Unlock()
+	f.ContainerListCall.CallCount++
+	f.ContainerListCall.Receives.Ctx = param1
+	f.ContainerListCall.Receives.Options = param2
+	if f.ContainerListCall.Stub != nil {
+		return f.ContainerListCall.Stub(param1, param2)
+	}
+	return f.ContainerListCall.Returns.ContainerSlice, f.ContainerListCall.Returns.Error
+}
+func (f *StartClient) ContainerLogs(param1 context.Context, param2 string, param3 types.ContainerLogsOptions) (io.ReadCloser, error) {
+	f.ContainerLogsCall.mutex.Lock()
+	defer f.ContainerLogsCall.mutex.Unlock()
+	f.ContainerLogsCall.CallCount++
+	f.ContainerLogsCall.Receives.Ctx = param1
+	f.ContainerLogsCall.Receives.Container = param2
+	f.ContainerLogsCall.Receives.Options = param3
+	if f.ContainerLogsCall.Stub != nil {
+		return f.ContainerLogsCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerLogsCall.Returns.ReadCloser, f.ContainerLogsCall.Returns.Error
+}
+func (f *StartClient) ContainerRemove(param1 context.Context, param2 string, param3 types.ContainerRemoveOptions) error {
+	f.ContainerRemoveCall.mutex.Lock()
+	defer f.ContainerRemoveCall.mutex.Unlock()
+	f.ContainerRemoveCall.CallCount++
+	f.ContainerRemoveCall.Receives.Ctx = param1
+	f.ContainerRemoveCall.Receives.ContainerID = param2
+	f.ContainerRemoveCall.Receives.Options = param3
+	if f.ContainerRemoveCall.Stub != nil {
+		return f.ContainerRemoveCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerRemoveCall.Returns.Error
+}
 func (f *StartClient) ContainerStart(param1 context.Context, param2 string, param3 types.ContainerStartOptions) error {
 	f.ContainerStartCall.mutex.Lock()
 	defer f.ContainerStartCall.mutex.Unlock()
@@ -110,6 +199,18 @@ func (f *StartClient) ContainerStart(param1 context.Context, param2 string, para
 	}
 	return f.ContainerStartCall.Returns.Error
 }
+func (f *StartClient) ContainerWait(param1 context.Context, param2 string, param3 container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	f.ContainerWaitCall.mutex.Lock()
+	defer f.ContainerWaitCall.mutex.Unlock()
+	f.ContainerWaitCall.CallCount++
+	f.ContainerWaitCall.Receives.Ctx = param1
+	f.ContainerWaitCall.Receives.ContainerID = param2
+	f.ContainerWaitCall.Receives.Condition = param3
+	if f.ContainerWaitCall.Stub != nil {
+		return f.ContainerWaitCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerWaitCall.Returns.WaitResponseChannel, f.ContainerWaitCall.Returns.ErrorChannel
+}
 func (f *StartClient) CopyToContainer(param1 context.Context, param2 string, param3 string, param4 io.Reader, param5 types.CopyToContainerOptions) error {
 	f.CopyToContainerCall.mutex.Lock()
 	defer f.CopyToContainerCall.mutex.Unlock()
@@ -2,12 +2,56 @@ package fakes
 
 import (
 	"context"
+	"io"
 	"sync"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
 )
 
 type TeardownClient struct {
+	ContainerInspectCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+		}
+		Returns struct {
+			ContainerJSON types.ContainerJSON
+			Error         error
+		}
+		Stub func(context.Context, string) (types.ContainerJSON, error)
+	}
+	ContainerListCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			Options types.ContainerListOptions
+		}
+		Returns struct {
+			ContainerSlice []types.Container
+			Error          error
+		}
+		Stub func(context.Context, types.ContainerListOptions) ([]types.Container, error)
+	}
+	ContainerLogsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     types.ContainerLogsOptions
+		}
+		Returns struct {
+			ReadCloser io.ReadCloser
+			Error      error
+		}
+		Stub func(context.Context, string, types.ContainerLogsOptions) (io.ReadCloser, error)
+	}
 	ContainerRemoveCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -21,8 +65,136 @@ type TeardownClient struct {
 		}
 		Stub func(context.Context, string, types.ContainerRemoveOptions) error
 	}
+	ContainerStopCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			Options     container.StopOptions
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, container.StopOptions) error
+	}
+	CopyFromContainerCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx         context.Context
+			ContainerID string
+			SrcPath     string
+		}
+		Returns struct {
+			ReadCloser        io.ReadCloser
+			ContainerPathStat types.ContainerPathStat
+			Error             error
+		}
+		Stub func(context.Context, string, string) (io.ReadCloser, types.ContainerPathStat, error)
+	}
+	ImageListCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			Options types.ImageListOptions
+		}
+		Returns struct {
+			ImageSummarySlice []types.ImageSummary
+			Error             error
+		}
+		Stub func(context.Context, types.ImageListOptions) ([]types.ImageSummary, error)
+	}
+	ImageRemoveCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			ImageID string
+			Options types.ImageRemoveOptions
+		}
+		Returns struct {
+			ImageDeleteResponseItemSlice []types.ImageDeleteResponseItem
+			Error                        error
+		}
+		Stub func(context.Context, string, types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+	}
+	NetworkListCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx     context.Context
+			Options types.NetworkListOptions
+		}
+		Returns struct {
+			NetworkResourceSlice []types.NetworkResource
+			Error                error
+		}
+		Stub func(context.Context, types.NetworkListOptions) ([]types.NetworkResource, error)
+	}
+	VolumeListCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx    context.Context
+			Filter filters.Args
+		}
+		Returns struct {
+			ListResponse volume.ListResponse
+			Error        error
+		}
+		Stub func(context.Context, filters.Args) (volume.ListResponse, error)
+	}
+	VolumeRemoveCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx      context.Context
+			VolumeID string
+			Force    bool
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, bool) error
+	}
 }
 
+func (f *TeardownClient) ContainerInspect(param1 context.Context, param2 string) (types.ContainerJSON, error) {
+	f.ContainerInspectCall.mutex.Lock()
+	defer f.ContainerInspectCall.mutex.Unlock()
+	f.ContainerInspectCall.CallCount++
+	f.ContainerInspectCall.Receives.Ctx = param1
+	f.ContainerInspectCall.Receives.ContainerID = param2
+	if f.ContainerInspectCall.Stub != nil {
+		return f.ContainerInspectCall.Stub(param1, param2)
+	}
+	return f.ContainerInspectCall.Returns.ContainerJSON, f.ContainerInspectCall.Returns.Error
+}
+func (f *TeardownClient) ContainerList(param1 context.Context, param2 types.ContainerListOptions) ([]types.Container, error) {
+	f.ContainerListCall.mutex.Lock()
+	defer f.ContainerListCall.mutex.Unlock()
+	f.ContainerListCall.CallCount++
+	f.ContainerListCall.Receives.Ctx = param1
+	f.ContainerListCall.Receives.Options = param2
+	if f.ContainerListCall.Stub != nil {
+		return f.ContainerListCall.Stub(param1, param2)
+	}
+	return f.ContainerListCall.Returns.ContainerSlice, f.ContainerListCall.Returns.Error
+}
+func (f *TeardownClient) ContainerLogs(param1 context.Context, param2 string, param3 types.ContainerLogsOptions) (io.ReadCloser, error) {
+	f.ContainerLogsCall.mutex.Lock()
+	defer f.ContainerLogsCall.mutex.Unlock()
+	f.ContainerLogsCall.CallCount++
+	f.ContainerLogsCall.Receives.Ctx = param1
+	f.ContainerLogsCall.Receives.ContainerID = param2
+	f.ContainerLogsCall.Receives.Options = param3
+	if f.ContainerLogsCall.Stub != nil {
+		return f.ContainerLogsCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerLogsCall.Returns.ReadCloser, f.ContainerLogsCall.Returns.Error
+}
 func (f *TeardownClient) ContainerRemove(param1 context.Context, param2 string, param3 types.ContainerRemoveOptions) error {
 	f.ContainerRemoveCall.mutex.Lock()
 	defer f.ContainerRemoveCall.mutex.Unlock()
@@ -35,3 +207,84 @@ func (f *TeardownClient) ContainerRemove(param1 context.Context, param2 string,
 	}
 	return f.ContainerRemoveCall.Returns.Error
 }
+func (f *TeardownClient) ContainerStop(param1 context.Context, param2 string, param3 container.StopOptions) error {
+	f.ContainerStopCall.mutex.Lock()
+	defer f.ContainerStopCall.mutex.Unlock()
+	f.ContainerStopCall.CallCount++
+	f.ContainerStopCall.Receives.Ctx = param1
+	f.ContainerStopCall.Receives.ContainerID = param2
+	f.ContainerStopCall.Receives.Options = param3
+	if f.ContainerStopCall.Stub != nil {
+		return f.ContainerStopCall.Stub(param1, param2, param3)
+	}
+	return f.ContainerStopCall.Returns.Error
+}
+func (f *TeardownClient) CopyFromContainer(param1 context.Context, param2 string, param3 string) (io.ReadCloser, types.ContainerPathStat, error) {
+	f.CopyFromContainerCall.mutex.Lock()
+	defer f.CopyFromContainerCall.mutex.Unlock()
+	f.CopyFromContainerCall.CallCount++
+	f.CopyFromContainerCall.Receives.Ctx = param1
+	f.CopyFromContainerCall.Receives.ContainerID = param2
+	f.CopyFromContainerCall.Receives.SrcPath = param3
+	if f.CopyFromContainerCall.Stub != nil {
+		return f.CopyFromContainerCall.Stub(param1, param2, param3)
+	}
+	return f.CopyFromContainerCall.Returns.ReadCloser, f.CopyFromContainerCall.Returns.ContainerPathStat, f.CopyFromContainerCall.Returns.Error
+}
+func (f *TeardownClient) ImageList(param1 context.Context, param2 types.ImageListOptions) ([]types.ImageSummary, error) {
+	f.ImageListCall.mutex.Lock()
+	defer f.ImageListCall.mutex.Unlock()
+	f.ImageListCall.CallCount++
+	f.ImageListCall.Receives.Ctx = param1
+	f.ImageListCall.Receives.Options = param2
+	if f.ImageListCall.Stub != nil {
+		return f.ImageListCall.Stub(param1, param2)
+	}
+	return f.ImageListCall.Returns.ImageSummarySlice, f.ImageListCall.Returns.Error
+}
+func (f *TeardownClient) ImageRemove(param1 context.Context, param2 string, param3 types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	f.ImageRemoveCall.mutex.Lock()
+	defer f.ImageRemoveCall.mutex.Unlock()
+	f.ImageRemoveCall.CallCount++
+	f.ImageRemoveCall.Receives.Ctx = param1
+	f.ImageRemoveCall.Receives.ImageID = param2
+	f.ImageRemoveCall.Receives.Options = param3
+	if f.ImageRemoveCall.Stub != nil {
+		return f.ImageRemoveCall.Stub(param1, param2, param3)
+	}
+	return f.ImageRemoveCall.Returns.ImageDeleteResponseItemSlice, f.ImageRemoveCall.Returns.Error
+}
+func (f *TeardownClient) NetworkList(param1 context.Context, param2 types.NetworkListOptions) ([]types.NetworkResource, error) {
+	f.NetworkListCall.mutex.Lock()
+	defer f.NetworkListCall.mutex.Unlock()
+	f.NetworkListCall.CallCount++
+	f.NetworkListCall.Receives.Ctx = param1
+	f.NetworkListCall.Receives.Options = param2
+	if f.NetworkListCall.Stub != nil {
+		return f.NetworkListCall.Stub(param1, param2)
+	}
+	return f.NetworkListCall.Returns.NetworkResourceSlice, f.NetworkListCall.Returns.Error
+}
+func (f *TeardownClient) VolumeList(param1 context.Context, param2 filters.Args) (volume.ListResponse, error) {
+	f.VolumeListCall.mutex.Lock()
+	defer f.VolumeListCall.mutex.Unlock()
+	f.VolumeListCall.CallCount++
+	f.VolumeListCall.Receives.Ctx = param1
+	f.VolumeListCall.Receives.Filter = param2
+	if f.VolumeListCall.Stub != nil {
+		return f.VolumeListCall.Stub(param1, param2)
+	}
+	return f.VolumeListCall.Returns.ListResponse, f.VolumeListCall.Returns.Error
+}
+func (f *TeardownClient) VolumeRemove(param1 context.Context, param2 string, param3 bool) error {
+	f.VolumeRemoveCall.mutex.Lock()
+	defer f.VolumeRemoveCall.mutex.Unlock()
+	f.VolumeRemoveCall.CallCount++
+	f.VolumeRemoveCall.Receives.Ctx = param1
+	f.VolumeRemoveCall.Receives.VolumeID = param2
+	f.VolumeRemoveCall.Receives.Force = param3
+	if f.VolumeRemoveCall.Stub != nil {
+		return f.VolumeRemoveCall.Stub(param1, param2, param3)
+	}
+	return f.VolumeRemoveCall.Returns.Error
+}
@@ -18,6 +18,19 @@ type TeardownNetworkManager struct {
 		}
 		Stub func(context.Context, string) error
 	}
+	ReleaseCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx   context.Context
+			Name  string
+			Owner string
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, string, string) error
+	}
 }
 
 func (f *TeardownNetworkManager) Delete(param1 context.Context, param2 string) error {
@@ -31,3 +44,15 @@ func (f *TeardownNetworkManager) Delete(param1 context.Context, param2 string) e
 	}
 	return f.DeleteCall.Returns.Error
 }
+func (f *TeardownNetworkManager) Release(param1 context.Context, param2 string, param3 string) error {
+	f.ReleaseCall.mutex.Lock()
+	defer f.ReleaseCall.mutex.Unlock()
+	f.ReleaseCall.CallCount++
+	f.ReleaseCall.Receives.Ctx = param1
+	f.ReleaseCall.Receives.Name = param2
+	f.ReleaseCall.Receives.Owner = param3
+	if f.ReleaseCall.Stub != nil {
+		return f.ReleaseCall.Stub(param1, param2, param3)
+	}
+	return f.ReleaseCall.Returns.Error
+}
@@ -0,0 +1,11 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/cloudfoundry/switchblade/internal/filelock"
+)
+
+func lockFile(ctx context.Context, path string) (func() error, error) {
+	return filelock.Lock(ctx, path)
+}
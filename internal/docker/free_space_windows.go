@@ -0,0 +1,18 @@
+package docker
+
+import "golang.org/x/sys/windows"
+
+func freeSpace(path string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var available uint64
+	err = windows.GetDiskFreeSpaceEx(p, &available, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return available, nil
+}
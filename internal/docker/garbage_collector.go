@@ -0,0 +1,347 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+)
+
+//go:generate faux --interface GarbageCollectorClient --output fakes/garbage_collector_client.go
+type GarbageCollectorClient interface {
+	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
+	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+	NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error)
+	VolumeList(ctx context.Context, filter filters.Args) (volume.ListResponse, error)
+	VolumeRemove(ctx context.Context, volumeID string, force bool) error
+}
+
+type GarbageCollector struct {
+	client    GarbageCollectorClient
+	networks  TeardownNetworkManager
+	workspace string
+	runID     string
+}
+
+func NewGarbageCollector(client GarbageCollectorClient, networks TeardownNetworkManager, workspace string) GarbageCollector {
+	return GarbageCollector{
+		client:    client,
+		networks:  networks,
+		workspace: workspace,
+	}
+}
+
+func (g GarbageCollector) WithRunID(runID string) GarbageCollector {
+	g.runID = runID
+	return g
+}
+
+func (g GarbageCollector) Run(ctx context.Context, olderThan time.Duration) error {
+	resources, err := g.List(ctx, olderThan)
+	if err != nil {
+		return err
+	}
+
+	return g.remove(ctx, resources)
+}
+
+// RemoveRun removes every container, volume, staging image, and network of
+// the run, however recently it was created, so that resources still being
+// created when the run is interrupted are not left behind. Workspace files are
+// left for Run. It does nothing without a run ID, since the resources of other
+// processes could not be told apart.
+func (g GarbageCollector) RemoveRun(ctx context.Context) error {
+	if g.runID == "" {
+		return nil
+	}
+
+	// A negative age also admits resources created after the listing began.
+	resources, err := g.List(ctx, -time.Minute)
+	if err != nil {
+		return err
+	}
+
+	var labelled []Resource
+	for _, resource := range resources {
+		if resource.Kind != "droplet" && resource.Kind != "file" {
+			labelled = append(labelled, resource)
+		}
+	}
+
+	return g.remove(ctx, labelled)
+}
+
+func (g GarbageCollector) remove(ctx context.Context, resources []Resource) error {
+	var err error
+	for _, resource := range resources {
+		switch resource.Kind {
+		case "container":
+			err = g.client.ContainerRemove(ctx, resource.ID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+			if err != nil && !client.IsErrNotFound(err) {
+				return fmt.Errorf("failed to remove container: %w", err)
+			}
+
+		case "volume":
+			err = g.client.VolumeRemove(ctx, resource.ID, true)
+			if err != nil && !client.IsErrNotFound(err) {
+				return fmt.Errorf("failed to remove volume: %w", err)
+			}
+
+		case "image":
+			_, err = g.client.ImageRemove(ctx, resource.ID, types.ImageRemoveOptions{Force: true})
+			if err != nil && !client.IsErrNotFound(err) {
+				return fmt.Errorf("failed to remove staging image: %w", err)
+			}
+
+		case "network":
+			err = g.networks.Delete(ctx, resource.Name)
+			if err != nil {
+				return fmt.Errorf("failed to delete network: %w", err)
+			}
+
+		case "droplet":
+			err = removeDroplet(ctx, filepath.Dir(resource.Name), filepath.Base(resource.Name))
+			if err != nil {
+				return fmt.Errorf("failed to delete droplets: %w", err)
+			}
+
+		case "file":
+			err = os.RemoveAll(resource.Name)
+			if err != nil {
+				return fmt.Errorf("failed to delete file: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g GarbageCollector) List(ctx context.Context, olderThan time.Duration) ([]Resource, error) {
+	now := time.Now()
+	cutoff := now.Add(-olderThan)
+
+	var resources []Resource
+	for _, label := range []string{AppLabel, StagingPoolLabel} {
+		args := filters.NewArgs(filters.Arg("label", label))
+		if g.runID != "" {
+			args.Add("label", fmt.Sprintf("%s=%s", RunLabel, g.runID))
+		}
+
+		containers, err := g.client.ContainerList(ctx, types.ContainerListOptions{
+			All:     true,
+			Filters: args,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		for _, container := range containers {
+			created := time.Unix(container.Created, 0)
+			if !created.Before(cutoff) {
+				continue
+			}
+
+			resources = append(resources, Resource{Kind: "container", Name: containerName(container.Names), ID: container.ID, Age: now.Sub(created)})
+		}
+	}
+
+	volumeArgs := filters.NewArgs(filters.Arg("label", AppLabel))
+	if g.runID != "" {
+		volumeArgs.Add("label", fmt.Sprintf("%s=%s", RunLabel, g.runID))
+	}
+
+	volumes, err := g.client.VolumeList(ctx, volumeArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	for _, v := range volumes.Volumes {
+		age := volumeAge(v, now)
+		if age <= olderThan {
+			continue
+		}
+
+		resources = append(resources, Resource{Kind: "volume", Name: v.Name, ID: v.Name, Age: age})
+	}
+
+	images, err := g.client.ImageList(ctx, types.ImageListOptions{
+		Filters: filters.NewArgs(filters.Arg("reference", stagingImageName(g.imagePattern()))),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staging images: %w", err)
+	}
+
+	for _, image := range images {
+		created := time.Unix(image.Created, 0)
+		if !created.Before(cutoff) {
+			continue
+		}
+
+		resources = append(resources, Resource{Kind: "image", Name: imageName(image.RepoTags), ID: image.ID, Age: now.Sub(created)})
+	}
+
+	networkFilter := "switchblade-"
+	if g.runID != "" {
+		networkFilter = internalNetworkName(g.runID)
+	}
+
+	networks, err := g.client.NetworkList(ctx, types.NetworkListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", networkFilter)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	for _, network := range networks {
+		if !g.ownsNetwork(network.Name) || !network.Created.Before(cutoff) {
+			continue
+		}
+
+		resources = append(resources, Resource{Kind: "network", Name: network.Name, ID: network.ID, Age: now.Sub(network.Created)})
+	}
+
+	droplets, err := g.staleDroplets(now, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list droplets: %w", err)
+	}
+	resources = append(resources, droplets...)
+
+	for _, dir := range []string{"source", "buildpacks", "build-cache", "crashes"} {
+		files, err := staleFiles(filepath.Join(g.workspace, dir), now, cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s files: %w", dir, err)
+		}
+		resources = append(resources, files...)
+	}
+
+	return resources, nil
+}
+
+func (g GarbageCollector) staleDroplets(now, cutoff time.Time) ([]Resource, error) {
+	dir := filepath.Join(g.workspace, "droplets")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var resources []Resource
+	referenced := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+
+		target, err := dropletBlob(dir, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if target != "" {
+			referenced[target] = true
+		}
+
+		info, err := os.Stat(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		var age time.Duration
+		if info != nil {
+			if !info.ModTime().Before(cutoff) {
+				continue
+			}
+
+			age = now.Sub(info.ModTime())
+		}
+
+		resources = append(resources, Resource{Kind: "droplet", Name: path, ID: target, Age: age})
+	}
+
+	blobs, err := os.ReadDir(filepath.Join(dir, "sha256"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return resources, nil
+		}
+
+		return nil, err
+	}
+
+	for _, blob := range blobs {
+		if referenced[filepath.Join("sha256", blob.Name())] {
+			continue
+		}
+
+		info, err := blob.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		resources = append(resources, Resource{Kind: "file", Name: filepath.Join(dir, "sha256", blob.Name()), Age: now.Sub(info.ModTime())})
+	}
+
+	return resources, nil
+}
+
+func staleFiles(dir string, now, cutoff time.Time) ([]Resource, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var resources []Resource
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		resources = append(resources, Resource{Kind: "file", Name: filepath.Join(dir, entry.Name()), Age: now.Sub(info.ModTime())})
+	}
+
+	return resources, nil
+}
+
+func (g GarbageCollector) imagePattern() string {
+	if g.runID == "" {
+		return "*"
+	}
+
+	return fmt.Sprintf("%s-*", g.runID)
+}
+
+func (g GarbageCollector) ownsNetwork(name string) bool {
+	if g.runID != "" {
+		return name == internalNetworkName(g.runID)
+	}
+
+	return strings.HasPrefix(name, "switchblade-") || strings.HasSuffix(name, fmt.Sprintf("-%s", InternalNetworkName))
+}
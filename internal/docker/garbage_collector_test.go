@@ -0,0 +1,403 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testGarbageCollector(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Run", func() {
+		var (
+			collector docker.GarbageCollector
+
+			client         *fakes.GarbageCollectorClient
+			networkManager *fakes.TeardownNetworkManager
+			workspace      string
+
+			containerListOptions []types.ContainerListOptions
+			removedContainers    []string
+			removedImages        []string
+			old                  time.Time
+		)
+
+		it.Before(func() {
+			var err error
+			workspace, err = os.MkdirTemp("", "workspace")
+			Expect(err).NotTo(HaveOccurred())
+
+			old = time.Now().Add(-2 * time.Hour)
+			containerListOptions = nil
+			removedContainers = nil
+			removedImages = nil
+
+			client = &fakes.GarbageCollectorClient{}
+			client.ContainerListCall.Stub = func(ctx gocontext.Context, options types.ContainerListOptions) ([]types.Container, error) {
+				containerListOptions = append(containerListOptions, options)
+
+				if options.Filters.Get("label")[0] == "switchblade.app" {
+					return []types.Container{
+						{ID: "old-app-container", Created: old.Unix()},
+						{ID: "new-app-container", Created: time.Now().Unix()},
+					}, nil
+				}
+
+				return []types.Container{
+					{ID: "old-pooled-container", Created: old.Unix()},
+				}, nil
+			}
+			client.ContainerRemoveCall.Stub = func(ctx gocontext.Context, containerID string, options types.ContainerRemoveOptions) error {
+				removedContainers = append(removedContainers, containerID)
+				return nil
+			}
+			client.ImageListCall.Returns.ImageSummarySlice = []types.ImageSummary{
+				{ID: "old-image", Created: old.Unix()},
+				{ID: "new-image", Created: time.Now().Unix()},
+			}
+			client.ImageRemoveCall.Stub = func(ctx gocontext.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+				removedImages = append(removedImages, imageID)
+				return nil, nil
+			}
+			client.NetworkListCall.Returns.NetworkResourceSlice = []types.NetworkResource{
+				{Name: "switchblade-internal", Created: old},
+				{Name: "switchblade-something-else-not-matching", Created: time.Now()},
+				{Name: "unrelated-switchblade-network", Created: old},
+			}
+
+			networkManager = &fakes.TeardownNetworkManager{}
+
+			for _, dir := range []string{"droplets/sha256", "source", "buildpacks", "build-cache"} {
+				Expect(os.MkdirAll(filepath.Join(workspace, dir), os.ModePerm)).To(Succeed())
+			}
+
+			Expect(os.WriteFile(filepath.Join(workspace, "droplets", "sha256", "old-digest.tar.gz"), nil, 0600)).To(Succeed())
+			Expect(os.Symlink(filepath.Join("sha256", "old-digest.tar.gz"), filepath.Join(workspace, "droplets", "old-app.tar.gz"))).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workspace, "droplets", "sha256", "orphaned-digest.tar.gz"), nil, 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workspace, "droplets", "sha256", "new-digest.tar.gz"), nil, 0600)).To(Succeed())
+			Expect(os.Symlink(filepath.Join("sha256", "new-digest.tar.gz"), filepath.Join(workspace, "droplets", "new-app.tar.gz"))).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workspace, "source", "old-app.tar.gz"), nil, 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workspace, "source", "new-app.tar.gz"), nil, 0600)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(workspace, "buildpacks", "old-app"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workspace, "build-cache", "old-app.tar.gz"), nil, 0600)).To(Succeed())
+
+			for _, path := range []string{
+				"droplets/sha256/old-digest.tar.gz",
+				"droplets/sha256/orphaned-digest.tar.gz",
+				"source/old-app.tar.gz",
+				"buildpacks/old-app",
+				"build-cache/old-app.tar.gz",
+			} {
+				Expect(os.Chtimes(filepath.Join(workspace, path), old, old)).To(Succeed())
+			}
+
+			collector = docker.NewGarbageCollector(client, networkManager, workspace)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workspace)).To(Succeed())
+		})
+
+		it("removes switchblade resources older than the given age", func() {
+			err := collector.Run(gocontext.Background(), time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(containerListOptions).To(Equal([]types.ContainerListOptions{
+				{All: true, Filters: filters.NewArgs(filters.Arg("label", "switchblade.app"))},
+				{All: true, Filters: filters.NewArgs(filters.Arg("label", "switchblade.staging-pool"))},
+			}))
+			Expect(removedContainers).To(Equal([]string{"old-app-container", "old-pooled-container"}))
+			Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}))
+
+			Expect(client.ImageListCall.Receives.Options).To(Equal(types.ImageListOptions{
+				Filters: filters.NewArgs(filters.Arg("reference", "switchblade-staging-*")),
+			}))
+			Expect(removedImages).To(Equal([]string{"old-image"}))
+
+			Expect(networkManager.DeleteCall.CallCount).To(Equal(1))
+			Expect(networkManager.DeleteCall.Receives.Name).To(Equal("switchblade-internal"))
+
+			Expect(filepath.Join(workspace, "droplets", "old-app.tar.gz")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(workspace, "droplets", "sha256", "old-digest.tar.gz")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(workspace, "droplets", "sha256", "orphaned-digest.tar.gz")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(workspace, "droplets", "new-app.tar.gz")).To(BeAnExistingFile())
+			Expect(filepath.Join(workspace, "droplets", "sha256", "new-digest.tar.gz")).To(BeAnExistingFile())
+			Expect(filepath.Join(workspace, "source", "old-app.tar.gz")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(workspace, "source", "new-app.tar.gz")).To(BeAnExistingFile())
+			Expect(filepath.Join(workspace, "buildpacks", "old-app")).NotTo(BeADirectory())
+			Expect(filepath.Join(workspace, "build-cache", "old-app.tar.gz")).NotTo(BeAnExistingFile())
+		})
+
+		context("List", func() {
+			it("returns the resources that would be removed without removing them", func() {
+				client.ContainerListCall.Stub = func(ctx gocontext.Context, options types.ContainerListOptions) ([]types.Container, error) {
+					if options.Filters.Get("label")[0] == "switchblade.app" {
+						return []types.Container{{ID: "old-app-container", Names: []string{"/old-app"}, Created: old.Unix()}}, nil
+					}
+
+					return nil, nil
+				}
+				client.ImageListCall.Returns.ImageSummarySlice = []types.ImageSummary{
+					{ID: "old-image", RepoTags: []string{"switchblade-staging-old-app:latest"}, Created: old.Unix()},
+				}
+
+				resources, err := collector.List(gocontext.Background(), time.Hour)
+				Expect(err).NotTo(HaveOccurred())
+
+				var kinds, names []string
+				for _, resource := range resources {
+					kinds = append(kinds, resource.Kind)
+					names = append(names, resource.Name)
+					Expect(resource.Age).To(BeNumerically("~", 2*time.Hour, time.Minute))
+				}
+
+				Expect(kinds).To(Equal([]string{"container", "image", "network", "droplet", "file", "file", "file", "file"}))
+				Expect(names).To(Equal([]string{
+					"old-app",
+					"switchblade-staging-old-app:latest",
+					"switchblade-internal",
+					filepath.Join(workspace, "droplets", "old-app.tar.gz"),
+					filepath.Join(workspace, "droplets", "sha256", "orphaned-digest.tar.gz"),
+					filepath.Join(workspace, "source", "old-app.tar.gz"),
+					filepath.Join(workspace, "buildpacks", "old-app"),
+					filepath.Join(workspace, "build-cache", "old-app.tar.gz"),
+				}))
+				Expect(resources[0].ID).To(Equal("old-app-container"))
+				Expect(resources[3].ID).To(Equal(filepath.Join("sha256", "old-digest.tar.gz")))
+
+				Expect(client.ContainerRemoveCall.CallCount).To(Equal(0))
+				Expect(client.ImageRemoveCall.CallCount).To(Equal(0))
+				Expect(networkManager.DeleteCall.CallCount).To(Equal(0))
+				Expect(filepath.Join(workspace, "droplets", "old-app.tar.gz")).To(BeAnExistingFile())
+				Expect(filepath.Join(workspace, "source", "old-app.tar.gz")).To(BeAnExistingFile())
+			})
+		})
+
+		context("when there are labelled volumes", func() {
+			it.Before(func() {
+				client.VolumeListCall.Returns.ListResponse = volume.ListResponse{
+					Volumes: []*volume.Volume{
+						{Name: "old-volume", CreatedAt: old.Format(time.RFC3339)},
+						{Name: "new-volume", CreatedAt: time.Now().Format(time.RFC3339)},
+					},
+				}
+			})
+
+			it("removes the ones older than the given age", func() {
+				err := collector.Run(gocontext.Background(), time.Hour)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.VolumeListCall.Receives.Filter).To(Equal(filters.NewArgs(filters.Arg("label", "switchblade.app"))))
+				Expect(client.VolumeRemoveCall.CallCount).To(Equal(1))
+				Expect(client.VolumeRemoveCall.Receives.VolumeID).To(Equal("old-volume"))
+				Expect(client.VolumeRemoveCall.Receives.Force).To(BeTrue())
+			})
+
+			context("failure cases", func() {
+				context("when the volumes cannot be listed", func() {
+					it.Before(func() {
+						client.VolumeListCall.Returns.Error = errors.New("could not list volumes")
+					})
+
+					it("returns an error", func() {
+						err := collector.Run(gocontext.Background(), time.Hour)
+						Expect(err).To(MatchError("failed to list volumes: could not list volumes"))
+					})
+				})
+
+				context("when a volume cannot be removed", func() {
+					it.Before(func() {
+						client.VolumeRemoveCall.Returns.Error = errors.New("could not remove volume")
+					})
+
+					it("returns an error", func() {
+						err := collector.Run(gocontext.Background(), time.Hour)
+						Expect(err).To(MatchError("failed to remove volume: could not remove volume"))
+					})
+				})
+			})
+		})
+
+		context("WithRunID", func() {
+			it.Before(func() {
+				client.ContainerListCall.Stub = func(ctx gocontext.Context, options types.ContainerListOptions) ([]types.Container, error) {
+					containerListOptions = append(containerListOptions, options)
+					return nil, nil
+				}
+				client.NetworkListCall.Returns.NetworkResourceSlice = []types.NetworkResource{
+					{Name: "switchblade-internal", Created: old},
+					{Name: "some-run-switchblade-internal", Created: old},
+				}
+
+				collector = collector.WithRunID("some-run")
+			})
+
+			it("only removes the resources belonging to that run", func() {
+				err := collector.Run(gocontext.Background(), time.Hour)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(containerListOptions).To(Equal([]types.ContainerListOptions{
+					{All: true, Filters: filters.NewArgs(filters.Arg("label", "switchblade.app"), filters.Arg("label", "switchblade.run=some-run"))},
+					{All: true, Filters: filters.NewArgs(filters.Arg("label", "switchblade.staging-pool"), filters.Arg("label", "switchblade.run=some-run"))},
+				}))
+
+				Expect(client.ImageListCall.Receives.Options).To(Equal(types.ImageListOptions{
+					Filters: filters.NewArgs(filters.Arg("reference", "switchblade-staging-some-run-*")),
+				}))
+
+				Expect(client.NetworkListCall.Receives.Options).To(Equal(types.NetworkListOptions{
+					Filters: filters.NewArgs(filters.Arg("name", "some-run-switchblade-internal")),
+				}))
+				Expect(networkManager.DeleteCall.CallCount).To(Equal(1))
+				Expect(networkManager.DeleteCall.Receives.Name).To(Equal("some-run-switchblade-internal"))
+			})
+		})
+
+		context("RemoveRun", func() {
+			it.Before(func() {
+				client.ContainerListCall.Stub = func(ctx gocontext.Context, options types.ContainerListOptions) ([]types.Container, error) {
+					containerListOptions = append(containerListOptions, options)
+
+					if options.Filters.Get("label")[0] == "switchblade.app" {
+						return []types.Container{{ID: "new-app-container", Created: time.Now().Unix()}}, nil
+					}
+
+					return nil, nil
+				}
+				client.VolumeListCall.Returns.ListResponse = volume.ListResponse{
+					Volumes: []*volume.Volume{{Name: "new-volume", CreatedAt: time.Now().Format(time.RFC3339)}},
+				}
+				client.NetworkListCall.Returns.NetworkResourceSlice = []types.NetworkResource{
+					{Name: "some-run-switchblade-internal", Created: time.Now()},
+				}
+
+				collector = collector.WithRunID("some-run")
+			})
+
+			it("removes the resources of the run however new they are and leaves the workspace", func() {
+				err := collector.RemoveRun(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(containerListOptions).To(ContainElement(types.ContainerListOptions{
+					All:     true,
+					Filters: filters.NewArgs(filters.Arg("label", "switchblade.app"), filters.Arg("label", "switchblade.run=some-run")),
+				}))
+				Expect(removedContainers).To(Equal([]string{"new-app-container"}))
+				Expect(client.VolumeRemoveCall.Receives.VolumeID).To(Equal("new-volume"))
+				Expect(removedImages).To(Equal([]string{"old-image", "new-image"}))
+				Expect(networkManager.DeleteCall.Receives.Name).To(Equal("some-run-switchblade-internal"))
+
+				Expect(filepath.Join(workspace, "droplets", "old-app.tar.gz")).To(BeAnExistingFile())
+				Expect(filepath.Join(workspace, "source", "old-app.tar.gz")).To(BeAnExistingFile())
+			})
+
+			context("without a run ID", func() {
+				it.Before(func() {
+					collector = collector.WithRunID("")
+				})
+
+				it("does nothing", func() {
+					err := collector.RemoveRun(gocontext.Background())
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(client.ContainerListCall.CallCount).To(Equal(0))
+				})
+			})
+		})
+
+		context("when the workspace is empty", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(workspace)).To(Succeed())
+			})
+
+			it("does not error", func() {
+				err := collector.Run(gocontext.Background(), time.Hour)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the containers cannot be listed", func() {
+				it.Before(func() {
+					client.ContainerListCall.Stub = nil
+					client.ContainerListCall.Returns.Error = errors.New("could not list containers")
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError("failed to list containers: could not list containers"))
+				})
+			})
+
+			context("when a container cannot be removed", func() {
+				it.Before(func() {
+					client.ContainerRemoveCall.Stub = nil
+					client.ContainerRemoveCall.Returns.Error = errors.New("could not remove container")
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError("failed to remove container: could not remove container"))
+				})
+			})
+
+			context("when the staging images cannot be listed", func() {
+				it.Before(func() {
+					client.ImageListCall.Returns.Error = errors.New("could not list images")
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError("failed to list staging images: could not list images"))
+				})
+			})
+
+			context("when a staging image cannot be removed", func() {
+				it.Before(func() {
+					client.ImageRemoveCall.Stub = nil
+					client.ImageRemoveCall.Returns.Error = errors.New("could not remove image")
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError("failed to remove staging image: could not remove image"))
+				})
+			})
+
+			context("when the networks cannot be listed", func() {
+				it.Before(func() {
+					client.NetworkListCall.Returns.Error = errors.New("could not list networks")
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError("failed to list networks: could not list networks"))
+				})
+			})
+
+			context("when a network cannot be deleted", func() {
+				it.Before(func() {
+					networkManager.DeleteCall.Returns.Error = errors.New("could not delete network")
+				})
+
+				it("returns an error", func() {
+					err := collector.Run(gocontext.Background(), time.Hour)
+					Expect(err).To(MatchError("failed to delete network: could not delete network"))
+				})
+			})
+		})
+	})
+}
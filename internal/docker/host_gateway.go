@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+)
+
+const HostGatewayName = "host.docker.internal"
+
+type HostGateway struct {
+	client RuntimeClient
+	detect func(ctx context.Context, client RuntimeClient) (Runtime, error)
+}
+
+func NewHostGateway(client RuntimeClient) HostGateway {
+	return HostGateway{client: client, detect: DetectRuntime}
+}
+
+func (g HostGateway) WithRuntimeDetector(detect func(ctx context.Context, client RuntimeClient) (Runtime, error)) HostGateway {
+	g.detect = detect
+	return g
+}
+
+func (g HostGateway) Resolve(ctx context.Context) (string, []string, error) {
+	runtime, err := g.detect(ctx, g.client)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return runtime.HostName, runtime.ExtraHosts, nil
+}
+
+func rewriteServiceHosts(services map[string]map[string]interface{}, host string) map[string]map[string]interface{} {
+	if services == nil {
+		return nil
+	}
+
+	rewritten := make(map[string]map[string]interface{}, len(services))
+	for name, credentials := range services {
+		rewritten[name] = rewriteHosts(credentials, host).(map[string]interface{})
+	}
+
+	return rewritten
+}
+
+func rewriteHosts(value interface{}, host string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		rewritten := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			rewritten[key] = rewriteHosts(item, host)
+		}
+
+		return rewritten
+	case []interface{}:
+		rewritten := make([]interface{}, len(v))
+		for i, item := range v {
+			rewritten[i] = rewriteHosts(item, host)
+		}
+
+		return rewritten
+	case string:
+		return rewriteHost(v, host)
+	default:
+		return value
+	}
+}
+
+func rewriteHost(value, gateway string) string {
+	if isLocalhost(value) {
+		return gateway
+	}
+
+	host, port, err := net.SplitHostPort(value)
+	if err == nil {
+		if isLocalhost(host) {
+			return net.JoinHostPort(gateway, port)
+		}
+
+		return value
+	}
+
+	uri, err := url.Parse(value)
+	if err != nil || uri.Host == "" || !isLocalhost(uri.Hostname()) {
+		return value
+	}
+
+	port = uri.Port()
+	uri.Host = gateway
+	if port != "" {
+		uri.Host = net.JoinHostPort(gateway, port)
+	}
+
+	return uri.String()
+}
+
+func isLocalhost(host string) bool {
+	switch strings.Trim(host, "[]") {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testHostGateway(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		gateway docker.HostGateway
+		client  *fakes.RuntimeClient
+	)
+
+	it.Before(func() {
+		client = &fakes.RuntimeClient{}
+		client.InfoCall.Returns.Info = types.Info{OperatingSystem: "Ubuntu 22.04.2 LTS", ServerVersion: "24.0.2"}
+
+		gateway = docker.NewHostGateway(client)
+	})
+
+	context("Resolve", func() {
+		it("returns the host name and extra hosts for the runtime", func() {
+			host, extraHosts, err := gateway.Resolve(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(host).To(Equal("host.docker.internal"))
+			Expect(extraHosts).To(Equal([]string{"host.docker.internal:host-gateway"}))
+		})
+
+		context("WithRuntimeDetector", func() {
+			it.Before(func() {
+				gateway = gateway.WithRuntimeDetector(func(ctx gocontext.Context, c docker.RuntimeClient) (docker.Runtime, error) {
+					Expect(c).To(Equal(client))
+					return docker.Runtime{Name: "podman", HostName: "host.containers.internal"}, nil
+				})
+			})
+
+			it("resolves the host using the detected runtime", func() {
+				host, extraHosts, err := gateway.Resolve(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(host).To(Equal("host.containers.internal"))
+				Expect(extraHosts).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the daemon cannot be inspected", func() {
+				it.Before(func() {
+					client.InfoCall.Returns.Error = errors.New("daemon unavailable")
+				})
+
+				it("returns an error", func() {
+					_, _, err := gateway.Resolve(gocontext.Background())
+					Expect(err).To(MatchError("failed to inspect docker daemon: daemon unavailable"))
+				})
+			})
+		})
+	})
+}
@@ -12,17 +12,44 @@ func TestDocker(t *testing.T) {
 	format.MaxLength = 0
 
 	suite := spec.New("switchblade/internal/docker", spec.Report(report.Terminal{}), spec.Parallel())
+	suite("ArchitectureValidator", testArchitectureValidator)
 	suite("BuildpacksCache", testBuildpacksCache)
 	suite("BuildpacksManager", testBuildpacksManager)
 	suite("BuildpacksRegistry", testBuildpacksRegistry)
+	suite("ContainerController", testContainerController)
+	suite("CredentialService", testCredentialService)
+	suite("DebugTransport", testDebugTransport)
+	suite("DropletCache", testDropletCache)
+	suite("DropletPusher", testDropletPusher)
+	suite("DropletSignature", testDropletSignature)
+	suite("EnvironmentSnapshotter", testEnvironmentSnapshotter)
+	suite("EventTransport", testEventTransport)
+	suite("GarbageCollector", testGarbageCollector)
+	suite("HostGateway", testHostGateway)
 	suite("Initialize", testInitialize)
+	suite("Journal", testJournal)
+	suite("JournalingClient", testJournalingClient)
 	suite("LifecycleManager", testLifecycleManager)
+	suite("LogStreamer", testLogStreamer)
 	suite("NetworkManager", testNetworkManager)
+	suite("OnceLifecycleBuilder", testOnceLifecycleBuilder)
+	suite("PrebuiltLifecycleManager", testPrebuiltLifecycleManager)
+	suite("Preflight", testPreflight)
+	suite("Reaper", testReaper)
+	suite("Recovery", testRecovery)
+	suite("RemoteHost", testRemoteHost)
+	suite("RootlessClient", testRootlessClient)
+	suite("Runtime", testRuntime)
+	suite("Scaler", testScaler)
 	suite("Setup", testSetup)
+	suite("StackPuller", testStackPuller)
 	suite("Stage", testStage)
+	suite("StagingPool", testStagingPool)
 	suite("Start", testStart)
 	suite("TGZArchiver", testTGZArchiver)
 	suite("Teardown", testTeardown)
+	suite("ThrottledClient", testThrottledClient)
+	suite("Transient", testTransient)
 	suite.Run(t)
 }
 
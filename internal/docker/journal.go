@@ -0,0 +1,182 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type JournalEntry struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	App       string    `json:"app"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Journal struct {
+	path string
+}
+
+func NewJournal(path string) Journal {
+	return Journal{
+		path: path,
+	}
+}
+
+func (j Journal) Record(ctx context.Context, entry JournalEntry) error {
+	unlock, err := lockFile(ctx, fmt.Sprintf("%s.lock", j.path))
+	if err != nil {
+		return fmt.Errorf("failed to lock journal: %w", err)
+	}
+	defer unlock()
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(j.path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(content, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	return file.Close()
+}
+
+func (j Journal) Entries(ctx context.Context) ([]JournalEntry, error) {
+	unlock, err := lockFile(ctx, fmt.Sprintf("%s.lock", j.path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock journal: %w", err)
+	}
+	defer unlock()
+
+	return j.read()
+}
+
+func (j Journal) Forget(ctx context.Context, app string) error {
+	return j.rewrite(ctx, func(entry JournalEntry) bool {
+		return entry.App != app
+	})
+}
+
+func (j Journal) Remove(ctx context.Context, entries []JournalEntry) error {
+	removed := map[JournalEntry]bool{}
+	for _, entry := range entries {
+		removed[entry] = true
+	}
+
+	return j.rewrite(ctx, func(entry JournalEntry) bool {
+		return !removed[entry]
+	})
+}
+
+func (j Journal) rewrite(ctx context.Context, keep func(JournalEntry) bool) error {
+	unlock, err := lockFile(ctx, fmt.Sprintf("%s.lock", j.path))
+	if err != nil {
+		return fmt.Errorf("failed to lock journal: %w", err)
+	}
+	defer unlock()
+
+	entries, err := j.read()
+	if err != nil {
+		return err
+	}
+
+	var kept []byte
+	for _, entry := range entries {
+		if !keep(entry) {
+			continue
+		}
+
+		content, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal journal entry: %w", err)
+		}
+
+		kept = append(kept, append(content, '\n')...)
+	}
+
+	if len(kept) == 0 {
+		err = os.Remove(j.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove journal: %w", err)
+		}
+
+		return nil
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(j.path), fmt.Sprintf("%s-*", filepath.Base(j.path)))
+	if err != nil {
+		return fmt.Errorf("failed to create journal: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(kept)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close journal: %w", err)
+	}
+
+	err = os.Rename(file.Name(), j.path)
+	if err != nil {
+		return fmt.Errorf("failed to replace journal: %w", err)
+	}
+
+	return nil
+}
+
+func (j Journal) read() ([]JournalEntry, error) {
+	file, err := os.Open(j.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry JournalEntry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return entries, nil
+}
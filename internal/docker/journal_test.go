@@ -0,0 +1,143 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/filelock"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testJournal(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		journal docker.Journal
+		dir     string
+		now     time.Time
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "journal")
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Now().UTC().Truncate(time.Second)
+		journal = docker.NewJournal(filepath.Join(dir, "workspace", "journal.jsonl"))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	context("Record", func() {
+		it("appends entries to the journal", func() {
+			Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "container", ID: "some-container-id", App: "some-app", Timestamp: now})).To(Succeed())
+			Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "network", ID: "some-network-id", Timestamp: now})).To(Succeed())
+
+			entries, err := journal.Entries(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(Equal([]docker.JournalEntry{
+				{Type: "container", ID: "some-container-id", App: "some-app", Timestamp: now},
+				{Type: "network", ID: "some-network-id", Timestamp: now},
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the journal cannot be written", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(dir, "workspace", "journal.jsonl"), os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := journal.Record(gocontext.Background(), docker.JournalEntry{Type: "container", ID: "some-container-id"})
+					Expect(err).To(MatchError(ContainSubstring("failed to open journal:")))
+				})
+			})
+
+			context("when the context is done while waiting for the lock", func() {
+				var unlock func() error
+
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(dir, "workspace"), os.ModePerm)).To(Succeed())
+
+					var err error
+					unlock, err = filelock.Lock(gocontext.Background(), filepath.Join(dir, "workspace", "journal.jsonl.lock"))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it.After(func() {
+					Expect(unlock()).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 50*time.Millisecond)
+					defer cancel()
+
+					err := journal.Record(ctx, docker.JournalEntry{Type: "container", ID: "some-container-id"})
+					Expect(err).To(MatchError(ContainSubstring("failed to lock journal:")))
+					Expect(err).To(MatchError(gocontext.DeadlineExceeded))
+				})
+			})
+		})
+	})
+
+	context("Entries", func() {
+		context("when the journal does not exist", func() {
+			it("returns no entries", func() {
+				entries, err := journal.Entries(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(BeEmpty())
+			})
+		})
+	})
+
+	context("Forget", func() {
+		it.Before(func() {
+			Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "container", ID: "some-container-id", App: "some-app", Timestamp: now})).To(Succeed())
+			Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "container", ID: "other-container-id", App: "other-app", Timestamp: now})).To(Succeed())
+		})
+
+		it("removes the entries for the app", func() {
+			Expect(journal.Forget(gocontext.Background(), "some-app")).To(Succeed())
+
+			entries, err := journal.Entries(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(Equal([]docker.JournalEntry{
+				{Type: "container", ID: "other-container-id", App: "other-app", Timestamp: now},
+			}))
+		})
+
+		context("when no entries remain", func() {
+			it("removes the journal", func() {
+				Expect(journal.Forget(gocontext.Background(), "some-app")).To(Succeed())
+				Expect(journal.Forget(gocontext.Background(), "other-app")).To(Succeed())
+
+				Expect(filepath.Join(dir, "workspace", "journal.jsonl")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	context("Remove", func() {
+		it("removes the given entries", func() {
+			Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "container", ID: "some-container-id", App: "some-app", Timestamp: now})).To(Succeed())
+			Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "image", ID: "some-image-id", App: "some-app", Timestamp: now})).To(Succeed())
+
+			Expect(journal.Remove(gocontext.Background(), []docker.JournalEntry{
+				{Type: "container", ID: "some-container-id", App: "some-app", Timestamp: now},
+			})).To(Succeed())
+
+			entries, err := journal.Entries(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(Equal([]docker.JournalEntry{
+				{Type: "image", ID: "some-image-id", App: "some-app", Timestamp: now},
+			}))
+		})
+	})
+}
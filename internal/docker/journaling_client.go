@@ -0,0 +1,77 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type JournalingClient struct {
+	client.CommonAPIClient
+
+	journal Journal
+}
+
+func NewJournalingClient(apiClient client.CommonAPIClient, journal Journal) JournalingClient {
+	return JournalingClient{
+		CommonAPIClient: apiClient,
+		journal:         journal,
+	}
+}
+
+func (c JournalingClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error) {
+	resp, err := c.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, containerName)
+	if err != nil {
+		return resp, err
+	}
+
+	var app string
+	if config != nil {
+		app = config.Labels[AppLabel]
+	}
+
+	err = c.journal.Record(ctx, JournalEntry{Type: "container", ID: resp.ID, App: app, Timestamp: time.Now()})
+	if err != nil {
+		return resp, fmt.Errorf("failed to record container: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c JournalingClient) ContainerCommit(ctx context.Context, containerID string, options types.ContainerCommitOptions) (types.IDResponse, error) {
+	resp, err := c.CommonAPIClient.ContainerCommit(ctx, containerID, options)
+	if err != nil {
+		return resp, err
+	}
+
+	repository, _, _ := strings.Cut(options.Reference, ":")
+	app := strings.TrimPrefix(repository, stagingImageName(""))
+
+	err = c.journal.Record(ctx, JournalEntry{Type: "image", ID: resp.ID, App: app, Timestamp: time.Now()})
+	if err != nil {
+		return resp, fmt.Errorf("failed to record image: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c JournalingClient) NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	resp, err := c.CommonAPIClient.NetworkCreate(ctx, name, options)
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.journal.Record(ctx, JournalEntry{Type: "network", ID: resp.ID, Timestamp: time.Now()})
+	if err != nil {
+		return resp, fmt.Errorf("failed to record network: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,104 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+type creatingAPIClient struct {
+	client.CommonAPIClient
+
+	err error
+}
+
+func (c creatingAPIClient) ContainerCreate(ctx gocontext.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error) {
+	return container.CreateResponse{ID: "some-container-id"}, c.err
+}
+
+func (c creatingAPIClient) ContainerCommit(ctx gocontext.Context, containerID string, options types.ContainerCommitOptions) (types.IDResponse, error) {
+	return types.IDResponse{ID: "some-image-id"}, c.err
+}
+
+func (c creatingAPIClient) NetworkCreate(ctx gocontext.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	return types.NetworkCreateResponse{ID: "some-network-id"}, c.err
+}
+
+func testJournalingClient(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		journal   docker.Journal
+		apiClient docker.JournalingClient
+		dir       string
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "journal")
+		Expect(err).NotTo(HaveOccurred())
+
+		journal = docker.NewJournal(filepath.Join(dir, "journal.jsonl"))
+		apiClient = docker.NewJournalingClient(creatingAPIClient{}, journal)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	it("records the containers, images, and networks it creates", func() {
+		ctx := gocontext.Background()
+
+		_, err := apiClient.NetworkCreate(ctx, "switchblade-internal", types.NetworkCreate{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = apiClient.ContainerCreate(ctx, &container.Config{Labels: map[string]string{docker.AppLabel: "some-app"}}, nil, nil, nil, "some-app")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = apiClient.ContainerCommit(ctx, "some-container-id", types.ContainerCommitOptions{Reference: "switchblade-staging-some-app:some-stack"})
+		Expect(err).NotTo(HaveOccurred())
+
+		entries, err := journal.Entries(gocontext.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(3))
+
+		Expect(entries[0].Type).To(Equal("network"))
+		Expect(entries[0].ID).To(Equal("some-network-id"))
+		Expect(entries[0].Timestamp).NotTo(BeZero())
+
+		Expect(entries[1].Type).To(Equal("container"))
+		Expect(entries[1].ID).To(Equal("some-container-id"))
+		Expect(entries[1].App).To(Equal("some-app"))
+
+		Expect(entries[2].Type).To(Equal("image"))
+		Expect(entries[2].ID).To(Equal("some-image-id"))
+		Expect(entries[2].App).To(Equal("some-app"))
+	})
+
+	context("when the resource cannot be created", func() {
+		it.Before(func() {
+			apiClient = docker.NewJournalingClient(creatingAPIClient{err: errors.New("could not create")}, journal)
+		})
+
+		it("does not record it", func() {
+			_, err := apiClient.ContainerCreate(gocontext.Background(), &container.Config{}, nil, nil, nil, "some-app")
+			Expect(err).To(MatchError("could not create"))
+
+			entries, err := journal.Entries(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
+}
@@ -2,6 +2,8 @@ package docker
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -17,6 +19,11 @@ import (
 
 var goVersionRegexp = regexp.MustCompile(`go(\d+\.\d+)`)
 
+const (
+	lifecycleGOOS   = "linux"
+	lifecycleGOARCH = "amd64"
+)
+
 //go:generate faux --interface Executable --output fakes/executable.go
 type Executable interface {
 	Execute(pexec.Execution) error
@@ -25,22 +32,24 @@ type Executable interface {
 type LifecycleManager struct {
 	golang   Executable
 	archiver Archiver
+	cache    string
 	m        *sync.Mutex
 }
 
-func NewLifecycleManager(golang Executable, archiver Archiver) LifecycleManager {
+func NewLifecycleManager(golang Executable, archiver Archiver, cache string) LifecycleManager {
 	return LifecycleManager{
 		golang:   golang,
 		archiver: archiver,
+		cache:    cache,
 		m:        &sync.Mutex{},
 	}
 }
 
-func (b LifecycleManager) Build(sourceURI, workspace string) (string, error) {
+func (b LifecycleManager) Build(ctx context.Context, sourceURI, workspace string) (string, error) {
 	b.m.Lock()
 	defer b.m.Unlock()
 
-	req, err := http.NewRequest("GET", sourceURI, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", sourceURI, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -70,12 +79,48 @@ func (b LifecycleManager) Build(sourceURI, workspace string) (string, error) {
 		return "", fmt.Errorf("failed to clear workspace: %w", err)
 	}
 
-	err = vacation.NewZipArchive(resp.Body).StripComponents(1).Decompress(filepath.Join(workspace, "repo"))
+	err = os.MkdirAll(workspace, os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	repo := bytes.NewBuffer(nil)
+	_, err = io.Copy(repo, resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to download lifecycle repo: %w", err)
+	}
+
+	binaries := filepath.Join(b.cache, fmt.Sprintf("%x-%s-%s", sha256.Sum256(repo.Bytes()), lifecycleGOOS, lifecycleGOARCH))
+	_, err = os.Stat(binaries)
+	if errors.Is(err, os.ErrNotExist) {
+		err = b.compile(repo, workspace, binaries)
+		if err != nil {
+			return "", err
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("failed to stat lifecycle cache: %w", err)
+	}
+
+	err = b.archiver.WithPrefix("/tmp/lifecycle").Compress(binaries, output)
+	if err != nil {
+		return "", fmt.Errorf("failed to archive lifecycle: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(workspace, "etag"), []byte(resp.Header.Get("ETag")), 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write lifecycle etag file: %w", err)
+	}
+
+	return output, nil
+}
+
+func (b LifecycleManager) compile(repo io.Reader, workspace, binaries string) error {
+	err := vacation.NewZipArchive(repo).StripComponents(1).Decompress(filepath.Join(workspace, "repo"))
 	if err != nil {
-		return "", fmt.Errorf("failed to decompress lifecycle repo: %w", err)
+		return fmt.Errorf("failed to decompress lifecycle repo: %w", err)
 	}
 
-	env := append(os.Environ(), "GOOS=linux", "GOARCH=amd64")
+	env := append(os.Environ(), fmt.Sprintf("GOOS=%s", lifecycleGOOS), fmt.Sprintf("GOARCH=%s", lifecycleGOARCH))
 	buffer := bytes.NewBuffer(nil)
 
 	_, err = os.Stat(filepath.Join(workspace, "repo", "go.mod"))
@@ -88,10 +133,10 @@ func (b LifecycleManager) Build(sourceURI, workspace string) (string, error) {
 			Stderr: buffer,
 		})
 		if err != nil {
-			return "", fmt.Errorf("failed to initialize go module: %w\n\n%s", err, buffer)
+			return fmt.Errorf("failed to initialize go module: %w\n\n%s", err, buffer)
 		}
 	} else if err != nil {
-		return "", fmt.Errorf("failed to stat go.mod: %w", err)
+		return fmt.Errorf("failed to stat go.mod: %w", err)
 	}
 
 	versionBuffer := bytes.NewBuffer(nil)
@@ -103,7 +148,7 @@ func (b LifecycleManager) Build(sourceURI, workspace string) (string, error) {
 		Stderr: buffer,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to identify go version: %w\n\n%s", err, buffer)
+		return fmt.Errorf("failed to identify go version: %w\n\n%s", err, buffer)
 	}
 
 	args := []string{"mod", "tidy"}
@@ -120,12 +165,12 @@ func (b LifecycleManager) Build(sourceURI, workspace string) (string, error) {
 		Stderr: buffer,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to tidy go module: %w\n\n%s", err, buffer)
+		return fmt.Errorf("failed to tidy go module: %w\n\n%s", err, buffer)
 	}
 
 	err = os.MkdirAll(filepath.Join(workspace, "output"), os.ModePerm)
 	if err != nil {
-		return "", fmt.Errorf("failed to create output directory: %w", err)
+		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	err = b.golang.Execute(pexec.Execution{
@@ -136,7 +181,7 @@ func (b LifecycleManager) Build(sourceURI, workspace string) (string, error) {
 		Stderr: buffer,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to build lifecycle builder: %w\n\n%s", err, buffer)
+		return fmt.Errorf("failed to build lifecycle builder: %w\n\n%s", err, buffer)
 	}
 
 	err = b.golang.Execute(pexec.Execution{
@@ -147,18 +192,34 @@ func (b LifecycleManager) Build(sourceURI, workspace string) (string, error) {
 		Stderr: buffer,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to build lifecycle launcher: %w\n\n%s", err, buffer)
+		return fmt.Errorf("failed to build lifecycle launcher: %w\n\n%s", err, buffer)
 	}
 
-	err = b.archiver.WithPrefix("/tmp/lifecycle").Compress(filepath.Join(workspace, "output"), output)
+	err = os.MkdirAll(b.cache, os.ModePerm)
 	if err != nil {
-		return "", fmt.Errorf("failed to archive lifecycle: %w", err)
+		return fmt.Errorf("failed to create lifecycle cache: %w", err)
 	}
 
-	err = os.WriteFile(filepath.Join(workspace, "etag"), []byte(resp.Header.Get("ETag")), 0600)
+	staging, err := os.MkdirTemp(b.cache, "staging")
 	if err != nil {
-		return "", fmt.Errorf("failed to write lifecycle etag file: %w", err)
+		return fmt.Errorf("failed to create lifecycle cache entry: %w", err)
 	}
+	defer os.RemoveAll(staging)
 
-	return output, nil
+	for _, binary := range []string{"builder", "launcher"} {
+		err = os.Rename(filepath.Join(workspace, "output", binary), filepath.Join(staging, binary))
+		if err != nil {
+			return fmt.Errorf("failed to move lifecycle %s into cache: %w", binary, err)
+		}
+	}
+
+	err = os.Rename(staging, binaries)
+	if err != nil {
+		_, statErr := os.Stat(binaries)
+		if statErr != nil {
+			return fmt.Errorf("failed to populate lifecycle cache: %w", err)
+		}
+	}
+
+	return nil
 }
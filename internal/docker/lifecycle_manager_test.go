@@ -2,6 +2,7 @@ package docker_test
 
 import (
 	"archive/zip"
+	gocontext "context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -26,6 +27,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 	context("Build", func() {
 		var (
 			workspace  string
+			cache      string
 			executable *fakes.Executable
 			executions []pexec.Execution
 			server     *httptest.Server
@@ -41,6 +43,9 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 
 			Expect(os.WriteFile(filepath.Join(workspace, "extra-file"), nil, 0600)).To(Succeed())
 
+			cache, err = os.MkdirTemp("", "cache")
+			Expect(err).NotTo(HaveOccurred())
+
 			executable = &fakes.Executable{}
 			executable.ExecuteCall.Stub = func(execution pexec.Execution) error {
 				executions = append(executions, execution)
@@ -48,6 +53,10 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 					fmt.Fprint(execution.Stdout, "go version go1.19.1 darwin/amd64")
 				}
 
+				if execution.Args[0] == "build" {
+					return os.WriteFile(execution.Args[2], []byte(execution.Args[3]), 0600)
+				}
+
 				return nil
 			}
 
@@ -102,15 +111,16 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 			archiver = &fakes.Archiver{}
 			archiver.WithPrefixCall.Returns.Archiver = archiver
 
-			manager = docker.NewLifecycleManager(executable, archiver)
+			manager = docker.NewLifecycleManager(executable, archiver, cache)
 		})
 
 		it.After(func() {
 			Expect(os.RemoveAll(workspace)).To(Succeed())
+			Expect(os.RemoveAll(cache)).To(Succeed())
 		})
 
 		it("builds the lifecycle", func() {
-			path, err := manager.Build(server.URL, workspace)
+			path, err := manager.Build(gocontext.Background(), server.URL, workspace)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(path).To(Equal(filepath.Join(workspace, "lifecycle.tar.gz")))
 
@@ -142,8 +152,14 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 				"Dir":  Equal(filepath.Join(workspace, "repo")),
 			}))
 
+			binaries, err := filepath.Glob(filepath.Join(cache, "*-linux-amd64"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(binaries).To(HaveLen(1))
+			Expect(filepath.Join(binaries[0], "builder")).To(BeARegularFile())
+			Expect(filepath.Join(binaries[0], "launcher")).To(BeARegularFile())
+
 			Expect(archiver.WithPrefixCall.Receives.Prefix).To(Equal("/tmp/lifecycle"))
-			Expect(archiver.CompressCall.Receives.Input).To(Equal(filepath.Join(workspace, "output")))
+			Expect(archiver.CompressCall.Receives.Input).To(Equal(binaries[0]))
 			Expect(archiver.CompressCall.Receives.Output).To(Equal(filepath.Join(workspace, "lifecycle.tar.gz")))
 
 			etag, err := os.ReadFile(filepath.Join(workspace, "etag"))
@@ -155,7 +171,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 
 		context("when a go.mod file already exists", func() {
 			it("builds the lifecycle", func() {
-				path, err := manager.Build(fmt.Sprintf("%s/with-go-modules", server.URL), workspace)
+				path, err := manager.Build(gocontext.Background(), fmt.Sprintf("%s/with-go-modules", server.URL), workspace)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(path).To(Equal(filepath.Join(workspace, "lifecycle.tar.gz")))
 
@@ -184,11 +200,39 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 				}))
 
 				Expect(archiver.WithPrefixCall.Receives.Prefix).To(Equal("/tmp/lifecycle"))
-				Expect(archiver.CompressCall.Receives.Input).To(Equal(filepath.Join(workspace, "output")))
+				Expect(archiver.CompressCall.Receives.Input).To(HavePrefix(cache))
 				Expect(archiver.CompressCall.Receives.Output).To(Equal(filepath.Join(workspace, "lifecycle.tar.gz")))
 			})
 		})
 
+		context("when the binaries for that lifecycle version are already cached", func() {
+			it.Before(func() {
+				_, err := manager.Build(gocontext.Background(), server.URL, workspace)
+				Expect(err).NotTo(HaveOccurred())
+
+				executions = nil
+			})
+
+			it("skips compiling the lifecycle in a new workspace", func() {
+				otherWorkspace, err := os.MkdirTemp("", "workspace")
+				Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(otherWorkspace)
+
+				path, err := manager.Build(gocontext.Background(), server.URL, otherWorkspace)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path).To(Equal(filepath.Join(otherWorkspace, "lifecycle.tar.gz")))
+
+				Expect(executions).To(HaveLen(0))
+
+				binaries, err := filepath.Glob(filepath.Join(cache, "*-linux-amd64"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(binaries).To(HaveLen(1))
+
+				Expect(archiver.CompressCall.Receives.Input).To(Equal(binaries[0]))
+				Expect(archiver.CompressCall.Receives.Output).To(Equal(filepath.Join(otherWorkspace, "lifecycle.tar.gz")))
+			})
+		})
+
 		context("when the etag matches", func() {
 			it.Before(func() {
 				err := os.WriteFile(filepath.Join(workspace, "etag"), []byte("some-etag"), 0600)
@@ -196,7 +240,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 			})
 
 			it("skips building the lifecycle", func() {
-				path, err := manager.Build(server.URL, workspace)
+				path, err := manager.Build(gocontext.Background(), server.URL, workspace)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(path).To(Equal(filepath.Join(workspace, "lifecycle.tar.gz")))
 
@@ -213,7 +257,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 					})
 
 					it("returns an error", func() {
-						_, err := manager.Build(server.URL, workspace)
+						_, err := manager.Build(gocontext.Background(), server.URL, workspace)
 						Expect(err).To(MatchError(ContainSubstring("failed to read etag:")))
 						Expect(err).To(MatchError(ContainSubstring("permission denied")))
 					})
@@ -224,7 +268,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 		context("failure cases", func() {
 			context("when the source uri is malformed", func() {
 				it("returns an error", func() {
-					_, err := manager.Build("%%%", workspace)
+					_, err := manager.Build(gocontext.Background(), "%%%", workspace)
 					Expect(err).To(MatchError(ContainSubstring("failed to create request:")))
 					Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
 				})
@@ -232,7 +276,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 
 			context("when the request fails", func() {
 				it("returns an error", func() {
-					_, err := manager.Build("http://localhost:0", workspace)
+					_, err := manager.Build(gocontext.Background(), "http://localhost:0", workspace)
 					Expect(err).To(MatchError(ContainSubstring("failed to complete request:")))
 					Expect(err).To(MatchError(ContainSubstring("dial tcp")))
 				})
@@ -250,7 +294,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(server.URL, workspace)
+					_, err := manager.Build(gocontext.Background(), server.URL, workspace)
 					Expect(err).To(MatchError(ContainSubstring("failed to decompress lifecycle repo:")))
 					Expect(err).To(MatchError(ContainSubstring("not a valid zip file")))
 				})
@@ -270,7 +314,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(server.URL, workspace)
+					_, err := manager.Build(gocontext.Background(), server.URL, workspace)
 					Expect(err).To(MatchError(ContainSubstring("failed to initialize go module: go mod init errored")))
 					Expect(err).To(MatchError(ContainSubstring("stdout: could not initialize")))
 					Expect(err).To(MatchError(ContainSubstring("stderr: could not initialize")))
@@ -291,7 +335,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(server.URL, workspace)
+					_, err := manager.Build(gocontext.Background(), server.URL, workspace)
 					Expect(err).To(MatchError(ContainSubstring("failed to identify go version: go version errored")))
 					Expect(err).To(MatchError(ContainSubstring("stdout: could not version")))
 					Expect(err).To(MatchError(ContainSubstring("stderr: could not version")))
@@ -312,7 +356,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(server.URL, workspace)
+					_, err := manager.Build(gocontext.Background(), server.URL, workspace)
 					Expect(err).To(MatchError(ContainSubstring("failed to tidy go module: go mod tidy errored")))
 					Expect(err).To(MatchError(ContainSubstring("stdout: could not tidy")))
 					Expect(err).To(MatchError(ContainSubstring("stderr: could not tidy")))
@@ -333,7 +377,7 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(server.URL, workspace)
+					_, err := manager.Build(gocontext.Background(), server.URL, workspace)
 					Expect(err).To(MatchError(ContainSubstring("failed to build lifecycle builder: go build builder errored")))
 					Expect(err).To(MatchError(ContainSubstring("stdout: could not build builder")))
 					Expect(err).To(MatchError(ContainSubstring("stderr: could not build builder")))
@@ -354,20 +398,33 @@ func testLifecycleManager(t *testing.T, context spec.G, it spec.S) {
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(server.URL, workspace)
+					_, err := manager.Build(gocontext.Background(), server.URL, workspace)
 					Expect(err).To(MatchError(ContainSubstring("failed to build lifecycle launcher: go build launcher errored")))
 					Expect(err).To(MatchError(ContainSubstring("stdout: could not build launcher")))
 					Expect(err).To(MatchError(ContainSubstring("stderr: could not build launcher")))
 				})
 			})
 
+			context("when the lifecycle cache cannot be inspected", func() {
+				it.Before(func() {
+					Expect(os.RemoveAll(cache)).To(Succeed())
+					Expect(os.WriteFile(cache, nil, 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := manager.Build(gocontext.Background(), server.URL, workspace)
+					Expect(err).To(MatchError(ContainSubstring("failed to stat lifecycle cache:")))
+					Expect(err).To(MatchError(ContainSubstring("not a directory")))
+				})
+			})
+
 			context("when the lifecycle cannot be archived", func() {
 				it.Before(func() {
 					archiver.CompressCall.Returns.Error = errors.New("could not compress lifecycle")
 				})
 
 				it("returns an error", func() {
-					_, err := manager.Build(server.URL, workspace)
+					_, err := manager.Build(gocontext.Background(), server.URL, workspace)
 					Expect(err).To(MatchError("failed to archive lifecycle: could not compress lifecycle"))
 				})
 			})
@@ -0,0 +1,42 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+//go:generate faux --interface LogStreamerClient --output fakes/log_streamer_client.go
+type LogStreamerClient interface {
+	ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error)
+}
+
+type LogStreamer struct {
+	client LogStreamerClient
+}
+
+func NewLogStreamer(client LogStreamerClient) LogStreamer {
+	return LogStreamer{client: client}
+}
+
+func (s LogStreamer) Stream(ctx context.Context, name string, w io.Writer) error {
+	logs, err := s.client.ContainerLogs(ctx, name, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch container logs: %w", err)
+	}
+	defer logs.Close()
+
+	_, err = stdcopy.StdCopy(w, w, logs)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to copy container logs: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package docker_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testLogStreamer(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		streamer docker.LogStreamer
+		client   *fakes.LogStreamerClient
+	)
+
+	it.Before(func() {
+		containerLogs := bytes.NewBuffer(nil)
+		_, err := stdcopy.NewStdWriter(containerLogs, stdcopy.Stdout).Write([]byte("Listening on port 8080\n"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = stdcopy.NewStdWriter(containerLogs, stdcopy.Stderr).Write([]byte("GET / 200\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		client = &fakes.LogStreamerClient{}
+		client.ContainerLogsCall.Returns.ReadCloser = io.NopCloser(containerLogs)
+
+		streamer = docker.NewLogStreamer(client)
+	})
+
+	context("Stream", func() {
+		it("follows the container logs", func() {
+			logs := bytes.NewBuffer(nil)
+
+			err := streamer.Stream(gocontext.Background(), "some-app", logs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logs.String()).To(Equal("Listening on port 8080\nGET / 200\n"))
+
+			Expect(client.ContainerLogsCall.Receives.Container).To(Equal("some-app"))
+			Expect(client.ContainerLogsCall.Receives.Options).To(Equal(types.ContainerLogsOptions{
+				ShowStdout: true,
+				ShowStderr: true,
+				Follow:     true,
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the container logs cannot be fetched", func() {
+				it.Before(func() {
+					client.ContainerLogsCall.Returns.Error = errors.New("no such container")
+				})
+
+				it("returns an error", func() {
+					err := streamer.Stream(gocontext.Background(), "some-app", bytes.NewBuffer(nil))
+					Expect(err).To(MatchError("failed to fetch container logs: no such container"))
+				})
+			})
+
+			context("when the container logs cannot be copied", func() {
+				it.Before(func() {
+					client.ContainerLogsCall.Returns.ReadCloser = io.NopCloser(bytes.NewBufferString("not multiplexed"))
+				})
+
+				it("returns an error", func() {
+					err := streamer.Stream(gocontext.Background(), "some-app", bytes.NewBuffer(nil))
+					Expect(err).To(MatchError(ContainSubstring("failed to copy container logs:")))
+				})
+			})
+		})
+	})
+}
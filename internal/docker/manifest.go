@@ -0,0 +1,233 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const RedactedValue = "[REDACTED]"
+
+var sensitiveEnvironmentKeys = []string{"AUTH", "CREDENTIAL", "KEY", "PASSWD", "PASSWORD", "PRIVATE", "SECRET", "TOKEN"}
+
+type Manifest struct {
+	Name           string              `json:"name"`
+	Source         string              `json:"source"`
+	Stack          StackManifest       `json:"stack"`
+	Lifecycle      LifecycleManifest   `json:"lifecycle"`
+	Buildpacks     []BuildpackManifest `json:"buildpacks"`
+	BuildpackOrder []string            `json:"buildpack_order"`
+	SkipDetect     bool                `json:"skip_detect"`
+	Env            map[string]string   `json:"env"`
+	EnvDigest      string              `json:"env_digest,omitempty"`
+	Services       []string            `json:"services,omitempty"`
+	Options        []string            `json:"options,omitempty"`
+}
+
+type StackManifest struct {
+	Name   string `json:"name"`
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+}
+
+type LifecycleManifest struct {
+	URI    string `json:"uri"`
+	ETag   string `json:"etag,omitempty"`
+	SHA256 string `json:"sha256"`
+}
+
+type BuildpackManifest struct {
+	Name   string `json:"name"`
+	URI    string `json:"uri"`
+	SHA256 string `json:"sha256"`
+}
+
+func ReadManifest(path string) (Manifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer file.Close()
+
+	var manifest Manifest
+	err = json.NewDecoder(file).Decode(&manifest)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func writeManifest(path string, manifest Manifest) error {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create manifests directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	err = os.WriteFile(path, content, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+func readLifecycleManifest(path string) (LifecycleManifest, error) {
+	digest, err := FileDigest(path)
+	if err != nil {
+		return LifecycleManifest{}, fmt.Errorf("failed to digest lifecycle: %w", err)
+	}
+
+	manifest := LifecycleManifest{
+		URI:    BuildpackAppLifecycleRepoURL,
+		SHA256: digest,
+	}
+
+	source, err := os.ReadFile(filepath.Join(filepath.Dir(path), "source"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return LifecycleManifest{}, fmt.Errorf("failed to read lifecycle source: %w", err)
+	}
+	if len(source) > 0 {
+		manifest.URI = string(source)
+	}
+
+	etag, err := os.ReadFile(filepath.Join(filepath.Dir(path), "etag"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return LifecycleManifest{}, fmt.Errorf("failed to read lifecycle etag: %w", err)
+	}
+	manifest.ETag = string(etag)
+
+	return manifest, nil
+}
+
+func readBuildpacksLock(path string) ([]BuildpackManifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read buildpacks lock: %w", err)
+	}
+
+	var buildpacks []BuildpackManifest
+	err = json.Unmarshal(content, &buildpacks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse buildpacks lock: %w", err)
+	}
+
+	return buildpacks, nil
+}
+
+// RedactEnvironment replaces the values of variables whose names look like
+// credentials with RedactedValue.
+func RedactEnvironment(env map[string]string) map[string]string {
+	redacted := map[string]string{}
+	for key, value := range env {
+		redacted[key] = value
+		for _, sensitive := range sensitiveEnvironmentKeys {
+			if strings.Contains(strings.ToUpper(key), sensitive) {
+				redacted[key] = RedactedValue
+				break
+			}
+		}
+	}
+
+	return redacted
+}
+
+// environmentDigest covers the unredacted values of env so that a change to
+// a redacted variable still changes the manifest.
+func environmentDigest(env map[string]string) string {
+	var keys []string
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s\x00%s\x00", key, env[key])
+	}
+
+	return formatDigest(hash)
+}
+
+func FileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return formatDigest(hash), nil
+}
+
+func DirectoryDigest(path string) (string, error) {
+	hash := sha256.New()
+	err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(hash, "%s\x00%s\x00", filepath.ToSlash(rel), info.Mode().Type())
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(file)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(hash, "%s\x00", filepath.ToSlash(link))
+		case info.Mode().IsRegular():
+			digest, err := FileDigest(file)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(hash, "%s\x00", digest)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return formatDigest(hash), nil
+}
+
+func formatDigest(hash hash.Hash) string {
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
@@ -20,12 +20,14 @@ type NetworkManagementClient interface {
 
 type NetworkManager struct {
 	client NetworkManagementClient
+	owners map[string]map[string]struct{}
 	m      *sync.Mutex
 }
 
 func NewNetworkManager(client NetworkManagementClient) NetworkManager {
 	return NetworkManager{
 		client: client,
+		owners: map[string]map[string]struct{}{},
 		m:      &sync.Mutex{},
 	}
 }
@@ -79,10 +81,41 @@ func (m NetworkManager) Connect(ctx context.Context, containerID, name string) e
 	return fmt.Errorf("failed to connect container to network: no such network %q", name)
 }
 
+func (m NetworkManager) Acquire(name, owner string) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	if m.owners[name] == nil {
+		m.owners[name] = map[string]struct{}{}
+	}
+
+	m.owners[name][owner] = struct{}{}
+}
+
+func (m NetworkManager) Release(ctx context.Context, name, owner string) error {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	delete(m.owners[name], owner)
+	if len(m.owners[name]) > 0 {
+		return nil
+	}
+
+	delete(m.owners, name)
+
+	return m.delete(ctx, name)
+}
+
 func (m NetworkManager) Delete(ctx context.Context, name string) error {
 	m.m.Lock()
 	defer m.m.Unlock()
 
+	delete(m.owners, name)
+
+	return m.delete(ctx, name)
+}
+
+func (m NetworkManager) delete(ctx context.Context, name string) error {
 	networks, err := m.client.NetworkList(ctx, types.NetworkListOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to list networks: %w", err)
@@ -91,7 +124,7 @@ func (m NetworkManager) Delete(ctx context.Context, name string) error {
 	for _, network := range networks {
 		if network.Name == name {
 			err = m.client.NetworkRemove(ctx, network.ID)
-			if err != nil && !errdefs.IsForbidden(err) {
+			if err != nil && !errdefs.IsForbidden(err) && !errdefs.IsNotFound(err) {
 				return fmt.Errorf("failed to delete network: %w", err)
 			}
 
@@ -211,6 +211,19 @@ func testNetworkManager(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the network is removed before it can be deleted", func() {
+			it.Before(func() {
+				client.NetworkRemoveCall.Returns.Error = errdefs.NotFound(errors.New("network not found"))
+			})
+
+			it("does not error", func() {
+				ctx := gocontext.Background()
+
+				err := manager.Delete(ctx, "some-network")
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
 		context("when the network still has containers attached", func() {
 			it.Before(func() {
 				client.NetworkRemoveCall.Returns.Error = errdefs.Forbidden(errors.New("containers still attached"))
@@ -252,4 +265,54 @@ func testNetworkManager(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 	})
+
+	context("Release", func() {
+		it.Before(func() {
+			client.NetworkListCall.Returns.NetworkResourceSlice = []types.NetworkResource{
+				{
+					Name: "some-network",
+					ID:   "some-network-id",
+				},
+			}
+		})
+
+		it("deletes the network once its last owner releases it", func() {
+			ctx := gocontext.Background()
+
+			manager.Acquire("some-network", "some-app")
+			manager.Acquire("some-network", "other-app")
+			manager.Acquire("some-network", "other-app")
+
+			err := manager.Release(ctx, "some-network", "some-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.NetworkRemoveCall.CallCount).To(Equal(0))
+
+			err = manager.Release(ctx, "some-network", "other-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.NetworkRemoveCall.CallCount).To(Equal(1))
+			Expect(client.NetworkRemoveCall.Receives.NetworkID).To(Equal("some-network-id"))
+		})
+
+		context("when the network has no owners", func() {
+			it("deletes the network", func() {
+				err := manager.Release(gocontext.Background(), "some-network", "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.NetworkRemoveCall.Receives.NetworkID).To(Equal("some-network-id"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the network cannot be removed", func() {
+				it.Before(func() {
+					client.NetworkRemoveCall.Returns.Error = errors.New("network could not be removed")
+				})
+
+				it("returns an error", func() {
+					err := manager.Release(gocontext.Background(), "some-network", "some-app")
+					Expect(err).To(MatchError("failed to delete network: network could not be removed"))
+				})
+			})
+		})
+	})
 }
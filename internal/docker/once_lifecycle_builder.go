@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+type OnceLifecycleBuilder struct {
+	builder LifecycleBuilder
+	locks   string
+
+	builds map[string]*lifecycleBuild
+	m      *sync.Mutex
+}
+
+type lifecycleBuild struct {
+	once sync.Once
+	path string
+	err  error
+}
+
+func NewOnceLifecycleBuilder(builder LifecycleBuilder, locks string) OnceLifecycleBuilder {
+	return OnceLifecycleBuilder{
+		builder: builder,
+		locks:   locks,
+		builds:  map[string]*lifecycleBuild{},
+		m:       &sync.Mutex{},
+	}
+}
+
+func (b OnceLifecycleBuilder) Build(ctx context.Context, sourceURI, workspace string) (string, error) {
+	key := fmt.Sprintf("%s %s", sourceURI, workspace)
+
+	b.m.Lock()
+	build, ok := b.builds[key]
+	if !ok {
+		build = &lifecycleBuild{}
+		b.builds[key] = build
+	}
+	b.m.Unlock()
+
+	build.once.Do(func() {
+		build.path, build.err = b.build(ctx, sourceURI, workspace)
+	})
+
+	if build.err != nil {
+		b.m.Lock()
+		if b.builds[key] == build {
+			delete(b.builds, key)
+		}
+		b.m.Unlock()
+
+		return "", build.err
+	}
+
+	return build.path, nil
+}
+
+func (b OnceLifecycleBuilder) build(ctx context.Context, sourceURI, workspace string) (string, error) {
+	unlock, err := lockFile(ctx, filepath.Join(b.locks, fmt.Sprintf("%s.lock", filepath.Base(workspace))))
+	if err != nil {
+		return "", fmt.Errorf("failed to lock lifecycle: %w", err)
+	}
+	defer unlock()
+
+	return b.builder.Build(ctx, sourceURI, workspace)
+}
@@ -0,0 +1,134 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testOnceLifecycleBuilder(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Build", func() {
+		var (
+			builder          docker.OnceLifecycleBuilder
+			lifecycleBuilder *fakes.LifecycleBuilder
+			locks            string
+		)
+
+		it.Before(func() {
+			var err error
+			locks, err = os.MkdirTemp("", "locks")
+			Expect(err).NotTo(HaveOccurred())
+
+			lifecycleBuilder = &fakes.LifecycleBuilder{}
+			lifecycleBuilder.BuildCall.Returns.Path = "/some/workspace/lifecycle/lifecycle.tar.gz"
+
+			builder = docker.NewOnceLifecycleBuilder(lifecycleBuilder, locks)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(locks)).To(Succeed())
+		})
+
+		it("builds the lifecycle once per workspace", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					path, err := builder.Build(gocontext.Background(), "some-source-uri", "/some/workspace/lifecycle")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path).To(Equal("/some/workspace/lifecycle/lifecycle.tar.gz"))
+				}()
+			}
+			wg.Wait()
+
+			Expect(lifecycleBuilder.BuildCall.CallCount).To(Equal(1))
+			Expect(lifecycleBuilder.BuildCall.Receives.Ctx).To(Equal(gocontext.Background()))
+			Expect(lifecycleBuilder.BuildCall.Receives.SourceURI).To(Equal("some-source-uri"))
+			Expect(lifecycleBuilder.BuildCall.Receives.Workspace).To(Equal("/some/workspace/lifecycle"))
+			Expect(filepath.Join(locks, "lifecycle.lock")).To(BeAnExistingFile())
+
+			_, err := builder.Build(gocontext.Background(), "some-source-uri", "/other/workspace/lifecycle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lifecycleBuilder.BuildCall.CallCount).To(Equal(2))
+		})
+
+		context("when the build fails", func() {
+			it.Before(func() {
+				lifecycleBuilder.BuildCall.Returns.Err = errors.New("could not build lifecycle")
+			})
+
+			it("retries on the next call", func() {
+				_, err := builder.Build(gocontext.Background(), "some-source-uri", "/some/workspace/lifecycle")
+				Expect(err).To(MatchError("could not build lifecycle"))
+
+				lifecycleBuilder.BuildCall.Returns.Err = nil
+
+				path, err := builder.Build(gocontext.Background(), "some-source-uri", "/some/workspace/lifecycle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path).To(Equal("/some/workspace/lifecycle/lifecycle.tar.gz"))
+				Expect(lifecycleBuilder.BuildCall.CallCount).To(Equal(2))
+			})
+		})
+
+		context("when another build holds the lock", func() {
+			var release chan struct{}
+
+			it.Before(func() {
+				release = make(chan struct{})
+				started := make(chan struct{})
+				lifecycleBuilder.BuildCall.Stub = func(gocontext.Context, string, string) (string, error) {
+					close(started)
+					<-release
+					return "/some/workspace/lifecycle/lifecycle.tar.gz", nil
+				}
+
+				go func() {
+					_, _ = builder.Build(gocontext.Background(), "some-source-uri", "/some/workspace/lifecycle")
+				}()
+				<-started
+			})
+
+			it.After(func() {
+				close(release)
+			})
+
+			it("gives up waiting when the context is done", func() {
+				ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+				defer cancel()
+
+				_, err := builder.Build(ctx, "some-source-uri", "/other/workspace/lifecycle")
+				Expect(err).To(MatchError(ContainSubstring("failed to lock lifecycle:")))
+				Expect(err).To(MatchError(gocontext.DeadlineExceeded))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the lock file cannot be created", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(locks, "not-a-directory"), nil, 0600)).To(Succeed())
+					builder = docker.NewOnceLifecycleBuilder(lifecycleBuilder, filepath.Join(locks, "not-a-directory"))
+				})
+
+				it("returns an error", func() {
+					_, err := builder.Build(gocontext.Background(), "some-source-uri", "/some/workspace/lifecycle")
+					Expect(err).To(MatchError(ContainSubstring("failed to lock lifecycle:")))
+					Expect(lifecycleBuilder.BuildCall.CallCount).To(Equal(0))
+				})
+			})
+		})
+	})
+}
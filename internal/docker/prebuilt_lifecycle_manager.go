@@ -0,0 +1,112 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+)
+
+type PrebuiltLifecycleManager struct {
+	archiver Archiver
+	uri      string
+	version  string
+	m        *sync.Mutex
+}
+
+func NewPrebuiltLifecycleManager(archiver Archiver, uri, version string) PrebuiltLifecycleManager {
+	return PrebuiltLifecycleManager{
+		archiver: archiver,
+		uri:      uri,
+		version:  version,
+		m:        &sync.Mutex{},
+	}
+}
+
+func (m PrebuiltLifecycleManager) Build(ctx context.Context, sourceURI, workspace string) (string, error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	uri := strings.NewReplacer(
+		"{version}", m.version,
+		"{os}", lifecycleGOOS,
+		"{arch}", lifecycleGOARCH,
+	).Replace(m.uri)
+
+	workspace = filepath.Join(workspace, "prebuilt")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	etag, err := os.ReadFile(filepath.Join(workspace, "etag"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read etag: %w", err)
+	}
+
+	source, err := os.ReadFile(filepath.Join(workspace, "source"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read lifecycle source: %w", err)
+	}
+
+	if len(etag) > 0 && string(source) == uri {
+		req.Header.Set("If-None-Match", string(etag))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to complete request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	output := filepath.Join(workspace, "lifecycle.tar.gz")
+	if resp.StatusCode == http.StatusNotModified {
+		return output, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download prebuilt lifecycle: unexpected response status %q from %s", resp.Status, uri)
+	}
+
+	err = os.RemoveAll(workspace)
+	if err != nil {
+		return "", fmt.Errorf("failed to clear workspace: %w", err)
+	}
+
+	binaries := filepath.Join(workspace, "binaries")
+	err = vacation.NewArchive(resp.Body).Decompress(binaries)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress prebuilt lifecycle: %w", err)
+	}
+
+	for _, binary := range []string{"builder", "launcher"} {
+		_, err = os.Stat(filepath.Join(binaries, binary))
+		if err != nil {
+			return "", fmt.Errorf("failed to find prebuilt lifecycle %s: %w", binary, err)
+		}
+	}
+
+	err = m.archiver.WithPrefix("/tmp/lifecycle").Compress(binaries, output)
+	if err != nil {
+		return "", fmt.Errorf("failed to archive lifecycle: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(workspace, "source"), []byte(uri), 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write lifecycle source file: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(workspace, "etag"), []byte(resp.Header.Get("ETag")), 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write lifecycle etag file: %w", err)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,208 @@
+package docker_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	gocontext "context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testPrebuiltLifecycleManager(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Build", func() {
+		var (
+			workspace string
+			requests  []*http.Request
+			server    *httptest.Server
+			archiver  *fakes.Archiver
+
+			manager docker.PrebuiltLifecycleManager
+		)
+
+		it.Before(func() {
+			var err error
+			workspace, err = os.MkdirTemp("", "workspace")
+			Expect(err).NotTo(HaveOccurred())
+
+			requests = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				requests = append(requests, req)
+
+				if req.URL.Path == "/missing/lifecycle-1.2.3-linux-amd64.tgz" {
+					http.NotFound(w, req)
+					return
+				}
+
+				w.Header().Set("ETag", "some-etag")
+
+				if req.Header.Get("If-None-Match") == "some-etag" {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				binaries := []string{"builder", "launcher"}
+				if req.URL.Path == "/incomplete/lifecycle-1.2.3-linux-amd64.tgz" {
+					binaries = []string{"builder"}
+				}
+
+				gw := gzip.NewWriter(w)
+				tw := tar.NewWriter(gw)
+				for _, binary := range binaries {
+					err := tw.WriteHeader(&tar.Header{Name: binary, Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg})
+					if err != nil {
+						http.Error(w, err.Error(), http.StatusInternalServerError)
+						return
+					}
+
+					_, err = tw.Write([]byte(binary))
+					if err != nil {
+						http.Error(w, err.Error(), http.StatusInternalServerError)
+						return
+					}
+				}
+
+				err := tw.Close()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+
+				err = gw.Close()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}))
+
+			archiver = &fakes.Archiver{}
+			archiver.WithPrefixCall.Returns.Archiver = archiver
+
+			manager = docker.NewPrebuiltLifecycleManager(archiver, server.URL+"/releases/lifecycle-{version}-{os}-{arch}.tgz", "1.2.3")
+		})
+
+		it.After(func() {
+			server.Close()
+			Expect(os.RemoveAll(workspace)).To(Succeed())
+		})
+
+		it("downloads the prebuilt lifecycle", func() {
+			path, err := manager.Build(gocontext.Background(), "some-source-uri", workspace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal(filepath.Join(workspace, "prebuilt", "lifecycle.tar.gz")))
+
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].URL.Path).To(Equal("/releases/lifecycle-1.2.3-linux-amd64.tgz"))
+
+			Expect(archiver.WithPrefixCall.Receives.Prefix).To(Equal("/tmp/lifecycle"))
+			Expect(archiver.CompressCall.Receives.Input).To(Equal(filepath.Join(workspace, "prebuilt", "binaries")))
+			Expect(archiver.CompressCall.Receives.Output).To(Equal(filepath.Join(workspace, "prebuilt", "lifecycle.tar.gz")))
+
+			content, err := os.ReadFile(filepath.Join(workspace, "prebuilt", "binaries", "builder"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("builder"))
+
+			content, err = os.ReadFile(filepath.Join(workspace, "prebuilt", "binaries", "launcher"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("launcher"))
+		})
+
+		context("when the lifecycle has already been downloaded", func() {
+			it.Before(func() {
+				_, err := manager.Build(gocontext.Background(), "some-source-uri", workspace)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("does not download it again", func() {
+				path, err := manager.Build(gocontext.Background(), "some-source-uri", workspace)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path).To(Equal(filepath.Join(workspace, "prebuilt", "lifecycle.tar.gz")))
+
+				Expect(requests).To(HaveLen(2))
+				Expect(requests[1].Header.Get("If-None-Match")).To(Equal("some-etag"))
+				Expect(archiver.CompressCall.CallCount).To(Equal(1))
+			})
+
+			context("when the version has changed", func() {
+				it("downloads the new version", func() {
+					manager = docker.NewPrebuiltLifecycleManager(archiver, server.URL+"/other/lifecycle-{version}-{os}-{arch}.tgz", "1.2.3")
+
+					_, err := manager.Build(gocontext.Background(), "some-source-uri", workspace)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(requests).To(HaveLen(2))
+					Expect(requests[1].Header.Get("If-None-Match")).To(BeEmpty())
+					Expect(archiver.CompressCall.CallCount).To(Equal(2))
+				})
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the request cannot be created", func() {
+				it.Before(func() {
+					manager = docker.NewPrebuiltLifecycleManager(archiver, "%%%", "1.2.3")
+				})
+
+				it("returns an error", func() {
+					_, err := manager.Build(gocontext.Background(), "some-source-uri", workspace)
+					Expect(err).To(MatchError(ContainSubstring("failed to create request:")))
+				})
+			})
+
+			context("when the request cannot be completed", func() {
+				it.Before(func() {
+					manager = docker.NewPrebuiltLifecycleManager(archiver, "this is not a url", "1.2.3")
+				})
+
+				it("returns an error", func() {
+					_, err := manager.Build(gocontext.Background(), "some-source-uri", workspace)
+					Expect(err).To(MatchError(ContainSubstring("failed to complete request:")))
+				})
+			})
+
+			context("when the lifecycle cannot be found", func() {
+				it.Before(func() {
+					manager = docker.NewPrebuiltLifecycleManager(archiver, server.URL+"/missing/lifecycle-{version}-{os}-{arch}.tgz", "1.2.3")
+				})
+
+				it("returns an error", func() {
+					_, err := manager.Build(gocontext.Background(), "some-source-uri", workspace)
+					Expect(err).To(MatchError(ContainSubstring(`failed to download prebuilt lifecycle: unexpected response status "404 Not Found"`)))
+				})
+			})
+
+			context("when the lifecycle is missing a binary", func() {
+				it.Before(func() {
+					manager = docker.NewPrebuiltLifecycleManager(archiver, server.URL+"/incomplete/lifecycle-{version}-{os}-{arch}.tgz", "1.2.3")
+				})
+
+				it("returns an error", func() {
+					_, err := manager.Build(gocontext.Background(), "some-source-uri", workspace)
+					Expect(err).To(MatchError(ContainSubstring("failed to find prebuilt lifecycle launcher:")))
+				})
+			})
+
+			context("when the lifecycle cannot be archived", func() {
+				it.Before(func() {
+					archiver.CompressCall.Returns.Error = errors.New("could not compress")
+				})
+
+				it("returns an error", func() {
+					_, err := manager.Build(gocontext.Background(), "some-source-uri", workspace)
+					Expect(err).To(MatchError("failed to archive lifecycle: could not compress"))
+				})
+			})
+		})
+	})
+}
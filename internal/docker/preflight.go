@@ -0,0 +1,128 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/docker/errdefs"
+)
+
+const (
+	MinimumAPIVersion = "1.41"
+	MinimumFreeSpace  = 5 << 30
+)
+
+type Problem struct {
+	Check   string
+	Message string
+	Remedy  string
+}
+
+//go:generate faux --interface PreflightClient --output fakes/preflight_client.go
+type PreflightClient interface {
+	Ping(ctx context.Context) (types.Ping, error)
+	ServerVersion(ctx context.Context) (types.Version, error)
+	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	DistributionInspect(ctx context.Context, image, encodedRegistryAuth string) (registry.DistributionInspect, error)
+}
+
+type Preflight struct {
+	client    PreflightClient
+	workspace string
+	stack     string
+	freeSpace func(path string) (uint64, error)
+}
+
+func NewPreflight(client PreflightClient, workspace, stack string) Preflight {
+	return Preflight{
+		client:    client,
+		workspace: workspace,
+		stack:     stack,
+		freeSpace: freeSpace,
+	}
+}
+
+func (p Preflight) WithFreeSpace(freeSpace func(path string) (uint64, error)) Preflight {
+	p.freeSpace = freeSpace
+	return p
+}
+
+func (p Preflight) Run(ctx context.Context) ([]Problem, error) {
+	var problems []Problem
+
+	available, err := p.freeSpace(p.workspace)
+	if err != nil {
+		problems = append(problems, Problem{
+			Check:   "disk",
+			Message: fmt.Sprintf("could not determine the free space in %s: %s", p.workspace, err),
+			Remedy:  "Check that the workspace directory exists and is readable, or choose another with WithWorkspace.",
+		})
+	} else if available < MinimumFreeSpace {
+		problems = append(problems, Problem{
+			Check:   "disk",
+			Message: fmt.Sprintf("only %d MiB is free in %s, but at least %d MiB is needed for droplets and build caches", available>>20, p.workspace, MinimumFreeSpace>>20),
+			Remedy:  "Free up space, run platform.GC, or choose a larger volume with WithWorkspace.",
+		})
+	}
+
+	_, err = p.client.Ping(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Nothing else can be checked without a daemon.
+		return append(problems, Problem{
+			Check:   "daemon",
+			Message: fmt.Sprintf("could not reach the Docker daemon: %s", err),
+			Remedy:  "Start the Docker daemon, or point DOCKER_HOST at a running one.",
+		}), nil
+	}
+
+	version, err := p.client.ServerVersion(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		problems = append(problems, Problem{
+			Check:   "api-version",
+			Message: fmt.Sprintf("could not read the Docker daemon version: %s", err),
+			Remedy:  "Check that the Docker daemon is healthy.",
+		})
+	} else if versions.LessThan(version.APIVersion, MinimumAPIVersion) {
+		problems = append(problems, Problem{
+			Check:   "api-version",
+			Message: fmt.Sprintf("Docker %s supports API version %s, but at least %s is required", version.Version, version.APIVersion, MinimumAPIVersion),
+			Remedy:  "Upgrade to Docker 20.10 or later.",
+		})
+	}
+
+	image := stackImage(p.stack)
+	_, _, err = p.client.ImageInspectWithRaw(ctx, image)
+	if err != nil && errdefs.IsNotFound(err) {
+		_, err = p.client.DistributionInspect(ctx, image, "")
+
+		// The registry cannot be asked without the distribution API, so the
+		// image is left to be pulled by the first deployment.
+		if errdefs.IsNotImplemented(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		problems = append(problems, Problem{
+			Check:   "stack",
+			Message: fmt.Sprintf("stack image %s is not available locally and could not be found in its registry: %s", image, err),
+			Remedy:  fmt.Sprintf("Check the stack name and registry access, or run `docker pull %s`.", image),
+		})
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,196 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+func testPreflight(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Run", func() {
+		var (
+			preflight docker.Preflight
+			client    *fakes.PreflightClient
+			workspace string
+			space     uint64
+		)
+
+		it.Before(func() {
+			var err error
+			workspace, err = os.MkdirTemp("", "workspace")
+			Expect(err).NotTo(HaveOccurred())
+
+			client = &fakes.PreflightClient{}
+			client.ServerVersionCall.Returns.Version = types.Version{Version: "24.0.5", APIVersion: "1.43"}
+
+			space = 20 << 30
+			preflight = docker.NewPreflight(client, workspace, "cflinuxfs4").
+				WithFreeSpace(func(path string) (uint64, error) {
+					return space, nil
+				})
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workspace)).To(Succeed())
+		})
+
+		it("finds no problems with a healthy environment", func() {
+			problems, err := preflight.Run(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+
+			Expect(client.PingCall.CallCount).To(Equal(1))
+			Expect(client.ImageInspectWithRawCall.Receives.ImageID).To(Equal("cloudfoundry/cflinuxfs4:latest"))
+			Expect(client.DistributionInspectCall.CallCount).To(Equal(0))
+		})
+
+		it("measures the free space in the workspace", func() {
+			var path string
+			preflight = preflight.WithFreeSpace(func(p string) (uint64, error) {
+				path = p
+				return space, nil
+			})
+
+			_, err := preflight.Run(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal(workspace))
+		})
+
+		context("when the stack image is not available locally", func() {
+			it.Before(func() {
+				client.ImageInspectWithRawCall.Returns.Error = errdefs.NotFound(errors.New("no such image"))
+			})
+
+			it("checks that its registry has it", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(BeEmpty())
+
+				Expect(client.DistributionInspectCall.Receives.Image).To(Equal("cloudfoundry/cflinuxfs4:latest"))
+			})
+
+			context("when the registry does not have it either", func() {
+				it.Before(func() {
+					client.DistributionInspectCall.Returns.Error = errors.New("manifest unknown")
+				})
+
+				it("reports a problem", func() {
+					problems, err := preflight.Run(gocontext.Background())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+						"Check":   Equal("stack"),
+						"Message": ContainSubstring("stack image cloudfoundry/cflinuxfs4:latest is not available locally and could not be found in its registry: manifest unknown"),
+						"Remedy":  ContainSubstring("docker pull cloudfoundry/cflinuxfs4:latest"),
+					})))
+				})
+			})
+
+			context("when the daemon does not implement the distribution API", func() {
+				it.Before(func() {
+					client.DistributionInspectCall.Returns.Error = errdefs.NotImplemented(errors.New("not implemented"))
+				})
+
+				it("leaves the image to be pulled on deploy", func() {
+					problems, err := preflight.Run(gocontext.Background())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(problems).To(BeEmpty())
+				})
+			})
+		})
+
+		context("when the daemon is too old", func() {
+			it.Before(func() {
+				client.ServerVersionCall.Returns.Version = types.Version{Version: "19.03.15", APIVersion: "1.40"}
+			})
+
+			it("reports a problem", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Check":   Equal("api-version"),
+					"Message": Equal("Docker 19.03.15 supports API version 1.40, but at least 1.41 is required"),
+				})))
+			})
+		})
+
+		context("when the workspace is short of space", func() {
+			it.Before(func() {
+				space = 1 << 30
+			})
+
+			it("reports a problem", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Check":   Equal("disk"),
+					"Message": ContainSubstring("only 1024 MiB is free"),
+				})))
+			})
+		})
+
+		context("when the daemon cannot be reached", func() {
+			it.Before(func() {
+				client.PingCall.Returns.Error = errors.New("Cannot connect to the Docker daemon")
+				space = 1 << 30
+			})
+
+			it("reports the problems it could find without it", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(
+					MatchFields(IgnoreExtras, Fields{"Check": Equal("disk")}),
+					MatchFields(IgnoreExtras, Fields{
+						"Check":   Equal("daemon"),
+						"Message": Equal("could not reach the Docker daemon: Cannot connect to the Docker daemon"),
+					}),
+				))
+
+				Expect(client.ServerVersionCall.CallCount).To(Equal(0))
+				Expect(client.ImageInspectWithRawCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("when the free space cannot be read", func() {
+			it.Before(func() {
+				preflight = preflight.WithFreeSpace(func(string) (uint64, error) {
+					return 0, errors.New("no such file or directory")
+				})
+			})
+
+			it("reports a problem", func() {
+				problems, err := preflight.Run(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(problems).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Check":   Equal("disk"),
+					"Message": ContainSubstring("no such file or directory"),
+				})))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the context is done", func() {
+				it("returns the context error", func() {
+					ctx, cancel := gocontext.WithCancel(gocontext.Background())
+					cancel()
+
+					client.PingCall.Returns.Error = gocontext.Canceled
+
+					_, err := preflight.Run(ctx)
+					Expect(err).To(MatchError(gocontext.Canceled))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+)
+
+const profileDirectory = "app/.profile.d"
+
+func profileScriptsTarball(scripts map[string]string) (*bytes.Buffer, error) {
+	var names []string
+	for name := range scripts {
+		if name == "" || name != path.Base(name) {
+			return nil, fmt.Errorf("failed to add profile script %q: name must not contain a path", name)
+		}
+
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buffer := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buffer)
+
+	err := tw.WriteHeader(&tar.Header{
+		Name:     profileDirectory + "/",
+		Mode:     0755,
+		Typeflag: tar.TypeDir,
+		Uid:      2000,
+		Gid:      2000,
+		Uname:    "vcap",
+		Gname:    "vcap",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		err = tw.WriteHeader(&tar.Header{
+			Name:     path.Join(profileDirectory, name),
+			Mode:     0755,
+			Size:     int64(len(scripts[name])),
+			Typeflag: tar.TypeReg,
+			Uid:      2000,
+			Gid:      2000,
+			Uname:    "vcap",
+			Gname:    "vcap",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tw.Write([]byte(scripts[name]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
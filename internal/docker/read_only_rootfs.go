@@ -0,0 +1,36 @@
+package docker
+
+import (
+	"io"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+const LifecycleDirectory = "/tmp/lifecycle"
+
+func readOnlyRootFSMounts() []mount.Mount {
+	return []mount.Mount{
+		{Type: mount.TypeVolume, Target: "/home/vcap"},
+		{Type: mount.TypeVolume, Target: LifecycleDirectory},
+	}
+}
+
+func relocateTarball(tarball io.Reader, dir string) io.Reader {
+	prefix := strings.TrimPrefix(path.Clean(dir), "/") + "/"
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(filterTarball(tarball, w, func(name string) (string, bool) {
+			name = strings.TrimPrefix(path.Clean("/"+name), "/")
+			if !strings.HasPrefix(name, prefix) {
+				return "", false
+			}
+
+			return strings.TrimPrefix(name, prefix), true
+		}))
+	}()
+
+	return r
+}
@@ -0,0 +1,176 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	ReaperImage = "testcontainers/ryuk:0.5.1"
+	ReaperLabel = "switchblade.reaper"
+)
+
+//go:generate faux --interface ReaperClient --output fakes/reaper_client.go
+type ReaperClient interface {
+	ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error)
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error)
+	ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error
+	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+}
+
+type Reaper struct {
+	client  ReaperClient
+	image   string
+	socket  string
+	host    string
+	timeout time.Duration
+	dial    func(address string) (net.Conn, error)
+	clock   Clock
+}
+
+func NewReaper(client ReaperClient) Reaper {
+	return Reaper{
+		client:  client,
+		image:   ReaperImage,
+		host:    "localhost",
+		socket:  DefaultDaemonSocket,
+		timeout: 10 * time.Second,
+		dial: func(address string) (net.Conn, error) {
+			return net.DialTimeout("tcp", address, time.Second)
+		},
+		clock: realClock{},
+	}
+}
+
+func (r Reaper) WithImage(image string) Reaper {
+	r.image = image
+	return r
+}
+
+func (r Reaper) WithSocket(socket string) Reaper {
+	r.socket = socket
+	return r
+}
+
+func (r Reaper) WithHost(host string) Reaper {
+	r.host = host
+	return r
+}
+
+func (r Reaper) WithTimeout(timeout time.Duration) Reaper {
+	r.timeout = timeout
+	return r
+}
+
+func (r Reaper) WithDialer(dial func(address string) (net.Conn, error)) Reaper {
+	r.dial = dial
+	return r
+}
+
+func (r Reaper) WithClock(clock Clock) Reaper {
+	r.clock = clock
+	return r
+}
+
+func (r Reaper) Start(ctx context.Context, runID string) (io.Closer, error) {
+	pullLogs, err := r.client.ImagePull(ctx, r.image, types.ImagePullOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull reaper image: %w", err)
+	}
+	defer pullLogs.Close()
+
+	_, err = io.Copy(io.Discard, pullLogs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy image pull logs: %w", err)
+	}
+
+	containerConfig := container.Config{
+		Image:        r.image,
+		ExposedPorts: nat.PortSet{"8080/tcp": struct{}{}},
+		Labels:       map[string]string{ReaperLabel: runID},
+	}
+
+	hostConfig := container.HostConfig{
+		AutoRemove:      true,
+		PublishAllPorts: true,
+		Binds:           []string{fmt.Sprintf("%s:/var/run/docker.sock", r.socket)},
+	}
+
+	resp, err := r.client.ContainerCreate(ctx, &containerConfig, &hostConfig, nil, nil, fmt.Sprintf("%s-reaper", runID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reaper container: %w", err)
+	}
+
+	err = r.client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start reaper container: %w", err)
+	}
+
+	reaper, err := r.client.ContainerInspect(ctx, resp.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect reaper container: %w", err)
+	}
+
+	var port string
+	if reaper.NetworkSettings != nil {
+		for _, binding := range reaper.NetworkSettings.Ports["8080/tcp"] {
+			port = binding.HostPort
+			break
+		}
+	}
+
+	if port == "" {
+		return nil, fmt.Errorf("failed to find reaper port for container %s", resp.ID)
+	}
+
+	conn, err := r.connect(net.JoinHostPort(r.host, port))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintf(conn, "label=%s=%s\n", RunLabel, runID)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to register reaper filter: %w", err)
+	}
+
+	ack, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read reaper acknowledgement: %w", err)
+	}
+
+	if strings.TrimSpace(ack) != "ACK" {
+		conn.Close()
+		return nil, fmt.Errorf("received unexpected reaper acknowledgement: %q", ack)
+	}
+
+	return conn, nil
+}
+
+func (r Reaper) connect(address string) (net.Conn, error) {
+	deadline := r.clock.Now().Add(r.timeout)
+	for {
+		conn, err := r.dial(address)
+		if err == nil {
+			return conn, nil
+		}
+
+		if r.clock.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to connect to reaper: %w", err)
+		}
+
+		<-r.clock.After(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,263 @@
+package docker_test
+
+import (
+	"bufio"
+	gocontext "context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testReaper(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Start", func() {
+		var (
+			reaper docker.Reaper
+
+			client   *fakes.ReaperClient
+			server   net.Conn
+			address  string
+			received chan string
+			ack      string
+			pipe     func(string) (net.Conn, error)
+		)
+
+		it.Before(func() {
+			client = &fakes.ReaperClient{}
+			client.ImagePullCall.Returns.ReadCloser = io.NopCloser(strings.NewReader("pulling..."))
+			client.ContainerCreateCall.Returns.CreateResponse = container.CreateResponse{ID: "some-reaper-id"}
+			client.ContainerInspectCall.Returns.ContainerJSON = types.ContainerJSON{
+				NetworkSettings: &types.NetworkSettings{
+					NetworkSettingsBase: types.NetworkSettingsBase{
+						Ports: nat.PortMap{
+							"8080/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "12345"}},
+						},
+					},
+				},
+			}
+
+			ack = "ACK\n"
+			received = make(chan string, 1)
+
+			pipe = func(addr string) (net.Conn, error) {
+				address = addr
+
+				var conn net.Conn
+				conn, server = net.Pipe()
+				go func() {
+					line, _ := bufio.NewReader(server).ReadString('\n')
+					received <- line
+					fmt.Fprint(server, ack)
+				}()
+
+				return conn, nil
+			}
+
+			reaper = docker.NewReaper(client).WithDialer(pipe)
+		})
+
+		it("starts the reaper and registers the run with it", func() {
+			session, err := reaper.Start(gocontext.Background(), "some-run")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ImagePullCall.Receives.Ref).To(Equal("testcontainers/ryuk:0.5.1"))
+
+			Expect(client.ContainerCreateCall.Receives.ContainerName).To(Equal("some-run-reaper"))
+			Expect(client.ContainerCreateCall.Receives.Config).To(Equal(&container.Config{
+				Image:        "testcontainers/ryuk:0.5.1",
+				ExposedPorts: nat.PortSet{"8080/tcp": struct{}{}},
+				Labels:       map[string]string{"switchblade.reaper": "some-run"},
+			}))
+			Expect(client.ContainerCreateCall.Receives.HostConfig).To(Equal(&container.HostConfig{
+				AutoRemove:      true,
+				PublishAllPorts: true,
+				Binds:           []string{"/var/run/docker.sock:/var/run/docker.sock"},
+			}))
+
+			Expect(client.ContainerStartCall.Receives.ContainerID).To(Equal("some-reaper-id"))
+			Expect(client.ContainerInspectCall.Receives.ContainerID).To(Equal("some-reaper-id"))
+
+			Expect(address).To(Equal("localhost:12345"))
+			Expect(<-received).To(Equal("label=switchblade.run=some-run\n"))
+
+			Expect(session.Close()).To(Succeed())
+
+			_, err = server.Read(make([]byte, 1))
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		context("WithImage", func() {
+			it("uses the given reaper image", func() {
+				session, err := reaper.WithImage("some-registry/ryuk:latest").Start(gocontext.Background(), "some-run")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(session.Close()).To(Succeed())
+
+				Expect(client.ImagePullCall.Receives.Ref).To(Equal("some-registry/ryuk:latest"))
+				Expect(client.ContainerCreateCall.Receives.Config.Image).To(Equal("some-registry/ryuk:latest"))
+			})
+		})
+
+		context("WithSocket", func() {
+			it("mounts the given daemon socket into the reaper", func() {
+				session, err := reaper.WithSocket("/run/user/1000/docker.sock").Start(gocontext.Background(), "some-run")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(session.Close()).To(Succeed())
+
+				Expect(client.ContainerCreateCall.Receives.HostConfig.Binds).To(Equal([]string{"/run/user/1000/docker.sock:/var/run/docker.sock"}))
+			})
+		})
+
+		context("WithHost", func() {
+			it("connects to the reaper on the given host", func() {
+				session, err := reaper.WithHost("staging-host.example.com").Start(gocontext.Background(), "some-run")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(session.Close()).To(Succeed())
+
+				Expect(address).To(Equal("staging-host.example.com:12345"))
+			})
+		})
+
+		context("when the reaper is not accepting connections yet", func() {
+			it("retries until it can connect", func() {
+				attempts := 0
+				reaper = reaper.WithDialer(func(addr string) (net.Conn, error) {
+					attempts++
+					if attempts < 3 {
+						return nil, errors.New("connection refused")
+					}
+
+					return pipe(addr)
+				})
+
+				session, err := reaper.Start(gocontext.Background(), "some-run")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(session.Close()).To(Succeed())
+				Expect(attempts).To(Equal(3))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the image cannot be pulled", func() {
+				it.Before(func() {
+					client.ImagePullCall.Returns.Error = errors.New("could not pull")
+				})
+
+				it("returns an error", func() {
+					_, err := reaper.Start(gocontext.Background(), "some-run")
+					Expect(err).To(MatchError("failed to pull reaper image: could not pull"))
+				})
+			})
+
+			context("when the container cannot be created", func() {
+				it.Before(func() {
+					client.ContainerCreateCall.Returns.Error = errors.New("could not create")
+				})
+
+				it("returns an error", func() {
+					_, err := reaper.Start(gocontext.Background(), "some-run")
+					Expect(err).To(MatchError("failed to create reaper container: could not create"))
+				})
+			})
+
+			context("when the container cannot be started", func() {
+				it.Before(func() {
+					client.ContainerStartCall.Returns.Error = errors.New("could not start")
+				})
+
+				it("returns an error", func() {
+					_, err := reaper.Start(gocontext.Background(), "some-run")
+					Expect(err).To(MatchError("failed to start reaper container: could not start"))
+				})
+			})
+
+			context("when the container cannot be inspected", func() {
+				it.Before(func() {
+					client.ContainerInspectCall.Returns.Error = errors.New("could not inspect")
+				})
+
+				it("returns an error", func() {
+					_, err := reaper.Start(gocontext.Background(), "some-run")
+					Expect(err).To(MatchError("failed to inspect reaper container: could not inspect"))
+				})
+			})
+
+			context("when the container does not publish its port", func() {
+				it.Before(func() {
+					client.ContainerInspectCall.Returns.ContainerJSON.NetworkSettings.Ports = nat.PortMap{}
+				})
+
+				it("returns an error", func() {
+					_, err := reaper.Start(gocontext.Background(), "some-run")
+					Expect(err).To(MatchError("failed to find reaper port for container some-reaper-id"))
+				})
+			})
+
+			context("when the reaper cannot be reached", func() {
+				it.Before(func() {
+					reaper = reaper.WithTimeout(time.Millisecond).WithDialer(func(string) (net.Conn, error) {
+						return nil, errors.New("connection refused")
+					})
+				})
+
+				it("returns an error", func() {
+					_, err := reaper.Start(gocontext.Background(), "some-run")
+					Expect(err).To(MatchError("failed to connect to reaper: connection refused"))
+				})
+
+				context("when a clock is provided", func() {
+					var clock *fakes.Clock
+
+					it.Before(func() {
+						now := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+						clock = &fakes.Clock{}
+						clock.NowCall.Stub = func() time.Time {
+							now = now.Add(30 * time.Second)
+							return now
+						}
+						clock.AfterCall.Stub = func(time.Duration) <-chan time.Time {
+							c := make(chan time.Time)
+							close(c)
+							return c
+						}
+
+						reaper = reaper.WithTimeout(time.Minute).WithClock(clock)
+					})
+
+					it("retries using that clock", func() {
+						_, err := reaper.Start(gocontext.Background(), "some-run")
+						Expect(err).To(MatchError("failed to connect to reaper: connection refused"))
+
+						Expect(clock.AfterCall.CallCount).To(Equal(2))
+						Expect(clock.AfterCall.Receives.D).To(Equal(100 * time.Millisecond))
+					})
+				})
+			})
+
+			context("when the reaper does not acknowledge the filter", func() {
+				it.Before(func() {
+					ack = "NOPE\n"
+				})
+
+				it("returns an error", func() {
+					_, err := reaper.Start(gocontext.Background(), "some-run")
+					Expect(err).To(MatchError(`received unexpected reaper acknowledgement: "NOPE\n"`))
+				})
+			})
+		})
+	})
+}
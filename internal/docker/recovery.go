@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+)
+
+//go:generate faux --interface RecoveryClient --output fakes/recovery_client.go
+type RecoveryClient interface {
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+	NetworkRemove(ctx context.Context, networkID string) error
+}
+
+type Recovery struct {
+	client  RecoveryClient
+	journal Journal
+}
+
+func NewRecovery(client RecoveryClient, journal Journal) Recovery {
+	return Recovery{
+		client:  client,
+		journal: journal,
+	}
+}
+
+func (r Recovery) Run(ctx context.Context) error {
+	entries, err := r.journal.Entries(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	for _, kind := range []string{"container", "image", "network"} {
+		for _, entry := range entries {
+			if entry.Type != kind {
+				continue
+			}
+
+			switch entry.Type {
+			case "container":
+				err = r.client.ContainerRemove(ctx, entry.ID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+				if err != nil && !errdefs.IsNotFound(err) {
+					return fmt.Errorf("failed to remove container: %w", err)
+				}
+
+			case "image":
+				_, err = r.client.ImageRemove(ctx, entry.ID, types.ImageRemoveOptions{Force: true})
+				if err != nil && !errdefs.IsNotFound(err) {
+					return fmt.Errorf("failed to remove image: %w", err)
+				}
+
+			case "network":
+				err = r.client.NetworkRemove(ctx, entry.ID)
+				if err != nil && !errdefs.IsNotFound(err) && !errdefs.IsForbidden(err) {
+					return fmt.Errorf("failed to remove network: %w", err)
+				}
+			}
+		}
+	}
+
+	err = r.journal.Remove(ctx, entries)
+	if err != nil {
+		return fmt.Errorf("failed to update journal: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRecovery(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		recovery docker.Recovery
+
+		client  *fakes.RecoveryClient
+		journal docker.Journal
+		dir     string
+		calls   []string
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "journal")
+		Expect(err).NotTo(HaveOccurred())
+
+		journal = docker.NewJournal(filepath.Join(dir, "journal.jsonl"))
+		Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "network", ID: "some-network-id"})).To(Succeed())
+		Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "container", ID: "some-container-id", App: "some-app"})).To(Succeed())
+		Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "image", ID: "some-image-id", App: "some-app"})).To(Succeed())
+
+		calls = nil
+		client = &fakes.RecoveryClient{}
+		client.ContainerRemoveCall.Stub = func(ctx gocontext.Context, containerID string, options types.ContainerRemoveOptions) error {
+			calls = append(calls, "container "+containerID)
+			return nil
+		}
+		client.ImageRemoveCall.Stub = func(ctx gocontext.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+			calls = append(calls, "image "+imageID)
+			return nil, nil
+		}
+		client.NetworkRemoveCall.Stub = func(ctx gocontext.Context, networkID string) error {
+			calls = append(calls, "network "+networkID)
+			return nil
+		}
+
+		recovery = docker.NewRecovery(client, journal)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	context("Run", func() {
+		it("removes the journaled resources and clears them from the journal", func() {
+			err := recovery.Run(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(calls).To(Equal([]string{
+				"container some-container-id",
+				"image some-image-id",
+				"network some-network-id",
+			}))
+			Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}))
+			Expect(client.ImageRemoveCall.Receives.Options).To(Equal(types.ImageRemoveOptions{Force: true}))
+
+			entries, err := journal.Entries(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		context("when the resources have already been removed", func() {
+			it.Before(func() {
+				client.ContainerRemoveCall.Stub = nil
+				client.ContainerRemoveCall.Returns.Error = errdefs.NotFound(errors.New("no such container"))
+				client.ImageRemoveCall.Stub = nil
+				client.ImageRemoveCall.Returns.Error = errdefs.NotFound(errors.New("no such image"))
+				client.NetworkRemoveCall.Stub = nil
+				client.NetworkRemoveCall.Returns.Error = errdefs.NotFound(errors.New("no such network"))
+			})
+
+			it("does not error", func() {
+				Expect(recovery.Run(gocontext.Background())).To(Succeed())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when a container cannot be removed", func() {
+				it.Before(func() {
+					client.ContainerRemoveCall.Stub = nil
+					client.ContainerRemoveCall.Returns.Error = errors.New("could not remove container")
+				})
+
+				it("returns an error and keeps the journal", func() {
+					err := recovery.Run(gocontext.Background())
+					Expect(err).To(MatchError("failed to remove container: could not remove container"))
+
+					entries, err := journal.Entries(gocontext.Background())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(entries).To(HaveLen(3))
+				})
+			})
+
+			context("when an image cannot be removed", func() {
+				it.Before(func() {
+					client.ImageRemoveCall.Stub = nil
+					client.ImageRemoveCall.Returns.Error = errors.New("could not remove image")
+				})
+
+				it("returns an error", func() {
+					err := recovery.Run(gocontext.Background())
+					Expect(err).To(MatchError("failed to remove image: could not remove image"))
+				})
+			})
+
+			context("when a network cannot be removed", func() {
+				it.Before(func() {
+					client.NetworkRemoveCall.Stub = nil
+					client.NetworkRemoveCall.Returns.Error = errors.New("could not remove network")
+				})
+
+				it("returns an error", func() {
+					err := recovery.Run(gocontext.Background())
+					Expect(err).To(MatchError("failed to remove network: could not remove network"))
+				})
+			})
+		})
+	})
+}
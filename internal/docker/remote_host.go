@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"net/url"
+)
+
+// RemoteHost returns the host name of a daemon reached over the network, such
+// as one configured with DOCKER_HOST=tcp://staging-host:2376, since ports that
+// it publishes are bound on that host rather than on this one. It returns an
+// empty string for daemons reached over a local socket or named pipe.
+func RemoteHost(daemonHost string) string {
+	uri, err := url.Parse(daemonHost)
+	if err != nil {
+		return ""
+	}
+
+	switch uri.Scheme {
+	case "tcp", "http", "https", "ssh":
+	default:
+		return ""
+	}
+
+	if isLocalhost(uri.Hostname()) {
+		return ""
+	}
+
+	return uri.Hostname()
+}
@@ -0,0 +1,32 @@
+package docker_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRemoteHost(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("RemoteHost", func() {
+		it("returns the host of a daemon reached over the network", func() {
+			Expect(docker.RemoteHost("tcp://staging-host.example.com:2376")).To(Equal("staging-host.example.com"))
+			Expect(docker.RemoteHost("https://10.0.0.5:2376")).To(Equal("10.0.0.5"))
+			Expect(docker.RemoteHost("ssh://ci@staging-host.example.com")).To(Equal("staging-host.example.com"))
+			Expect(docker.RemoteHost("tcp://[fd00::5]:2376")).To(Equal("fd00::5"))
+		})
+
+		context("when the daemon is local", func() {
+			it("returns an empty host", func() {
+				Expect(docker.RemoteHost("unix:///var/run/docker.sock")).To(BeEmpty())
+				Expect(docker.RemoteHost("npipe:////./pipe/docker_engine")).To(BeEmpty())
+				Expect(docker.RemoteHost("tcp://localhost:2375")).To(BeEmpty())
+				Expect(docker.RemoteHost("tcp://127.0.0.1:2375")).To(BeEmpty())
+			})
+		})
+	})
+}
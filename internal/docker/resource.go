@@ -0,0 +1,96 @@
+package docker
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/volume"
+)
+
+type Resource struct {
+	Kind string
+	Name string
+	ID   string
+	Age  time.Duration
+}
+
+type TeardownFailure struct {
+	Resource Resource
+	Error    string
+}
+
+type TeardownReport struct {
+	Removed []Resource
+	Missing []Resource
+	Failed  []TeardownFailure
+}
+
+func (r *TeardownReport) record(resource Resource, removed bool, err error) error {
+	switch {
+	case err != nil:
+		r.Failed = append(r.Failed, TeardownFailure{Resource: resource, Error: err.Error()})
+		return err
+	case removed:
+		r.Removed = append(r.Removed, resource)
+	default:
+		r.Missing = append(r.Missing, resource)
+	}
+
+	return nil
+}
+
+func removalResult(err error, isNotFound func(error) bool) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+
+	if isNotFound(err) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func isNotExist(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
+
+func volumeAge(v *volume.Volume, now time.Time) time.Duration {
+	created, err := time.Parse(time.RFC3339, v.CreatedAt)
+	if err != nil {
+		return 0
+	}
+
+	return now.Sub(created)
+}
+
+func containerName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	return strings.TrimPrefix(names[0], "/")
+}
+
+func imageName(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	return tags[0]
+}
+
+func fileResource(path string, now time.Time) (Resource, bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Resource{}, false, nil
+		}
+
+		return Resource{}, false, err
+	}
+
+	return Resource{Kind: "file", Name: path, Age: now.Sub(info.ModTime())}, true, nil
+}
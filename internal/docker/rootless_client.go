@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+const DefaultDaemonSocket = "/var/run/docker.sock"
+
+type RootlessClient struct {
+	client.CommonAPIClient
+}
+
+func NewRootlessClient(apiClient client.CommonAPIClient) RootlessClient {
+	return RootlessClient{CommonAPIClient: apiClient}
+}
+
+func (c RootlessClient) CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+	err := c.CommonAPIClient.CopyToContainer(ctx, containerID, dstPath, content, options)
+	if err == nil || !strings.Contains(err.Error(), "lchown") {
+		return err
+	}
+
+	info, infoErr := c.CommonAPIClient.Info(ctx)
+	if infoErr != nil || !isRootless(info) {
+		return err
+	}
+
+	return fmt.Errorf("%w: the rootless docker daemon cannot map the vcap user (uid 2000) into the container, add a subordinate id range of at least 65536 ids for your user to /etc/subuid and /etc/subgid and restart the daemon", err)
+}
+
+//go:generate faux --interface DaemonSocketClient --output fakes/daemon_socket_client.go
+type DaemonSocketClient interface {
+	Info(ctx context.Context) (types.Info, error)
+	DaemonHost() string
+}
+
+func DaemonSocket(ctx context.Context, client DaemonSocketClient) (string, error) {
+	info, err := client.Info(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect docker daemon: %w", err)
+	}
+
+	host := client.DaemonHost()
+	if isRootless(info) && strings.HasPrefix(host, "unix://") {
+		return strings.TrimPrefix(host, "unix://"), nil
+	}
+
+	return DefaultDaemonSocket, nil
+}
+
+func isRootless(info types.Info) bool {
+	for _, option := range info.SecurityOptions {
+		if strings.Contains(option, "name=rootless") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+type copyingAPIClient struct {
+	client.CommonAPIClient
+
+	copyErr error
+	info    types.Info
+	infoErr error
+}
+
+func (c copyingAPIClient) CopyToContainer(ctx gocontext.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+	return c.copyErr
+}
+
+func (c copyingAPIClient) Info(ctx gocontext.Context) (types.Info, error) {
+	return c.info, c.infoErr
+}
+
+func testRootlessClient(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		apiClient copyingAPIClient
+	)
+
+	it.Before(func() {
+		apiClient = copyingAPIClient{
+			copyErr: errors.New("Error response from daemon: lchown /home/vcap/app: invalid argument"),
+			info:    types.Info{SecurityOptions: []string{"name=seccomp,profile=builtin", "name=rootless", "name=cgroupns"}},
+		}
+	})
+
+	context("CopyToContainer", func() {
+		it("explains ownership failures on a rootless daemon", func() {
+			err := docker.NewRootlessClient(apiClient).CopyToContainer(gocontext.Background(), "some-container", "/", nil, types.CopyToContainerOptions{})
+			Expect(err).To(MatchError(ContainSubstring("lchown /home/vcap/app: invalid argument: the rootless docker daemon cannot map the vcap user (uid 2000) into the container")))
+			Expect(err).To(MatchError(ContainSubstring("/etc/subuid and /etc/subgid")))
+			Expect(errors.Is(err, apiClient.copyErr)).To(BeTrue())
+		})
+
+		context("when the copy succeeds", func() {
+			it.Before(func() {
+				apiClient.copyErr = nil
+			})
+
+			it("returns no error", func() {
+				err := docker.NewRootlessClient(apiClient).CopyToContainer(gocontext.Background(), "some-container", "/", nil, types.CopyToContainerOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("when the daemon is not rootless", func() {
+			it.Before(func() {
+				apiClient.info = types.Info{SecurityOptions: []string{"name=seccomp,profile=builtin"}}
+			})
+
+			it("returns the original error", func() {
+				err := docker.NewRootlessClient(apiClient).CopyToContainer(gocontext.Background(), "some-container", "/", nil, types.CopyToContainerOptions{})
+				Expect(err).To(Equal(apiClient.copyErr))
+			})
+		})
+
+		context("when the failure is unrelated to ownership", func() {
+			it.Before(func() {
+				apiClient.copyErr = errors.New("no such container")
+			})
+
+			it("returns the original error", func() {
+				err := docker.NewRootlessClient(apiClient).CopyToContainer(gocontext.Background(), "some-container", "/", nil, types.CopyToContainerOptions{})
+				Expect(err).To(Equal(apiClient.copyErr))
+			})
+		})
+	})
+
+	context("DaemonSocket", func() {
+		var socketClient *fakes.DaemonSocketClient
+
+		it.Before(func() {
+			socketClient = &fakes.DaemonSocketClient{}
+			socketClient.InfoCall.Returns.Info = apiClient.info
+			socketClient.DaemonHostCall.Returns.String = "unix:///run/user/1000/docker.sock"
+		})
+
+		it("returns the socket of a rootless daemon", func() {
+			socket, err := docker.DaemonSocket(gocontext.Background(), socketClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(socket).To(Equal("/run/user/1000/docker.sock"))
+		})
+
+		context("when the daemon is not rootless", func() {
+			it.Before(func() {
+				socketClient.InfoCall.Returns.Info = types.Info{}
+			})
+
+			it("returns the default socket", func() {
+				socket, err := docker.DaemonSocket(gocontext.Background(), socketClient)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(socket).To(Equal("/var/run/docker.sock"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the daemon cannot be inspected", func() {
+				it.Before(func() {
+					socketClient.InfoCall.Returns.Error = errors.New("daemon unavailable")
+				})
+
+				it("returns an error", func() {
+					_, err := docker.DaemonSocket(gocontext.Background(), socketClient)
+					Expect(err).To(MatchError("failed to inspect docker daemon: daemon unavailable"))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	DockerEngineRuntime   = "docker-engine"
+	DockerDesktopRuntime  = "docker-desktop"
+	ColimaRuntime         = "colima"
+	RancherDesktopRuntime = "rancher-desktop"
+	PodmanRuntime         = "podman"
+)
+
+type Runtime struct {
+	Name       string
+	Version    string
+	HostName   string
+	ExtraHosts []string
+}
+
+//go:generate faux --interface RuntimeClient --output fakes/runtime_client.go
+type RuntimeClient interface {
+	Info(ctx context.Context) (types.Info, error)
+}
+
+func DetectRuntime(ctx context.Context, client RuntimeClient) (Runtime, error) {
+	info, err := client.Info(ctx)
+	if err != nil {
+		return Runtime{}, fmt.Errorf("failed to inspect docker daemon: %w", err)
+	}
+
+	runtime := Runtime{
+		Name:     DockerEngineRuntime,
+		Version:  info.ServerVersion,
+		HostName: HostGatewayName,
+	}
+
+	switch {
+	case strings.Contains(info.OperatingSystem, "Docker Desktop"):
+		runtime.Name = DockerDesktopRuntime
+	case strings.Contains(info.OperatingSystem, "Rancher Desktop") || strings.Contains(info.Name, "rancher-desktop"):
+		runtime.Name = RancherDesktopRuntime
+	case info.Name == "colima" || strings.HasPrefix(info.Name, "colima-"):
+		runtime.Name = ColimaRuntime
+		runtime.HostName = "host.lima.internal"
+	default:
+		if !supportsHostGateway(info.ServerVersion) {
+			return Runtime{}, fmt.Errorf("failed to detect container runtime: docker %s does not support the host-gateway address, upgrade the daemon to 20.10 or later", info.ServerVersion)
+		}
+
+		runtime.ExtraHosts = []string{fmt.Sprintf("%s:host-gateway", HostGatewayName)}
+	}
+
+	return runtime, nil
+}
+
+func supportsHostGateway(version string) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return true
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return true
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return true
+	}
+
+	return major > 20 || major == 20 && minor >= 10
+}
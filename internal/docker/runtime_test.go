@@ -0,0 +1,122 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRuntime(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		client *fakes.RuntimeClient
+	)
+
+	it.Before(func() {
+		client = &fakes.RuntimeClient{}
+		client.InfoCall.Returns.Info = types.Info{
+			Name:            "some-host",
+			OperatingSystem: "Ubuntu 22.04.2 LTS",
+			ServerVersion:   "24.0.2",
+		}
+	})
+
+	context("DetectRuntime", func() {
+		it("detects a Docker Engine daemon", func() {
+			runtime, err := docker.DetectRuntime(gocontext.Background(), client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runtime).To(Equal(docker.Runtime{
+				Name:       "docker-engine",
+				Version:    "24.0.2",
+				HostName:   "host.docker.internal",
+				ExtraHosts: []string{"host.docker.internal:host-gateway"},
+			}))
+		})
+
+		context("when the daemon is Docker Desktop", func() {
+			it.Before(func() {
+				client.InfoCall.Returns.Info.OperatingSystem = "Docker Desktop"
+			})
+
+			it("relies on the host name resolving natively", func() {
+				runtime, err := docker.DetectRuntime(gocontext.Background(), client)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(runtime.Name).To(Equal("docker-desktop"))
+				Expect(runtime.HostName).To(Equal("host.docker.internal"))
+				Expect(runtime.ExtraHosts).To(BeEmpty())
+			})
+		})
+
+		context("when the daemon is Colima", func() {
+			it.Before(func() {
+				client.InfoCall.Returns.Info.Name = "colima"
+			})
+
+			it("uses the Lima host name", func() {
+				runtime, err := docker.DetectRuntime(gocontext.Background(), client)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(runtime.Name).To(Equal("colima"))
+				Expect(runtime.HostName).To(Equal("host.lima.internal"))
+				Expect(runtime.ExtraHosts).To(BeEmpty())
+			})
+		})
+
+		context("when the daemon is a named Colima profile", func() {
+			it.Before(func() {
+				client.InfoCall.Returns.Info.Name = "colima-arm"
+			})
+
+			it("detects Colima", func() {
+				runtime, err := docker.DetectRuntime(gocontext.Background(), client)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(runtime.Name).To(Equal("colima"))
+			})
+		})
+
+		context("when the daemon is Rancher Desktop", func() {
+			it.Before(func() {
+				client.InfoCall.Returns.Info.Name = "lima-rancher-desktop"
+			})
+
+			it("relies on the host name resolving natively", func() {
+				runtime, err := docker.DetectRuntime(gocontext.Background(), client)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(runtime.Name).To(Equal("rancher-desktop"))
+				Expect(runtime.HostName).To(Equal("host.docker.internal"))
+				Expect(runtime.ExtraHosts).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the daemon cannot be inspected", func() {
+				it.Before(func() {
+					client.InfoCall.Returns.Error = errors.New("daemon unavailable")
+				})
+
+				it("returns an error", func() {
+					_, err := docker.DetectRuntime(gocontext.Background(), client)
+					Expect(err).To(MatchError("failed to inspect docker daemon: daemon unavailable"))
+				})
+			})
+
+			context("when the daemon is too old to support host-gateway", func() {
+				it.Before(func() {
+					client.InfoCall.Returns.Info.ServerVersion = "19.03.15"
+				})
+
+				it("returns an error", func() {
+					_, err := docker.DetectRuntime(gocontext.Background(), client)
+					Expect(err).To(MatchError("failed to detect container runtime: docker 19.03.15 does not support the host-gateway address, upgrade the daemon to 20.10 or later"))
+				})
+			})
+		})
+	})
+}
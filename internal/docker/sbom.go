@@ -0,0 +1,139 @@
+package docker
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SBOMComponentBuildpack  = "buildpack"
+	SBOMComponentDependency = "dependency"
+)
+
+type SBOMComponent struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Buildpack string `json:"buildpack,omitempty"`
+	URI       string `json:"uri,omitempty"`
+	SHA256    string `json:"sha256,omitempty"`
+}
+
+var installedDependency = regexp.MustCompile(`(?m)^-----> Installing (\S+) (\S+)\s*$`)
+
+func ReadSBOM(path string) ([]SBOMComponent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sbom: %w", err)
+	}
+	defer file.Close()
+
+	var components []SBOMComponent
+	err = json.NewDecoder(file).Decode(&components)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sbom: %w", err)
+	}
+
+	return components, nil
+}
+
+func parseInstalledDependencies(output string) []SBOMComponent {
+	var components []SBOMComponent
+	seen := map[string]struct{}{}
+	for _, match := range installedDependency.FindAllStringSubmatch(output, -1) {
+		key := match[1] + "@" + match[2]
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		components = append(components, SBOMComponent{
+			Type:    SBOMComponentDependency,
+			Name:    match[1],
+			Version: match[2],
+		})
+	}
+
+	return components
+}
+
+func parseDepsConfigs(r io.Reader) ([]SBOMComponent, error) {
+	var components []SBOMComponent
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve deps from tarball: %w", err)
+		}
+
+		if path.Base(hdr.Name) != "config.yml" || path.Dir(path.Dir(hdr.Name)) != "deps" {
+			continue
+		}
+
+		var config struct {
+			Name    string `yaml:"name"`
+			Version string `yaml:"version"`
+		}
+		err = yaml.NewDecoder(tr).Decode(&config)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse %s: %w", hdr.Name, err)
+		}
+
+		if config.Name == "" {
+			continue
+		}
+
+		components = append(components, SBOMComponent{
+			Type:    SBOMComponentBuildpack,
+			Name:    config.Name,
+			Version: config.Version,
+		})
+	}
+
+	return components, nil
+}
+
+type manifestDependency struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	URI     string `yaml:"uri"`
+	SHA256  string `yaml:"sha256"`
+}
+
+func parseManifestDependencies(r io.Reader) ([]manifestDependency, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve manifest.yml from tarball: %w", err)
+		}
+
+		if hdr.Name != "manifest.yml" {
+			continue
+		}
+
+		var manifest struct {
+			Dependencies []manifestDependency `yaml:"dependencies"`
+		}
+		err = yaml.NewDecoder(tr).Decode(&manifest)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse manifest.yml: %w", err)
+		}
+
+		return manifest.Dependencies, nil
+	}
+}
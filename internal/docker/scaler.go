@@ -0,0 +1,152 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const InstanceLabel = "switchblade.instance"
+
+//go:generate faux --interface ScalerClient --output fakes/scaler_client.go
+type ScalerClient interface {
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error)
+	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error
+	ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error
+	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+}
+
+type Scaler struct {
+	client    ScalerClient
+	networks  StartNetworkManager
+	workspace string
+}
+
+func NewScaler(client ScalerClient, networks StartNetworkManager, workspace string) Scaler {
+	return Scaler{
+		client:    client,
+		networks:  networks,
+		workspace: workspace,
+	}
+}
+
+func (s Scaler) Scale(ctx context.Context, name string, instances int) error {
+	if instances < 1 {
+		return errors.New("failed to scale: instances must be at least 1")
+	}
+
+	primary, err := s.client.ContainerInspect(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	containers, err := s.client.ContainerList(ctx, types.ContainerListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", AppLabel, name)),
+			filters.Arg("label", InstanceLabel),
+		),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	existing := map[int]bool{}
+	for _, c := range containers {
+		index, err := strconv.Atoi(c.Labels[InstanceLabel])
+		if err != nil {
+			return fmt.Errorf("failed to parse instance index: %w", err)
+		}
+
+		if index < instances {
+			existing[index] = true
+			continue
+		}
+
+		err = s.client.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+		if err != nil {
+			return fmt.Errorf("failed to remove instance: %w", err)
+		}
+	}
+
+	for index := 1; index < instances; index++ {
+		if existing[index] {
+			continue
+		}
+
+		err = s.start(ctx, primary, name, index)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s Scaler) start(ctx context.Context, primary types.ContainerJSON, name string, index int) error {
+	labels := map[string]string{}
+	for key, value := range primary.Config.Labels {
+		labels[key] = value
+	}
+	labels[InstanceLabel] = strconv.Itoa(index)
+
+	containerConfig := *primary.Config
+	containerConfig.Labels = labels
+	containerConfig.Env = append(append([]string{}, primary.Config.Env...), fmt.Sprintf("CF_INSTANCE_INDEX=%d", index))
+
+	hostConfig := container.HostConfig{
+		PublishAllPorts: true,
+		NetworkMode:     primary.HostConfig.NetworkMode,
+	}
+
+	resp, err := s.client.ContainerCreate(ctx, &containerConfig, &hostConfig, nil, nil, fmt.Sprintf("%s-%d", name, index))
+	if err != nil {
+		return fmt.Errorf("failed to create instance container: %w", err)
+	}
+
+	err = s.networks.Connect(ctx, resp.ID, BridgeNetworkName)
+	if err != nil {
+		return fmt.Errorf("failed to connect instance to network: %w", err)
+	}
+
+	lifecycleTarball, err := os.Open(filepath.Join(s.workspace, "lifecycle", "lifecycle.tar.gz"))
+	if err != nil {
+		return fmt.Errorf("failed to open lifecycle: %w", err)
+	}
+	defer lifecycleTarball.Close()
+
+	err = s.client.CopyToContainer(ctx, resp.ID, "/", lifecycleTarball, types.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to copy lifecycle into instance: %w", err)
+	}
+
+	dropletTarball, err := openDroplet(filepath.Join(s.workspace, "droplets"), name)
+	if err != nil {
+		return fmt.Errorf("failed to open droplet: %w", err)
+	}
+	defer dropletTarball.Close()
+
+	err = s.client.CopyToContainer(ctx, resp.ID, "/home/vcap/", dropletTarball, types.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to copy droplet into instance: %w", err)
+	}
+
+	err = s.client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to start instance: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testScaler(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Scale", func() {
+		var (
+			scaler docker.Scaler
+
+			client         *fakes.ScalerClient
+			networkManager *fakes.StartNetworkManager
+			workspace      string
+
+			createdNames               []string
+			copyToContainerInvocations []copyToContainerInvocation
+		)
+
+		it.Before(func() {
+			var err error
+			workspace, err = os.MkdirTemp("", "workspace")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.MkdirAll(filepath.Join(workspace, "lifecycle"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workspace, "lifecycle", "lifecycle.tar.gz"), []byte("lifecycle-content"), 0600)).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(workspace, "droplets"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workspace, "droplets", "some-app.tar.gz"), []byte("droplet-content"), 0600)).To(Succeed())
+
+			createdNames = nil
+			copyToContainerInvocations = nil
+
+			client = &fakes.ScalerClient{}
+			client.ContainerInspectCall.Returns.ContainerJSON = types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{
+					HostConfig: &container.HostConfig{
+						NetworkMode: container.NetworkMode("switchblade-internal"),
+					},
+				},
+				Config: &container.Config{
+					Image:  "cloudfoundry/default-stack:latest",
+					Cmd:    []string{"/tmp/lifecycle/launcher", "app", "some-command", ""},
+					Env:    []string{"PORT=8080"},
+					Labels: map[string]string{"switchblade.app": "some-app"},
+				},
+			}
+			client.ContainerCreateCall.Stub = func(ctx gocontext.Context, config *container.Config, hostConfig *container.HostConfig, _ *network.NetworkingConfig, _ *specs.Platform, name string) (container.CreateResponse, error) {
+				createdNames = append(createdNames, name)
+				return container.CreateResponse{ID: name + "-id"}, nil
+			}
+			client.CopyToContainerCall.Stub = func(ctx gocontext.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+				b, err := io.ReadAll(content)
+				if err != nil {
+					return err
+				}
+
+				copyToContainerInvocations = append(copyToContainerInvocations, copyToContainerInvocation{
+					ContainerID: containerID,
+					DstPath:     dstPath,
+					Content:     string(b),
+				})
+
+				return nil
+			}
+
+			networkManager = &fakes.StartNetworkManager{}
+
+			scaler = docker.NewScaler(client, networkManager, workspace)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workspace)).To(Succeed())
+		})
+
+		it("starts additional instance containers", func() {
+			err := scaler.Scale(gocontext.Background(), "some-app", 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ContainerInspectCall.Receives.ContainerID).To(Equal("some-app"))
+			Expect(client.ContainerListCall.Receives.Options).To(Equal(types.ContainerListOptions{
+				All: true,
+				Filters: filters.NewArgs(
+					filters.Arg("label", "switchblade.app=some-app"),
+					filters.Arg("label", "switchblade.instance"),
+				),
+			}))
+
+			Expect(createdNames).To(Equal([]string{"some-app-1", "some-app-2"}))
+			Expect(client.ContainerCreateCall.Receives.Config).To(Equal(&container.Config{
+				Image: "cloudfoundry/default-stack:latest",
+				Cmd:   []string{"/tmp/lifecycle/launcher", "app", "some-command", ""},
+				Env:   []string{"PORT=8080", "CF_INSTANCE_INDEX=2"},
+				Labels: map[string]string{
+					"switchblade.app":      "some-app",
+					"switchblade.instance": "2",
+				},
+			}))
+			Expect(client.ContainerCreateCall.Receives.HostConfig).To(Equal(&container.HostConfig{
+				PublishAllPorts: true,
+				NetworkMode:     container.NetworkMode("switchblade-internal"),
+			}))
+
+			Expect(networkManager.ConnectCall.CallCount).To(Equal(2))
+			Expect(networkManager.ConnectCall.Receives.ContainerID).To(Equal("some-app-2-id"))
+			Expect(networkManager.ConnectCall.Receives.Name).To(Equal("bridge"))
+
+			Expect(copyToContainerInvocations).To(Equal([]copyToContainerInvocation{
+				{ContainerID: "some-app-1-id", DstPath: "/", Content: "lifecycle-content"},
+				{ContainerID: "some-app-1-id", DstPath: "/home/vcap/", Content: "droplet-content"},
+				{ContainerID: "some-app-2-id", DstPath: "/", Content: "lifecycle-content"},
+				{ContainerID: "some-app-2-id", DstPath: "/home/vcap/", Content: "droplet-content"},
+			}))
+
+			Expect(client.ContainerStartCall.CallCount).To(Equal(2))
+			Expect(client.ContainerStartCall.Receives.ContainerID).To(Equal("some-app-2-id"))
+			Expect(client.ContainerRemoveCall.CallCount).To(Equal(0))
+		})
+
+		context("when instances are already running", func() {
+			it.Before(func() {
+				client.ContainerListCall.Returns.ContainerSlice = []types.Container{
+					{ID: "some-app-1-id", Labels: map[string]string{"switchblade.instance": "1"}},
+					{ID: "some-app-2-id", Labels: map[string]string{"switchblade.instance": "2"}},
+				}
+			})
+
+			it("removes the instances above the requested count", func() {
+				err := scaler.Scale(gocontext.Background(), "some-app", 2)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ContainerCreateCall.CallCount).To(Equal(0))
+				Expect(client.ContainerRemoveCall.CallCount).To(Equal(1))
+				Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-app-2-id"))
+				Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the instance count is less than 1", func() {
+				it("returns an error", func() {
+					err := scaler.Scale(gocontext.Background(), "some-app", 0)
+					Expect(err).To(MatchError("failed to scale: instances must be at least 1"))
+				})
+			})
+
+			context("when the container cannot be inspected", func() {
+				it.Before(func() {
+					client.ContainerInspectCall.Returns.Error = errors.New("could not inspect container")
+				})
+
+				it("returns an error", func() {
+					err := scaler.Scale(gocontext.Background(), "some-app", 2)
+					Expect(err).To(MatchError("failed to inspect container: could not inspect container"))
+				})
+			})
+
+			context("when the instances cannot be listed", func() {
+				it.Before(func() {
+					client.ContainerListCall.Returns.Error = errors.New("could not list containers")
+				})
+
+				it("returns an error", func() {
+					err := scaler.Scale(gocontext.Background(), "some-app", 2)
+					Expect(err).To(MatchError("failed to list instances: could not list containers"))
+				})
+			})
+
+			context("when an instance cannot be removed", func() {
+				it.Before(func() {
+					client.ContainerListCall.Returns.ContainerSlice = []types.Container{
+						{ID: "some-app-1-id", Labels: map[string]string{"switchblade.instance": "1"}},
+					}
+					client.ContainerRemoveCall.Returns.Error = errors.New("could not remove container")
+				})
+
+				it("returns an error", func() {
+					err := scaler.Scale(gocontext.Background(), "some-app", 1)
+					Expect(err).To(MatchError("failed to remove instance: could not remove container"))
+				})
+			})
+
+			context("when an instance cannot be created", func() {
+				it.Before(func() {
+					client.ContainerCreateCall.Stub = nil
+					client.ContainerCreateCall.Returns.Error = errors.New("could not create container")
+				})
+
+				it("returns an error", func() {
+					err := scaler.Scale(gocontext.Background(), "some-app", 2)
+					Expect(err).To(MatchError("failed to create instance container: could not create container"))
+				})
+			})
+
+			context("when the droplet cannot be opened", func() {
+				it.Before(func() {
+					Expect(os.Remove(filepath.Join(workspace, "droplets", "some-app.tar.gz"))).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := scaler.Scale(gocontext.Background(), "some-app", 2)
+					Expect(err).To(MatchError(ContainSubstring("failed to open droplet")))
+				})
+			})
+
+			context("when an instance cannot be started", func() {
+				it.Before(func() {
+					client.ContainerStartCall.Returns.Error = errors.New("could not start container")
+				})
+
+				it("returns an error", func() {
+					err := scaler.Scale(gocontext.Background(), "some-app", 2)
+					Expect(err).To(MatchError("failed to start instance: could not start container"))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,41 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+type SecurityProfile struct {
+	Seccomp  string
+	AppArmor string
+	CapAdd   []string
+	CapDrop  []string
+}
+
+func (p SecurityProfile) apply(hostConfig *container.HostConfig) error {
+	if p.Seccomp != "" {
+		profile := p.Seccomp
+		if profile != "unconfined" && !strings.HasPrefix(strings.TrimSpace(profile), "{") {
+			content, err := os.ReadFile(profile)
+			if err != nil {
+				return fmt.Errorf("failed to read seccomp profile: %w", err)
+			}
+
+			profile = string(content)
+		}
+
+		hostConfig.SecurityOpt = append(hostConfig.SecurityOpt, fmt.Sprintf("seccomp=%s", profile))
+	}
+
+	if p.AppArmor != "" {
+		hostConfig.SecurityOpt = append(hostConfig.SecurityOpt, fmt.Sprintf("apparmor=%s", p.AppArmor))
+	}
+
+	hostConfig.CapAdd = append(hostConfig.CapAdd, p.CapAdd...)
+	hostConfig.CapDrop = append(hostConfig.CapDrop, p.CapDrop...)
+
+	return nil
+}
@@ -1,7 +1,12 @@
 package docker
 
 import (
+	"archive/tar"
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -21,15 +27,22 @@ const (
 	BuildpackAppLifecycleRepoURL = "https://github.com/cloudfoundry/buildpackapplifecycle/archive/refs/heads/master.zip"
 	InternalNetworkName          = "switchblade-internal"
 	BridgeNetworkName            = "bridge"
+	SourceStreamBufferSize       = 1024 * 1024
+	AppLabel                     = "switchblade.app"
+	RunLabel                     = "switchblade.run"
 )
 
 type SetupPhase interface {
-	Run(ctx context.Context, logs io.Writer, name, path string) (containerID string, err error)
+	Run(ctx context.Context, logs io.Writer, name, path string) (containerID, stackDigest string, err error)
 	WithBuildpacks(buildpacks ...string) SetupPhase
 	WithStack(stack string) SetupPhase
 	WithEnv(env map[string]string) SetupPhase
 	WithoutInternetAccess() SetupPhase
 	WithServices(services map[string]map[string]interface{}) SetupPhase
+	WithStagingContainerReuse() SetupPhase
+	WithSource(source io.Reader) SetupPhase
+	WithStackSetup(setup func(Execer) error) SetupPhase
+	WithSecurityProfile(profile SecurityProfile) SetupPhase
 }
 
 //go:generate faux --interface SetupClient --output fakes/setup_client.go
@@ -39,17 +52,31 @@ type SetupClient interface {
 	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error
 	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
 	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	ContainerCommit(ctx context.Context, containerID string, options types.ContainerCommitOptions) (types.IDResponse, error)
+	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	ContainerRename(ctx context.Context, containerID, newContainerName string) error
+	ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error
+	ContainerExecCreate(ctx context.Context, container string, config types.ExecConfig) (types.IDResponse, error)
+	ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error)
+	ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error)
+	ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+	Info(ctx context.Context) (types.Info, error)
+}
+
+//go:generate faux --interface StagingContainerPool --output fakes/staging_container_pool.go
+type StagingContainerPool interface {
+	Take(ctx context.Context, stack string) (containerID string, ok bool, err error)
 }
 
 //go:generate faux --interface LifecycleBuilder --output fakes/lifecycle_builder.go
 type LifecycleBuilder interface {
-	Build(sourceURI, workspace string) (path string, err error)
+	Build(ctx context.Context, sourceURI, workspace string) (path string, err error)
 }
 
 //go:generate faux --interface BuildpacksBuilder --output fakes/buildpacks_builder.go
 type BuildpacksBuilder interface {
 	Order() (order string, skipDetect bool, err error)
-	Build(workspace, name string) (path string, err error)
+	Build(ctx context.Context, workspace, name string) (path string, err error)
 	WithBuildpacks(buildpacks ...string) BuildpacksBuilder
 }
 
@@ -57,12 +84,16 @@ type BuildpacksBuilder interface {
 type Archiver interface {
 	WithPrefix(prefix string) Archiver
 	Compress(input, output string) error
+	Stream(input string, output io.Writer) error
+	StreamTar(input io.Reader, output io.Writer) error
+	Digest(input string) (string, error)
 }
 
 //go:generate faux --interface SetupNetworkManager --output fakes/setup_network_manager.go
 type SetupNetworkManager interface {
 	Create(ctx context.Context, name, driver string, internal bool) error
 	Connect(ctx context.Context, containerID, name string) error
+	Acquire(name, owner string)
 }
 
 type Setup struct {
@@ -76,54 +107,396 @@ type Setup struct {
 	env                map[string]string
 	disconnectInternet bool
 	services           map[string]map[string]interface{}
+	reuseContainer     bool
+	pool               StagingContainerPool
+	puller             StackPuller
+	runID              string
+	source             io.Reader
+	stackSetup         func(Execer) error
+	customizer         StackCustomizer
+	architecture       ArchitectureValidator
+	hostGateway        *HostGateway
+	security           *SecurityProfile
 }
 
 func NewSetup(client SetupClient, lifecycle LifecycleBuilder, buildpacks BuildpacksBuilder, archiver Archiver, networks SetupNetworkManager, workspace, stack string) Setup {
 	return Setup{
-		client:     client,
-		lifecycle:  lifecycle,
-		stack:      stack,
-		buildpacks: buildpacks,
-		archiver:   archiver,
-		networks:   networks,
-		workspace:  workspace,
+		client:       client,
+		lifecycle:    lifecycle,
+		stack:        stack,
+		buildpacks:   buildpacks,
+		archiver:     archiver,
+		networks:     networks,
+		workspace:    workspace,
+		puller:       NewStackPuller(client, filepath.Join(workspace, "locks")),
+		customizer:   NewStackCustomizer(client),
+		architecture: NewArchitectureValidator(client),
 	}
 }
 
-func (s Setup) Run(ctx context.Context, logs io.Writer, name, path string) (string, error) {
-	lifecycle, err := s.lifecycle.Build(BuildpackAppLifecycleRepoURL, filepath.Join(s.workspace, "lifecycle"))
+func (s Setup) Run(ctx context.Context, logs io.Writer, name, path string) (string, string, error) {
+	manifestPath := filepath.Join(s.workspace, "manifests", fmt.Sprintf("%s.json", name))
+	manifest, err := ReadManifest(manifestPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", "", err
+	}
+
+	containerID, err := s.prepare(ctx, logs, name, path, &manifest)
 	if err != nil {
-		return "", fmt.Errorf("failed to build lifecycle: %w", err)
+		return "", "", err
+	}
+
+	image, _, err := s.client.ImageInspectWithRaw(ctx, stackImage(s.stack))
+	if err != nil && !errdefs.IsNotFound(err) {
+		return "", "", fmt.Errorf("failed to inspect stack image: %w", err)
+	}
+
+	manifest.Name = name
+	manifest.Stack = StackManifest{
+		Name:   stackName(s.stack),
+		Image:  stackImage(s.stack),
+		Digest: repoDigest(image.RepoDigests),
+	}
+	manifest.Env = RedactEnvironment(s.env)
+	manifest.EnvDigest = environmentDigest(s.env)
+	manifest.Services = nil
+	for key := range s.services {
+		manifest.Services = append(manifest.Services, key)
 	}
+	sort.Strings(manifest.Services)
+	manifest.Options = s.options()
 
-	buildpacks, err := s.buildpacks.Build(filepath.Join(s.workspace, "buildpacks"), name)
+	err = writeManifest(manifestPath, manifest)
 	if err != nil {
-		return "", fmt.Errorf("failed to build buildpacks: %w", err)
+		return "", "", err
 	}
 
-	source := filepath.Join(s.workspace, "source", fmt.Sprintf("%s.tar.gz", name))
-	err = s.archiver.WithPrefix("/tmp/app").Compress(path, source)
+	return containerID, manifest.Stack.Digest, nil
+}
+
+func (s Setup) options() []string {
+	var options []string
+	if s.disconnectInternet {
+		options = append(options, "WithoutInternetAccess")
+	}
+	if s.reuseContainer {
+		options = append(options, "WithStagingContainerReuse")
+	}
+	if s.stackSetup != nil {
+		options = append(options, "WithStackSetup")
+	}
+	if s.hostGateway != nil {
+		options = append(options, "WithHostServices")
+	}
+	if s.security != nil {
+		options = append(options, "WithSecurityProfile")
+	}
+	if s.pool != nil {
+		options = append(options, "WithStagingPool")
+	}
+
+	return options
+}
+
+func (s Setup) prepare(ctx context.Context, logs io.Writer, name, path string, manifest *Manifest) (string, error) {
+	if s.pool != nil && !s.reuseContainer && s.stackSetup == nil && s.hostGateway == nil && s.security == nil {
+		containerID, ok, err := s.pool.Take(ctx, s.stack)
+		if err != nil {
+			return "", fmt.Errorf("failed to take pooled staging container: %w", err)
+		}
+
+		if ok {
+			return s.runPooled(ctx, containerID, name, path, manifest)
+		}
+	}
+
+	lifecycle, err := s.lifecycle.Build(ctx, BuildpackAppLifecycleRepoURL, filepath.Join(s.workspace, "lifecycle"))
 	if err != nil {
-		return "", fmt.Errorf("failed to archive source code: %w", err)
+		return "", fmt.Errorf("failed to build lifecycle: %w", err)
 	}
 
-	pullLogs, err := s.client.ImagePull(ctx, fmt.Sprintf("cloudfoundry/%s:latest", s.stack), types.ImagePullOptions{})
+	manifest.Lifecycle, err = readLifecycleManifest(lifecycle)
 	if err != nil {
-		return "", fmt.Errorf("failed to pull base image: %w", err)
+		return "", err
 	}
-	defer pullLogs.Close()
 
-	_, err = io.Copy(logs, pullLogs)
+	buildpacks, err := s.buildBuildpacks(ctx, name, manifest)
 	if err != nil {
-		return "", fmt.Errorf("failed to copy image pull logs: %w", err)
+		return "", err
 	}
 
-	err = s.networks.Create(ctx, InternalNetworkName, "bridge", true)
+	image := stackImage(s.stack)
+
+	var (
+		prepared     bool
+		stagingImage string
+	)
+	if s.reuseContainer {
+		buildpacksDigest, err := FileDigest(buildpacks)
+		if err != nil {
+			return "", fmt.Errorf("failed to digest buildpacks: %w", err)
+		}
+
+		stagingImage = stagingImageReference(name, s.stack, manifest.Lifecycle.SHA256, buildpacksDigest)
+
+		_, _, err = s.client.ImageInspectWithRaw(ctx, stagingImage)
+		if err != nil && !errdefs.IsNotFound(err) {
+			return "", fmt.Errorf("failed to inspect staging image: %w", err)
+		}
+
+		prepared = err == nil
+	}
+
+	var tarballs []string
+	if prepared {
+		image = stagingImage
+	} else {
+		err = s.puller.Pull(ctx, logs, image)
+		if err != nil {
+			return "", err
+		}
+
+		err = s.architecture.Validate(ctx, image)
+		if err != nil {
+			return "", err
+		}
+
+		if s.stackSetup != nil {
+			image, err = s.customizer.Customize(ctx, name, image, s.stackSetup)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		tarballs = []string{lifecycle, buildpacks}
+	}
+
+	err = s.networks.Create(ctx, internalNetworkName(s.runID), "bridge", true)
 	if err != nil {
 		return "", fmt.Errorf("failed to create network: %w", err)
 	}
+	s.networks.Acquire(internalNetworkName(s.runID), name)
+
+	var extraHosts []string
+	if s.hostGateway != nil {
+		var host string
+		host, extraHosts, err = s.hostGateway.Resolve(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		s.services = rewriteServiceHosts(s.services, host)
+	}
+
+	env, err := s.environment(name)
+	if err != nil {
+		return "", err
+	}
+
+	cmd, err := s.command(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.removeConflictingContainer(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	containerConfig := container.Config{
+		Image:      image,
+		Cmd:        cmd,
+		User:       "vcap",
+		Env:        env,
+		WorkingDir: "/home/vcap",
+		Labels:     runLabels(s.runID, map[string]string{AppLabel: name}),
+	}
+
+	hostConfig := container.HostConfig{
+		NetworkMode: container.NetworkMode(internalNetworkName(s.runID)),
+		ExtraHosts:  extraHosts,
+	}
+
+	if s.security != nil {
+		err = s.security.apply(&hostConfig)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	resp, err := s.client.ContainerCreate(ctx, &containerConfig, &hostConfig, nil, nil, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging container: %w", err)
+	}
+
+	if !s.disconnectInternet {
+		err = s.networks.Connect(ctx, resp.ID, BridgeNetworkName)
+		if err != nil {
+			return "", fmt.Errorf("failed to connect container to network: %w", err)
+		}
+	}
+
+	err = s.copyTarballs(ctx, resp.ID, tarballs)
+	if err != nil {
+		return "", err
+	}
+
+	if !prepared {
+		err = s.copySource(ctx, resp.ID, path, manifest)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if s.reuseContainer && !prepared {
+		_, err = s.client.ContainerCommit(ctx, resp.ID, types.ContainerCommitOptions{Reference: stagingImage})
+		if err != nil {
+			return "", fmt.Errorf("failed to commit staging container: %w", err)
+		}
+	}
+
+	err = s.copyBuildCache(ctx, resp.ID, name)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.ID, nil
+}
+
+func (s Setup) runPooled(ctx context.Context, containerID, name, path string, manifest *Manifest) (string, error) {
+	s.networks.Acquire(internalNetworkName(s.runID), name)
+
+	buildpacks, err := s.buildBuildpacks(ctx, name, manifest)
+	if err != nil {
+		return "", err
+	}
+
+	env, err := s.environment(name)
+	if err != nil {
+		return "", err
+	}
+
+	cmd, err := s.command(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.removeConflictingContainer(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.client.ContainerRename(ctx, containerID, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to rename pooled staging container: %w", err)
+	}
+
+	script, err := stagingScript(env, cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging script: %w", err)
+	}
+
+	err = s.client.CopyToContainer(ctx, containerID, "/", script, types.CopyToContainerOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to copy staging script to container: %w", err)
+	}
+
+	if !s.disconnectInternet {
+		err = s.networks.Connect(ctx, containerID, BridgeNetworkName)
+		if err != nil {
+			return "", fmt.Errorf("failed to connect container to network: %w", err)
+		}
+	}
+
+	err = s.copyTarballs(ctx, containerID, []string{buildpacks})
+	if err != nil {
+		return "", err
+	}
+
+	err = s.copySource(ctx, containerID, path, manifest)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.copyBuildCache(ctx, containerID, name)
+	if err != nil {
+		return "", err
+	}
+
+	return containerID, nil
+}
+
+func (s Setup) buildBuildpacks(ctx context.Context, name string, manifest *Manifest) (string, error) {
+	buildpacks, err := s.buildpacks.Build(ctx, filepath.Join(s.workspace, "buildpacks"), name)
+	if err != nil {
+		return "", fmt.Errorf("failed to build buildpacks: %w", err)
+	}
+
+	manifest.Buildpacks, err = readBuildpacksLock(strings.TrimSuffix(buildpacks, ".tar.gz") + ".json")
+	if err != nil {
+		return "", err
+	}
+
+	return buildpacks, nil
+}
 
-	env := []string{fmt.Sprintf("CF_STACK=%s", s.stack)}
+func (s Setup) copySource(ctx context.Context, containerID, path string, manifest *Manifest) error {
+	source := s.source
+	hash := sha256.New()
+	if source != nil {
+		source = io.TeeReader(source, hash)
+	} else {
+		digest, err := s.archiver.Digest(path)
+		if err != nil {
+			return fmt.Errorf("failed to digest source code: %w", err)
+		}
+
+		manifest.Source = digest
+	}
+
+	pr, pw := io.Pipe()
+
+	archived := make(chan error, 1)
+	go func() {
+		buffer := bufio.NewWriterSize(pw, SourceStreamBufferSize)
+
+		archiver := s.archiver.WithPrefix("/tmp/app")
+
+		var err error
+		if source != nil {
+			err = archiver.StreamTar(source, buffer)
+		} else {
+			err = archiver.Stream(path, buffer)
+		}
+		if err == nil {
+			err = buffer.Flush()
+		}
+
+		pw.CloseWithError(err)
+		archived <- err
+	}()
+
+	err := s.client.CopyToContainer(ctx, containerID, "/", pr, types.CopyToContainerOptions{})
+	pr.Close()
+
+	archiveErr := <-archived
+	if archiveErr != nil && !errors.Is(archiveErr, io.ErrClosedPipe) {
+		return fmt.Errorf("failed to archive source code: %w", archiveErr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to copy source code to container: %w", err)
+	}
+
+	if source != nil {
+		manifest.Source = formatDigest(hash)
+	}
+
+	return nil
+}
+
+func (s Setup) environment(name string) ([]string, error) {
+	env := []string{fmt.Sprintf("CF_STACK=%s", stackName(s.stack))}
 	for key, value := range s.env {
 		env = append(env, fmt.Sprintf("%s=%s", key, value))
 	}
@@ -147,7 +520,7 @@ func (s Setup) Run(ctx context.Context, logs io.Writer, name, path string) (stri
 			"user-provided": services,
 		})
 		if err != nil {
-			return "", fmt.Errorf("failed to marshal services json: %w", err)
+			return nil, fmt.Errorf("failed to marshal services json: %w", err)
 		}
 
 		env = append(env, fmt.Sprintf("VCAP_SERVICES=%s", content))
@@ -155,82 +528,78 @@ func (s Setup) Run(ctx context.Context, logs io.Writer, name, path string) (stri
 		env = append(env, "VCAP_SERVICES={}")
 	}
 
+	return env, nil
+}
+
+func (s Setup) command(manifest *Manifest) ([]string, error) {
 	order, skipDetect, err := s.buildpacks.Order()
 	if err != nil {
-		return "", fmt.Errorf("failed to determine buildpack ordering: %w", err)
+		return nil, fmt.Errorf("failed to determine buildpack ordering: %w", err)
 	}
 
+	manifest.BuildpackOrder = nil
+	if order != "" {
+		manifest.BuildpackOrder = strings.Split(order, ",")
+	}
+	manifest.SkipDetect = skipDetect
+
+	return []string{
+		"/tmp/lifecycle/builder",
+		"--buildArtifactsCacheDir=/tmp/cache",
+		"--buildDir=/tmp/app",
+		fmt.Sprintf("--buildpackOrder=%s", order),
+		"--buildpacksDir=/tmp/buildpacks",
+		"--outputBuildArtifactsCache=/tmp/output-cache",
+		"--outputDroplet=/tmp/droplet",
+		"--outputMetadata=/tmp/result.json",
+		fmt.Sprintf("--skipDetect=%t", skipDetect),
+	}, nil
+}
+
+func (s Setup) removeConflictingContainer(ctx context.Context, name string) error {
 	ctnr, err := s.client.ContainerInspect(ctx, name)
 	if err != nil && !errdefs.IsNotFound(err) {
-		return "", fmt.Errorf("failed to inspect staging container: %w", err)
+		return fmt.Errorf("failed to inspect staging container: %w", err)
 	}
 	if err == nil {
-		err = s.client.ContainerRemove(ctx, ctnr.ID, types.ContainerRemoveOptions{Force: true})
-		if err != nil {
-			return "", fmt.Errorf("failed to remove conflicting container: %w", err)
-		}
-	}
-
-	containerConfig := container.Config{
-		Image: fmt.Sprintf("cloudfoundry/%s:latest", s.stack),
-		Cmd: []string{
-			"/tmp/lifecycle/builder",
-			"--buildArtifactsCacheDir=/tmp/cache",
-			"--buildDir=/tmp/app",
-			fmt.Sprintf("--buildpackOrder=%s", order),
-			"--buildpacksDir=/tmp/buildpacks",
-			"--outputBuildArtifactsCache=/tmp/output-cache",
-			"--outputDroplet=/tmp/droplet",
-			"--outputMetadata=/tmp/result.json",
-			fmt.Sprintf("--skipDetect=%t", skipDetect),
-		},
-		User:       "vcap",
-		Env:        env,
-		WorkingDir: "/home/vcap",
-	}
-
-	hostConfig := container.HostConfig{
-		NetworkMode: container.NetworkMode(InternalNetworkName),
-	}
-
-	resp, err := s.client.ContainerCreate(ctx, &containerConfig, &hostConfig, nil, nil, name)
-	if err != nil {
-		return "", fmt.Errorf("failed to create staging container: %w", err)
-	}
-
-	if !s.disconnectInternet {
-		err = s.networks.Connect(ctx, resp.ID, BridgeNetworkName)
+		err = s.client.ContainerRemove(ctx, ctnr.ID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
 		if err != nil {
-			return "", fmt.Errorf("failed to connect container to network: %w", err)
+			return fmt.Errorf("failed to remove conflicting container: %w", err)
 		}
 	}
 
-	tarballs := []string{lifecycle, buildpacks, source}
+	return nil
+}
 
+func (s Setup) copyBuildCache(ctx context.Context, containerID, name string) error {
 	buildCachePath := filepath.Join(s.workspace, "build-cache", fmt.Sprintf("%s.tar.gz", name))
-	_, err = os.Stat(buildCachePath)
+	_, err := os.Stat(buildCachePath)
 	if err == nil {
-		tarballs = append(tarballs, buildCachePath)
+		return s.copyTarballs(ctx, containerID, []string{buildCachePath})
 	}
 
+	return nil
+}
+
+func (s Setup) copyTarballs(ctx context.Context, containerID string, tarballs []string) error {
 	for _, tarballPath := range tarballs {
 		tarball, err := os.Open(tarballPath)
 		if err != nil {
-			return "", fmt.Errorf("failed to open tarball: %w", err)
+			return fmt.Errorf("failed to open tarball: %w", err)
 		}
 
-		err = s.client.CopyToContainer(ctx, resp.ID, "/", tarball, types.CopyToContainerOptions{})
+		err = s.client.CopyToContainer(ctx, containerID, "/", tarball, types.CopyToContainerOptions{})
 		if err != nil {
-			return "", fmt.Errorf("failed to copy tarball to container: %w", err)
+			return fmt.Errorf("failed to copy tarball to container: %w", err)
 		}
 
 		err = tarball.Close()
 		if err != nil && !errors.Is(err, os.ErrClosed) {
-			return "", fmt.Errorf("failed to close tarball: %w", err)
+			return fmt.Errorf("failed to close tarball: %w", err)
 		}
 	}
 
-	return resp.ID, nil
+	return nil
 }
 
 func (s Setup) WithBuildpacks(buildpacks ...string) SetupPhase {
@@ -257,3 +626,139 @@ func (s Setup) WithServices(services map[string]map[string]interface{}) SetupPha
 	s.services = services
 	return s
 }
+
+func (s Setup) WithStagingContainerReuse() SetupPhase {
+	s.reuseContainer = true
+	return s
+}
+
+func (s Setup) WithSource(source io.Reader) SetupPhase {
+	s.source = source
+	return s
+}
+
+func (s Setup) WithStackSetup(setup func(Execer) error) SetupPhase {
+	s.stackSetup = setup
+	return s
+}
+
+func (s Setup) WithSecurityProfile(profile SecurityProfile) SetupPhase {
+	s.security = &profile
+	return s
+}
+
+func (s Setup) WithStackPuller(puller StackPuller) Setup {
+	s.puller = puller
+	return s
+}
+
+func (s Setup) WithStagingPool(pool StagingContainerPool) Setup {
+	s.pool = pool
+	return s
+}
+
+func (s Setup) WithHostGateway(gateway HostGateway) Setup {
+	s.hostGateway = &gateway
+	return s
+}
+
+func (s Setup) WithRunID(runID string) Setup {
+	s.runID = runID
+	return s
+}
+
+func stagingScript(env, cmd []string) (io.Reader, error) {
+	script := bytes.NewBufferString("#!/bin/bash\nset -e\n")
+	for _, variable := range env {
+		fmt.Fprintf(script, "export %s\n", shellQuote(variable))
+	}
+
+	var args []string
+	for _, arg := range cmd {
+		args = append(args, shellQuote(arg))
+	}
+	fmt.Fprintf(script, "exec %s\n", strings.Join(args, " "))
+
+	buffer := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buffer)
+
+	err := tw.WriteHeader(&tar.Header{
+		Name:     strings.TrimPrefix(StagingScriptPath, "/"),
+		Mode:     0755,
+		Size:     int64(script.Len()),
+		Typeflag: tar.TypeReg,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(tw, script)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+func shellQuote(value string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", `'\''`))
+}
+
+// stagingImageReference tags the reusable staging image with the lifecycle
+// and buildpack digests so that a change to either prepares a fresh image.
+func stagingImageReference(name, stack, lifecycleDigest, buildpacksDigest string) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", lifecycleDigest, buildpacksDigest)
+
+	return fmt.Sprintf("%s:%s-%s", stagingImageName(name), strings.NewReplacer("@", "-", ":", "-").Replace(stack), hex.EncodeToString(hash.Sum(nil))[:12])
+}
+
+func stackImage(stack string) string {
+	name, digest, pinned := strings.Cut(stack, "@")
+	if pinned {
+		return fmt.Sprintf("cloudfoundry/%s@%s", name, digest)
+	}
+
+	return fmt.Sprintf("cloudfoundry/%s:latest", stack)
+}
+
+func stackName(stack string) string {
+	name, _, _ := strings.Cut(stack, "@")
+	return name
+}
+
+func repoDigest(repoDigests []string) string {
+	for _, reference := range repoDigests {
+		_, digest, ok := strings.Cut(reference, "@")
+		if ok {
+			return digest
+		}
+	}
+
+	return ""
+}
+
+func stagingImageName(name string) string {
+	return fmt.Sprintf("switchblade-staging-%s", name)
+}
+
+func internalNetworkName(runID string) string {
+	if runID == "" {
+		return InternalNetworkName
+	}
+
+	return fmt.Sprintf("%s-%s", runID, InternalNetworkName)
+}
+
+func runLabels(runID string, labels map[string]string) map[string]string {
+	if runID != "" {
+		labels[RunLabel] = runID
+	}
+
+	return labels
+}
@@ -0,0 +1,99 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+)
+
+//go:generate faux --interface StackPullerClient --output fakes/stack_puller_client.go
+type StackPullerClient interface {
+	ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error)
+	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+}
+
+type StackPuller struct {
+	client StackPullerClient
+	locks  string
+
+	pulls map[string]*stackPull
+	m     *sync.Mutex
+}
+
+type stackPull struct {
+	once sync.Once
+	err  error
+}
+
+func NewStackPuller(client StackPullerClient, locks string) StackPuller {
+	return StackPuller{
+		client: client,
+		locks:  locks,
+		pulls:  map[string]*stackPull{},
+		m:      &sync.Mutex{},
+	}
+}
+
+func (p StackPuller) Pull(ctx context.Context, logs io.Writer, image string) error {
+	p.m.Lock()
+	pull, ok := p.pulls[image]
+	if !ok {
+		pull = &stackPull{}
+		p.pulls[image] = pull
+	}
+	p.m.Unlock()
+
+	pull.once.Do(func() {
+		pull.err = p.pull(ctx, logs, image)
+	})
+
+	if pull.err != nil {
+		p.m.Lock()
+		if p.pulls[image] == pull {
+			delete(p.pulls, image)
+		}
+		p.m.Unlock()
+
+		return pull.err
+	}
+
+	return nil
+}
+
+func (p StackPuller) pull(ctx context.Context, logs io.Writer, image string) error {
+	unlock, err := lockFile(ctx, filepath.Join(p.locks, fmt.Sprintf("%s.lock", strings.NewReplacer("/", "-", ":", "-", "@", "-").Replace(image))))
+	if err != nil {
+		return fmt.Errorf("failed to lock base image: %w", err)
+	}
+	defer unlock()
+
+	if strings.Contains(image, "@") {
+		_, _, err := p.client.ImageInspectWithRaw(ctx, image)
+		if err == nil {
+			return nil
+		}
+
+		if !errdefs.IsNotFound(err) {
+			return fmt.Errorf("failed to inspect base image: %w", err)
+		}
+	}
+
+	pullLogs, err := p.client.ImagePull(ctx, image, types.ImagePullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull base image: %w", err)
+	}
+	defer pullLogs.Close()
+
+	_, err = io.Copy(logs, pullLogs)
+	if err != nil {
+		return fmt.Errorf("failed to copy image pull logs: %w", err)
+	}
+
+	return nil
+}
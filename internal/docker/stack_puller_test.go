@@ -0,0 +1,146 @@
+package docker_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testStackPuller(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("Pull", func() {
+		var (
+			puller docker.StackPuller
+			client *fakes.StackPullerClient
+			locks  string
+		)
+
+		it.Before(func() {
+			var err error
+			locks, err = os.MkdirTemp("", "locks")
+			Expect(err).NotTo(HaveOccurred())
+
+			client = &fakes.StackPullerClient{}
+			client.ImagePullCall.Stub = func(gocontext.Context, string, types.ImagePullOptions) (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewBuffer([]byte("Pulling image...\n"))), nil
+			}
+			client.ImageInspectWithRawCall.Returns.Error = errdefs.NotFound(errors.New("no such image"))
+
+			puller = docker.NewStackPuller(client, locks)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(locks)).To(Succeed())
+		})
+
+		it("pulls each image once", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					err := puller.Pull(gocontext.Background(), io.Discard, "cloudfoundry/some-stack:latest")
+					Expect(err).NotTo(HaveOccurred())
+				}()
+			}
+			wg.Wait()
+
+			Expect(client.ImagePullCall.CallCount).To(Equal(1))
+			Expect(client.ImagePullCall.Receives.Ref).To(Equal("cloudfoundry/some-stack:latest"))
+			Expect(client.ImageInspectWithRawCall.CallCount).To(Equal(0))
+			Expect(filepath.Join(locks, "cloudfoundry-some-stack-latest.lock")).To(BeAnExistingFile())
+
+			logs := bytes.NewBuffer(nil)
+			err := puller.Pull(gocontext.Background(), logs, "cloudfoundry/other-stack:latest")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logs.String()).To(Equal("Pulling image...\n"))
+			Expect(client.ImagePullCall.CallCount).To(Equal(2))
+		})
+
+		context("when the image is pinned by digest and available locally", func() {
+			it.Before(func() {
+				client.ImageInspectWithRawCall.Returns.Error = nil
+			})
+
+			it("does not pull the image", func() {
+				err := puller.Pull(gocontext.Background(), io.Discard, "cloudfoundry/some-stack@sha256:some-digest")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ImageInspectWithRawCall.Receives.ImageID).To(Equal("cloudfoundry/some-stack@sha256:some-digest"))
+				Expect(client.ImagePullCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("when the pull fails", func() {
+			it.Before(func() {
+				client.ImagePullCall.Stub = nil
+				client.ImagePullCall.Returns.Error = errors.New("could not pull image")
+			})
+
+			it("retries on the next call", func() {
+				err := puller.Pull(gocontext.Background(), io.Discard, "cloudfoundry/some-stack:latest")
+				Expect(err).To(MatchError("failed to pull base image: could not pull image"))
+
+				client.ImagePullCall.Returns.Error = nil
+				client.ImagePullCall.Returns.ReadCloser = io.NopCloser(bytes.NewBuffer(nil))
+
+				err = puller.Pull(gocontext.Background(), io.Discard, "cloudfoundry/some-stack:latest")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.ImagePullCall.CallCount).To(Equal(2))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the lock file cannot be created", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(locks, "not-a-directory"), nil, 0600)).To(Succeed())
+					puller = docker.NewStackPuller(client, filepath.Join(locks, "not-a-directory"))
+				})
+
+				it("returns an error", func() {
+					err := puller.Pull(gocontext.Background(), io.Discard, "cloudfoundry/some-stack:latest")
+					Expect(err).To(MatchError(ContainSubstring("failed to lock base image:")))
+				})
+			})
+
+			context("when the image cannot be inspected", func() {
+				it.Before(func() {
+					client.ImageInspectWithRawCall.Returns.Error = errors.New("could not inspect image")
+				})
+
+				it("returns an error", func() {
+					err := puller.Pull(gocontext.Background(), io.Discard, "cloudfoundry/some-stack@sha256:some-digest")
+					Expect(err).To(MatchError("failed to inspect base image: could not inspect image"))
+				})
+			})
+
+			context("when the pull logs cannot be copied", func() {
+				it.Before(func() {
+					client.ImagePullCall.Stub = nil
+					client.ImagePullCall.Returns.ReadCloser = io.NopCloser(iotest.ErrReader(errors.New("could not read logs")))
+				})
+
+				it("returns an error", func() {
+					err := puller.Pull(gocontext.Background(), io.Discard, "cloudfoundry/some-stack:latest")
+					Expect(err).To(MatchError("failed to copy image pull logs: could not read logs"))
+				})
+			})
+		})
+	})
+}
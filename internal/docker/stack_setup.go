@@ -0,0 +1,67 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+type StackCustomizer struct {
+	client SetupClient
+}
+
+func NewStackCustomizer(client SetupClient) StackCustomizer {
+	return StackCustomizer{client: client}
+}
+
+func (c StackCustomizer) Customize(ctx context.Context, name, image string, setup func(Execer) error) (string, error) {
+	containerConfig := container.Config{
+		Image:  image,
+		Cmd:    []string{"sleep", "infinity"},
+		User:   "root",
+		Labels: map[string]string{AppLabel: name},
+	}
+
+	resp, err := c.client.ContainerCreate(ctx, &containerConfig, nil, nil, nil, fmt.Sprintf("%s-stack-setup", name))
+	if err != nil {
+		return "", fmt.Errorf("failed to create stack setup container: %w", err)
+	}
+
+	err = c.customize(ctx, resp.ID, name, setup)
+	if err != nil {
+		_ = c.client.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		return "", err
+	}
+
+	err = c.client.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to remove stack setup container: %w", err)
+	}
+
+	return stackSetupImageReference(name), nil
+}
+
+func (c StackCustomizer) customize(ctx context.Context, containerID, name string, setup func(Execer) error) error {
+	err := c.client.ContainerStart(ctx, containerID, types.ContainerStartOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to start stack setup container: %w", err)
+	}
+
+	err = setup(containerExecer{ctx: ctx, client: c.client, containerID: containerID, user: "root"})
+	if err != nil {
+		return fmt.Errorf("failed to run stack setup: %w", err)
+	}
+
+	_, err = c.client.ContainerCommit(ctx, containerID, types.ContainerCommitOptions{Reference: stackSetupImageReference(name)})
+	if err != nil {
+		return fmt.Errorf("failed to commit stack setup container: %w", err)
+	}
+
+	return nil
+}
+
+func stackSetupImageReference(name string) string {
+	return fmt.Sprintf("%s:stack-setup", stagingImageName(name))
+}
@@ -3,7 +3,12 @@ package docker
 import (
 	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,11 +17,27 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/klauspost/compress/zstd"
 )
 
 type StagePhase interface {
-	Run(ctx context.Context, logs io.Writer, containerID, name string) (command string, err error)
+	Run(ctx context.Context, logs io.Writer, containerID, name string) (result StageResult, err error)
+}
+
+type StageResult struct {
+	Command      string
+	Processes    map[string]string
+	Sidecars     []Sidecar
+	Buildpacks   []StagedBuildpack
+	DetectOutput string
+}
+
+type StagedBuildpack struct {
+	Key     string
+	Name    string
+	Version string
 }
 
 //go:generate faux --interface StageClient --output fakes/stage_client.go
@@ -29,9 +50,13 @@ type StageClient interface {
 }
 
 type Stage struct {
-	client    StageClient
-	archiver  Archiver
-	workspace string
+	client      StageClient
+	archiver    Archiver
+	workspace   string
+	zstdDroplet bool
+	sbom        bool
+	signer      crypto.Signer
+	cache       *DropletCache
 }
 
 func NewStage(client StageClient, archiver Archiver, workspace string) Stage {
@@ -42,143 +67,413 @@ func NewStage(client StageClient, archiver Archiver, workspace string) Stage {
 	}
 }
 
-func (s Stage) Run(ctx context.Context, logs io.Writer, containerID, name string) (string, error) {
+func (s Stage) Run(ctx context.Context, logs io.Writer, containerID, name string) (StageResult, error) {
+	var cacheKey string
+	if s.cache != nil && !s.sbom {
+		manifest, err := ReadManifest(filepath.Join(s.workspace, "manifests", fmt.Sprintf("%s.json", name)))
+		if err != nil {
+			return StageResult{}, err
+		}
+
+		cacheKey, err = s.cache.Key(manifest, s.dropletExtension())
+		if err != nil {
+			return StageResult{}, err
+		}
+
+		result, ok, err := s.loadCachedDroplet(ctx, logs, containerID, name, cacheKey)
+		if err != nil || ok {
+			return result, err
+		}
+	}
+
 	err := s.client.ContainerStart(ctx, containerID, types.ContainerStartOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed to start container: %w", err)
+		return StageResult{}, fmt.Errorf("failed to start container: %w", err)
 	}
 
-	var status container.WaitResponse
 	onExit, onErr := s.client.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
-	select {
-	case err := <-onErr:
-		if err != nil {
-			return "", fmt.Errorf("failed to wait on container: %w", err)
-		}
-	case status = <-onExit:
-	}
 
+	// Follow the logs while the container runs so that long stagings report
+	// their progress as it happens. The stream ends when the container exits.
 	containerLogs, err := s.client.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
+		Follow:     true,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch container logs: %w", err)
+		return StageResult{}, fmt.Errorf("failed to fetch container logs: %w", err)
 	}
 	defer containerLogs.Close()
 
-	_, err = stdcopy.StdCopy(logs, logs, containerLogs)
+	output := bytes.NewBuffer(nil)
+	_, err = stdcopy.StdCopy(io.MultiWriter(logs, output), io.MultiWriter(logs, output), containerLogs)
 	if err != nil {
-		return "", fmt.Errorf("failed to copy container logs: %w", err)
+		return StageResult{}, fmt.Errorf("failed to copy container logs: %w", err)
+	}
+
+	var status container.WaitResponse
+	select {
+	case err := <-onErr:
+		if err != nil {
+			return StageResult{}, fmt.Errorf("failed to wait on container: %w", err)
+		}
+	case status = <-onExit:
 	}
 
 	if status.StatusCode != 0 {
-		err = s.client.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
+		err = s.client.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
 		if err != nil {
-			return "", fmt.Errorf("failed to remove container: %w", err)
+			return StageResult{}, fmt.Errorf("failed to remove container: %w", err)
 		}
 
-		return "", fmt.Errorf("App staging failed: container exited with non-zero status code (%d)", status.StatusCode)
+		return StageResult{}, fmt.Errorf("App staging failed: container exited with non-zero status code (%d)", status.StatusCode)
 	}
 
-	droplet, _, err := s.client.CopyFromContainer(ctx, containerID, "/tmp/droplet")
+	dropletCopied := make(chan error, 1)
+	go func() {
+		dropletCopied <- s.copyDroplet(ctx, containerID, name)
+	}()
+
+	result, resultErr := s.readResult(ctx, containerID)
+
+	err = <-dropletCopied
 	if err != nil {
-		return "", fmt.Errorf("failed to copy droplet from container: %w", err)
+		return StageResult{}, err
+	}
+
+	if resultErr != nil {
+		return StageResult{}, resultErr
 	}
-	defer droplet.Close()
 
-	err = os.MkdirAll(filepath.Join(s.workspace, "droplets"), os.ModePerm)
+	buildCache, _, err := s.client.CopyFromContainer(ctx, containerID, "/tmp/output-cache")
 	if err != nil {
-		return "", fmt.Errorf("failed to create droplets directory: %w", err)
+		return StageResult{}, fmt.Errorf("failed to copy build cache from container: %w", err)
 	}
+	defer buildCache.Close()
 
-	dropletFile, err := os.Create(filepath.Join(s.workspace, "droplets", fmt.Sprintf("%s.tar.gz", name)))
+	err = os.MkdirAll(filepath.Join(s.workspace, "build-cache"), os.ModePerm)
 	if err != nil {
-		return "", fmt.Errorf("failed to create droplet tarball: %w", err)
+		return StageResult{}, fmt.Errorf("failed to create build-cache directory: %w", err)
 	}
-	defer dropletFile.Close()
 
-	tr := tar.NewReader(droplet)
+	tr := tar.NewReader(buildCache)
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("failed to retrieve droplet from tarball: %w", err)
+			return StageResult{}, fmt.Errorf("failed to retrieve build cache from tarball: %w", err)
 		}
 
-		if hdr.Name == "droplet" {
-			_, err = io.CopyN(dropletFile, tr, hdr.Size)
+		if hdr.Name == "output-cache" {
+			cachePath := filepath.Join(s.workspace, "build-cache", name)
+			outputFile, err := os.Create(cachePath)
+			if err != nil {
+				return StageResult{}, fmt.Errorf("failed to create build-cache path: %w", err)
+			}
+
+			_, err = io.CopyN(outputFile, tr, hdr.Size)
 			if err != nil {
-				return "", fmt.Errorf("failed to copy droplet from tarball: %w", err)
+				return StageResult{}, fmt.Errorf("failed to copy build cache: %w", err)
+			}
+			defer os.RemoveAll(cachePath)
+
+			err = s.archiver.WithPrefix("/tmp/cache").Compress(cachePath, filepath.Join(s.workspace, "build-cache", fmt.Sprintf("%s.tar.gz", name)))
+			if err != nil {
+				return StageResult{}, fmt.Errorf("failed to recompress build cache: %w", err)
 			}
 		}
 	}
 
-	buildCache, _, err := s.client.CopyFromContainer(ctx, containerID, "/tmp/output-cache")
+	if s.sbom {
+		var buildpacks []string
+		for _, buildpack := range result.Buildpacks {
+			buildpacks = append(buildpacks, buildpack.Key)
+		}
+
+		err = s.collectSBOM(ctx, containerID, name, buildpacks, output.String())
+		if err != nil {
+			return StageResult{}, err
+		}
+	}
+
+	err = s.client.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
 	if err != nil {
-		return "", fmt.Errorf("failed to copy build cache from container: %w", err)
+		return StageResult{}, fmt.Errorf("failed to remove container: %w", err)
 	}
-	defer buildCache.Close()
 
-	err = os.MkdirAll(filepath.Join(s.workspace, "build-cache"), os.ModePerm)
+	if cacheKey != "" {
+		err = s.cache.Store(ctx, cacheKey, filepath.Join(s.workspace, "droplets"), name, s.dropletExtension(), result)
+		if err != nil {
+			return StageResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s Stage) loadCachedDroplet(ctx context.Context, logs io.Writer, containerID, name, key string) (StageResult, bool, error) {
+	dir := filepath.Join(s.workspace, "droplets")
+	result, digest, ok, err := s.cache.Load(ctx, key, dir, name, s.dropletExtension())
+	if err != nil || !ok {
+		return StageResult{}, false, err
+	}
+
+	if s.signer != nil {
+		err = writeSignature(filepath.Join(dir, name+s.dropletExtension()), digest, s.signer)
+		if err != nil {
+			return StageResult{}, false, err
+		}
+	}
+
+	err = s.client.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+	if err != nil {
+		return StageResult{}, false, fmt.Errorf("failed to remove container: %w", err)
+	}
+
+	fmt.Fprintf(logs, "Using cached droplet %s\n", key[:12])
+
+	return result, true, nil
+}
+
+func (s Stage) dropletExtension() string {
+	if s.zstdDroplet {
+		return ".tar.zst"
+	}
+
+	return ".tar.gz"
+}
+
+func (s Stage) WithZstdDroplets() Stage {
+	s.zstdDroplet = true
+	return s
+}
+
+func (s Stage) WithSBOM() Stage {
+	s.sbom = true
+	return s
+}
+
+func (s Stage) WithDropletSigning(key crypto.Signer) Stage {
+	s.signer = key
+	return s
+}
+
+func (s Stage) WithDropletCache(cache DropletCache) Stage {
+	s.cache = &cache
+	return s
+}
+
+func (s Stage) collectSBOM(ctx context.Context, containerID, name string, buildpacks []string, output string) error {
+	deps, _, err := s.client.CopyFromContainer(ctx, containerID, "/tmp/deps")
+	if err != nil {
+		return fmt.Errorf("failed to copy deps from container: %w", err)
+	}
+	defer deps.Close()
+
+	components, err := parseDepsConfigs(deps)
 	if err != nil {
-		return "", fmt.Errorf("failed to create build-cache directory: %w", err)
+		return err
 	}
 
-	tr = tar.NewReader(buildCache)
+	dependencies := parseInstalledDependencies(output)
+	for _, buildpack := range buildpacks {
+		manifest, _, err := s.client.CopyFromContainer(ctx, containerID, fmt.Sprintf("/tmp/buildpacks/%x/manifest.yml", md5.Sum([]byte(buildpack))))
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+
+			return fmt.Errorf("failed to copy manifest.yml from container: %w", err)
+		}
+
+		entries, err := parseManifestDependencies(manifest)
+		manifest.Close()
+		if err != nil {
+			return err
+		}
+
+		for i, dependency := range dependencies {
+			if dependency.Buildpack != "" {
+				continue
+			}
+
+			for _, entry := range entries {
+				if entry.Name == dependency.Name && entry.Version == dependency.Version {
+					dependencies[i].Buildpack = buildpack
+					dependencies[i].URI = entry.URI
+					dependencies[i].SHA256 = entry.SHA256
+					break
+				}
+			}
+		}
+	}
+
+	components = append(components, dependencies...)
+
+	err = os.MkdirAll(filepath.Join(s.workspace, "sboms"), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create sboms directory: %w", err)
+	}
+
+	content, err := json.Marshal(components)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sbom: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(s.workspace, "sboms", fmt.Sprintf("%s.json", name)), content, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write sbom: %w", err)
+	}
+
+	return nil
+}
+
+func (s Stage) copyDroplet(ctx context.Context, containerID, name string) error {
+	droplet, _, err := s.client.CopyFromContainer(ctx, containerID, "/tmp/droplet")
+	if err != nil {
+		return fmt.Errorf("failed to copy droplet from container: %w", err)
+	}
+	defer droplet.Close()
+
+	dir := filepath.Join(s.workspace, "droplets")
+	err = os.MkdirAll(filepath.Join(dir, "sha256"), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create droplets directory: %w", err)
+	}
+
+	extension := s.dropletExtension()
+
+	err = removeStaleDroplets(dir, name)
+	if err != nil {
+		return err
+	}
+
+	dropletFile, err := os.CreateTemp(dir, name+"-*"+extension)
+	if err != nil {
+		return fmt.Errorf("failed to create droplet tarball: %w", err)
+	}
+	defer os.Remove(dropletFile.Name())
+	defer dropletFile.Close()
+
+	hash := sha256.New()
+	w := io.MultiWriter(dropletFile, hash)
+
+	tr := tar.NewReader(droplet)
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("failed to retrieve build cache from tarball: %w", err)
+			return fmt.Errorf("failed to retrieve droplet from tarball: %w", err)
 		}
 
-		if hdr.Name == "output-cache" {
-			cachePath := filepath.Join(s.workspace, "build-cache", name)
-			outputFile, err := os.Create(cachePath)
-			if err != nil {
-				return "", fmt.Errorf("failed to create build-cache path: %w", err)
-			}
+		if hdr.Name == "droplet" {
+			if s.zstdDroplet {
+				err = recompressZstd(w, io.LimitReader(tr, hdr.Size))
+				if err != nil {
+					return fmt.Errorf("failed to recompress droplet: %w", err)
+				}
 
-			_, err = io.CopyN(outputFile, tr, hdr.Size)
-			if err != nil {
-				return "", fmt.Errorf("failed to copy build cache: %w", err)
+				continue
 			}
-			defer os.RemoveAll(cachePath)
 
-			err = s.archiver.WithPrefix("/tmp/cache").Compress(cachePath, filepath.Join(s.workspace, "build-cache", fmt.Sprintf("%s.tar.gz", name)))
+			_, err = io.CopyN(w, tr, hdr.Size)
 			if err != nil {
-				return "", fmt.Errorf("failed to recompress build cache: %w", err)
+				return fmt.Errorf("failed to copy droplet from tarball: %w", err)
 			}
 		}
 	}
 
-	result, _, err := s.client.CopyFromContainer(ctx, containerID, "/tmp/result.json")
+	err = dropletFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close droplet tarball: %w", err)
+	}
+
+	unlock, err := lockDroplets(ctx, dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	blob := filepath.Join("sha256", hex.EncodeToString(hash.Sum(nil))+extension)
+	err = os.Rename(dropletFile.Name(), filepath.Join(dir, blob))
+	if err != nil {
+		return fmt.Errorf("failed to store droplet: %w", err)
+	}
+
+	err = linkDroplet(dir, blob, filepath.Join(dir, name+extension))
 	if err != nil {
-		return "", fmt.Errorf("failed to copy result.json from container: %w", err)
+		return fmt.Errorf("failed to link droplet: %w", err)
+	}
+
+	if s.signer != nil {
+		err = writeSignature(filepath.Join(dir, name+extension), hash.Sum(nil), s.signer)
+		if err != nil {
+			return err
+		}
 	}
-	defer result.Close()
+
+	return nil
+}
+
+func removeStaleDroplets(dir, name string) error {
+	for _, stale := range []string{".tar.gz", ".tar.zst", ".tar.gz" + SignatureExtension, ".tar.zst" + SignatureExtension} {
+		err := os.RemoveAll(filepath.Join(dir, name+stale))
+		if err != nil {
+			return fmt.Errorf("failed to remove stale droplet: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func recompressZstd(w io.Writer, r io.Reader) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(zw, gzr)
+	if err != nil {
+		zw.Close()
+		return err
+	}
+
+	return zw.Close()
+}
+
+func (s Stage) readResult(ctx context.Context, containerID string) (StageResult, error) {
+	resultJSON, _, err := s.client.CopyFromContainer(ctx, containerID, "/tmp/result.json")
+	if err != nil {
+		return StageResult{}, fmt.Errorf("failed to copy result.json from container: %w", err)
+	}
+	defer resultJSON.Close()
 
 	buffer := bytes.NewBuffer(nil)
 
-	tr = tar.NewReader(result)
+	tr := tar.NewReader(resultJSON)
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("failed to retrieve result.json from tarball: %w", err)
+			return StageResult{}, fmt.Errorf("failed to retrieve result.json from tarball: %w", err)
 		}
 
 		if hdr.Name == "result.json" {
 			_, err = io.CopyN(buffer, tr, hdr.Size)
 			if err != nil {
-				return "", fmt.Errorf("failed to copy result.json from tarball: %w", err)
+				return StageResult{}, fmt.Errorf("failed to copy result.json from tarball: %w", err)
 			}
 		}
 	}
@@ -188,23 +483,48 @@ func (s Stage) Run(ctx context.Context, logs io.Writer, containerID, name string
 			Type    string `json:"type"`
 			Command string `json:"command"`
 		} `json:"processes"`
+		Sidecars []struct {
+			Name         string   `json:"name"`
+			Command      string   `json:"command"`
+			ProcessTypes []string `json:"process_types"`
+		} `json:"sidecars"`
+		LifecycleMetadata struct {
+			DetectedBuildpack string `json:"detected_buildpack"`
+			Buildpacks        []struct {
+				Key     string `json:"key"`
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"buildpacks"`
+		} `json:"lifecycle_metadata"`
 	}
 	err = json.NewDecoder(buffer).Decode(&resultContent)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse result.json: %w", err)
+		return StageResult{}, fmt.Errorf("failed to parse result.json: %w", err)
 	}
 
-	var command string
+	result := StageResult{DetectOutput: resultContent.LifecycleMetadata.DetectedBuildpack}
 	for _, process := range resultContent.Processes {
 		if process.Type == "web" {
-			command = process.Command
+			result.Command = process.Command
 		}
+
+		if result.Processes == nil {
+			result.Processes = map[string]string{}
+		}
+		result.Processes[process.Type] = process.Command
 	}
 
-	err = s.client.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
-	if err != nil {
-		return "", fmt.Errorf("failed to remove container: %w", err)
+	for _, sidecar := range resultContent.Sidecars {
+		result.Sidecars = append(result.Sidecars, Sidecar(sidecar))
+	}
+
+	for _, buildpack := range resultContent.LifecycleMetadata.Buildpacks {
+		result.Buildpacks = append(result.Buildpacks, StagedBuildpack{
+			Key:     buildpack.Key,
+			Name:    buildpack.Name,
+			Version: buildpack.Version,
+		})
 	}
 
-	return command, nil
+	return result, nil
 }
@@ -5,18 +5,26 @@ import (
 	"bytes"
 	"compress/gzip"
 	gocontext "context"
+	"crypto/ed25519"
+	"crypto/md5"
+	"crypto/rand"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"testing/iotest"
+	"time"
 
 	"github.com/cloudfoundry/switchblade/internal/docker"
 	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/paketo-buildpacks/packit/v2/vacation"
 	"github.com/sclevine/spec"
 
@@ -74,7 +82,17 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 						"processes": [
 							{ "type": "web", "command": "some-command" },
 							{ "type": "worker", "command": "other-command" }
-						]
+						],
+						"sidecars": [
+							{ "name": "some-sidecar", "command": "./some-sidecar", "process_types": ["web"], "memory": 64 }
+						],
+						"lifecycle_metadata": {
+							"detected_buildpack": "go",
+							"buildpacks": [
+								{ "key": "some-buildpack" },
+								{ "key": "go_buildpack", "name": "go", "version": "1.10.2" }
+							]
+						}
 					}`)
 					if err != nil {
 						return nil, types.ContainerPathStat{}, err
@@ -97,9 +115,23 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 			ctx := gocontext.Background()
 			logs := bytes.NewBuffer(nil)
 
-			command, err := stage.Run(ctx, logs, "some-container-id", "some-app")
+			result, err := stage.Run(ctx, logs, "some-container-id", "some-app")
 			Expect(err).NotTo(HaveOccurred())
-			Expect(command).To(Equal("some-command"))
+			Expect(result).To(Equal(docker.StageResult{
+				Command: "some-command",
+				Processes: map[string]string{
+					"web":    "some-command",
+					"worker": "other-command",
+				},
+				Sidecars: []docker.Sidecar{
+					{Name: "some-sidecar", Command: "./some-sidecar", ProcessTypes: []string{"web"}},
+				},
+				Buildpacks: []docker.StagedBuildpack{
+					{Key: "some-buildpack"},
+					{Key: "go_buildpack", Name: "go", Version: "1.10.2"},
+				},
+				DetectOutput: "go",
+			}))
 
 			Expect(client.ContainerStartCall.Receives.ContainerID).To(Equal("some-container-id"))
 
@@ -110,18 +142,18 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 			Expect(client.ContainerLogsCall.Receives.Options).To(Equal(types.ContainerLogsOptions{
 				ShowStdout: true,
 				ShowStderr: true,
+				Follow:     true,
 			}))
 
-			Expect(copyFromContainerInvocations).To(HaveLen(3))
-			Expect(copyFromContainerInvocations[0].ContainerID).To(Equal("some-container-id"))
-			Expect(copyFromContainerInvocations[0].SrcPath).To(Equal("/tmp/droplet"))
-			Expect(copyFromContainerInvocations[1].ContainerID).To(Equal("some-container-id"))
-			Expect(copyFromContainerInvocations[1].SrcPath).To(Equal("/tmp/output-cache"))
-			Expect(copyFromContainerInvocations[2].ContainerID).To(Equal("some-container-id"))
-			Expect(copyFromContainerInvocations[2].SrcPath).To(Equal("/tmp/result.json"))
+			Expect(copyFromContainerInvocations).To(ConsistOf(
+				copyFromContainerInvocation{ContainerID: "some-container-id", SrcPath: "/tmp/droplet"},
+				copyFromContainerInvocation{ContainerID: "some-container-id", SrcPath: "/tmp/result.json"},
+				copyFromContainerInvocation{ContainerID: "some-container-id", SrcPath: "/tmp/output-cache"},
+			))
+			Expect(copyFromContainerInvocations[2].SrcPath).To(Equal("/tmp/output-cache"))
 
 			Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-container-id"))
-			Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{Force: true}))
+			Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}))
 
 			Expect(logs).To(ContainLines("Fetching container logs..."))
 
@@ -140,6 +172,497 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 			Expect(string(content)).To(Equal("some-cache-contents"))
 		})
 
+		context("when another app produces an identical droplet", func() {
+			it.Before(func() {
+				_, err := stage.Run(gocontext.Background(), bytes.NewBuffer(nil), "other-container-id", "other-app")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("links both apps to the same content-addressed droplet", func() {
+				_, err := stage.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				target, err := os.Readlink(filepath.Join(workspace, "droplets", "some-app.tar.gz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(target).To(MatchRegexp(`^sha256/[0-9a-f]{64}\.tar\.gz$`))
+
+				otherTarget, err := os.Readlink(filepath.Join(workspace, "droplets", "other-app.tar.gz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(otherTarget).To(Equal(target))
+
+				blobs, err := os.ReadDir(filepath.Join(workspace, "droplets", "sha256"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(blobs).To(HaveLen(1))
+			})
+		})
+
+		context("WithDropletSigning", func() {
+			var key ed25519.PrivateKey
+
+			it.Before(func() {
+				var err error
+				_, key, err = ed25519.GenerateKey(rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.MkdirAll(filepath.Join(workspace, "droplets"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workspace, "droplets", "some-app.tar.zst.sig"), []byte("stale-signature"), 0600)).To(Succeed())
+			})
+
+			it("writes a detached signature over the droplet", func() {
+				_, err := stage.WithDropletSigning(key).Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz.sig")).To(BeARegularFile())
+				Expect(filepath.Join(workspace, "droplets", "some-app.tar.zst.sig")).NotTo(BeAnExistingFile())
+
+				err = docker.VerifyDroplet(filepath.Join(workspace, "droplets", "some-app.tar.gz"), key.Public())
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("WithDropletCache", func() {
+			var cacheDir string
+
+			it.Before(func() {
+				var err error
+				cacheDir, err = os.MkdirTemp("", "droplet-cache")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.MkdirAll(filepath.Join(workspace, "manifests"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workspace, "manifests", "some-app.json"), []byte(`{"source": "sha256:some-source-digest"}`), 0600)).To(Succeed())
+
+				stage = stage.WithDropletCache(docker.NewDropletCache(cacheDir))
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(cacheDir)).To(Succeed())
+			})
+
+			it("reuses the droplet when the same source is staged again", func() {
+				first, err := stage.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.ContainerStartCall.CallCount).To(Equal(1))
+
+				Expect(os.MkdirAll(filepath.Join(workspace, "manifests"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workspace, "manifests", "other-app.json"), []byte(`{"name": "other-app", "source": "sha256:some-source-digest"}`), 0600)).To(Succeed())
+
+				logs := bytes.NewBuffer(nil)
+				second, err := stage.Run(gocontext.Background(), logs, "other-container-id", "other-app")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(second).To(Equal(first))
+
+				Expect(client.ContainerStartCall.CallCount).To(Equal(1))
+				Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("other-container-id"))
+				Expect(logs).To(ContainSubstring("Using cached droplet"))
+
+				content, err := os.ReadFile(filepath.Join(workspace, "droplets", "other-app.tar.gz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(content).NotTo(BeEmpty())
+			})
+
+			context("when the source changes", func() {
+				it("stages it again", func() {
+					_, err := stage.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(os.WriteFile(filepath.Join(workspace, "manifests", "some-app.json"), []byte(`{"source": "sha256:other-source-digest"}`), 0600)).To(Succeed())
+
+					_, err = stage.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(client.ContainerStartCall.CallCount).To(Equal(2))
+				})
+			})
+
+			context("when SBOM collection is enabled", func() {
+				it("does not use the cache", func() {
+					_, err := stage.WithSBOM().Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					entries, err := os.ReadDir(cacheDir)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(entries).To(BeEmpty())
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the manifest cannot be read", func() {
+					it.Before(func() {
+						Expect(os.RemoveAll(filepath.Join(workspace, "manifests"))).To(Succeed())
+					})
+
+					it("returns an error", func() {
+						_, err := stage.Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+						Expect(err).To(MatchError(ContainSubstring("failed to open manifest:")))
+					})
+				})
+			})
+		})
+
+		context("WithZstdDroplets", func() {
+			it.Before(func() {
+				stub := client.CopyFromContainerCall.Stub
+				client.CopyFromContainerCall.Stub = func(ctx gocontext.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+					if srcPath == "/tmp/droplet" {
+						buffer := bytes.NewBuffer(nil)
+						if err := generateGzipDroplet(buffer); err != nil {
+							return nil, types.ContainerPathStat{}, err
+						}
+
+						return io.NopCloser(buffer), types.ContainerPathStat{}, nil
+					}
+
+					return stub(ctx, containerID, srcPath)
+				}
+
+				Expect(os.MkdirAll(filepath.Join(workspace, "droplets"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workspace, "droplets", "some-app.tar.gz"), []byte("stale-droplet"), 0600)).To(Succeed())
+			})
+
+			it("recompresses the droplet with zstd", func() {
+				ctx := gocontext.Background()
+				logs := bytes.NewBuffer(nil)
+
+				_, err := stage.WithZstdDroplets().Run(ctx, logs, "some-container-id", "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+
+				file, err := os.Open(filepath.Join(workspace, "droplets", "some-app.tar.zst"))
+				Expect(err).NotTo(HaveOccurred())
+				defer file.Close()
+
+				zr, err := zstd.NewReader(file)
+				Expect(err).NotTo(HaveOccurred())
+				defer zr.Close()
+
+				tr := tar.NewReader(zr)
+				hdr, err := tr.Next()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hdr.Name).To(Equal("some-file"))
+
+				content, err := io.ReadAll(tr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-droplet-contents"))
+			})
+
+			context("failure cases", func() {
+				context("when the droplet is not gzipped", func() {
+					it.Before(func() {
+						client.CopyFromContainerCall.Stub = func(ctx gocontext.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+							buffer := bytes.NewBuffer(nil)
+							if srcPath == "/tmp/droplet" {
+								if err := generateDroplet(buffer); err != nil {
+									return nil, types.ContainerPathStat{}, err
+								}
+							}
+
+							if srcPath == "/tmp/result.json" {
+								if err := generateResultJSON(buffer, `{ "processes": [] }`); err != nil {
+									return nil, types.ContainerPathStat{}, err
+								}
+							}
+
+							return io.NopCloser(buffer), types.ContainerPathStat{}, nil
+						}
+					})
+
+					it("returns an error", func() {
+						ctx := gocontext.Background()
+						logs := bytes.NewBuffer(nil)
+
+						_, err := stage.WithZstdDroplets().Run(ctx, logs, "some-container-id", "some-app")
+						Expect(err).To(MatchError(ContainSubstring("failed to recompress droplet:")))
+					})
+				})
+			})
+		})
+
+		context("WithSBOM", func() {
+			it.Before(func() {
+				containerLogs := bytes.NewBuffer(nil)
+				containerLogsWriter := stdcopy.NewStdWriter(containerLogs, stdcopy.Stdout)
+				_, err := containerLogsWriter.Write([]byte(`-----> Go Buildpack version 1.10.2
+-----> Installing dep 0.5.4
+       Download [https://example.com/dep-0.5.4.tgz]
+-----> Installing go 1.20.1
+-----> Installing go 1.20.1
+-----> Installing glide 0.13.3
+`))
+				Expect(err).NotTo(HaveOccurred())
+				client.ContainerLogsCall.Returns.ReadCloser = io.NopCloser(containerLogs)
+
+				stub := client.CopyFromContainerCall.Stub
+				client.CopyFromContainerCall.Stub = func(ctx gocontext.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+					buffer := bytes.NewBuffer(nil)
+					switch srcPath {
+					case "/tmp/result.json":
+						err := generateResultJSON(buffer, `{
+							"processes": [{ "type": "web", "command": "some-command" }],
+							"lifecycle_metadata": {
+								"buildpacks": [
+									{ "key": "go_buildpack", "name": "go", "version": "1.10.2" },
+									{ "key": "missing_buildpack" }
+								]
+							}
+						}`)
+						if err != nil {
+							return nil, types.ContainerPathStat{}, err
+						}
+
+					case "/tmp/deps":
+						err := generateTarball(buffer, map[string]string{
+							"deps/0/config.yml":    "name: go\nconfig: {}\nversion: 1.10.2\n",
+							"deps/0/go/bin/go":     "some-binary",
+							"deps/1/config.yml":    "",
+							"deps/some-config.yml": "name: other\n",
+						})
+						if err != nil {
+							return nil, types.ContainerPathStat{}, err
+						}
+
+					case fmt.Sprintf("/tmp/buildpacks/%x/manifest.yml", md5.Sum([]byte("go_buildpack"))):
+						err := generateTarball(buffer, map[string]string{
+							"manifest.yml": `---
+language: go
+dependencies:
+- name: go
+  version: 1.20.1
+  uri: https://example.com/go-1.20.1.tgz
+  sha256: some-go-sha
+- name: dep
+  version: 0.5.4
+  uri: https://example.com/dep-0.5.4.tgz
+  sha256: some-dep-sha
+`,
+						})
+						if err != nil {
+							return nil, types.ContainerPathStat{}, err
+						}
+
+					case fmt.Sprintf("/tmp/buildpacks/%x/manifest.yml", md5.Sum([]byte("missing_buildpack"))):
+						return nil, types.ContainerPathStat{}, errdefs.NotFound(errors.New("no such file"))
+
+					default:
+						return stub(ctx, containerID, srcPath)
+					}
+
+					return io.NopCloser(buffer), types.ContainerPathStat{}, nil
+				}
+			})
+
+			it("writes an sbom for the staged app", func() {
+				ctx := gocontext.Background()
+				logs := bytes.NewBuffer(nil)
+
+				_, err := stage.WithSBOM().Run(ctx, logs, "some-container-id", "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logs).To(ContainLines("-----> Installing go 1.20.1"))
+
+				components, err := docker.ReadSBOM(filepath.Join(workspace, "sboms", "some-app.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(components).To(Equal([]docker.SBOMComponent{
+					{
+						Type:    docker.SBOMComponentBuildpack,
+						Name:    "go",
+						Version: "1.10.2",
+					},
+					{
+						Type:      docker.SBOMComponentDependency,
+						Name:      "dep",
+						Version:   "0.5.4",
+						Buildpack: "go_buildpack",
+						URI:       "https://example.com/dep-0.5.4.tgz",
+						SHA256:    "some-dep-sha",
+					},
+					{
+						Type:      docker.SBOMComponentDependency,
+						Name:      "go",
+						Version:   "1.20.1",
+						Buildpack: "go_buildpack",
+						URI:       "https://example.com/go-1.20.1.tgz",
+						SHA256:    "some-go-sha",
+					},
+					{
+						Type:    docker.SBOMComponentDependency,
+						Name:    "glide",
+						Version: "0.13.3",
+					},
+				}))
+
+				Expect(client.ContainerRemoveCall.CallCount).To(Equal(1))
+			})
+
+			it("does not collect an sbom by default", func() {
+				ctx := gocontext.Background()
+				logs := bytes.NewBuffer(nil)
+
+				_, err := stage.Run(ctx, logs, "some-container-id", "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(workspace, "sboms")).NotTo(BeADirectory())
+			})
+
+			context("failure cases", func() {
+				context("when the deps cannot be copied from the container", func() {
+					it.Before(func() {
+						stub := client.CopyFromContainerCall.Stub
+						client.CopyFromContainerCall.Stub = func(ctx gocontext.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+							if srcPath == "/tmp/deps" {
+								return nil, types.ContainerPathStat{}, errors.New("failed to copy deps")
+							}
+
+							return stub(ctx, containerID, srcPath)
+						}
+					})
+
+					it("returns an error", func() {
+						_, err := stage.WithSBOM().Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+						Expect(err).To(MatchError("failed to copy deps from container: failed to copy deps"))
+					})
+				})
+
+				context("when a deps config is malformed", func() {
+					it.Before(func() {
+						stub := client.CopyFromContainerCall.Stub
+						client.CopyFromContainerCall.Stub = func(ctx gocontext.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+							if srcPath == "/tmp/deps" {
+								buffer := bytes.NewBuffer(nil)
+								err := generateTarball(buffer, map[string]string{"deps/0/config.yml": "%%%"})
+								if err != nil {
+									return nil, types.ContainerPathStat{}, err
+								}
+
+								return io.NopCloser(buffer), types.ContainerPathStat{}, nil
+							}
+
+							return stub(ctx, containerID, srcPath)
+						}
+					})
+
+					it("returns an error", func() {
+						_, err := stage.WithSBOM().Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+						Expect(err).To(MatchError(ContainSubstring("failed to parse deps/0/config.yml:")))
+					})
+				})
+
+				context("when a buildpack manifest cannot be copied from the container", func() {
+					it.Before(func() {
+						stub := client.CopyFromContainerCall.Stub
+						client.CopyFromContainerCall.Stub = func(ctx gocontext.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+							if srcPath == fmt.Sprintf("/tmp/buildpacks/%x/manifest.yml", md5.Sum([]byte("go_buildpack"))) {
+								return nil, types.ContainerPathStat{}, errors.New("failed to copy manifest")
+							}
+
+							return stub(ctx, containerID, srcPath)
+						}
+					})
+
+					it("returns an error", func() {
+						_, err := stage.WithSBOM().Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+						Expect(err).To(MatchError("failed to copy manifest.yml from container: failed to copy manifest"))
+					})
+				})
+
+				context("when a buildpack manifest is malformed", func() {
+					it.Before(func() {
+						stub := client.CopyFromContainerCall.Stub
+						client.CopyFromContainerCall.Stub = func(ctx gocontext.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+							if srcPath == fmt.Sprintf("/tmp/buildpacks/%x/manifest.yml", md5.Sum([]byte("go_buildpack"))) {
+								buffer := bytes.NewBuffer(nil)
+								err := generateTarball(buffer, map[string]string{"manifest.yml": "%%%"})
+								if err != nil {
+									return nil, types.ContainerPathStat{}, err
+								}
+
+								return io.NopCloser(buffer), types.ContainerPathStat{}, nil
+							}
+
+							return stub(ctx, containerID, srcPath)
+						}
+					})
+
+					it("returns an error", func() {
+						_, err := stage.WithSBOM().Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+						Expect(err).To(MatchError(ContainSubstring("failed to parse manifest.yml:")))
+					})
+				})
+
+				context("when the sboms directory cannot be created", func() {
+					it.Before(func() {
+						Expect(os.WriteFile(filepath.Join(workspace, "sboms"), nil, 0600)).To(Succeed())
+					})
+
+					it("returns an error", func() {
+						_, err := stage.WithSBOM().Run(gocontext.Background(), bytes.NewBuffer(nil), "some-container-id", "some-app")
+						Expect(err).To(MatchError(ContainSubstring("failed to create sboms directory:")))
+					})
+				})
+			})
+		})
+
+		context("when staging takes a while", func() {
+			var logged chan struct{}
+
+			it.Before(func() {
+				logged = make(chan struct{})
+				exited := make(chan container.WaitResponse)
+				failed := make(chan error, 1)
+				go func() {
+					select {
+					case <-logged:
+						close(exited)
+					case <-time.After(5 * time.Second):
+						failed <- errors.New("timed out waiting for staging logs")
+					}
+				}()
+
+				client.ContainerWaitCall.Returns.WaitResponseChannel = exited
+				client.ContainerWaitCall.Returns.ErrorChannel = failed
+			})
+
+			it("streams the logs before the container exits", func() {
+				ctx := gocontext.Background()
+				logs := bytes.NewBuffer(nil)
+
+				_, err := stage.Run(ctx, notifyingWriter{Writer: logs, once: &sync.Once{}, written: logged}, "some-container-id", "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logs).To(ContainLines("Fetching container logs..."))
+			})
+		})
+
+		context("when copying the droplet is slow", func() {
+			it.Before(func() {
+				resultRequested := make(chan struct{})
+				stub := client.CopyFromContainerCall.Stub
+				client.CopyFromContainerCall.Stub = func(ctx gocontext.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+					content, stat, err := stub(ctx, containerID, srcPath)
+					switch srcPath {
+					case "/tmp/result.json":
+						close(resultRequested)
+					case "/tmp/droplet":
+						content = io.NopCloser(io.MultiReader(blockingReader{wait: resultRequested}, content))
+					}
+
+					return content, stat, err
+				}
+			})
+
+			it("fetches the result.json while the droplet is being copied", func() {
+				ctx := gocontext.Background()
+				logs := bytes.NewBuffer(nil)
+
+				result, err := stage.Run(ctx, logs, "some-container-id", "some-app")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Command).To(Equal("some-command"))
+
+				content, err := os.ReadFile(filepath.Join(workspace, "droplets", "some-app.tar.gz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-droplet-contents"))
+			})
+		})
+
 		context("when the container exits with a non-zero status", func() {
 			it.Before(func() {
 				containerWaitOKBodyChannel := make(chan container.WaitResponse)
@@ -169,10 +692,11 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 				Expect(client.ContainerLogsCall.Receives.Options).To(Equal(types.ContainerLogsOptions{
 					ShowStdout: true,
 					ShowStderr: true,
+					Follow:     true,
 				}))
 
 				Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-container-id"))
-				Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{Force: true}))
+				Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}))
 
 				Expect(copyFromContainerInvocations).To(HaveLen(0))
 
@@ -272,7 +796,7 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 							return nil, types.ContainerPathStat{}, errors.New("could not copy droplet")
 						}
 
-						return nil, types.ContainerPathStat{}, nil
+						return io.NopCloser(bytes.NewBuffer(nil)), types.ContainerPathStat{}, nil
 					}
 				})
 
@@ -308,7 +832,7 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 							return io.NopCloser(iotest.ErrReader(errors.New("could not read tarball"))), types.ContainerPathStat{}, nil
 						}
 
-						return nil, types.ContainerPathStat{}, nil
+						return io.NopCloser(bytes.NewBuffer(nil)), types.ContainerPathStat{}, nil
 					}
 				})
 
@@ -333,6 +857,11 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 
 						case "/tmp/output-cache":
 							return nil, types.ContainerPathStat{}, errors.New("could not copy output-cache")
+
+						case "/tmp/result.json":
+							if err := generateResultJSON(buffer, `{ "processes": [] }`); err != nil {
+								return nil, types.ContainerPathStat{}, err
+							}
 						}
 
 						return io.NopCloser(buffer), types.ContainerPathStat{}, nil
@@ -361,6 +890,11 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 
 						case "/tmp/output-cache":
 							return io.NopCloser(iotest.ErrReader(errors.New("could not read tarball"))), types.ContainerPathStat{}, nil
+
+						case "/tmp/result.json":
+							if err := generateResultJSON(buffer, `{ "processes": [] }`); err != nil {
+								return nil, types.ContainerPathStat{}, err
+							}
 						}
 
 						return io.NopCloser(buffer), types.ContainerPathStat{}, nil
@@ -414,6 +948,11 @@ func testStage(t *testing.T, context spec.G, it spec.S) {
 							if err != nil {
 								return nil, types.ContainerPathStat{}, err
 							}
+
+						case "/tmp/result.json":
+							if err := generateResultJSON(buffer, `{ "processes": [] }`); err != nil {
+								return nil, types.ContainerPathStat{}, err
+							}
 						}
 
 						return io.NopCloser(buffer), types.ContainerPathStat{}, nil
@@ -564,6 +1103,41 @@ func generateDroplet(buffer io.Writer) error {
 	return nil
 }
 
+func generateGzipDroplet(buffer io.Writer) error {
+	droplet := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(droplet)
+	ttw := tar.NewWriter(gw)
+
+	err := ttw.WriteHeader(&tar.Header{Name: "some-file", Mode: 0600, Size: 21})
+	if err != nil {
+		return err
+	}
+
+	_, err = ttw.Write([]byte("some-droplet-contents"))
+	if err != nil {
+		return err
+	}
+
+	if err := ttw.Close(); err != nil {
+		return err
+	}
+
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(buffer)
+	defer tw.Close()
+
+	err = tw.WriteHeader(&tar.Header{Name: "droplet", Mode: 0600, Size: int64(droplet.Len())})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, droplet)
+	return err
+}
+
 func generateBuildCache(buffer io.Writer) error {
 	cache := bytes.NewBuffer(nil)
 	gw := gzip.NewWriter(cache)
@@ -602,6 +1176,25 @@ func generateBuildCache(buffer io.Writer) error {
 	return nil
 }
 
+func generateTarball(buffer io.Writer, files map[string]string) error {
+	tw := tar.NewWriter(buffer)
+	defer tw.Close()
+
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))})
+		if err != nil {
+			return err
+		}
+
+		_, err = tw.Write([]byte(content))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func generateResultJSON(buffer io.Writer, result string) error {
 	tw := tar.NewWriter(buffer)
 	defer tw.Close()
@@ -619,3 +1212,28 @@ func generateResultJSON(buffer io.Writer, result string) error {
 
 	return nil
 }
+
+type blockingReader struct {
+	wait chan struct{}
+}
+
+func (r blockingReader) Read(p []byte) (int, error) {
+	select {
+	case <-r.wait:
+		return 0, io.EOF
+	case <-time.After(5 * time.Second):
+		return 0, errors.New("timed out waiting for reader to unblock")
+	}
+}
+
+type notifyingWriter struct {
+	io.Writer
+	once    *sync.Once
+	written chan struct{}
+}
+
+func (w notifyingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.once.Do(func() { close(w.written) })
+	return n, err
+}
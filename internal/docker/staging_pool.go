@@ -0,0 +1,223 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	StagingPoolLabel  = "switchblade.staging-pool"
+	StagingScriptPath = "/tmp/switchblade/stage"
+)
+
+//go:generate faux --interface StagingPoolClient --output fakes/staging_pool_client.go
+type StagingPoolClient interface {
+	ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error)
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error)
+	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+}
+
+type StagingPool struct {
+	client    StagingPoolClient
+	lifecycle LifecycleBuilder
+	networks  SetupNetworkManager
+	workspace string
+	size      int
+	puller    StackPuller
+	runID     string
+
+	ready   map[string][]string
+	errs    map[string]error
+	closed  *bool
+	filling *sync.WaitGroup
+	fill    *sync.Mutex
+	m       *sync.Mutex
+}
+
+func NewStagingPool(client StagingPoolClient, lifecycle LifecycleBuilder, networks SetupNetworkManager, workspace string, size int) StagingPool {
+	return StagingPool{
+		client:    client,
+		lifecycle: lifecycle,
+		networks:  networks,
+		workspace: workspace,
+		size:      size,
+		puller:    NewStackPuller(client, filepath.Join(workspace, "locks")),
+		ready:     map[string][]string{},
+		errs:      map[string]error{},
+		closed:    new(bool),
+		filling:   &sync.WaitGroup{},
+		fill:      &sync.Mutex{},
+		m:         &sync.Mutex{},
+	}
+}
+
+func (p StagingPool) WithStackPuller(puller StackPuller) StagingPool {
+	p.puller = puller
+	return p
+}
+
+func (p StagingPool) WithRunID(runID string) StagingPool {
+	p.runID = runID
+	return p
+}
+
+func (p StagingPool) Fill(ctx context.Context, stack string) error {
+	p.fill.Lock()
+	defer p.fill.Unlock()
+
+	if p.count(stack) >= p.size {
+		return nil
+	}
+
+	lifecycle, err := p.lifecycle.Build(ctx, BuildpackAppLifecycleRepoURL, filepath.Join(p.workspace, "lifecycle"))
+	if err != nil {
+		return fmt.Errorf("failed to build lifecycle: %w", err)
+	}
+
+	image := stackImage(stack)
+	err = p.puller.Pull(ctx, io.Discard, image)
+	if err != nil {
+		return err
+	}
+
+	err = p.networks.Create(ctx, internalNetworkName(p.runID), "bridge", true)
+	if err != nil {
+		return fmt.Errorf("failed to create network: %w", err)
+	}
+	p.networks.Acquire(internalNetworkName(p.runID), StagingPoolLabel)
+
+	for p.count(stack) < p.size {
+		containerConfig := container.Config{
+			Image:      image,
+			Cmd:        []string{"/bin/bash", StagingScriptPath},
+			User:       "vcap",
+			WorkingDir: "/home/vcap",
+			Labels:     runLabels(p.runID, map[string]string{StagingPoolLabel: stack}),
+		}
+
+		hostConfig := container.HostConfig{
+			NetworkMode: container.NetworkMode(internalNetworkName(p.runID)),
+		}
+
+		resp, err := p.client.ContainerCreate(ctx, &containerConfig, &hostConfig, nil, nil, "")
+		if err != nil {
+			return fmt.Errorf("failed to create pooled container: %w", err)
+		}
+
+		tarball, err := os.Open(lifecycle)
+		if err != nil {
+			_ = p.client.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+			return fmt.Errorf("failed to open lifecycle: %w", err)
+		}
+
+		err = p.client.CopyToContainer(ctx, resp.ID, "/", tarball, types.CopyToContainerOptions{})
+		if err != nil {
+			tarball.Close()
+			_ = p.client.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+			return fmt.Errorf("failed to copy lifecycle into pooled container: %w", err)
+		}
+
+		err = tarball.Close()
+		if err != nil && !errors.Is(err, os.ErrClosed) {
+			return fmt.Errorf("failed to close lifecycle: %w", err)
+		}
+
+		p.m.Lock()
+		p.ready[stack] = append(p.ready[stack], resp.ID)
+		p.m.Unlock()
+	}
+
+	return nil
+}
+
+func (p StagingPool) Take(ctx context.Context, stack string) (string, bool, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	err := p.errs[stack]
+	if err != nil {
+		delete(p.errs, stack)
+		return "", false, fmt.Errorf("failed to refill staging pool: %w", err)
+	}
+
+	var containerID string
+	if containers := p.ready[stack]; len(containers) > 0 {
+		containerID, p.ready[stack] = containers[0], containers[1:]
+	}
+
+	p.warm(ctx, stack)
+
+	return containerID, containerID != "", nil
+}
+
+func (p StagingPool) Warm(ctx context.Context, stack string) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.warm(ctx, stack)
+}
+
+// warm starts filling the pool in the background. It must be called with p.m
+// held so that Drain cannot start waiting between the closed check and the
+// call to filling.Add.
+func (p StagingPool) warm(ctx context.Context, stack string) {
+	if *p.closed {
+		return
+	}
+
+	p.filling.Add(1)
+	go func() {
+		defer p.filling.Done()
+
+		err := p.Fill(ctx, stack)
+		if err != nil && ctx.Err() == nil {
+			p.m.Lock()
+			p.errs[stack] = err
+			p.m.Unlock()
+		}
+	}()
+}
+
+func (p StagingPool) Drain(ctx context.Context) error {
+	p.m.Lock()
+	*p.closed = true
+	p.m.Unlock()
+
+	p.filling.Wait()
+
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	for stack, containers := range p.ready {
+		for _, containerID := range containers {
+			err := p.client.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+			if err != nil && !client.IsErrNotFound(err) {
+				return fmt.Errorf("failed to remove pooled container: %w", err)
+			}
+		}
+
+		delete(p.ready, stack)
+	}
+
+	return nil
+}
+
+func (p StagingPool) count(stack string) int {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	return len(p.ready[stack])
+}
@@ -0,0 +1,356 @@
+package docker_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testStagingPool(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		pool docker.StagingPool
+
+		client           *fakes.StagingPoolClient
+		lifecycleBuilder *fakes.LifecycleBuilder
+		networkManager   *fakes.SetupNetworkManager
+		workspace        string
+
+		copyToContainerInvocations []copyToContainerInvocation
+	)
+
+	it.Before(func() {
+		var err error
+		workspace, err = os.MkdirTemp("", "workspace")
+		Expect(err).NotTo(HaveOccurred())
+
+		lifecycleBuilder = &fakes.LifecycleBuilder{}
+		lifecycleBuilder.BuildCall.Returns.Path = filepath.Join(workspace, "lifecycle", "lifecycle.tar.gz")
+		Expect(os.MkdirAll(filepath.Join(workspace, "lifecycle"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(workspace, "lifecycle", "lifecycle.tar.gz"), []byte("lifecycle-content"), 0600)).To(Succeed())
+
+		networkManager = &fakes.SetupNetworkManager{}
+
+		client = &fakes.StagingPoolClient{}
+		client.ImagePullCall.Stub = func(gocontext.Context, string, types.ImagePullOptions) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewBuffer([]byte("Pulling image...\n"))), nil
+		}
+		client.ContainerCreateCall.Stub = func(ctx gocontext.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error) {
+			return container.CreateResponse{ID: fmt.Sprintf("pooled-container-%d", client.ContainerCreateCall.CallCount)}, nil
+		}
+		client.CopyToContainerCall.Stub = func(ctx gocontext.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+			b, err := io.ReadAll(content)
+			if err != nil {
+				return err
+			}
+
+			copyToContainerInvocations = append(copyToContainerInvocations, copyToContainerInvocation{
+				ContainerID: containerID,
+				DstPath:     dstPath,
+				Content:     string(b),
+			})
+
+			return nil
+		}
+
+		copyToContainerInvocations = nil
+
+		pool = docker.NewStagingPool(client, lifecycleBuilder, networkManager, workspace, 2)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workspace)).To(Succeed())
+	})
+
+	context("Fill", func() {
+		it("creates containers with the lifecycle until the pool is full", func() {
+			err := pool.Fill(gocontext.Background(), "some-stack")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(lifecycleBuilder.BuildCall.Receives.SourceURI).To(Equal("https://github.com/cloudfoundry/buildpackapplifecycle/archive/refs/heads/master.zip"))
+			Expect(lifecycleBuilder.BuildCall.Receives.Workspace).To(Equal(filepath.Join(workspace, "lifecycle")))
+
+			Expect(client.ImagePullCall.Receives.Ref).To(Equal("cloudfoundry/some-stack:latest"))
+
+			Expect(networkManager.CreateCall.Receives.Name).To(Equal("switchblade-internal"))
+			Expect(networkManager.CreateCall.Receives.Driver).To(Equal("bridge"))
+			Expect(networkManager.CreateCall.Receives.Internal).To(BeTrue())
+			Expect(networkManager.AcquireCall.Receives.Name).To(Equal("switchblade-internal"))
+			Expect(networkManager.AcquireCall.Receives.Owner).To(Equal("switchblade.staging-pool"))
+
+			Expect(client.ContainerCreateCall.CallCount).To(Equal(2))
+			Expect(client.ContainerCreateCall.Receives.Config).To(Equal(&container.Config{
+				Image:      "cloudfoundry/some-stack:latest",
+				Cmd:        []string{"/bin/bash", "/tmp/switchblade/stage"},
+				User:       "vcap",
+				WorkingDir: "/home/vcap",
+				Labels:     map[string]string{"switchblade.staging-pool": "some-stack"},
+			}))
+			Expect(client.ContainerCreateCall.Receives.HostConfig).To(Equal(&container.HostConfig{
+				NetworkMode: container.NetworkMode("switchblade-internal"),
+			}))
+			Expect(client.ContainerCreateCall.Receives.ContainerName).To(BeEmpty())
+
+			Expect(copyToContainerInvocations).To(Equal([]copyToContainerInvocation{
+				{
+					ContainerID: "pooled-container-1",
+					DstPath:     "/",
+					Content:     "lifecycle-content",
+				},
+				{
+					ContainerID: "pooled-container-2",
+					DstPath:     "/",
+					Content:     "lifecycle-content",
+				},
+			}))
+		})
+
+		context("when the stack is pinned by digest and available locally", func() {
+			it("does not pull the image", func() {
+				err := pool.Fill(gocontext.Background(), "some-stack@sha256:some-digest")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ImageInspectWithRawCall.Receives.ImageID).To(Equal("cloudfoundry/some-stack@sha256:some-digest"))
+				Expect(client.ImagePullCall.CallCount).To(Equal(0))
+				Expect(client.ContainerCreateCall.Receives.Config.Image).To(Equal("cloudfoundry/some-stack@sha256:some-digest"))
+			})
+		})
+
+		context("when the pool is already full", func() {
+			it.Before(func() {
+				Expect(pool.Fill(gocontext.Background(), "some-stack")).To(Succeed())
+			})
+
+			it("does nothing", func() {
+				err := pool.Fill(gocontext.Background(), "some-stack")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(lifecycleBuilder.BuildCall.CallCount).To(Equal(1))
+				Expect(client.ContainerCreateCall.CallCount).To(Equal(2))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the lifecycle cannot be built", func() {
+				it.Before(func() {
+					lifecycleBuilder.BuildCall.Returns.Err = errors.New("could not build lifecycle")
+				})
+
+				it("returns an error", func() {
+					err := pool.Fill(gocontext.Background(), "some-stack")
+					Expect(err).To(MatchError("failed to build lifecycle: could not build lifecycle"))
+				})
+			})
+
+			context("when the image cannot be pulled", func() {
+				it.Before(func() {
+					client.ImagePullCall.Stub = nil
+					client.ImagePullCall.Returns.Error = errors.New("could not pull image")
+				})
+
+				it("returns an error", func() {
+					err := pool.Fill(gocontext.Background(), "some-stack")
+					Expect(err).To(MatchError("failed to pull base image: could not pull image"))
+				})
+			})
+
+			context("when the network cannot be created", func() {
+				it.Before(func() {
+					networkManager.CreateCall.Returns.Error = errors.New("could not create network")
+				})
+
+				it("returns an error", func() {
+					err := pool.Fill(gocontext.Background(), "some-stack")
+					Expect(err).To(MatchError("failed to create network: could not create network"))
+				})
+			})
+
+			context("when a container cannot be created", func() {
+				it.Before(func() {
+					client.ContainerCreateCall.Stub = nil
+					client.ContainerCreateCall.Returns.Error = errors.New("could not create container")
+				})
+
+				it("returns an error", func() {
+					err := pool.Fill(gocontext.Background(), "some-stack")
+					Expect(err).To(MatchError("failed to create pooled container: could not create container"))
+				})
+			})
+
+			context("when the lifecycle cannot be opened", func() {
+				it.Before(func() {
+					Expect(os.Remove(filepath.Join(workspace, "lifecycle", "lifecycle.tar.gz"))).To(Succeed())
+				})
+
+				it("removes the container and returns an error", func() {
+					err := pool.Fill(gocontext.Background(), "some-stack")
+					Expect(err).To(MatchError(ContainSubstring("failed to open lifecycle:")))
+
+					Expect(client.ContainerRemoveCall.CallCount).To(Equal(1))
+					Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("pooled-container-1"))
+				})
+			})
+
+			context("when the lifecycle cannot be copied into the container", func() {
+				it.Before(func() {
+					client.CopyToContainerCall.Stub = nil
+					client.CopyToContainerCall.Returns.Error = errors.New("could not copy lifecycle")
+				})
+
+				it("removes the container and returns an error", func() {
+					err := pool.Fill(gocontext.Background(), "some-stack")
+					Expect(err).To(MatchError("failed to copy lifecycle into pooled container: could not copy lifecycle"))
+
+					Expect(client.ContainerRemoveCall.CallCount).To(Equal(1))
+					Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("pooled-container-1"))
+				})
+			})
+		})
+	})
+
+	context("Take", func() {
+		it.Before(func() {
+			Expect(pool.Fill(gocontext.Background(), "some-stack")).To(Succeed())
+		})
+
+		it("returns a ready container and refills the pool", func() {
+			containerID, ok, err := pool.Take(gocontext.Background(), "some-stack")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(containerID).To(Equal("pooled-container-1"))
+
+			Expect(pool.Drain(gocontext.Background())).To(Succeed())
+
+			Expect(client.ContainerCreateCall.CallCount).To(Equal(3))
+			Expect(client.ContainerRemoveCall.CallCount).To(Equal(2))
+		})
+
+		context("when there are no ready containers for the stack", func() {
+			it("reports that no container was taken", func() {
+				containerID, ok, err := pool.Take(gocontext.Background(), "other-stack")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+				Expect(containerID).To(BeEmpty())
+
+				Expect(pool.Drain(gocontext.Background())).To(Succeed())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the pool could not be refilled", func() {
+				it.Before(func() {
+					lifecycleBuilder.BuildCall.Returns.Err = errors.New("could not build lifecycle")
+
+					_, _, err := pool.Take(gocontext.Background(), "other-stack")
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("returns an error", func() {
+					Expect(pool.Drain(gocontext.Background())).To(Succeed())
+
+					_, _, err := pool.Take(gocontext.Background(), "other-stack")
+					Expect(err).To(MatchError("failed to refill staging pool: failed to build lifecycle: could not build lifecycle"))
+				})
+			})
+		})
+	})
+
+	context("Warm", func() {
+		it("fills the pool in the background", func() {
+			pool.Warm(gocontext.Background(), "some-stack")
+
+			Expect(pool.Drain(gocontext.Background())).To(Succeed())
+
+			Expect(client.ContainerCreateCall.CallCount).To(Equal(2))
+			Expect(client.ContainerRemoveCall.CallCount).To(Equal(2))
+		})
+
+		context("when the pool has been drained", func() {
+			it.Before(func() {
+				Expect(pool.Drain(gocontext.Background())).To(Succeed())
+			})
+
+			it("does not start filling the pool", func() {
+				pool.Warm(gocontext.Background(), "some-stack")
+
+				Expect(pool.Drain(gocontext.Background())).To(Succeed())
+				Expect(client.ContainerCreateCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("when the context is cancelled", func() {
+			it.Before(func() {
+				lifecycleBuilder.BuildCall.Stub = func(ctx gocontext.Context, sourceURI, workspace string) (string, error) {
+					return "", ctx.Err()
+				}
+			})
+
+			it("does not report the cancellation on the next take", func() {
+				ctx, cancel := gocontext.WithCancel(gocontext.Background())
+				cancel()
+
+				pool.Warm(ctx, "some-stack")
+				Expect(pool.Drain(gocontext.Background())).To(Succeed())
+
+				_, ok, err := pool.Take(gocontext.Background(), "some-stack")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+		})
+	})
+
+	context("Drain", func() {
+		it.Before(func() {
+			Expect(pool.Fill(gocontext.Background(), "some-stack")).To(Succeed())
+		})
+
+		it("removes the ready containers", func() {
+			err := pool.Drain(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ContainerRemoveCall.CallCount).To(Equal(2))
+			Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}))
+		})
+
+		context("when a container has already been removed", func() {
+			it.Before(func() {
+				client.ContainerRemoveCall.Returns.Error = errdefs.NotFound(errors.New("no such container"))
+			})
+
+			it("ignores the error", func() {
+				err := pool.Drain(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when a container cannot be removed", func() {
+				it.Before(func() {
+					client.ContainerRemoveCall.Returns.Error = errors.New("could not remove container")
+				})
+
+				it("returns an error", func() {
+					err := pool.Drain(gocontext.Background())
+					Expect(err).To(MatchError("failed to remove pooled container: could not remove container"))
+				})
+			})
+		})
+	})
+}
@@ -1,18 +1,30 @@
 package docker
 
 import (
+	"bytes"
 	"context"
+	"crypto"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
+	"github.com/klauspost/compress/zstd"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -21,14 +33,68 @@ type StartPhase interface {
 	WithStack(stack string) StartPhase
 	WithEnv(env map[string]string) StartPhase
 	WithServices(services map[string]map[string]interface{}) StartPhase
+	WithCredentials(credentials map[string]interface{}) StartPhase
+	WithHealthCheckPolling(polling HealthCheckPolling) StartPhase
+	WithHealthCheck(healthCheck HealthCheck) StartPhase
+	WithCustomizedStack() StartPhase
+	WithDevMounts(mounts map[string]string) StartPhase
+	WithUser(uid, gid int) StartPhase
+	WithReadOnlyRootFS() StartPhase
+	WithSecurityProfile(profile SecurityProfile) StartPhase
+	WithProfileScripts(scripts map[string]string) StartPhase
+	WithProcessCommands(commands map[string]func(command string) string) StartPhase
+	WithSidecars(sidecars []Sidecar) StartPhase
+	WithProcessType(processType string) StartPhase
 }
 
+// Sidecar is an additional process that runs in the same container as the
+// process types it lists.
+type Sidecar struct {
+	Name         string
+	Command      string
+	ProcessTypes []string
+}
+
+type StartError struct {
+	ExitCode int64
+	Logs     string
+}
+
+func (e StartError) Error() string {
+	return fmt.Sprintf("App failed to start: container exited with status code (%d)\n\nOutput:\n%s", e.ExitCode, e.Logs)
+}
+
+type HealthCheckPolling struct {
+	InitialDelay   time.Duration
+	Interval       time.Duration
+	RequestTimeout time.Duration
+	Timeout        time.Duration
+}
+
+// HealthCheck decides when a started app counts as healthy, using the same
+// types as Cloud Foundry. A "port" check waits for the app to accept
+// connections, an "http" check waits for the endpoint to respond with a 200
+// status, and a "process" check only requires the process to be running.
+type HealthCheck struct {
+	Type     string
+	Endpoint string
+	Timeout  time.Duration
+}
+
+// portCheckWait is how long a "port" health check holds its connection open
+// before deciding that the app accepted it.
+const portCheckWait = 100 * time.Millisecond
+
 //go:generate faux --interface StartClient --output fakes/start_client.go
 type StartClient interface {
 	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error)
 	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error
 	ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error
 	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+	ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error)
+	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
 }
 
 //go:generate faux --interface StartNetworkManager --output fakes/start_network_manager.go
@@ -37,29 +103,77 @@ type StartNetworkManager interface {
 }
 
 type Start struct {
-	client    StartClient
-	networks  StartNetworkManager
-	workspace string
-	stack     string
-	env       map[string]string
-	services  map[string]map[string]interface{}
+	client      StartClient
+	networks    StartNetworkManager
+	workspace   string
+	stack       string
+	env         map[string]string
+	services    map[string]map[string]interface{}
+	polling     *HealthCheckPolling
+	healthCheck *HealthCheck
+	runID       string
+	clock       Clock
+	customized  bool
+	devMounts   map[string]string
+	user        string
+	readOnly    bool
+	security    *SecurityProfile
+	hostGateway *HostGateway
+	verifier    crypto.PublicKey
+	profile     map[string]string
+	commands    map[string]func(command string) string
+	sidecars    []Sidecar
+	processType string
+	remoteHost  string
+
+	credentials       map[string]interface{}
+	credentialService CredentialServiceRunner
 }
 
 func NewStart(client StartClient, networks StartNetworkManager, workspace, stack string) Start {
 	return Start{
-		client:    client,
-		networks:  networks,
-		workspace: workspace,
-		stack:     stack,
+		client:      client,
+		networks:    networks,
+		workspace:   workspace,
+		stack:       stack,
+		user:        "vcap",
+		clock:       realClock{},
+		processType: "web",
 	}
 }
 
 func (s Start) Run(ctx context.Context, logs io.Writer, name, command string) (string, string, error) {
+	if s.healthCheck != nil {
+		switch s.healthCheck.Type {
+		case "port", "http", "process":
+		default:
+			return "", "", fmt.Errorf("unsupported health check type %q", s.healthCheck.Type)
+		}
+	}
+
+	if s.verifier != nil {
+		err := verifyStoredDroplet(filepath.Join(s.workspace, "droplets"), name, s.verifier)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	var extraHosts []string
+	if s.hostGateway != nil {
+		host, hosts, err := s.hostGateway.Resolve(ctx)
+		if err != nil {
+			return "", "", err
+		}
+
+		extraHosts = hosts
+		s.services = rewriteServiceHosts(s.services, host)
+	}
+
 	env := []string{
 		"LANG=en_US.UTF-8",
 		"MEMORY_LIMIT=1024m",
 		"PORT=8080",
-		fmt.Sprintf(`VCAP_APPLICATION={"application_name":%[1]q,"name":%[1]q,"process_type":"web","limits":{"mem":1024}}`, name),
+		fmt.Sprintf(`VCAP_APPLICATION={"application_name":%[1]q,"name":%[1]q,"process_type":%[2]q,"limits":{"mem":1024}}`, name, s.processType),
 		"VCAP_PLATFORM_OPTIONS={}",
 	}
 	for key, value := range s.env {
@@ -93,23 +207,75 @@ func (s Start) Run(ctx context.Context, logs io.Writer, name, command string) (s
 		env = append(env, "VCAP_SERVICES={}")
 	}
 
+	if len(s.credentials) > 0 {
+		if s.credentialService == nil {
+			return "", "", errors.New("failed to start credential service: no credential service configured")
+		}
+
+		url, err := s.credentialService.Run(ctx, logs, name, s.stack, s.credentials)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to start credential service: %w", err)
+		}
+
+		env = append(env, fmt.Sprintf("CREDHUB_API=%s", url))
+	}
+
+	if override, ok := s.commands[s.processType]; ok {
+		command = override(command)
+	}
+	command = withSidecars(command, s.processType, s.sidecars)
+
+	image := stackImage(s.stack)
+	if s.customized {
+		image = stackSetupImageReference(name)
+	}
+
 	containerConfig := container.Config{
-		Image: fmt.Sprintf("cloudfoundry/%s:latest", s.stack),
+		Image: image,
 		Cmd: []string{
 			"/tmp/lifecycle/launcher",
 			"app",
 			command,
 			"",
 		},
-		User:         "vcap",
+		User:         s.user,
 		Env:          env,
 		WorkingDir:   "/home/vcap",
 		ExposedPorts: nat.PortSet{"8080/tcp": struct{}{}},
+		Labels:       runLabels(s.runID, map[string]string{AppLabel: name}),
 	}
 
 	hostConfig := container.HostConfig{
 		PublishAllPorts: true,
-		NetworkMode:     container.NetworkMode(InternalNetworkName),
+		NetworkMode:     container.NetworkMode(internalNetworkName(s.runID)),
+		ExtraHosts:      extraHosts,
+	}
+
+	if s.security != nil {
+		err := s.security.apply(&hostConfig)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	if s.readOnly {
+		hostConfig.ReadonlyRootfs = true
+		hostConfig.Tmpfs = map[string]string{"/tmp": "rw,exec,mode=1777"}
+		hostConfig.Mounts = append(hostConfig.Mounts, readOnlyRootFSMounts()...)
+	}
+
+	var targets []string
+	for target := range s.devMounts {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	for _, target := range targets {
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: s.devMounts[target],
+			Target: target,
+		})
 	}
 
 	resp, err := s.client.ContainerCreate(ctx, &containerConfig, &hostConfig, nil, nil, name)
@@ -128,23 +294,60 @@ func (s Start) Run(ctx context.Context, logs io.Writer, name, command string) (s
 	}
 	defer lifecycleTarball.Close()
 
-	err = s.client.CopyToContainer(ctx, resp.ID, "/", lifecycleTarball, types.CopyToContainerOptions{})
+	var lifecycle io.Reader = lifecycleTarball
+	lifecyclePath := "/"
+	if s.readOnly {
+		lifecycle = relocateTarball(lifecycleTarball, LifecycleDirectory)
+		lifecyclePath = LifecycleDirectory
+	}
+
+	err = s.client.CopyToContainer(ctx, resp.ID, lifecyclePath, lifecycle, types.CopyToContainerOptions{})
 	if err != nil {
 		return "", "", fmt.Errorf("failed to copy lifecycle into container: %w", err)
 	}
 
-	dropletTarball, err := os.Open(filepath.Join(s.workspace, "droplets", fmt.Sprintf("%s.tar.gz", name)))
+	dropletTarball, err := openDroplet(filepath.Join(s.workspace, "droplets"), name)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to open droplet: %w", err)
 	}
 	defer dropletTarball.Close()
 
-	err = s.client.CopyToContainer(ctx, resp.ID, "/home/vcap/", dropletTarball, types.CopyToContainerOptions{})
+	var droplet io.Reader = dropletTarball
+	if len(targets) > 0 {
+		droplet = excludeMounted(dropletTarball, "/home/vcap", targets)
+	}
+
+	err = s.client.CopyToContainer(ctx, resp.ID, "/home/vcap/", droplet, types.CopyToContainerOptions{})
 	if err != nil {
 		return "", "", fmt.Errorf("failed to copy droplet into container: %w", err)
 	}
 
+	if len(s.profile) > 0 {
+		scripts, err := profileScriptsTarball(s.profile)
+		if err != nil {
+			return "", "", err
+		}
+
+		err = s.client.CopyToContainer(ctx, resp.ID, "/home/vcap/", scripts, types.CopyToContainerOptions{})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to copy profile scripts into container: %w", err)
+		}
+	}
+
 	err = s.client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
+	// Only containers left behind by this run are removed, since one that
+	// holds a published port may be a live app of another suite.
+	if port, ok := allocatedPort(err); ok && s.runID != "" {
+		removed, removeErr := s.removeStaleContainers(ctx, resp.ID, port)
+		if removeErr != nil {
+			return "", "", removeErr
+		}
+
+		if removed {
+			fmt.Fprintf(logs, "Removed stale container holding port %d, retrying start\n", port)
+			err = s.client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
+		}
+	}
 	if err != nil {
 		return "", "", fmt.Errorf("failed to start container: %w", err)
 	}
@@ -154,30 +357,343 @@ func (s Start) Run(ctx context.Context, logs io.Writer, name, command string) (s
 		return "", "", fmt.Errorf("failed to inspect container: %w", err)
 	}
 
-	var externalURL string
-	bindings, ok := container.NetworkSettings.Ports["8080/tcp"]
-	if ok {
-		for _, binding := range bindings {
-			if binding.HostIP == "0.0.0.0" {
-				externalURL = fmt.Sprintf("http://%s:%s", binding.HostIP, binding.HostPort)
-			}
-		}
+	externalURL := publishedURL(container.NetworkSettings.Ports["8080/tcp"])
+	if s.remoteHost != "" {
+		externalURL = remoteURL(container.NetworkSettings.Ports["8080/tcp"], s.remoteHost)
 	}
 
 	var internalURL string
-	network, ok := container.NetworkSettings.Networks[InternalNetworkName]
+	network, ok := container.NetworkSettings.Networks[internalNetworkName(s.runID)]
 	if ok {
 		internalURL = fmt.Sprintf("http://%s:8080", network.IPAddress)
 	}
 
+	// Only the web process serves HTTP, so other process types are not polled.
+	polling := s.healthCheckPolling()
+	if polling != nil && externalURL != "" && s.processType == "web" {
+		err = s.waitForHealthy(ctx, resp.ID, externalURL, *polling)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
 	return externalURL, internalURL, nil
 }
 
+func (s Start) removeStaleContainers(ctx context.Context, containerID string, port uint16) (bool, error) {
+	args := filters.NewArgs(
+		filters.Arg("label", AppLabel),
+		filters.Arg("label", fmt.Sprintf("%s=%s", RunLabel, s.runID)),
+	)
+
+	containers, err := s.client.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: args,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var removed bool
+	for _, c := range containers {
+		if c.ID == containerID || c.Labels[RunLabel] != s.runID || !holdsPort(c, port) {
+			continue
+		}
+
+		err = s.client.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+		if err != nil {
+			return false, fmt.Errorf("failed to remove stale container: %w", err)
+		}
+
+		removed = true
+	}
+
+	return removed, nil
+}
+
+var allocatedPortPattern = regexp.MustCompile(`:(\d+) failed: port is already allocated`)
+
+func allocatedPort(err error) (uint16, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	matches := allocatedPortPattern.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return 0, false
+	}
+
+	port, err := strconv.ParseUint(matches[1], 10, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint16(port), true
+}
+
+func holdsPort(c types.Container, port uint16) bool {
+	for _, p := range c.Ports {
+		if p.PublicPort == port {
+			return true
+		}
+	}
+
+	return false
+}
+
+// healthCheckPolling returns how the app should be polled once it has
+// started, or nil when it should not be polled at all. A health check polls
+// with the default settings unless polling has been configured, and its
+// timeout takes precedence.
+func (s Start) healthCheckPolling() *HealthCheckPolling {
+	if s.healthCheck == nil {
+		return s.polling
+	}
+
+	if s.healthCheck.Type == "process" {
+		return nil
+	}
+
+	polling := pollingDefaults(HealthCheckPolling{})
+	if s.polling != nil {
+		polling = *s.polling
+	}
+
+	if s.healthCheck.Timeout != 0 {
+		polling.Timeout = s.healthCheck.Timeout
+	}
+
+	return &polling
+}
+
+func pollingDefaults(polling HealthCheckPolling) HealthCheckPolling {
+	if polling.Interval == 0 {
+		polling.Interval = time.Second
+	}
+
+	if polling.RequestTimeout == 0 {
+		polling.RequestTimeout = time.Second
+	}
+
+	if polling.Timeout == 0 {
+		polling.Timeout = time.Minute
+	}
+
+	return polling
+}
+
+func (s Start) waitForHealthy(ctx context.Context, containerID, url string, polling HealthCheckPolling) error {
+	client := http.Client{Timeout: polling.RequestTimeout}
+
+	var endpoint string
+	if s.healthCheck != nil && s.healthCheck.Type == "http" {
+		endpoint = s.healthCheck.Endpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url+endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health check request: %w", err)
+	}
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	onExit, onErr := s.client.ContainerWait(waitCtx, containerID, container.WaitConditionNotRunning)
+
+	timeout := s.clock.After(polling.Timeout)
+	wait := s.clock.After(polling.InitialDelay)
+
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			if lastErr == nil {
+				lastErr = ctx.Err()
+			}
+
+			return fmt.Errorf("failed to wait for app to become healthy within %s: %w", polling.Timeout, lastErr)
+		case <-timeout:
+			if lastErr == nil {
+				lastErr = context.DeadlineExceeded
+			}
+
+			return fmt.Errorf("failed to wait for app to become healthy within %s: %w", polling.Timeout, lastErr)
+		case status := <-onExit:
+			return s.exited(ctx, containerID, status)
+		case <-onErr:
+			// The container can no longer be watched, so fall back to polling
+			// until the timeout.
+			onErr = nil
+			continue
+		case <-wait:
+		}
+
+		err := s.probe(ctx, client, req)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		wait = s.clock.After(polling.Interval)
+	}
+}
+
+// probe checks the app once. Without a health check, any HTTP response
+// counts as healthy.
+func (s Start) probe(ctx context.Context, client http.Client, req *http.Request) error {
+	if s.healthCheck == nil {
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	}
+
+	if s.healthCheck.Type == "port" {
+		return probePort(ctx, client.Timeout, req.URL.Host)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", req.URL, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// probePort connects to the app's published port. Docker's userland proxy
+// accepts the connection even when nothing is listening in the container and
+// then closes it straight away, so a connection that stays open for a moment
+// is what shows that the app is listening.
+func probePort(ctx context.Context, timeout time.Duration, address string) error {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = conn.SetReadDeadline(time.Now().Add(portCheckWait))
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Read(make([]byte, 1))
+	var netErr net.Error
+	if err == nil || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return nil
+	}
+
+	return fmt.Errorf("connection to %s was closed: %w", address, err)
+}
+
+func (s Start) exited(ctx context.Context, containerID string, status container.WaitResponse) error {
+	containerLogs, err := s.client.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch container logs: %w", err)
+	}
+	defer containerLogs.Close()
+
+	output := bytes.NewBuffer(nil)
+	_, err = stdcopy.StdCopy(output, output, containerLogs)
+	if err != nil {
+		return fmt.Errorf("failed to copy container logs: %w", err)
+	}
+
+	return StartError{
+		ExitCode: status.StatusCode,
+		Logs:     output.String(),
+	}
+}
+
+func openDroplet(dir, name string) (io.ReadCloser, error) {
+	file, err := os.Open(filepath.Join(dir, fmt.Sprintf("%s.tar.zst", name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return os.Open(filepath.Join(dir, fmt.Sprintf("%s.tar.gz", name)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := zstd.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return zstdDroplet{Decoder: decoder, file: file}, nil
+}
+
+type zstdDroplet struct {
+	*zstd.Decoder
+
+	file *os.File
+}
+
+func (d zstdDroplet) Close() error {
+	d.Decoder.Close()
+	return d.file.Close()
+}
+
 func (s Start) WithStack(stack string) StartPhase {
 	s.stack = stack
 	return s
 }
 
+func (s Start) WithCustomizedStack() StartPhase {
+	s.customized = true
+	return s
+}
+
+func (s Start) WithDevMounts(mounts map[string]string) StartPhase {
+	s.devMounts = mounts
+	return s
+}
+
+func (s Start) WithUser(uid, gid int) StartPhase {
+	s.user = fmt.Sprintf("%d:%d", uid, gid)
+	return s
+}
+
+func (s Start) WithReadOnlyRootFS() StartPhase {
+	s.readOnly = true
+	return s
+}
+
+func (s Start) WithSecurityProfile(profile SecurityProfile) StartPhase {
+	s.security = &profile
+	return s
+}
+
+func (s Start) WithProfileScripts(scripts map[string]string) StartPhase {
+	s.profile = scripts
+	return s
+}
+
+func (s Start) WithProcessCommands(commands map[string]func(command string) string) StartPhase {
+	s.commands = commands
+	return s
+}
+
+func (s Start) WithSidecars(sidecars []Sidecar) StartPhase {
+	s.sidecars = sidecars
+	return s
+}
+
+func (s Start) WithProcessType(processType string) StartPhase {
+	s.processType = processType
+	return s
+}
+
 func (s Start) WithEnv(env map[string]string) StartPhase {
 	s.env = env
 	return s
@@ -187,3 +703,95 @@ func (s Start) WithServices(services map[string]map[string]interface{}) StartPha
 	s.services = services
 	return s
 }
+
+func (s Start) WithCredentials(credentials map[string]interface{}) StartPhase {
+	s.credentials = credentials
+	return s
+}
+
+func (s Start) WithCredentialService(service CredentialServiceRunner) Start {
+	s.credentialService = service
+	return s
+}
+
+func (s Start) WithHealthCheckPolling(polling HealthCheckPolling) StartPhase {
+	polling = pollingDefaults(polling)
+	s.polling = &polling
+	return s
+}
+
+func (s Start) WithHealthCheck(healthCheck HealthCheck) StartPhase {
+	s.healthCheck = &healthCheck
+	return s
+}
+
+func (s Start) WithClock(clock Clock) Start {
+	s.clock = clock
+	return s
+}
+
+func (s Start) WithHostGateway(gateway HostGateway) Start {
+	s.hostGateway = &gateway
+	return s
+}
+
+// WithRemoteHost points the external URL at the host of a remote daemon, where
+// the app's port is published.
+func (s Start) WithRemoteHost(host string) Start {
+	s.remoteHost = host
+	return s
+}
+
+func (s Start) WithRunID(runID string) Start {
+	s.runID = runID
+	return s
+}
+
+func (s Start) WithDropletVerification(key crypto.PublicKey) Start {
+	s.verifier = key
+	return s
+}
+
+func publishedURL(bindings []nat.PortBinding) string {
+	var fallback string
+	for _, binding := range bindings {
+		switch binding.HostIP {
+		case "0.0.0.0":
+			return fmt.Sprintf("http://%s:%s", binding.HostIP, binding.HostPort)
+		case "127.0.0.1":
+			fallback = fmt.Sprintf("http://%s:%s", binding.HostIP, binding.HostPort)
+		case "", "::":
+			if fallback == "" {
+				fallback = fmt.Sprintf("http://localhost:%s", binding.HostPort)
+			}
+		}
+	}
+
+	return fallback
+}
+
+func remoteURL(bindings []nat.PortBinding, host string) string {
+	for _, binding := range bindings {
+		if binding.HostPort != "" {
+			return fmt.Sprintf("http://%s", net.JoinHostPort(host, binding.HostPort))
+		}
+	}
+
+	return ""
+}
+
+// withSidecars runs the sidecars for processType in the background of the
+// launcher's shell before the process command itself.
+func withSidecars(command, processType string, sidecars []Sidecar) string {
+	var prefix string
+	for _, sidecar := range sidecars {
+		for _, t := range sidecar.ProcessTypes {
+			if t == processType {
+				prefix += fmt.Sprintf("(%s) & ", sidecar.Command)
+				break
+			}
+		}
+	}
+
+	return prefix + command
+}
@@ -0,0 +1,59 @@
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+func filterTarball(input io.Reader, output io.Writer, rename func(name string) (string, bool)) error {
+	buffered := bufio.NewReader(input)
+	magic, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read tarball: %w", err)
+	}
+
+	var reader io.Reader = buffered
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return fmt.Errorf("failed to read tarball: %w", err)
+		}
+		defer gz.Close()
+
+		reader = gz
+	}
+
+	tr := tar.NewReader(reader)
+	tw := tar.NewWriter(output)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tarball: %w", err)
+		}
+
+		name, ok := rename(hdr.Name)
+		if !ok {
+			continue
+		}
+		hdr.Name = name
+
+		err = tw.WriteHeader(hdr)
+		if err != nil {
+			return fmt.Errorf("failed to write tarball: %w", err)
+		}
+
+		_, err = io.Copy(tw, tr)
+		if err != nil {
+			return fmt.Errorf("failed to write tarball: %w", err)
+		}
+	}
+
+	return tw.Close()
+}
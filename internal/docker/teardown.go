@@ -1,34 +1,81 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 )
 
 type TeardownPhase interface {
-	Run(ctx context.Context, name string) error
+	Run(ctx context.Context, name string) (TeardownReport, error)
+	List(ctx context.Context, name string) ([]Resource, error)
+	Archive(ctx context.Context, name, dir string) error
+	Names(ctx context.Context, prefix string) ([]string, error)
 }
 
 //go:generate faux --interface TeardownClient --output fakes/teardown_client.go
 type TeardownClient interface {
+	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
+	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerLogs(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error)
+	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error)
 	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	ContainerStop(ctx context.Context, containerID string, options container.StopOptions) error
+	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
+	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+	NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error)
+	VolumeList(ctx context.Context, filter filters.Args) (volume.ListResponse, error)
+	VolumeRemove(ctx context.Context, volumeID string, force bool) error
 }
 
 //go:generate faux --interface TeardownNetworkManager --output fakes/teardown_network_manager.go
 type TeardownNetworkManager interface {
 	Delete(ctx context.Context, name string) error
+	Release(ctx context.Context, name, owner string) error
+}
+
+const CrashLogLines = 100
+
+type CrashReport struct {
+	Name       string    `json:"name"`
+	ExitCode   int       `json:"exit_code"`
+	OOMKilled  bool      `json:"oom_killed"`
+	Error      string    `json:"error,omitempty"`
+	FinishedAt time.Time `json:"finished_at"`
+	Logs       []string  `json:"logs"`
+}
+
+type TeardownPolicy struct {
+	KeepDroplets bool
+	KeepCaches   bool
 }
 
 type Teardown struct {
-	client    TeardownClient
-	networks  TeardownNetworkManager
-	workspace string
+	client      TeardownClient
+	networks    TeardownNetworkManager
+	workspace   string
+	stopTimeout time.Duration
+	journal     *Journal
+	runID       string
+	onCrash     func(CrashReport)
+	policy      TeardownPolicy
 }
 
 func NewTeardown(client TeardownClient, networks TeardownNetworkManager, workspace string) Teardown {
@@ -39,41 +86,569 @@ func NewTeardown(client TeardownClient, networks TeardownNetworkManager, workspa
 	}
 }
 
-func (t Teardown) Run(ctx context.Context, name string) error {
-	err := t.client.ContainerRemove(ctx, name, types.ContainerRemoveOptions{Force: true})
-	if err != nil && !client.IsErrNotFound(err) {
-		return fmt.Errorf("failed to remove container: %w", err)
+func (t Teardown) WithStopTimeout(timeout time.Duration) Teardown {
+	t.stopTimeout = timeout
+	return t
+}
+
+func (t Teardown) WithJournal(journal Journal) Teardown {
+	t.journal = &journal
+	return t
+}
+
+func (t Teardown) WithRunID(runID string) Teardown {
+	t.runID = runID
+	return t
+}
+
+func (t Teardown) WithCrashHandler(handler func(CrashReport)) Teardown {
+	t.onCrash = handler
+	return t
+}
+
+func (t Teardown) WithPolicy(policy TeardownPolicy) Teardown {
+	t.policy = policy
+	return t
+}
+
+func (t Teardown) Run(ctx context.Context, name string) (TeardownReport, error) {
+	var report TeardownReport
+
+	err := t.captureCrash(ctx, name)
+	if err != nil {
+		return report, err
 	}
 
-	err = t.networks.Delete(ctx, InternalNetworkName)
+	removed, err := t.removeContainer(ctx, name)
+	err = report.record(Resource{Kind: "container", Name: name, ID: name}, removed, err)
 	if err != nil {
-		return fmt.Errorf("failed to delete network: %w", err)
+		return report, err
 	}
 
-	err = os.Remove(filepath.Join(t.workspace, "droplets", fmt.Sprintf("%s.tar.gz", name)))
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("failed to delete droplet tarball: %w", err)
+	containers, err := t.client.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", AppLabel, name))),
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to list app containers: %w", err)
 	}
 
-	err = os.Remove(filepath.Join(t.workspace, "source", fmt.Sprintf("%s.tar.gz", name)))
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("failed to delete source tarball: %w", err)
+	for _, container := range containers {
+		removed, err := t.removeContainer(ctx, container.ID)
+		err = report.record(Resource{Kind: "container", Name: containerName(container.Names), ID: container.ID}, removed, err)
+		if err != nil {
+			return report, err
+		}
 	}
 
-	err = os.Remove(filepath.Join(t.workspace, "buildpacks", fmt.Sprintf("%s.tar.gz", name)))
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("failed to delete buildpack tarball: %w", err)
+	volumes, err := t.client.VolumeList(ctx, filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", AppLabel, name))))
+	if err != nil {
+		return report, fmt.Errorf("failed to list app volumes: %w", err)
+	}
+
+	for _, v := range volumes.Volumes {
+		err = t.client.VolumeRemove(ctx, v.Name, true)
+		removed, err := removalResult(err, client.IsErrNotFound)
+		if err != nil {
+			err = fmt.Errorf("failed to remove volume: %w", err)
+		}
+
+		err = report.record(Resource{Kind: "volume", Name: v.Name, ID: v.Name}, removed, err)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if !t.policy.KeepCaches {
+		images, err := t.client.ImageList(ctx, types.ImageListOptions{
+			Filters: filters.NewArgs(filters.Arg("reference", stagingImageName(name))),
+		})
+		if err != nil {
+			return report, fmt.Errorf("failed to list staging images: %w", err)
+		}
+
+		for _, image := range images {
+			_, err = t.client.ImageRemove(ctx, image.ID, types.ImageRemoveOptions{Force: true})
+			removed, err := removalResult(err, client.IsErrNotFound)
+			if err != nil {
+				err = fmt.Errorf("failed to remove staging image: %w", err)
+			}
+
+			err = report.record(Resource{Kind: "image", Name: imageName(image.RepoTags), ID: image.ID}, removed, err)
+			if err != nil {
+				return report, err
+			}
+		}
+	}
+
+	err = t.networks.Release(ctx, internalNetworkName(t.runID), name)
+	if err != nil {
+		return report, fmt.Errorf("failed to delete network: %w", err)
+	}
+
+	if !t.policy.KeepDroplets {
+		for _, extension := range []string{".tar.gz", ".tar.zst"} {
+			path := filepath.Join(t.workspace, "droplets", name+extension)
+
+			_, err = os.Lstat(path)
+			removed, err := removalResult(err, isNotExist)
+			if err == nil && removed {
+				err = removeDroplet(ctx, filepath.Dir(path), filepath.Base(path))
+			}
+			if err != nil {
+				err = fmt.Errorf("failed to delete droplet tarball: %w", err)
+			}
+
+			err = report.record(Resource{Kind: "droplet", Name: path}, removed, err)
+			if err != nil {
+				return report, err
+			}
+		}
+	}
+
+	for _, file := range []struct {
+		path        string
+		description string
+		keep        bool
+	}{
+		{path: filepath.Join(t.workspace, "source", fmt.Sprintf("%s.tar.gz", name)), description: "source tarball"},
+		{path: filepath.Join(t.workspace, "buildpacks", fmt.Sprintf("%s.tar.gz", name)), description: "buildpack tarball"},
+		{path: filepath.Join(t.workspace, "buildpacks", name), description: "buildpacks"},
+		{path: filepath.Join(t.workspace, "build-cache", fmt.Sprintf("%s.tar.gz", name)), description: "build-cache tarball", keep: t.policy.KeepCaches},
+	} {
+		if file.keep {
+			continue
+		}
+
+		_, err = os.Lstat(file.path)
+		removed, err := removalResult(err, isNotExist)
+		if err == nil && removed {
+			err = os.RemoveAll(file.path)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to delete %s: %w", file.description, err)
+		}
+
+		err = report.record(Resource{Kind: "file", Name: file.path}, removed, err)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if t.journal != nil {
+		err = t.journal.Forget(ctx, name)
+		if err != nil {
+			return report, fmt.Errorf("failed to update journal: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func (t Teardown) removeContainer(ctx context.Context, containerID string) (bool, error) {
+	if t.stopTimeout > 0 {
+		timeout := int(t.stopTimeout.Round(time.Second) / time.Second)
+		if timeout < 1 {
+			timeout = 1
+		}
+
+		err := t.client.ContainerStop(ctx, containerID, container.StopOptions{Signal: "SIGTERM", Timeout: &timeout})
+		if err != nil && !client.IsErrNotFound(err) {
+			return false, fmt.Errorf("failed to stop container: %w", err)
+		}
+	}
+
+	err := t.client.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+	removed, err := removalResult(err, client.IsErrNotFound)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove container: %w", err)
+	}
+
+	return removed, nil
+}
+
+func (t Teardown) Names(ctx context.Context, prefix string) ([]string, error) {
+	args := filters.NewArgs(filters.Arg("label", AppLabel))
+	if t.runID != "" {
+		args.Add("label", fmt.Sprintf("%s=%s", RunLabel, t.runID))
+	}
+
+	containers, err := t.client.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list app containers: %w", err)
+	}
+
+	seen := map[string]struct{}{}
+	var names []string
+	for _, c := range containers {
+		name := c.Labels[AppLabel]
+		if _, ok := seen[name]; ok || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (t Teardown) List(ctx context.Context, name string) ([]Resource, error) {
+	now := time.Now()
+
+	containers, err := t.client.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", AppLabel, name))),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list app containers: %w", err)
+	}
+
+	var resources []Resource
+	for _, container := range containers {
+		resources = append(resources, Resource{Kind: "container", Name: containerName(container.Names), ID: container.ID, Age: now.Sub(time.Unix(container.Created, 0))})
+	}
+
+	volumes, err := t.client.VolumeList(ctx, filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", AppLabel, name))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list app volumes: %w", err)
+	}
+
+	for _, v := range volumes.Volumes {
+		resources = append(resources, Resource{Kind: "volume", Name: v.Name, ID: v.Name, Age: volumeAge(v, now)})
+	}
+
+	if !t.policy.KeepCaches {
+		images, err := t.client.ImageList(ctx, types.ImageListOptions{
+			Filters: filters.NewArgs(filters.Arg("reference", stagingImageName(name))),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list staging images: %w", err)
+		}
+
+		for _, image := range images {
+			resources = append(resources, Resource{Kind: "image", Name: imageName(image.RepoTags), ID: image.ID, Age: now.Sub(time.Unix(image.Created, 0))})
+		}
+	}
+
+	networks, err := t.client.NetworkList(ctx, types.NetworkListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", internalNetworkName(t.runID))),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	for _, network := range networks {
+		if network.Name == internalNetworkName(t.runID) {
+			resources = append(resources, Resource{Kind: "network", Name: network.Name, ID: network.ID, Age: now.Sub(network.Created)})
+		}
+	}
+
+	if !t.policy.KeepDroplets {
+		for _, extension := range []string{".tar.gz", ".tar.zst"} {
+			path := filepath.Join(t.workspace, "droplets", name+extension)
+
+			resource, ok, err := fileResource(path, now)
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat droplet tarball: %w", err)
+			}
+
+			if ok {
+				resource.Kind = "droplet"
+				resource.ID, _ = dropletBlob(filepath.Dir(path), filepath.Base(path))
+				resources = append(resources, resource)
+			}
+		}
+	}
+
+	paths := []string{
+		filepath.Join(t.workspace, "source", fmt.Sprintf("%s.tar.gz", name)),
+		filepath.Join(t.workspace, "buildpacks", fmt.Sprintf("%s.tar.gz", name)),
+		filepath.Join(t.workspace, "buildpacks", name),
+	}
+	if !t.policy.KeepCaches {
+		paths = append(paths, filepath.Join(t.workspace, "build-cache", fmt.Sprintf("%s.tar.gz", name)))
+	}
+
+	for _, path := range paths {
+		resource, ok, err := fileResource(path, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat workspace file: %w", err)
+		}
+
+		if ok {
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources, nil
+}
+
+func removeDroplet(ctx context.Context, dir, filename string) error {
+	unlock, err := lockDroplets(ctx, dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	blob, err := dropletBlob(dir, filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	err = os.Remove(filepath.Join(dir, filename))
+	if err != nil {
+		return err
+	}
+
+	if blob == "" {
+		return nil
 	}
 
-	err = os.RemoveAll(filepath.Join(t.workspace, "buildpacks", name))
+	links, err := os.ReadDir(dir)
 	if err != nil {
-		return fmt.Errorf("failed to delete buildpacks: %w", err)
+		return err
+	}
+
+	for _, link := range links {
+		if link.IsDir() {
+			continue
+		}
+
+		target, err := dropletBlob(dir, link.Name())
+		if err != nil {
+			return err
+		}
+
+		if target == blob {
+			return nil
+		}
 	}
 
-	err = os.Remove(filepath.Join(t.workspace, "build-cache", fmt.Sprintf("%s.tar.gz", name)))
+	err = os.Remove(filepath.Join(dir, blob))
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("failed to delete build-cache tarball: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+func (t Teardown) Archive(ctx context.Context, name, dir string) error {
+	containers, err := t.client.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", AppLabel, name))),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list app containers: %w", err)
+	}
+
+	for _, container := range containers {
+		containerDir := filepath.Join(dir, "containers", container.ID)
+		if containerName := containerName(container.Names); containerName != "" {
+			containerDir = filepath.Join(dir, "containers", containerName)
+		}
+
+		err = os.MkdirAll(containerDir, os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("failed to create artifacts directory: %w", err)
+		}
+
+		inspect, err := t.client.ContainerInspect(ctx, container.ID)
+		if err != nil && !client.IsErrNotFound(err) {
+			return fmt.Errorf("failed to inspect container: %w", err)
+		}
+
+		content, err := json.MarshalIndent(inspect, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal container inspect output: %w", err)
+		}
+
+		err = os.WriteFile(filepath.Join(containerDir, "inspect.json"), content, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to write container inspect output: %w", err)
+		}
+
+		err = t.archiveLogs(ctx, container.ID, filepath.Join(containerDir, "output.log"))
+		if err != nil {
+			return fmt.Errorf("failed to archive container logs: %w", err)
+		}
+
+		err = t.archiveResult(ctx, container.ID, filepath.Join(containerDir, "result.json"))
+		if err != nil {
+			return fmt.Errorf("failed to archive result.json: %w", err)
+		}
+	}
+
+	for _, extension := range []string{".tar.gz", ".tar.zst"} {
+		err = copyFile(filepath.Join(t.workspace, "droplets", name+extension), filepath.Join(dir, "droplet"+extension))
+		if err != nil {
+			return fmt.Errorf("failed to archive droplet: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (t Teardown) captureCrash(ctx context.Context, name string) error {
+	info, err := t.client.ContainerInspect(ctx, name)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	if info.ContainerJSONBase == nil || info.State == nil || info.State.Running || !started(info.State.StartedAt) {
+		return nil
+	}
+
+	report := CrashReport{
+		Name:      name,
+		ExitCode:  info.State.ExitCode,
+		OOMKilled: info.State.OOMKilled,
+		Error:     info.State.Error,
+	}
+	report.FinishedAt, _ = time.Parse(time.RFC3339Nano, info.State.FinishedAt)
+
+	logs, err := t.client.ContainerLogs(ctx, name, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Tail: strconv.Itoa(CrashLogLines)})
+	if err != nil && !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to read container logs: %w", err)
+	}
+
+	if logs != nil {
+		defer logs.Close()
+
+		buffer := bytes.NewBuffer(nil)
+		_, err = stdcopy.StdCopy(buffer, buffer, logs)
+		if err != nil {
+			return fmt.Errorf("failed to read container logs: %w", err)
+		}
+
+		report.Logs = strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	}
+
+	err = os.MkdirAll(filepath.Join(t.workspace, "crashes"), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create crashes directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal crash report: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(t.workspace, "crashes", fmt.Sprintf("%s.json", name)), content, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write crash report: %w", err)
+	}
+
+	if t.onCrash != nil {
+		t.onCrash(report)
 	}
 
 	return nil
 }
+
+func started(timestamp string) bool {
+	startedAt, err := time.Parse(time.RFC3339Nano, timestamp)
+	return err == nil && !startedAt.IsZero()
+}
+
+func (t Teardown) archiveLogs(ctx context.Context, containerID, path string) error {
+	logs, err := t.client.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+	defer logs.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = stdcopy.StdCopy(file, file, logs)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func (t Teardown) archiveResult(ctx context.Context, containerID, path string) error {
+	result, _, err := t.client.CopyFromContainer(ctx, containerID, "/tmp/result.json")
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+	defer result.Close()
+
+	tr := tar.NewReader(result)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if hdr.Name != "result.json" {
+			continue
+		}
+
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.CopyN(file, tr, hdr.Size)
+		if err != nil {
+			return err
+		}
+
+		return file.Close()
+	}
+}
+
+func copyFile(source, destination string) error {
+	src, err := os.Open(source)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		return err
+	}
+
+	return dst.Close()
+}
@@ -1,16 +1,27 @@
 package docker_test
 
 import (
+	"archive/tar"
+	"bytes"
 	gocontext "context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/cloudfoundry/switchblade/internal/docker"
 	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/cloudfoundry/switchblade/internal/filelock"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/sclevine/spec"
 
 	. "github.com/onsi/gomega"
@@ -41,6 +52,8 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			Expect(err).NotTo(HaveOccurred())
 			err = os.WriteFile(filepath.Join(workspace, "droplets", "some-app.tar.gz"), []byte("some-droplet-contents"), 0600)
 			Expect(err).NotTo(HaveOccurred())
+			err = os.WriteFile(filepath.Join(workspace, "droplets", "some-app.tar.zst"), []byte("some-droplet-contents"), 0600)
+			Expect(err).NotTo(HaveOccurred())
 
 			err = os.Mkdir(filepath.Join(workspace, "source"), os.ModePerm)
 			Expect(err).NotTo(HaveOccurred())
@@ -69,18 +82,31 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 		it("stops the app and cleans up its artifacts", func() {
 			ctx := gocontext.Background()
 
-			err := teardown.Run(ctx, "some-app")
+			_, err := teardown.Run(ctx, "some-app")
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(client.ContainerRemoveCall.Receives.Ctx).To(Equal(ctx))
 			Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-app"))
 			Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{
-				Force: true,
+				Force:         true,
+				RemoveVolumes: true,
+			}))
+
+			Expect(client.ContainerListCall.Receives.Options).To(Equal(types.ContainerListOptions{
+				All:     true,
+				Filters: filters.NewArgs(filters.Arg("label", "switchblade.app=some-app")),
 			}))
 
-			Expect(networkManager.DeleteCall.Receives.Name).To(Equal("switchblade-internal"))
+			Expect(client.ImageListCall.Receives.Options).To(Equal(types.ImageListOptions{
+				Filters: filters.NewArgs(filters.Arg("reference", "switchblade-staging-some-app")),
+			}))
+			Expect(client.ImageRemoveCall.CallCount).To(Equal(0))
+
+			Expect(networkManager.ReleaseCall.Receives.Name).To(Equal("switchblade-internal"))
+			Expect(networkManager.ReleaseCall.Receives.Owner).To(Equal("some-app"))
 
 			Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(workspace, "droplets", "some-app.tar.zst")).NotTo(BeAnExistingFile())
 			Expect(filepath.Join(workspace, "source", "some-app.tar.gz")).NotTo(BeAnExistingFile())
 			Expect(filepath.Join(workspace, "buildpacks", "some-app.tar.gz")).NotTo(BeAnExistingFile())
 			Expect(filepath.Join(workspace, "buildpacks", "some-app", "some-buildpack")).NotTo(BeAnExistingFile())
@@ -88,6 +114,388 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			Expect(filepath.Join(workspace, "build-cache", "some-app.tar.gz")).NotTo(BeAnExistingFile())
 		})
 
+		it("reports what was removed", func() {
+			report, err := teardown.Run(gocontext.Background(), "some-app")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report).To(Equal(docker.TeardownReport{
+				Removed: []docker.Resource{
+					{Kind: "container", Name: "some-app", ID: "some-app"},
+					{Kind: "droplet", Name: filepath.Join(workspace, "droplets", "some-app.tar.gz")},
+					{Kind: "droplet", Name: filepath.Join(workspace, "droplets", "some-app.tar.zst")},
+					{Kind: "file", Name: filepath.Join(workspace, "source", "some-app.tar.gz")},
+					{Kind: "file", Name: filepath.Join(workspace, "buildpacks", "some-app.tar.gz")},
+					{Kind: "file", Name: filepath.Join(workspace, "buildpacks", "some-app")},
+					{Kind: "file", Name: filepath.Join(workspace, "build-cache", "some-app.tar.gz")},
+				},
+			}))
+		})
+
+		context("when some resources are already gone", func() {
+			it.Before(func() {
+				client.ContainerRemoveCall.Returns.Error = errdefs.NotFound(errors.New("no such container"))
+				Expect(os.Remove(filepath.Join(workspace, "source", "some-app.tar.gz"))).To(Succeed())
+			})
+
+			it("reports them as missing", func() {
+				report, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.Missing).To(Equal([]docker.Resource{
+					{Kind: "container", Name: "some-app", ID: "some-app"},
+					{Kind: "file", Name: filepath.Join(workspace, "source", "some-app.tar.gz")},
+				}))
+				Expect(report.Removed).To(HaveLen(5))
+				Expect(report.Failed).To(BeEmpty())
+			})
+		})
+
+		context("when a resource cannot be removed", func() {
+			it.Before(func() {
+				client.VolumeListCall.Returns.ListResponse = volume.ListResponse{
+					Volumes: []*volume.Volume{{Name: "some-volume"}},
+				}
+				client.VolumeRemoveCall.Returns.Error = errors.New("volume is in use")
+			})
+
+			it("reports the failure alongside what was already removed", func() {
+				report, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).To(MatchError("failed to remove volume: volume is in use"))
+
+				Expect(report).To(Equal(docker.TeardownReport{
+					Removed: []docker.Resource{
+						{Kind: "container", Name: "some-app", ID: "some-app"},
+					},
+					Failed: []docker.TeardownFailure{
+						{
+							Resource: docker.Resource{Kind: "volume", Name: "some-volume", ID: "some-volume"},
+							Error:    "failed to remove volume: volume is in use",
+						},
+					},
+				}))
+			})
+		})
+
+		context("List", func() {
+			it("returns the resources that would be removed without removing them", func() {
+				client.ContainerListCall.Returns.ContainerSlice = []types.Container{
+					{ID: "some-container-id", Names: []string{"/some-app"}, Created: time.Now().Add(-time.Hour).Unix()},
+				}
+				client.ImageListCall.Returns.ImageSummarySlice = []types.ImageSummary{
+					{ID: "some-image-id", RepoTags: []string{"switchblade-staging-some-app:latest"}, Created: time.Now().Add(-time.Hour).Unix()},
+				}
+				client.NetworkListCall.Returns.NetworkResourceSlice = []types.NetworkResource{
+					{ID: "some-network-id", Name: "switchblade-internal", Created: time.Now().Add(-time.Hour)},
+					{ID: "other-network-id", Name: "switchblade-internal-other"},
+				}
+
+				resources, err := teardown.List(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(resources).To(HaveLen(9))
+				Expect(resources[0]).To(Equal(docker.Resource{Kind: "container", Name: "some-app", ID: "some-container-id", Age: resources[0].Age}))
+				Expect(resources[0].Age).To(BeNumerically("~", time.Hour, time.Minute))
+				Expect(resources[1]).To(Equal(docker.Resource{Kind: "image", Name: "switchblade-staging-some-app:latest", ID: "some-image-id", Age: resources[1].Age}))
+				Expect(resources[2]).To(Equal(docker.Resource{Kind: "network", Name: "switchblade-internal", ID: "some-network-id", Age: resources[2].Age}))
+
+				var names []string
+				for _, resource := range resources[3:] {
+					names = append(names, resource.Name)
+				}
+				Expect(names).To(Equal([]string{
+					filepath.Join(workspace, "droplets", "some-app.tar.gz"),
+					filepath.Join(workspace, "droplets", "some-app.tar.zst"),
+					filepath.Join(workspace, "source", "some-app.tar.gz"),
+					filepath.Join(workspace, "buildpacks", "some-app.tar.gz"),
+					filepath.Join(workspace, "buildpacks", "some-app"),
+					filepath.Join(workspace, "build-cache", "some-app.tar.gz"),
+				}))
+				Expect(resources[3].Kind).To(Equal("droplet"))
+				Expect(resources[5].Kind).To(Equal("file"))
+
+				Expect(client.NetworkListCall.Receives.Options).To(Equal(types.NetworkListOptions{
+					Filters: filters.NewArgs(filters.Arg("name", "switchblade-internal")),
+				}))
+				Expect(client.ContainerRemoveCall.CallCount).To(Equal(0))
+				Expect(client.ImageRemoveCall.CallCount).To(Equal(0))
+				Expect(networkManager.ReleaseCall.CallCount).To(Equal(0))
+				Expect(filepath.Join(workspace, "source", "some-app.tar.gz")).To(BeAnExistingFile())
+			})
+
+			context("failure cases", func() {
+				context("when the networks cannot be listed", func() {
+					it.Before(func() {
+						client.NetworkListCall.Returns.Error = errors.New("could not list networks")
+					})
+
+					it("returns an error", func() {
+						_, err := teardown.List(gocontext.Background(), "some-app")
+						Expect(err).To(MatchError("failed to list networks: could not list networks"))
+					})
+				})
+			})
+		})
+
+		context("Names", func() {
+			it.Before(func() {
+				client.ContainerListCall.Returns.ContainerSlice = []types.Container{
+					{ID: "some-container-id", Labels: map[string]string{"switchblade.app": "nodejs_some-app"}},
+					{ID: "some-staging-container-id", Labels: map[string]string{"switchblade.app": "nodejs_some-app"}},
+					{ID: "other-container-id", Labels: map[string]string{"switchblade.app": "ruby_other-app"}},
+					{ID: "another-container-id", Labels: map[string]string{"switchblade.app": "nodejs_another-app"}},
+				}
+			})
+
+			it("returns the deployments whose names match the prefix", func() {
+				names, err := teardown.Names(gocontext.Background(), "nodejs_")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(names).To(Equal([]string{"nodejs_another-app", "nodejs_some-app"}))
+
+				Expect(client.ContainerListCall.Receives.Options).To(Equal(types.ContainerListOptions{
+					All:     true,
+					Filters: filters.NewArgs(filters.Arg("label", "switchblade.app")),
+				}))
+			})
+
+			context("failure cases", func() {
+				context("when the containers cannot be listed", func() {
+					it.Before(func() {
+						client.ContainerListCall.Returns.Error = errors.New("could not list containers")
+					})
+
+					it("returns an error", func() {
+						_, err := teardown.Names(gocontext.Background(), "nodejs_")
+						Expect(err).To(MatchError("failed to list app containers: could not list containers"))
+					})
+				})
+			})
+		})
+
+		context("Archive", func() {
+			var artifacts string
+
+			it.Before(func() {
+				var err error
+				artifacts, err = os.MkdirTemp("", "artifacts")
+				Expect(err).NotTo(HaveOccurred())
+
+				client.ContainerListCall.Returns.ContainerSlice = []types.Container{
+					{ID: "some-container-id", Names: []string{"/some-app"}},
+				}
+				client.ContainerInspectCall.Returns.ContainerJSON = types.ContainerJSON{
+					ContainerJSONBase: &types.ContainerJSONBase{ID: "some-container-id"},
+				}
+
+				logs := bytes.NewBuffer(nil)
+				_, err = stdcopy.NewStdWriter(logs, stdcopy.Stdout).Write([]byte("some-stdout\n"))
+				Expect(err).NotTo(HaveOccurred())
+				_, err = stdcopy.NewStdWriter(logs, stdcopy.Stderr).Write([]byte("some-stderr\n"))
+				Expect(err).NotTo(HaveOccurred())
+				client.ContainerLogsCall.Returns.ReadCloser = io.NopCloser(logs)
+
+				result := bytes.NewBuffer(nil)
+				tw := tar.NewWriter(result)
+				Expect(tw.WriteHeader(&tar.Header{Name: "result.json", Mode: 0600, Size: 17})).To(Succeed())
+				_, err = tw.Write([]byte(`{"processes":[]}` + "\n"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tw.Close()).To(Succeed())
+				client.CopyFromContainerCall.Returns.ReadCloser = io.NopCloser(result)
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(artifacts)).To(Succeed())
+			})
+
+			it("exports the container output, inspect output, result.json, and droplet", func() {
+				err := teardown.Archive(gocontext.Background(), "some-app", artifacts)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ContainerListCall.Receives.Options).To(Equal(types.ContainerListOptions{
+					All:     true,
+					Filters: filters.NewArgs(filters.Arg("label", "switchblade.app=some-app")),
+				}))
+				Expect(client.ContainerInspectCall.Receives.ContainerID).To(Equal("some-container-id"))
+				Expect(client.ContainerLogsCall.Receives.Options).To(Equal(types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true}))
+				Expect(client.CopyFromContainerCall.Receives.SrcPath).To(Equal("/tmp/result.json"))
+
+				content, err := os.ReadFile(filepath.Join(artifacts, "containers", "some-app", "inspect.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring(`"Id": "some-container-id"`))
+
+				content, err = os.ReadFile(filepath.Join(artifacts, "containers", "some-app", "output.log"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-stdout\nsome-stderr\n"))
+
+				content, err = os.ReadFile(filepath.Join(artifacts, "containers", "some-app", "result.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal(`{"processes":[]}` + "\n"))
+
+				content, err = os.ReadFile(filepath.Join(artifacts, "droplet.tar.gz"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-droplet-contents"))
+
+				Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).To(BeAnExistingFile())
+			})
+
+			context("when the container has no result.json", func() {
+				it.Before(func() {
+					client.CopyFromContainerCall.Returns.Error = errdefs.NotFound(errors.New("no such file"))
+				})
+
+				it("skips it", func() {
+					err := teardown.Archive(gocontext.Background(), "some-app", artifacts)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(filepath.Join(artifacts, "containers", "some-app", "result.json")).NotTo(BeAnExistingFile())
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the app containers cannot be listed", func() {
+					it.Before(func() {
+						client.ContainerListCall.Returns.Error = errors.New("could not list containers")
+					})
+
+					it("returns an error", func() {
+						err := teardown.Archive(gocontext.Background(), "some-app", artifacts)
+						Expect(err).To(MatchError("failed to list app containers: could not list containers"))
+					})
+				})
+
+				context("when the container cannot be inspected", func() {
+					it.Before(func() {
+						client.ContainerInspectCall.Returns.Error = errors.New("could not inspect container")
+					})
+
+					it("returns an error", func() {
+						err := teardown.Archive(gocontext.Background(), "some-app", artifacts)
+						Expect(err).To(MatchError("failed to inspect container: could not inspect container"))
+					})
+				})
+
+				context("when the container logs cannot be read", func() {
+					it.Before(func() {
+						client.ContainerLogsCall.Returns.Error = errors.New("could not read logs")
+					})
+
+					it("returns an error", func() {
+						err := teardown.Archive(gocontext.Background(), "some-app", artifacts)
+						Expect(err).To(MatchError("failed to archive container logs: could not read logs"))
+					})
+				})
+
+				context("when result.json cannot be copied", func() {
+					it.Before(func() {
+						client.CopyFromContainerCall.Returns.Error = errors.New("could not copy")
+					})
+
+					it("returns an error", func() {
+						err := teardown.Archive(gocontext.Background(), "some-app", artifacts)
+						Expect(err).To(MatchError("failed to archive result.json: could not copy"))
+					})
+				})
+			})
+		})
+
+		context("when the droplet is content-addressed", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(workspace, "droplets", "some-app.tar.gz"))).To(Succeed())
+				Expect(os.Mkdir(filepath.Join(workspace, "droplets", "sha256"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workspace, "droplets", "sha256", "some-digest.tar.gz"), []byte("some-droplet-contents"), 0600)).To(Succeed())
+				Expect(os.Symlink(filepath.Join("sha256", "some-digest.tar.gz"), filepath.Join(workspace, "droplets", "some-app.tar.gz"))).To(Succeed())
+			})
+
+			it("removes the link and the unreferenced droplet", func() {
+				_, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(workspace, "droplets", "sha256", "some-digest.tar.gz")).NotTo(BeAnExistingFile())
+			})
+
+			context("when another process is linking a droplet", func() {
+				var unlock func() error
+
+				it.Before(func() {
+					var err error
+					unlock, err = filelock.Lock(gocontext.Background(), filepath.Join(workspace, "droplets.lock"))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it.After(func() {
+					Expect(unlock()).To(Succeed())
+				})
+
+				it("waits for the droplets lock before removing the droplet", func() {
+					ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+					defer cancel()
+
+					_, err := teardown.Run(ctx, "some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to lock droplets")))
+					Expect(err).To(MatchError(gocontext.DeadlineExceeded))
+
+					Expect(filepath.Join(workspace, "droplets", "sha256", "some-digest.tar.gz")).To(BeAnExistingFile())
+				})
+			})
+
+			context("when another app links to the same droplet", func() {
+				it.Before(func() {
+					Expect(os.Symlink(filepath.Join("sha256", "some-digest.tar.gz"), filepath.Join(workspace, "droplets", "other-app.tar.gz"))).To(Succeed())
+				})
+
+				it("keeps the droplet", func() {
+					_, err := teardown.Run(gocontext.Background(), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+					Expect(filepath.Join(workspace, "droplets", "sha256", "some-digest.tar.gz")).To(BeAnExistingFile())
+				})
+			})
+		})
+
+		context("when the droplet could not be symlinked", func() {
+			var digest string
+
+			it.Before(func() {
+				digest = fmt.Sprintf("%x", sha256.Sum256([]byte("some-droplet-contents")))
+
+				Expect(os.Remove(filepath.Join(workspace, "droplets", "some-app.tar.gz"))).To(Succeed())
+				Expect(os.Mkdir(filepath.Join(workspace, "droplets", "sha256"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workspace, "droplets", "sha256", digest+".tar.gz"), []byte("some-droplet-contents"), 0600)).To(Succeed())
+			})
+
+			it("removes a hard link and the unreferenced droplet", func() {
+				Expect(os.Link(filepath.Join(workspace, "droplets", "sha256", digest+".tar.gz"), filepath.Join(workspace, "droplets", "some-app.tar.gz"))).To(Succeed())
+
+				_, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(workspace, "droplets", "sha256", digest+".tar.gz")).NotTo(BeAnExistingFile())
+			})
+
+			it("removes a copy and the unreferenced droplet", func() {
+				Expect(os.WriteFile(filepath.Join(workspace, "droplets", "some-app.tar.gz"), []byte("some-droplet-contents"), 0600)).To(Succeed())
+
+				_, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(workspace, "droplets", "sha256", digest+".tar.gz")).NotTo(BeAnExistingFile())
+			})
+
+			it("keeps the droplet when another app hard links to it", func() {
+				Expect(os.Link(filepath.Join(workspace, "droplets", "sha256", digest+".tar.gz"), filepath.Join(workspace, "droplets", "some-app.tar.gz"))).To(Succeed())
+				Expect(os.Link(filepath.Join(workspace, "droplets", "sha256", digest+".tar.gz"), filepath.Join(workspace, "droplets", "other-app.tar.gz"))).To(Succeed())
+
+				_, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(workspace, "droplets", "sha256", digest+".tar.gz")).To(BeAnExistingFile())
+			})
+		})
+
 		context("when the container does not exist", func() {
 			it.Before(func() {
 				client.ContainerRemoveCall.Returns.Error = errdefs.NotFound(errors.New("no such container"))
@@ -96,8 +504,390 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			it("does not error", func() {
 				ctx := gocontext.Background()
 
-				err := teardown.Run(ctx, "some-app")
+				_, err := teardown.Run(ctx, "some-app")
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("when there are other containers labelled for the app", func() {
+			it.Before(func() {
+				client.ContainerListCall.Returns.ContainerSlice = []types.Container{
+					{ID: "some-staging-container-id"},
+				}
+			})
+
+			it("removes them along with their volumes", func() {
+				_, err := teardown.Run(gocontext.Background(), "some-app")
 				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ContainerRemoveCall.CallCount).To(Equal(2))
+				Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-staging-container-id"))
+				Expect(client.ContainerRemoveCall.Receives.Options).To(Equal(types.ContainerRemoveOptions{
+					Force:         true,
+					RemoveVolumes: true,
+				}))
+			})
+		})
+
+		context("when the app container exited unexpectedly", func() {
+			var reports []docker.CrashReport
+
+			it.Before(func() {
+				reports = nil
+				client.ContainerInspectCall.Returns.ContainerJSON = types.ContainerJSON{
+					ContainerJSONBase: &types.ContainerJSONBase{
+						State: &types.ContainerState{
+							Status:     "exited",
+							ExitCode:   137,
+							OOMKilled:  true,
+							StartedAt:  "2023-01-01T00:00:00Z",
+							FinishedAt: "2023-01-01T00:05:00Z",
+						},
+					},
+				}
+
+				logs := bytes.NewBuffer(nil)
+				_, err := stdcopy.NewStdWriter(logs, stdcopy.Stdout).Write([]byte("some-output\n"))
+				Expect(err).NotTo(HaveOccurred())
+				_, err = stdcopy.NewStdWriter(logs, stdcopy.Stderr).Write([]byte("killed\n"))
+				Expect(err).NotTo(HaveOccurred())
+				client.ContainerLogsCall.Returns.ReadCloser = io.NopCloser(logs)
+
+				teardown = teardown.WithCrashHandler(func(report docker.CrashReport) {
+					reports = append(reports, report)
+				})
+			})
+
+			it("captures the crash diagnostics before removing the container", func() {
+				_, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ContainerInspectCall.Receives.ContainerID).To(Equal("some-app"))
+				Expect(client.ContainerLogsCall.Receives.ContainerID).To(Equal("some-app"))
+				Expect(client.ContainerLogsCall.Receives.Options).To(Equal(types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Tail: "100"}))
+
+				report := docker.CrashReport{
+					Name:       "some-app",
+					ExitCode:   137,
+					OOMKilled:  true,
+					FinishedAt: time.Date(2023, 1, 1, 0, 5, 0, 0, time.UTC),
+					Logs:       []string{"some-output", "killed"},
+				}
+				Expect(reports).To(Equal([]docker.CrashReport{report}))
+
+				content, err := os.ReadFile(filepath.Join(workspace, "crashes", "some-app.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(MatchJSON(`{
+					"name": "some-app",
+					"exit_code": 137,
+					"oom_killed": true,
+					"finished_at": "2023-01-01T00:05:00Z",
+					"logs": ["some-output", "killed"]
+				}`))
+
+				Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-app"))
+			})
+
+			context("when the container is still running", func() {
+				it.Before(func() {
+					client.ContainerInspectCall.Returns.ContainerJSON.State.Running = true
+				})
+
+				it("does not capture anything", func() {
+					_, err := teardown.Run(gocontext.Background(), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(reports).To(BeEmpty())
+					Expect(filepath.Join(workspace, "crashes", "some-app.json")).NotTo(BeAnExistingFile())
+				})
+			})
+
+			context("when the container never started", func() {
+				it.Before(func() {
+					client.ContainerInspectCall.Returns.ContainerJSON.State.StartedAt = "0001-01-01T00:00:00Z"
+				})
+
+				it("does not capture anything", func() {
+					_, err := teardown.Run(gocontext.Background(), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(reports).To(BeEmpty())
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the container cannot be inspected", func() {
+					it.Before(func() {
+						client.ContainerInspectCall.Returns.Error = errors.New("could not inspect container")
+					})
+
+					it("returns an error", func() {
+						_, err := teardown.Run(gocontext.Background(), "some-app")
+						Expect(err).To(MatchError("failed to inspect container: could not inspect container"))
+					})
+				})
+
+				context("when the container logs cannot be read", func() {
+					it.Before(func() {
+						client.ContainerLogsCall.Returns.Error = errors.New("could not read logs")
+					})
+
+					it("returns an error", func() {
+						_, err := teardown.Run(gocontext.Background(), "some-app")
+						Expect(err).To(MatchError("failed to read container logs: could not read logs"))
+					})
+				})
+			})
+		})
+
+		context("WithRunID", func() {
+			it.Before(func() {
+				teardown = teardown.WithRunID("some-run")
+			})
+
+			it("releases the run's network", func() {
+				_, err := teardown.Run(gocontext.Background(), "some-run-some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(networkManager.ReleaseCall.Receives.Name).To(Equal("some-run-switchblade-internal"))
+				Expect(networkManager.ReleaseCall.Receives.Owner).To(Equal("some-run-some-app"))
+			})
+		})
+
+		context("WithStopTimeout", func() {
+			var calls []string
+
+			it.Before(func() {
+				calls = nil
+				client.ContainerStopCall.Stub = func(ctx gocontext.Context, containerID string, options container.StopOptions) error {
+					calls = append(calls, "stop "+containerID)
+					return nil
+				}
+				client.ContainerRemoveCall.Stub = func(ctx gocontext.Context, containerID string, options types.ContainerRemoveOptions) error {
+					calls = append(calls, "remove "+containerID)
+					return nil
+				}
+				client.ContainerListCall.Returns.ContainerSlice = []types.Container{
+					{ID: "some-staging-container-id"},
+				}
+
+				teardown = teardown.WithStopTimeout(30 * time.Second)
+			})
+
+			it("stops each container gracefully before removing it", func() {
+				_, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(calls).To(Equal([]string{
+					"stop some-app",
+					"remove some-app",
+					"stop some-staging-container-id",
+					"remove some-staging-container-id",
+				}))
+
+				timeout := 30
+				Expect(client.ContainerStopCall.Receives.Options).To(Equal(container.StopOptions{Signal: "SIGTERM", Timeout: &timeout}))
+			})
+
+			context("when the container does not exist", func() {
+				it.Before(func() {
+					client.ContainerStopCall.Stub = nil
+					client.ContainerStopCall.Returns.Error = errdefs.NotFound(errors.New("no such container"))
+				})
+
+				it("does not error", func() {
+					_, err := teardown.Run(gocontext.Background(), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the container cannot be stopped", func() {
+					it.Before(func() {
+						client.ContainerStopCall.Stub = nil
+						client.ContainerStopCall.Returns.Error = errors.New("could not stop container")
+					})
+
+					it("returns an error", func() {
+						_, err := teardown.Run(gocontext.Background(), "some-app")
+						Expect(err).To(MatchError("failed to stop container: could not stop container"))
+					})
+				})
+			})
+		})
+
+		context("WithJournal", func() {
+			var journal docker.Journal
+
+			it.Before(func() {
+				journal = docker.NewJournal(filepath.Join(workspace, "journal.jsonl"))
+				Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "container", ID: "some-container-id", App: "some-app"})).To(Succeed())
+				Expect(journal.Record(gocontext.Background(), docker.JournalEntry{Type: "container", ID: "other-container-id", App: "other-app"})).To(Succeed())
+
+				teardown = teardown.WithJournal(journal)
+			})
+
+			it("forgets the journaled resources for the app", func() {
+				_, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				entries, err := journal.Entries(gocontext.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(Equal([]docker.JournalEntry{
+					{Type: "container", ID: "other-container-id", App: "other-app"},
+				}))
+			})
+		})
+
+		context("WithPolicy", func() {
+			it.Before(func() {
+				client.ImageListCall.Returns.ImageSummarySlice = []types.ImageSummary{
+					{ID: "some-image-id", RepoTags: []string{"switchblade-staging-some-app:latest"}},
+				}
+			})
+
+			context("when droplets are kept", func() {
+				it.Before(func() {
+					teardown = teardown.WithPolicy(docker.TeardownPolicy{KeepDroplets: true})
+				})
+
+				it("keeps the droplets and removes everything else", func() {
+					_, err := teardown.Run(gocontext.Background(), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-app"))
+					Expect(client.ImageRemoveCall.Receives.ImageID).To(Equal("some-image-id"))
+
+					Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).To(BeAnExistingFile())
+					Expect(filepath.Join(workspace, "droplets", "some-app.tar.zst")).To(BeAnExistingFile())
+					Expect(filepath.Join(workspace, "source", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+					Expect(filepath.Join(workspace, "build-cache", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+				})
+
+				it("does not list the droplets", func() {
+					resources, err := teardown.List(gocontext.Background(), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					for _, resource := range resources {
+						Expect(resource.Kind).NotTo(Equal("droplet"))
+					}
+				})
+			})
+
+			context("when caches are kept", func() {
+				it.Before(func() {
+					teardown = teardown.WithPolicy(docker.TeardownPolicy{KeepCaches: true})
+				})
+
+				it("keeps the build cache and staging images and removes everything else", func() {
+					_, err := teardown.Run(gocontext.Background(), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(client.ContainerRemoveCall.Receives.ContainerID).To(Equal("some-app"))
+					Expect(client.ImageListCall.CallCount).To(Equal(0))
+					Expect(client.ImageRemoveCall.CallCount).To(Equal(0))
+
+					Expect(filepath.Join(workspace, "build-cache", "some-app.tar.gz")).To(BeAnExistingFile())
+					Expect(filepath.Join(workspace, "droplets", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+					Expect(filepath.Join(workspace, "source", "some-app.tar.gz")).NotTo(BeAnExistingFile())
+				})
+
+				it("does not list the caches", func() {
+					resources, err := teardown.List(gocontext.Background(), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					var names []string
+					for _, resource := range resources {
+						names = append(names, resource.Name)
+					}
+
+					Expect(names).NotTo(ContainElement("switchblade-staging-some-app:latest"))
+					Expect(names).NotTo(ContainElement(filepath.Join(workspace, "build-cache", "some-app.tar.gz")))
+				})
+			})
+		})
+
+		context("when there are volumes labelled for the app", func() {
+			var removed []string
+
+			it.Before(func() {
+				removed = nil
+				client.VolumeListCall.Returns.ListResponse = volume.ListResponse{
+					Volumes: []*volume.Volume{
+						{Name: "some-volume"},
+						{Name: "other-volume"},
+					},
+				}
+				client.VolumeRemoveCall.Stub = func(ctx gocontext.Context, volumeID string, force bool) error {
+					removed = append(removed, volumeID)
+					return nil
+				}
+			})
+
+			it("removes them", func() {
+				_, err := teardown.Run(gocontext.Background(), "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.VolumeListCall.Receives.Filter).To(Equal(filters.NewArgs(filters.Arg("label", "switchblade.app=some-app"))))
+				Expect(removed).To(Equal([]string{"some-volume", "other-volume"}))
+				Expect(client.VolumeRemoveCall.Receives.Force).To(BeTrue())
+			})
+
+			context("when a volume has already been removed", func() {
+				it.Before(func() {
+					client.VolumeRemoveCall.Stub = nil
+					client.VolumeRemoveCall.Returns.Error = errdefs.NotFound(errors.New("no such volume"))
+				})
+
+				it("does not error", func() {
+					_, err := teardown.Run(gocontext.Background(), "some-app")
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the volumes cannot be listed", func() {
+					it.Before(func() {
+						client.VolumeListCall.Returns.Error = errors.New("could not list volumes")
+					})
+
+					it("returns an error", func() {
+						_, err := teardown.Run(gocontext.Background(), "some-app")
+						Expect(err).To(MatchError("failed to list app volumes: could not list volumes"))
+					})
+				})
+
+				context("when a volume cannot be removed", func() {
+					it.Before(func() {
+						client.VolumeRemoveCall.Stub = nil
+						client.VolumeRemoveCall.Returns.Error = errors.New("could not remove volume")
+					})
+
+					it("returns an error", func() {
+						_, err := teardown.Run(gocontext.Background(), "some-app")
+						Expect(err).To(MatchError("failed to remove volume: could not remove volume"))
+					})
+				})
+			})
+		})
+
+		context("when there are reusable staging images", func() {
+			it.Before(func() {
+				client.ImageListCall.Returns.ImageSummarySlice = []types.ImageSummary{
+					{ID: "some-image-id"},
+				}
+			})
+
+			it("removes them", func() {
+				ctx := gocontext.Background()
+
+				_, err := teardown.Run(ctx, "some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ImageRemoveCall.Receives.ImageID).To(Equal("some-image-id"))
+				Expect(client.ImageRemoveCall.Receives.Options).To(Equal(types.ImageRemoveOptions{
+					Force: true,
+				}))
 			})
 		})
 
@@ -109,7 +899,7 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			it("does not error", func() {
 				ctx := gocontext.Background()
 
-				err := teardown.Run(ctx, "some-app")
+				_, err := teardown.Run(ctx, "some-app")
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -122,7 +912,7 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			it("does not error", func() {
 				ctx := gocontext.Background()
 
-				err := teardown.Run(ctx, "some-app")
+				_, err := teardown.Run(ctx, "some-app")
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -135,7 +925,7 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			it("does not error", func() {
 				ctx := gocontext.Background()
 
-				err := teardown.Run(ctx, "some-app")
+				_, err := teardown.Run(ctx, "some-app")
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -148,7 +938,7 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			it("does not error", func() {
 				ctx := gocontext.Background()
 
-				err := teardown.Run(ctx, "some-app")
+				_, err := teardown.Run(ctx, "some-app")
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -161,7 +951,7 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 			it("does not error", func() {
 				ctx := gocontext.Background()
 
-				err := teardown.Run(ctx, "some-app")
+				_, err := teardown.Run(ctx, "some-app")
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -175,20 +965,80 @@ func testTeardown(t *testing.T, context spec.G, it spec.S) {
 				it("returns an error", func() {
 					ctx := gocontext.Background()
 
-					err := teardown.Run(ctx, "some-app")
+					_, err := teardown.Run(ctx, "some-app")
+					Expect(err).To(MatchError("failed to remove container: could not remove container"))
+				})
+			})
+
+			context("when the app containers cannot be listed", func() {
+				it.Before(func() {
+					client.ContainerListCall.Returns.Error = errors.New("could not list containers")
+				})
+
+				it("returns an error", func() {
+					_, err := teardown.Run(gocontext.Background(), "some-app")
+					Expect(err).To(MatchError("failed to list app containers: could not list containers"))
+				})
+			})
+
+			context("when a labelled app container cannot be removed", func() {
+				it.Before(func() {
+					client.ContainerListCall.Returns.ContainerSlice = []types.Container{
+						{ID: "some-staging-container-id"},
+					}
+					client.ContainerRemoveCall.Stub = func(ctx gocontext.Context, containerID string, options types.ContainerRemoveOptions) error {
+						if containerID == "some-staging-container-id" {
+							return errors.New("could not remove container")
+						}
+
+						return nil
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := teardown.Run(gocontext.Background(), "some-app")
 					Expect(err).To(MatchError("failed to remove container: could not remove container"))
 				})
 			})
 
+			context("when the staging images cannot be listed", func() {
+				it.Before(func() {
+					client.ImageListCall.Returns.Error = errors.New("could not list images")
+				})
+
+				it("returns an error", func() {
+					ctx := gocontext.Background()
+
+					_, err := teardown.Run(ctx, "some-app")
+					Expect(err).To(MatchError("failed to list staging images: could not list images"))
+				})
+			})
+
+			context("when a staging image cannot be removed", func() {
+				it.Before(func() {
+					client.ImageListCall.Returns.ImageSummarySlice = []types.ImageSummary{
+						{ID: "some-image-id"},
+					}
+					client.ImageRemoveCall.Returns.Error = errors.New("could not remove image")
+				})
+
+				it("returns an error", func() {
+					ctx := gocontext.Background()
+
+					_, err := teardown.Run(ctx, "some-app")
+					Expect(err).To(MatchError("failed to remove staging image: could not remove image"))
+				})
+			})
+
 			context("when the network cannot be delete", func() {
 				it.Before(func() {
-					networkManager.DeleteCall.Returns.Error = errors.New("could not delete network")
+					networkManager.ReleaseCall.Returns.Error = errors.New("could not delete network")
 				})
 
 				it("returns an error", func() {
 					ctx := gocontext.Background()
 
-					err := teardown.Run(ctx, "some-app")
+					_, err := teardown.Run(ctx, "some-app")
 					Expect(err).To(MatchError("failed to delete network: could not delete network"))
 				})
 			})
@@ -2,14 +2,17 @@ package docker
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
 	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
 )
 
 type TGZArchiver struct {
@@ -40,7 +43,11 @@ func (a TGZArchiver) Compress(input, output string) error {
 	gw := gzip.NewWriter(file)
 	defer gw.Close()
 
-	tw := tar.NewWriter(gw)
+	return a.Stream(input, gw)
+}
+
+func (a TGZArchiver) Stream(input string, output io.Writer) error {
+	tw := tar.NewWriter(output)
 	defer tw.Close()
 
 	info, err := os.Stat(input)
@@ -58,30 +65,67 @@ func (a TGZArchiver) Compress(input, output string) error {
 	}
 }
 
+func (a TGZArchiver) Digest(input string) (string, error) {
+	info, err := os.Stat(input)
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		return DirectoryDigest(input)
+	}
+
+	return FileDigest(input)
+}
+
+func (a TGZArchiver) StreamTar(input io.Reader, output io.Writer) error {
+	tw := tar.NewWriter(output)
+	defer tw.Close()
+
+	reader := bufio.NewReader(input)
+	magic, err := reader.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read tar stream: %w", err)
+	}
+
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip stream: %w", err)
+		}
+
+		return a.fromTar(tar.NewReader(zr), tw)
+	}
+
+	return a.fromTar(tar.NewReader(reader), tw)
+}
+
 func (a TGZArchiver) fromDirectory(input string, tw *tar.Writer) error {
-	err := filepath.Walk(input, func(path string, info fs.FileInfo, err error) error {
+	err := filepath.Walk(input, func(file string, info fs.FileInfo, err error) error {
 		if err != nil {
 			return fmt.Errorf("failed to walk input path: %w", err)
 		}
 
 		var link string
 		if info.Mode()&fs.ModeSymlink != 0 {
-			link, err = os.Readlink(path)
+			link, err = os.Readlink(file)
 			if err != nil {
 				return fmt.Errorf("failed to read symlink: %w", err)
 			}
 
-			if !strings.HasPrefix(link, string(filepath.Separator)) {
-				link = filepath.Clean(filepath.Join(filepath.Dir(path), link))
+			if !filepath.IsAbs(link) {
+				link = filepath.Clean(filepath.Join(filepath.Dir(file), link))
 			}
 
-			link, err = filepath.Rel(filepath.Dir(path), link)
+			link, err = filepath.Rel(filepath.Dir(file), link)
 			if err != nil {
 				return fmt.Errorf("failed to find link path relative to path: %w", err)
 			}
+
+			link = filepath.ToSlash(link)
 		}
 
-		rel, err := filepath.Rel(input, path)
+		rel, err := filepath.Rel(input, file)
 		if err != nil {
 			return fmt.Errorf("failed to find path relative to input: %w", err)
 		}
@@ -91,7 +135,8 @@ func (a TGZArchiver) fromDirectory(input string, tw *tar.Writer) error {
 			return fmt.Errorf("failed to create tar header: %w", err)
 		}
 
-		header.Name = filepath.Join(a.prefix, rel)
+		header.Name = path.Join(a.prefix, filepath.ToSlash(rel))
+		header.Mode = archiveMode(info, header.Mode)
 		header.Uid = 2000
 		header.Gid = 2000
 		header.Uname = "vcap"
@@ -103,7 +148,7 @@ func (a TGZArchiver) fromDirectory(input string, tw *tar.Writer) error {
 		}
 
 		if info.Mode().IsRegular() {
-			f, err := os.Open(path)
+			f, err := os.Open(file)
 			if err != nil {
 				return fmt.Errorf("failed to open file: %w", err)
 			}
@@ -129,13 +174,46 @@ func (a TGZArchiver) fromFile(input string, tw *tar.Writer) error {
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
 	}
+	defer file.Close()
 
-	zr, err := gzip.NewReader(file)
+	magic := make([]byte, 262)
+	n, err := io.ReadFull(file, magic)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	magic = magic[:n]
+
+	_, err = file.Seek(0, io.SeekStart)
 	if err != nil {
-		return fmt.Errorf("failed to read gzip file: %w", err)
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat file: %w", err)
+		}
+
+		zr, err := zip.NewReader(file, info.Size())
+		if err != nil {
+			return fmt.Errorf("failed to read zip file: %w", err)
+		}
+
+		return a.fromZip(zr, tw)
+	case len(magic) == 262 && string(magic[257:262]) == "ustar":
+		return a.fromTar(tar.NewReader(file), tw)
+	default:
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip file: %w", err)
+		}
+
+		return a.fromTar(tar.NewReader(zr), tw)
 	}
+}
 
-	tr := tar.NewReader(zr)
+func (a TGZArchiver) fromTar(tr *tar.Reader, tw *tar.Writer) error {
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
@@ -145,7 +223,7 @@ func (a TGZArchiver) fromFile(input string, tw *tar.Writer) error {
 			return fmt.Errorf("failed to read tar header: %w", err)
 		}
 
-		hdr.Name = filepath.Join(a.prefix, hdr.Name)
+		hdr.Name = path.Join(a.prefix, hdr.Name)
 		hdr.Uid = 2000
 		hdr.Gid = 2000
 		hdr.Uname = "vcap"
@@ -166,3 +244,65 @@ func (a TGZArchiver) fromFile(input string, tw *tar.Writer) error {
 
 	return nil
 }
+
+func (a TGZArchiver) fromZip(zr *zip.Reader, tw *tar.Writer) error {
+	for _, f := range zr.File {
+		info := f.FileInfo()
+
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			content, err := readZipFile(f)
+			if err != nil {
+				return err
+			}
+
+			link = string(content)
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return fmt.Errorf("failed to create tar header: %w", err)
+		}
+
+		hdr.Name = path.Join(a.prefix, f.Name)
+		hdr.Uid = 2000
+		hdr.Gid = 2000
+		hdr.Uname = "vcap"
+		hdr.Gname = "vcap"
+
+		err = tw.WriteHeader(hdr)
+		if err != nil {
+			return fmt.Errorf("failed to write tar header: %w", err)
+		}
+
+		if info.Mode().IsRegular() {
+			rc, err := f.Open()
+			if err != nil {
+				return fmt.Errorf("failed to open zip entry: %w", err)
+			}
+
+			_, err = io.Copy(tw, rc)
+			rc.Close()
+			if err != nil {
+				return fmt.Errorf("failed to copy file: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip entry: %w", err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip entry: %w", err)
+	}
+
+	return content, nil
+}
@@ -2,13 +2,18 @@ package docker_test
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudfoundry/switchblade/internal/docker"
 	"github.com/paketo-buildpacks/packit/v2/vacation"
@@ -139,6 +144,28 @@ func testTGZArchiver(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when streaming the archive", func() {
+			it("writes an uncompressed tarball to the given writer", func() {
+				buffer := bytes.NewBuffer(nil)
+				err := archiver.WithPrefix("/some/path").Stream(input, buffer)
+				Expect(err).NotTo(HaveOccurred())
+
+				testOutput := filepath.Join(tmpDir, "test-output")
+				Expect(os.Mkdir(testOutput, os.ModePerm)).To(Succeed())
+
+				err = vacation.NewTarArchive(buffer).Decompress(testOutput)
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(testOutput, "some", "path", "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-content"))
+
+				content, err = os.ReadFile(filepath.Join(testOutput, "some", "path", "some-dir", "other-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("other-content"))
+			})
+		})
+
 		context("failure cases", func() {
 			context("when a file in the input cannot be opened", func() {
 				it.Before(func() {
@@ -322,4 +349,226 @@ func testTGZArchiver(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 	})
+
+	context("when the path is an uncompressed tarball", func() {
+		it.Before(func() {
+			file, err := os.CreateTemp(tmpDir, "input-archive")
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+
+			tw := tar.NewWriter(file)
+			defer tw.Close()
+
+			Expect(tw.WriteHeader(&tar.Header{Name: "some-file", Mode: 0400, Size: 12, Typeflag: tar.TypeReg})).To(Succeed())
+			_, err = tw.Write([]byte("some-content"))
+			Expect(err).NotTo(HaveOccurred())
+
+			input = file.Name()
+		})
+
+		it("creates an archive of the given path", func() {
+			err := archiver.WithPrefix("/some/path").Compress(input, output)
+			Expect(err).NotTo(HaveOccurred())
+
+			testOutput := filepath.Join(tmpDir, "test-output")
+			Expect(os.Mkdir(testOutput, os.ModePerm)).To(Succeed())
+
+			file, err := os.Open(output)
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+
+			err = vacation.NewGzipArchive(file).Decompress(testOutput)
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := os.ReadFile(filepath.Join(testOutput, "some", "path", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("some-content"))
+		})
+	})
+
+	context("when the path is a zip file", func() {
+		it.Before(func() {
+			file, err := os.CreateTemp(tmpDir, "input-archive.jar")
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+
+			zw := zip.NewWriter(file)
+			defer zw.Close()
+
+			header := &zip.FileHeader{Name: "some-dir/"}
+			header.SetMode(fs.ModeDir | 0755)
+			_, err = zw.CreateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+
+			header = &zip.FileHeader{Name: "some-file", Method: zip.Deflate}
+			header.SetMode(0400)
+			w, err := zw.CreateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write([]byte("some-content"))
+			Expect(err).NotTo(HaveOccurred())
+
+			header = &zip.FileHeader{Name: "some-dir/other-file", Method: zip.Deflate}
+			header.SetMode(0600)
+			w, err = zw.CreateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write([]byte("other-content"))
+			Expect(err).NotTo(HaveOccurred())
+
+			header = &zip.FileHeader{Name: "some-dir/some-link"}
+			header.SetMode(fs.ModeSymlink | 0777)
+			w, err = zw.CreateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write([]byte("other-file"))
+			Expect(err).NotTo(HaveOccurred())
+
+			input = file.Name()
+		})
+
+		it("creates an archive of the given path", func() {
+			err := archiver.WithPrefix("/some/path").Compress(input, output)
+			Expect(err).NotTo(HaveOccurred())
+
+			testOutput := filepath.Join(tmpDir, "test-output")
+			Expect(os.Mkdir(testOutput, os.ModePerm)).To(Succeed())
+
+			file, err := os.Open(output)
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+
+			err = vacation.NewGzipArchive(file).Decompress(testOutput)
+			Expect(err).NotTo(HaveOccurred())
+
+			files, err := filepath.Glob(filepath.Join(testOutput, "some", "path", "*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf([]string{
+				filepath.Join(testOutput, "some", "path", "some-dir"),
+				filepath.Join(testOutput, "some", "path", "some-file"),
+			}))
+
+			content, err := os.ReadFile(filepath.Join(testOutput, "some", "path", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("some-content"))
+
+			info, err := os.Stat(filepath.Join(testOutput, "some", "path", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(fs.FileMode(0400)))
+
+			content, err = os.ReadFile(filepath.Join(testOutput, "some", "path", "some-dir", "other-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("other-content"))
+
+			link, err := os.Readlink(filepath.Join(testOutput, "some", "path", "some-dir", "some-link"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).To(Equal("other-file"))
+		})
+	})
+
+	context("when streaming from a tar stream", func() {
+		var stream *bytes.Buffer
+
+		it.Before(func() {
+			stream = bytes.NewBuffer(nil)
+			tw := tar.NewWriter(stream)
+			Expect(tw.WriteHeader(&tar.Header{Name: "some-file", Mode: 0400, Size: 12, Typeflag: tar.TypeReg})).To(Succeed())
+			_, err := tw.Write([]byte("some-content"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tw.Close()).To(Succeed())
+		})
+
+		it("rewrites the entries with the prefix and ownership", func() {
+			output := bytes.NewBuffer(nil)
+			err := archiver.WithPrefix("/some/path").StreamTar(stream, output)
+			Expect(err).NotTo(HaveOccurred())
+
+			tr := tar.NewReader(output)
+			hdr, err := tr.Next()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hdr.Name).To(Equal("/some/path/some-file"))
+			Expect(hdr.Uname).To(Equal("vcap"))
+
+			content, err := io.ReadAll(tr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("some-content"))
+		})
+
+		context("when the stream is gzipped", func() {
+			it.Before(func() {
+				compressed := bytes.NewBuffer(nil)
+				gw := gzip.NewWriter(compressed)
+				_, err := io.Copy(gw, stream)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gw.Close()).To(Succeed())
+
+				stream = compressed
+			})
+
+			it("decompresses the stream", func() {
+				output := bytes.NewBuffer(nil)
+				err := archiver.StreamTar(stream, output)
+				Expect(err).NotTo(HaveOccurred())
+
+				hdr, err := tar.NewReader(output).Next()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hdr.Name).To(Equal("some-file"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the stream is not a tarball", func() {
+				it("returns an error", func() {
+					err := archiver.StreamTar(strings.NewReader("not a tarball but long enough to need a header block"), bytes.NewBuffer(nil))
+					Expect(err).To(MatchError(ContainSubstring("failed to read tar header")))
+				})
+			})
+		})
+	})
+
+	context("Digest", func() {
+		it("returns a digest of the directory contents that ignores timestamps", func() {
+			digest, err := archiver.Digest(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(MatchRegexp(`^sha256:[0-9a-f]{64}$`))
+
+			later := time.Now().Add(time.Hour)
+			Expect(os.Chtimes(filepath.Join(input, "some-dir", "other-file"), later, later)).To(Succeed())
+
+			unchanged, err := archiver.Digest(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unchanged).To(Equal(digest))
+
+			Expect(os.WriteFile(filepath.Join(input, "some-dir", "other-file"), []byte("changed-content"), 0600)).To(Succeed())
+
+			changed, err := archiver.Digest(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).NotTo(Equal(digest))
+		})
+
+		context("when the path is a file", func() {
+			it("returns the digest of the file", func() {
+				digest, err := archiver.Digest(filepath.Join(input, "some-dir", "other-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(digest).To(Equal(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("other-content")))))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the path does not exist", func() {
+				it("returns an error", func() {
+					_, err := archiver.Digest(filepath.Join(tmpDir, "missing"))
+					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				})
+			})
+		})
+	})
+
+	context("when the path is not an archive", func() {
+		it.Before(func() {
+			input = filepath.Join(input, "some-dir", "other-file")
+		})
+
+		it("returns an error", func() {
+			err := archiver.Compress(input, output)
+			Expect(err).To(MatchError("failed to read gzip file: gzip: invalid header"))
+		})
+	})
 }
@@ -0,0 +1,85 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type ThrottledClient struct {
+	client.CommonAPIClient
+
+	slots chan struct{}
+}
+
+func NewThrottledClient(apiClient client.CommonAPIClient, limit int) ThrottledClient {
+	var slots chan struct{}
+	if limit > 0 {
+		slots = make(chan struct{}, limit)
+	}
+
+	return ThrottledClient{
+		CommonAPIClient: apiClient,
+		slots:           slots,
+	}
+}
+
+func (c ThrottledClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return container.CreateResponse{}, err
+	}
+	defer release()
+
+	return c.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, containerName)
+}
+
+func (c ThrottledClient) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pullLogs, err := c.CommonAPIClient.ImagePull(ctx, ref, options)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return releasingReadCloser{ReadCloser: pullLogs, release: release}, nil
+}
+
+func (c ThrottledClient) acquire(ctx context.Context) (func(), error) {
+	if c.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to wait for docker api slot: %w", ctx.Err())
+	}
+
+	once := &sync.Once{}
+	return func() {
+		once.Do(func() { <-c.slots })
+	}, nil
+}
+
+type releasingReadCloser struct {
+	io.ReadCloser
+
+	release func()
+}
+
+func (r releasingReadCloser) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
@@ -0,0 +1,175 @@
+package docker_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+type blockingAPIClient struct {
+	client.CommonAPIClient
+
+	active  *int32
+	maximum *int32
+	wait    chan struct{}
+}
+
+func (c blockingAPIClient) ContainerCreate(ctx gocontext.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error) {
+	active := atomic.AddInt32(c.active, 1)
+	defer atomic.AddInt32(c.active, -1)
+
+	for {
+		maximum := atomic.LoadInt32(c.maximum)
+		if active <= maximum || atomic.CompareAndSwapInt32(c.maximum, maximum, active) {
+			break
+		}
+	}
+
+	<-c.wait
+
+	return container.CreateResponse{ID: containerName}, nil
+}
+
+func (c blockingAPIClient) ImagePull(ctx gocontext.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	if ref == "" {
+		return nil, errors.New("invalid reference")
+	}
+
+	return io.NopCloser(bytes.NewBufferString("Pulling image...\n")), nil
+}
+
+func testThrottledClient(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect       = NewWithT(t).Expect
+		Eventually   = NewWithT(t).Eventually
+		Consistently = NewWithT(t).Consistently
+
+		apiClient blockingAPIClient
+	)
+
+	it.Before(func() {
+		apiClient = blockingAPIClient{
+			active:  new(int32),
+			maximum: new(int32),
+			wait:    make(chan struct{}),
+		}
+	})
+
+	context("ContainerCreate", func() {
+		it("limits the number of concurrent requests", func() {
+			throttledClient := docker.NewThrottledClient(apiClient, 2)
+
+			done := make(chan error)
+			for i := 0; i < 4; i++ {
+				go func() {
+					_, err := throttledClient.ContainerCreate(gocontext.Background(), nil, nil, nil, nil, "some-container")
+					done <- err
+				}()
+			}
+
+			Eventually(func() int32 { return atomic.LoadInt32(apiClient.active) }).Should(Equal(int32(2)))
+			Consistently(func() int32 { return atomic.LoadInt32(apiClient.active) }, 50*time.Millisecond).Should(Equal(int32(2)))
+
+			close(apiClient.wait)
+			for i := 0; i < 4; i++ {
+				Expect(<-done).To(Succeed())
+			}
+
+			Expect(atomic.LoadInt32(apiClient.maximum)).To(Equal(int32(2)))
+		})
+
+		context("when there is no limit", func() {
+			it("does not limit requests", func() {
+				throttledClient := docker.NewThrottledClient(apiClient, 0)
+
+				done := make(chan error)
+				for i := 0; i < 4; i++ {
+					go func() {
+						_, err := throttledClient.ContainerCreate(gocontext.Background(), nil, nil, nil, nil, "some-container")
+						done <- err
+					}()
+				}
+
+				Eventually(func() int32 { return atomic.LoadInt32(apiClient.active) }).Should(Equal(int32(4)))
+
+				close(apiClient.wait)
+				for i := 0; i < 4; i++ {
+					Expect(<-done).To(Succeed())
+				}
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the context is cancelled while waiting", func() {
+				it("returns an error", func() {
+					throttledClient := docker.NewThrottledClient(apiClient, 1)
+
+					done := make(chan error)
+					go func() {
+						_, err := throttledClient.ContainerCreate(gocontext.Background(), nil, nil, nil, nil, "some-container")
+						done <- err
+					}()
+					Eventually(func() int32 { return atomic.LoadInt32(apiClient.active) }).Should(Equal(int32(1)))
+
+					ctx, cancel := gocontext.WithCancel(gocontext.Background())
+					cancel()
+
+					_, err := throttledClient.ContainerCreate(ctx, nil, nil, nil, nil, "other-container")
+					Expect(err).To(MatchError("failed to wait for docker api slot: context canceled"))
+
+					close(apiClient.wait)
+					Expect(<-done).To(Succeed())
+				})
+			})
+		})
+	})
+
+	context("ImagePull", func() {
+		it("holds the slot until the pull logs are closed", func() {
+			throttledClient := docker.NewThrottledClient(apiClient, 1)
+
+			pullLogs, err := throttledClient.ImagePull(gocontext.Background(), "some-image", types.ImagePullOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			_, err = throttledClient.ImagePull(ctx, "other-image", types.ImagePullOptions{})
+			Expect(err).To(MatchError(ContainSubstring("failed to wait for docker api slot")))
+
+			Expect(pullLogs.Close()).To(Succeed())
+			Expect(pullLogs.Close()).To(Succeed())
+
+			pullLogs, err = throttledClient.ImagePull(gocontext.Background(), "other-image", types.ImagePullOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullLogs.Close()).To(Succeed())
+		})
+
+		context("when the pull fails", func() {
+			it("releases the slot", func() {
+				throttledClient := docker.NewThrottledClient(apiClient, 1)
+
+				_, err := throttledClient.ImagePull(gocontext.Background(), "", types.ImagePullOptions{})
+				Expect(err).To(MatchError("invalid reference"))
+
+				pullLogs, err := throttledClient.ImagePull(gocontext.Background(), "some-image", types.ImagePullOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pullLogs.Close()).To(Succeed())
+			})
+		})
+	})
+}
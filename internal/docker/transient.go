@@ -0,0 +1,41 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"syscall"
+
+	"github.com/docker/docker/errdefs"
+)
+
+// IsTransient reports whether err was caused by the Docker daemon or the
+// connection to it, rather than by the app or the buildpacks staging it. Only
+// a known set of infrastructure failures are classified as transient, so a
+// buildpack that exits with a non-zero status is never matched.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// A truncated response, such as an image pull stream that is cut off.
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	// A 500 or 503 from the daemon.
+	var system errdefs.ErrSystem
+	if errors.As(err, &system) {
+		return true
+	}
+
+	var unavailable errdefs.ErrUnavailable
+	if errors.As(err, &unavailable) {
+		return true
+	}
+
+	// A 409 from the daemon, such as two stagings racing to create the same
+	// network.
+	var conflict errdefs.ErrConflict
+	return errors.As(err, &conflict)
+}
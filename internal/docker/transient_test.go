@@ -0,0 +1,48 @@
+package docker_test
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/docker/docker/errdefs"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testTransient(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("IsTransient", func() {
+		it("matches failures in the daemon or the connection to it", func() {
+			for _, err := range []error{
+				fmt.Errorf("failed to copy image pull logs: %w", io.ErrUnexpectedEOF),
+				fmt.Errorf("failed to copy image pull logs: %w", io.EOF),
+				fmt.Errorf("failed to create container: %w", syscall.ECONNRESET),
+				fmt.Errorf("failed to start container: %w", errdefs.System(errors.New("internal server error"))),
+				fmt.Errorf("failed to pull base image: %w", errdefs.Unavailable(errors.New("service unavailable"))),
+				fmt.Errorf("failed to create network: %w", errdefs.Conflict(errors.New("network with name switchblade-internal already exists"))),
+			} {
+				Expect(docker.IsTransient(err)).To(BeTrue(), err.Error())
+			}
+		})
+
+		it("does not match anything else", func() {
+			for _, err := range []error{
+				nil,
+				errors.New("App staging failed: container exited with non-zero status code (222)"),
+				fmt.Errorf("failed to inspect stack image: %w", errdefs.NotFound(errors.New("no such image"))),
+				fmt.Errorf("failed to pull base image: %w", errdefs.Unauthorized(errors.New("unauthorized"))),
+				fmt.Errorf("failed to start container: %w", gocontext.DeadlineExceeded),
+				fmt.Errorf("failed to start container: %w", gocontext.Canceled),
+			} {
+				Expect(docker.IsTransient(err)).To(BeFalse(), fmt.Sprint(err))
+			}
+		})
+	})
+}
@@ -0,0 +1,60 @@
+//go:build !windows
+
+package filelock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const lockPollInterval = 50 * time.Millisecond
+
+// Lock takes an exclusive lock on the file at path, creating it if needed,
+// so that processes sharing a workspace can serialize work. It returns a
+// function that releases the lock.
+func Lock(ctx context.Context, path string) (func() error, error) {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	// A context that can never be done can simply block on the lock;
+	// otherwise the lock is polled so that cancellation is observed.
+	how := syscall.LOCK_EX
+	if ctx.Done() != nil {
+		how |= syscall.LOCK_NB
+	}
+
+	for {
+		err = syscall.Flock(int(file.Fd()), how)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			file.Close()
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	return func() error {
+		defer file.Close()
+		return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	}, nil
+}
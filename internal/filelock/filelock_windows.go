@@ -0,0 +1,60 @@
+package filelock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+const lockPollInterval = 50 * time.Millisecond
+
+// Lock takes an exclusive lock on the file at path, creating it if needed,
+// so that processes sharing a workspace can serialize work. It returns a
+// function that releases the lock.
+func Lock(ctx context.Context, path string) (func() error, error) {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	// A context that can never be done can simply block on the lock;
+	// otherwise the lock is polled so that cancellation is observed.
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK)
+	if ctx.Done() != nil {
+		flags |= windows.LOCKFILE_FAIL_IMMEDIATELY
+	}
+
+	overlapped := &windows.Overlapped{}
+	for {
+		err = windows.LockFileEx(windows.Handle(file.Fd()), flags, 0, 1, 0, overlapped)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+			file.Close()
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	return func() error {
+		defer file.Close()
+		return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, overlapped)
+	}, nil
+}
@@ -0,0 +1,39 @@
+package podman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// Client adapts the Docker client to the differences in Podman's
+// Docker-compatible API.
+type Client struct {
+	client.CommonAPIClient
+}
+
+func NewClient(apiClient client.CommonAPIClient) Client {
+	return Client{CommonAPIClient: apiClient}
+}
+
+func (c Client) CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+	err := c.CommonAPIClient.CopyToContainer(ctx, containerID, dstPath, content, options)
+	if err == nil || !strings.Contains(err.Error(), "lchown") {
+		return err
+	}
+
+	return fmt.Errorf("%w: podman cannot map the vcap user (uid 2000) into the container, add a subordinate id range of at least 65536 ids for your user to /etc/subuid and /etc/subgid and run `podman system migrate`", err)
+}
+
+// DistributionInspect is not served by Podman, so the error is reported as
+// such rather than as a missing image.
+func (c Client) DistributionInspect(ctx context.Context, image, encodedRegistryAuth string) (registry.DistributionInspect, error) {
+	return registry.DistributionInspect{}, errdefs.NotImplemented(errors.New("podman does not implement the distribution API"))
+}
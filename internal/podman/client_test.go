@@ -0,0 +1,80 @@
+package podman_test
+
+import (
+	gocontext "context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/podman"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+type copyingAPIClient struct {
+	client.CommonAPIClient
+
+	copyErr error
+}
+
+func (c copyingAPIClient) CopyToContainer(ctx gocontext.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+	return c.copyErr
+}
+
+func testClient(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		apiClient copyingAPIClient
+	)
+
+	it.Before(func() {
+		apiClient = copyingAPIClient{
+			copyErr: errors.New("Error response from daemon: lchown /home/vcap/app: invalid argument"),
+		}
+	})
+
+	context("CopyToContainer", func() {
+		it("explains ownership failures", func() {
+			err := podman.NewClient(apiClient).CopyToContainer(gocontext.Background(), "some-container", "/", nil, types.CopyToContainerOptions{})
+			Expect(err).To(MatchError(ContainSubstring("lchown /home/vcap/app: invalid argument: podman cannot map the vcap user (uid 2000) into the container")))
+			Expect(err).To(MatchError(ContainSubstring("/etc/subuid and /etc/subgid")))
+			Expect(err).To(MatchError(ContainSubstring("podman system migrate")))
+			Expect(errors.Is(err, apiClient.copyErr)).To(BeTrue())
+		})
+
+		context("when the copy succeeds", func() {
+			it.Before(func() {
+				apiClient.copyErr = nil
+			})
+
+			it("returns no error", func() {
+				err := podman.NewClient(apiClient).CopyToContainer(gocontext.Background(), "some-container", "/", nil, types.CopyToContainerOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("when the failure is unrelated to ownership", func() {
+			it.Before(func() {
+				apiClient.copyErr = errors.New("no such container")
+			})
+
+			it("returns the original error", func() {
+				err := podman.NewClient(apiClient).CopyToContainer(gocontext.Background(), "some-container", "/", nil, types.CopyToContainerOptions{})
+				Expect(err).To(Equal(apiClient.copyErr))
+			})
+		})
+	})
+
+	context("DistributionInspect", func() {
+		it("reports that the API is not implemented", func() {
+			_, err := podman.NewClient(apiClient).DistributionInspect(gocontext.Background(), "some-image", "")
+			Expect(err).To(MatchError("podman does not implement the distribution API"))
+			Expect(errdefs.IsNotImplemented(err)).To(BeTrue())
+		})
+	})
+}
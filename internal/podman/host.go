@@ -0,0 +1,52 @@
+package podman
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const RootfulSocket = "/run/podman/podman.sock"
+
+// Host returns the address of the Podman API service. CONTAINER_HOST is used
+// when it is set, as it is by the podman CLI. Otherwise the rootless socket of
+// the current user is preferred over the rootful one when it exists.
+func Host(getenv func(string) string) string {
+	if host := getenv("CONTAINER_HOST"); host != "" {
+		return host
+	}
+
+	if dir := getenv("XDG_RUNTIME_DIR"); dir != "" {
+		socket := filepath.Join(dir, "podman", "podman.sock")
+		if _, err := os.Stat(socket); err == nil {
+			return fmt.Sprintf("unix://%s", socket)
+		}
+	}
+
+	return fmt.Sprintf("unix://%s", RootfulSocket)
+}
+
+// Socket returns the path of the socket that the API service at host listens
+// on, so that it can be mounted into the reaper container. The path of an
+// ssh:// host is the socket on the remote machine, which is where the reaper
+// runs.
+func Socket(host string) (string, error) {
+	if strings.HasPrefix(host, "unix://") {
+		return strings.TrimPrefix(host, "unix://"), nil
+	}
+
+	if strings.HasPrefix(host, "ssh://") {
+		uri, err := url.Parse(host)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse podman host: %w", err)
+		}
+
+		if uri.Path != "" && uri.Path != "/" {
+			return uri.Path, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to determine the podman socket of %s: the reaper needs a unix:// or ssh:// host with a socket path", host)
+}
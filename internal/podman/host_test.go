@@ -0,0 +1,96 @@
+package podman_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/podman"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testHost(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		env     map[string]string
+		runtime string
+	)
+
+	it.Before(func() {
+		var err error
+		runtime, err = os.MkdirTemp("", "runtime")
+		Expect(err).NotTo(HaveOccurred())
+
+		env = map[string]string{"XDG_RUNTIME_DIR": runtime}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(runtime)).To(Succeed())
+	})
+
+	getenv := func(key string) string {
+		return env[key]
+	}
+
+	context("Host", func() {
+		it("returns the rootful socket", func() {
+			Expect(podman.Host(getenv)).To(Equal("unix:///run/podman/podman.sock"))
+		})
+
+		context("when the rootless socket exists", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(runtime, "podman"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(runtime, "podman", "podman.sock"), nil, 0600)).To(Succeed())
+			})
+
+			it("returns the rootless socket", func() {
+				Expect(podman.Host(getenv)).To(Equal("unix://" + filepath.Join(runtime, "podman", "podman.sock")))
+			})
+
+			context("when CONTAINER_HOST is set", func() {
+				it.Before(func() {
+					env["CONTAINER_HOST"] = "ssh://core@localhost:53685/run/user/501/podman/podman.sock"
+				})
+
+				it("returns it", func() {
+					Expect(podman.Host(getenv)).To(Equal("ssh://core@localhost:53685/run/user/501/podman/podman.sock"))
+				})
+			})
+		})
+	})
+
+	context("Socket", func() {
+		it("returns the path of a unix socket", func() {
+			socket, err := podman.Socket("unix:///run/user/1000/podman/podman.sock")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(socket).To(Equal("/run/user/1000/podman/podman.sock"))
+		})
+
+		context("when the host is an ssh URI", func() {
+			it("returns the path of the remote socket", func() {
+				socket, err := podman.Socket("ssh://core@localhost:53685/run/user/501/podman/podman.sock")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(socket).To(Equal("/run/user/501/podman/podman.sock"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the ssh URI has no socket path", func() {
+				it("returns an error", func() {
+					_, err := podman.Socket("ssh://core@localhost:53685")
+					Expect(err).To(MatchError("failed to determine the podman socket of ssh://core@localhost:53685: the reaper needs a unix:// or ssh:// host with a socket path"))
+				})
+			})
+
+			context("when the host is a tcp address", func() {
+				it("returns an error", func() {
+					_, err := podman.Socket("tcp://localhost:8080")
+					Expect(err).To(MatchError(ContainSubstring("failed to determine the podman socket of tcp://localhost:8080")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,19 @@
+package podman_test
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega/format"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestPodman(t *testing.T) {
+	format.MaxLength = 0
+
+	suite := spec.New("switchblade/internal/podman", spec.Report(report.Terminal{}), spec.Parallel())
+	suite("Client", testClient)
+	suite("Host", testHost)
+	suite("Runtime", testRuntime)
+	suite.Run(t)
+}
@@ -0,0 +1,26 @@
+package podman
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+)
+
+const HostName = "host.containers.internal"
+
+// DetectRuntime describes the Podman service behind client. Podman adds
+// host.containers.internal to every container's hosts file itself, so no
+// extra hosts are needed to reach the test host.
+func DetectRuntime(ctx context.Context, client docker.RuntimeClient) (docker.Runtime, error) {
+	info, err := client.Info(ctx)
+	if err != nil {
+		return docker.Runtime{}, fmt.Errorf("failed to inspect podman service: %w", err)
+	}
+
+	return docker.Runtime{
+		Name:     docker.PodmanRuntime,
+		Version:  info.ServerVersion,
+		HostName: HostName,
+	}, nil
+}
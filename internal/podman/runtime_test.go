@@ -0,0 +1,53 @@
+package podman_test
+
+import (
+	gocontext "context"
+	"errors"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/docker/fakes"
+	"github.com/cloudfoundry/switchblade/internal/podman"
+	"github.com/docker/docker/api/types"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRuntime(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		client *fakes.RuntimeClient
+	)
+
+	it.Before(func() {
+		client = &fakes.RuntimeClient{}
+		client.InfoCall.Returns.Info = types.Info{OperatingSystem: "fedora", ServerVersion: "4.9.3"}
+	})
+
+	context("DetectRuntime", func() {
+		it("describes the podman service", func() {
+			runtime, err := podman.DetectRuntime(gocontext.Background(), client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runtime).To(Equal(docker.Runtime{
+				Name:     "podman",
+				Version:  "4.9.3",
+				HostName: "host.containers.internal",
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the service cannot be inspected", func() {
+				it.Before(func() {
+					client.InfoCall.Returns.Error = errors.New("service unavailable")
+				})
+
+				it("returns an error", func() {
+					_, err := podman.DetectRuntime(gocontext.Background(), client)
+					Expect(err).To(MatchError("failed to inspect podman service: service unavailable"))
+				})
+			})
+		})
+	})
+}
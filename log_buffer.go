@@ -0,0 +1,205 @@
+package switchblade
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+func WithLogLimit(limit int) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.logs.limit = limit
+		return config
+	}
+}
+
+func withLogDirectory(dir string) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.logs.dir = dir
+		return config
+	}
+}
+
+type logBuffers struct {
+	limit int
+	dir   string
+	sinks []LogSink
+}
+
+func (l logBuffers) withWriter(w io.Writer) logBuffers {
+	if w == nil {
+		return l
+	}
+
+	l.sinks = append(append([]LogSink{}, l.sinks...), writerLogSink{w: w})
+	return l
+}
+
+func (l logBuffers) create(name string) (*logBuffer, error) {
+	dir := l.dir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	buffer := &logBuffer{
+		limit: l.limit,
+		dir:   dir,
+		name:  name,
+		tail:  bytes.NewBuffer(nil),
+		m:     &sync.Mutex{},
+	}
+
+	for _, sink := range l.sinks {
+		w, err := sink.Open(name)
+		if err != nil {
+			_ = buffer.close()
+			return nil, fmt.Errorf("failed to open log sink: %w", err)
+		}
+
+		buffer.sinks = append(buffer.sinks, w)
+	}
+
+	return buffer, nil
+}
+
+type logBuffer struct {
+	limit   int
+	dir     string
+	name    string
+	path    string
+	file    *os.File
+	tail    *bytes.Buffer
+	sinks   []io.WriteCloser
+	sinkErr error
+	tap     io.Writer
+	m       *sync.Mutex
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	for _, sink := range b.sinks {
+		_, err := sink.Write(p)
+		if err != nil && b.sinkErr == nil {
+			b.sinkErr = fmt.Errorf("failed to write to log sink: %w", err)
+		}
+	}
+
+	if b.tap != nil {
+		_, _ = b.tap.Write(p)
+	}
+
+	if b.limit <= 0 || (b.path == "" && b.tail.Len()+len(p) <= b.limit) {
+		return b.tail.Write(p)
+	}
+
+	if b.file == nil {
+		err := b.spill()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := b.file.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("failed to write log file: %w", err)
+	}
+
+	// Discarding the head of the buffer is free, and the buffer reuses that
+	// space once enough of it has been discarded, so chatty output does not
+	// copy the whole tail on every write.
+	b.tail.Write(p)
+	if b.tail.Len() > b.limit {
+		b.tail.Next(b.tail.Len() - b.limit)
+	}
+
+	return n, nil
+}
+
+// spill opens the file that output past the limit is written to, creating it
+// with the output buffered so far on the first spill. The file stays open
+// until the buffer is closed.
+func (b *logBuffer) spill() error {
+	if b.path != "" {
+		file, err := os.OpenFile(b.path, os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+
+		b.file = file
+		return nil
+	}
+
+	err := os.MkdirAll(b.dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(b.dir, fmt.Sprintf("%s-*.log", b.name))
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+
+	_, err = file.Write(b.tail.Bytes())
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write log file: %w", err)
+	}
+
+	b.path = file.Name()
+	b.file = file
+
+	return nil
+}
+
+func (b *logBuffer) record(w io.Writer) func() {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.tap = w
+
+	return func() {
+		b.m.Lock()
+		defer b.m.Unlock()
+
+		b.tap = nil
+	}
+}
+
+func (b *logBuffer) String() string {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if b.path == "" {
+		return b.tail.String()
+	}
+
+	return fmt.Sprintf("[output truncated to the last %d bytes, full output written to %s]\n%s", b.limit, b.path, b.tail.String())
+}
+
+func (b *logBuffer) close() error {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	err := b.sinkErr
+	for _, sink := range b.sinks {
+		closeErr := sink.Close()
+		if closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close log sink: %w", closeErr)
+		}
+	}
+	b.sinks = nil
+
+	if b.file != nil {
+		closeErr := b.file.Close()
+		if closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close log file: %w", closeErr)
+		}
+		b.file = nil
+	}
+
+	return err
+}
@@ -0,0 +1,156 @@
+package switchblade
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+type LogSink interface {
+	Open(name string) (io.WriteCloser, error)
+}
+
+type LogEntry struct {
+	Name    string
+	Content string
+}
+
+func WithLogSinks(sinks ...LogSink) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.logs.sinks = append(config.logs.sinks, sinks...)
+		return config
+	}
+}
+
+type fileLogSink struct {
+	dir string
+}
+
+func FileLogSink(dir string) LogSink {
+	return fileLogSink{dir: dir}
+}
+
+func (s fileLogSink) Open(name string) (io.WriteCloser, error) {
+	err := os.MkdirAll(s.dir, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log sink directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(s.dir, fmt.Sprintf("%s.log", name)), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log sink file: %w", err)
+	}
+
+	return file, nil
+}
+
+type httpLogSink struct {
+	url    string
+	client *http.Client
+}
+
+func HTTPLogSink(uri string) LogSink {
+	return httpLogSink{url: uri, client: http.DefaultClient}
+}
+
+func (s httpLogSink) Open(name string) (io.WriteCloser, error) {
+	uri, err := url.Parse(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse log sink url: %w", err)
+	}
+
+	query := uri.Query()
+	query.Set("deployment", name)
+	uri.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("POST", uri.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log sink request: %w", err)
+	}
+
+	r, w := io.Pipe()
+	req.Body = r
+	req.Header.Set("Content-Type", "text/plain")
+
+	done := make(chan error, 1)
+	go func() {
+		resp, err := s.client.Do(req)
+		if err != nil {
+			r.CloseWithError(err)
+			done <- fmt.Errorf("failed to send logs: %w", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			err = fmt.Errorf("failed to send logs: unexpected response status: %s", resp.Status)
+			r.CloseWithError(err)
+			done <- err
+			return
+		}
+
+		_, _ = io.Copy(io.Discard, r)
+		done <- nil
+	}()
+
+	return httpLogWriter{PipeWriter: w, done: done}, nil
+}
+
+type httpLogWriter struct {
+	*io.PipeWriter
+	done chan error
+}
+
+func (w httpLogWriter) Close() error {
+	err := w.PipeWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return <-w.done
+}
+
+type writerLogSink struct {
+	w io.Writer
+}
+
+func (s writerLogSink) Open(name string) (io.WriteCloser, error) {
+	return writerLogWriter{Writer: s.w}, nil
+}
+
+type writerLogWriter struct {
+	io.Writer
+}
+
+func (w writerLogWriter) Close() error {
+	return nil
+}
+
+type channelLogSink struct {
+	entries chan<- LogEntry
+}
+
+func ChannelLogSink(entries chan<- LogEntry) LogSink {
+	return channelLogSink{entries: entries}
+}
+
+func (s channelLogSink) Open(name string) (io.WriteCloser, error) {
+	return channelLogWriter{name: name, entries: s.entries}, nil
+}
+
+type channelLogWriter struct {
+	name    string
+	entries chan<- LogEntry
+}
+
+func (w channelLogWriter) Write(p []byte) (int, error) {
+	w.entries <- LogEntry{Name: w.name, Content: string(p)}
+	return len(p), nil
+}
+
+func (w channelLogWriter) Close() error {
+	return nil
+}
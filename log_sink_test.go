@@ -0,0 +1,160 @@
+package switchblade_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testLogSink(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("FileLogSink", func() {
+		var dir string
+
+		it.Before(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "log-sink")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		it("appends logs to a file per deployment", func() {
+			sink := switchblade.FileLogSink(filepath.Join(dir, "logs"))
+
+			for _, content := range []string{"first\n", "second\n"} {
+				w, err := sink.Open("some-app")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = io.WriteString(w, content)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(w.Close()).To(Succeed())
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, "logs", "some-app.log"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("first\nsecond\n"))
+		})
+
+		context("failure cases", func() {
+			context("when the directory cannot be created", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(dir, "logs"), nil, 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := switchblade.FileLogSink(filepath.Join(dir, "logs")).Open("some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to create log sink directory:")))
+				})
+			})
+		})
+	})
+
+	context("HTTPLogSink", func() {
+		var (
+			server *httptest.Server
+			status int
+
+			m        sync.Mutex
+			requests []string
+		)
+
+		it.Before(func() {
+			status = http.StatusNoContent
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				m.Lock()
+				requests = append(requests, fmt.Sprintf("%s %s %s %s", req.Method, req.URL.RequestURI(), req.Header.Get("Content-Type"), body))
+				m.Unlock()
+
+				w.WriteHeader(status)
+			}))
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("streams the logs in a request per deployment", func() {
+			w, err := switchblade.HTTPLogSink(server.URL + "/logs?source=switchblade").Open("some-app")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = io.WriteString(w, "first\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = io.WriteString(w, "second\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(w.Close()).To(Succeed())
+
+			m.Lock()
+			defer m.Unlock()
+			Expect(requests).To(Equal([]string{
+				"POST /logs?deployment=some-app&source=switchblade text/plain first\nsecond\n",
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the url cannot be parsed", func() {
+				it("returns an error", func() {
+					_, err := switchblade.HTTPLogSink("%%%").Open("some-app")
+					Expect(err).To(MatchError(ContainSubstring("failed to parse log sink url:")))
+				})
+			})
+
+			context("when the endpoint responds with an error", func() {
+				it.Before(func() {
+					status = http.StatusTeapot
+				})
+
+				it("returns an error when closed", func() {
+					w, err := switchblade.HTTPLogSink(server.URL).Open("some-app")
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _ = io.WriteString(w, "some-logs\n")
+
+					err = w.Close()
+					Expect(err).To(MatchError("failed to send logs: unexpected response status: 418 I'm a teapot"))
+				})
+			})
+		})
+	})
+
+	context("ChannelLogSink", func() {
+		it("sends each write to the channel", func() {
+			entries := make(chan switchblade.LogEntry, 2)
+
+			w, err := switchblade.ChannelLogSink(entries).Open("some-app")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = io.WriteString(w, "first\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = io.WriteString(w, "second\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(w.Close()).To(Succeed())
+
+			Expect(<-entries).To(Equal(switchblade.LogEntry{Name: "some-app", Content: "first\n"}))
+			Expect(<-entries).To(Equal(switchblade.LogEntry{Name: "some-app", Content: "second\n"}))
+		})
+	})
+}
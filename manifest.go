@@ -0,0 +1,106 @@
+package switchblade
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/switchblade/internal/docker"
+)
+
+type Manifest struct {
+	Name           string              `json:"name"`
+	Source         string              `json:"source"`
+	Stack          ManifestStack       `json:"stack"`
+	Lifecycle      ManifestLifecycle   `json:"lifecycle"`
+	Buildpacks     []ManifestBuildpack `json:"buildpacks"`
+	BuildpackOrder []string            `json:"buildpack_order"`
+	SkipDetect     bool                `json:"skip_detect"`
+	Env            map[string]string   `json:"env"`
+	Services       []string            `json:"services,omitempty"`
+	Options        []string            `json:"options,omitempty"`
+}
+
+type ManifestStack struct {
+	Name   string `json:"name"`
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+}
+
+type ManifestLifecycle struct {
+	URI    string `json:"uri"`
+	ETag   string `json:"etag,omitempty"`
+	SHA256 string `json:"sha256"`
+}
+
+type ManifestBuildpack struct {
+	Name   string `json:"name"`
+	URI    string `json:"uri"`
+	SHA256 string `json:"sha256"`
+}
+
+func (d Deployment) Manifest() (Manifest, error) {
+	if d.manifest == "" {
+		return Manifest{}, fmt.Errorf("failed to read manifest for %s: manifests are not recorded by this platform", d.Name)
+	}
+
+	m, err := docker.ReadManifest(d.manifest)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to read manifest for %s: %w", d.Name, err)
+	}
+
+	manifest := Manifest{
+		Name:           m.Name,
+		Source:         m.Source,
+		Stack:          ManifestStack(m.Stack),
+		Lifecycle:      ManifestLifecycle(m.Lifecycle),
+		BuildpackOrder: m.BuildpackOrder,
+		SkipDetect:     m.SkipDetect,
+		Env:            m.Env,
+		Services:       m.Services,
+		Options:        m.Options,
+	}
+	for _, buildpack := range m.Buildpacks {
+		manifest.Buildpacks = append(manifest.Buildpacks, ManifestBuildpack(buildpack))
+	}
+
+	return manifest, nil
+}
+
+func manifestPath(workspace, name string) string {
+	if workspace == "" {
+		return ""
+	}
+
+	return filepath.Join(workspace, "manifests", fmt.Sprintf("%s.json", name))
+}
+
+func copyManifest(path, dir string) error {
+	if path == "" {
+		return nil
+	}
+
+	input, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer input.Close()
+
+	output, err := os.Create(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	defer output.Close()
+
+	_, err = io.Copy(output, input)
+	if err != nil {
+		return fmt.Errorf("failed to copy manifest: %w", err)
+	}
+
+	return nil
+}
@@ -78,7 +78,7 @@ func (matcher *containLinesMatcher) lines(actual interface{}) []string {
 
 	var lines []string
 	for _, line := range strings.Split(raw, "\n") {
-		lines = append(lines, re.ReplaceAllString(line, ""))
+		lines = append(lines, re.ReplaceAllString(strings.TrimSuffix(line, "\r"), ""))
 	}
 
 	return lines
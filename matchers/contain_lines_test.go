@@ -211,6 +211,27 @@ func testContainLines(t *testing.T, context spec.G, it spec.S) {
 				})
 			})
 
+			context("when the actual value has CRLF line endings", func() {
+				it.Before(func() {
+					actual = strings.Join([]string{
+						"zeroth-line",
+						"first-line",
+						"second-line",
+						"some-line-content",
+						"other-line-content",
+						"another-line-content",
+						"sixth-line",
+						"seventh-line",
+					}, "\r\n")
+				})
+
+				it("returns true", func() {
+					result, err := matcher.Match(actual)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(BeTrue())
+				})
+			})
+
 			context("when the actual value does not match", func() {
 				it.Before(func() {
 					actual = strings.Join([]string{
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 
 	"github.com/cloudfoundry/switchblade"
 	"github.com/onsi/gomega/types"
@@ -75,9 +76,28 @@ func (sm *ServeMatcher) compare(actual string, expected interface{}) (bool, erro
 }
 
 func (sm *ServeMatcher) FailureMessage(actual interface{}) (message string) {
-	return fmt.Sprintf("Expected the response from deployment:\n\n\t%s\n\nto contain:\n\n\t%s", sm.response, sm.expected)
+	return fmt.Sprintf("Expected the response from deployment:\n\n\t%s\n\nto contain:\n\n\t%s%s", sm.response, sm.expected, sm.traffic(actual))
 }
 
 func (sm *ServeMatcher) NegatedFailureMessage(actual interface{}) (message string) {
-	return fmt.Sprintf("Expected the response from deployment:\n\n\t%s\n\nnot to contain:\n\n\t%s", sm.response, sm.expected)
+	return fmt.Sprintf("Expected the response from deployment:\n\n\t%s\n\nnot to contain:\n\n\t%s%s", sm.response, sm.expected, sm.traffic(actual))
+}
+
+func (sm *ServeMatcher) traffic(actual interface{}) string {
+	deployment, ok := actual.(switchblade.Deployment)
+	if !ok {
+		return ""
+	}
+
+	exchanges := deployment.Traffic()
+	if len(exchanges) == 0 {
+		return ""
+	}
+
+	var lines []string
+	for _, exchange := range exchanges {
+		lines = append(lines, exchange.String())
+	}
+
+	return fmt.Sprintf("\n\nCaptured traffic:\n\n%s", strings.Join(lines, "\n"))
 }
@@ -1,13 +1,16 @@
 package matchers_test
 
 import (
+	gocontext "context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/cloudfoundry/switchblade"
+	"github.com/cloudfoundry/switchblade/fakes"
 	"github.com/cloudfoundry/switchblade/matchers"
 	"github.com/sclevine/spec"
 
@@ -181,5 +184,31 @@ not to contain:
 	no such content`)))
 			})
 		})
+
+		context("when the deployment captured its traffic", func() {
+			it("includes the captured traffic in the failure message", func() {
+				start := &fakes.DockerStartPhase{}
+				start.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, command string) (string, string, error) {
+					return server.URL, "", nil
+				}
+
+				platform := switchblade.NewDocker(&fakes.DockerInitializePhase{}, &fakes.DockerSetupPhase{}, &fakes.DockerStagePhase{}, start, &fakes.DockerTeardownPhase{})
+				deployment, _, err := platform.Deploy.WithTrafficCapture().Execute("some-app", "/some/path/to/my/app")
+				Expect(err).NotTo(HaveOccurred())
+				defer func() {
+					Expect(platform.Delete.Execute("some-app")).To(Succeed())
+				}()
+
+				result, err := matcher.Match(deployment)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(BeFalse())
+
+				message := matcher.FailureMessage(deployment)
+				Expect(message).To(ContainSubstring("Captured traffic:"))
+				Expect(message).To(ContainSubstring(fmt.Sprintf("> GET %s/\n", server.URL)))
+				Expect(message).To(ContainSubstring("< 200 OK\n"))
+				Expect(message).To(ContainSubstring("<\n< some string\n"))
+			})
+		})
 	})
 }
@@ -0,0 +1,57 @@
+package switchblade
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+)
+
+type Metadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+type metadataReader interface {
+	Read(ctx context.Context, name string) (Metadata, error)
+}
+
+type deploymentMetadata struct {
+	name   string
+	reader metadataReader
+}
+
+func (d Deployment) Metadata() (Metadata, error) {
+	if d.metadata == nil {
+		return Metadata{}, fmt.Errorf("failed to read metadata for %s: metadata is not supported by this platform", d.Name)
+	}
+
+	metadata, err := d.metadata.reader.Read(context.Background(), d.metadata.name)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("failed to read metadata for %s: %w", d.Name, err)
+	}
+
+	return metadata, nil
+}
+
+func withMetadataReader(reader metadataReader) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.metadata = reader
+		return config
+	}
+}
+
+type cloudFoundryMetadataReader struct {
+	reader    cloudfoundry.MetadataReader
+	workspace string
+}
+
+func (r cloudFoundryMetadataReader) Read(ctx context.Context, name string) (Metadata, error) {
+	metadata, err := r.reader.Read(ctx, filepath.Join(r.workspace, name), name)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	return Metadata(metadata), nil
+}
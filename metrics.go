@@ -0,0 +1,221 @@
+package switchblade
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var MetricsBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200}
+
+type Metrics struct {
+	m          *sync.Mutex
+	deploys    map[string]int
+	failures   map[metricsKey]int
+	durations  map[metricsKey]*histogram
+	workspaces []string
+}
+
+type metricsKey struct {
+	platform string
+	phase    string
+}
+
+type histogram struct {
+	counts []int
+	count  int
+	sum    float64
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		m:         &sync.Mutex{},
+		deploys:   map[string]int{},
+		failures:  map[metricsKey]int{},
+		durations: map[metricsKey]*histogram{},
+	}
+}
+
+func WithMetrics(metrics *Metrics) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.metrics = metrics
+		config.instrumentation.hooks = append(config.instrumentation.hooks, PhaseHook{
+			Start: metrics.start,
+			Stop:  metrics.stop,
+		})
+		return config
+	}
+}
+
+func (m *Metrics) start(phase string, labels map[string]string) {
+	if phase != "setup" {
+		return
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.deploys[labels["platform"]]++
+}
+
+func (m *Metrics) stop(phase string, labels map[string]string, duration time.Duration, err error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	key := metricsKey{platform: labels["platform"], phase: phase}
+	if err != nil {
+		m.failures[key]++
+	}
+
+	h, ok := m.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]int, len(MetricsBuckets))}
+		m.durations[key] = h
+	}
+
+	seconds := duration.Seconds()
+	for i, bucket := range MetricsBuckets {
+		if seconds <= bucket {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (m *Metrics) watch(workspace string) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	for _, w := range m.workspaces {
+		if w == workspace {
+			return
+		}
+	}
+
+	m.workspaces = append(m.workspaces, workspace)
+}
+
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	buffer := bytes.NewBuffer(nil)
+
+	fmt.Fprintln(buffer, "# HELP switchblade_deploys_total Number of deployments started.")
+	fmt.Fprintln(buffer, "# TYPE switchblade_deploys_total counter")
+	var platforms []string
+	for platform := range m.deploys {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	for _, platform := range platforms {
+		fmt.Fprintf(buffer, "switchblade_deploys_total{platform=%q} %d\n", platform, m.deploys[platform])
+	}
+
+	fmt.Fprintln(buffer, "# HELP switchblade_phase_failures_total Number of phases that returned an error.")
+	fmt.Fprintln(buffer, "# TYPE switchblade_phase_failures_total counter")
+	var failures []metricsKey
+	for key := range m.failures {
+		failures = append(failures, key)
+	}
+	for _, key := range sortMetricsKeys(failures) {
+		fmt.Fprintf(buffer, "switchblade_phase_failures_total{platform=%q,phase=%q} %d\n", key.platform, key.phase, m.failures[key])
+	}
+
+	fmt.Fprintln(buffer, "# HELP switchblade_phase_duration_seconds Time spent in each phase.")
+	fmt.Fprintln(buffer, "# TYPE switchblade_phase_duration_seconds histogram")
+	var durations []metricsKey
+	for key := range m.durations {
+		durations = append(durations, key)
+	}
+	for _, key := range sortMetricsKeys(durations) {
+		h := m.durations[key]
+		for i, bucket := range MetricsBuckets {
+			fmt.Fprintf(buffer, "switchblade_phase_duration_seconds_bucket{platform=%q,phase=%q,le=%q} %d\n", key.platform, key.phase, strconv.FormatFloat(bucket, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(buffer, "switchblade_phase_duration_seconds_bucket{platform=%q,phase=%q,le=\"+Inf\"} %d\n", key.platform, key.phase, h.count)
+		fmt.Fprintf(buffer, "switchblade_phase_duration_seconds_sum{platform=%q,phase=%q} %s\n", key.platform, key.phase, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(buffer, "switchblade_phase_duration_seconds_count{platform=%q,phase=%q} %d\n", key.platform, key.phase, h.count)
+	}
+
+	fmt.Fprintln(buffer, "# HELP switchblade_workspace_bytes Size of the files in the workspace.")
+	fmt.Fprintln(buffer, "# TYPE switchblade_workspace_bytes gauge")
+	for _, workspace := range m.workspaces {
+		fmt.Fprintf(buffer, "switchblade_workspace_bytes{workspace=%q} %d\n", workspace, directorySize(workspace))
+	}
+
+	return buffer.WriteTo(w)
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = m.WriteTo(w)
+}
+
+func (m *Metrics) Push(gateway, job string) error {
+	buffer := bytes.NewBuffer(nil)
+	_, err := m.WriteTo(buffer)
+	if err != nil {
+		return fmt.Errorf("failed to render metrics: %w", err)
+	}
+
+	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/metrics/job/%s", strings.TrimSuffix(gateway, "/"), url.PathEscape(job)), buffer)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to push metrics: unexpected response status: %s", resp.Status)
+	}
+
+	return nil
+}
+
+func sortMetricsKeys(keys []metricsKey) []metricsKey {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].platform != keys[j].platform {
+			return keys[i].platform < keys[j].platform
+		}
+
+		return keys[i].phase < keys[j].phase
+	})
+
+	return keys
+}
+
+func directorySize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err == nil {
+				size += info.Size()
+			}
+		}
+
+		return nil
+	})
+
+	return size
+}
@@ -0,0 +1,135 @@
+package switchblade_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/cloudfoundry/switchblade/fakes"
+	"github.com/sclevine/spec"
+
+	. "github.com/cloudfoundry/switchblade/matchers"
+	. "github.com/onsi/gomega"
+)
+
+func testMetrics(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		metrics  *switchblade.Metrics
+		platform switchblade.Platform
+		stage    *fakes.DockerStagePhase
+	)
+
+	it.Before(func() {
+		metrics = switchblade.NewMetrics()
+		stage = &fakes.DockerStagePhase{}
+
+		platform = switchblade.NewDocker(&fakes.DockerInitializePhase{}, &fakes.DockerSetupPhase{}, stage, &fakes.DockerStartPhase{}, &fakes.DockerTeardownPhase{}, switchblade.WithMetrics(metrics))
+	})
+
+	it("records deployments, failures, and phase durations", func() {
+		_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+		Expect(err).NotTo(HaveOccurred())
+
+		stage.RunCall.Returns.Err = errors.New("stage phase errored")
+		_, _, err = platform.Deploy.Execute("other-app", "/some/path/to/my/app")
+		Expect(err).To(HaveOccurred())
+
+		buffer := bytes.NewBuffer(nil)
+		_, err = metrics.WriteTo(buffer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(buffer).To(ContainLines(
+			"# TYPE switchblade_deploys_total counter",
+			`switchblade_deploys_total{platform="docker"} 2`,
+		))
+		Expect(buffer).To(ContainLines(
+			"# TYPE switchblade_phase_failures_total counter",
+			`switchblade_phase_failures_total{platform="docker",phase="stage"} 1`,
+		))
+		Expect(buffer).To(ContainLines(
+			`switchblade_phase_duration_seconds_bucket{platform="docker",phase="stage",le="1200"} 2`,
+			`switchblade_phase_duration_seconds_bucket{platform="docker",phase="stage",le="+Inf"} 2`,
+		))
+		Expect(buffer).To(ContainLines(
+			`switchblade_phase_duration_seconds_count{platform="docker",phase="setup"} 2`,
+		))
+		Expect(buffer).To(ContainLines(
+			`switchblade_phase_duration_seconds_count{platform="docker",phase="start"} 1`,
+		))
+		Expect(buffer).To(ContainLines("# TYPE switchblade_workspace_bytes gauge"))
+	})
+
+	it("serves the metrics over HTTP", func() {
+		_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("text/plain; version=0.0.4"))
+		Expect(recorder.Body).To(ContainLines(`switchblade_deploys_total{platform="docker"} 1`))
+	})
+
+	context("Push", func() {
+		var (
+			server *httptest.Server
+			status int
+
+			method, path string
+			body         []byte
+		)
+
+		it.Before(func() {
+			status = http.StatusOK
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				method = req.Method
+				path = req.URL.Path
+				body, _ = io.ReadAll(req.Body)
+				w.WriteHeader(status)
+			}))
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("pushes the metrics to a gateway", func() {
+			_, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+			Expect(err).NotTo(HaveOccurred())
+
+			err = metrics.Push(server.URL+"/", "buildpack-canary")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(method).To(Equal("PUT"))
+			Expect(path).To(Equal("/metrics/job/buildpack-canary"))
+			Expect(string(body)).To(ContainSubstring(`switchblade_deploys_total{platform="docker"} 1`))
+		})
+
+		context("failure cases", func() {
+			context("when the gateway responds with an error", func() {
+				it.Before(func() {
+					status = http.StatusBadRequest
+				})
+
+				it("returns an error", func() {
+					err := metrics.Push(server.URL, "buildpack-canary")
+					Expect(err).To(MatchError("failed to push metrics: unexpected response status: 400 Bad Request"))
+				})
+			})
+
+			context("when the gateway cannot be reached", func() {
+				it("returns an error", func() {
+					err := metrics.Push("http://127.0.0.1:0", "buildpack-canary")
+					Expect(err).To(MatchError(ContainSubstring("failed to push metrics:")))
+				})
+			})
+		})
+	})
+}
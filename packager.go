@@ -0,0 +1,81 @@
+package switchblade
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+var packagedBuildpackPattern = regexp.MustCompile(`buildpack created and saved as (\S+\.zip)`)
+
+func PackageBuildpack(path string, cached bool) (Buildpack, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return Buildpack{}, err
+	}
+
+	language, err := buildpackLanguage(filepath.Join(path, "manifest.yml"))
+	if err != nil {
+		return Buildpack{}, err
+	}
+
+	logs := bytes.NewBuffer(nil)
+	err = pexec.NewExecutable("buildpack-packager").Execute(pexec.Execution{
+		Args:   []string{"build", fmt.Sprintf("-cached=%t", cached), "-any-stack"},
+		Dir:    path,
+		Stdout: logs,
+		Stderr: logs,
+	})
+	if err != nil {
+		return Buildpack{}, fmt.Errorf("failed to package buildpack: %w\n\nOutput:\n%s", err, logs)
+	}
+
+	matches := packagedBuildpackPattern.FindStringSubmatch(logs.String())
+	if matches == nil {
+		return Buildpack{}, fmt.Errorf("failed to find packaged buildpack in output:\n%s", logs)
+	}
+
+	uri := matches[1]
+	if !filepath.IsAbs(uri) {
+		uri = filepath.Join(path, uri)
+	}
+
+	return Buildpack{
+		Name: fmt.Sprintf("%s_buildpack", language),
+		URI:  uri,
+	}, nil
+}
+
+func buildpackLanguage(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open buildpack manifest: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || key != "language" {
+			continue
+		}
+
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if value != "" {
+			return value, nil
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return "", fmt.Errorf("failed to read buildpack manifest: %w", err)
+	}
+
+	return "", fmt.Errorf("failed to find language in buildpack manifest %s", path)
+}
@@ -0,0 +1,114 @@
+package switchblade_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testPackageBuildpack(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		bin    string
+		source string
+		path   string
+	)
+
+	it.Before(func() {
+		var err error
+		bin, err = os.MkdirTemp("", "bin")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = os.WriteFile(filepath.Join(bin, "buildpack-packager"), []byte(`#!/usr/bin/env bash
+set -eu
+echo "$@" > args
+if [[ -f fail ]]; then
+  echo "something bad happened"
+  exit 1
+fi
+echo "Cached buildpack created and saved as some_buildpack-cached-v1.2.3.zip with a size of 12MB"
+`), 0700)
+		Expect(err).NotTo(HaveOccurred())
+
+		path = os.Getenv("PATH")
+		Expect(os.Setenv("PATH", fmt.Sprintf("%s%c%s", bin, os.PathListSeparator, path))).To(Succeed())
+
+		source, err = os.MkdirTemp("", "source")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = os.WriteFile(filepath.Join(source, "manifest.yml"), []byte("---\nlanguage: some\ndefault_versions: []\n"), 0600)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.Setenv("PATH", path)).To(Succeed())
+		Expect(os.RemoveAll(bin)).To(Succeed())
+		Expect(os.RemoveAll(source)).To(Succeed())
+	})
+
+	it("packages the buildpack and returns its name and zip", func() {
+		buildpack, err := switchblade.PackageBuildpack(source, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buildpack).To(Equal(switchblade.Buildpack{
+			Name: "some_buildpack",
+			URI:  filepath.Join(source, "some_buildpack-cached-v1.2.3.zip"),
+		}))
+
+		args, err := os.ReadFile(filepath.Join(source, "args"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(args)).To(Equal("build -cached=true -any-stack\n"))
+	})
+
+	context("when the buildpack is uncached", func() {
+		it("passes the flag to the packager", func() {
+			_, err := switchblade.PackageBuildpack(source, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			args, err := os.ReadFile(filepath.Join(source, "args"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(args)).To(Equal("build -cached=false -any-stack\n"))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the manifest does not exist", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(source, "manifest.yml"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := switchblade.PackageBuildpack(source, true)
+				Expect(err).To(MatchError(ContainSubstring("failed to open buildpack manifest")))
+			})
+		})
+
+		context("when the manifest does not specify a language", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(source, "manifest.yml"), []byte("---\ndefault_versions: []\n"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := switchblade.PackageBuildpack(source, true)
+				Expect(err).To(MatchError(fmt.Sprintf("failed to find language in buildpack manifest %s", filepath.Join(source, "manifest.yml"))))
+			})
+		})
+
+		context("when the packager fails", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(source, "fail"), nil, 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := switchblade.PackageBuildpack(source, true)
+				Expect(err).To(MatchError(ContainSubstring("failed to package buildpack: exit status 1\n\nOutput:\nsomething bad happened\n")))
+			})
+		})
+	})
+}
@@ -1,12 +1,18 @@
 package switchblade
 
 import (
+	"context"
+	"crypto"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
 	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/cloudfoundry/switchblade/internal/podman"
 	"github.com/docker/docker/client"
 	"github.com/paketo-buildpacks/packit/v2/pexec"
 )
@@ -18,8 +24,69 @@ type Buildpack struct {
 
 type Service map[string]interface{}
 
+type Sidecar struct {
+	Name         string
+	Command      string
+	Memory       string
+	ProcessTypes []string
+}
+
+type HealthCheckPolling struct {
+	InitialDelay   time.Duration
+	Interval       time.Duration
+	RequestTimeout time.Duration
+	Timeout        time.Duration
+}
+
+type SecurityProfile struct {
+	Seccomp  string
+	AppArmor string
+	CapAdd   []string
+	CapDrop  []string
+}
+
+type Resource struct {
+	Kind string
+	Name string
+	ID   string
+	Age  time.Duration
+}
+
+type TeardownFailure struct {
+	Resource Resource
+	Error    string
+}
+
+type TeardownReport struct {
+	Removed []Resource
+	Missing []Resource
+	Failed  []TeardownFailure
+}
+
+type CrashReport struct {
+	Name       string
+	ExitCode   int
+	OOMKilled  bool
+	Error      string
+	FinishedAt time.Time
+	Logs       []string
+}
+
+type TeardownPolicy struct {
+	KeepDroplets bool
+	KeepCaches   bool
+}
+
 type Platform struct {
-	initialize initializeProcess
+	initialize  initializeProcess
+	close       closeProcess
+	gc          gcProcess
+	recovery    recoverProcess
+	interrupt   interruptProcess
+	preflight   preflightProcess
+	cleanup     *cleanupTracker
+	deployments *deploymentTracker
+	runID       string
 
 	Deploy DeployProcess
 	Delete DeleteProcess
@@ -31,24 +98,345 @@ type DeployProcess interface {
 	WithEnv(env map[string]string) DeployProcess
 	WithoutInternetAccess() DeployProcess
 	WithServices(map[string]Service) DeployProcess
+	WithCredentials(credentials map[string]interface{}) DeployProcess
+	WithStagingContainerReuse() DeployProcess
+	WithStackSetup(setup func(exec Execer) error) DeployProcess
+	WithTrafficCapture() DeployProcess
+	WithDevMode(paths ...string) DeployProcess
+	WithUser(uid, gid int) DeployProcess
+	WithReadOnlyRootFS() DeployProcess
+	WithSecurityProfile(profile SecurityProfile) DeployProcess
+	WithHealthCheckPolling(polling HealthCheckPolling) DeployProcess
+	WithHealthCheck(healthCheckType, endpoint string, timeout time.Duration) DeployProcess
+	WithSidecars(sidecars ...Sidecar) DeployProcess
+	WithProfileScript(name, contents string) DeployProcess
+	WithProcessCommand(processType string, command func(original string) string) DeployProcess
+	WithProcessType(processType string) DeployProcess
+	WithMetadata(metadata Metadata) DeployProcess
+	WithAppFeature(name string, enabled bool) DeployProcess
+	WithManifest(path string) DeployProcess
+	WithManifestVars(vars map[string]string, varsFiles ...string) DeployProcess
+	WithRuntimeLogWriter(w io.Writer) DeployProcess
 
 	Execute(name, path string) (Deployment, fmt.Stringer, error)
+	ExecuteFromReader(ctx context.Context, logs io.Writer, name string, bits io.Reader) (Deployment, error)
 }
 
 type DeleteProcess interface {
 	Execute(name string) error
+	ExecuteWithReport(name string) (TeardownReport, error)
+	DryRun(name string) ([]Resource, error)
+	ByPrefix(ctx context.Context, prefix string) error
 }
 
 type initializeProcess interface {
 	Execute(buildpacks ...Buildpack) error
 }
 
+type closeProcess interface {
+	Execute() error
+}
+
+type gcProcess interface {
+	Execute(ctx context.Context, olderThan time.Duration) error
+	DryRun(ctx context.Context, olderThan time.Duration) ([]Resource, error)
+}
+
+type recoverProcess interface {
+	Execute(ctx context.Context) error
+}
+
+type interruptProcess interface {
+	Execute(ctx context.Context) error
+}
+
+type PlatformOption func(platformConfig) platformConfig
+
+type platformConfig struct {
+	stagingPoolSize  int
+	stagingLimit     int
+	stagingRetries   int
+	dockerAPILimit   int
+	apiDebugLog      io.Writer
+	lifecycleURI     string
+	lifecycleVersion string
+	zstdDroplets     bool
+	dropletCache     bool
+	dropletKey       crypto.Signer
+	stopTimeout      time.Duration
+	asyncTeardown    bool
+	instrumentation  instrumentation
+	logs             logBuffers
+	deployments      *deploymentTracker
+	artifactsDir     string
+	artifacts        *artifactTracker
+	runID            string
+	crashHandler     func(CrashReport)
+	teardownPolicy   TeardownPolicy
+	uploadMissing    bool
+	registryAuth     registryAuth
+	target           CloudFoundryTarget
+	droplets         dropletPusher
+	scaler           instanceScaler
+	metadata         metadataReader
+	features         appFeatureReader
+	stagedBuildpacks stagedBuildpackReader
+	controller       containerController
+	snapshotter      environmentSnapshotter
+	traffic          *trafficProxies
+	runtimeLogs      runtimeLogStreams
+	clock            Clock
+	cassette         cassette
+	reaper           bool
+	sbom             bool
+	hostServices     bool
+	scanner          DropletScanner
+	scanImages       bool
+	workspace        string
+	workspaceRoot    string
+	metrics          *Metrics
+	events           *eventStream
+}
+
+type cassette struct {
+	path   string
+	replay bool
+}
+
+type registryAuth struct {
+	username string
+	password string
+}
+
+// CloudFoundryTarget describes the foundation, org, and space that a Cloud
+// Foundry platform deploys to.
+type CloudFoundryTarget struct {
+	API               string
+	Username          string
+	Password          string
+	Org               string
+	Space             string
+	SkipSSLValidation bool
+}
+
+type dropletPusher interface {
+	Push(ctx context.Context, logs io.Writer, name, ref string) error
+	Snapshot(ctx context.Context, name, ref string) error
+}
+
+type containerController interface {
+	Kill(ctx context.Context, name, signal string) error
+	Restart(ctx context.Context, name string) error
+}
+
+func newPlatformConfig(options []PlatformOption) platformConfig {
+	config := platformConfig{deployments: &deploymentTracker{names: map[string]struct{}{}}, traffic: newTrafficProxies(), runtimeLogs: newRuntimeLogStreams()}
+	for _, option := range options {
+		config = option(config)
+	}
+	config.artifacts = &artifactTracker{dir: config.artifactsDir, failures: map[string]fmt.Stringer{}}
+
+	return config
+}
+
+func WithStagingPool(size int) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.stagingPoolSize = size
+		return config
+	}
+}
+
+func WithStagingLimit(limit int) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.stagingLimit = limit
+		return config
+	}
+}
+
+func WithStagingRetries(retries int) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.stagingRetries = retries
+		return config
+	}
+}
+
+func WithDockerAPILimit(limit int) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.dockerAPILimit = limit
+		return config
+	}
+}
+
+func WithAPIDebugLog(w io.Writer) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.apiDebugLog = w
+		return config
+	}
+}
+
+func WithPrebuiltLifecycle(uri, version string) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.lifecycleURI = uri
+		config.lifecycleVersion = version
+		return config
+	}
+}
+
+func WithZstdDroplets() PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.zstdDroplets = true
+		return config
+	}
+}
+
+func WithDropletCache() PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.dropletCache = true
+		return config
+	}
+}
+
+func WithGracefulStop(timeout time.Duration) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.stopTimeout = timeout
+		return config
+	}
+}
+
+func WithRunID(id string) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.runID = id
+		return config
+	}
+}
+
+func WithCrashHandler(handler func(CrashReport)) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.crashHandler = handler
+		return config
+	}
+}
+
+func WithTeardownPolicy(policy TeardownPolicy) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.teardownPolicy = policy
+		return config
+	}
+}
+
+func WithMissingBuildpackUpload() PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.uploadMissing = true
+		return config
+	}
+}
+
+func WithRegistryAuth(username, password string) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.registryAuth = registryAuth{username: username, password: password}
+		return config
+	}
+}
+
+func WithCloudFoundryTarget(target CloudFoundryTarget) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.target = target
+		return config
+	}
+}
+
+func WithCassetteRecording(path string) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.cassette = cassette{path: path}
+		return config
+	}
+}
+
+func WithCassetteReplay(path string) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.cassette = cassette{path: path, replay: true}
+		return config
+	}
+}
+
+func WithReaper() PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.reaper = true
+		return config
+	}
+}
+
+func WithSBOM() PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.sbom = true
+		return config
+	}
+}
+
+func WithHostServices() PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.hostServices = true
+		return config
+	}
+}
+
+func WithWorkspace(dir string) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.workspaceRoot = dir
+		return config
+	}
+}
+
+func withRunWorkspace(workspace string) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.workspace = workspace
+		return config
+	}
+}
+
+func withDropletPusher(pusher dropletPusher) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.droplets = pusher
+		return config
+	}
+}
+
+func withContainerController(controller containerController) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.controller = controller
+		return config
+	}
+}
+
+func withEnvironmentSnapshotter(snapshotter environmentSnapshotter) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.snapshotter = snapshotter
+		return config
+	}
+}
+
+func withScaler(scaler instanceScaler) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.scaler = scaler
+		return config
+	}
+}
+
 const (
 	CloudFoundry = "cf"
 	Docker       = "docker"
+	Podman       = "podman"
+)
+
+const (
+	HealthCheckPort    = "port"
+	HealthCheckHTTP    = "http"
+	HealthCheckProcess = "process"
 )
 
-func NewPlatform(platformType, token, stack string) (Platform, error) {
+func NewPlatform(platformType, token, stack string, options ...PlatformOption) (Platform, error) {
+	config := newPlatformConfig(options)
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return Platform{}, err
@@ -56,42 +444,310 @@ func NewPlatform(platformType, token, stack string) (Platform, error) {
 
 	switch platformType {
 	case CloudFoundry:
-		cli := pexec.NewExecutable("cf")
+		var cli cloudfoundry.Executable = cloudfoundry.NewCommand("cf")
+		if config.cassette.path != "" {
+			if config.cassette.replay {
+				cli, err = cloudfoundry.NewReplayingExecutable(config.cassette.path)
+				if err != nil {
+					return Platform{}, err
+				}
+			} else {
+				cli = cloudfoundry.NewRecordingExecutable(cli, config.cassette.path)
+			}
+		}
+
+		workspace, err := resolveWorkspace(config.workspaceRoot, os.TempDir())
+		if err != nil {
+			return Platform{}, err
+		}
+
+		cfHome := filepath.Join(home, ".cf")
+		if config.target.API != "" {
+			dir, err := os.MkdirTemp(workspace, "cf-home-")
+			if err != nil {
+				return Platform{}, fmt.Errorf("failed to create $CF_HOME: %w", err)
+			}
 
-		initialize := cloudfoundry.NewInitialize(cli)
-		setup := cloudfoundry.NewSetup(cli, filepath.Join(home, ".cf"), stack)
+			cli = cloudfoundry.NewHomeExecutable(cli, dir)
+			cfHome = filepath.Join(dir, ".cf")
+		}
+
+		if config.events != nil {
+			cli = cloudfoundry.NewEventingExecutable(cli, config.events.record("cf"))
+		}
+
+		if config.target.API != "" {
+			err = cloudfoundry.NewLogin(cli).Run(filepath.Dir(cfHome), cloudfoundry.Target(config.target))
+			if err != nil {
+				return Platform{}, fmt.Errorf("failed to target %s: %w", config.target.API, err)
+			}
+		}
+
+		locks := filepath.Join(workspace, "locks")
+		if config.target.API != "" {
+			locks = filepath.Join(locks, strings.NewReplacer("://", "-", "/", "-", ":", "-").Replace(config.target.API))
+		}
+
+		initialize := cloudfoundry.NewInitialize(cli).WithLocks(locks)
+		if config.uploadMissing {
+			initialize = initialize.WithMissingBuildpacks(cloudFoundryBuildpackLister{registry: docker.NewBuildpacksRegistry("https://api.github.com", token)})
+		}
+		setup := cloudfoundry.NewSetup(cli, cfHome, stack)
 		stage := cloudfoundry.NewStage(cli)
 		teardown := cloudfoundry.NewTeardown(cli)
+		if config.stopTimeout > 0 {
+			teardown = teardown.WithGracefulStop()
+		}
+		if config.clock != nil {
+			teardown = teardown.WithClock(config.clock)
+		}
+
+		options = append([]PlatformOption{
+			withLogDirectory(filepath.Join(workspace, "logs")),
+			withScaler(cloudFoundryScaler{scaler: cloudfoundry.NewScaler(cli), workspace: workspace}),
+			withMetadataReader(cloudFoundryMetadataReader{reader: cloudfoundry.NewMetadataReader(cli), workspace: workspace}),
+			withAppFeatureReader(cloudFoundryAppFeatureReader{reader: cloudfoundry.NewAppFeatureReader(cli), workspace: workspace}),
+			withStagedBuildpackReader(cloudFoundryStagedBuildpackReader{reader: cloudfoundry.NewStagedBuildpackReader(cli), workspace: workspace}),
+			withRuntimeLogStreamer(cloudFoundryLogStreamer{streamer: cloudfoundry.NewLogStreamer(cli), workspace: workspace}),
+		}, options...)
+
+		platform := NewCloudFoundry(initialize, setup, stage, teardown, workspace, options...)
+		platform.gc = cloudFoundryGCProcess{collector: cloudfoundry.NewGarbageCollector(cli, workspace)}
+		platform.preflight = cloudFoundryPreflightProcess{preflight: cloudfoundry.NewPreflight(cli)}
+
+		return platform, nil
+	case Docker, Podman:
+		opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+		if platformType == Podman {
+			opts = append(opts, client.WithHost(podman.Host(os.Getenv)))
+		}
+
+		if config.events != nil {
+			opts = append(opts, docker.WithEventTransport(config.events.record("docker")))
+		}
 
-		return NewCloudFoundry(initialize, setup, stage, teardown, os.TempDir()), nil
-	case Docker:
-		client, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		if config.apiDebugLog != nil {
+			opts = append(opts, docker.WithDebugTransport(config.apiDebugLog))
+		}
+
+		apiClient, err := client.NewClientWithOpts(opts...)
 		if err != nil {
 			return Platform{}, err
 		}
 
-		workspace := filepath.Join(home, ".switchblade")
+		if config.reaper && config.runID == "" {
+			config.runID, err = RandomName()
+			if err != nil {
+				return Platform{}, err
+			}
+
+			options = append(options, WithRunID(config.runID))
+		}
+
+		root, err := resolveWorkspace(config.workspaceRoot, filepath.Join(home, ".switchblade"))
+		if err != nil {
+			return Platform{}, err
+		}
+
+		workspace := root
+		if config.runID != "" {
+			workspace = filepath.Join(root, "runs", config.runID)
+		}
+
+		journal := docker.NewJournal(filepath.Join(workspace, "journal.jsonl"))
+		var compatClient client.CommonAPIClient = docker.NewRootlessClient(apiClient)
+		if platformType == Podman {
+			compatClient = podman.NewClient(apiClient)
+		}
+
+		client := docker.NewJournalingClient(docker.NewThrottledClient(compatClient, config.dockerAPILimit), journal)
+
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return Platform{}, err
+		}
+
+		options = append([]PlatformOption{
+			withLogDirectory(filepath.Join(workspace, "logs")),
+			withRunWorkspace(workspace),
+			withDropletPusher(docker.NewDropletPusher(client).WithAuth(config.registryAuth.username, config.registryAuth.password)),
+			withContainerController(docker.NewContainerController(client)),
+			withEnvironmentSnapshotter(docker.NewEnvironmentSnapshotter(client)),
+			withRuntimeLogStreamer(docker.NewLogStreamer(client)),
+		}, options...)
 
 		golang := pexec.NewExecutable("go")
 		archiver := docker.NewTGZArchiver()
-		lifecycleManager := docker.NewLifecycleManager(golang, archiver)
-		buildpacksCache := docker.NewBuildpacksCache(filepath.Join(workspace, "buildpacks-cache"))
+		var lifecycleManager docker.LifecycleBuilder = docker.NewLifecycleManager(golang, archiver, filepath.Join(cache, "switchblade", "lifecycle"))
+		if config.lifecycleURI != "" {
+			lifecycleManager = docker.NewPrebuiltLifecycleManager(archiver, config.lifecycleURI, config.lifecycleVersion)
+		}
+		lifecycleManager = docker.NewOnceLifecycleBuilder(lifecycleManager, filepath.Join(workspace, "locks"))
+		stackPuller := docker.NewStackPuller(client, filepath.Join(workspace, "locks"))
+		buildpacksCache := docker.NewBuildpacksCache(filepath.Join(root, "buildpacks-cache"))
 		buildpacksRegistry := docker.NewBuildpacksRegistry("https://api.github.com", token)
 		buildpacksManager := docker.NewBuildpacksManager(archiver, buildpacksCache, buildpacksRegistry)
 		networkManager := docker.NewNetworkManager(client)
+		options = append([]PlatformOption{withScaler(docker.NewScaler(client, networkManager, workspace))}, options...)
 
 		initialize := docker.NewInitialize(buildpacksRegistry)
-		setup := docker.NewSetup(client, lifecycleManager, buildpacksManager, archiver, networkManager, workspace, stack)
+		setup := docker.NewSetup(client, lifecycleManager, buildpacksManager, archiver, networkManager, workspace, stack).WithStackPuller(stackPuller).WithRunID(config.runID)
 		stage := docker.NewStage(client, archiver, workspace)
-		start := docker.NewStart(client, networkManager, workspace, stack)
-		teardown := docker.NewTeardown(client, networkManager, workspace)
+		if config.zstdDroplets {
+			stage = stage.WithZstdDroplets()
+		}
+		if config.dropletCache {
+			stage = stage.WithDropletCache(docker.NewDropletCache(filepath.Join(root, "droplet-cache")))
+		}
+		if config.sbom {
+			stage = stage.WithSBOM()
+		}
+		if config.dropletKey != nil {
+			stage = stage.WithDropletSigning(config.dropletKey)
+		}
+		credentialService := docker.NewCredentialService(client, golang, archiver, filepath.Join(cache, "switchblade", "credential-service")).WithRunID(config.runID)
+		start := docker.NewStart(client, networkManager, workspace, stack).WithRunID(config.runID).WithCredentialService(credentialService)
+		if config.clock != nil {
+			start = start.WithClock(config.clock)
+		}
+		if config.dropletKey != nil {
+			start = start.WithDropletVerification(config.dropletKey.Public())
+		}
+		remoteHost := docker.RemoteHost(apiClient.DaemonHost())
+		if remoteHost != "" {
+			start = start.WithRemoteHost(remoteHost)
+		}
+		if config.hostServices {
+			hostGateway := docker.NewHostGateway(client)
+			if platformType == Podman {
+				hostGateway = hostGateway.WithRuntimeDetector(podman.DetectRuntime)
+			}
+
+			setup = setup.WithHostGateway(hostGateway)
+			start = start.WithHostGateway(hostGateway)
+		}
+		teardown := docker.NewTeardown(client, networkManager, workspace).WithJournal(journal).WithRunID(config.runID).WithPolicy(docker.TeardownPolicy(config.teardownPolicy))
+		if config.stopTimeout > 0 {
+			teardown = teardown.WithStopTimeout(config.stopTimeout)
+		}
+		if config.crashHandler != nil {
+			teardown = teardown.WithCrashHandler(func(report docker.CrashReport) {
+				config.crashHandler(CrashReport(report))
+			})
+		}
+
+		gc := dockerGCProcess{collector: docker.NewGarbageCollector(client, networkManager, workspace).WithRunID(config.runID)}
+		recovery := dockerRecoverProcess{recovery: docker.NewRecovery(client, journal)}
 
-		return NewDocker(initialize, setup, stage, start, teardown), nil
+		var closer dockerCloseProcess
+		if config.reaper {
+			var socket string
+			if platformType == Podman {
+				socket, err = podman.Socket(apiClient.DaemonHost())
+			} else {
+				socket, err = docker.DaemonSocket(context.Background(), apiClient)
+			}
+			if err != nil {
+				return Platform{}, err
+			}
+
+			reaper := docker.NewReaper(apiClient).WithSocket(socket)
+			if remoteHost != "" {
+				reaper = reaper.WithHost(remoteHost)
+			}
+			if config.clock != nil {
+				reaper = reaper.WithClock(config.clock)
+			}
+
+			closer.reaper, err = reaper.Start(context.Background(), config.runID)
+			if err != nil {
+				return Platform{}, err
+			}
+		}
+
+		if config.stagingPoolSize > 0 {
+			pool := docker.NewStagingPool(client, lifecycleManager, networkManager, workspace, config.stagingPoolSize).WithStackPuller(stackPuller).WithRunID(config.runID)
+			pool.Warm(context.Background(), stack)
+
+			setup = setup.WithStagingPool(pool)
+			closer.pool = &pool
+		}
+
+		platform := NewDocker(initialize, setup, stage, start, teardown, options...)
+		platform.close = closer
+		platform.gc = gc
+		platform.recovery = recovery
+		platform.interrupt = dockerInterruptProcess{collector: gc.collector}
+		platform.preflight = dockerPreflightProcess{preflight: docker.NewPreflight(client, workspace, stack)}
+
+		return platform, nil
 	}
 
 	return Platform{}, fmt.Errorf("unknown platform type: %q", platformType)
 }
 
+func (p Platform) RunID() string {
+	return p.runID
+}
+
 func (p Platform) Initialize(buildpacks ...Buildpack) error {
 	return p.initialize.Execute(buildpacks...)
 }
+
+func (p Platform) WaitForCleanup(ctx context.Context) error {
+	if p.cleanup == nil {
+		return nil
+	}
+
+	return p.cleanup.wait(ctx)
+}
+
+func (p Platform) GC(ctx context.Context, olderThan time.Duration) error {
+	if p.gc == nil {
+		return nil
+	}
+
+	return p.gc.Execute(ctx, olderThan)
+}
+
+func (p Platform) GCDryRun(ctx context.Context, olderThan time.Duration) ([]Resource, error) {
+	if p.gc == nil {
+		return nil, nil
+	}
+
+	return p.gc.DryRun(ctx, olderThan)
+}
+
+func (p Platform) Recover(ctx context.Context) error {
+	if p.recovery == nil {
+		return nil
+	}
+
+	return p.recovery.Execute(ctx)
+}
+
+func (p Platform) Close() error {
+	if p.close == nil {
+		return nil
+	}
+
+	return p.close.Execute()
+}
+
+func resolveWorkspace(dir, fallback string) (string, error) {
+	if dir == "" {
+		dir = fallback
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workspace: %w", err)
+	}
+
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	return dir, nil
+}
@@ -0,0 +1,138 @@
+package switchblade_test
+
+import (
+	gocontext "context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testNewPlatform(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		tmpDir string
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "workspace")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	context("WithWorkspace", func() {
+		it("creates the workspace directory", func() {
+			_, err := switchblade.NewPlatform(switchblade.CloudFoundry, "some-token", "some-stack", switchblade.WithWorkspace(filepath.Join(tmpDir, "some", "workspace")))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(tmpDir, "some", "workspace")).To(BeADirectory())
+		})
+
+		context("failure cases", func() {
+			context("when the workspace cannot be created", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(tmpDir, "some-file"), nil, 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := switchblade.NewPlatform(switchblade.CloudFoundry, "some-token", "some-stack", switchblade.WithWorkspace(filepath.Join(tmpDir, "some-file", "workspace")))
+					Expect(err).To(MatchError(ContainSubstring("failed to create workspace:")))
+					Expect(err).To(MatchError(ContainSubstring("not a directory")))
+				})
+			})
+		})
+	})
+
+	context("WithCloudFoundryTarget", func() {
+		var (
+			workspace string
+			cassette  string
+			target    switchblade.CloudFoundryTarget
+		)
+
+		it.Before(func() {
+			workspace = filepath.Join(tmpDir, "workspace")
+			cassette = filepath.Join(tmpDir, "cassette.jsonl")
+			target = switchblade.CloudFoundryTarget{
+				API:      "https://api.example.com",
+				Username: "some-username",
+				Password: "some-password",
+				Org:      "some-org",
+				Space:    "some-space",
+			}
+		})
+
+		it("logs in to the target with its own $CF_HOME", func() {
+			err := os.WriteFile(cassette, []byte(`{"args":["api","https://api.example.com"]}
+{"args":["auth"]}
+{"args":["target","-o","some-org","-s","some-space"]}
+`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = switchblade.NewPlatform(switchblade.CloudFoundry, "some-token", "some-stack",
+				switchblade.WithWorkspace(workspace),
+				switchblade.WithCassetteReplay(cassette),
+				switchblade.WithCloudFoundryTarget(target),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			homes, err := filepath.Glob(filepath.Join(workspace, "cf-home-*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(homes).To(HaveLen(1))
+			Expect(homes[0]).To(BeADirectory())
+		})
+
+		context("failure cases", func() {
+			context("when authentication fails", func() {
+				it.Before(func() {
+					err := os.WriteFile(cassette, []byte(`{"args":["api","https://api.example.com"]}
+{"args":["auth"],"stdout":"Credentials were rejected, please try again.","error":"exit status 1"}
+`), 0600)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("returns an error", func() {
+					_, err := switchblade.NewPlatform(switchblade.CloudFoundry, "some-token", "some-stack",
+						switchblade.WithWorkspace(workspace),
+						switchblade.WithCassetteReplay(cassette),
+						switchblade.WithCloudFoundryTarget(target),
+					)
+					Expect(err).To(MatchError(ContainSubstring("failed to target https://api.example.com: failed to authenticate: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("Credentials were rejected")))
+				})
+			})
+		})
+	})
+
+	context("Preflight", func() {
+		it("reports the problems found with the platform", func() {
+			cassette := filepath.Join(tmpDir, "cassette.jsonl")
+			err := os.WriteFile(cassette, []byte(`{"args":["oauth-token"],"stdout":"bearer some-token\n"}
+{"args":["curl","/v3/organization_quotas?names=default"],"stdout":"{\"resources\": []}"}
+`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+
+			platform, err := switchblade.NewPlatform(switchblade.CloudFoundry, "some-token", "some-stack",
+				switchblade.WithWorkspace(filepath.Join(tmpDir, "workspace")),
+				switchblade.WithCassetteReplay(cassette),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			problems, err := platform.Preflight(gocontext.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(HaveLen(2))
+			Expect(problems[0].Check).To(Equal("auth"))
+			Expect(problems[1].Check).To(Equal("quota"))
+			Expect(problems[1].Remedy).To(Equal("Create it with `cf create-org-quota default`."))
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package switchblade
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+	"github.com/cloudfoundry/switchblade/internal/docker"
+)
+
+type PreflightProblem struct {
+	Check   string
+	Message string
+	Remedy  string
+}
+
+type preflightProcess interface {
+	Execute(ctx context.Context) ([]PreflightProblem, error)
+}
+
+func (p Platform) Preflight(ctx context.Context) ([]PreflightProblem, error) {
+	if p.preflight == nil {
+		return nil, nil
+	}
+
+	problems, err := p.preflight.Execute(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run preflight checks: %w", err)
+	}
+
+	return problems, nil
+}
+
+type dockerPreflightProcess struct {
+	preflight docker.Preflight
+}
+
+func (p dockerPreflightProcess) Execute(ctx context.Context) ([]PreflightProblem, error) {
+	problems, err := p.preflight.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var converted []PreflightProblem
+	for _, problem := range problems {
+		converted = append(converted, PreflightProblem(problem))
+	}
+
+	return converted, nil
+}
+
+type cloudFoundryPreflightProcess struct {
+	preflight cloudfoundry.Preflight
+}
+
+func (p cloudFoundryPreflightProcess) Execute(ctx context.Context) ([]PreflightProblem, error) {
+	problems, err := p.preflight.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var converted []PreflightProblem
+	for _, problem := range problems {
+		converted = append(converted, PreflightProblem(problem))
+	}
+
+	return converted, nil
+}
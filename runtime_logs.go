@@ -0,0 +1,95 @@
+package switchblade
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+)
+
+type runtimeLogStreamer interface {
+	Stream(ctx context.Context, name string, w io.Writer) error
+}
+
+func withRuntimeLogStreamer(streamer runtimeLogStreamer) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.runtimeLogs.streamer = streamer
+		return config
+	}
+}
+
+type runtimeLogStreams struct {
+	streamer runtimeLogStreamer
+	streams  *runtimeLogRegistry
+}
+
+type runtimeLogRegistry struct {
+	streams map[string]runtimeLogStream
+	m       sync.Mutex
+}
+
+type runtimeLogStream struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// stop cancels the stream and waits for it to exit so that nothing is written
+// to its writer afterwards.
+func (s runtimeLogStream) stop() {
+	s.cancel()
+	<-s.done
+}
+
+func newRuntimeLogStreams() runtimeLogStreams {
+	return runtimeLogStreams{streams: &runtimeLogRegistry{streams: map[string]runtimeLogStream{}}}
+}
+
+func (s runtimeLogStreams) start(name, target string, w io.Writer) {
+	if w == nil || s.streamer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := runtimeLogStream{cancel: cancel, done: make(chan struct{})}
+
+	s.streams.m.Lock()
+	existing, ok := s.streams.streams[name]
+	s.streams.streams[name] = stream
+	s.streams.m.Unlock()
+
+	if ok {
+		existing.stop()
+	}
+
+	go func() {
+		defer close(stream.done)
+
+		err := s.streamer.Stream(ctx, target, w)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(w, "failed to stream runtime logs for %s: %s\n", name, err)
+		}
+	}()
+}
+
+func (s runtimeLogStreams) stop(name string) {
+	s.streams.m.Lock()
+	stream, ok := s.streams.streams[name]
+	delete(s.streams.streams, name)
+	s.streams.m.Unlock()
+
+	if ok {
+		stream.stop()
+	}
+}
+
+type cloudFoundryLogStreamer struct {
+	streamer  cloudfoundry.LogStreamer
+	workspace string
+}
+
+func (s cloudFoundryLogStreamer) Stream(ctx context.Context, name string, w io.Writer) error {
+	return s.streamer.Stream(ctx, filepath.Join(s.workspace, name), name, w)
+}
@@ -0,0 +1,160 @@
+package switchblade
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type SBOM struct {
+	Name       string
+	Components []SBOMComponent
+}
+
+type SBOMComponent struct {
+	Type      string
+	Name      string
+	Version   string
+	Buildpack string
+	URI       string
+	SHA256    string
+}
+
+func (s SBOM) CycloneDX() ([]byte, error) {
+	type hash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+
+	type reference struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}
+
+	type property struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	type component struct {
+		Type               string      `json:"type"`
+		Name               string      `json:"name"`
+		Version            string      `json:"version,omitempty"`
+		Hashes             []hash      `json:"hashes,omitempty"`
+		ExternalReferences []reference `json:"externalReferences,omitempty"`
+		Properties         []property  `json:"properties,omitempty"`
+	}
+
+	var components []component
+	for _, c := range s.Components {
+		entry := component{
+			Type:    "library",
+			Name:    c.Name,
+			Version: c.Version,
+		}
+
+		if c.Type == "buildpack" {
+			entry.Type = "application"
+		}
+
+		if c.SHA256 != "" {
+			entry.Hashes = []hash{{Alg: "SHA-256", Content: c.SHA256}}
+		}
+
+		if c.URI != "" {
+			entry.ExternalReferences = []reference{{Type: "distribution", URL: c.URI}}
+		}
+
+		entry.Properties = []property{{Name: "switchblade:type", Value: c.Type}}
+		if c.Buildpack != "" {
+			entry.Properties = append(entry.Properties, property{Name: "switchblade:buildpack", Value: c.Buildpack})
+		}
+
+		components = append(components, entry)
+	}
+
+	content, err := json.Marshal(map[string]interface{}{
+		"bomFormat":   "CycloneDX",
+		"specVersion": "1.4",
+		"version":     1,
+		"metadata": map[string]interface{}{
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"component": map[string]string{
+				"type": "application",
+				"name": s.Name,
+			},
+		},
+		"components": components,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal CycloneDX document: %w", err)
+	}
+
+	return content, nil
+}
+
+func (s SBOM) SPDX() ([]byte, error) {
+	type checksum struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	}
+
+	type pkg struct {
+		SPDXID                string     `json:"SPDXID"`
+		Name                  string     `json:"name"`
+		VersionInfo           string     `json:"versionInfo,omitempty"`
+		DownloadLocation      string     `json:"downloadLocation"`
+		FilesAnalyzed         bool       `json:"filesAnalyzed"`
+		Checksums             []checksum `json:"checksums,omitempty"`
+		PrimaryPackagePurpose string     `json:"primaryPackagePurpose"`
+	}
+
+	var packages []pkg
+	for i, c := range s.Components {
+		entry := pkg{
+			SPDXID:                fmt.Sprintf("SPDXRef-Package-%d", i),
+			Name:                  c.Name,
+			VersionInfo:           c.Version,
+			DownloadLocation:      "NOASSERTION",
+			PrimaryPackagePurpose: "LIBRARY",
+		}
+
+		if c.Type == "buildpack" {
+			entry.PrimaryPackagePurpose = "APPLICATION"
+		}
+
+		if c.URI != "" {
+			entry.DownloadLocation = c.URI
+		}
+
+		if c.SHA256 != "" {
+			entry.Checksums = []checksum{{Algorithm: "SHA256", ChecksumValue: c.SHA256}}
+		}
+
+		packages = append(packages, entry)
+	}
+
+	identity, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SPDX document: %w", err)
+	}
+
+	content, err := json.Marshal(map[string]interface{}{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              s.Name,
+		"documentNamespace": fmt.Sprintf("https://github.com/cloudfoundry/switchblade/spdx/%s-%x", s.Name, sha256.Sum256(identity)),
+		"creationInfo": map[string]interface{}{
+			"created":  time.Now().UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: switchblade"},
+		},
+		"packages": packages,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SPDX document: %w", err)
+	}
+
+	return content, nil
+}
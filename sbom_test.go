@@ -0,0 +1,127 @@
+package switchblade_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSBOM(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		sbom switchblade.SBOM
+	)
+
+	it.Before(func() {
+		sbom = switchblade.SBOM{
+			Name: "some-app",
+			Components: []switchblade.SBOMComponent{
+				{
+					Type:    "buildpack",
+					Name:    "go",
+					Version: "1.10.2",
+				},
+				{
+					Type:      "dependency",
+					Name:      "go",
+					Version:   "1.20.1",
+					Buildpack: "go_buildpack",
+					URI:       "https://example.com/go-1.20.1.tgz",
+					SHA256:    "some-go-sha",
+				},
+			},
+		}
+	})
+
+	context("CycloneDX", func() {
+		it("renders a CycloneDX document", func() {
+			content, err := sbom.CycloneDX()
+			Expect(err).NotTo(HaveOccurred())
+
+			var document struct {
+				BOMFormat   string `json:"bomFormat"`
+				SpecVersion string `json:"specVersion"`
+				Metadata    struct {
+					Timestamp string            `json:"timestamp"`
+					Component map[string]string `json:"component"`
+				} `json:"metadata"`
+				Components []json.RawMessage `json:"components"`
+			}
+			Expect(json.Unmarshal(content, &document)).To(Succeed())
+
+			Expect(document.BOMFormat).To(Equal("CycloneDX"))
+			Expect(document.SpecVersion).To(Equal("1.4"))
+			Expect(document.Metadata.Timestamp).NotTo(BeEmpty())
+			Expect(document.Metadata.Component).To(Equal(map[string]string{
+				"type": "application",
+				"name": "some-app",
+			}))
+			Expect(document.Components).To(HaveLen(2))
+			Expect(string(document.Components[0])).To(MatchJSON(`{
+				"type": "application",
+				"name": "go",
+				"version": "1.10.2",
+				"properties": [{ "name": "switchblade:type", "value": "buildpack" }]
+			}`))
+			Expect(string(document.Components[1])).To(MatchJSON(`{
+				"type": "library",
+				"name": "go",
+				"version": "1.20.1",
+				"hashes": [{ "alg": "SHA-256", "content": "some-go-sha" }],
+				"externalReferences": [{ "type": "distribution", "url": "https://example.com/go-1.20.1.tgz" }],
+				"properties": [
+					{ "name": "switchblade:type", "value": "dependency" },
+					{ "name": "switchblade:buildpack", "value": "go_buildpack" }
+				]
+			}`))
+		})
+	})
+
+	context("SPDX", func() {
+		it("renders an SPDX document", func() {
+			content, err := sbom.SPDX()
+			Expect(err).NotTo(HaveOccurred())
+
+			var document struct {
+				SPDXVersion       string `json:"spdxVersion"`
+				Name              string `json:"name"`
+				DocumentNamespace string `json:"documentNamespace"`
+				CreationInfo      struct {
+					Created  string   `json:"created"`
+					Creators []string `json:"creators"`
+				} `json:"creationInfo"`
+				Packages []json.RawMessage `json:"packages"`
+			}
+			Expect(json.Unmarshal(content, &document)).To(Succeed())
+
+			Expect(document.SPDXVersion).To(Equal("SPDX-2.3"))
+			Expect(document.Name).To(Equal("some-app"))
+			Expect(document.DocumentNamespace).To(HavePrefix("https://github.com/cloudfoundry/switchblade/spdx/some-app-"))
+			Expect(document.CreationInfo.Created).NotTo(BeEmpty())
+			Expect(document.CreationInfo.Creators).To(Equal([]string{"Tool: switchblade"}))
+			Expect(document.Packages).To(HaveLen(2))
+			Expect(string(document.Packages[0])).To(MatchJSON(`{
+				"SPDXID": "SPDXRef-Package-0",
+				"name": "go",
+				"versionInfo": "1.10.2",
+				"downloadLocation": "NOASSERTION",
+				"filesAnalyzed": false,
+				"primaryPackagePurpose": "APPLICATION"
+			}`))
+			Expect(string(document.Packages[1])).To(MatchJSON(`{
+				"SPDXID": "SPDXRef-Package-1",
+				"name": "go",
+				"versionInfo": "1.20.1",
+				"downloadLocation": "https://example.com/go-1.20.1.tgz",
+				"filesAnalyzed": false,
+				"checksums": [{ "algorithm": "SHA256", "checksumValue": "some-go-sha" }],
+				"primaryPackagePurpose": "LIBRARY"
+			}`))
+		})
+	})
+}
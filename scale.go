@@ -0,0 +1,90 @@
+package switchblade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+)
+
+type instanceScaler interface {
+	Scale(ctx context.Context, name string, instances int) error
+}
+
+type deploymentScaler struct {
+	name   string
+	scaler instanceScaler
+}
+
+func (d Deployment) Scale(instances int) error {
+	if d.scaler == nil {
+		return fmt.Errorf("failed to scale %s: scaling is not supported by this platform", d.Name)
+	}
+
+	err := d.scaler.scaler.Scale(context.Background(), d.scaler.name, instances)
+	if err != nil {
+		return fmt.Errorf("failed to scale %s: %w", d.Name, err)
+	}
+
+	return nil
+}
+
+func (d Deployment) ScaleDuring(probe func(ctx context.Context) error, interval time.Duration, instances ...int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- probe(ctx)
+	}()
+
+	probing := true
+	for i, count := range instances {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+
+		select {
+		case err := <-done:
+			probing = false
+			if err != nil {
+				return fmt.Errorf("failed to probe %s while scaling: %w", d.Name, err)
+			}
+		default:
+		}
+
+		err := d.Scale(count)
+		if err != nil {
+			cancel()
+			if probing {
+				<-done
+			}
+
+			return err
+		}
+	}
+
+	cancel()
+	if !probing {
+		return nil
+	}
+
+	err := <-done
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("failed to probe %s while scaling: %w", d.Name, err)
+	}
+
+	return nil
+}
+
+type cloudFoundryScaler struct {
+	scaler    cloudfoundry.Scaler
+	workspace string
+}
+
+func (s cloudFoundryScaler) Scale(ctx context.Context, name string, instances int) error {
+	return s.scaler.Scale(filepath.Join(s.workspace, name), name, instances)
+}
@@ -0,0 +1,33 @@
+package switchblade
+
+//go:generate faux --interface DropletScanner --output fakes/droplet_scanner.go
+type DropletScanner interface {
+	Scan(target ScanTarget) (ScanResult, error)
+}
+
+type ScanTarget struct {
+	Name    string
+	Droplet string
+	Image   string
+}
+
+type ScanResult struct {
+	Scanner  string
+	Findings []ScanFinding
+	Output   string
+}
+
+type ScanFinding struct {
+	ID       string
+	Severity string
+	Package  string
+	Version  string
+}
+
+func WithDropletScanner(scanner DropletScanner, exportImage bool) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.scanner = scanner
+		config.scanImages = exportImage
+		return config
+	}
+}
@@ -0,0 +1,119 @@
+package switchblade
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type StackMatrix struct {
+	Platforms map[string]Platform
+	Stacks    []string
+	Configure func(DeployProcess) DeployProcess
+}
+
+type StackMatrixResult struct {
+	Platform   string
+	Stack      string
+	Deployment Deployment
+	Logs       string
+	Error      error
+}
+
+type StackMatrixReport []StackMatrixResult
+
+func (m StackMatrix) Run(name, source string, assert func(Deployment) error) StackMatrixReport {
+	var platforms []string
+	for platform := range m.Platforms {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	var report StackMatrixReport
+	for _, platform := range platforms {
+		for _, stack := range m.Stacks {
+			report = append(report, m.run(platform, stack, fmt.Sprintf("%s-%s", name, stack), source, assert))
+		}
+	}
+
+	return report
+}
+
+func (m StackMatrix) run(platform, stack, name, source string, assert func(Deployment) error) (result StackMatrixResult) {
+	result = StackMatrixResult{Platform: platform, Stack: stack}
+
+	deploy := m.Platforms[platform].Deploy.WithStack(stack)
+	if m.Configure != nil {
+		deploy = m.Configure(deploy)
+	}
+
+	defer func() {
+		err := m.Platforms[platform].Delete.Execute(name)
+		if err != nil && result.Error == nil {
+			result.Error = fmt.Errorf("failed to delete %s: %w", name, err)
+		}
+	}()
+
+	deployment, logs, err := deploy.Execute(name, source)
+	if logs != nil {
+		result.Logs = logs.String()
+	}
+	if err != nil {
+		result.Error = fmt.Errorf("failed to deploy %s: %w", name, err)
+		return result
+	}
+
+	result.Deployment = deployment
+
+	defer func() {
+		if r := recover(); r != nil {
+			result.Error = fmt.Errorf("assertion panicked: %v", r)
+		}
+	}()
+
+	err = assert(deployment)
+	if err != nil {
+		result.Error = err
+	}
+
+	return result
+}
+
+func (r StackMatrixReport) Failures() StackMatrixReport {
+	var failures StackMatrixReport
+	for _, result := range r {
+		if result.Error != nil {
+			failures = append(failures, result)
+		}
+	}
+
+	return failures
+}
+
+func (r StackMatrixReport) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	var lines []string
+	for _, failure := range failures {
+		lines = append(lines, fmt.Sprintf("  %s/%s: %s", failure.Platform, failure.Stack, failure.Error))
+	}
+
+	return fmt.Errorf("%d of %d stack matrix entries failed:\n%s", len(failures), len(r), strings.Join(lines, "\n"))
+}
+
+func (r StackMatrixReport) String() string {
+	var lines []string
+	for _, result := range r {
+		status := "PASS"
+		if result.Error != nil {
+			status = fmt.Sprintf("FAIL: %s", result.Error)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s/%s: %s", result.Platform, result.Stack, status))
+	}
+
+	return strings.Join(lines, "\n")
+}
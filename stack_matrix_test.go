@@ -0,0 +1,202 @@
+package switchblade_test
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/cloudfoundry/switchblade/fakes"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testStackMatrix(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		matrix switchblade.StackMatrix
+
+		dockerSetup    *fakes.DockerSetupPhase
+		dockerStart    *fakes.DockerStartPhase
+		dockerTeardown *fakes.DockerTeardownPhase
+		cfSetup        *fakes.CloudFoundrySetupPhase
+		cfTeardown     *fakes.CloudFoundryTeardownPhase
+		workspace      string
+	)
+
+	it.Before(func() {
+		var err error
+		workspace, err = os.MkdirTemp("", "workspace")
+		Expect(err).NotTo(HaveOccurred())
+
+		dockerSetup = &fakes.DockerSetupPhase{}
+		dockerSetup.WithStackCall.Returns.SetupPhase = dockerSetup
+		dockerSetup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
+			fmt.Fprintf(logs, "Setting up %s...\n", name)
+			return "some-container-id", "", nil
+		}
+
+		stage := &fakes.DockerStagePhase{}
+
+		dockerStart = &fakes.DockerStartPhase{}
+		dockerStart.WithStackCall.Returns.StartPhase = dockerStart
+		dockerStart.RunCall.Returns.ExternalURL = "some-docker-url"
+
+		dockerTeardown = &fakes.DockerTeardownPhase{}
+
+		cfSetup = &fakes.CloudFoundrySetupPhase{}
+		cfSetup.WithStackCall.Returns.SetupPhase = cfSetup
+
+		cfStage := &fakes.CloudFoundryStagePhase{}
+		cfStage.RunCall.Returns.Url = "some-cf-url"
+
+		cfTeardown = &fakes.CloudFoundryTeardownPhase{}
+
+		matrix = switchblade.StackMatrix{
+			Platforms: map[string]switchblade.Platform{
+				switchblade.Docker:       switchblade.NewDocker(&fakes.DockerInitializePhase{}, dockerSetup, stage, dockerStart, dockerTeardown),
+				switchblade.CloudFoundry: switchblade.NewCloudFoundry(&fakes.CloudFoundryInitializePhase{}, cfSetup, cfStage, cfTeardown, workspace),
+			},
+			Stacks: []string{"cflinuxfs3", "cflinuxfs4"},
+		}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workspace)).To(Succeed())
+	})
+
+	it("deploys and asserts on every stack for every platform", func() {
+		var urls []string
+		report := matrix.Run("some-app", "/some/path/to/my/app", func(deployment switchblade.Deployment) error {
+			urls = append(urls, deployment.ExternalURL)
+			return nil
+		})
+		Expect(report.Err()).NotTo(HaveOccurred())
+		Expect(report.Failures()).To(BeEmpty())
+
+		Expect(report).To(HaveLen(4))
+		Expect(report[0].Platform).To(Equal("cf"))
+		Expect(report[0].Stack).To(Equal("cflinuxfs3"))
+		Expect(report[1].Platform).To(Equal("cf"))
+		Expect(report[1].Stack).To(Equal("cflinuxfs4"))
+		Expect(report[2].Platform).To(Equal("docker"))
+		Expect(report[2].Stack).To(Equal("cflinuxfs3"))
+		Expect(report[2].Logs).To(ContainSubstring("Setting up some-app-cflinuxfs3..."))
+		Expect(report[3].Platform).To(Equal("docker"))
+		Expect(report[3].Stack).To(Equal("cflinuxfs4"))
+		Expect(report[3].Deployment.Name).To(Equal("some-app-cflinuxfs4"))
+
+		Expect(urls).To(Equal([]string{"some-cf-url", "some-cf-url", "some-docker-url", "some-docker-url"}))
+
+		Expect(dockerSetup.WithStackCall.CallCount).To(Equal(2))
+		Expect(dockerSetup.WithStackCall.Receives.Stack).To(Equal("cflinuxfs4"))
+		Expect(cfSetup.WithStackCall.CallCount).To(Equal(2))
+
+		Expect(dockerTeardown.RunCall.CallCount).To(Equal(2))
+		Expect(dockerTeardown.RunCall.Receives.Name).To(Equal("some-app-cflinuxfs4"))
+		Expect(cfTeardown.RunCall.CallCount).To(Equal(2))
+
+		Expect(report.String()).To(Equal(`cf/cflinuxfs3: PASS
+cf/cflinuxfs4: PASS
+docker/cflinuxfs3: PASS
+docker/cflinuxfs4: PASS`))
+	})
+
+	context("when the deploy process is configured", func() {
+		it.Before(func() {
+			dockerSetup.WithEnvCall.Returns.SetupPhase = dockerSetup
+			dockerStart.WithEnvCall.Returns.StartPhase = dockerStart
+
+			matrix.Platforms = map[string]switchblade.Platform{switchblade.Docker: matrix.Platforms[switchblade.Docker]}
+			matrix.Configure = func(deploy switchblade.DeployProcess) switchblade.DeployProcess {
+				return deploy.WithEnv(map[string]string{"SOME_KEY": "some-value"})
+			}
+		})
+
+		it("applies the configuration to each deployment", func() {
+			report := matrix.Run("some-app", "/some/path/to/my/app", func(switchblade.Deployment) error { return nil })
+			Expect(report.Err()).NotTo(HaveOccurred())
+
+			Expect(dockerSetup.WithEnvCall.CallCount).To(Equal(2))
+			Expect(dockerSetup.WithEnvCall.Receives.Env).To(Equal(map[string]string{"SOME_KEY": "some-value"}))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when a deployment fails on one stack", func() {
+			it.Before(func() {
+				dockerSetup.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, path string) (string, string, error) {
+					if name == "some-app-cflinuxfs4" {
+						fmt.Fprintln(logs, "Unsupported stack")
+						return "", "", errors.New("could not set up")
+					}
+
+					return "some-container-id", "", nil
+				}
+			})
+
+			it("records the failure and continues with the other stacks", func() {
+				report := matrix.Run("some-app", "/some/path/to/my/app", func(switchblade.Deployment) error { return nil })
+				Expect(report).To(HaveLen(4))
+
+				failures := report.Failures()
+				Expect(failures).To(HaveLen(1))
+				Expect(failures[0].Platform).To(Equal("docker"))
+				Expect(failures[0].Stack).To(Equal("cflinuxfs4"))
+				Expect(failures[0].Logs).To(ContainSubstring("Unsupported stack"))
+				Expect(failures[0].Error).To(MatchError(ContainSubstring("failed to deploy some-app-cflinuxfs4: failed to run setup phase: could not set up")))
+
+				Expect(report.Err()).To(MatchError(ContainSubstring("1 of 4 stack matrix entries failed:\n  docker/cflinuxfs4: failed to deploy some-app-cflinuxfs4")))
+				Expect(dockerTeardown.RunCall.CallCount).To(Equal(2))
+			})
+		})
+
+		context("when the assertion fails", func() {
+			it("records the assertion error", func() {
+				report := matrix.Run("some-app", "/some/path/to/my/app", func(deployment switchblade.Deployment) error {
+					if deployment.ExternalURL == "some-cf-url" {
+						return errors.New("unexpected response")
+					}
+
+					return nil
+				})
+
+				Expect(report.Failures()).To(HaveLen(2))
+				Expect(report.Err()).To(MatchError(ContainSubstring("cf/cflinuxfs3: unexpected response")))
+				Expect(report.String()).To(ContainSubstring("cf/cflinuxfs4: FAIL: unexpected response"))
+			})
+		})
+
+		context("when the assertion panics", func() {
+			it("records the panic and still deletes the deployment", func() {
+				report := matrix.Run("some-app", "/some/path/to/my/app", func(switchblade.Deployment) error {
+					panic("boom")
+				})
+
+				Expect(report.Failures()).To(HaveLen(4))
+				Expect(report[0].Error).To(MatchError("assertion panicked: boom"))
+				Expect(dockerTeardown.RunCall.CallCount).To(Equal(2))
+				Expect(cfTeardown.RunCall.CallCount).To(Equal(2))
+			})
+		})
+
+		context("when the deployment cannot be deleted", func() {
+			it.Before(func() {
+				dockerTeardown.RunCall.Returns.Error = errors.New("could not delete")
+			})
+
+			it("records the delete error", func() {
+				report := matrix.Run("some-app", "/some/path/to/my/app", func(switchblade.Deployment) error { return nil })
+
+				Expect(report.Failures()).To(HaveLen(2))
+				Expect(report[2].Error).To(MatchError(ContainSubstring("failed to delete some-app-cflinuxfs3:")))
+				Expect(report[2].Error).To(MatchError(ContainSubstring("could not delete")))
+			})
+		})
+	})
+}
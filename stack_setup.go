@@ -0,0 +1,6 @@
+package switchblade
+
+//go:generate faux --interface Execer --output fakes/execer.go
+type Execer interface {
+	Exec(command ...string) (output string, err error)
+}
@@ -0,0 +1,38 @@
+package switchblade
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/cloudfoundry/switchblade/internal/cloudfoundry"
+)
+
+type stagedBuildpackReader interface {
+	Read(ctx context.Context, name string) ([]StagingBuildpack, error)
+}
+
+func withStagedBuildpackReader(reader stagedBuildpackReader) PlatformOption {
+	return func(config platformConfig) platformConfig {
+		config.stagedBuildpacks = reader
+		return config
+	}
+}
+
+type cloudFoundryStagedBuildpackReader struct {
+	reader    cloudfoundry.StagedBuildpackReader
+	workspace string
+}
+
+func (r cloudFoundryStagedBuildpackReader) Read(ctx context.Context, name string) ([]StagingBuildpack, error) {
+	staged, err := r.reader.Read(ctx, filepath.Join(r.workspace, name), name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buildpacks []StagingBuildpack
+	for _, buildpack := range staged {
+		buildpacks = append(buildpacks, StagingBuildpack(buildpack))
+	}
+
+	return buildpacks, nil
+}
@@ -0,0 +1,201 @@
+package switchblade
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	stagingHeaderPattern    = regexp.MustCompile(`-----> (.*)$`)
+	stagingBuildpackPattern = regexp.MustCompile(`^(.+?) [Bb]uildpack version (\S+)`)
+)
+
+type StagingLog struct {
+	Sections   []StagingSection
+	Buildpacks []StagingBuildpack
+	Duration   time.Duration
+}
+
+type StagingBuildpack struct {
+	Name         string
+	Version      string
+	DetectOutput string
+}
+
+type StagingSection struct {
+	Buildpack string
+	Version   string
+	Phases    []StagingPhase
+	Duration  time.Duration
+}
+
+type StagingPhase struct {
+	Name     string
+	Lines    []string
+	Duration time.Duration
+}
+
+func ParseStagingLog(logs string) StagingLog {
+	var lines []stagingLine
+	for _, line := range strings.Split(logs, "\n") {
+		lines = append(lines, stagingLine{text: line})
+	}
+
+	return parseStagingLines(lines)
+}
+
+func (l StagingLog) Section(buildpack string) (StagingSection, bool) {
+	for _, section := range l.Sections {
+		if section.Buildpack == buildpack {
+			return section, true
+		}
+	}
+
+	return StagingSection{}, false
+}
+
+func (l StagingLog) Detected() (StagingBuildpack, bool) {
+	if len(l.Buildpacks) == 0 {
+		return StagingBuildpack{}, false
+	}
+
+	return l.Buildpacks[len(l.Buildpacks)-1], true
+}
+
+func (s StagingSection) Phase(name string) (StagingPhase, bool) {
+	for _, phase := range s.Phases {
+		if strings.HasPrefix(phase.Name, name) {
+			return phase, true
+		}
+	}
+
+	return StagingPhase{}, false
+}
+
+type stagingLine struct {
+	text string
+	at   time.Time
+}
+
+func parseStagingLines(lines []stagingLine) StagingLog {
+	var log StagingLog
+	if len(lines) == 0 {
+		return log
+	}
+
+	end := lines[len(lines)-1].at
+	log.Duration = end.Sub(lines[0].at)
+
+	var sectionStart, phaseStart time.Time
+	closeSection := func(at time.Time) {
+		if len(log.Sections) > 0 {
+			log.Sections[len(log.Sections)-1].Duration = at.Sub(sectionStart)
+		}
+	}
+	closePhase := func(at time.Time) {
+		if len(log.Sections) > 0 {
+			phases := log.Sections[len(log.Sections)-1].Phases
+			if len(phases) > 0 {
+				phases[len(phases)-1].Duration = at.Sub(phaseStart)
+			}
+		}
+	}
+
+	inPhase := false
+	for _, line := range lines {
+		text := strings.TrimRight(line.text, "\r")
+
+		matches := stagingHeaderPattern.FindStringSubmatch(text)
+		if matches == nil {
+			if inPhase && strings.TrimSpace(text) != "" {
+				phases := log.Sections[len(log.Sections)-1].Phases
+				phases[len(phases)-1].Lines = append(phases[len(phases)-1].Lines, strings.TrimSpace(text))
+			}
+
+			continue
+		}
+
+		title := strings.TrimSpace(matches[1])
+		if inPhase {
+			closePhase(line.at)
+		}
+
+		if buildpack := stagingBuildpackPattern.FindStringSubmatch(title); buildpack != nil {
+			closeSection(line.at)
+			log.Sections = append(log.Sections, StagingSection{Buildpack: buildpack[1], Version: buildpack[2]})
+			sectionStart = line.at
+			inPhase = false
+			continue
+		}
+
+		if len(log.Sections) == 0 {
+			log.Sections = append(log.Sections, StagingSection{})
+			sectionStart = line.at
+		}
+
+		section := &log.Sections[len(log.Sections)-1]
+		section.Phases = append(section.Phases, StagingPhase{Name: title})
+		phaseStart = line.at
+		inPhase = true
+	}
+
+	if inPhase {
+		closePhase(end)
+	}
+	closeSection(end)
+
+	return log
+}
+
+type stagingLogRecorder struct {
+	clock   Clock
+	partial []byte
+	lines   []stagingLine
+	m       *sync.Mutex
+}
+
+func newStagingLogRecorder(clock Clock) *stagingLogRecorder {
+	return &stagingLogRecorder{clock: clock, m: &sync.Mutex{}}
+}
+
+func (r *stagingLogRecorder) Write(p []byte) (int, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	at := r.now()
+	r.partial = append(r.partial, p...)
+	for {
+		i := bytes.IndexByte(r.partial, '\n')
+		if i < 0 {
+			break
+		}
+
+		r.lines = append(r.lines, stagingLine{text: string(r.partial[:i]), at: at})
+		r.partial = r.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+func (r *stagingLogRecorder) log() StagingLog {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	lines := r.lines
+	if len(r.partial) > 0 {
+		lines = append(lines, stagingLine{text: string(r.partial), at: r.now()})
+	}
+
+	return parseStagingLines(lines)
+}
+
+func (r *stagingLogRecorder) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+
+	return r.clock.Now()
+}
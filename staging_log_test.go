@@ -0,0 +1,115 @@
+package switchblade_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testStagingLog(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseStagingLog", func() {
+		it("groups the output into phases for each buildpack", func() {
+			log := switchblade.ParseStagingLog(`Setting up...
+   -----> Node.js Buildpack version 1.8.9
+   -----> Installing binaries
+          engines.node (package.json): 18.x
+          Installing node 18.16.0
+   -----> Installing dependencies
+          Running npm install
+-----> Ruby Buildpack version 1.9.1
+-----> Supplying Ruby
+       Using Ruby version: ruby-3.2.2
+-----> Finalizing Ruby
+`)
+			Expect(log.Sections).To(Equal([]switchblade.StagingSection{
+				{
+					Buildpack: "Node.js",
+					Version:   "1.8.9",
+					Phases: []switchblade.StagingPhase{
+						{
+							Name:  "Installing binaries",
+							Lines: []string{"engines.node (package.json): 18.x", "Installing node 18.16.0"},
+						},
+						{
+							Name:  "Installing dependencies",
+							Lines: []string{"Running npm install"},
+						},
+					},
+				},
+				{
+					Buildpack: "Ruby",
+					Version:   "1.9.1",
+					Phases: []switchblade.StagingPhase{
+						{
+							Name:  "Supplying Ruby",
+							Lines: []string{"Using Ruby version: ruby-3.2.2"},
+						},
+						{
+							Name: "Finalizing Ruby",
+						},
+					},
+				},
+			}))
+
+			section, ok := log.Section("Ruby")
+			Expect(ok).To(BeTrue())
+			Expect(section.Version).To(Equal("1.9.1"))
+
+			phase, ok := section.Phase("Supplying")
+			Expect(ok).To(BeTrue())
+			Expect(phase.Lines).To(ConsistOf("Using Ruby version: ruby-3.2.2"))
+
+			_, ok = section.Phase("Releasing")
+			Expect(ok).To(BeFalse())
+
+			_, ok = log.Section("Go")
+			Expect(ok).To(BeFalse())
+		})
+
+		context("when phases appear before any buildpack version", func() {
+			it("groups them into a section without a buildpack", func() {
+				log := switchblade.ParseStagingLog("-----> Downloading buildpacks\n       some-output\n")
+				Expect(log.Sections).To(Equal([]switchblade.StagingSection{
+					{
+						Phases: []switchblade.StagingPhase{
+							{Name: "Downloading buildpacks", Lines: []string{"some-output"}},
+						},
+					},
+				}))
+			})
+		})
+
+		context("when the logs are empty", func() {
+			it("returns an empty log", func() {
+				Expect(switchblade.ParseStagingLog("")).To(Equal(switchblade.StagingLog{}))
+			})
+		})
+	})
+
+	context("Detected", func() {
+		it("returns the buildpack that detected the app", func() {
+			log := switchblade.StagingLog{
+				Buildpacks: []switchblade.StagingBuildpack{
+					{Name: "apm_buildpack", Version: "1.2.3"},
+					{Name: "go_buildpack", Version: "1.10.2", DetectOutput: "go"},
+				},
+			}
+
+			buildpack, ok := log.Detected()
+			Expect(ok).To(BeTrue())
+			Expect(buildpack).To(Equal(switchblade.StagingBuildpack{Name: "go_buildpack", Version: "1.10.2", DetectOutput: "go"}))
+		})
+
+		context("when no buildpacks were recorded", func() {
+			it("returns false", func() {
+				_, ok := switchblade.StagingLog{}.Detected()
+				Expect(ok).To(BeFalse())
+			})
+		})
+	})
+}
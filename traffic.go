@@ -0,0 +1,216 @@
+package switchblade
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type TrafficExchange struct {
+	Request  TrafficRequest
+	Response TrafficResponse
+	Error    string
+}
+
+type TrafficRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+type TrafficResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (e TrafficExchange) String() string {
+	buffer := bytes.NewBuffer(nil)
+	fmt.Fprintf(buffer, "> %s %s\n", e.Request.Method, e.Request.URL)
+	writeTrafficHeaders(buffer, ">", e.Request.Header)
+	if len(e.Request.Body) > 0 {
+		fmt.Fprintf(buffer, ">\n> %s\n", e.Request.Body)
+	}
+
+	if e.Error != "" {
+		fmt.Fprintf(buffer, "< error: %s\n", e.Error)
+		return buffer.String()
+	}
+
+	fmt.Fprintf(buffer, "< %d %s\n", e.Response.StatusCode, http.StatusText(e.Response.StatusCode))
+	writeTrafficHeaders(buffer, "<", e.Response.Header)
+	if len(e.Response.Body) > 0 {
+		fmt.Fprintf(buffer, "<\n< %s\n", e.Response.Body)
+	}
+
+	return buffer.String()
+}
+
+func (d Deployment) Traffic() []TrafficExchange {
+	if d.traffic == nil {
+		return nil
+	}
+
+	return d.traffic.exchanges()
+}
+
+func writeTrafficHeaders(w io.Writer, prefix string, header http.Header) {
+	var names []string
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "%s %s: %s\n", prefix, name, strings.Join(header[name], ", "))
+	}
+}
+
+type trafficProxies struct {
+	proxies map[string]*trafficProxy
+	m       sync.Mutex
+}
+
+func newTrafficProxies() *trafficProxies {
+	return &trafficProxies{proxies: map[string]*trafficProxy{}}
+}
+
+func (p *trafficProxies) start(name, target string) (*trafficProxy, error) {
+	uri, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse traffic capture target: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for traffic capture: %w", err)
+	}
+
+	proxy := &trafficProxy{
+		url: fmt.Sprintf("http://%s", listener.Addr()),
+		m:   &sync.Mutex{},
+	}
+
+	reverseProxy := httputil.NewSingleHostReverseProxy(uri)
+	director := reverseProxy.Director
+	reverseProxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = uri.Host
+	}
+	reverseProxy.Transport = trafficTransport{base: http.DefaultTransport, proxy: proxy}
+	proxy.server = &http.Server{Handler: reverseProxy}
+	go func() {
+		_ = proxy.server.Serve(listener)
+	}()
+
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if existing, ok := p.proxies[name]; ok {
+		_ = existing.server.Close()
+	}
+	p.proxies[name] = proxy
+
+	return proxy, nil
+}
+
+func (p *trafficProxies) stop(name string) error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	proxy, ok := p.proxies[name]
+	if !ok {
+		return nil
+	}
+	delete(p.proxies, name)
+
+	err := proxy.server.Close()
+	if err != nil {
+		return fmt.Errorf("failed to stop traffic capture: %w", err)
+	}
+
+	return nil
+}
+
+type trafficProxy struct {
+	url     string
+	server  *http.Server
+	records []TrafficExchange
+	m       *sync.Mutex
+}
+
+func (p *trafficProxy) record(exchange TrafficExchange) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.records = append(p.records, exchange)
+}
+
+func (p *trafficProxy) exchanges() []TrafficExchange {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	return append([]TrafficExchange{}, p.records...)
+}
+
+type trafficTransport struct {
+	base  http.RoundTripper
+	proxy *trafficProxy
+}
+
+func (t trafficTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := TrafficExchange{
+		Request: TrafficRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+		},
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			exchange.Error = err.Error()
+			t.proxy.record(exchange)
+			return nil, err
+		}
+
+		exchange.Request.Body = body
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		exchange.Error = err.Error()
+		t.proxy.record(exchange)
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		exchange.Error = err.Error()
+		t.proxy.record(exchange)
+		return nil, err
+	}
+
+	exchange.Response = TrafficResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+	}
+	t.proxy.record(exchange)
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
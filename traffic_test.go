@@ -0,0 +1,102 @@
+package switchblade_test
+
+import (
+	gocontext "context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/cloudfoundry/switchblade/fakes"
+	"github.com/cloudfoundry/switchblade/internal/docker"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testTraffic(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		platform switchblade.Platform
+		server   *httptest.Server
+		teardown *fakes.DockerTeardownPhase
+	)
+
+	it.Before(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			w.Header().Set("X-Received-Forwarded-For", req.Header.Get("X-Forwarded-For"))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, "received %s", body)
+		}))
+
+		setup := &fakes.DockerSetupPhase{}
+		stage := &fakes.DockerStagePhase{}
+		start := &fakes.DockerStartPhase{}
+		start.RunCall.Stub = func(ctx gocontext.Context, logs io.Writer, name, command string) (string, string, error) {
+			return server.URL, "some-internal-url", nil
+		}
+		teardown = &fakes.DockerTeardownPhase{}
+		teardown.RunCall.Returns.TeardownReport = docker.TeardownReport{}
+
+		platform = switchblade.NewDocker(&fakes.DockerInitializePhase{}, setup, stage, start, teardown)
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	it("records the requests sent to the app and the responses it returns", func() {
+		deployment, _, err := platform.Deploy.WithTrafficCapture().Execute("some-app", "/some/path/to/my/app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployment.ExternalURL).NotTo(Equal(server.URL))
+		Expect(deployment.Traffic()).To(BeEmpty())
+
+		req, err := http.NewRequest(http.MethodPost, deployment.ExternalURL+"/some/path", strings.NewReader("some-body"))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("X-Custom", "some-value")
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		content, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+		Expect(string(content)).To(Equal("received some-body"))
+
+		traffic := deployment.Traffic()
+		Expect(traffic).To(HaveLen(1))
+		Expect(traffic[0].Request.Method).To(Equal(http.MethodPost))
+		Expect(traffic[0].Request.URL).To(Equal(server.URL + "/some/path"))
+		Expect(traffic[0].Request.Header.Get("X-Custom")).To(Equal("some-value"))
+		Expect(traffic[0].Request.Header.Get("X-Forwarded-For")).To(Equal("127.0.0.1"))
+		Expect(string(traffic[0].Request.Body)).To(Equal("some-body"))
+		Expect(traffic[0].Response.StatusCode).To(Equal(http.StatusCreated))
+		Expect(traffic[0].Response.Header.Get("X-Received-Forwarded-For")).To(Equal("127.0.0.1"))
+		Expect(string(traffic[0].Response.Body)).To(Equal("received some-body"))
+
+		Expect(traffic[0].String()).To(ContainSubstring(fmt.Sprintf("> POST %s/some/path\n", server.URL)))
+		Expect(traffic[0].String()).To(ContainSubstring("> X-Custom: some-value\n"))
+		Expect(traffic[0].String()).To(ContainSubstring(">\n> some-body\n"))
+		Expect(traffic[0].String()).To(ContainSubstring("< 201 Created\n"))
+		Expect(traffic[0].String()).To(ContainSubstring("<\n< received some-body\n"))
+
+		Expect(platform.Delete.Execute("some-app")).To(Succeed())
+
+		_, err = http.Get(deployment.ExternalURL)
+		Expect(err).To(HaveOccurred())
+	})
+
+	context("when traffic capture is not enabled", func() {
+		it("talks to the app directly and records nothing", func() {
+			deployment, _, err := platform.Deploy.Execute("some-app", "/some/path/to/my/app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployment.ExternalURL).To(Equal(server.URL))
+			Expect(deployment.Traffic()).To(BeNil())
+		})
+	})
+}
@@ -0,0 +1,82 @@
+package switchblade
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+type Address struct {
+	Host string
+	Port int
+}
+
+func (a Address) String() string {
+	return net.JoinHostPort(a.Host, strconv.Itoa(a.Port))
+}
+
+func (d Deployment) ExternalAddress() (Address, error) {
+	return parseAddress(d.Name, "external", d.ExternalURL)
+}
+
+func (d Deployment) InternalAddress() (Address, error) {
+	return parseAddress(d.Name, "internal", d.InternalURL)
+}
+
+func (d Deployment) ExternalURLWithScheme(scheme string) (string, error) {
+	return withScheme(d.Name, "external", d.ExternalURL, scheme)
+}
+
+func (d Deployment) InternalURLWithScheme(scheme string) (string, error) {
+	return withScheme(d.Name, "internal", d.InternalURL, scheme)
+}
+
+func parseAddress(name, kind, rawURL string) (Address, error) {
+	u, err := parseDeploymentURL(name, kind, rawURL)
+	if err != nil {
+		return Address{}, err
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPorts[u.Scheme]
+	}
+
+	number, err := strconv.Atoi(port)
+	if err != nil {
+		return Address{}, fmt.Errorf("failed to parse %s url for %s: unknown port for %q", kind, name, rawURL)
+	}
+
+	return Address{Host: u.Hostname(), Port: number}, nil
+}
+
+func withScheme(name, kind, rawURL, scheme string) (string, error) {
+	u, err := parseDeploymentURL(name, kind, rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = scheme
+	return u.String(), nil
+}
+
+func parseDeploymentURL(name, kind, rawURL string) (*url.URL, error) {
+	if rawURL == "" {
+		return nil, fmt.Errorf("failed to parse %s url for %s: no %s url was published", kind, name, kind)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s url for %s: %w", kind, name, err)
+	}
+
+	return u, nil
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+}
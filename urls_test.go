@@ -0,0 +1,133 @@
+package switchblade_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/switchblade"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDeploymentURLs(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		deployment switchblade.Deployment
+	)
+
+	it.Before(func() {
+		deployment = switchblade.Deployment{
+			Name:        "some-app",
+			ExternalURL: "http://some-app.example.com/some/path",
+			InternalURL: "http://172.19.0.2:8080",
+		}
+	})
+
+	context("ExternalAddress", func() {
+		it("returns the host and the port implied by the scheme", func() {
+			address, err := deployment.ExternalAddress()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal(switchblade.Address{Host: "some-app.example.com", Port: 80}))
+			Expect(address.String()).To(Equal("some-app.example.com:80"))
+		})
+
+		context("when the url has an explicit port", func() {
+			it.Before(func() {
+				deployment.ExternalURL = "http://[::1]:12345"
+			})
+
+			it("returns that port", func() {
+				address, err := deployment.ExternalAddress()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address).To(Equal(switchblade.Address{Host: "::1", Port: 12345}))
+				Expect(address.String()).To(Equal("[::1]:12345"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when no external url was published", func() {
+				it.Before(func() {
+					deployment.ExternalURL = ""
+				})
+
+				it("returns an error", func() {
+					_, err := deployment.ExternalAddress()
+					Expect(err).To(MatchError("failed to parse external url for some-app: no external url was published"))
+				})
+			})
+
+			context("when the url cannot be parsed", func() {
+				it.Before(func() {
+					deployment.ExternalURL = "%%%"
+				})
+
+				it("returns an error", func() {
+					_, err := deployment.ExternalAddress()
+					Expect(err).To(MatchError(ContainSubstring("failed to parse external url for some-app:")))
+				})
+			})
+
+			context("when the port cannot be determined", func() {
+				it.Before(func() {
+					deployment.ExternalURL = "tcp://some-app.example.com"
+				})
+
+				it("returns an error", func() {
+					_, err := deployment.ExternalAddress()
+					Expect(err).To(MatchError(`failed to parse external url for some-app: unknown port for "tcp://some-app.example.com"`))
+				})
+			})
+		})
+	})
+
+	context("InternalAddress", func() {
+		it("returns the host and port", func() {
+			address, err := deployment.InternalAddress()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal(switchblade.Address{Host: "172.19.0.2", Port: 8080}))
+		})
+
+		context("failure cases", func() {
+			context("when no internal url was published", func() {
+				it.Before(func() {
+					deployment.InternalURL = ""
+				})
+
+				it("returns an error", func() {
+					_, err := deployment.InternalAddress()
+					Expect(err).To(MatchError("failed to parse internal url for some-app: no internal url was published"))
+				})
+			})
+		})
+	})
+
+	context("ExternalURLWithScheme", func() {
+		it("returns the url with the given scheme", func() {
+			u, err := deployment.ExternalURLWithScheme("wss")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u).To(Equal("wss://some-app.example.com/some/path"))
+		})
+
+		context("failure cases", func() {
+			context("when no external url was published", func() {
+				it.Before(func() {
+					deployment.ExternalURL = ""
+				})
+
+				it("returns an error", func() {
+					_, err := deployment.ExternalURLWithScheme("https")
+					Expect(err).To(MatchError("failed to parse external url for some-app: no external url was published"))
+				})
+			})
+		})
+	})
+
+	context("InternalURLWithScheme", func() {
+		it("returns the url with the given scheme", func() {
+			u, err := deployment.InternalURLWithScheme("ws")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u).To(Equal("ws://172.19.0.2:8080"))
+		})
+	})
+}